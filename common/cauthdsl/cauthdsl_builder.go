@@ -151,6 +151,17 @@ func SignedByAnyAdmin(ids []string) *cb.SignaturePolicyEnvelope {
 	return signedByAnyOfGivenRole(msp.MSPRole_ADMIN, ids)
 }
 
+// SignedByNOutOfGivenIdentities returns a policy that requires
+// n valid signatures of distinct identities out of the given
+// serialized identities
+func SignedByNOutOfGivenIdentities(n int32, identities [][]byte) *cb.SignaturePolicyEnvelope {
+	sigspolicy := make([]*cb.SignaturePolicy, len(identities))
+	for i := range identities {
+		sigspolicy[i] = SignedBy(int32(i))
+	}
+	return Envelope(NOutOf(n, sigspolicy), identities)
+}
+
 // And is a convenience method which utilizes NOutOf to produce And equivalent behavior
 func And(lhs, rhs *cb.SignaturePolicy) *cb.SignaturePolicy {
 	return NOutOf(2, []*cb.SignaturePolicy{lhs, rhs})
@@ -128,6 +128,29 @@ func TestMultipleSignature(t *testing.T) {
 	}
 }
 
+func TestNOutOfGivenIdentities(t *testing.T) {
+	identities := [][]byte{[]byte("signer0"), []byte("signer1"), []byte("signer2"), []byte("signer3")}
+	policy := SignedByNOutOfGivenIdentities(3, identities)
+
+	spe, err := compile(policy.Rule, policy.Identities, &mockDeserializer{})
+	if err != nil {
+		t.Fatalf("Could not create a new SignaturePolicyEvaluator using the given policy, crypto-helper: %s", err)
+	}
+
+	if !spe(toSignedData(moreMsgs, identities[1:], [][]byte{validSignature, validSignature, validSignature})) {
+		t.Errorf("Expected authentication to succeed with three valid signatures")
+	}
+	if spe(toSignedData(msgs, identities[:2], [][]byte{validSignature, validSignature})) {
+		t.Errorf("Expected authentication to fail given only two signatures")
+	}
+	if spe(toSignedData(moreMsgs, identities[:3], [][]byte{validSignature, invalidSignature, validSignature})) {
+		t.Errorf("Expected authentication to fail given one of three invalid signatures")
+	}
+	if spe(toSignedData(moreMsgs, [][]byte{identities[0], identities[0], identities[1]}, [][]byte{validSignature, validSignature, validSignature})) {
+		t.Errorf("Expected authentication to fail because one of the three signatures was duplicated")
+	}
+}
+
 func TestComplexNestedSignature(t *testing.T) {
 	policy := Envelope(And(Or(And(SignedBy(0), SignedBy(1)), And(SignedBy(0), SignedBy(0))), SignedBy(0)), signers)
 
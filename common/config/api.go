@@ -94,6 +94,10 @@ type Orderer interface {
 	// used for ordering
 	KafkaBrokers() []string
 
+	// BFTConsenters returns the orderers that take part in the consensus
+	// of the channel when the consensus type is BFT
+	BFTConsenters() []*ab.BFTConsenter
+
 	// Organizations returns the organizations for the ordering service
 	Organizations() map[string]Org
 }
@@ -23,7 +23,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/config/msp"
+	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 )
 
@@ -47,8 +49,17 @@ const (
 
 	// KafkaBrokersKey is the cb.ConfigItem type key name for the KafkaBrokers message
 	KafkaBrokersKey = "KafkaBrokers"
+
+	// BFTConsentersKey is the cb.ConfigItem type key name for the BFTConsenters message
+	BFTConsentersKey = "BFTConsenters"
+
+	// BlockValidationPolicyKey is the name of the orderer policy the signatures of blocks are checked against
+	BlockValidationPolicyKey = "BlockValidation"
 )
 
+// BFTConsensusType is the consensus type of the channels ordered by BFT consenters
+const BFTConsensusType = "bft"
+
 // OrdererProtos is used as the source of the OrdererConfig
 type OrdererProtos struct {
 	ConsensusType       *ab.ConsensusType
@@ -56,6 +67,7 @@ type OrdererProtos struct {
 	BatchTimeout        *ab.BatchTimeout
 	KafkaBrokers        *ab.KafkaBrokers
 	ChannelRestrictions *ab.ChannelRestrictions
+	BFTConsenters       *ab.BFTConsenters
 }
 
 // Config is stores the orderer component configuration
@@ -136,6 +148,20 @@ func (oc *OrdererConfig) KafkaBrokers() []string {
 	return oc.protos.KafkaBrokers.Brokers
 }
 
+// BFTConsenters returns the orderers that take part in the consensus
+// of the channel when the consensus type is BFT
+func (oc *OrdererConfig) BFTConsenters() []*ab.BFTConsenter {
+	return oc.protos.BFTConsenters.Consenters
+}
+
+// BFTQuorum returns the number of consenters, out of the given number of BFT consenters,
+// that have to agree on a block. It is 2f+1 for 3f+1 consenters, such that any two quorums
+// intersect in at least f+1 consenters, one of which is not faulty.
+func BFTQuorum(consenters int) int {
+	f := (consenters - 1) / 3
+	return (consenters+f)/2 + 1
+}
+
 // MaxChannelsCount returns the maximum count of channels this orderer supports
 func (oc *OrdererConfig) MaxChannelsCount() uint64 {
 	return oc.protos.ChannelRestrictions.MaxCount
@@ -152,6 +178,7 @@ func (oc *OrdererConfig) Validate(tx interface{}, groups map[string]ValuePropose
 		oc.validateBatchSize,
 		oc.validateBatchTimeout,
 		oc.validateKafkaBrokers,
+		oc.validateBFTConsenters,
 	} {
 		if err := validator(); err != nil {
 			return err
@@ -215,6 +242,62 @@ func (oc *OrdererConfig) validateKafkaBrokers() error {
 	return nil
 }
 
+func (oc *OrdererConfig) validateBFTConsenters() error {
+	identities := make(map[string]struct{})
+	for i, consenter := range oc.protos.BFTConsenters.Consenters {
+		if consenter.Host == "" || consenter.Port == 0 || consenter.Port > 65535 {
+			return fmt.Errorf("Invalid endpoint %s:%d of BFT consenter %d", consenter.Host, consenter.Port, i)
+		}
+		if len(consenter.Identity) == 0 {
+			return fmt.Errorf("BFT consenter %d has no identity", i)
+		}
+		if _, exists := identities[string(consenter.Identity)]; exists {
+			return fmt.Errorf("BFT consenter %d has the identity of another consenter", i)
+		}
+		identities[string(consenter.Identity)] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateBFTBlockValidationPolicy returns an error if the given orderer group is that of a
+// channel ordered by BFT consenters, and its BlockValidation policy isn't the one derived
+// from the consenters by BFTBlockValidationPolicy. Config updates can't change the consenters
+// without changing the quorum the peers check blocks against accordingly, nor the other way around.
+func ValidateBFTBlockValidationPolicy(ordererGroup *cb.ConfigGroup) error {
+	if ordererGroup == nil {
+		return nil
+	}
+	consensusType := &ab.ConsensusType{}
+	if value, ok := ordererGroup.Values[ConsensusTypeKey]; ok {
+		if err := proto.Unmarshal(value.Value, consensusType); err != nil {
+			return fmt.Errorf("Error unmarshaling the consensus type: %s", err)
+		}
+	}
+	if consensusType.Type != BFTConsensusType {
+		return nil
+	}
+
+	consenters := &ab.BFTConsenters{}
+	if value, ok := ordererGroup.Values[BFTConsentersKey]; ok {
+		if err := proto.Unmarshal(value.Value, consenters); err != nil {
+			return fmt.Errorf("Error unmarshaling the BFT consenters: %s", err)
+		}
+	}
+	configPolicy, ok := ordererGroup.Policies[BlockValidationPolicyKey]
+	if !ok || configPolicy.Policy == nil || configPolicy.Policy.Type != int32(cb.Policy_SIGNATURE) {
+		return fmt.Errorf("The %s policy of a BFT channel must be a signature policy", BlockValidationPolicyKey)
+	}
+	policy := &cb.SignaturePolicyEnvelope{}
+	if err := proto.Unmarshal(configPolicy.Policy.Value, policy); err != nil {
+		return fmt.Errorf("Error unmarshaling the %s policy: %s", BlockValidationPolicyKey, err)
+	}
+	if !proto.Equal(policy, BFTBlockValidationPolicy(consenters.Consenters)) {
+		return fmt.Errorf("The %s policy does not require the signatures of a quorum of the %d BFT consenters",
+			BlockValidationPolicyKey, len(consenters.Consenters))
+	}
+	return nil
+}
+
 // This does just a barebones sanity check.
 func brokerEntrySeemsValid(broker string) bool {
 	if !strings.Contains(broker, ":") {
@@ -19,7 +19,10 @@ package config
 import (
 	"testing"
 
+	"github.com/hyperledger/fabric/common/cauthdsl"
+	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
@@ -83,3 +86,70 @@ func TestKafkaBrokers(t *testing.T) {
 	oc = &OrdererConfig{protos: &OrdererProtos{KafkaBrokers: &ab.KafkaBrokers{Brokers: []string{"127.0.0.1", "foo.bar", "127.0.0.1:-1", "localhost:65536", "foo.bar.:9092", ".127.0.0.1:9092", "-foo.bar:9092"}}}}
 	assert.Error(t, oc.validateKafkaBrokers(), "Invalid kafka brokers")
 }
+
+func TestBFTConsenters(t *testing.T) {
+	consenters := func(c ...*ab.BFTConsenter) *OrdererConfig {
+		return &OrdererConfig{protos: &OrdererProtos{BFTConsenters: &ab.BFTConsenters{Consenters: c}}}
+	}
+	assert.NoError(t, consenters().validateBFTConsenters(), "No BFT consenters")
+
+	oc := consenters(
+		&ab.BFTConsenter{Host: "orderer0", Port: 7050, Identity: []byte("id0")},
+		&ab.BFTConsenter{Host: "orderer1", Port: 7050, Identity: []byte("id1")},
+	)
+	assert.NoError(t, oc.validateBFTConsenters(), "Valid BFT consenters")
+
+	oc = consenters(&ab.BFTConsenter{Port: 7050, Identity: []byte("id0")})
+	assert.Error(t, oc.validateBFTConsenters(), "Missing host")
+
+	oc = consenters(&ab.BFTConsenter{Host: "orderer0", Port: 70500, Identity: []byte("id0")})
+	assert.Error(t, oc.validateBFTConsenters(), "Invalid port")
+
+	oc = consenters(&ab.BFTConsenter{Host: "orderer0", Port: 7050})
+	assert.Error(t, oc.validateBFTConsenters(), "Missing identity")
+
+	oc = consenters(
+		&ab.BFTConsenter{Host: "orderer0", Port: 7050, Identity: []byte("id0")},
+		&ab.BFTConsenter{Host: "orderer1", Port: 7050, Identity: []byte("id0")},
+	)
+	assert.Error(t, oc.validateBFTConsenters(), "Duplicate identity")
+}
+
+func TestBFTQuorum(t *testing.T) {
+	for consenters, quorum := range map[int]int{1: 1, 2: 2, 3: 2, 4: 3, 5: 4, 6: 4, 7: 5, 10: 7} {
+		assert.Equal(t, quorum, BFTQuorum(consenters), "Quorum of %d consenters", consenters)
+	}
+}
+
+func TestBFTBlockValidationPolicy(t *testing.T) {
+	consenters := []*ab.BFTConsenter{
+		{Host: "orderer0", Port: 7050, Identity: []byte("id0")},
+		{Host: "orderer1", Port: 7050, Identity: []byte("id1")},
+		{Host: "orderer2", Port: 7050, Identity: []byte("id2")},
+		{Host: "orderer3", Port: 7050, Identity: []byte("id3")},
+	}
+	ordererGroup := func(consensusType string, consenters []*ab.BFTConsenter, policy *cb.ConfigGroup) *cb.ConfigGroup {
+		group := TemplateConsensusType(consensusType).Groups[OrdererGroupKey]
+		group.Values[BFTConsentersKey] = TemplateBFTConsenters(consenters).Groups[OrdererGroupKey].Values[BFTConsentersKey]
+		if policy != nil {
+			group.Policies = policy.Groups[OrdererGroupKey].Policies
+		}
+		return group
+	}
+	assert.NoError(t, ValidateBFTBlockValidationPolicy(nil), "No orderer group")
+	assert.NoError(t, ValidateBFTBlockValidationPolicy(ordererGroup("solo", nil, nil)), "Not a BFT channel")
+	assert.NoError(t, ValidateBFTBlockValidationPolicy(ordererGroup(BFTConsensusType, consenters, TemplateBFTBlockValidationPolicy(consenters))),
+		"Policy derived from the consenters")
+
+	assert.Error(t, ValidateBFTBlockValidationPolicy(ordererGroup(BFTConsensusType, consenters, nil)), "Missing policy")
+	assert.Error(t, ValidateBFTBlockValidationPolicy(ordererGroup(BFTConsensusType, consenters, TemplateBFTBlockValidationPolicy(consenters[:3]))),
+		"Consenter added without updating the policy")
+	assert.Error(t, ValidateBFTBlockValidationPolicy(ordererGroup(BFTConsensusType, consenters[:3], TemplateBFTBlockValidationPolicy(consenters))),
+		"Consenter removed without updating the policy")
+
+	// a single signature of any consenter
+	singleSignature := TemplateBFTBlockValidationPolicy(consenters)
+	singleSignature.Groups[OrdererGroupKey].Policies[BlockValidationPolicyKey].Policy.Value = utils.MarshalOrPanic(
+		cauthdsl.SignedByNOutOfGivenIdentities(1, [][]byte{[]byte("id0"), []byte("id1"), []byte("id2"), []byte("id3")}))
+	assert.Error(t, ValidateBFTBlockValidationPolicy(ordererGroup(BFTConsensusType, consenters, singleSignature)), "Policy weaker than a quorum")
+}
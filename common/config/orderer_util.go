@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"github.com/hyperledger/fabric/common/cauthdsl"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -51,6 +52,35 @@ func TemplateChannelRestrictions(maxChannels uint64) *cb.ConfigGroup {
 	return ordererConfigGroup(ChannelRestrictionsKey, utils.MarshalOrPanic(&ab.ChannelRestrictions{MaxCount: maxChannels}))
 }
 
+// TemplateBFTConsenters creates a headerless config item representing the BFT consenters
+func TemplateBFTConsenters(consenters []*ab.BFTConsenter) *cb.ConfigGroup {
+	return ordererConfigGroup(BFTConsentersKey, utils.MarshalOrPanic(&ab.BFTConsenters{Consenters: consenters}))
+}
+
+// BFTBlockValidationPolicy returns the signature policy which requires
+// the signatures of a quorum of the given BFT consenters
+func BFTBlockValidationPolicy(consenters []*ab.BFTConsenter) *cb.SignaturePolicyEnvelope {
+	identities := make([][]byte, len(consenters))
+	for i, consenter := range consenters {
+		identities[i] = consenter.Identity
+	}
+	return cauthdsl.SignedByNOutOfGivenIdentities(int32(BFTQuorum(len(consenters))), identities)
+}
+
+// TemplateBFTBlockValidationPolicy creates the BlockValidation policy of
+// a channel ordered by the given BFT consenters
+func TemplateBFTBlockValidationPolicy(consenters []*ab.BFTConsenter) *cb.ConfigGroup {
+	result := cb.NewConfigGroup()
+	result.Groups[OrdererGroupKey] = cb.NewConfigGroup()
+	result.Groups[OrdererGroupKey].Policies[BlockValidationPolicyKey] = &cb.ConfigPolicy{
+		Policy: &cb.Policy{
+			Type:  int32(cb.Policy_SIGNATURE),
+			Value: utils.MarshalOrPanic(BFTBlockValidationPolicy(consenters)),
+		},
+	}
+	return result
+}
+
 // TemplateKafkaBrokers creates a headerless config item representing the kafka brokers
 func TemplateKafkaBrokers(brokers []string) *cb.ConfigGroup {
 	return ordererConfigGroup(KafkaBrokersKey, utils.MarshalOrPanic(&ab.KafkaBrokers{Brokers: brokers}))
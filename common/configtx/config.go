@@ -267,6 +267,11 @@ func processConfig(channelGroup *cb.ConfigGroup, proposer api.Proposer) (*config
 
 func (cm *configManager) processConfig(channelGroup *cb.ConfigGroup) (*configResult, error) {
 	logger.Debugf("Beginning new config for channel %s", cm.current.channelID)
+	// The policy the blocks are validated against must follow the BFT consenters
+	if err := config.ValidateBFTBlockValidationPolicy(channelGroup.Groups[config.OrdererGroupKey]); err != nil {
+		return nil, err
+	}
+
 	configResult, err := processConfig(channelGroup, cm.initializer)
 	if err != nil {
 		return nil, err
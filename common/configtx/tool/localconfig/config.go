@@ -131,6 +131,7 @@ type Orderer struct {
 	BatchTimeout  time.Duration   `yaml:"BatchTimeout"`
 	BatchSize     BatchSize       `yaml:"BatchSize"`
 	Kafka         Kafka           `yaml:"Kafka"`
+	BFT           BFT             `yaml:"BFT"`
 	Organizations []*Organization `yaml:"Organizations"`
 	MaxChannels   uint64          `yaml:"MaxChannels"`
 }
@@ -147,6 +148,20 @@ type Kafka struct {
 	Brokers []string `yaml:"Brokers"`
 }
 
+// BFT contains configuration for the BFT orderer.
+type BFT struct {
+	Consenters []*BFTConsenter `yaml:"Consenters"`
+}
+
+// BFTConsenter identifies an orderer that takes part in the BFT consensus.
+type BFTConsenter struct {
+	Host  string `yaml:"Host"`
+	Port  uint16 `yaml:"Port"`
+	MSPID string `yaml:"MSPID"`
+	// Cert is the path of the PEM encoded certificate of the signing identity of the orderer
+	Cert string `yaml:"Cert"`
+}
+
 var genesisDefaults = TopLevel{
 	Orderer: &Orderer{
 		OrdererType:  "solo",
@@ -234,6 +249,10 @@ func (p *Profile) completeInitialization(configDir string) {
 		return
 	}
 
+	for _, consenter := range p.Orderer.BFT.Consenters {
+		cf.TranslatePathInPlace(configDir, &consenter.Cert)
+	}
+
 	for {
 		switch {
 		case p.Orderer.OrdererType == "":
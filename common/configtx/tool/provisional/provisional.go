@@ -18,6 +18,7 @@ package provisional
 
 import (
 	"fmt"
+	"io/ioutil"
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/config"
@@ -30,6 +31,7 @@ import (
 	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap"
 	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -65,6 +67,8 @@ const (
 	ConsensusTypeSolo = "solo"
 	// ConsensusTypeKafka identifies the Kafka-based consensus implementation.
 	ConsensusTypeKafka = "kafka"
+	// ConsensusTypeBFT identifies the byzantine fault tolerant consensus implementation.
+	ConsensusTypeBFT = config.BFTConsensusType
 
 	// TestChainID is the default value of ChainID. It is used by all testing
 	// networks. It it necessary to set and export this variable so that test
@@ -73,7 +77,7 @@ const (
 	TestChainID = "testchainid"
 
 	// BlockValidationPolicyKey TODO
-	BlockValidationPolicyKey = "BlockValidation"
+	BlockValidationPolicyKey = config.BlockValidationPolicyKey
 
 	// OrdererAdminsPolicy is the absolute path to the orderer admins policy
 	OrdererAdminsPolicy = "/Channel/Orderer/Admins"
@@ -119,8 +123,7 @@ func New(conf *genesisconfig.Profile) Generator {
 			config.TemplateBatchTimeout(conf.Orderer.BatchTimeout.String()),
 			config.TemplateChannelRestrictions(conf.Orderer.MaxChannels),
 
-			// Initialize the default Reader/Writer/Admins orderer policies, as well as block validation policy
-			policies.TemplateImplicitMetaPolicyWithSubPolicy([]string{config.OrdererGroupKey}, BlockValidationPolicyKey, configvaluesmsp.WritersPolicyKey, cb.ImplicitMetaPolicy_ANY),
+			// Initialize the default Reader/Writer/Admins orderer policies
 			policies.TemplateImplicitMetaAnyPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.ReadersPolicyKey),
 			policies.TemplateImplicitMetaAnyPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.WritersPolicyKey),
 			policies.TemplateImplicitMetaMajorityPolicy([]string{config.OrdererGroupKey}, configvaluesmsp.AdminsPolicyKey),
@@ -138,13 +141,22 @@ func New(conf *genesisconfig.Profile) Generator {
 			)
 		}
 
+		// A block is valid if it is signed by any orderer, except for
+		// BFT ordering where it must be signed by a quorum of the consenters
+		blockValidationPolicy := policies.TemplateImplicitMetaPolicyWithSubPolicy([]string{config.OrdererGroupKey}, BlockValidationPolicyKey, configvaluesmsp.WritersPolicyKey, cb.ImplicitMetaPolicy_ANY)
+
 		switch conf.Orderer.OrdererType {
 		case ConsensusTypeSolo:
 		case ConsensusTypeKafka:
 			bs.ordererGroups = append(bs.ordererGroups, config.TemplateKafkaBrokers(conf.Orderer.Kafka.Brokers))
+		case ConsensusTypeBFT:
+			consenters := bftConsenters(conf.Orderer.BFT.Consenters)
+			bs.ordererGroups = append(bs.ordererGroups, config.TemplateBFTConsenters(consenters))
+			blockValidationPolicy = config.TemplateBFTBlockValidationPolicy(consenters)
 		default:
 			panic(fmt.Errorf("Wrong consenter type value given: %s", conf.Orderer.OrdererType))
 		}
+		bs.ordererGroups = append(bs.ordererGroups, blockValidationPolicy)
 	}
 
 	if conf.Application != nil {
@@ -227,6 +239,25 @@ func New(conf *genesisconfig.Profile) Generator {
 	return bs
 }
 
+func bftConsenters(confConsenters []*genesisconfig.BFTConsenter) []*ab.BFTConsenter {
+	if len(confConsenters) == 0 {
+		logger.Panic("BFT ordering requires at least one consenter")
+	}
+	var consenters []*ab.BFTConsenter
+	for _, consenter := range confConsenters {
+		cert, err := ioutil.ReadFile(consenter.Cert)
+		if err != nil {
+			logger.Panicf("Error loading the certificate of BFT consenter %s:%d: %s", consenter.Host, consenter.Port, err)
+		}
+		consenters = append(consenters, &ab.BFTConsenter{
+			Host:     consenter.Host,
+			Port:     uint32(consenter.Port),
+			Identity: utils.MarshalOrPanic(&mspprotos.SerializedIdentity{Mspid: consenter.MSPID, IdBytes: cert}),
+		})
+	}
+	return consenters
+}
+
 // ChannelTemplate TODO
 func (bs *bootstrapper) ChannelTemplate() configtx.Template {
 	return configtx.NewModPolicySettingTemplate(
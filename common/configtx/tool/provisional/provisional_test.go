@@ -18,21 +18,39 @@ package provisional
 
 import (
 	"bytes"
+	"fmt"
+	"path/filepath"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/common/configtx"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 )
 
 var confSolo *genesisconfig.Profile
 var confKafka *genesisconfig.Profile
+var confBFT *genesisconfig.Profile
 var testCases []*genesisconfig.Profile
 
 func init() {
 	confSolo = genesisconfig.Load(genesisconfig.SampleSingleMSPSoloProfile)
 	confKafka = genesisconfig.Load("SampleInsecureKafka")
-	testCases = []*genesisconfig.Profile{confSolo, confKafka}
+	confBFT = genesisconfig.Load(genesisconfig.SampleSingleMSPSoloProfile)
+	confBFT.Orderer.OrdererType = ConsensusTypeBFT
+	for i := 0; i < 4; i++ {
+		confBFT.Orderer.BFT.Consenters = append(confBFT.Orderer.BFT.Consenters, &genesisconfig.BFTConsenter{
+			Host:  fmt.Sprintf("orderer%d", i),
+			Port:  7050,
+			MSPID: fmt.Sprintf("Orderer%dMSP", i),
+			Cert:  filepath.Join(confBFT.Orderer.Organizations[0].MSPDir, "signcerts", "peer.pem"),
+		})
+	}
+	testCases = []*genesisconfig.Profile{confSolo, confKafka, confBFT}
 }
 
 func TestGenesisBlockHeader(t *testing.T) {
@@ -71,3 +89,36 @@ func TestGenesisBlockForChannelHeader(t *testing.T) {
 		assert.Nil(t, genesisBlock.Header.PreviousHash, "Case %s: Header previousHash to be nil", tc.Orderer.OrdererType)
 	}
 }
+
+func TestBFTBlockValidationPolicy(t *testing.T) {
+	genesisBlock := New(confBFT).GenesisBlockForChannel("mychannel")
+	env, err := utils.ExtractEnvelope(genesisBlock, 0)
+	assert.NoError(t, err)
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	assert.NoError(t, err)
+	configEnv, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	assert.NoError(t, err)
+	ordererGroup := configEnv.Config.ChannelGroup.Groups[config.OrdererGroupKey]
+
+	consenters := &ab.BFTConsenters{}
+	assert.NoError(t, proto.Unmarshal(ordererGroup.Values[config.BFTConsentersKey].Value, consenters))
+	assert.Len(t, consenters.Consenters, 4)
+
+	policy := ordererGroup.Policies[BlockValidationPolicyKey].Policy
+	assert.Equal(t, int32(cb.Policy_SIGNATURE), policy.Type, "Blocks should be validated by the signatures of the consenters")
+	spe := &cb.SignaturePolicyEnvelope{}
+	assert.NoError(t, proto.Unmarshal(policy.Value, spe))
+	assert.Len(t, spe.Identities, 4)
+	assert.Equal(t, int32(3), spe.Rule.GetNOutOf().N, "Blocks should be signed by a quorum of 3 out of 4 consenters")
+
+	_, err = configtx.NewManagerImpl(env, configtx.NewInitializer(), nil)
+	assert.NoError(t, err, "The policy derived from the consenters should be accepted")
+
+	// the quorum no longer matches the consenters once one of them is removed
+	consenters.Consenters = consenters.Consenters[:3]
+	ordererGroup.Values[config.BFTConsentersKey].Value = utils.MarshalOrPanic(consenters)
+	payload.Data = utils.MarshalOrPanic(configEnv)
+	env.Payload = utils.MarshalOrPanic(payload)
+	_, err = configtx.NewManagerImpl(env, configtx.NewInitializer(), nil)
+	assert.Error(t, err, "The policy should be rejected once it no longer follows the consenters")
+}
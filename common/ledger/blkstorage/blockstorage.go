@@ -55,6 +55,8 @@ type BlockStoreProvider interface {
 	OpenBlockStore(ledgerid string) (BlockStore, error)
 	Exists(ledgerid string) (bool, error)
 	List() ([]string, error)
+	// Drop deletes the blocks and the index of the given ledger. The block store must not be open
+	Drop(ledgerid string) error
 	Close()
 }
 
@@ -38,6 +38,9 @@ type blockfileStream struct {
 	file          *os.File
 	reader        *bufio.Reader
 	currentOffset int64
+	format        blockfileFormat
+	// incompleteHeader is set when a crash happened while the header of the file was written
+	incompleteHeader bool
 }
 
 // blockStream reads blocks sequentially from multiple files.
@@ -55,7 +58,11 @@ type blockStream struct {
 type blockPlacementInfo struct {
 	fileNum          int
 	blockStartOffset int64
+	// blockBytesOffset is the offset of the bytes stored for the block,
+	// which are compressed when the file format enables compression
 	blockBytesOffset int64
+	blockEndOffset   int64
+	format           blockfileFormat
 }
 
 ///////////////////////////////////
@@ -69,6 +76,15 @@ func newBlockfileStream(rootDir string, fileNum int, startOffset int64) (*blockf
 	if file, err = os.OpenFile(filePath, os.O_RDONLY, 0600); err != nil {
 		return nil, err
 	}
+	format, incompleteHeader, err := readBlockfileFormat(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	// the first block follows the header of the file
+	if startOffset == 0 {
+		startOffset = int64(len(format.header()))
+	}
 	var newPosition int64
 	if newPosition, err = file.Seek(startOffset, 0); err != nil {
 		return nil, err
@@ -77,7 +93,13 @@ func newBlockfileStream(rootDir string, fileNum int, startOffset int64) (*blockf
 		panic(fmt.Sprintf("Could not seek file [%s] to given startOffset [%d]. New position = [%d]",
 			filePath, startOffset, newPosition))
 	}
-	s := &blockfileStream{fileNum, file, bufio.NewReader(file), startOffset}
+	s := &blockfileStream{
+		fileNum:          fileNum,
+		file:             file,
+		reader:           bufio.NewReader(file),
+		currentOffset:    startOffset,
+		format:           format,
+		incompleteHeader: incompleteHeader}
 	return s, nil
 }
 
@@ -96,6 +118,10 @@ func (s *blockfileStream) nextBlockBytesAndPlacementInfo() ([]byte, *blockPlacem
 	var fileInfo os.FileInfo
 	moreContentAvailable := true
 
+	if s.incompleteHeader {
+		return nil, nil, ErrUnexpectedEndOfBlockfile
+	}
+
 	if fileInfo, err = s.file.Stat(); err != nil {
 		return nil, nil, err
 	}
@@ -124,7 +150,8 @@ func (s *blockfileStream) nextBlockBytesAndPlacementInfo() ([]byte, *blockPlacem
 		}
 		panic(fmt.Errorf("Error in decoding varint bytes [%#v]", lenBytes))
 	}
-	bytesExpected := int64(n) + int64(length)
+	trailerLen := s.format.trailerLen()
+	bytesExpected := int64(n) + int64(length) + int64(trailerLen)
 	if bytesExpected > remainingBytes {
 		logger.Debugf("At least [%d] bytes expected. Remaining bytes = [%d]. Returning with error [%s]",
 			bytesExpected, remainingBytes, ErrUnexpectedEndOfBlockfile)
@@ -134,16 +161,22 @@ func (s *blockfileStream) nextBlockBytesAndPlacementInfo() ([]byte, *blockPlacem
 	if _, err = s.reader.Discard(n); err != nil {
 		return nil, nil, err
 	}
-	blockBytes := make([]byte, length)
-	if _, err = io.ReadAtLeast(s.reader, blockBytes, int(length)); err != nil {
-		logger.Debugf("Error while trying to read [%d] bytes from fileNum [%d]: %s", length, s.fileNum, err)
+	storedBytes := make([]byte, int(length)+trailerLen)
+	if _, err = io.ReadAtLeast(s.reader, storedBytes, len(storedBytes)); err != nil {
+		logger.Debugf("Error while trying to read [%d] bytes from fileNum [%d]: %s", len(storedBytes), s.fileNum, err)
 		return nil, nil, err
 	}
+	blockBytes, err := s.format.decodeStoredBytes(storedBytes[:length], storedBytes[length:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("Error while decoding block in fileNum [%d] at offset [%d]: %s", s.fileNum, s.currentOffset, err)
+	}
 	blockPlacementInfo := &blockPlacementInfo{
 		fileNum:          s.fileNum,
 		blockStartOffset: s.currentOffset,
-		blockBytesOffset: s.currentOffset + int64(n)}
-	s.currentOffset += int64(n) + int64(length)
+		blockBytesOffset: s.currentOffset + int64(n),
+		blockEndOffset:   s.currentOffset + bytesExpected,
+		format:           s.format}
+	s.currentOffset += bytesExpected
 	logger.Debugf("Returning blockbytes - length=[%d], placementInfo={%s}", len(blockBytes), blockPlacementInfo)
 	return blockBytes, blockPlacementInfo, nil
 }
@@ -203,7 +236,27 @@ func (s *blockStream) close() error {
 	return s.currentFileStream.close()
 }
 
+// blockLocPointer returns the location of the block recorded in the index
+func (i *blockPlacementInfo) blockLocPointer() *fileLocPointer {
+	return &fileLocPointer{fileSuffixNum: i.fileNum, locPointer: locPointer{offset: int(i.blockStartOffset)}}
+}
+
+// txLocPointer returns the location recorded in the index for a transaction of the block, given its
+// location within the block bytes. In the legacy format the transaction is read straight from the block
+// file, while in the checksummed format the stored bytes may be compressed, so that the location is the
+// one of the block along with the offset of the transaction within the block bytes
+func (i *blockPlacementInfo) txLocPointer(txLoc *locPointer) *fileLocPointer {
+	if i.format.checksummed() {
+		return &fileLocPointer{
+			fileSuffixNum: i.fileNum,
+			locPointer:    locPointer{offset: int(i.blockStartOffset), bytesLength: txLoc.bytesLength},
+			format:        i.format,
+			offsetInBlock: txLoc.offset}
+	}
+	return newFileLocationPointer(i.fileNum, int(i.blockBytesOffset), txLoc)
+}
+
 func (i *blockPlacementInfo) String() string {
-	return fmt.Sprintf("fileNum=[%d], startOffset=[%d], bytesOffset=[%d]",
-		i.fileNum, i.blockStartOffset, i.blockBytesOffset)
+	return fmt.Sprintf("fileNum=[%d], startOffset=[%d], bytesOffset=[%d], endOffset=[%d], format=[%s]",
+		i.fileNum, i.blockStartOffset, i.blockBytesOffset, i.blockEndOffset, i.format)
 }
@@ -0,0 +1,217 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/snappy"
+)
+
+/*
+Each block file is written in one of two formats, and the files of a ledger may mix both formats.
+
+In the legacy format, a block file is a sequence of blocks, each one prefixed by its length as a varint.
+
+In the checksummed format, a block file starts with a header made of a zero byte, which cannot start a
+file of the legacy format since no block is empty, the magic "fabricblk", the version of the format and
+the compression of the blocks. The header is followed by a record per block, made of the length of the
+bytes stored for the block as a varint, the stored bytes, which are the block bytes compressed if
+compression is enabled, and the CRC32C checksum of the stored bytes.
+
+A block store whose configured format changes moves on to a new block file when opened. The existing
+files are read in the format they were written in until they are migrated: once the block store is
+opened, the files that precede the current block file and are in another format are rewritten in the
+configured format in the background, one file at a time, see migrate.go.
+*/
+
+// Names of the block file formats and compressions, as configured
+const (
+	FormatLegacy      = "legacy"
+	FormatChecksummed = "checksummed"
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+)
+
+// ErrBlockChecksumMismatch is returned when the bytes of a block in a block file
+// of the checksummed format do not match their checksum
+var ErrBlockChecksumMismatch = errors.New("block does not match its checksum in blockfile")
+
+const (
+	checksummedFormatVersion = byte(1)
+
+	compressionNone   = byte(0)
+	compressionSnappy = byte(1)
+
+	checksumLen = 4
+)
+
+var (
+	blockfileMagic     = []byte("fabricblk")
+	blockfileHeaderLen = 1 + len(blockfileMagic) + 2
+	castagnoliTable    = crc32.MakeTable(crc32.Castagnoli)
+
+	// legacyFormat is the zero value, which is the format of the block files written before
+	// the checksummed format was introduced
+	legacyFormat = blockfileFormat{}
+)
+
+// blockfileFormat is the format of a block file
+type blockfileFormat struct {
+	version     byte
+	compression byte
+}
+
+func newBlockfileFormat(format, compression string) (blockfileFormat, error) {
+	switch format {
+	case FormatLegacy, "":
+		if compression != CompressionNone && compression != "" {
+			return blockfileFormat{}, fmt.Errorf("Blocks cannot be compressed in the [%s] block file format", FormatLegacy)
+		}
+		return legacyFormat, nil
+	case FormatChecksummed:
+		switch compression {
+		case CompressionNone, "":
+			return blockfileFormat{checksummedFormatVersion, compressionNone}, nil
+		case CompressionSnappy:
+			return blockfileFormat{checksummedFormatVersion, compressionSnappy}, nil
+		default:
+			return blockfileFormat{}, fmt.Errorf("Unknown block compression [%s], expected [%s] or [%s]",
+				compression, CompressionNone, CompressionSnappy)
+		}
+	default:
+		return blockfileFormat{}, fmt.Errorf("Unknown block file format [%s], expected [%s] or [%s]",
+			format, FormatLegacy, FormatChecksummed)
+	}
+}
+
+func (f blockfileFormat) checksummed() bool {
+	return f.version == checksummedFormatVersion
+}
+
+// header returns the bytes that start a block file of the format
+func (f blockfileFormat) header() []byte {
+	if !f.checksummed() {
+		return nil
+	}
+	header := append([]byte{0}, blockfileMagic...)
+	return append(header, f.version, f.compression)
+}
+
+// encodeRecord returns the bytes appended to a block file for a block,
+// along with the offset of the bytes stored for the block within them
+func (f blockfileFormat) encodeRecord(blockBytes []byte) ([]byte, int, error) {
+	storedBytes := blockBytes
+	switch f.compression {
+	case compressionSnappy:
+		storedBytes = snappy.Encode(nil, blockBytes)
+	}
+	record := proto.EncodeVarint(uint64(len(storedBytes)))
+	storedBytesOffset := len(record)
+	record = append(record, storedBytes...)
+	if f.checksummed() {
+		checksum := make([]byte, checksumLen)
+		binary.BigEndian.PutUint32(checksum, crc32.Checksum(storedBytes, castagnoliTable))
+		record = append(record, checksum...)
+	}
+	return record, storedBytesOffset, nil
+}
+
+// trailerLen is the number of bytes that follow the bytes stored for a block in a record
+func (f blockfileFormat) trailerLen() int {
+	if f.checksummed() {
+		return checksumLen
+	}
+	return 0
+}
+
+// decodeStoredBytes checks the bytes stored for a block against their checksum and returns the block bytes
+func (f blockfileFormat) decodeStoredBytes(storedBytes, trailer []byte) ([]byte, error) {
+	if !f.checksummed() {
+		return storedBytes, nil
+	}
+	if binary.BigEndian.Uint32(trailer) != crc32.Checksum(storedBytes, castagnoliTable) {
+		return nil, ErrBlockChecksumMismatch
+	}
+	switch f.compression {
+	case compressionSnappy:
+		return snappy.Decode(nil, storedBytes)
+	}
+	return storedBytes, nil
+}
+
+func (f blockfileFormat) String() string {
+	if !f.checksummed() {
+		return FormatLegacy
+	}
+	switch f.compression {
+	case compressionNone:
+		return FormatChecksummed + "/" + CompressionNone
+	case compressionSnappy:
+		return FormatChecksummed + "/" + CompressionSnappy
+	default:
+		return fmt.Sprintf("%s/%d", FormatChecksummed, f.compression)
+	}
+}
+
+// readBlockfileFormat reads the format of a block file from its header. A file without header, empty
+// files included, is in the legacy format. A file whose header was partially written because of a
+// crash is reported as incomplete, it holds no block
+func readBlockfileFormat(file *os.File) (format blockfileFormat, incomplete bool, err error) {
+	header := make([]byte, blockfileHeaderLen)
+	n, err := file.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return blockfileFormat{}, false, err
+	}
+	if n == 0 || header[0] != 0 {
+		return legacyFormat, false, nil
+	}
+	if n < blockfileHeaderLen {
+		return blockfileFormat{}, true, nil
+	}
+	if !bytes.Equal(header[1:1+len(blockfileMagic)], blockfileMagic) {
+		return blockfileFormat{}, false, fmt.Errorf("Block file [%s] has no valid header", file.Name())
+	}
+	format = blockfileFormat{version: header[1+len(blockfileMagic)], compression: header[2+len(blockfileMagic)]}
+	if format.version != checksummedFormatVersion || format.compression > compressionSnappy {
+		return blockfileFormat{}, false, fmt.Errorf("Block file [%s] has unsupported format [%d] or compression [%d]",
+			file.Name(), format.version, format.compression)
+	}
+	return format, false, nil
+}
+
+// getBlockfileFormat returns the format of the given block file, an incomplete header being
+// reported as the legacy format since the file holds no block
+func getBlockfileFormat(rootDir string, fileNum int) (blockfileFormat, error) {
+	file, err := os.Open(deriveBlockfilePath(rootDir, fileNum))
+	if err != nil {
+		return blockfileFormat{}, err
+	}
+	defer file.Close()
+	format, incomplete, err := readBlockfileFormat(file)
+	if incomplete {
+		return legacyFormat, nil
+	}
+	return format, err
+}
@@ -0,0 +1,243 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
+	putil "github.com/hyperledger/fabric/protos/utils"
+)
+
+func TestNewConfWithFormat(t *testing.T) {
+	conf, err := NewConfWithFormat(testPath(), 0, FormatChecksummed, CompressionSnappy)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf.format, blockfileFormat{checksummedFormatVersion, compressionSnappy})
+	conf, err = NewConfWithFormat(testPath(), 0, FormatLegacy, CompressionNone)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, conf.format, legacyFormat)
+
+	_, err = NewConfWithFormat(testPath(), 0, FormatLegacy, CompressionSnappy)
+	testutil.AssertError(t, err, "legacy format cannot be compressed")
+	_, err = NewConfWithFormat(testPath(), 0, FormatChecksummed, "zip")
+	testutil.AssertError(t, err, "unknown compression")
+	_, err = NewConfWithFormat(testPath(), 0, "v3", CompressionNone)
+	testutil.AssertError(t, err, "unknown format")
+}
+
+func TestBlockfileFormats(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionSnappy} {
+		conf, err := NewConfWithFormat(testPath(), 4096, FormatChecksummed, compression)
+		testutil.AssertNoError(t, err, "")
+		testBlockfileFormat(t, conf)
+	}
+}
+
+func testBlockfileFormat(t *testing.T, conf *Conf) {
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 20)
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	blkfileMgrWrapper.addBlocks(blocks)
+	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.cpInfo.latestFileFormat, conf.format)
+	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.cpInfo.latestFileChunkSuffixNum > 0, true)
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+	blkfileMgrWrapper.close()
+	env.provider.Close()
+
+	// the checkpoint info and the index are rebuilt from the block files
+	os.RemoveAll(conf.getIndexDir())
+	env = newTestEnv(t, conf)
+	defer env.Cleanup()
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.cpInfo.latestFileFormat, conf.format)
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+}
+
+func TestBlockfileFormatMigration(t *testing.T) {
+	legacyConf := NewConf(testPath(), 0)
+	env := newTestEnv(t, legacyConf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 15)
+	addBlocksToStore(t, env, "testLedger", blocks[:5])
+	env.provider.Close()
+
+	// the blocks following the legacy block file go to a new file in the checksummed format
+	conf, err := NewConfWithFormat(legacyConf.blockStorageDir, 0, FormatChecksummed, CompressionSnappy)
+	testutil.AssertNoError(t, err, "")
+	env = newTestEnv(t, conf)
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	cpInfo := blkfileMgrWrapper.blockfileMgr.cpInfo
+	testutil.AssertEquals(t, cpInfo.latestFileChunkSuffixNum, 1)
+	testutil.AssertEquals(t, cpInfo.latestFileChunksize, blockfileHeaderLen)
+	testutil.AssertEquals(t, cpInfo.latestFileFormat, conf.format)
+	blkfileMgrWrapper.addBlocks(blocks[5:10])
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks[:10])
+	blkfileMgrWrapper.close()
+	env.provider.Close()
+
+	// and back to the legacy format
+	env = newTestEnv(t, legacyConf)
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, "testLedger")
+	testutil.AssertEquals(t, blkfileMgrWrapper.blockfileMgr.cpInfo.latestFileChunkSuffixNum, 2)
+	blkfileMgrWrapper.addBlocks(blocks[10:])
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+	blkfileMgrWrapper.close()
+	env.provider.Close()
+
+	inconsistencies, err := VerifyBlockStore(legacyConf.blockStorageDir, "testLedger", env.provider.indexConfig,
+		func(block *common.Block) error { return nil })
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(inconsistencies), 0)
+	cpInfo, err = constructCheckpointInfoFromBlockFiles(legacyConf.getLedgerBlockDir("testLedger"))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, cpInfo.lastBlockNumber, uint64(14))
+	testutil.AssertEquals(t, cpInfo.latestFileFormat, legacyFormat)
+}
+
+func TestBlockfileFormatEmptyStore(t *testing.T) {
+	legacyConf := NewConf(testPath(), 0)
+	env := newTestEnv(t, legacyConf)
+	defer env.Cleanup()
+	newTestBlockfileWrapper(env, "testLedger").close()
+	env.provider.Close()
+
+	// the empty block file is started over in the checksummed format
+	conf, err := NewConfWithFormat(legacyConf.blockStorageDir, 0, FormatChecksummed, CompressionNone)
+	testutil.AssertNoError(t, err, "")
+	env = newTestEnv(t, conf)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	cpInfo := blkfileMgrWrapper.blockfileMgr.cpInfo
+	testutil.AssertEquals(t, cpInfo.latestFileChunkSuffixNum, 0)
+	testutil.AssertEquals(t, cpInfo.isChainEmpty, true)
+	testutil.AssertEquals(t, cpInfo.latestFileFormat, conf.format)
+	blocks := testutil.ConstructTestBlocks(t, 3)
+	blkfileMgrWrapper.addBlocks(blocks)
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+}
+
+func TestBlockfileChecksumMismatch(t *testing.T) {
+	conf, err := NewConfWithFormat(testPath(), 0, FormatChecksummed, CompressionNone)
+	testutil.AssertNoError(t, err, "")
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 3)
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	blkfileMgrWrapper.addBlocks(blocks)
+
+	// flip a byte of the last block
+	file, err := os.OpenFile(deriveBlockfilePath(conf.getLedgerBlockDir("testLedger"), 0), os.O_RDWR, 0600)
+	testutil.AssertNoError(t, err, "")
+	offset := int64(blkfileMgrWrapper.blockfileMgr.cpInfo.latestFileChunksize - checksumLen - 10)
+	b := make([]byte, 1)
+	_, err = file.ReadAt(b, offset)
+	testutil.AssertNoError(t, err, "")
+	_, err = file.WriteAt([]byte{^b[0]}, offset)
+	testutil.AssertNoError(t, err, "")
+	file.Close()
+
+	_, err = blkfileMgrWrapper.blockfileMgr.retrieveBlockByNumber(2)
+	testutil.AssertError(t, err, "the block does not match its checksum")
+	testutil.AssertEquals(t, strings.Contains(err.Error(), ErrBlockChecksumMismatch.Error()), true)
+	_, err = blkfileMgrWrapper.blockfileMgr.retrieveBlockByNumber(1)
+	testutil.AssertNoError(t, err, "")
+}
+
+func TestIncompleteBlockfileHeader(t *testing.T) {
+	conf, err := NewConfWithFormat(testPath(), 0, FormatChecksummed, CompressionNone)
+	testutil.AssertNoError(t, err, "")
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 3)
+	addBlocksToStore(t, env, "testLedger", blocks[:2])
+	env.provider.Close()
+
+	// a crash happened while the next block file got started
+	rootDir := conf.getLedgerBlockDir("testLedger")
+	file, err := os.Create(deriveBlockfilePath(rootDir, 1))
+	testutil.AssertNoError(t, err, "")
+	_, err = file.Write(conf.format.header()[:4])
+	testutil.AssertNoError(t, err, "")
+	file.Close()
+	os.RemoveAll(conf.getIndexDir())
+
+	env = newTestEnv(t, conf)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	defer blkfileMgrWrapper.close()
+	cpInfo := blkfileMgrWrapper.blockfileMgr.cpInfo
+	testutil.AssertEquals(t, cpInfo.latestFileChunkSuffixNum, 1)
+	testutil.AssertEquals(t, cpInfo.latestFileChunksize, blockfileHeaderLen)
+	testutil.AssertEquals(t, cpInfo.lastBlockNumber, uint64(1))
+	blkfileMgrWrapper.addBlocks(blocks[2:])
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+}
+
+func TestCheckpointInfoWithoutFormat(t *testing.T) {
+	cpInfo := &checkpointInfo{latestFileChunkSuffixNum: 2, latestFileChunksize: 100, lastBlockNumber: 10}
+	b, err := cpInfo.marshal()
+	testutil.AssertNoError(t, err, "")
+	// the checkpoint info saved before the formats were introduced ends with the chain empty marker
+	legacyBytes := b[:len(b)-2]
+	cpInfo.latestFileFormat = blockfileFormat{checksummedFormatVersion, compressionSnappy}
+	testutil.AssertNoError(t, cpInfo.unmarshal(legacyBytes), "")
+	testutil.AssertEquals(t, cpInfo.latestFileFormat, legacyFormat)
+	testutil.AssertEquals(t, cpInfo.lastBlockNumber, uint64(10))
+
+	flp := &fileLocPointer{fileSuffixNum: 1, locPointer: locPointer{offset: 12, bytesLength: 30},
+		format: blockfileFormat{checksummedFormatVersion, compressionNone}, offsetInBlock: 50}
+	b, err = flp.marshal()
+	testutil.AssertNoError(t, err, "")
+	unmarshaled := &fileLocPointer{}
+	testutil.AssertNoError(t, unmarshaled.unmarshal(b), "")
+	testutil.AssertEquals(t, unmarshaled, flp)
+
+	// the location of a transaction of the legacy format records no format
+	flp = &fileLocPointer{fileSuffixNum: 1, locPointer: locPointer{offset: 12, bytesLength: 30}}
+	b, err = flp.marshal()
+	testutil.AssertNoError(t, err, "")
+	unmarshaled = &fileLocPointer{}
+	testutil.AssertNoError(t, unmarshaled.unmarshal(b), "")
+	testutil.AssertEquals(t, unmarshaled, flp)
+	testutil.AssertEquals(t, unmarshaled.inBlock(), false)
+}
+
+func testBlocksAndTransactions(t *testing.T, w *testBlockfileMgrWrapper, blocks []*common.Block) {
+	w.testGetBlockByHash(blocks)
+	w.testGetBlockByNumber(blocks, 0)
+	for blockNum, block := range blocks {
+		for txNum, txEnvelopeBytes := range block.Data.Data {
+			txEnvelope, err := putil.GetEnvelopeFromBlock(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "")
+			txID, err := extractTxID(txEnvelopeBytes)
+			testutil.AssertNoError(t, err, "")
+			txEnvelopeFromFileMgr, err := w.blockfileMgr.retrieveTransactionByID(txID)
+			testutil.AssertNoError(t, err, "")
+			testutil.AssertEquals(t, txEnvelopeFromFileMgr, txEnvelope)
+			txEnvelopeFromFileMgr, err = w.blockfileMgr.retrieveTransactionByBlockNumTranNum(uint64(blockNum), uint64(txNum))
+			testutil.AssertNoError(t, err, "")
+			testutil.AssertEquals(t, txEnvelopeFromFileMgr, txEnvelope)
+		}
+	}
+}
@@ -37,7 +37,7 @@ func constructCheckpointInfoFromBlockFiles(rootDir string) (*checkpointInfo, err
 	logger.Debugf("Last file number found = %d", lastFileNum)
 
 	if lastFileNum == -1 {
-		cpInfo := &checkpointInfo{0, 0, true, 0}
+		cpInfo := &checkpointInfo{isChainEmpty: true}
 		logger.Debugf("No block file found")
 		return cpInfo, nil
 	}
@@ -67,11 +67,17 @@ func constructCheckpointInfoFromBlockFiles(rootDir string) (*checkpointInfo, err
 		lastBlockNumber = lastBlock.Header.Number
 	}
 
+	latestFileFormat, err := getBlockfileFormat(rootDir, lastFileNum)
+	if err != nil {
+		return nil, err
+	}
+
 	cpInfo := &checkpointInfo{
 		lastBlockNumber:          lastBlockNumber,
 		latestFileChunksize:      int(endOffsetLastBlock),
 		latestFileChunkSuffixNum: lastFileNum,
 		isChainEmpty:             lastFileNum == 0 && numBlocksInFile == 0,
+		latestFileFormat:         latestFileFormat,
 	}
 	logger.Debugf("Checkpoint info constructed from file system = %s", spew.Sdump(cpInfo))
 	return cpInfo, nil
@@ -18,6 +18,7 @@ package fsblkstorage
 
 import (
 	"fmt"
+	"io"
 	"math"
 	"sync"
 	"sync/atomic"
@@ -53,6 +54,11 @@ type blockfileMgr struct {
 	cpInfoCond        *sync.Cond
 	currentFileWriter *blockfileWriter
 	bcInfo            atomic.Value
+	// fileLock is held by the reads that locate blocks through the index, and exclusively by the
+	// migration of a block file while the file and its index entries are replaced
+	fileLock      sync.RWMutex
+	migrationStop chan struct{}
+	migrationDone chan struct{}
 }
 
 /*
@@ -104,6 +110,13 @@ func newBlockfileMgr(id string, conf *Conf, indexConfig *blkstorage.IndexConfig,
 	if err != nil {
 		panic(fmt.Sprintf("Error: %s", err))
 	}
+	// Complete a migration or a rollback of the block files that may have been interrupted by a crash
+	if err := completePendingMigration(rootDir, indexStore); err != nil {
+		panic(fmt.Sprintf("Could not complete the pending migration of a block file: %s", err))
+	}
+	if err := completePendingRollback(rootDir, indexStore); err != nil {
+		panic(fmt.Sprintf("Could not complete the pending rollback of the block files: %s", err))
+	}
 	// Instantiate the manager, i.e. blockFileMgr structure
 	mgr := &blockfileMgr{rootDir: rootDir, conf: conf, db: indexStore}
 
@@ -172,9 +185,56 @@ func newBlockfileMgr(id string, conf *Conf, indexConfig *blkstorage.IndexConfig,
 			PreviousBlockHash: previousBlockHash}
 	}
 	mgr.bcInfo.Store(bcInfo)
+
+	// The blocks are appended in the configured format. The next blocks go to a new block file in the
+	// configured format, and the block files written before in another format are migrated lazily
+	if cpInfo.latestFileFormat != conf.format {
+		if cpInfo.latestFileChunksize <= len(cpInfo.latestFileFormat.header()) {
+			// the current block file holds no block, it is started over in the configured format
+			if err = mgr.startBlockfile(currentFileWriter); err != nil {
+				panic(fmt.Sprintf("Could not start current file in the configured format: %s", err))
+			}
+		} else {
+			logger.Infof("Appending the next blocks to a new block file in the [%s] format rather than the [%s] format",
+				conf.format, cpInfo.latestFileFormat)
+			mgr.moveToNextFile()
+		}
+	}
+	mgr.migrationStop = make(chan struct{})
+	mgr.migrationDone = make(chan struct{})
+	go mgr.migrateBlockfiles(mgr.cpInfo.latestFileChunkSuffixNum)
 	return mgr
 }
 
+// startBlockfile empties the block file of the writer, which holds no block, and writes the header of the
+// configured format to it. The checkpoint info is updated accordingly unless the writer is not the one of
+// the current block file
+func (mgr *blockfileMgr) startBlockfile(writer *blockfileWriter) error {
+	if err := writer.truncateFile(0); err != nil {
+		return err
+	}
+	header := mgr.conf.format.header()
+	if len(header) > 0 {
+		if err := writer.append(header, true); err != nil {
+			return err
+		}
+	}
+	if writer != mgr.currentFileWriter {
+		return nil
+	}
+	cpInfo := &checkpointInfo{
+		latestFileChunkSuffixNum: mgr.cpInfo.latestFileChunkSuffixNum,
+		latestFileChunksize:      len(header),
+		isChainEmpty:             mgr.cpInfo.isChainEmpty,
+		lastBlockNumber:          mgr.cpInfo.lastBlockNumber,
+		latestFileFormat:         mgr.conf.format}
+	if err := mgr.saveCurrentInfo(cpInfo, true); err != nil {
+		return err
+	}
+	mgr.updateCheckpoint(cpInfo)
+	return nil
+}
+
 //cp = checkpointInfo, from the database gets the file suffix and the size of
 // the file of where the last block was written.  Also retrieves contains the
 // last block number that was written.  At init
@@ -191,7 +251,14 @@ func syncCPInfoFromFS(rootDir string, cpInfo *checkpointInfo) {
 	//Test is !exists because when file number is first used the file does not exist yet
 	//checks that the file exists and that the size of the file is what is stored in cpinfo
 	//status of file [/tmp/tests/ledger/blkstorage/fsblkstorage/blocks/blockfile_000000]: exists=[false], size=[0]
-	if !exists || int(size) == cpInfo.latestFileChunksize {
+	if !exists {
+		return
+	}
+	// the format recorded may be missing if a crash happened right after the file got started
+	if cpInfo.latestFileFormat, err = getBlockfileFormat(rootDir, cpInfo.latestFileChunkSuffixNum); err != nil {
+		panic(fmt.Sprintf("Could not read format of file [%s]: %s", filePath, err))
+	}
+	if int(size) == cpInfo.latestFileChunksize {
 		// check point info is in sync with the file on disk
 		return
 	}
@@ -220,14 +287,21 @@ func deriveBlockfilePath(rootDir string, suffixNum int) string {
 }
 
 func (mgr *blockfileMgr) close() {
+	select {
+	case <-mgr.migrationStop:
+	default:
+		close(mgr.migrationStop)
+	}
+	<-mgr.migrationDone
 	mgr.currentFileWriter.close()
 }
 
 func (mgr *blockfileMgr) moveToNextFile() {
 	cpInfo := &checkpointInfo{
 		latestFileChunkSuffixNum: mgr.cpInfo.latestFileChunkSuffixNum + 1,
-		latestFileChunksize:      0,
-		lastBlockNumber:          mgr.cpInfo.lastBlockNumber}
+		latestFileChunksize:      len(mgr.conf.format.header()),
+		lastBlockNumber:          mgr.cpInfo.lastBlockNumber,
+		latestFileFormat:         mgr.conf.format}
 
 	nextFileWriter, err := newBlockfileWriter(
 		deriveBlockfilePath(mgr.rootDir, cpInfo.latestFileChunkSuffixNum))
@@ -235,6 +309,10 @@ func (mgr *blockfileMgr) moveToNextFile() {
 	if err != nil {
 		panic(fmt.Sprintf("Could not open writer to next file: %s", err))
 	}
+	// the next file may hold a partial header if a crash happened while it was started
+	if err = mgr.startBlockfile(nextFileWriter); err != nil {
+		panic(fmt.Sprintf("Could not start next file: %s", err))
+	}
 	mgr.currentFileWriter.close()
 	err = mgr.saveCurrentInfo(cpInfo, true)
 	if err != nil {
@@ -256,25 +334,21 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 	//Get the location / offset where each transaction starts in the block and where the block ends
 	txOffsets := info.txOffsets
 	currentOffset := mgr.cpInfo.latestFileChunksize
+	//The record holds the block bytes, prefixed by their length and possibly compressed and checksummed
+	record, storedBytesOffset, err := mgr.conf.format.encodeRecord(blockBytes)
 	if err != nil {
-		return fmt.Errorf("Error while serializing block: %s", err)
+		return fmt.Errorf("Error while encoding block: %s", err)
 	}
-	blockBytesLen := len(blockBytes)
-	blockBytesEncodedLen := proto.EncodeVarint(uint64(blockBytesLen))
-	totalBytesToAppend := blockBytesLen + len(blockBytesEncodedLen)
+	totalBytesToAppend := len(record)
 
 	//Determine if we need to start a new file since the size of this block
 	//exceeds the amount of space left in the current file
 	if currentOffset+totalBytesToAppend > mgr.conf.maxBlockfileSize {
 		mgr.moveToNextFile()
-		currentOffset = 0
-	}
-	//append blockBytesEncodedLen to the file
-	err = mgr.currentFileWriter.append(blockBytesEncodedLen, false)
-	if err == nil {
-		//append the actual block bytes to the file
-		err = mgr.currentFileWriter.append(blockBytes, true)
+		currentOffset = mgr.cpInfo.latestFileChunksize
 	}
+	//append the record to the file
+	err = mgr.currentFileWriter.append(record, true)
 	if err != nil {
 		truncateErr := mgr.currentFileWriter.truncateFile(mgr.cpInfo.latestFileChunksize)
 		if truncateErr != nil {
@@ -289,7 +363,8 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 		latestFileChunkSuffixNum: currentCPInfo.latestFileChunkSuffixNum,
 		latestFileChunksize:      currentCPInfo.latestFileChunksize + totalBytesToAppend,
 		isChainEmpty:             false,
-		lastBlockNumber:          block.Header.Number}
+		lastBlockNumber:          block.Header.Number,
+		latestFileFormat:         currentCPInfo.latestFileFormat}
 	//save the checkpoint information in the database
 	if err = mgr.saveCurrentInfo(newCPInfo, false); err != nil {
 		truncateErr := mgr.currentFileWriter.truncateFile(currentCPInfo.latestFileChunksize)
@@ -299,17 +374,17 @@ func (mgr *blockfileMgr) addBlock(block *common.Block) error {
 		return fmt.Errorf("Error while saving current file info to db: %s", err)
 	}
 
-	//Index block file location pointer updated with file suffex and offset for the new block
-	blockFLP := &fileLocPointer{fileSuffixNum: newCPInfo.latestFileChunkSuffixNum}
-	blockFLP.offset = currentOffset
-	// shift the txoffset because we prepend length of bytes before block bytes
-	for _, txOffset := range txOffsets {
-		txOffset.loc.offset += len(blockBytesEncodedLen)
-	}
+	//Index block file location updated with file suffix and offset for the new block
+	placement := &blockPlacementInfo{
+		fileNum:          newCPInfo.latestFileChunkSuffixNum,
+		blockStartOffset: int64(currentOffset),
+		blockBytesOffset: int64(currentOffset + storedBytesOffset),
+		blockEndOffset:   int64(newCPInfo.latestFileChunksize),
+		format:           newCPInfo.latestFileFormat}
 	//save the index in the database
 	mgr.index.indexBlock(&blockIdxInfo{
 		blockNum: block.Header.Number, blockHash: blockHash,
-		flp: blockFLP, txOffsets: txOffsets, metadata: block.Metadata})
+		placement: placement, txOffsets: txOffsets, metadata: block.Metadata})
 
 	//update the checkpoint info (for storage) and the blockchain info (for APIs) in the manager
 	mgr.updateCheckpoint(newCPInfo)
@@ -392,18 +467,10 @@ func (mgr *blockfileMgr) syncIndex() error {
 			return err
 		}
 
-		//The blockStartOffset will get applied to the txOffsets prior to indexing within indexBlock(),
-		//therefore just shift by the difference between blockBytesOffset and blockStartOffset
-		numBytesToShift := int(blockPlacementInfo.blockBytesOffset - blockPlacementInfo.blockStartOffset)
-		for _, offset := range info.txOffsets {
-			offset.loc.offset += numBytesToShift
-		}
-
 		//Update the blockIndexInfo with what was actually stored in file system
 		blockIdxInfo.blockHash = info.blockHeader.Hash()
 		blockIdxInfo.blockNum = info.blockHeader.Number
-		blockIdxInfo.flp = &fileLocPointer{fileSuffixNum: blockPlacementInfo.fileNum,
-			locPointer: locPointer{offset: int(blockPlacementInfo.blockStartOffset)}}
+		blockIdxInfo.placement = blockPlacementInfo
 		blockIdxInfo.txOffsets = info.txOffsets
 		blockIdxInfo.metadata = info.metadata
 
@@ -443,6 +510,8 @@ func (mgr *blockfileMgr) updateBlockchainInfo(latestBlockHash []byte, latestBloc
 
 func (mgr *blockfileMgr) retrieveBlockByHash(blockHash []byte) (*common.Block, error) {
 	logger.Debugf("retrieveBlockByHash() - blockHash = [%#v]", blockHash)
+	mgr.fileLock.RLock()
+	defer mgr.fileLock.RUnlock()
 	loc, err := mgr.index.getBlockLocByHash(blockHash)
 	if err != nil {
 		return nil, err
@@ -452,6 +521,8 @@ func (mgr *blockfileMgr) retrieveBlockByHash(blockHash []byte) (*common.Block, e
 
 func (mgr *blockfileMgr) retrieveBlockByNumber(blockNum uint64) (*common.Block, error) {
 	logger.Debugf("retrieveBlockByNumber() - blockNum = [%d]", blockNum)
+	mgr.fileLock.RLock()
+	defer mgr.fileLock.RUnlock()
 
 	// interpret math.MaxUint64 as a request for last block
 	if blockNum == math.MaxUint64 {
@@ -467,6 +538,8 @@ func (mgr *blockfileMgr) retrieveBlockByNumber(blockNum uint64) (*common.Block,
 
 func (mgr *blockfileMgr) retrieveBlockByTxID(txID string) (*common.Block, error) {
 	logger.Debugf("retrieveBlockByTxID() - txID = [%s]", txID)
+	mgr.fileLock.RLock()
+	defer mgr.fileLock.RUnlock()
 
 	loc, err := mgr.index.getBlockLocByTxID(txID)
 
@@ -483,6 +556,8 @@ func (mgr *blockfileMgr) retrieveTxValidationCodeByTxID(txID string) (peer.TxVal
 
 func (mgr *blockfileMgr) retrieveBlockHeaderByNumber(blockNum uint64) (*common.BlockHeader, error) {
 	logger.Debugf("retrieveBlockHeaderByNumber() - blockNum = [%d]", blockNum)
+	mgr.fileLock.RLock()
+	defer mgr.fileLock.RUnlock()
 	loc, err := mgr.index.getBlockLocByBlockNum(blockNum)
 	if err != nil {
 		return nil, err
@@ -504,6 +579,8 @@ func (mgr *blockfileMgr) retrieveBlocks(startNum uint64) (*blocksItr, error) {
 
 func (mgr *blockfileMgr) retrieveTransactionByID(txID string) (*common.Envelope, error) {
 	logger.Debugf("retrieveTransactionByID() - txId = [%s]", txID)
+	mgr.fileLock.RLock()
+	defer mgr.fileLock.RUnlock()
 	loc, err := mgr.index.getTxLoc(txID)
 	if err != nil {
 		return nil, err
@@ -513,6 +590,8 @@ func (mgr *blockfileMgr) retrieveTransactionByID(txID string) (*common.Envelope,
 
 func (mgr *blockfileMgr) retrieveTransactionByBlockNumTranNum(blockNum uint64, tranNum uint64) (*common.Envelope, error) {
 	logger.Debugf("retrieveTransactionByBlockNumTranNum() - blockNum = [%d], tranNum = [%d]", blockNum, tranNum)
+	mgr.fileLock.RLock()
+	defer mgr.fileLock.RUnlock()
 	loc, err := mgr.index.getTXLocByBlockNumTranNum(blockNum, tranNum)
 	if err != nil {
 		return nil, err
@@ -536,7 +615,12 @@ func (mgr *blockfileMgr) fetchTransactionEnvelope(lp *fileLocPointer) (*common.E
 	logger.Debugf("Entering fetchTransactionEnvelope() %v\n", lp)
 	var err error
 	var txEnvelopeBytes []byte
-	if txEnvelopeBytes, err = mgr.fetchRawBytes(lp); err != nil {
+	if lp.inBlock() {
+		txEnvelopeBytes, err = mgr.fetchTxBytesFromBlock(lp)
+	} else {
+		txEnvelopeBytes, err = mgr.fetchRawBytes(lp)
+	}
+	if err != nil {
 		return nil, err
 	}
 	_, n := proto.DecodeVarint(txEnvelopeBytes)
@@ -556,6 +640,19 @@ func (mgr *blockfileMgr) fetchBlockBytes(lp *fileLocPointer) ([]byte, error) {
 	return b, nil
 }
 
+// fetchTxBytesFromBlock reads the bytes of a transaction from the bytes of its block,
+// which are possibly compressed in the file
+func (mgr *blockfileMgr) fetchTxBytesFromBlock(lp *fileLocPointer) ([]byte, error) {
+	blockBytes, err := mgr.fetchBlockBytes(lp)
+	if err != nil {
+		return nil, err
+	}
+	if blockBytes == nil || lp.offsetInBlock+lp.bytesLength > len(blockBytes) {
+		return nil, fmt.Errorf("Transaction at [%s] lies beyond the bytes of its block", lp)
+	}
+	return blockBytes[lp.offsetInBlock : lp.offsetInBlock+lp.bytesLength], nil
+}
+
 func (mgr *blockfileMgr) fetchRawBytes(lp *fileLocPointer) ([]byte, error) {
 	filePath := deriveBlockfilePath(mgr.rootDir, lp.fileSuffixNum)
 	reader, err := newBlockfileReader(filePath)
@@ -633,6 +730,7 @@ type checkpointInfo struct {
 	latestFileChunksize      int
 	isChainEmpty             bool
 	lastBlockNumber          uint64
+	latestFileFormat         blockfileFormat
 }
 
 func (i *checkpointInfo) marshal() ([]byte, error) {
@@ -654,6 +752,12 @@ func (i *checkpointInfo) marshal() ([]byte, error) {
 	if err = buffer.EncodeVarint(chainEmptyMarker); err != nil {
 		return nil, err
 	}
+	if err = buffer.EncodeVarint(uint64(i.latestFileFormat.version)); err != nil {
+		return nil, err
+	}
+	if err = buffer.EncodeVarint(uint64(i.latestFileFormat.compression)); err != nil {
+		return nil, err
+	}
 	return buffer.Bytes(), nil
 }
 
@@ -681,10 +785,24 @@ func (i *checkpointInfo) unmarshal(b []byte) error {
 		return err
 	}
 	i.isChainEmpty = chainEmptyMarker == 1
+
+	// the checkpoint info saved before the block file formats were introduced records no format
+	i.latestFileFormat = legacyFormat
+	if val, err = buffer.DecodeVarint(); err == io.ErrUnexpectedEOF {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	i.latestFileFormat.version = byte(val)
+	if val, err = buffer.DecodeVarint(); err != nil {
+		return err
+	}
+	i.latestFileFormat.compression = byte(val)
 	return nil
 }
 
 func (i *checkpointInfo) String() string {
-	return fmt.Sprintf("latestFileChunkSuffixNum=[%d], latestFileChunksize=[%d], isChainEmpty=[%t], lastBlockNumber=[%d]",
-		i.latestFileChunkSuffixNum, i.latestFileChunksize, i.isChainEmpty, i.lastBlockNumber)
+	return fmt.Sprintf("latestFileChunkSuffixNum=[%d], latestFileChunksize=[%d], isChainEmpty=[%t], lastBlockNumber=[%d], latestFileFormat=[%s]",
+		i.latestFileChunkSuffixNum, i.latestFileChunksize, i.isChainEmpty, i.lastBlockNumber, i.latestFileFormat)
 }
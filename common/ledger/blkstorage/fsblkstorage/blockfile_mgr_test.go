@@ -77,7 +77,8 @@ func testBlockfileMgrCrashDuringWriting(t *testing.T, numBlocksBeforeCheckpoint
 		currentCPInfo.latestFileChunkSuffixNum,
 		currentCPInfo.latestFileChunksize,
 		currentCPInfo.isChainEmpty,
-		currentCPInfo.lastBlockNumber}
+		currentCPInfo.lastBlockNumber,
+		currentCPInfo.latestFileFormat}
 
 	// add blocks after cp
 	blkfileMgrWrapper.addBlocks(blocksAfterCP)
@@ -20,6 +20,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
@@ -46,6 +47,7 @@ var errIndexEmpty = errors.New("NoBlockIndexed")
 type index interface {
 	getLastBlockIndexed() (uint64, error)
 	indexBlock(blockIdxInfo *blockIdxInfo) error
+	relocateBlock(blockIdxInfo *blockIdxInfo, newPlacement *blockPlacementInfo, batch *leveldbhelper.UpdateBatch) error
 	getBlockLocByHash(blockHash []byte) (*fileLocPointer, error)
 	getBlockLocByBlockNum(blockNum uint64) (*fileLocPointer, error)
 	getTxLoc(txID string) (*fileLocPointer, error)
@@ -57,7 +59,8 @@ type index interface {
 type blockIdxInfo struct {
 	blockNum  uint64
 	blockHash []byte
-	flp       *fileLocPointer
+	placement *blockPlacementInfo
+	// txOffsets locate the transactions within the block bytes
 	txOffsets []*txindexInfo
 	metadata  *common.BlockMetadata
 }
@@ -96,7 +99,7 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 		return nil
 	}
 	logger.Debugf("Indexing block [%s]", blockIdxInfo)
-	flp := blockIdxInfo.flp
+	flp := blockIdxInfo.placement.blockLocPointer()
 	txOffsets := blockIdxInfo.txOffsets
 	txsfltr := ledgerUtil.TxValidationFlags(blockIdxInfo.metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
 	batch := leveldbhelper.NewUpdateBatch()
@@ -118,7 +121,7 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	//Index3 Used to find a transaction by it's transaction id
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxID]; ok {
 		for _, txoffset := range txOffsets {
-			txFlp := newFileLocationPointer(flp.fileSuffixNum, flp.offset, txoffset.loc)
+			txFlp := blockIdxInfo.placement.txLocPointer(txoffset.loc)
 			logger.Debugf("Adding txLoc [%s] for tx ID: [%s] to index", txFlp, txoffset.txID)
 			txFlpBytes, marshalErr := txFlp.marshal()
 			if marshalErr != nil {
@@ -131,7 +134,7 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	//Index4 - Store BlockNumTranNum will be used to query history data
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNumTranNum]; ok {
 		for txIterator, txoffset := range txOffsets {
-			txFlp := newFileLocationPointer(flp.fileSuffixNum, flp.offset, txoffset.loc)
+			txFlp := blockIdxInfo.placement.txLocPointer(txoffset.loc)
 			logger.Debugf("Adding txLoc [%s] for tx number:[%d] ID: [%s] to blockNumTranNum index", txFlp, txIterator, txoffset.txID)
 			txFlpBytes, marshalErr := txFlp.marshal()
 			if marshalErr != nil {
@@ -163,6 +166,63 @@ func (index *blockIndex) indexBlock(blockIdxInfo *blockIdxInfo) error {
 	return nil
 }
 
+// relocateBlock adds to the batch the updates of the index entries of the block of blockIdxInfo, which is
+// moved to newPlacement. The entries of a transaction ID are only updated when they point to this block,
+// since a transaction ID that appears in several blocks is indexed to the last one
+func (index *blockIndex) relocateBlock(blockIdxInfo *blockIdxInfo, newPlacement *blockPlacementInfo,
+	batch *leveldbhelper.UpdateBatch) error {
+	oldFlpBytes, err := blockIdxInfo.placement.blockLocPointer().marshal()
+	if err != nil {
+		return err
+	}
+	flpBytes, err := newPlacement.blockLocPointer().marshal()
+	if err != nil {
+		return err
+	}
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockHash]; ok {
+		batch.Put(constructBlockHashKey(blockIdxInfo.blockHash), flpBytes)
+	}
+	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNum]; ok {
+		batch.Put(constructBlockNumKey(blockIdxInfo.blockNum), flpBytes)
+	}
+	for txIterator, txoffset := range blockIdxInfo.txOffsets {
+		oldTxFlpBytes, err := blockIdxInfo.placement.txLocPointer(txoffset.loc).marshal()
+		if err != nil {
+			return err
+		}
+		txFlpBytes, err := newPlacement.txLocPointer(txoffset.loc).marshal()
+		if err != nil {
+			return err
+		}
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrTxID]; ok {
+			if err = index.relocateEntry(constructTxIDKey(txoffset.txID), oldTxFlpBytes, txFlpBytes, batch); err != nil {
+				return err
+			}
+		}
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockNumTranNum]; ok {
+			batch.Put(constructBlockNumTranNumKey(blockIdxInfo.blockNum, uint64(txIterator)), txFlpBytes)
+		}
+		if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockTxID]; ok {
+			if err = index.relocateEntry(constructBlockTxIDKey(txoffset.txID), oldFlpBytes, flpBytes, batch); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relocateEntry adds to the batch the update of the given entry to newValue if its value is oldValue
+func (index *blockIndex) relocateEntry(key, oldValue, newValue []byte, batch *leveldbhelper.UpdateBatch) error {
+	value, err := index.db.Get(key)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(value, oldValue) {
+		batch.Put(key, newValue)
+	}
+	return nil
+}
+
 func (index *blockIndex) getBlockLocByHash(blockHash []byte) (*fileLocPointer, error) {
 	if _, ok := index.indexItemsMap[blkstorage.IndexableAttrBlockHash]; !ok {
 		return nil, blkstorage.ErrAttrNotIndexed
@@ -314,6 +374,11 @@ func (lp *locPointer) String() string {
 type fileLocPointer struct {
 	fileSuffixNum int
 	locPointer
+	// format is recorded for the transactions of the block files of the checksummed format, where the
+	// block bytes may be compressed. Such a transaction is located at offsetInBlock within the bytes
+	// of the block stored at offset, while the other ones are read straight from the block file
+	format        blockfileFormat
+	offsetInBlock int
 }
 
 func newFileLocationPointer(fileSuffixNum int, beginningOffset int, relativeLP *locPointer) *fileLocPointer {
@@ -337,6 +402,14 @@ func (flp *fileLocPointer) marshal() ([]byte, error) {
 	if e != nil {
 		return nil, e
 	}
+	if !flp.inBlock() {
+		return buffer.Bytes(), nil
+	}
+	for _, val := range []uint64{uint64(flp.format.version), uint64(flp.format.compression), uint64(flp.offsetInBlock)} {
+		if e = buffer.EncodeVarint(val); e != nil {
+			return nil, e
+		}
+	}
 	return buffer.Bytes(), nil
 }
 
@@ -358,10 +431,37 @@ func (flp *fileLocPointer) unmarshal(b []byte) error {
 		return e
 	}
 	flp.bytesLength = int(i)
+	// the format and the offset in the block are only recorded for the checksummed format
+	flp.format = legacyFormat
+	i, e = buffer.DecodeVarint()
+	if e == io.ErrUnexpectedEOF {
+		return nil
+	}
+	if e != nil {
+		return e
+	}
+	flp.format.version = byte(i)
+	if i, e = buffer.DecodeVarint(); e != nil {
+		return e
+	}
+	flp.format.compression = byte(i)
+	if i, e = buffer.DecodeVarint(); e != nil {
+		return e
+	}
+	flp.offsetInBlock = int(i)
 	return nil
 }
 
+// inBlock tells whether the location is the one of a transaction within the bytes of its block
+func (flp *fileLocPointer) inBlock() bool {
+	return flp.format.checksummed()
+}
+
 func (flp *fileLocPointer) String() string {
+	if flp.inBlock() {
+		return fmt.Sprintf("fileSuffixNum=%d, %s, format=%s, offsetInBlock=%d",
+			flp.fileSuffixNum, flp.locPointer.String(), flp.format, flp.offsetInBlock)
+	}
 	return fmt.Sprintf("fileSuffixNum=%d, %s", flp.fileSuffixNum, flp.locPointer.String())
 }
 
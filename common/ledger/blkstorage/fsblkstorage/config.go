@@ -30,6 +30,8 @@ const (
 type Conf struct {
 	blockStorageDir  string
 	maxBlockfileSize int
+	// format is the format of the new block files
+	format blockfileFormat
 }
 
 // NewConf constructs new `Conf`.
@@ -38,7 +40,20 @@ func NewConf(blockStorageDir string, maxBlockfileSize int) *Conf {
 	if maxBlockfileSize <= 0 {
 		maxBlockfileSize = defaultMaxBlockfileSize
 	}
-	return &Conf{blockStorageDir, maxBlockfileSize}
+	return &Conf{blockStorageDir: blockStorageDir, maxBlockfileSize: maxBlockfileSize}
+}
+
+// NewConfWithFormat constructs new `Conf` whose new block files are written in the given format,
+// FormatLegacy or FormatChecksummed, the blocks of the checksummed format being compressed
+// according to compression, CompressionNone or CompressionSnappy
+func NewConfWithFormat(blockStorageDir string, maxBlockfileSize int, format, compression string) (*Conf, error) {
+	blockfileFormat, err := newBlockfileFormat(format, compression)
+	if err != nil {
+		return nil, err
+	}
+	conf := NewConf(blockStorageDir, maxBlockfileSize)
+	conf.format = blockfileFormat
+	return conf, nil
 }
 
 func (conf *Conf) getIndexDir() string {
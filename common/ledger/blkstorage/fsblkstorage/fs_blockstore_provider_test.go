@@ -55,6 +55,35 @@ func TestMultipleBlockStores(t *testing.T) {
 	checkWithWrongInputs(t, store2, 10)
 }
 
+func TestDropBlockStore(t *testing.T) {
+	env := newTestEnv(t, NewConf(testPath(), 0))
+	defer env.Cleanup()
+
+	provider := env.provider
+	blocks1 := testutil.ConstructTestBlocks(t, 5)
+	blocks2 := testutil.ConstructTestBlocks(t, 5)
+	addBlocksToStore(t, env, "ledger1", blocks1)
+	addBlocksToStore(t, env, "ledger2", blocks2)
+
+	testutil.AssertNoError(t, provider.Drop("ledger1"), "")
+	// dropping again is harmless
+	testutil.AssertNoError(t, provider.Drop("ledger1"), "")
+	exists, err := provider.Exists("ledger1")
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, false)
+
+	store1, _ := provider.OpenBlockStore("ledger1")
+	defer store1.Shutdown()
+	bcInfo, _ := store1.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(0))
+	_, err = store1.RetrieveBlockByHash(blocks1[2].Header.Hash())
+	testutil.AssertError(t, err, "the index of the dropped ledger should be gone")
+
+	store2, _ := provider.OpenBlockStore("ledger2")
+	defer store2.Shutdown()
+	checkBlocks(t, blocks2, store2)
+}
+
 func checkBlocks(t *testing.T, expectedBlocks []*common.Block, store blkstorage.BlockStore) {
 	bcInfo, _ := store.GetBlockchainInfo()
 	testutil.AssertEquals(t, bcInfo.Height, uint64(len(expectedBlocks)))
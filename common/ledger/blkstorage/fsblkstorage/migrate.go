@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+)
+
+/*
+The block files that are in another format than the configured one are migrated lazily: once the
+block store is opened, a background goroutine rewrites them in the configured format, one file at a
+time, while the blocks keep being appended to the current block file. A block file is migrated in
+the following steps, so that a crash at any point leaves the block store consistent
+  *)  The blocks of the file are written in the configured format to a migrated file next to it
+  *)  The index entries of the blocks are moved to the migrated file, in the same batch as a marker
+      of the pending migration
+  *)  The migrated file replaces the block file and the marker is removed. This step is completed
+      the next time the block store is opened, should a crash happen before the marker is removed
+The reads that locate blocks through the index hold the file lock of the manager, so that the
+index entries and the block files they point to are replaced at once.
+*/
+
+// migrationPendingKey marks the migration of a block file whose index entries point
+// to the migrated file already, but which has not been replaced by it yet
+var migrationPendingKey = []byte("migrationPending")
+
+const migratedBlockfilePrefix = "migrated_"
+
+var errMigrationStopped = errors.New("block file migration stopped")
+
+// blockRelocation records where a block is moved by the migration of its block file
+type blockRelocation struct {
+	blockIdxInfo *blockIdxInfo
+	newPlacement *blockPlacementInfo
+}
+
+func deriveMigratedBlockfilePath(rootDir string, suffixNum int) string {
+	return rootDir + "/" + migratedBlockfilePrefix + blockfilePrefix + fmt.Sprintf("%06d", suffixNum)
+}
+
+// migrateBlockfiles migrates the block files that precede the block file lastFileNum
+// and are in another format than the configured one
+func (mgr *blockfileMgr) migrateBlockfiles(lastFileNum int) {
+	defer close(mgr.migrationDone)
+	for fileNum := 0; fileNum < lastFileNum; fileNum++ {
+		format, err := getBlockfileFormat(mgr.rootDir, fileNum)
+		if err != nil {
+			logger.Errorf("Could not read format of block file [%d], block files are not migrated: %s", fileNum, err)
+			return
+		}
+		if format == mgr.conf.format {
+			continue
+		}
+		logger.Infof("Migrating block file [%d] from the [%s] format to the [%s] format", fileNum, format, mgr.conf.format)
+		if err = mgr.migrateBlockfile(fileNum); err != nil {
+			if err != errMigrationStopped {
+				logger.Errorf("Could not migrate block file [%d], block files are not migrated: %s", fileNum, err)
+			}
+			return
+		}
+	}
+}
+
+// migrateBlockfile rewrites the given block file in the configured format
+func (mgr *blockfileMgr) migrateBlockfile(fileNum int) error {
+	migratedPath := deriveMigratedBlockfilePath(mgr.rootDir, fileNum)
+	relocations, err := mgr.writeMigratedBlockfile(fileNum, migratedPath)
+	if err != nil {
+		os.Remove(migratedPath)
+		return err
+	}
+
+	mgr.fileLock.Lock()
+	defer mgr.fileLock.Unlock()
+	batch := leveldbhelper.NewUpdateBatch()
+	for _, relocation := range relocations {
+		if err = mgr.index.relocateBlock(relocation.blockIdxInfo, relocation.newPlacement, batch); err != nil {
+			os.Remove(migratedPath)
+			return err
+		}
+	}
+	batch.Put(migrationPendingKey, proto.EncodeVarint(uint64(fileNum)))
+	if err = mgr.db.WriteBatch(batch, true); err != nil {
+		os.Remove(migratedPath)
+		return err
+	}
+	return completePendingMigration(mgr.rootDir, mgr.db)
+}
+
+// writeMigratedBlockfile writes the blocks of the given block file in the configured format
+// to the file migratedPath, and returns where the blocks are moved
+func (mgr *blockfileMgr) writeMigratedBlockfile(fileNum int, migratedPath string) ([]*blockRelocation, error) {
+	// a migration interrupted by a crash leaves a partially migrated file behind
+	if err := os.Remove(migratedPath); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	stream, err := newBlockfileStream(mgr.rootDir, fileNum, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.close()
+	writer, err := newBlockfileWriter(migratedPath)
+	if err != nil {
+		return nil, err
+	}
+	defer writer.close()
+
+	header := mgr.conf.format.header()
+	if err = writer.append(header, false); err != nil {
+		return nil, err
+	}
+	offset := len(header)
+	var relocations []*blockRelocation
+	for {
+		select {
+		case <-mgr.migrationStop:
+			return nil, errMigrationStopped
+		default:
+		}
+		blockBytes, placement, err := stream.nextBlockBytesAndPlacementInfo()
+		if err != nil {
+			return nil, err
+		}
+		if blockBytes == nil {
+			break
+		}
+		info, err := extractSerializedBlockInfo(blockBytes)
+		if err != nil {
+			return nil, err
+		}
+		record, storedBytesOffset, err := mgr.conf.format.encodeRecord(blockBytes)
+		if err != nil {
+			return nil, err
+		}
+		if err = writer.append(record, false); err != nil {
+			return nil, err
+		}
+		relocations = append(relocations, &blockRelocation{
+			blockIdxInfo: &blockIdxInfo{
+				blockNum:  info.blockHeader.Number,
+				blockHash: info.blockHeader.Hash(),
+				placement: placement,
+				txOffsets: info.txOffsets,
+				metadata:  info.metadata},
+			newPlacement: &blockPlacementInfo{
+				fileNum:          fileNum,
+				blockStartOffset: int64(offset),
+				blockBytesOffset: int64(offset + storedBytesOffset),
+				blockEndOffset:   int64(offset + len(record)),
+				format:           mgr.conf.format}})
+		offset += len(record)
+	}
+	if err = writer.file.Sync(); err != nil {
+		return nil, err
+	}
+	return relocations, nil
+}
+
+// completePendingMigration replaces the block file whose migration is recorded in the index db,
+// if any, by its migrated file. Every step is idempotent, so it can be repeated after a crash
+func completePendingMigration(rootDir string, db *leveldbhelper.DBHandle) error {
+	b, err := db.Get(migrationPendingKey)
+	if err != nil || b == nil {
+		return err
+	}
+	fileNum, _ := proto.DecodeVarint(b)
+	migratedPath := deriveMigratedBlockfilePath(rootDir, int(fileNum))
+	// the migrated file is gone if it replaced the block file already
+	if _, err = os.Stat(migratedPath); err == nil {
+		if err = os.Rename(migratedPath, deriveBlockfilePath(rootDir, int(fileNum))); err != nil {
+			return err
+		}
+		if err = syncDir(rootDir); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	logger.Infof("Block file [%d] in [%s] migrated", fileNum, rootDir)
+	return db.Delete(migrationPendingKey, true)
+}
+
+// syncDir flushes the entries of the given directory, so that a rename is durable
+func syncDir(dirPath string) error {
+	dir, err := os.Open(dirPath)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+func TestLazyBlockfileMigration(t *testing.T) {
+	legacyConf := NewConf(testPath(), 4096)
+	env := newTestEnv(t, legacyConf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 30)
+	addBlocksToStore(t, env, "testLedger", blocks[:20])
+	env.provider.Close()
+
+	conf, err := NewConfWithFormat(legacyConf.blockStorageDir, 4096, FormatChecksummed, CompressionSnappy)
+	testutil.AssertNoError(t, err, "")
+	env = newTestEnv(t, conf)
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	mgr := blkfileMgrWrapper.blockfileMgr
+	lastLegacyFileNum := mgr.cpInfo.latestFileChunkSuffixNum - 1
+	testutil.AssertEquals(t, lastLegacyFileNum > 0, true)
+
+	// the blocks are read and added while the block files are migrated
+	itr, err := mgr.retrieveBlocks(0)
+	testutil.AssertNoError(t, err, "")
+	for _, block := range blocks[:20] {
+		testBlocksAndTransactions(t, blkfileMgrWrapper, blocks[:20])
+		b, err := itr.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, b.(*common.Block).Header, block.Header)
+	}
+	blkfileMgrWrapper.addBlocks(blocks[20:])
+	<-mgr.migrationDone
+	itr.Close()
+
+	rootDir := conf.getLedgerBlockDir("testLedger")
+	for fileNum := 0; fileNum <= mgr.cpInfo.latestFileChunkSuffixNum; fileNum++ {
+		format, err := getBlockfileFormat(rootDir, fileNum)
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, format, conf.format)
+		_, err = os.Stat(deriveMigratedBlockfilePath(rootDir, fileNum))
+		testutil.AssertEquals(t, os.IsNotExist(err), true)
+	}
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+	blkfileMgrWrapper.close()
+	env.provider.Close()
+
+	inconsistencies, err := VerifyBlockStore(conf.blockStorageDir, "testLedger", env.provider.indexConfig,
+		func(block *common.Block) error { return nil })
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(inconsistencies), 0)
+}
+
+func TestPendingBlockfileMigration(t *testing.T) {
+	legacyConf := NewConf(testPath(), 4096)
+	env := newTestEnv(t, legacyConf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 20)
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	blkfileMgrWrapper.addBlocks(blocks)
+	blkfileMgrWrapper.close()
+
+	// a crash happens once the index entries of the first block file point to its migrated file
+	conf, err := NewConfWithFormat(legacyConf.blockStorageDir, 4096, FormatChecksummed, CompressionSnappy)
+	testutil.AssertNoError(t, err, "")
+	mgr := blkfileMgrWrapper.blockfileMgr
+	mgr.conf = conf
+	mgr.migrationStop = make(chan struct{})
+	rootDir := conf.getLedgerBlockDir("testLedger")
+	relocations, err := mgr.writeMigratedBlockfile(0, deriveMigratedBlockfilePath(rootDir, 0))
+	testutil.AssertNoError(t, err, "")
+	batch := leveldbhelper.NewUpdateBatch()
+	for _, relocation := range relocations {
+		testutil.AssertNoError(t, mgr.index.relocateBlock(relocation.blockIdxInfo, relocation.newPlacement, batch), "")
+	}
+	batch.Put(migrationPendingKey, proto.EncodeVarint(0))
+	testutil.AssertNoError(t, mgr.db.WriteBatch(batch, true), "")
+	env.provider.Close()
+
+	// the migration is completed when the block store is opened
+	env = newTestEnv(t, conf)
+	defer env.Cleanup()
+	blkfileMgrWrapper = newTestBlockfileWrapper(env, "testLedger")
+	format, err := getBlockfileFormat(rootDir, 0)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, format, conf.format)
+	pending, err := blkfileMgrWrapper.blockfileMgr.db.Get(migrationPendingKey)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, pending)
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+	blkfileMgrWrapper.close()
+}
+
+func TestBlockfileMigrationStopped(t *testing.T) {
+	legacyConf := NewConf(testPath(), 4096)
+	env := newTestEnv(t, legacyConf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 20)
+	addBlocksToStore(t, env, "testLedger", blocks)
+	env.provider.Close()
+
+	// the block store is closed while the block files are migrated
+	conf, err := NewConfWithFormat(legacyConf.blockStorageDir, 4096, FormatChecksummed, CompressionNone)
+	testutil.AssertNoError(t, err, "")
+	env = newTestEnv(t, conf)
+	newTestBlockfileWrapper(env, "testLedger").close()
+	env.provider.Close()
+
+	env = newTestEnv(t, conf)
+	defer env.Cleanup()
+	blkfileMgrWrapper := newTestBlockfileWrapper(env, "testLedger")
+	<-blkfileMgrWrapper.blockfileMgr.migrationDone
+	testBlocksAndTransactions(t, blkfileMgrWrapper, blocks)
+	blkfileMgrWrapper.close()
+}
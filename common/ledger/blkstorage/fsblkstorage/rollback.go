@@ -0,0 +1,276 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+)
+
+// rollbackPendingKey marks a rollback that has been started but not yet completed.
+// The rollback is completed the next time either Rollback is invoked or the block
+// store is opened, so that a crash halfway through never leaves an inconsistent store
+var rollbackPendingKey = []byte("rollbackPending")
+
+// deleteBatchSize is the number of index entries deleted in one leveldb batch
+const deleteBatchSize = 1000
+
+// rollbackInfo records the target of a rollback
+type rollbackInfo struct {
+	// fileSuffixNum and offset locate the end of the last retained block
+	fileSuffixNum int
+	offset        int64
+	blockNum      uint64
+}
+
+func (i *rollbackInfo) marshal() ([]byte, error) {
+	buffer := proto.NewBuffer([]byte{})
+	if err := buffer.EncodeVarint(uint64(i.fileSuffixNum)); err != nil {
+		return nil, err
+	}
+	if err := buffer.EncodeVarint(uint64(i.offset)); err != nil {
+		return nil, err
+	}
+	if err := buffer.EncodeVarint(i.blockNum); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+func (i *rollbackInfo) unmarshal(b []byte) error {
+	buffer := proto.NewBuffer(b)
+	var val uint64
+	var err error
+	if val, err = buffer.DecodeVarint(); err != nil {
+		return err
+	}
+	i.fileSuffixNum = int(val)
+	if val, err = buffer.DecodeVarint(); err != nil {
+		return err
+	}
+	i.offset = int64(val)
+	if i.blockNum, err = buffer.DecodeVarint(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ValidateRollbackParams checks that the block store of the given ledger
+// exists and contains the block blockNum
+func ValidateRollbackParams(blockStorageDir, ledgerID string, blockNum uint64) error {
+	conf := NewConf(blockStorageDir, 0)
+	p := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: conf.getIndexDir()})
+	defer p.Close()
+	_, err := loadRollbackCheckpointInfo(p.GetDBHandle(ledgerID), ledgerID, blockNum)
+	return err
+}
+
+// Rollback truncates the block store of the given ledger so that blockNum becomes
+// its last block. The block index is dropped and rebuilt from the block files the
+// next time the block store is opened. The block store must not be open
+func Rollback(blockStorageDir, ledgerID string, blockNum uint64) error {
+	conf := NewConf(blockStorageDir, 0)
+	p := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: conf.getIndexDir()})
+	defer p.Close()
+	db := p.GetDBHandle(ledgerID)
+	rootDir := conf.getLedgerBlockDir(ledgerID)
+
+	// finish the job of a previous migration or rollback which got interrupted, so that
+	// the migrated file of the block file cut by the rollback does not replace it afterwards
+	if err := completePendingMigration(rootDir, db); err != nil {
+		return err
+	}
+	if err := completePendingRollback(rootDir, db); err != nil {
+		return err
+	}
+
+	cpInfo, err := loadRollbackCheckpointInfo(db, ledgerID, blockNum)
+	if err != nil {
+		return err
+	}
+	if cpInfo.lastBlockNumber == blockNum {
+		logger.Infof("Block store of ledger [%s] already ends at block [%d]. Nothing to roll back", ledgerID, blockNum)
+		return nil
+	}
+
+	// The files are scanned rather than looking up the index
+	// since the index may well be what got corrupted
+	info, err := findBlockEnd(rootDir, cpInfo.latestFileChunkSuffixNum, blockNum)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Rolling back block store of ledger [%s] from block [%d] to block [%d]", ledgerID, cpInfo.lastBlockNumber, blockNum)
+	b, err := info.marshal()
+	if err != nil {
+		return err
+	}
+	if err = db.Put(rollbackPendingKey, b, true); err != nil {
+		return err
+	}
+	return completePendingRollback(rootDir, db)
+}
+
+func loadRollbackCheckpointInfo(db *leveldbhelper.DBHandle, ledgerID string, blockNum uint64) (*checkpointInfo, error) {
+	b, err := db.Get(blkMgrInfoKey)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("No block store found for ledger [%s]", ledgerID)
+	}
+	cpInfo := &checkpointInfo{}
+	if err = cpInfo.unmarshal(b); err != nil {
+		return nil, err
+	}
+	if cpInfo.isChainEmpty {
+		return nil, fmt.Errorf("Block store of ledger [%s] is empty", ledgerID)
+	}
+	if blockNum > cpInfo.lastBlockNumber {
+		return nil, fmt.Errorf("Block number [%d] is beyond the last block [%d] of ledger [%s]",
+			blockNum, cpInfo.lastBlockNumber, ledgerID)
+	}
+	return cpInfo, nil
+}
+
+// findBlockEnd scans the block files and returns where the block blockNum ends
+func findBlockEnd(rootDir string, endFileNum int, blockNum uint64) (*rollbackInfo, error) {
+	stream, err := newBlockStream(rootDir, 0, 0, endFileNum)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.close()
+
+	for {
+		blockBytes, placementInfo, err := stream.nextBlockBytesAndPlacementInfo()
+		if err != nil {
+			return nil, err
+		}
+		if blockBytes == nil {
+			return nil, fmt.Errorf("Block [%d] not found in the block files", blockNum)
+		}
+		info, err := extractSerializedBlockInfo(blockBytes)
+		if err != nil {
+			return nil, err
+		}
+		if info.blockHeader.Number == blockNum {
+			return &rollbackInfo{
+				fileSuffixNum: placementInfo.fileNum,
+				offset:        placementInfo.blockEndOffset,
+				blockNum:      blockNum,
+			}, nil
+		}
+	}
+}
+
+// completePendingRollback carries out the rollback recorded in the index db,
+// if any. Every step is idempotent, so it can be repeated after a crash
+func completePendingRollback(rootDir string, db *leveldbhelper.DBHandle) error {
+	b, err := db.Get(rollbackPendingKey)
+	if err != nil || b == nil {
+		return err
+	}
+	info := &rollbackInfo{}
+	if err = info.unmarshal(b); err != nil {
+		return err
+	}
+	logger.Infof("Truncating block files in [%s] after block [%d]", rootDir, info.blockNum)
+
+	// remove the block files that follow the one containing the last retained block...
+	filesInfo, err := ioutil.ReadDir(rootDir)
+	if err != nil {
+		return err
+	}
+	for _, fileInfo := range filesInfo {
+		name := fileInfo.Name()
+		if fileInfo.IsDir() || !isBlockFileName(name) {
+			continue
+		}
+		fileNum, err := strconv.Atoi(strings.TrimPrefix(name, blockfilePrefix))
+		if err != nil {
+			return err
+		}
+		if fileNum > info.fileSuffixNum {
+			if err = os.Remove(deriveBlockfilePath(rootDir, fileNum)); err != nil {
+				return err
+			}
+		}
+	}
+	// ...and cut that file right after the block
+	if err = os.Truncate(deriveBlockfilePath(rootDir, info.fileSuffixNum), info.offset); err != nil {
+		return err
+	}
+
+	latestFileFormat, err := getBlockfileFormat(rootDir, info.fileSuffixNum)
+	if err != nil {
+		return err
+	}
+	cpInfo := &checkpointInfo{
+		latestFileChunkSuffixNum: info.fileSuffixNum,
+		latestFileChunksize:      int(info.offset),
+		isChainEmpty:             false,
+		lastBlockNumber:          info.blockNum,
+		latestFileFormat:         latestFileFormat,
+	}
+	if b, err = cpInfo.marshal(); err != nil {
+		return err
+	}
+	if err = db.Put(blkMgrInfoKey, b, true); err != nil {
+		return err
+	}
+
+	// drop the index, starting with its checkpoint, so that it is rebuilt from the block files
+	if err = db.Delete(indexCheckpointKey, true); err != nil {
+		return err
+	}
+	if err = deleteIndexEntries(db); err != nil {
+		return err
+	}
+	return db.Delete(rollbackPendingKey, true)
+}
+
+// deleteIndexEntries deletes all the entries of the index db
+// except the checkpoint info and the rollback marker
+func deleteIndexEntries(db *leveldbhelper.DBHandle) error {
+	for {
+		batch := leveldbhelper.NewUpdateBatch()
+		itr := db.GetIterator(nil, nil)
+		for itr.Next() && len(batch.KVs) < deleteBatchSize {
+			key := itr.Key()
+			if bytes.Equal(key, blkMgrInfoKey) || bytes.Equal(key, rollbackPendingKey) {
+				continue
+			}
+			batch.Delete(append([]byte(nil), key...))
+		}
+		itr.Release()
+		if err := itr.Error(); err != nil {
+			return err
+		}
+		if len(batch.KVs) == 0 {
+			return nil
+		}
+		if err := db.WriteBatch(batch, true); err != nil {
+			return err
+		}
+	}
+}
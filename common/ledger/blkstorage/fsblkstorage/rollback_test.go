@@ -0,0 +1,140 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+func TestRollback(t *testing.T) {
+	// small block files so that the blocks span several files
+	conf := NewConf(testPath(), 2048)
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+
+	blocks := testutil.ConstructTestBlocks(t, 20)
+	otherBlocks := testutil.ConstructTestBlocks(t, 5)
+	addBlocksToStore(t, env, "ledger1", blocks)
+	addBlocksToStore(t, env, "ledger2", otherBlocks)
+	env.provider.Close()
+
+	lastFileNum, err := retrieveLastFileSuffix(conf.getLedgerBlockDir("ledger1"))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, lastFileNum > 0, true)
+
+	testutil.AssertError(t, ValidateRollbackParams(conf.blockStorageDir, "ledger1", 20), "block beyond the last block")
+	testutil.AssertError(t, ValidateRollbackParams(conf.blockStorageDir, "nonexistent", 0), "non existing ledger")
+	testutil.AssertNoError(t, ValidateRollbackParams(conf.blockStorageDir, "ledger1", 5), "")
+
+	testutil.AssertNoError(t, Rollback(conf.blockStorageDir, "ledger1", 5), "")
+	// rolling back to the current last block is a no-op
+	testutil.AssertNoError(t, Rollback(conf.blockStorageDir, "ledger1", 5), "")
+
+	env.provider = NewProvider(conf, env.provider.indexConfig).(*FsBlockstoreProvider)
+	store, err := env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	checkBlocks(t, blocks[:6], store)
+
+	// the index entries of the removed blocks are gone
+	txID, err := extractTxID(blocks[10].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	_, err = store.RetrieveTxByID(txID)
+	testutil.AssertSame(t, err, blkstorage.ErrNotFoundInIndex)
+	_, err = store.RetrieveBlockByHash(blocks[10].Header.Hash())
+	testutil.AssertError(t, err, "block should not be found")
+
+	// the blocks can be committed again
+	for _, b := range blocks[6:] {
+		testutil.AssertNoError(t, store.AddBlock(b), "")
+	}
+	checkBlocks(t, blocks, store)
+	store.Shutdown()
+
+	// the other ledger is untouched
+	otherStore, err := env.provider.OpenBlockStore("ledger2")
+	testutil.AssertNoError(t, err, "")
+	checkBlocks(t, otherBlocks, otherStore)
+	otherStore.Shutdown()
+}
+
+func TestRollbackToGenesis(t *testing.T) {
+	conf := NewConf(testPath(), 0)
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	addBlocksToStore(t, env, "ledger1", blocks)
+	env.provider.Close()
+
+	testutil.AssertNoError(t, Rollback(conf.blockStorageDir, "ledger1", 0), "")
+
+	env.provider = NewProvider(conf, env.provider.indexConfig).(*FsBlockstoreProvider)
+	store, err := env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	checkBlocks(t, blocks[:1], store)
+	store.Shutdown()
+}
+
+func TestRollbackCompletedOnOpen(t *testing.T) {
+	conf := NewConf(testPath(), 2048)
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+
+	blocks := testutil.ConstructTestBlocks(t, 20)
+	addBlocksToStore(t, env, "ledger1", blocks)
+	env.provider.Close()
+
+	// simulate a crash right after the rollback was recorded
+	lastFileNum, err := retrieveLastFileSuffix(conf.getLedgerBlockDir("ledger1"))
+	testutil.AssertNoError(t, err, "")
+	info, err := findBlockEnd(conf.getLedgerBlockDir("ledger1"), lastFileNum, 12)
+	testutil.AssertNoError(t, err, "")
+	b, err := info.marshal()
+	testutil.AssertNoError(t, err, "")
+	p := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: conf.getIndexDir()})
+	testutil.AssertNoError(t, p.GetDBHandle("ledger1").Put(rollbackPendingKey, b, true), "")
+	p.Close()
+
+	env.provider = NewProvider(conf, env.provider.indexConfig).(*FsBlockstoreProvider)
+	store, err := env.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "")
+	checkBlocks(t, blocks[:13], store)
+	store.Shutdown()
+}
+
+func TestRollbackInfoMarshaling(t *testing.T) {
+	info := &rollbackInfo{fileSuffixNum: 3, offset: 123456789, blockNum: 987654321}
+	b, err := info.marshal()
+	testutil.AssertNoError(t, err, "")
+	info2 := &rollbackInfo{}
+	testutil.AssertNoError(t, info2.unmarshal(b), "")
+	testutil.AssertEquals(t, info2, info)
+}
+
+func addBlocksToStore(t *testing.T, env *testEnv, ledgerID string, blocks []*common.Block) {
+	store, err := env.provider.OpenBlockStore(ledgerID)
+	testutil.AssertNoError(t, err, "")
+	defer store.Shutdown()
+	for _, b := range blocks {
+		testutil.AssertNoError(t, store.AddBlock(b), "")
+	}
+}
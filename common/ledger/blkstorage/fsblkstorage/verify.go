@@ -0,0 +1,299 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// BlockScanner reads the blocks of a ledger from its block files, in the order they were
+// appended, without relying on the checkpoint info or on the block index
+type BlockScanner struct {
+	stream *blockStream
+}
+
+// NewBlockScanner returns a scanner of the block files of the given ledger. The block files are
+// only read, so the scanner can be used on a copy of the block store of another peer
+func NewBlockScanner(blockStorageDir, ledgerID string) (*BlockScanner, error) {
+	rootDir := NewConf(blockStorageDir, 0).getLedgerBlockDir(ledgerID)
+	if _, err := os.Stat(rootDir); err != nil {
+		return nil, fmt.Errorf("No block files found for ledger [%s]: %s", ledgerID, err)
+	}
+	lastFileNum, err := retrieveLastFileSuffix(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if lastFileNum < 0 {
+		return &BlockScanner{}, nil
+	}
+	stream, err := newBlockStream(rootDir, 0, 0, lastFileNum)
+	if err != nil {
+		return nil, err
+	}
+	return &BlockScanner{stream}, nil
+}
+
+// Next returns the next block, or nil once all the blocks have been read
+func (s *BlockScanner) Next() (*common.Block, error) {
+	blockBytes, _, err := s.next()
+	if err != nil || blockBytes == nil {
+		return nil, err
+	}
+	return deserializeBlock(blockBytes)
+}
+
+func (s *BlockScanner) next() ([]byte, *blockPlacementInfo, error) {
+	if s.stream == nil {
+		return nil, nil, nil
+	}
+	return s.stream.nextBlockBytesAndPlacementInfo()
+}
+
+// Close releases the block file being read
+func (s *BlockScanner) Close() {
+	if s.stream != nil {
+		s.stream.close()
+	}
+}
+
+// VerifyBlockStore scans the block files of the given ledger and checks that the block numbers follow
+// one another, that the checkpoint info matches the last block and that the entries of the block index
+// point to the blocks and transactions in the files. The blocks are passed in order to the visitor, which
+// can check their content. The inconsistencies found are returned, an error is returned when the block
+// store cannot be verified at all. The block store must not be open
+func VerifyBlockStore(blockStorageDir, ledgerID string, indexConfig *blkstorage.IndexConfig,
+	visitor func(block *common.Block) error) ([]string, error) {
+	conf := NewConf(blockStorageDir, 0)
+	p := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: conf.getIndexDir()})
+	defer p.Close()
+	db := p.GetDBHandle(ledgerID)
+	b, err := db.Get(blkMgrInfoKey)
+	if err != nil {
+		return nil, err
+	}
+	if b == nil {
+		return nil, fmt.Errorf("No block store found for ledger [%s]", ledgerID)
+	}
+	cpInfo := &checkpointInfo{}
+	if err = cpInfo.unmarshal(b); err != nil {
+		return nil, err
+	}
+
+	scanner, err := NewBlockScanner(blockStorageDir, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+
+	v := &blockStoreVerifier{index: newBlockIndex(indexConfig, db), pendingTxEntries: make(map[string]string)}
+	lastBlockIndexed, err := v.index.getLastBlockIndexed()
+	indexEmpty := err == errIndexEmpty
+	if err != nil && !indexEmpty {
+		return nil, err
+	}
+
+	numBlocks := uint64(0)
+	var lastPlacement *blockPlacementInfo
+	for {
+		blockBytes, placement, err := scanner.next()
+		if err != nil {
+			v.report("Block files cannot be read after %d blocks: %s", numBlocks, err)
+			break
+		}
+		if blockBytes == nil {
+			break
+		}
+		block, err := deserializeBlock(blockBytes)
+		if err != nil {
+			v.report("Block in block file [%d] at offset [%d] cannot be decoded: %s", placement.fileNum, placement.blockStartOffset, err)
+			break
+		}
+		if block.Header.Number != numBlocks {
+			v.report("Block [%d] found in block file [%d] at offset [%d] where block [%d] was expected",
+				block.Header.Number, placement.fileNum, placement.blockStartOffset, numBlocks)
+			break
+		}
+		if !indexEmpty && block.Header.Number <= lastBlockIndexed {
+			if err = v.verifyIndexEntries(blockBytes, block, placement); err != nil {
+				return nil, err
+			}
+		}
+		if err = visitor(block); err != nil {
+			return nil, err
+		}
+		numBlocks++
+		lastPlacement = placement
+	}
+
+	v.verifyCheckpointInfo(cpInfo, numBlocks, lastPlacement)
+	switch {
+	case !indexEmpty && lastBlockIndexed >= numBlocks:
+		v.report("The block index records block [%d] as the last block indexed while the block files contain %d blocks",
+			lastBlockIndexed, numBlocks)
+	case numBlocks > 0 && (indexEmpty || lastBlockIndexed < numBlocks-1):
+		v.report("The block index does not cover the blocks from block [%d] on, it gets completed when the block store is opened",
+			nextToIndex(indexEmpty, lastBlockIndexed))
+	}
+	var pending []string
+	for _, inconsistency := range v.pendingTxEntries {
+		pending = append(pending, inconsistency)
+	}
+	sort.Strings(pending)
+	v.inconsistencies = append(v.inconsistencies, pending...)
+	return v.inconsistencies, nil
+}
+
+func nextToIndex(indexEmpty bool, lastBlockIndexed uint64) uint64 {
+	if indexEmpty {
+		return 0
+	}
+	return lastBlockIndexed + 1
+}
+
+type blockStoreVerifier struct {
+	index           *blockIndex
+	inconsistencies []string
+	// pendingTxEntries records the index entries of transaction IDs that do not point to the current
+	// transaction. Since a later transaction with the same ID overwrites the entries, they are only
+	// reported if no later transaction matches them
+	pendingTxEntries map[string]string
+}
+
+func (v *blockStoreVerifier) report(format string, args ...interface{}) {
+	v.inconsistencies = append(v.inconsistencies, fmt.Sprintf(format, args...))
+}
+
+func (v *blockStoreVerifier) verifyCheckpointInfo(cpInfo *checkpointInfo, numBlocks uint64, lastPlacement *blockPlacementInfo) {
+	if numBlocks == 0 {
+		if !cpInfo.isChainEmpty {
+			v.report("The checkpoint info records block [%d] as the last block while the block files contain no block", cpInfo.lastBlockNumber)
+		}
+		return
+	}
+	if cpInfo.isChainEmpty {
+		v.report("The checkpoint info records an empty block store while the block files contain %d blocks", numBlocks)
+		return
+	}
+	if cpInfo.lastBlockNumber != numBlocks-1 {
+		v.report("The checkpoint info records block [%d] as the last block while the block files end with block [%d]",
+			cpInfo.lastBlockNumber, numBlocks-1)
+	}
+	// the block store moves on to a new block file, which holds no block yet, when its configured format changes
+	atNewFile := cpInfo.latestFileChunkSuffixNum > lastPlacement.fileNum &&
+		cpInfo.latestFileChunksize == len(cpInfo.latestFileFormat.header())
+	if atNewFile {
+		return
+	}
+	if cpInfo.latestFileChunkSuffixNum != lastPlacement.fileNum || int64(cpInfo.latestFileChunksize) != lastPlacement.blockEndOffset {
+		v.report("The checkpoint info records the end of the last block in block file [%d] at offset [%d] while it is in block file [%d] at offset [%d]",
+			cpInfo.latestFileChunkSuffixNum, cpInfo.latestFileChunksize, lastPlacement.fileNum, lastPlacement.blockEndOffset)
+	} else if cpInfo.latestFileFormat != lastPlacement.format {
+		v.report("The checkpoint info records the [%s] format for block file [%d] while it is in the [%s] format",
+			cpInfo.latestFileFormat, lastPlacement.fileNum, lastPlacement.format)
+	}
+}
+
+// verifyIndexEntries checks the index entries of the block and of its transactions
+// against the location of the block in the block files
+func (v *blockStoreVerifier) verifyIndexEntries(blockBytes []byte, block *common.Block, placement *blockPlacementInfo) error {
+	info, err := extractSerializedBlockInfo(blockBytes)
+	if err != nil {
+		return err
+	}
+	blockNum := block.Header.Number
+	blockFLP := placement.blockLocPointer()
+
+	flp, err := v.index.getBlockLocByBlockNum(blockNum)
+	if problem := locationProblem(flp, err, blockFLP, false); problem != "" {
+		v.report("Block number index entry of block [%d] %s", blockNum, problem)
+	}
+	flp, err = v.index.getBlockLocByHash(block.Header.Hash())
+	if problem := locationProblem(flp, err, blockFLP, false); problem != "" {
+		v.report("Block hash index entry of block [%d] %s", blockNum, problem)
+	}
+
+	txsFilter := ledgerUtil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	for txNum, txOffset := range info.txOffsets {
+		txID := txOffset.txID
+		txFLP := placement.txLocPointer(txOffset.loc)
+
+		flp, err = v.index.getTXLocByBlockNumTranNum(blockNum, uint64(txNum))
+		if problem := locationProblem(flp, err, txFLP, true); problem != "" {
+			v.report("Index entry of transaction [%d] of block [%d] %s", txNum, blockNum, problem)
+		}
+
+		flp, err = v.index.getTxLoc(txID)
+		v.setTxEntryProblem("t"+txID, locationProblem(flp, err, txFLP, true),
+			"Transaction ID index entry of transaction [%s] of block [%d]", txID, blockNum)
+
+		flp, err = v.index.getBlockLocByTxID(txID)
+		v.setTxEntryProblem("b"+txID, locationProblem(flp, err, blockFLP, false),
+			"Block index entry of transaction [%s] of block [%d]", txID, blockNum)
+
+		code, err := v.index.getTxValidationCodeByTxID(txID)
+		problem := entryProblem(err)
+		if err == nil && code != txsFilter.Flag(txNum) {
+			problem = fmt.Sprintf("records code [%s] instead of [%s]", code, txsFilter.Flag(txNum))
+		}
+		v.setTxEntryProblem("v"+txID, problem,
+			"Validation code index entry of transaction [%s] of block [%d]", txID, blockNum)
+	}
+	return nil
+}
+
+// setTxEntryProblem records the problem of an index entry keyed by a transaction ID,
+// which replaces the one found for a previous transaction with the same ID, if any
+func (v *blockStoreVerifier) setTxEntryProblem(entryKey string, problem string, format string, args ...interface{}) {
+	if problem == "" {
+		delete(v.pendingTxEntries, entryKey)
+		return
+	}
+	v.pendingTxEntries[entryKey] = fmt.Sprintf(format, args...) + " " + problem
+}
+
+// locationProblem compares the location read from the index with the expected one. The
+// length is only compared for the transactions, it is not recorded for the blocks
+func locationProblem(flp *fileLocPointer, err error, expected *fileLocPointer, compareLength bool) string {
+	if err != nil {
+		return entryProblem(err)
+	}
+	if flp.fileSuffixNum != expected.fileSuffixNum || flp.offset != expected.offset ||
+		(compareLength && (flp.bytesLength != expected.bytesLength || flp.format != expected.format || flp.offsetInBlock != expected.offsetInBlock)) {
+		return fmt.Sprintf("points to [%s] instead of [%s]", flp, expected)
+	}
+	return ""
+}
+
+// entryProblem describes the error returned when reading an index entry. An attribute
+// that is not indexed is not a problem
+func entryProblem(err error) string {
+	switch err {
+	case nil, blkstorage.ErrAttrNotIndexed:
+		return ""
+	case blkstorage.ErrNotFoundInIndex:
+		return "is missing"
+	default:
+		return fmt.Sprintf("cannot be read: %s", err)
+	}
+}
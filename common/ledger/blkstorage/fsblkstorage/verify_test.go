@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fsblkstorage
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+func TestVerifyBlockStore(t *testing.T) {
+	conf := NewConf(testPath(), 2048)
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	addBlocksToStore(t, env, "ledger1", blocks)
+	env.provider.Close()
+
+	var visited []*common.Block
+	visitor := func(block *common.Block) error {
+		visited = append(visited, block)
+		return nil
+	}
+	inconsistencies, err := VerifyBlockStore(conf.blockStorageDir, "ledger1", env.provider.indexConfig, visitor)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(inconsistencies), 0)
+	testutil.AssertEquals(t, visited, blocks)
+
+	_, err = VerifyBlockStore(conf.blockStorageDir, "nonexistent", env.provider.indexConfig, visitor)
+	testutil.AssertError(t, err, "non existing ledger")
+
+	// damage the index
+	txID, err := extractTxID(blocks[3].Data.Data[0])
+	testutil.AssertNoError(t, err, "")
+	p := leveldbhelper.NewProvider(&leveldbhelper.Conf{DBPath: conf.getIndexDir()})
+	db := p.GetDBHandle("ledger1")
+	flpBytes, err := db.Get(constructBlockNumKey(2))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNoError(t, db.Put(constructBlockNumKey(1), flpBytes, true), "")
+	testutil.AssertNoError(t, db.Delete(constructTxIDKey(txID), true), "")
+	p.Close()
+
+	inconsistencies, err = VerifyBlockStore(conf.blockStorageDir, "ledger1", env.provider.indexConfig, visitor)
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, len(inconsistencies), 2)
+	testutil.AssertEquals(t, strings.HasPrefix(inconsistencies[0], "Block number index entry of block [1] points to"), true)
+	testutil.AssertEquals(t, inconsistencies[1], "Transaction ID index entry of transaction ["+txID+"] of block [3] is missing")
+}
+
+func TestVerifyBlockStoreTruncatedFiles(t *testing.T) {
+	conf := NewConf(testPath(), 0)
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 5)
+	addBlocksToStore(t, env, "ledger1", blocks)
+	env.provider.Close()
+
+	// cut the last block in half
+	filePath := deriveBlockfilePath(conf.getLedgerBlockDir("ledger1"), 0)
+	fileInfo, err := os.Stat(filePath)
+	testutil.AssertNoError(t, err, "")
+	lastBlockBytes, _, err := serializeBlock(blocks[4])
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNoError(t, os.Truncate(filePath, fileInfo.Size()-int64(len(lastBlockBytes)/2)), "")
+
+	numVisited := 0
+	inconsistencies, err := VerifyBlockStore(conf.blockStorageDir, "ledger1", env.provider.indexConfig, func(block *common.Block) error {
+		numVisited++
+		return nil
+	})
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, numVisited, 4)
+	testutil.AssertEquals(t, inconsistencies[0], "Block files cannot be read after 4 blocks: "+ErrUnexpectedEndOfBlockfile.Error())
+	testutil.AssertEquals(t, strings.HasPrefix(inconsistencies[1], "The checkpoint info records block [4] as the last block"), true)
+	testutil.AssertEquals(t, strings.HasPrefix(inconsistencies[3], "The block index records block [4] as the last block indexed"), true)
+}
+
+func TestBlockScanner(t *testing.T) {
+	conf := NewConf(testPath(), 2048)
+	env := newTestEnv(t, conf)
+	defer env.Cleanup()
+	blocks := testutil.ConstructTestBlocks(t, 10)
+	addBlocksToStore(t, env, "ledger1", blocks)
+
+	scanner, err := NewBlockScanner(conf.blockStorageDir, "ledger1")
+	testutil.AssertNoError(t, err, "")
+	defer scanner.Close()
+	for _, expected := range blocks {
+		block, err := scanner.Next()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertEquals(t, block, expected)
+	}
+	block, err := scanner.Next()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, block)
+
+	_, err = NewBlockScanner(conf.blockStorageDir, "nonexistent")
+	testutil.AssertError(t, err, "non existing ledger")
+}
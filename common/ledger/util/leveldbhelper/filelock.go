@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbhelper
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/ledger/util"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// FileLock is an exclusive lock on a directory that holds across processes.
+// It relies on the lock goleveldb takes on the LOCK file of an opened db,
+// which the operating system releases should the process holding it exit
+type FileLock struct {
+	filePath string
+	db       *leveldb.DB
+	mux      sync.Mutex
+}
+
+// NewFileLock returns the lock on the given directory, which is created if missing
+func NewFileLock(filePath string) *FileLock {
+	return &FileLock{filePath: filePath}
+}
+
+// Lock acquires the lock, or returns an error if it is held already,
+// be it by this process or by another one
+func (f *FileLock) Lock() error {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.db != nil {
+		return fmt.Errorf("Lock on [%s] is held already", f.filePath)
+	}
+	if _, err := util.CreateDirIfMissing(f.filePath); err != nil {
+		return err
+	}
+	db, err := leveldb.OpenFile(f.filePath, nil)
+	if err != nil {
+		return fmt.Errorf("Could not acquire lock on [%s], it is held by another process: %s", f.filePath, err)
+	}
+	f.db = db
+	return nil
+}
+
+// Unlock releases the lock, if held
+func (f *FileLock) Unlock() {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.db == nil {
+		return
+	}
+	if err := f.db.Close(); err != nil {
+		logger.Errorf("Error while releasing lock on [%s]: %s", f.filePath, err)
+	}
+	f.db = nil
+}
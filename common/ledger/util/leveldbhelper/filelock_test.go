@@ -0,0 +1,42 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leveldbhelper
+
+import (
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+)
+
+func TestFileLock(t *testing.T) {
+	defer os.RemoveAll(testDBPath)
+	os.RemoveAll(testDBPath)
+
+	lock := NewFileLock(testDBPath)
+	testutil.AssertNoError(t, lock.Lock(), "")
+	testutil.AssertError(t, lock.Lock(), "The lock should not be acquired twice")
+
+	// another holder, such as another process, cannot acquire the lock
+	otherLock := NewFileLock(testDBPath)
+	testutil.AssertError(t, otherLock.Lock(), "The lock should be held by the first holder")
+
+	lock.Unlock()
+	lock.Unlock()
+	testutil.AssertNoError(t, otherLock.Lock(), "")
+	otherLock.Unlock()
+}
@@ -96,6 +96,28 @@ func (h *DBHandle) WriteBatch(batch *UpdateBatch, sync bool) error {
 	return nil
 }
 
+// DeleteAll deletes all the keys of the named db. The keys are deleted in
+// batches of at most batchSize keys, so a crash may leave a part of them behind
+func (h *DBHandle) DeleteAll(batchSize int) error {
+	for {
+		levelBatch := &leveldb.Batch{}
+		itr := h.GetIterator(nil, nil)
+		for itr.Next() && levelBatch.Len() < batchSize {
+			levelBatch.Delete(append([]byte(nil), itr.Iterator.Key()...))
+		}
+		itr.Release()
+		if err := itr.Error(); err != nil {
+			return err
+		}
+		if levelBatch.Len() == 0 {
+			return nil
+		}
+		if err := h.db.WriteBatch(levelBatch, true); err != nil {
+			return err
+		}
+	}
+}
+
 // GetIterator gets an handle to iterator. The iterator should be released after the use.
 // The resultset contains all the keys that are present in the db between the startKey (inclusive) and the endKey (exclusive).
 // A nil startKey represents the first available key and a nil endKey represent a logical key after the last available key
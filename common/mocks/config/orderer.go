@@ -33,6 +33,8 @@ type Orderer struct {
 	BatchTimeoutVal time.Duration
 	// KafkaBrokersVal is returned as the result of KafkaBrokers()
 	KafkaBrokersVal []string
+	// BFTConsentersVal is returned as the result of BFTConsenters()
+	BFTConsentersVal []*ab.BFTConsenter
 	// MaxChannelsCountVal is returns as the result of MaxChannelsCount()
 	MaxChannelsCountVal uint64
 	// OrganizationsVal is returned as the result of Organizations()
@@ -59,6 +61,11 @@ func (scm *Orderer) KafkaBrokers() []string {
 	return scm.KafkaBrokersVal
 }
 
+// BFTConsenters returns the BFTConsentersVal
+func (scm *Orderer) BFTConsenters() []*ab.BFTConsenter {
+	return scm.BFTConsentersVal
+}
+
 // MaxChannelsCount returns the MaxChannelsCountVal
 func (scm *Orderer) MaxChannelsCount() uint64 {
 	return scm.MaxChannelsCountVal
@@ -17,22 +17,59 @@ limitations under the License.
 package core
 
 import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/chaincode"
+	"github.com/hyperledger/fabric/core/peer"
+	"github.com/hyperledger/fabric/core/policy"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/hyperledger/fabric/msp/mgmt"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"golang.org/x/net/context"
 )
 
 var log = flogging.MustGetLogger("server")
 
+// proposalTimeWindow bounds how far the timestamp of a proposal to a mutating
+// admin RPC may be from the time of the peer. The proposals are remembered
+// for as long as their timestamp is within the window, so that they cannot be replayed
+const proposalTimeWindow = 5 * time.Minute
+
 // NewAdminServer creates and returns a Admin service instance.
 func NewAdminServer() *ServerAdmin {
-	s := new(ServerAdmin)
+	s := &ServerAdmin{
+		policyChecker: policy.NewPolicyChecker(
+			peer.NewChannelPolicyManagerGetter(),
+			mgmt.GetLocalMSP(),
+			mgmt.NewLocalMSPPrincipalGetter(),
+		),
+		gossipService: service.GetGossipService,
+		seenProposals: make(map[string]time.Time),
+	}
 	return s
 }
 
 // ServerAdmin implementation of the Admin service for the Peer
 type ServerAdmin struct {
+	policyChecker policy.PolicyChecker
+	// gossipService returns the gossip service, which is initialized
+	// after the Admin service is registered
+	gossipService func() service.GossipService
+
+	seenLock sync.Mutex
+	// seenProposals holds the time until which each proposal that was accepted
+	// by a mutating admin RPC is remembered, by the creator and nonce of the proposal
+	seenProposals map[string]time.Time
 }
 
 // GetStatus reports the status of the server
@@ -71,3 +108,160 @@ func (*ServerAdmin) RevertLogLevels(context.Context, *empty.Empty) (*empty.Empty
 
 	return &empty.Empty{}, err
 }
+
+// GetChaincodeRuntimes returns the state of the chaincode runtimes launched by
+// the peer. The proposal must be signed by an admin of the local MSP
+func (s *ServerAdmin) GetChaincodeRuntimes(ctx context.Context, signedProp *pb.SignedProposal) (*pb.ChaincodeRuntimes, error) {
+	if err := s.policyChecker.CheckPolicyNoChannel(mgmt.Admins, signedProp); err != nil {
+		return nil, fmt.Errorf("Authorization for GetChaincodeRuntimes has been denied: %s", err)
+	}
+	chaincodeSupport := chaincode.GetChain()
+	if chaincodeSupport == nil {
+		return nil, fmt.Errorf("chaincode support is not initialized")
+	}
+	return &pb.ChaincodeRuntimes{Runtimes: chaincodeSupport.GetRuntimes()}, nil
+}
+
+// GetGossipStatus returns the membership and channel state of the gossip component.
+// The proposal must be signed by an admin of the local MSP
+func (s *ServerAdmin) GetGossipStatus(ctx context.Context, signedProp *pb.SignedProposal) (*pb.GossipStatus, error) {
+	if err := s.policyChecker.CheckPolicyNoChannel(mgmt.Admins, signedProp); err != nil {
+		return nil, fmt.Errorf("Authorization for GetGossipStatus has been denied: %s", err)
+	}
+	status := s.gossipService().Status()
+
+	gossipStatus := &pb.GossipStatus{
+		Self:                  gossipMember(status.Self),
+		CertStoreSize:         int32(status.CertStoreSize),
+		StateInfoMsgStoreSize: int32(status.StateInfoMsgStoreSize),
+	}
+	for _, member := range status.Peers {
+		gossipStatus.Peers = append(gossipStatus.Peers, gossipMember(member))
+	}
+	for chainID, chanStatus := range status.Channels {
+		gossipChannel := &pb.GossipChannelStatus{
+			ChannelId:             chainID,
+			Leader:                chanStatus.Leader,
+			BlockMsgStoreSize:     int32(chanStatus.BlockMsgStoreSize),
+			BlockPullSize:         int32(chanStatus.BlockPullSize),
+			StateInfoMsgStoreSize: int32(chanStatus.StateInfoMsgStoreSize),
+			LeaderMsgStoreSize:    int32(chanStatus.LeaderMsgStoreSize),
+		}
+		for _, member := range chanStatus.Peers {
+			gossipMember := gossipMember(member)
+			// peers publish the number of the last block of their ledger as the metadata of the channel
+			if nodeMetastate, err := state.FromBytes(member.Metadata); err == nil {
+				gossipMember.LedgerHeight = nodeMetastate.LedgerHeight + 1
+			}
+			gossipChannel.Peers = append(gossipChannel.Peers, gossipMember)
+		}
+		gossipStatus.Channels = append(gossipStatus.Channels, gossipChannel)
+	}
+	sort.Sort(channelsByID(gossipStatus.Channels))
+	for _, identity := range status.Identities {
+		gossipStatus.Identities = append(gossipStatus.Identities, &pb.GossipIdentity{
+			PkiId:    identity.PKIid,
+			Org:      string(identity.Org),
+			Identity: identity.Identity,
+		})
+	}
+	return gossipStatus, nil
+}
+
+// UpdateGossipConfig changes the external endpoint and the bootstrap peers of the gossip component
+// as requested by the GossipConfigUpdate carried by the proposal, and returns the resulting configuration.
+// The proposal must be signed by an admin of the local MSP, be recent and not have been used before
+func (s *ServerAdmin) UpdateGossipConfig(ctx context.Context, signedProp *pb.SignedProposal) (*pb.GossipConfig, error) {
+	if err := s.policyChecker.CheckPolicyNoChannel(mgmt.Admins, signedProp); err != nil {
+		return nil, fmt.Errorf("Authorization for UpdateGossipConfig has been denied: %s", err)
+	}
+	prop, err := utils.GetProposal(signedProp.ProposalBytes)
+	if err != nil {
+		return nil, fmt.Errorf("Failed extracting proposal: %s", err)
+	}
+	if err := s.checkProposalReplay(prop); err != nil {
+		return nil, fmt.Errorf("Rejecting proposal for UpdateGossipConfig: %s", err)
+	}
+	update := &pb.GossipConfigUpdate{}
+	if err := proto.Unmarshal(prop.Payload, update); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling gossip config update: %s", err)
+	}
+
+	gossipService := s.gossipService()
+	if update.UpdateExternalEndpoint {
+		if err := gossipService.UpdateExternalEndpoint(update.ExternalEndpoint); err != nil {
+			return nil, err
+		}
+	}
+	if err := gossipService.UpdateBootstrapPeers(update.AddBootstrapPeers, update.RemoveBootstrapPeers); err != nil {
+		return nil, err
+	}
+	log.Infof("Updated gossip config: %s", update)
+
+	return &pb.GossipConfig{
+		BootstrapPeers:   gossipService.BootstrapPeers(),
+		ExternalEndpoint: gossipService.Status().Self.Endpoint,
+	}, nil
+}
+
+// checkProposalReplay returns an error if the timestamp of the proposal isn't within
+// proposalTimeWindow of the time of the peer, or if the proposal was accepted before
+func (s *ServerAdmin) checkProposalReplay(prop *pb.Proposal) error {
+	hdr, err := utils.GetHeader(prop.Header)
+	if err != nil {
+		return fmt.Errorf("Failed extracting header: %s", err)
+	}
+	chdr, err := utils.UnmarshalChannelHeader(hdr.ChannelHeader)
+	if err != nil {
+		return fmt.Errorf("Failed extracting channel header: %s", err)
+	}
+	shdr, err := utils.GetSignatureHeader(hdr.SignatureHeader)
+	if err != nil {
+		return fmt.Errorf("Failed extracting signature header: %s", err)
+	}
+	if chdr.Timestamp == nil || len(shdr.Nonce) == 0 {
+		return fmt.Errorf("Proposal has no timestamp or no nonce")
+	}
+
+	now := time.Now()
+	timestamp := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos))
+	if timestamp.Before(now.Add(-proposalTimeWindow)) || timestamp.After(now.Add(proposalTimeWindow)) {
+		return fmt.Errorf("Proposal timestamp %s is not within %s of the time of the peer", timestamp, proposalTimeWindow)
+	}
+
+	s.seenLock.Lock()
+	defer s.seenLock.Unlock()
+	for key, expiration := range s.seenProposals {
+		if now.After(expiration) {
+			delete(s.seenProposals, key)
+		}
+	}
+	key := string(shdr.Creator) + string(shdr.Nonce)
+	if _, seen := s.seenProposals[key]; seen {
+		return fmt.Errorf("Proposal was already used")
+	}
+	s.seenProposals[key] = timestamp.Add(proposalTimeWindow)
+	return nil
+}
+
+func gossipMember(member gossip.MemberStatus) *pb.GossipMember {
+	gossipMember := &pb.GossipMember{
+		Endpoint:         member.Endpoint,
+		InternalEndpoint: member.InternalEndpoint,
+		PkiId:            member.PKIid,
+		Org:              string(member.Org),
+	}
+	if !member.LastAlive.IsZero() {
+		gossipMember.LastAlive = &timestamp.Timestamp{
+			Seconds: member.LastAlive.Unix(),
+			Nanos:   int32(member.LastAlive.Nanosecond()),
+		}
+	}
+	return gossipMember
+}
+
+type channelsByID []*pb.GossipChannelStatus
+
+func (c channelsByID) Len() int           { return len(c) }
+func (c channelsByID) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c channelsByID) Less(i, j int) bool { return c[i].ChannelId < c[j].ChannelId }
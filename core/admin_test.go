@@ -18,12 +18,25 @@ package core
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/testutil"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/service"
+	"github.com/hyperledger/fabric/gossip/state"
+	"github.com/hyperledger/fabric/msp/mgmt"
+	cb "github.com/hyperledger/fabric/protos/common"
 	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -68,3 +81,221 @@ func TestLoggingCalls(t *testing.T) {
 	assert.Equal(t, flogging.DefaultLevel(), logResponse.LogLevel, "log level should have been the default")
 	assert.Nil(t, err, "Error should have been nil")
 }
+
+func TestGetChaincodeRuntimes(t *testing.T) {
+	policyChecker := &mockPolicyChecker{}
+	server := NewAdminServer()
+	server.policyChecker = policyChecker
+	signedProp := &pb.SignedProposal{ProposalBytes: []byte("proposal"), Signature: []byte("signature")}
+
+	// chaincode support is not initialized in these tests
+	response, err := server.GetChaincodeRuntimes(context.Background(), signedProp)
+	assert.Nil(t, response, "Response should have been nil")
+	assert.EqualError(t, err, "chaincode support is not initialized")
+
+	// the proposal must be signed by an admin of the local MSP
+	policyChecker.err = fmt.Errorf("not an admin")
+	response, err = server.GetChaincodeRuntimes(context.Background(), signedProp)
+	assert.Nil(t, response, "Response should have been nil")
+	assert.Contains(t, err.Error(), "Authorization for GetChaincodeRuntimes has been denied")
+}
+
+type mockPolicyChecker struct {
+	err error
+}
+
+func (*mockPolicyChecker) CheckPolicy(channelID, policyName string, signedProp *pb.SignedProposal) error {
+	panic("implement me")
+}
+
+func (*mockPolicyChecker) CheckPolicyBySignedData(channelID, policyName string, sd []*cb.SignedData) error {
+	panic("implement me")
+}
+
+func (pc *mockPolicyChecker) CheckPolicyNoChannel(policyName string, signedProp *pb.SignedProposal) error {
+	if policyName != mgmt.Admins {
+		return fmt.Errorf("unexpected policy [%s]", policyName)
+	}
+	return pc.err
+}
+
+type mockGossipService struct {
+	service.GossipService
+	status         *gossip.Status
+	bootstrapPeers []string
+}
+
+func (g *mockGossipService) Status() *gossip.Status {
+	return g.status
+}
+
+func (g *mockGossipService) UpdateBootstrapPeers(add []string, remove []string) error {
+	for _, endpoint := range add {
+		if endpoint == "" {
+			return fmt.Errorf("empty endpoint")
+		}
+	}
+	bootstrapPeers := []string{}
+	for _, endpoint := range g.bootstrapPeers {
+		removed := false
+		for _, r := range remove {
+			removed = removed || r == endpoint
+		}
+		if !removed {
+			bootstrapPeers = append(bootstrapPeers, endpoint)
+		}
+	}
+	g.bootstrapPeers = append(bootstrapPeers, add...)
+	return nil
+}
+
+func (g *mockGossipService) BootstrapPeers() []string {
+	return g.bootstrapPeers
+}
+
+func (g *mockGossipService) UpdateExternalEndpoint(endpoint string) error {
+	if endpoint == "bad" {
+		return fmt.Errorf("bad endpoint")
+	}
+	g.status.Self.Endpoint = endpoint
+	return nil
+}
+
+func TestGetGossipStatus(t *testing.T) {
+	lastAlive := time.Unix(1500000000, 500)
+	// the last block of p2 is block 10
+	metadata, err := state.NewNodeMetastate(10).Bytes()
+	assert.NoError(t, err, "Error should have been nil")
+	p1 := gossip.MemberStatus{
+		NetworkMember: discovery.NetworkMember{Endpoint: "p1:7051", InternalEndpoint: "p1:7051", PKIid: common.PKIidType("p1")},
+		Org:           api.OrgIdentityType("Org1MSP"),
+	}
+	p2 := gossip.MemberStatus{
+		NetworkMember: discovery.NetworkMember{Endpoint: "p2:7051", PKIid: common.PKIidType("p2"), Metadata: metadata},
+		Org:           api.OrgIdentityType("Org2MSP"),
+		LastAlive:     lastAlive,
+	}
+	policyChecker := &mockPolicyChecker{}
+	server := NewAdminServer()
+	server.policyChecker = policyChecker
+	server.gossipService = func() service.GossipService {
+		return &mockGossipService{status: &gossip.Status{
+			Self:  p1,
+			Peers: []gossip.MemberStatus{p2},
+			Channels: map[string]*gossip.ChannelStatus{
+				"chB": {},
+				"chA": {Peers: []gossip.MemberStatus{p2}, Leader: common.PKIidType("p2"), BlockMsgStoreSize: 3, BlockPullSize: 2},
+			},
+			Identities:            []gossip.IdentityStatus{{PKIid: common.PKIidType("p2"), Org: api.OrgIdentityType("Org2MSP"), Identity: api.PeerIdentityType("cert2")}},
+			CertStoreSize:         2,
+			StateInfoMsgStoreSize: 1,
+		}}
+	}
+
+	signedProp := &pb.SignedProposal{ProposalBytes: []byte("proposal"), Signature: []byte("signature")}
+	status, err := server.GetGossipStatus(context.Background(), signedProp)
+	assert.NoError(t, err, "Error should have been nil")
+	member2 := &pb.GossipMember{Endpoint: "p2:7051", PkiId: []byte("p2"), Org: "Org2MSP", LastAlive: &timestamp.Timestamp{Seconds: 1500000000, Nanos: 500}}
+	assert.Equal(t, &pb.GossipStatus{
+		Self:  &pb.GossipMember{Endpoint: "p1:7051", InternalEndpoint: "p1:7051", PkiId: []byte("p1"), Org: "Org1MSP"},
+		Peers: []*pb.GossipMember{member2},
+		Channels: []*pb.GossipChannelStatus{
+			{
+				ChannelId:         "chA",
+				Peers:             []*pb.GossipMember{{Endpoint: "p2:7051", PkiId: []byte("p2"), Org: "Org2MSP", LedgerHeight: 11, LastAlive: member2.LastAlive}},
+				Leader:            []byte("p2"),
+				BlockMsgStoreSize: 3,
+				BlockPullSize:     2,
+			},
+			{ChannelId: "chB"},
+		},
+		Identities:            []*pb.GossipIdentity{{PkiId: []byte("p2"), Org: "Org2MSP", Identity: []byte("cert2")}},
+		CertStoreSize:         2,
+		StateInfoMsgStoreSize: 1,
+	}, status)
+
+	// the proposal must be signed by an admin of the local MSP
+	policyChecker.err = fmt.Errorf("not an admin")
+	status, err = server.GetGossipStatus(context.Background(), signedProp)
+	assert.Nil(t, status, "Response should have been nil")
+	assert.Error(t, err, "Error should have been set")
+}
+
+func TestUpdateGossipConfig(t *testing.T) {
+	policyChecker := &mockPolicyChecker{}
+	gossipService := &mockGossipService{
+		status:         &gossip.Status{Self: gossip.MemberStatus{NetworkMember: discovery.NetworkMember{Endpoint: "p1:7051"}}},
+		bootstrapPeers: []string{"p2:7051", "p3:7051"},
+	}
+	server := NewAdminServer()
+	server.policyChecker = policyChecker
+	server.gossipService = func() service.GossipService {
+		return gossipService
+	}
+	signedPropAt := func(update *pb.GossipConfigUpdate, timestamp time.Time) *pb.SignedProposal {
+		payload, err := proto.Marshal(update)
+		assert.NoError(t, err, "Error should have been nil")
+		nonce, err := utils.CreateNonce()
+		assert.NoError(t, err, "Error should have been nil")
+		chdr := utils.MakeChannelHeader(cb.HeaderType_MESSAGE, 0, "", 0)
+		chdr.Timestamp.Seconds = timestamp.Unix()
+		hdr, err := proto.Marshal(utils.MakePayloadHeader(chdr, utils.MakeSignatureHeader([]byte("admin"), nonce)))
+		assert.NoError(t, err, "Error should have been nil")
+		propBytes, err := proto.Marshal(&pb.Proposal{Header: hdr, Payload: payload})
+		assert.NoError(t, err, "Error should have been nil")
+		return &pb.SignedProposal{ProposalBytes: propBytes, Signature: []byte("signature")}
+	}
+	signedProp := func(update *pb.GossipConfigUpdate) *pb.SignedProposal {
+		return signedPropAt(update, time.Now())
+	}
+
+	// the external endpoint is left unchanged unless requested
+	config, err := server.UpdateGossipConfig(context.Background(), signedProp(&pb.GossipConfigUpdate{
+		AddBootstrapPeers:    []string{"p4:7051"},
+		RemoveBootstrapPeers: []string{"p2:7051"},
+		ExternalEndpoint:     "p1.example.com:7051",
+	}))
+	assert.NoError(t, err, "Error should have been nil")
+	assert.Equal(t, &pb.GossipConfig{BootstrapPeers: []string{"p3:7051", "p4:7051"}, ExternalEndpoint: "p1:7051"}, config)
+
+	config, err = server.UpdateGossipConfig(context.Background(), signedProp(&pb.GossipConfigUpdate{
+		UpdateExternalEndpoint: true,
+		ExternalEndpoint:       "p1.example.com:7051",
+	}))
+	assert.NoError(t, err, "Error should have been nil")
+	assert.Equal(t, &pb.GossipConfig{BootstrapPeers: []string{"p3:7051", "p4:7051"}, ExternalEndpoint: "p1.example.com:7051"}, config)
+
+	// invalid updates are rejected
+	_, err = server.UpdateGossipConfig(context.Background(), signedProp(&pb.GossipConfigUpdate{
+		UpdateExternalEndpoint: true,
+		ExternalEndpoint:       "bad",
+	}))
+	assert.Error(t, err, "Error should have been set")
+	_, err = server.UpdateGossipConfig(context.Background(), signedProp(&pb.GossipConfigUpdate{
+		AddBootstrapPeers: []string{""},
+	}))
+	assert.Error(t, err, "Error should have been set")
+	_, err = server.UpdateGossipConfig(context.Background(), &pb.SignedProposal{ProposalBytes: []byte("garbage")})
+	assert.Error(t, err, "Error should have been set")
+
+	// a proposal can't be replayed, neither right away nor once it gets old
+	replayed := signedProp(&pb.GossipConfigUpdate{AddBootstrapPeers: []string{"p5:7051"}})
+	_, err = server.UpdateGossipConfig(context.Background(), replayed)
+	assert.NoError(t, err, "Error should have been nil")
+	_, err = server.UpdateGossipConfig(context.Background(), replayed)
+	assert.Error(t, err, "Error should have been set")
+	_, err = server.UpdateGossipConfig(context.Background(), signedPropAt(&pb.GossipConfigUpdate{}, time.Now().Add(-2*proposalTimeWindow)))
+	assert.Error(t, err, "Error should have been set")
+	_, err = server.UpdateGossipConfig(context.Background(), signedPropAt(&pb.GossipConfigUpdate{}, time.Now().Add(2*proposalTimeWindow)))
+	assert.Error(t, err, "Error should have been set")
+	propBytes, err := proto.Marshal(&pb.Proposal{})
+	assert.NoError(t, err, "Error should have been nil")
+	_, err = server.UpdateGossipConfig(context.Background(), &pb.SignedProposal{ProposalBytes: propBytes})
+	assert.Error(t, err, "Error should have been set")
+
+	// the proposal must be signed by an admin of the local MSP
+	policyChecker.err = fmt.Errorf("not an admin")
+	config, err = server.UpdateGossipConfig(context.Background(), signedProp(&pb.GossipConfigUpdate{}))
+	assert.Nil(t, config, "Response should have been nil")
+	assert.Error(t, err, "Error should have been set")
+}
@@ -32,6 +32,7 @@ import (
 	"strings"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/chaincode/platforms"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
@@ -88,6 +89,17 @@ func getHistoryQueryExecutor(context context.Context) ledger.HistoryQueryExecuto
 //This is where the VM that's running the chaincode would hook in
 type chaincodeRTEnv struct {
 	handler *Handler
+
+	//what the chaincode was launched with, so that it can be restarted
+	//without a transaction (nil for chaincodes not launched by the peer)
+	launchSpec *launchSpec
+}
+
+//launchSpec holds what is needed to launch a chaincode container again
+type launchSpec struct {
+	cccid   *ccprovider.CCContext
+	cds     *pb.ChaincodeDeploymentSpec
+	builder api.BuildSpecFactory
 }
 
 // runningChaincodes contains maps of chaincodeIDs to their chaincodeRTEs
@@ -106,14 +118,14 @@ func GetChain() *ChaincodeSupport {
 	return theChaincodeSupport
 }
 
-func (chaincodeSupport *ChaincodeSupport) preLaunchSetup(chaincode string) chan bool {
+func (chaincodeSupport *ChaincodeSupport) preLaunchSetup(chaincode string, ls *launchSpec) chan bool {
 	chaincodeSupport.runningChaincodes.Lock()
 	defer chaincodeSupport.runningChaincodes.Unlock()
 	//register placeholder Handler. This will be transferred in registerHandler
 	//NOTE: from this point, existence of handler for this chaincode means the chaincode
 	//is in the process of getting started (or has been started)
 	notfy := make(chan bool, 1)
-	chaincodeSupport.runningChaincodes.chaincodeMap[chaincode] = &chaincodeRTEnv{handler: &Handler{readyNotify: notfy}}
+	chaincodeSupport.runningChaincodes.chaincodeMap[chaincode] = &chaincodeRTEnv{handler: &Handler{readyNotify: notfy}, launchSpec: ls}
 	return notfy
 }
 
@@ -201,6 +213,8 @@ func NewChaincodeSupport(getCCEndpoint func() (*pb.PeerEndpoint, error), userrun
 	theChaincodeSupport.shimLogLevel = getLogLevelFromViper("shim")
 	theChaincodeSupport.logFormat = viper.GetString("chaincode.logging.format")
 
+	theChaincodeSupport.supervisor = newRuntimeSupervisor(theChaincodeSupport.relaunch)
+
 	return theChaincodeSupport
 }
 
@@ -241,6 +255,7 @@ type ChaincodeSupport struct {
 	executetimeout    time.Duration
 	userRunsCC        bool
 	peerTLS           bool
+	supervisor        *runtimeSupervisor
 }
 
 // DuplicateChaincodeHandlerError returned if attempt to register same chaincodeID while a stream already exists.
@@ -304,14 +319,30 @@ func (chaincodeSupport *ChaincodeSupport) deregisterHandler(chaincodehandler *Ha
 
 	key := chaincodehandler.ChaincodeID.Name
 	chaincodeLogger.Debugf("Deregister handler: %s", key)
+
+	//nothing will answer the transactions still waiting on this handler
+	chaincodehandler.failPendingTransactions(fmt.Sprintf("chaincode %s terminated", key))
+
 	chaincodeSupport.runningChaincodes.Lock()
-	defer chaincodeSupport.runningChaincodes.Unlock()
-	if _, ok := chaincodeSupport.chaincodeHasBeenLaunched(key); !ok {
+	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(key)
+	if !ok {
+		chaincodeSupport.runningChaincodes.Unlock()
 		// Handler NOT found
 		return fmt.Errorf("Error deregistering handler, could not find handler with key: %s", key)
 	}
+	if chrte.handler != chaincodehandler {
+		//the chaincode was stopped and launched again meanwhile, the entry
+		//belongs to the new handler
+		chaincodeSupport.runningChaincodes.Unlock()
+		chaincodeLogger.Debugf("Handler with key %s was replaced, nothing to deregister", key)
+		return nil
+	}
 	delete(chaincodeSupport.runningChaincodes.chaincodeMap, key)
+	chaincodeSupport.runningChaincodes.Unlock()
 	chaincodeLogger.Debugf("Deregistered handler with key: %s", key)
+
+	//the chaincode was not stopped by the peer (Stop removes it from the map)
+	chaincodeSupport.supervisor.terminated(key, "chaincode stream terminated")
 	return nil
 }
 
@@ -410,7 +441,7 @@ func (chaincodeSupport *ChaincodeSupport) getArgsAndEnv(cccid *ccprovider.CCCont
 
 //launchAndWaitForRegister will launch container if not already running. Use
 //the targz to create the image if not found
-func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.Context, cccid *ccprovider.CCContext, cds *pb.ChaincodeDeploymentSpec, cLang pb.ChaincodeSpec_Type, builder api.BuildSpecFactory) error {
+func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.Context, cccid *ccprovider.CCContext, cds *pb.ChaincodeDeploymentSpec, cLang pb.ChaincodeSpec_Type, builder api.BuildSpecFactory) (err error) {
 	canName := cccid.GetCanonicalName()
 	if canName == "" {
 		return fmt.Errorf("chaincode name not set")
@@ -440,6 +471,13 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 	//this chaincode
 	chaincodeLogger.Debugf("chaincode %s is being launched", canName)
 	chaincodeSupport.runningChaincodes.launchStarted[canName] = true
+	ls := &launchSpec{cccid: cccid, cds: cds, builder: builder}
+	chaincodeSupport.supervisor.launching(canName, ls)
+	defer func() {
+		if err != nil {
+			chaincodeSupport.supervisor.launchFailed(canName, err)
+		}
+	}()
 
 	//now that chaincode launch sequence is done (whether successful or not),
 	//unset launch flag as we get out of this function. If launch was not
@@ -472,7 +510,7 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 	//and use the launching context and make it its own
 	var notfy chan bool
 	preLaunchFunc := func() error {
-		notfy = chaincodeSupport.preLaunchSetup(canName)
+		notfy = chaincodeSupport.preLaunchSetup(canName, ls)
 		return nil
 	}
 
@@ -511,6 +549,60 @@ func (chaincodeSupport *ChaincodeSupport) launchAndWaitForRegister(ctxt context.
 	return err
 }
 
+// HandleResourceViolation implements ccintf.ResourceViolationHandler. The VM
+// calls it when a chaincode container exceeds its resource profile; the
+// container is stopped and the supervisor launches the chaincode again
+func (chaincodeSupport *ChaincodeSupport) HandleResourceViolation(ccid ccintf.CCID, reason string) {
+	canName := ccid.ChaincodeSpec.ChaincodeId.Name + ":" + ccid.Version
+
+	chaincodeSupport.runningChaincodes.Lock()
+	chrte, ok := chaincodeSupport.chaincodeHasBeenLaunched(canName)
+	chaincodeSupport.runningChaincodes.Unlock()
+	if !ok || chrte.launchSpec == nil {
+		chaincodeLogger.Warningf("chaincode %s exceeded its resource profile (%s) but is not running or was not launched by the peer", canName, reason)
+		return
+	}
+
+	if !chaincodeSupport.supervisor.terminated(canName, "exceeded its resource profile: "+reason) {
+		return
+	}
+	if err := chaincodeSupport.Stop(context.Background(), chrte.launchSpec.cccid, chrte.launchSpec.cds); err != nil {
+		chaincodeLogger.Errorf("failed stopping chaincode %s: %s", canName, err)
+	}
+}
+
+// HandleContainerExit implements ccintf.ContainerExitHandler. The VM calls it
+// when a chaincode container exits, whether or not it was stopped by the peer
+func (chaincodeSupport *ChaincodeSupport) HandleContainerExit(ccid ccintf.CCID, exitCode int) {
+	canName := ccid.ChaincodeSpec.ChaincodeId.Name + ":" + ccid.Version
+	chaincodeSupport.supervisor.terminated(canName, fmt.Sprintf("container exited with code %d", exitCode))
+}
+
+//relaunch launches a chaincode again from what it was last launched with and
+//waits for it to be ready
+func (chaincodeSupport *ChaincodeSupport) relaunch(ls *launchSpec) error {
+	//the transaction the chaincode was first launched for is long gone, the
+	//relaunch gets a transaction ID of its own
+	cccid := ccprovider.NewCCContext(ls.cccid.ChainID, ls.cccid.Name, ls.cccid.Version, util.GenerateUUID(), false, nil, nil)
+
+	ctxt := context.Background()
+	if err := chaincodeSupport.launchAndWaitForRegister(ctxt, cccid, ls.cds, ls.cds.ChaincodeSpec.Type, ls.builder); err != nil {
+		return err
+	}
+	if err := chaincodeSupport.sendReady(ctxt, cccid, chaincodeSupport.ccStartupTimeout); err != nil {
+		if errIgnore := chaincodeSupport.Stop(ctxt, cccid, ls.cds); errIgnore != nil {
+			chaincodeLogger.Errorf("stop failed %s(%s)", errIgnore, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// GetRuntimes returns the state of the chaincode runtimes known to the peer
+func (chaincodeSupport *ChaincodeSupport) GetRuntimes() []*pb.ChaincodeRuntime {
+	return chaincodeSupport.supervisor.states()
+}
+
 //Stop stops a chaincode if running
 func (chaincodeSupport *ChaincodeSupport) Stop(context context.Context, cccid *ccprovider.CCContext, cds *pb.ChaincodeDeploymentSpec) error {
 	canName := cccid.GetCanonicalName()
@@ -518,6 +610,8 @@ func (chaincodeSupport *ChaincodeSupport) Stop(context context.Context, cccid *c
 		return fmt.Errorf("chaincode name not set")
 	}
 
+	chaincodeSupport.supervisor.stopped(canName)
+
 	//stop the chaincode
 	sir := container.StopImageReq{CCID: ccintf.CCID{ChaincodeSpec: cds.ChaincodeSpec, NetworkID: chaincodeSupport.peerNetworkID, PeerID: chaincodeSupport.peerID, Version: cccid.Version}, Timeout: 0}
 	// The line below is left for debugging. It replaces the line above to keep
@@ -677,6 +771,9 @@ func (chaincodeSupport *ChaincodeSupport) Launch(context context.Context, cccid
 			if errIgnore != nil {
 				chaincodeLogger.Errorf("stop failed %s(%s)", errIgnore, err)
 			}
+			chaincodeSupport.supervisor.launchFailed(canName, err)
+		} else {
+			chaincodeSupport.supervisor.running(canName)
 		}
 		chaincodeLogger.Debug("sending init completed")
 	}
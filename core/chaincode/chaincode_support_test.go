@@ -42,6 +42,7 @@ import (
 	"github.com/hyperledger/fabric/core/policy"
 	"github.com/hyperledger/fabric/core/scc"
 	plgr "github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	putils "github.com/hyperledger/fabric/protos/utils"
 	"golang.org/x/net/context"
@@ -127,6 +128,13 @@ func (meqe *mockExecQuerySimulator) ExecuteUpdate(query string) error {
 	return meqe.txsim.ExecuteUpdate(query)
 }
 
+func (meqe *mockExecQuerySimulator) SetCrossChannelRead(namespace string, crossChannelRead *kvrwset.CrossChannelRead) error {
+	if meqe.txsim == nil {
+		return fmt.Errorf("SetState txsimulator not initialed")
+	}
+	return meqe.txsim.SetCrossChannelRead(namespace, crossChannelRead)
+}
+
 func (meqe *mockExecQuerySimulator) GetTxSimulationResults() ([]byte, error) {
 	if meqe.txsim == nil {
 		return nil, fmt.Errorf("SetState txsimulator not initialed")
@@ -32,9 +32,11 @@ import (
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	"github.com/hyperledger/fabric/core/container/ccintf"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
 	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/looplab/fsm"
 	logging "github.com/op/go-logging"
@@ -200,6 +202,21 @@ func (handler *Handler) createTxContext(ctxt context.Context, chainID string, tx
 	return txctx, nil
 }
 
+//failPendingTransactions answers every transaction waiting for a response
+//from the chaincode with an error, so that they do not wait for the timeout
+func (handler *Handler) failPendingTransactions(reason string) {
+	handler.Lock()
+	defer handler.Unlock()
+	for txid, txctx := range handler.txCtxs {
+		select {
+		case txctx.responseNotifier <- &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: []byte(reason), Txid: txid}:
+			chaincodeLogger.Debugf("[%s]failed pending transaction: %s", shorttxid(txid), reason)
+		default:
+			//already answered
+		}
+	}
+}
+
 func (handler *Handler) getTxContext(txid string) *transactionContext {
 	handler.Lock()
 	defer handler.Unlock()
@@ -421,6 +438,7 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_GET_STATE_BY_RANGE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_QUERY_RESULT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(), Src: []string{readystate}, Dst: readystate},
+			{Name: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY_RANGE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_QUERY_STATE_NEXT.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_QUERY_STATE_CLOSE.String(), Src: []string{readystate}, Dst: readystate},
 			{Name: pb.ChaincodeMessage_ERROR.String(), Src: []string{readystate}, Dst: readystate},
@@ -429,20 +447,21 @@ func newChaincodeSupportHandler(chaincodeSupport *ChaincodeSupport, peerChatStre
 			{Name: pb.ChaincodeMessage_TRANSACTION.String(), Src: []string{readystate}, Dst: readystate},
 		},
 		fsm.Callbacks{
-			"before_" + pb.ChaincodeMessage_REGISTER.String():           func(e *fsm.Event) { v.beforeRegisterEvent(e, v.FSM.Current()) },
-			"before_" + pb.ChaincodeMessage_COMPLETED.String():          func(e *fsm.Event) { v.beforeCompletedEvent(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_GET_STATE.String():           func(e *fsm.Event) { v.afterGetState(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_GET_STATE_BY_RANGE.String():  func(e *fsm.Event) { v.afterGetStateByRange(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_GET_QUERY_RESULT.String():    func(e *fsm.Event) { v.afterGetQueryResult(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String(): func(e *fsm.Event) { v.afterGetHistoryForKey(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_QUERY_STATE_NEXT.String():    func(e *fsm.Event) { v.afterQueryStateNext(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_QUERY_STATE_CLOSE.String():   func(e *fsm.Event) { v.afterQueryStateClose(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_PUT_STATE.String():           func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_DEL_STATE.String():           func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
-			"after_" + pb.ChaincodeMessage_INVOKE_CHAINCODE.String():    func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
-			"enter_" + establishedstate:                                 func(e *fsm.Event) { v.enterEstablishedState(e, v.FSM.Current()) },
-			"enter_" + readystate:                                       func(e *fsm.Event) { v.enterReadyState(e, v.FSM.Current()) },
-			"enter_" + endstate:                                         func(e *fsm.Event) { v.enterEndState(e, v.FSM.Current()) },
+			"before_" + pb.ChaincodeMessage_REGISTER.String():                 func(e *fsm.Event) { v.beforeRegisterEvent(e, v.FSM.Current()) },
+			"before_" + pb.ChaincodeMessage_COMPLETED.String():                func(e *fsm.Event) { v.beforeCompletedEvent(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_STATE.String():                 func(e *fsm.Event) { v.afterGetState(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_STATE_BY_RANGE.String():        func(e *fsm.Event) { v.afterGetStateByRange(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_QUERY_RESULT.String():          func(e *fsm.Event) { v.afterGetQueryResult(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY.String():       func(e *fsm.Event) { v.afterGetHistoryForKey(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_GET_HISTORY_FOR_KEY_RANGE.String(): func(e *fsm.Event) { v.afterGetHistoryForKeyRange(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_QUERY_STATE_NEXT.String():          func(e *fsm.Event) { v.afterQueryStateNext(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_QUERY_STATE_CLOSE.String():         func(e *fsm.Event) { v.afterQueryStateClose(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_PUT_STATE.String():                 func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_DEL_STATE.String():                 func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
+			"after_" + pb.ChaincodeMessage_INVOKE_CHAINCODE.String():          func(e *fsm.Event) { v.enterBusyState(e, v.FSM.Current()) },
+			"enter_" + establishedstate:                                       func(e *fsm.Event) { v.enterEstablishedState(e, v.FSM.Current()) },
+			"enter_" + readystate:                                             func(e *fsm.Event) { v.enterReadyState(e, v.FSM.Current()) },
+			"enter_" + endstate:                                               func(e *fsm.Event) { v.enterEndState(e, v.FSM.Current()) },
 		},
 	)
 
@@ -1054,8 +1073,45 @@ func (handler *Handler) afterGetHistoryForKey(e *fsm.Event, state string) {
 	chaincodeLogger.Debug("Exiting GET_HISTORY_FOR_KEY")
 }
 
+// afterGetHistoryForKeyRange handles a GET_HISTORY_FOR_KEY_RANGE request from the chaincode.
+func (handler *Handler) afterGetHistoryForKeyRange(e *fsm.Event, state string) {
+	msg, ok := e.Args[0].(*pb.ChaincodeMessage)
+	if !ok {
+		e.Cancel(fmt.Errorf("Received unexpected message type"))
+		return
+	}
+	chaincodeLogger.Debugf("Received %s, invoking get state from ledger", pb.ChaincodeMessage_GET_HISTORY_FOR_KEY_RANGE)
+
+	// Query ledger history db
+	handler.handleGetHistoryForKeyRange(msg)
+	chaincodeLogger.Debug("Exiting GET_HISTORY_FOR_KEY_RANGE")
+}
+
+// historyQueryFunc runs a history query of the chaincode chaincodeID
+type historyQueryFunc func(historyQueryExecutor ledger.HistoryQueryExecutor, chaincodeID string) (commonledger.ResultsIterator, error)
+
 // Handles query to ledger history db
 func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
+	getHistoryForKey := &pb.GetHistoryForKey{}
+	unmarshalErr := proto.Unmarshal(msg.Payload, getHistoryForKey)
+	handler.handleHistoryQuery(msg, unmarshalErr, func(historyQueryExecutor ledger.HistoryQueryExecutor, chaincodeID string) (commonledger.ResultsIterator, error) {
+		return historyQueryExecutor.GetHistoryForKeyWithOptions(chaincodeID, getHistoryForKey.Key, getHistoryForKey.Options)
+	})
+}
+
+// Handles query to ledger history db over a range of keys
+func (handler *Handler) handleGetHistoryForKeyRange(msg *pb.ChaincodeMessage) {
+	getHistoryForKeyRange := &pb.GetHistoryForKeyRange{}
+	unmarshalErr := proto.Unmarshal(msg.Payload, getHistoryForKeyRange)
+	handler.handleHistoryQuery(msg, unmarshalErr, func(historyQueryExecutor ledger.HistoryQueryExecutor, chaincodeID string) (commonledger.ResultsIterator, error) {
+		return historyQueryExecutor.GetHistoryForKeyRange(chaincodeID, getHistoryForKeyRange.StartKey,
+			getHistoryForKeyRange.EndKey, getHistoryForKeyRange.Options)
+	})
+}
+
+// handleHistoryQuery runs the history query of a GET_HISTORY_FOR_KEY or a GET_HISTORY_FOR_KEY_RANGE
+// request, whose payload could not be unmarshalled if unmarshalErr is not nil, and sends back the first results
+func (handler *Handler) handleHistoryQuery(msg *pb.ChaincodeMessage, unmarshalErr error, query historyQueryFunc) {
 	// The defer followed by triggering a go routine dance is needed to ensure that the previous state transition
 	// is completed before the next one is triggered. The previous state transition is deemed complete only when
 	// the afterQueryState function is exited. Interesting bug fix!!
@@ -1072,7 +1128,7 @@ func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
 
 		defer func() {
 			handler.deleteTXIDEntry(msg.Txid)
-			chaincodeLogger.Debugf("[%s]handleGetHistoryForKey serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
+			chaincodeLogger.Debugf("[%s]handleHistoryQuery serial send %s", shorttxid(serialSendMsg.Txid), serialSendMsg.Type)
 			handler.serialSendAsync(serialSendMsg, nil)
 		}()
 
@@ -1088,8 +1144,6 @@ func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
 			serialSendMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR, Payload: payload, Txid: msg.Txid}
 		}
 
-		getHistoryForKey := &pb.GetHistoryForKey{}
-		unmarshalErr := proto.Unmarshal(msg.Payload, getHistoryForKey)
 		if unmarshalErr != nil {
 			errHandler([]byte(unmarshalErr.Error()), nil, "Failed to unmarshall query request. Sending %s", pb.ChaincodeMessage_ERROR)
 			return
@@ -1103,7 +1157,7 @@ func (handler *Handler) handleGetHistoryForKey(msg *pb.ChaincodeMessage) {
 		}
 		chaincodeID := handler.getCCRootName()
 
-		historyIter, err := txContext.historyQueryExecutor.GetHistoryForKey(chaincodeID, getHistoryForKey.Key)
+		historyIter, err := query(txContext.historyQueryExecutor, chaincodeID)
 		if err != nil {
 			errHandler([]byte(err.Error()), nil, "Failed to get ledger history iterator. Sending %s", pb.ChaincodeMessage_ERROR)
 			return
@@ -1223,7 +1277,9 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			ctxt := context.Background()
 			txsim := txContext.txsimulator
 			historyQueryExecutor := txContext.historyQueryExecutor
-			if calledCcIns.ChainID != txContext.chainID {
+			isCrossChannel := calledCcIns.ChainID != txContext.chainID
+			var calledChannelHeight uint64
+			if isCrossChannel {
 				lgr := peer.GetLedger(calledCcIns.ChainID)
 				if lgr == nil {
 					payload := "Failed to find ledger for called channel " + calledCcIns.ChainID
@@ -1239,6 +1295,17 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 				}
 				defer txsim2.Done()
 				txsim = txsim2
+
+				// the height is taken while holding the simulator, which keeps the state from
+				// moving. The block store may be a block ahead of the state, in which case a
+				// read that this block updates fails the validation at that height
+				bcInfo, err2 := lgr.GetBlockchainInfo()
+				if err2 != nil {
+					triggerNextStateMsg = &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_ERROR,
+						Payload: []byte(err2.Error()), Txid: msg.Txid}
+					return
+				}
+				calledChannelHeight = bcInfo.Height
 			}
 			ctxt = context.WithValue(ctxt, TXSimulatorKey, txsim)
 			ctxt = context.WithValue(ctxt, HistoryQueryExecutorKey, historyQueryExecutor)
@@ -1303,6 +1370,12 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 			} else {
 				res, err = proto.Marshal(response)
 			}
+
+			// Record the invocation on the other channel in the read-write set of the
+			// calling chaincode so that it can be checked during validation
+			if err == nil && isCrossChannel {
+				err = recordCrossChannelRead(txContext.txsimulator, txsim, chaincodeID, calledCcIns, calledChannelHeight, res)
+			}
 		}
 
 		if err != nil {
@@ -1318,6 +1391,49 @@ func (handler *Handler) enterBusyState(e *fsm.Event, state string) {
 	}()
 }
 
+// recordCrossChannelRead adds the reads performed by a chaincode invoked on another channel (captured by
+// calledTxsim) to the read-write set of the calling chaincode. As the invocation on the other channel is
+// read-only, an error is returned if the called chaincode attempted to write to the state
+func recordCrossChannelRead(callerTxsim ledger.TxSimulator, calledTxsim ledger.TxSimulator, callerNs string,
+	calledCcIns *sysccprovider.ChaincodeInstance, calledChannelHeight uint64, response []byte) error {
+	simRes, err := calledTxsim.GetTxSimulationResults()
+	if err != nil {
+		return err
+	}
+	calledTxRWSet := &rwsetutil.TxRwSet{}
+	if err = calledTxRWSet.FromProtoBytes(simRes); err != nil {
+		return err
+	}
+	ccRead := &kvrwset.CrossChannelRead{
+		ChannelId:     calledCcIns.ChainID,
+		ChaincodeName: calledCcIns.ChaincodeName,
+		BlockHeight:   calledChannelHeight,
+		ResponseHash:  util.ComputeSHA256(response),
+	}
+	var nestedCCReads []*kvrwset.CrossChannelRead
+	for _, nsRWSet := range calledTxRWSet.NsRwSets {
+		if len(nsRWSet.KvRwSet.Writes) > 0 {
+			return fmt.Errorf("chaincode %s on channel %s attempted to write to namespace %s; writes are not allowed in a cross channel invocation",
+				calledCcIns.ChaincodeName, calledCcIns.ChainID, nsRWSet.NameSpace)
+		}
+		if len(nsRWSet.KvRwSet.RangeQueriesInfo) > 0 {
+			chaincodeLogger.Warningf("Range queries performed by chaincode %s on channel %s are not validated in a cross channel invocation",
+				calledCcIns.ChaincodeName, calledCcIns.ChainID)
+		}
+		if len(nsRWSet.KvRwSet.Reads) > 0 {
+			ccRead.NsReads = append(ccRead.NsReads, &kvrwset.NsReads{Namespace: nsRWSet.NameSpace, Reads: nsRWSet.KvRwSet.Reads})
+		}
+		// invocations made by the called chaincode on further channels are attributed to the calling chaincode as well
+		nestedCCReads = append(nestedCCReads, nsRWSet.KvRwSet.CrossChannelReads...)
+	}
+	for _, r := range append([]*kvrwset.CrossChannelRead{ccRead}, nestedCCReads...) {
+		if err = callerTxsim.SetCrossChannelRead(callerNs, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (handler *Handler) enterEstablishedState(e *fsm.Event, state string) {
 	handler.notifyDuringStartup(true)
 }
@@ -78,6 +78,22 @@ func splitEnvPaths(value string) Paths {
 	return paths
 }
 
+// orderedGopaths returns the GOPATH entries to search, with the chaincode's
+// own GOPATH first followed by the entries of value in their original order.
+// Duplicates and empty entries are dropped.
+func orderedGopaths(first string, value string) []string {
+	seen := make(map[string]bool)
+	paths := make([]string, 0)
+	for _, path := range append([]string{first}, filepath.SplitList(value)...) {
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
 func flattenEnvPaths(paths Paths) string {
 
 	_paths := make([]string, 0)
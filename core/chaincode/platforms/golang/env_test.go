@@ -28,6 +28,12 @@ func Test_splitEnvPath(t *testing.T) {
 	assert.Equal(t, len(paths), 3)
 }
 
+func Test_orderedGopaths(t *testing.T) {
+	sep := string(os.PathListSeparator)
+	paths := orderedGopaths("bar", "foo"+sep+"bar"+sep+sep+"baz")
+	assert.Equal(t, []string{"bar", "foo", "baz"}, paths)
+}
+
 func Test_getGoEnv(t *testing.T) {
 	goenv, err := getGoEnv()
 	assert.NoError(t, err)
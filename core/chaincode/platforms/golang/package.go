@@ -147,3 +147,49 @@ func findSource(gopath, pkg string) (SourceMap, error) {
 
 	return sources, nil
 }
+
+// metadataDir is the directory of the chaincode holding the metadata, such as
+// the statedb index definitions, to be shipped at the root of the package
+const metadataDir = "META-INF"
+
+// findMetadata collects the files under the META-INF directory of the chaincode,
+// if any. Unlike the source, the whole tree is collected and it is named from the
+// root of the package, so that META-INF/statedb/couchdb/indexes/foo.json in the
+// chaincode directory ends up as META-INF/statedb/couchdb/indexes/foo.json
+func findMetadata(gopath, pkg string) (Sources, error) {
+	files := make(Sources, 0)
+	root := filepath.Join(gopath, "src", pkg, metadataDir)
+
+	exists, err := pathExists(root)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return files, nil
+	}
+
+	walkFn := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("error obtaining relative path for %s: %s", path, err)
+		}
+
+		files = append(files, SourceDescriptor{Name: filepath.ToSlash(filepath.Join(metadataDir, rel)), Path: path, Info: info})
+
+		return nil
+	}
+
+	if err := filepath.Walk(root, walkFn); err != nil {
+		return nil, fmt.Errorf("Error walking directory: %s", err)
+	}
+
+	return files, nil
+}
@@ -139,6 +139,9 @@ func (goPlatform *Platform) ValidateDeploymentSpec(cds *pb.ChaincodeDeploymentSp
 	// the container itself needs to be the last line of defense and be configured to be
 	// resilient in enforcing constraints. However, we should still do our best to keep as much
 	// garbage out of the system as possible.
+	//
+	// The only other entries allowed are the chaincode metadata under /META-INF, which is
+	// never compiled, such as the statedb index definitions.
 	re := regexp.MustCompile(`(/)?src/.*`)
 	is := bytes.NewReader(cds.CodePackage)
 	gr, err := gzip.NewReader(is)
@@ -157,7 +160,7 @@ func (goPlatform *Platform) ValidateDeploymentSpec(cds *pb.ChaincodeDeploymentSp
 		// --------------------------------------------------------------------------------------
 		// Check name for conforming path
 		// --------------------------------------------------------------------------------------
-		if !re.MatchString(header.Name) {
+		if !re.MatchString(header.Name) && !strings.HasPrefix(strings.TrimPrefix(header.Name, "/"), metadataDir+"/") {
 			return fmt.Errorf("illegal file detected in payload: \"%s\"", header.Name)
 		}
 
@@ -269,10 +272,9 @@ func (goPlatform *Platform) GetDeploymentPayload(spec *pb.ChaincodeSpec) ([]byte
 	if err != nil {
 		return nil, err
 	}
-	gopaths := splitEnvPaths(env["GOPATH"])
+	gopaths := orderedGopaths(code.Gopath, env["GOPATH"])
 	goroots := splitEnvPaths(env["GOROOT"])
-	gopaths[code.Gopath] = true
-	env["GOPATH"] = flattenEnvPaths(gopaths)
+	env["GOPATH"] = strings.Join(gopaths, string(os.PathListSeparator))
 
 	// --------------------------------------------------------------------------------------
 	// Retrieve the list of first-order imports referenced by the chaincode
@@ -363,7 +365,10 @@ func (goPlatform *Platform) GetDeploymentPayload(spec *pb.ChaincodeSpec) ([]byte
 		// any of the system packages.  However, the official way (go-list) to make this determination
 		// is too expensive to run for every dep.  Therefore, we cheat.  We assume that any packages that
 		// cannot be found must be system packages and silently skip them
-		for gopath := range gopaths {
+		//
+		// GOPATH entries are searched in order and the first match wins, mirroring the
+		// go tool, so that the package content does not depend on map iteration order
+		for _, gopath := range gopaths {
 			fqp := filepath.Join(gopath, "src", dep)
 			exists, err := pathExists(fqp)
 
@@ -381,6 +386,7 @@ func (goPlatform *Platform) GetDeploymentPayload(spec *pb.ChaincodeSpec) ([]byte
 				for _, file := range files {
 					fileMap[file.Name] = file
 				}
+				break
 			}
 		}
 	}
@@ -401,10 +407,21 @@ func (goPlatform *Platform) GetDeploymentPayload(spec *pb.ChaincodeSpec) ([]byte
 	vendorDependencies(code.Pkg, files)
 
 	// --------------------------------------------------------------------------------------
-	// Sort on the filename so the tarball at least looks sane in terms of package grouping
+	// Sort on the filename so the tarball is reproducible and looks sane in terms of
+	// package grouping
 	// --------------------------------------------------------------------------------------
 	sort.Sort(files)
 
+	// --------------------------------------------------------------------------------------
+	// Append the metadata of the chaincode, such as the statedb index definitions
+	// --------------------------------------------------------------------------------------
+	metadata, err := findMetadata(code.Gopath, code.Pkg)
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(metadata)
+	files = append(files, metadata...)
+
 	// --------------------------------------------------------------------------------------
 	// Write out our tar package
 	// --------------------------------------------------------------------------------------
@@ -419,8 +436,12 @@ func (goPlatform *Platform) GetDeploymentPayload(spec *pb.ChaincodeSpec) ([]byte
 		}
 	}
 
-	tw.Close()
-	gw.Close()
+	if err = tw.Close(); err != nil {
+		return nil, fmt.Errorf("Error closing tar: %s", err)
+	}
+	if err = gw.Close(); err != nil {
+		return nil, fmt.Errorf("Error closing gzip: %s", err)
+	}
 
 	return payload.Bytes(), nil
 }
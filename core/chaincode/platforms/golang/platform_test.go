@@ -92,6 +92,10 @@ func TestValidateCDS(t *testing.T) {
 	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/nowhere", File: "/bin/warez", Mode: 0100400, SuccessExpected: false})
 	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/somewhere", File: "/src/path/to/somewhere/main.go", Mode: 0100400, SuccessExpected: true})
 	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/somewhere", File: "/src/path/to/somewhere/warez", Mode: 0100555, SuccessExpected: false})
+	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/somewhere", File: "META-INF/statedb/couchdb/indexes/indexOwner.json", Mode: 0100400, SuccessExpected: true})
+	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/somewhere", File: "/META-INF/statedb/couchdb/indexes/indexOwner.json", Mode: 0100400, SuccessExpected: true})
+	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/somewhere", File: "META-INF/warez", Mode: 0100555, SuccessExpected: false})
+	specs = append(specs, spec{CCName: "NoCode", Path: "path/to/somewhere", File: "/bin/META-INF/warez", Mode: 0100400, SuccessExpected: false})
 
 	for _, s := range specs {
 		cds, err := generateFakeCDS(s.CCName, s.Path, s.File, s.Mode)
@@ -145,6 +149,59 @@ func Test_findSource(t *testing.T) {
 	}
 }
 
+func Test_findMetadata(t *testing.T) {
+	gopath, err := getGopath()
+	if err != nil {
+		t.Errorf("failed to get GOPATH: %s", err)
+	}
+
+	metadata, err := findMetadata(gopath, "github.com/hyperledger/fabric/examples/chaincode/go/marbles02")
+	assert.NoError(t, err)
+	names := make([]string, 0)
+	for _, file := range metadata {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "META-INF/statedb/couchdb/indexes/indexOwner.json")
+
+	// chaincodes without metadata
+	metadata, err = findMetadata(gopath, "github.com/hyperledger/fabric/examples/chaincode/go/map")
+	assert.NoError(t, err)
+	assert.Len(t, metadata, 0)
+}
+
+func Test_DeploymentPayloadWithMetadata(t *testing.T) {
+	platform := &Platform{}
+	spec := &pb.ChaincodeSpec{
+		ChaincodeId: &pb.ChaincodeID{
+			Path: "github.com/hyperledger/fabric/examples/chaincode/go/marbles02",
+		},
+	}
+
+	payload, err := platform.GetDeploymentPayload(spec)
+	assert.NoError(t, err)
+
+	gr, err := gzip.NewReader(bytes.NewReader(payload))
+	assert.NoError(t, err)
+	tr := tar.NewReader(gr)
+
+	found := false
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			// We only get here if there are no more entries to scan
+			break
+		}
+		if header.Name == "META-INF/statedb/couchdb/indexes/indexOwner.json" {
+			found = true
+		}
+	}
+	assert.True(t, found, "the index definition should have been packaged")
+
+	// the metadata does not prevent the package from being valid
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: payload}
+	assert.NoError(t, platform.ValidateDeploymentSpec(cds))
+}
+
 func Test_DeploymentPayload(t *testing.T) {
 	platform := &Platform{}
 	spec := &pb.ChaincodeSpec{
@@ -228,12 +228,15 @@ func getPeerAddress() string {
 	return peerAddress
 }
 
+// newPeerClientConnection returns a connection to the peer from the pool of
+// connections shared by the clients of this process. The connection is kept
+// for the lifetime of the process.
 func newPeerClientConnection() (*grpc.ClientConn, error) {
-	var peerAddress = getPeerAddress()
-	if comm.TLSEnabled() {
-		return comm.NewClientConnectionWithAddress(peerAddress, true, true, comm.InitTLSForPeer())
+	pc, err := comm.PeerConnectionPool().Acquire(getPeerAddress())
+	if err != nil {
+		return nil, err
 	}
-	return comm.NewClientConnectionWithAddress(peerAddress, true, false, nil)
+	return pc.ClientConn, nil
 }
 
 func chatWithPeer(chaincodename string, stream PeerChaincodeStream, cc Chaincode) error {
@@ -455,13 +458,43 @@ func (stub *ChaincodeStub) GetQueryResult(query string) (StateQueryIteratorInter
 
 // GetHistoryForKey documentation can be found in interfaces.go
 func (stub *ChaincodeStub) GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error) {
-	response, err := stub.handler.handleGetHistoryForKey(key, stub.TxID)
+	return stub.GetHistoryForKeyWithOptions(key, nil)
+}
+
+// GetHistoryForKeyWithOptions documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetHistoryForKeyWithOptions(key string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	response, err := stub.handler.handleGetHistoryForKey(key, options, stub.TxID)
+	if err != nil {
+		return nil, err
+	}
+	return &HistoryQueryIterator{CommonIterator: &CommonIterator{stub.handler, stub.TxID, response, 0}}, nil
+}
+
+// GetHistoryForKeyRange documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return stub.handleGetHistoryForKeyRange(startKey, endKey, options)
+}
+
+func (stub *ChaincodeStub) handleGetHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	response, err := stub.handler.handleGetHistoryForKeyRange(startKey, endKey, options, stub.TxID)
 	if err != nil {
 		return nil, err
 	}
 	return &HistoryQueryIterator{CommonIterator: &CommonIterator{stub.handler, stub.TxID, response, 0}}, nil
 }
 
+// GetHistoryForPartialCompositeKey documentation can be found in interfaces.go
+func (stub *ChaincodeStub) GetHistoryForPartialCompositeKey(objectType string, attributes []string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return stub.handleGetHistoryForKeyRange(partialCompositeKey, partialCompositeKey+string(maxUnicodeRuneValue), options)
+}
+
 //CreateCompositeKey documentation can be found in interfaces.go
 func (stub *ChaincodeStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
 	return createCompositeKey(objectType, attributes)
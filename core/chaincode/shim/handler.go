@@ -22,6 +22,7 @@ import (
 	"sync"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/looplab/fsm"
 )
@@ -667,7 +668,20 @@ func (handler *Handler) handleGetQueryResult(query string, txid string) (*pb.Que
 	return nil, errors.New(fmt.Sprintf("Incorrect chaincode message %s received. Expecting %s or %s", responseMsg.Type, pb.ChaincodeMessage_RESPONSE, pb.ChaincodeMessage_ERROR))
 }
 
-func (handler *Handler) handleGetHistoryForKey(key string, txid string) (*pb.QueryResponse, error) {
+func (handler *Handler) handleGetHistoryForKey(key string, options *queryresult.HistoryQueryOptions, txid string) (*pb.QueryResponse, error) {
+	//we constructed a valid object. No need to check for error
+	payloadBytes, _ := proto.Marshal(&pb.GetHistoryForKey{Key: key, Options: options})
+	return handler.handleHistoryQuery(pb.ChaincodeMessage_GET_HISTORY_FOR_KEY, payloadBytes, txid)
+}
+
+func (handler *Handler) handleGetHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions, txid string) (*pb.QueryResponse, error) {
+	//we constructed a valid object. No need to check for error
+	payloadBytes, _ := proto.Marshal(&pb.GetHistoryForKeyRange{StartKey: startKey, EndKey: endKey, Options: options})
+	return handler.handleHistoryQuery(pb.ChaincodeMessage_GET_HISTORY_FOR_KEY_RANGE, payloadBytes, txid)
+}
+
+// handleHistoryQuery sends a history query message to validator chaincode support and waits for the first results
+func (handler *Handler) handleHistoryQuery(msgType pb.ChaincodeMessage_Type, payloadBytes []byte, txid string) (*pb.QueryResponse, error) {
 	// Create the channel on which to communicate the response from validating peer
 	var respChan chan pb.ChaincodeMessage
 	var err error
@@ -677,17 +691,13 @@ func (handler *Handler) handleGetHistoryForKey(key string, txid string) (*pb.Que
 
 	defer handler.deleteChannel(txid)
 
-	// Send GET_HISTORY_FOR_KEY message to validator chaincode support
-	//we constructed a valid object. No need to check for error
-	payloadBytes, _ := proto.Marshal(&pb.GetHistoryForKey{Key: key})
-
-	msg := &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY, Payload: payloadBytes, Txid: txid}
-	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_HISTORY_FOR_KEY)
+	msg := &pb.ChaincodeMessage{Type: msgType, Payload: payloadBytes, Txid: txid}
+	chaincodeLogger.Debugf("[%s]Sending %s", shorttxid(msg.Txid), msgType)
 
 	var responseMsg pb.ChaincodeMessage
 
 	if responseMsg, err = handler.sendReceive(msg, respChan); err != nil {
-		return nil, errors.New(fmt.Sprintf("[%s]error sending %s", shorttxid(msg.Txid), pb.ChaincodeMessage_GET_HISTORY_FOR_KEY))
+		return nil, errors.New(fmt.Sprintf("[%s]error sending %s", shorttxid(msg.Txid), msgType))
 	}
 
 	if responseMsg.Type.String() == pb.ChaincodeMessage_RESPONSE.String() {
@@ -69,13 +69,13 @@ type ChaincodeStubInterface interface {
 	// If the called chaincode is on the same channel, it simply adds the called
 	// chaincode read set and write set to the calling transaction.
 	// If the called chaincode is on a different channel,
-	// only the Response is returned to the calling chaincode; any PutState calls
-	// from the called chaincode will not have any effect on the ledger; that is,
-	// the called chaincode on a different channel will not have its read set
-	// and write set applied to the transaction. Only the calling chaincode's
-	// read set and write set will be applied to the transaction. Effectively
-	// the called chaincode on a different channel is a `Query`, which does not
-	// participate in state validation checks in subsequent commit phase.
+	// only the Response is returned to the calling chaincode and the invocation
+	// is read-only; an error is returned if the called chaincode calls PutState
+	// or DelState. The reads performed by the called chaincode, along with the
+	// hash of the Response and the height of the called channel, are recorded
+	// in the read set of the calling chaincode. During the commit phase, these
+	// reads are validated against the committed state of the called channel
+	// and the transaction is invalidated if any of them is stale.
 	// If `channel` is empty, the caller's channel is assumed.
 	InvokeChaincode(chaincodeName string, args [][]byte, channel string) pb.Response
 
@@ -163,6 +163,28 @@ type ChaincodeStubInterface interface {
 	// update ledger, and should limit use to read-only chaincode operations.
 	GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error)
 
+	// GetHistoryForKeyWithOptions returns the history of a key like GetHistoryForKey
+	// does, restricted to the blocks and the timestamps given in the options, and
+	// newest first if the options ask so. The block bounds restrict the portion of
+	// the history db that is read, so they should be preferred for long histories.
+	// A nil options returns the whole history. The same caveats as for
+	// GetHistoryForKey apply.
+	GetHistoryForKeyWithOptions(key string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error)
+
+	// GetHistoryForKeyRange returns the history of all the keys in the range
+	// [startKey, endKey), including the keys which have been deleted since.
+	// Empty startKey or endKey mean an unbounded range on that side. The results
+	// are ordered by key and, for each key, in the order of the transactions,
+	// reversed altogether if the options ask for the newest first. The key of each
+	// result is set in `KeyModification.Key`. The same caveats as for
+	// GetHistoryForKey apply.
+	GetHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error)
+
+	// GetHistoryForPartialCompositeKey returns the history of all the composite
+	// keys whose prefix matches the given partial composite key, including the
+	// ones which have been deleted since. It works like GetHistoryForKeyRange.
+	GetHistoryForPartialCompositeKey(objectType string, keys []string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error)
+
 	// GetCreator returns `SignatureHeader.Creator` (e.g. an identity)
 	// of the `SignedProposal`. This is the identity of the agent (or user)
 	// submitting the transaction.
@@ -22,6 +22,7 @@ import (
 	"container/list"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/golang/protobuf/ptypes/timestamp"
@@ -64,6 +65,13 @@ type MockStub struct {
 
 	// mocked signedProposal
 	signedProposal *pb.SignedProposal
+
+	// history keeps the modifications of each key, in the order of the transactions
+	history map[string][]*queryresult.KeyModification
+
+	// blockNum is the number of the block of the current transaction, each mocked
+	// transaction being committed in a block of its own
+	blockNum uint64
 }
 
 func (stub *MockStub) GetTxID() string {
@@ -107,6 +115,7 @@ func (stub *MockStub) MockTransactionStart(txid string) {
 func (stub *MockStub) MockTransactionEnd(uuid string) {
 	stub.signedProposal = nil
 	stub.TxID = ""
+	stub.blockNum++
 }
 
 // Register a peer chaincode with this MockStub
@@ -160,6 +169,7 @@ func (stub *MockStub) PutState(key string, value []byte) error {
 
 	mockLogger.Debug("MockStub", stub.Name, "Putting", key, value)
 	stub.State[key] = value
+	stub.addToHistory(key, value, false)
 
 	// insert key into ordered list of keys
 	for elem := stub.Keys.Front(); elem != nil; elem = elem.Next() {
@@ -198,6 +208,7 @@ func (stub *MockStub) PutState(key string, value []byte) error {
 func (stub *MockStub) DelState(key string) error {
 	mockLogger.Debug("MockStub", stub.Name, "Deleting", key, stub.State[key])
 	delete(stub.State, key)
+	stub.addToHistory(key, nil, true)
 
 	for elem := stub.Keys.Front(); elem != nil; elem = elem.Next() {
 		if strings.Compare(key, elem.Value.(string)) == 0 {
@@ -230,7 +241,60 @@ func (stub *MockStub) GetQueryResult(query string) (StateQueryIteratorInterface,
 // GetHistoryForKey function can be invoked by a chaincode to return a history of
 // key values across time. GetHistoryForKey is intended to be used for read-only queries.
 func (stub *MockStub) GetHistoryForKey(key string) (HistoryQueryIteratorInterface, error) {
-	return nil, errors.New("Not Implemented")
+	return stub.GetHistoryForKeyWithOptions(key, nil)
+}
+
+// GetHistoryForKeyWithOptions returns the history of a key recorded by the MockStub,
+// each mocked transaction being committed in a block of its own
+func (stub *MockStub) GetHistoryForKeyWithOptions(key string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	return newMockHistoryQueryIterator(stub.history[key], options), nil
+}
+
+// GetHistoryForKeyRange returns the history of the keys in the range [startKey, endKey)
+// recorded by the MockStub, including the keys which have been deleted since
+func (stub *MockStub) GetHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	if err := validateSimpleKeys(startKey, endKey); err != nil {
+		return nil, err
+	}
+	return stub.getHistoryForKeyRange(startKey, endKey, options), nil
+}
+
+// GetHistoryForPartialCompositeKey returns the history of the composite keys whose prefix
+// matches the given partial composite key recorded by the MockStub
+func (stub *MockStub) GetHistoryForPartialCompositeKey(objectType string, attributes []string, options *queryresult.HistoryQueryOptions) (HistoryQueryIteratorInterface, error) {
+	partialCompositeKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return stub.getHistoryForKeyRange(partialCompositeKey, partialCompositeKey+string(maxUnicodeRuneValue), options), nil
+}
+
+func (stub *MockStub) getHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions) HistoryQueryIteratorInterface {
+	var keys []string
+	for key := range stub.history {
+		if key >= startKey && (endKey == "" || key < endKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	var keyModifications []*queryresult.KeyModification
+	for _, key := range keys {
+		keyModifications = append(keyModifications, stub.history[key]...)
+	}
+	return newMockHistoryQueryIterator(keyModifications, options)
+}
+
+// addToHistory records the modification of a key by the current transaction,
+// which replaces any previous modification of the key by the same transaction
+func (stub *MockStub) addToHistory(key string, value []byte, isDelete bool) {
+	keyModification := &queryresult.KeyModification{TxId: stub.TxID, Value: value, Timestamp: stub.TxTimestamp,
+		IsDelete: isDelete, Key: key, BlockNum: stub.blockNum}
+	keyHistory := stub.history[key]
+	if n := len(keyHistory); n > 0 && keyHistory[n-1].BlockNum == stub.blockNum {
+		keyHistory[n-1] = keyModification
+		return
+	}
+	stub.history[key] = append(keyHistory, keyModification)
 }
 
 //GetStateByPartialCompositeKey function can be invoked by a chaincode to query the
@@ -331,6 +395,7 @@ func NewMockStub(name string, cc Chaincode) *MockStub {
 	s.State = make(map[string][]byte)
 	s.Invokables = make(map[string]*MockStub)
 	s.Keys = list.New()
+	s.history = make(map[string][]*queryresult.KeyModification)
 
 	return s
 }
@@ -453,6 +518,81 @@ func NewMockStateRangeQueryIterator(stub *MockStub, startKey string, endKey stri
 	return iter
 }
 
+/*****************************
+ History Query Iterator
+*****************************/
+
+// MockHistoryQueryIterator iterates over the history recorded by a MockStub
+type MockHistoryQueryIterator struct {
+	Closed              bool
+	KeyModifications    []*queryresult.KeyModification
+	CurrentModification int
+}
+
+func newMockHistoryQueryIterator(keyModifications []*queryresult.KeyModification, options *queryresult.HistoryQueryOptions) *MockHistoryQueryIterator {
+	iter := &MockHistoryQueryIterator{}
+	for _, km := range keyModifications {
+		if isInHistoryQuery(km, options) {
+			iter.KeyModifications = append(iter.KeyModifications, km)
+		}
+	}
+	if options.GetNewestFirst() {
+		for i, j := 0, len(iter.KeyModifications)-1; i < j; i, j = i+1, j-1 {
+			iter.KeyModifications[i], iter.KeyModifications[j] = iter.KeyModifications[j], iter.KeyModifications[i]
+		}
+	}
+	return iter
+}
+
+// isInHistoryQuery tells whether the key modification is within the bounds of the options
+func isInHistoryQuery(km *queryresult.KeyModification, options *queryresult.HistoryQueryOptions) bool {
+	if options == nil {
+		return true
+	}
+	if km.BlockNum < options.StartBlock || (options.HasEndBlock && km.BlockNum > options.EndBlock) {
+		return false
+	}
+	if options.StartTime != nil && isTimestampBefore(km.Timestamp, options.StartTime) {
+		return false
+	}
+	return options.EndTime == nil || !isTimestampBefore(options.EndTime, km.Timestamp)
+}
+
+// isTimestampBefore tells whether the timestamp a is strictly before the timestamp b
+func isTimestampBefore(a, b *timestamp.Timestamp) bool {
+	if a.GetSeconds() != b.GetSeconds() {
+		return a.GetSeconds() < b.GetSeconds()
+	}
+	return a.GetNanos() < b.GetNanos()
+}
+
+// HasNext returns true if the history query iterator contains additional key modifications
+func (iter *MockHistoryQueryIterator) HasNext() bool {
+	return !iter.Closed && iter.CurrentModification < len(iter.KeyModifications)
+}
+
+// Next returns the next key modification in the history query iterator.
+func (iter *MockHistoryQueryIterator) Next() (*queryresult.KeyModification, error) {
+	if iter.Closed {
+		return nil, errors.New("MockHistoryQueryIterator.Next() called after Close()")
+	}
+	if !iter.HasNext() {
+		return nil, errors.New("MockHistoryQueryIterator.Next() called when it does not HaveNext()")
+	}
+	km := iter.KeyModifications[iter.CurrentModification]
+	iter.CurrentModification++
+	return km, nil
+}
+
+// Close closes the history query iterator.
+func (iter *MockHistoryQueryIterator) Close() error {
+	if iter.Closed {
+		return errors.New("MockHistoryQueryIterator.Close() called after Close()")
+	}
+	iter.Closed = true
+	return nil
+}
+
 func getBytes(function string, args []string) [][]byte {
 	bytes := make([][]byte, 0, len(args)+1)
 	bytes = append(bytes, []byte(function))
@@ -23,7 +23,9 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestMockStateRangeQueryIterator(t *testing.T) {
@@ -235,6 +237,47 @@ func TestGetTxTimestamp(t *testing.T) {
 	stub.MockTransactionEnd("init")
 }
 
+func TestMockHistory(t *testing.T) {
+	stub := NewMockStub("historyTest", nil)
+	// block 0 to 3
+	stub.MockTransactionStart("tx0")
+	stub.PutState("a", []byte("a0"))
+	stub.PutState("b", []byte("b0"))
+	stub.MockTransactionEnd("tx0")
+	stub.MockTransactionStart("tx1")
+	stub.PutState("a", []byte("a1"))
+	stub.PutState("a", []byte("a1bis"))
+	stub.MockTransactionEnd("tx1")
+	stub.MockTransactionStart("tx2")
+	stub.DelState("b")
+	stub.MockTransactionEnd("tx2")
+	stub.MockTransactionStart("tx3")
+	stub.PutState("a", []byte("a3"))
+	stub.MockTransactionEnd("tx3")
+
+	history := func(iter HistoryQueryIteratorInterface, err error) []string {
+		assert.NoError(t, err)
+		defer iter.Close()
+		var results []string
+		for iter.HasNext() {
+			km, err := iter.Next()
+			assert.NoError(t, err)
+			results = append(results, fmt.Sprintf("%s:%s@%d", km.Key, km.Value, km.BlockNum))
+		}
+		return results
+	}
+
+	assert.Equal(t, []string{"a:a0@0", "a:a1bis@1", "a:a3@3"}, history(stub.GetHistoryForKey("a")))
+	assert.Equal(t, []string{"a:a0@0"}, history(stub.GetHistoryForKeyWithOptions("a",
+		&queryresult.HistoryQueryOptions{HasEndBlock: true})))
+	assert.Equal(t, []string{"a:a3@3", "a:a1bis@1"}, history(stub.GetHistoryForKeyWithOptions("a",
+		&queryresult.HistoryQueryOptions{StartBlock: 1, NewestFirst: true})))
+	// the deleted key b is part of the history of the range
+	assert.Equal(t, []string{"a:a0@0", "a:a1bis@1", "a:a3@3", "b:b0@0", "b:@2"}, history(stub.GetHistoryForKeyRange("", "", nil)))
+	assert.Equal(t, []string{"b:b0@0"}, history(stub.GetHistoryForKeyRange("b", "", &queryresult.HistoryQueryOptions{EndBlock: 1, HasEndBlock: true})))
+	assert.Empty(t, history(stub.GetHistoryForPartialCompositeKey("color", []string{"blue"}, nil)))
+}
+
 //TestMockMock clearly cheating for coverage... but not. Mock should
 //be tucked away under common/mocks package which is not
 //included for coverage. Moving mockstub to another package
@@ -298,7 +298,7 @@ func (t *shimTestCC) richq(stub ChaincodeStubInterface, args []string) pb.Respon
 	return Success(buffer.Bytes())
 }
 
-// rangeq calls range query
+// historyq calls history query, over a key range if two keys are given
 func (t *shimTestCC) historyq(stub ChaincodeStubInterface, args []string) pb.Response {
 	if len(args) < 1 {
 		return Error("Incorrect number of arguments. Expecting 1")
@@ -306,7 +306,13 @@ func (t *shimTestCC) historyq(stub ChaincodeStubInterface, args []string) pb.Res
 
 	key := args[0]
 
-	resultsIterator, err := stub.GetHistoryForKey(key)
+	var resultsIterator HistoryQueryIteratorInterface
+	var err error
+	if len(args) > 1 {
+		resultsIterator, err = stub.GetHistoryForKeyRange(key, args[1], nil)
+	} else {
+		resultsIterator, err = stub.GetHistoryForKey(key)
+	}
 	if err != nil {
 		return Error(err.Error())
 	}
@@ -793,6 +799,27 @@ func TestInvoke(t *testing.T) {
 	//wait for done
 	processDone(t, done, false)
 
+	//history range query
+
+	//create the response
+	historyQueryResponse = &pb.QueryResponse{Results: []*pb.QueryResultBytes{
+		&pb.QueryResultBytes{ResultBytes: utils.MarshalOrPanic(&lproto.KeyModification{TxId: "6", Value: []byte("100"), Key: "A"})}},
+		HasMore: false}
+	payload = utils.MarshalOrPanic(historyQueryResponse)
+
+	respSet = &mockpeer.MockResponseSet{errorFunc, errorFunc, []*mockpeer.MockResponse{
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_GET_HISTORY_FOR_KEY_RANGE, Txid: "7b"}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Payload: payload, Txid: "7b"}},
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_QUERY_STATE_CLOSE, Txid: "7b"}, &pb.ChaincodeMessage{Type: pb.ChaincodeMessage_RESPONSE, Txid: "7b"}},
+		&mockpeer.MockResponse{&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_COMPLETED, Txid: "7b"}, nil}}}
+	peerSide.SetResponses(respSet)
+
+	ci = &pb.ChaincodeInput{[][]byte{[]byte("historyq"), []byte("A"), []byte("B")}}
+	payload = utils.MarshalOrPanic(ci)
+	peerSide.Send(&pb.ChaincodeMessage{Type: pb.ChaincodeMessage_TRANSACTION, Payload: payload, Txid: "7b"})
+
+	//wait for done
+	processDone(t, done, false)
+
 	//query result
 
 	//create the response
@@ -0,0 +1,268 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+const (
+	initialBackoffDefault = time.Second
+	maxBackoffDefault     = time.Minute
+)
+
+//runtimeState is what the supervisor knows about a chaincode runtime
+type runtimeState struct {
+	state        pb.ChaincodeRuntime_State
+	restartCount uint32
+	lastError    string
+	runningSince time.Time
+
+	//crashes since the chaincode last ran for longer than the maximum
+	//backoff, drives the delay before the next relaunch
+	consecutiveCrashes int
+
+	launchSpec   *launchSpec
+	restartTimer *time.Timer
+	//identifies the pending relaunch, so that a superseded timer does nothing
+	restartSeq uint64
+}
+
+//runtimeSupervisor tracks the state of the chaincode runtimes and relaunches,
+//with exponential backoff, the ones that terminate while they are running
+type runtimeSupervisor struct {
+	sync.Mutex
+	runtimes map[string]*runtimeState
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	//maximum number of consecutive relaunches, 0 means no limit
+	maxRestarts int
+
+	//relaunch starts the chaincode again and waits for it to be ready
+	relaunch func(ls *launchSpec) error
+}
+
+func newRuntimeSupervisor(relaunch func(ls *launchSpec) error) *runtimeSupervisor {
+	s := &runtimeSupervisor{
+		runtimes:       make(map[string]*runtimeState),
+		initialBackoff: initialBackoffDefault,
+		maxBackoff:     maxBackoffDefault,
+		maxRestarts:    viper.GetInt("chaincode.supervisor.maxRestarts"),
+		relaunch:       relaunch,
+	}
+	if b := viper.GetDuration("chaincode.supervisor.initialBackoff"); b > 0 {
+		s.initialBackoff = b
+	}
+	if b := viper.GetDuration("chaincode.supervisor.maxBackoff"); b > 0 {
+		s.maxBackoff = b
+	}
+	if s.maxBackoff < s.initialBackoff {
+		chaincodeLogger.Warningf("chaincode.supervisor.maxBackoff (%s) is lower than initialBackoff (%s), using %s", s.maxBackoff, s.initialBackoff, s.initialBackoff)
+		s.maxBackoff = s.initialBackoff
+	}
+	return s
+}
+
+//call this under lock
+func (s *runtimeSupervisor) getRuntime(canName string) *runtimeState {
+	rt, ok := s.runtimes[canName]
+	if !ok {
+		rt = &runtimeState{}
+		s.runtimes[canName] = rt
+	}
+	return rt
+}
+
+//launching records that the chaincode is being launched. A launch supersedes
+//any pending relaunch
+func (s *runtimeSupervisor) launching(canName string, ls *launchSpec) {
+	s.Lock()
+	defer s.Unlock()
+
+	rt := s.getRuntime(canName)
+	if rt.restartTimer != nil {
+		rt.restartTimer.Stop()
+		rt.restartTimer = nil
+	}
+	rt.state = pb.ChaincodeRuntime_LAUNCHING
+	if ls != nil {
+		rt.launchSpec = ls
+	}
+}
+
+//running records that the chaincode is up and ready
+func (s *runtimeSupervisor) running(canName string) {
+	s.Lock()
+	defer s.Unlock()
+
+	rt := s.getRuntime(canName)
+	rt.state = pb.ChaincodeRuntime_RUNNING
+	rt.runningSince = time.Now()
+}
+
+//launchFailed records that the chaincode could not be launched
+func (s *runtimeSupervisor) launchFailed(canName string, err error) {
+	s.Lock()
+	defer s.Unlock()
+
+	rt := s.getRuntime(canName)
+	rt.state = pb.ChaincodeRuntime_CRASHED
+	rt.lastError = err.Error()
+}
+
+//stopped records that the chaincode is being stopped on purpose, so that its
+//termination is not taken for a crash. Crashed chaincodes keep their state
+//as their stop is part of the crash handling
+func (s *runtimeSupervisor) stopped(canName string) {
+	s.Lock()
+	defer s.Unlock()
+
+	rt, ok := s.runtimes[canName]
+	if !ok || rt.state == pb.ChaincodeRuntime_CRASHED {
+		return
+	}
+	rt.state = pb.ChaincodeRuntime_STOPPED
+}
+
+//terminated records that a running chaincode went away without being
+//stopped and schedules its relaunch. Returns false if the chaincode was not
+//running, in which case the termination is expected and nothing is done
+func (s *runtimeSupervisor) terminated(canName string, reason string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	rt, ok := s.runtimes[canName]
+	if ok && rt.state == pb.ChaincodeRuntime_CRASHED && !strings.Contains(rt.lastError, reason) {
+		//the stream and the container going away are both reported for
+		//the same crash, keep what each of them tells
+		rt.lastError = rt.lastError + "; " + reason
+	}
+	if !ok || rt.state != pb.ChaincodeRuntime_RUNNING {
+		chaincodeLogger.Debugf("chaincode %s terminated while not running (%s)", canName, reason)
+		return false
+	}
+
+	chaincodeLogger.Errorf("chaincode %s terminated unexpectedly: %s", canName, reason)
+	rt.state = pb.ChaincodeRuntime_CRASHED
+	rt.lastError = reason
+	if time.Since(rt.runningSince) > s.maxBackoff {
+		rt.consecutiveCrashes = 0
+	}
+	rt.consecutiveCrashes++
+	s.scheduleRestart(canName, rt)
+	return true
+}
+
+//call this under lock
+func (s *runtimeSupervisor) scheduleRestart(canName string, rt *runtimeState) {
+	if rt.launchSpec == nil || rt.launchSpec.cds.ExecEnv == pb.ChaincodeDeploymentSpec_SYSTEM {
+		chaincodeLogger.Warningf("chaincode %s was not launched by the peer, not relaunching it", canName)
+		return
+	}
+	if s.maxRestarts > 0 && rt.consecutiveCrashes > s.maxRestarts {
+		chaincodeLogger.Errorf("chaincode %s crashed %d times in a row, giving up relaunching it", canName, rt.consecutiveCrashes)
+		return
+	}
+
+	backoff := s.initialBackoff
+	for i := 1; i < rt.consecutiveCrashes && backoff < s.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.maxBackoff {
+		backoff = s.maxBackoff
+	}
+
+	chaincodeLogger.Infof("relaunching chaincode %s in %s", canName, backoff)
+	rt.restartSeq++
+	seq := rt.restartSeq
+	rt.restartTimer = time.AfterFunc(backoff, func() { s.restart(canName, seq) })
+}
+
+//restart relaunches a crashed chaincode, unless it was launched again or
+//stopped in the meantime
+func (s *runtimeSupervisor) restart(canName string, seq uint64) {
+	s.Lock()
+	rt, ok := s.runtimes[canName]
+	if !ok || rt.restartTimer == nil || rt.restartSeq != seq || rt.state != pb.ChaincodeRuntime_CRASHED {
+		s.Unlock()
+		return
+	}
+	rt.restartTimer = nil
+	rt.restartCount++
+	ls := rt.launchSpec
+	s.Unlock()
+
+	err := s.relaunch(ls)
+
+	s.Lock()
+	defer s.Unlock()
+	if err == nil {
+		chaincodeLogger.Infof("chaincode %s relaunched", canName)
+		rt.state = pb.ChaincodeRuntime_RUNNING
+		rt.runningSince = time.Now()
+		return
+	}
+
+	chaincodeLogger.Errorf("failed relaunching chaincode %s: %s", canName, err)
+	rt.state = pb.ChaincodeRuntime_CRASHED
+	rt.lastError = err.Error()
+	rt.consecutiveCrashes++
+	s.scheduleRestart(canName, rt)
+}
+
+//states returns the state of every chaincode known to the supervisor,
+//sorted by name
+func (s *runtimeSupervisor) states() []*pb.ChaincodeRuntime {
+	s.Lock()
+	defer s.Unlock()
+
+	res := make([]*pb.ChaincodeRuntime, 0, len(s.runtimes))
+	for canName, rt := range s.runtimes {
+		name, version := canName, ""
+		if i := strings.LastIndex(canName, ":"); i >= 0 {
+			name, version = canName[:i], canName[i+1:]
+		}
+		res = append(res, &pb.ChaincodeRuntime{
+			Name:         name,
+			Version:      version,
+			State:        rt.state,
+			RestartCount: rt.restartCount,
+			LastError:    rt.lastError,
+		})
+	}
+	sort.Sort(runtimesByName(res))
+	return res
+}
+
+type runtimesByName []*pb.ChaincodeRuntime
+
+func (r runtimesByName) Len() int      { return len(r) }
+func (r runtimesByName) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r runtimesByName) Less(i, j int) bool {
+	if r[i].Name != r[j].Name {
+		return r[i].Name < r[j].Name
+	}
+	return r[i].Version < r[j].Version
+}
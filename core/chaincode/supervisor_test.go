@@ -0,0 +1,172 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSupervisor(relaunch func(ls *launchSpec) error) *runtimeSupervisor {
+	s := newRuntimeSupervisor(relaunch)
+	s.initialBackoff = time.Millisecond
+	s.maxBackoff = 4 * time.Millisecond
+	return s
+}
+
+func getRuntimeState(s *runtimeSupervisor, canName string) runtimeState {
+	s.Lock()
+	defer s.Unlock()
+	return *s.runtimes[canName]
+}
+
+func waitForRuntimeState(t *testing.T, s *runtimeSupervisor, canName string, state pb.ChaincodeRuntime_State) runtimeState {
+	for i := 0; i < 500; i++ {
+		if rt := getRuntimeState(s, canName); rt.state == state && rt.restartTimer == nil {
+			return rt
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("chaincode %s did not get to state %s", canName, state)
+	return runtimeState{}
+}
+
+func TestSupervisorRelaunchesCrashedChaincode(t *testing.T) {
+	relaunched := make(chan *launchSpec, 1)
+	s := newTestSupervisor(func(ls *launchSpec) error {
+		relaunched <- ls
+		return nil
+	})
+	ls := &launchSpec{cds: &pb.ChaincodeDeploymentSpec{}}
+
+	s.launching("mycc:1.0", ls)
+	assert.Equal(t, pb.ChaincodeRuntime_LAUNCHING, getRuntimeState(s, "mycc:1.0").state)
+	s.running("mycc:1.0")
+	assert.Equal(t, pb.ChaincodeRuntime_RUNNING, getRuntimeState(s, "mycc:1.0").state)
+
+	assert.True(t, s.terminated("mycc:1.0", "chaincode stream terminated"))
+	select {
+	case l := <-relaunched:
+		assert.True(t, l == ls)
+	case <-time.After(5 * time.Second):
+		t.Fatal("chaincode was not relaunched")
+	}
+	rt := waitForRuntimeState(t, s, "mycc:1.0", pb.ChaincodeRuntime_RUNNING)
+	assert.Equal(t, uint32(1), rt.restartCount)
+	assert.Equal(t, "chaincode stream terminated", rt.lastError)
+
+	// a stopped chaincode going away is not a crash
+	s.stopped("mycc:1.0")
+	assert.False(t, s.terminated("mycc:1.0", "container exited with code 137"))
+	assert.Equal(t, pb.ChaincodeRuntime_STOPPED, getRuntimeState(s, "mycc:1.0").state)
+
+	// neither is one the supervisor does not know about
+	assert.False(t, s.terminated("othercc:1.0", "chaincode stream terminated"))
+}
+
+func TestSupervisorGivesUpAfterMaxRestarts(t *testing.T) {
+	attempts := make(chan struct{}, 10)
+	s := newTestSupervisor(func(ls *launchSpec) error {
+		attempts <- struct{}{}
+		return errors.New("registration failed")
+	})
+	s.maxRestarts = 3
+
+	s.launching("mycc:1.0", &launchSpec{cds: &pb.ChaincodeDeploymentSpec{}})
+	s.running("mycc:1.0")
+	assert.True(t, s.terminated("mycc:1.0", "container exited with code 2"))
+
+	rt := waitForRuntimeState(t, s, "mycc:1.0", pb.ChaincodeRuntime_CRASHED)
+	for rt.restartCount < 3 {
+		rt = waitForRuntimeState(t, s, "mycc:1.0", pb.ChaincodeRuntime_CRASHED)
+	}
+	// give a fourth attempt, which should not happen, a chance to run
+	time.Sleep(50 * time.Millisecond)
+	rt = getRuntimeState(s, "mycc:1.0")
+	assert.Equal(t, uint32(3), rt.restartCount)
+	assert.Equal(t, "registration failed", rt.lastError)
+	assert.Len(t, attempts, 3)
+}
+
+func TestSupervisorDoesNotRelaunchSystemChaincodes(t *testing.T) {
+	s := newTestSupervisor(func(ls *launchSpec) error {
+		t.Fatal("system chaincode should not be relaunched")
+		return nil
+	})
+
+	s.launching("lscc:1.0", &launchSpec{cds: &pb.ChaincodeDeploymentSpec{ExecEnv: pb.ChaincodeDeploymentSpec_SYSTEM}})
+	s.running("lscc:1.0")
+	assert.True(t, s.terminated("lscc:1.0", "chaincode stream terminated"))
+
+	// the second report of the same crash is kept along the first one
+	assert.False(t, s.terminated("lscc:1.0", "container exited with code 1"))
+	rt := getRuntimeState(s, "lscc:1.0")
+	assert.Equal(t, pb.ChaincodeRuntime_CRASHED, rt.state)
+	assert.Equal(t, "chaincode stream terminated; container exited with code 1", rt.lastError)
+	assert.Nil(t, rt.restartTimer)
+}
+
+func TestSupervisorStates(t *testing.T) {
+	s := newTestSupervisor(nil)
+	s.launching("mycc:2.0", nil)
+	s.running("mycc:1.0")
+	s.launchFailed("acc:1.0", errors.New("Timeout expired while starting chaincode"))
+
+	states := s.states()
+	assert.Len(t, states, 3)
+	assert.Equal(t, &pb.ChaincodeRuntime{Name: "acc", Version: "1.0", State: pb.ChaincodeRuntime_CRASHED, LastError: "Timeout expired while starting chaincode"}, states[0])
+	assert.Equal(t, &pb.ChaincodeRuntime{Name: "mycc", Version: "1.0", State: pb.ChaincodeRuntime_RUNNING}, states[1])
+	assert.Equal(t, &pb.ChaincodeRuntime{Name: "mycc", Version: "2.0", State: pb.ChaincodeRuntime_LAUNCHING}, states[2])
+}
+
+func TestDeregisterHandlerFailsPendingTransactions(t *testing.T) {
+	relaunched := make(chan struct{}, 1)
+	chaincodeSupport := &ChaincodeSupport{
+		runningChaincodes: &runningChaincodes{chaincodeMap: make(map[string]*chaincodeRTEnv), launchStarted: make(map[string]bool)},
+	}
+	chaincodeSupport.supervisor = newTestSupervisor(func(ls *launchSpec) error {
+		relaunched <- struct{}{}
+		return nil
+	})
+
+	handler := &Handler{ChaincodeID: &pb.ChaincodeID{Name: "mycc:1.0"}, registered: true, chaincodeSupport: chaincodeSupport}
+	handler.txCtxs = map[string]*transactionContext{"tx1": {responseNotifier: make(chan *pb.ChaincodeMessage, 1)}}
+	chaincodeSupport.runningChaincodes.chaincodeMap["mycc:1.0"] = &chaincodeRTEnv{handler: handler}
+	chaincodeSupport.supervisor.launching("mycc:1.0", &launchSpec{cds: &pb.ChaincodeDeploymentSpec{}})
+	chaincodeSupport.supervisor.running("mycc:1.0")
+
+	assert.NoError(t, handler.deregister())
+
+	select {
+	case msg := <-handler.txCtxs["tx1"].responseNotifier:
+		assert.Equal(t, pb.ChaincodeMessage_ERROR, msg.Type)
+		assert.Equal(t, "chaincode mycc:1.0 terminated", string(msg.Payload))
+	default:
+		t.Fatal("pending transaction was not failed")
+	}
+	assert.Len(t, chaincodeSupport.runningChaincodes.chaincodeMap, 0)
+
+	select {
+	case <-relaunched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("chaincode was not relaunched")
+	}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// peerConnPool holds the connections to remote peers that the clients of
+// this process share
+var peerConnPool = NewConnectionPool(dialPeer)
+
+// PeerConnectionPool returns the pool of the connections to remote peers that
+// the clients of this process share, such as the endorser, admin, events and
+// chaincode support clients. Gossip keeps a pool of its own, since its
+// connections are authenticated with the TLS certificate of the peer.
+func PeerConnectionPool() *ConnectionPool {
+	return peerConnPool
+}
+
+// dialPeer dials a remote peer with the TLS settings of the peer
+func dialPeer(endpoint string) (*grpc.ClientConn, error) {
+	if TLSEnabled() {
+		return NewClientConnectionWithAddress(endpoint, true, true, InitTLSForPeer())
+	}
+	return NewClientConnectionWithAddress(endpoint, true, false, nil)
+}
+
+// ConnectionPool shares gRPC client connections among the users that
+// communicate with the same remote endpoint. Since gRPC multiplexes all the
+// calls and streams of a client connection over a single HTTP/2 connection,
+// sharing client connections keeps a single connection to each remote peer.
+type ConnectionPool struct {
+	sync.Mutex
+	dial  func(endpoint string) (*grpc.ClientConn, error)
+	conns map[string]*poolEntry
+}
+
+type poolEntry struct {
+	cc       *grpc.ClientConn
+	refCount int
+	// evicted indicates the entry was removed from the pool,
+	// and its connection is closed once it isn't used anymore
+	evicted bool
+}
+
+// PooledConn is a client connection acquired from a ConnectionPool
+type PooledConn struct {
+	*grpc.ClientConn
+	pool     *ConnectionPool
+	endpoint string
+	entry    *poolEntry
+	once     sync.Once
+}
+
+// NewConnectionPool creates a ConnectionPool that uses the given function
+// to dial remote endpoints
+func NewConnectionPool(dial func(endpoint string) (*grpc.ClientConn, error)) *ConnectionPool {
+	return &ConnectionPool{
+		dial:  dial,
+		conns: make(map[string]*poolEntry),
+	}
+}
+
+// Acquire returns a client connection to the given endpoint, and dials
+// the endpoint if there is no connection to it in the pool.
+// The returned connection must be released once it isn't used anymore.
+func (p *ConnectionPool) Acquire(endpoint string) (*PooledConn, error) {
+	p.Lock()
+	if entry, exists := p.conns[endpoint]; exists {
+		entry.refCount++
+		p.Unlock()
+		return &PooledConn{ClientConn: entry.cc, pool: p, endpoint: endpoint, entry: entry}, nil
+	}
+	p.Unlock()
+
+	// Dial without holding the lock, in order not to delay acquiring
+	// connections to other endpoints
+	cc, err := p.dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	entry, exists := p.conns[endpoint]
+	if exists {
+		// Someone else dialed the endpoint in the meantime
+		cc.Close()
+	} else {
+		entry = &poolEntry{cc: cc}
+		p.conns[endpoint] = entry
+	}
+	entry.refCount++
+	return &PooledConn{ClientConn: entry.cc, pool: p, endpoint: endpoint, entry: entry}, nil
+}
+
+// Release releases the connection, which is closed once none of its users use it
+func (pc *PooledConn) Release() {
+	pc.once.Do(func() {
+		pc.pool.release(pc, false)
+	})
+}
+
+// Discard releases the connection and removes it from the pool, so that
+// the endpoint is dialed again the next time a connection to it is acquired.
+// It is used when the connection is suspected to be broken.
+func (pc *PooledConn) Discard() {
+	pc.once.Do(func() {
+		pc.pool.release(pc, true)
+	})
+}
+
+func (p *ConnectionPool) release(pc *PooledConn, evict bool) {
+	p.Lock()
+	defer p.Unlock()
+	entry := pc.entry
+	entry.refCount--
+	if evict && !entry.evicted {
+		entry.evicted = true
+		delete(p.conns, pc.endpoint)
+	}
+	if entry.refCount > 0 {
+		return
+	}
+	if !entry.evicted {
+		delete(p.conns, pc.endpoint)
+	}
+	entry.cc.Close()
+}
+
+// Size returns the number of connections in the pool
+func (p *ConnectionPool) Size() int {
+	p.Lock()
+	defer p.Unlock()
+	return len(p.conns)
+}
+
+// Close closes all the connections in the pool
+func (p *ConnectionPool) Close() {
+	p.Lock()
+	defer p.Unlock()
+	for endpoint, entry := range p.conns {
+		entry.evicted = true
+		entry.cc.Close()
+		delete(p.conns, endpoint)
+	}
+}
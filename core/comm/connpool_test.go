@@ -0,0 +1,150 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+func newTestConnectionPool(dials *int) *ConnectionPool {
+	lock := &sync.Mutex{}
+	return NewConnectionPool(func(endpoint string) (*grpc.ClientConn, error) {
+		lock.Lock()
+		*dials++
+		lock.Unlock()
+		if endpoint == "" {
+			return nil, fmt.Errorf("empty endpoint")
+		}
+		// Without grpc.WithBlock() the connection is established lazily
+		return grpc.Dial(endpoint, grpc.WithInsecure())
+	})
+}
+
+func TestConnectionPoolSharing(t *testing.T) {
+	t.Parallel()
+	dials := 0
+	pool := newTestConnectionPool(&dials)
+
+	c1, err := pool.Acquire("localhost:7001")
+	assert.NoError(t, err)
+	c2, err := pool.Acquire("localhost:7001")
+	assert.NoError(t, err)
+	c3, err := pool.Acquire("localhost:7002")
+	assert.NoError(t, err)
+	assert.True(t, c1.ClientConn == c2.ClientConn)
+	assert.False(t, c1.ClientConn == c3.ClientConn)
+	assert.Equal(t, 2, dials)
+	assert.Equal(t, 2, pool.Size())
+
+	// The connection is kept as long as someone uses it
+	c1.Release()
+	c1.Release()
+	assert.Equal(t, 2, pool.Size())
+	c2.Release()
+	assert.Equal(t, 1, pool.Size())
+
+	// A released endpoint is dialed again
+	c1, err = pool.Acquire("localhost:7001")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, dials)
+
+	pool.Close()
+	assert.Equal(t, 0, pool.Size())
+	c1.Release()
+	c3.Release()
+
+	_, err = pool.Acquire("")
+	assert.Error(t, err)
+	assert.Equal(t, 0, pool.Size())
+}
+
+func TestConnectionPoolDiscard(t *testing.T) {
+	t.Parallel()
+	dials := 0
+	pool := newTestConnectionPool(&dials)
+
+	c1, _ := pool.Acquire("localhost:7003")
+	c2, _ := pool.Acquire("localhost:7003")
+	c1.Discard()
+	assert.Equal(t, 0, pool.Size())
+
+	// A discarded connection isn't handed out anymore, but it isn't closed
+	// under the feet of the ones still using it
+	c3, _ := pool.Acquire("localhost:7003")
+	assert.Equal(t, 2, dials)
+	assert.False(t, c2.ClientConn == c3.ClientConn)
+	c2.Release()
+	assert.Equal(t, 1, pool.Size())
+	c3.Release()
+	assert.Equal(t, 0, pool.Size())
+}
+
+func TestConnectionPoolConcurrentAcquire(t *testing.T) {
+	t.Parallel()
+	dials := 0
+	pool := newTestConnectionPool(&dials)
+	defer pool.Close()
+
+	conns := make(chan *PooledConn, 10)
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := pool.Acquire("localhost:7004")
+			assert.NoError(t, err)
+			conns <- c
+		}()
+	}
+	wg.Wait()
+	close(conns)
+
+	// Connections dialed concurrently are closed, and a single one is shared
+	var shared *grpc.ClientConn
+	for c := range conns {
+		if shared == nil {
+			shared = c.ClientConn
+		}
+		assert.True(t, shared == c.ClientConn)
+	}
+	assert.Equal(t, 1, pool.Size())
+}
+
+func TestPeerConnectionPool(t *testing.T) {
+	t.Parallel()
+	lsnr, err := net.Listen("tcp", "localhost:0")
+	assert.NoError(t, err)
+	srv := grpc.NewServer()
+	go srv.Serve(lsnr)
+	defer srv.Stop()
+
+	// The clients of the process share a single connection to the peer
+	endpoint := lsnr.Addr().String()
+	c1, err := PeerConnectionPool().Acquire(endpoint)
+	assert.NoError(t, err)
+	c2, err := PeerConnectionPool().Acquire(endpoint)
+	assert.NoError(t, err)
+	assert.True(t, c1.ClientConn == c2.ClientConn)
+	c1.Release()
+	c2.Release()
+}
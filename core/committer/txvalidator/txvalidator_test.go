@@ -17,10 +17,15 @@ limitations under the License.
 package txvalidator
 
 import (
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/configtx"
 	"github.com/hyperledger/fabric/common/configtx/test"
+	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	util2 "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
@@ -63,7 +68,7 @@ func TestBlockValidation(t *testing.T) {
 	}
 
 	mockVsccValidator := &validator.MockVsccValidator{}
-	tValidator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, mockVsccValidator}
+	tValidator := &txValidator{support: &mocktxvalidator.Support{LedgerVal: ledger}, vscc: mockVsccValidator, sem: make(chan struct{}, 4)}
 
 	bcInfo, _ := ledger.GetBlockchainInfo()
 	testutil.AssertEquals(t, bcInfo, &common.BlockchainInfo{
@@ -85,7 +90,7 @@ func TestBlockValidation(t *testing.T) {
 			CIns:     upgradeChaincodeIns,
 			RespPayl: prespPaylBytes,
 		}
-		newTxValidator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, newMockVsccValidator}
+		newTxValidator := &txValidator{support: &mocktxvalidator.Support{LedgerVal: ledger}, vscc: newMockVsccValidator}
 
 		// generate new block
 		newBlock := testutil.ConstructBlock(t, 2, block.Header.Hash(), [][]byte{simRes}, true) // contains one tx with chaincode version v1
@@ -108,7 +113,7 @@ func TestNewTxValidator_DuplicateTransactions(t *testing.T) {
 
 	defer ledger.Close()
 
-	tValidator := &txValidator{&mocktxvalidator.Support{LedgerVal: ledger}, &validator.MockVsccValidator{}}
+	tValidator := &txValidator{support: &mocktxvalidator.Support{LedgerVal: ledger}, vscc: &validator.MockVsccValidator{}, sem: make(chan struct{}, 4)}
 
 	// Create simple endorsement transaction
 	payload := &common.Payload{
@@ -162,6 +167,165 @@ func TestNewTxValidator_DuplicateTransactions(t *testing.T) {
 	assert.True(t, txsfltr.IsInvalid(0))
 }
 
+func TestParallelBlockValidation(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+
+	gb, _ := test.MakeGenesisBlock("TestLedger")
+	gbHash := gb.Header.Hash()
+	ledger, _ := ledgermgmt.CreateLedger(gb)
+	defer ledger.Close()
+
+	var simResults [][]byte
+	for i := 0; i < 20; i++ {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", fmt.Sprintf("key%d", i), []byte("value"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		simResults = append(simResults, simRes)
+	}
+
+	validate := func(poolSize int) util.TxValidationFlags {
+		block := testutil.ConstructBlock(t, 1, gbHash, simResults, true)
+		// repeat two transactions of the block, and add a nil and a bogus one
+		block.Data.Data = append(block.Data.Data, block.Data.Data[3], nil, []byte("garbage"), block.Data.Data[7])
+
+		tValidator := &txValidator{
+			support: &mocktxvalidator.Support{LedgerVal: ledger},
+			vscc:    &validator.MockVsccValidator{},
+			sem:     make(chan struct{}, poolSize),
+		}
+		err := tValidator.Validate(block)
+		assert.NoError(t, err)
+		return util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	}
+
+	txsfltr := validate(1)
+	assert.Len(t, txsfltr, 24)
+	for i := 0; i < 20; i++ {
+		assert.True(t, txsfltr.IsValid(i), "transaction %d should be valid", i)
+	}
+	// only the later occurrence of a txid is invalidated
+	assert.True(t, txsfltr.IsSetTo(20, peer.TxValidationCode_DUPLICATE_TXID))
+	assert.True(t, txsfltr.IsValid(21))
+	assert.True(t, txsfltr.IsInvalid(22))
+	assert.True(t, txsfltr.IsSetTo(23, peer.TxValidationCode_DUPLICATE_TXID))
+
+	// the outcome does not depend on the number of workers
+	for _, poolSize := range []int{2, 8, 32} {
+		assert.Equal(t, txsfltr, validate(poolSize), "pool size %d", poolSize)
+	}
+}
+
+// countingVsccValidator counts the transactions it is validating and has validated
+type countingVsccValidator struct {
+	lock      sync.Mutex
+	inFlight  int
+	validated int
+}
+
+func (v *countingVsccValidator) VSCCValidateTx(payload *common.Payload, envBytes []byte, env *common.Envelope) (error, peer.TxValidationCode) {
+	v.lock.Lock()
+	v.inFlight++
+	v.lock.Unlock()
+	time.Sleep(time.Millisecond)
+	v.lock.Lock()
+	v.inFlight--
+	v.validated++
+	v.lock.Unlock()
+	return nil, peer.TxValidationCode_VALID
+}
+
+// configOrderSupport records the number of transactions validated when each config is applied
+type configOrderSupport struct {
+	*mocktxvalidator.Support
+	vscc     *countingVsccValidator
+	inFlight []int
+	applied  []int
+}
+
+func (s *configOrderSupport) Apply(configtx *common.ConfigEnvelope) error {
+	s.vscc.lock.Lock()
+	defer s.vscc.lock.Unlock()
+	s.inFlight = append(s.inFlight, s.vscc.inFlight)
+	s.applied = append(s.applied, s.vscc.validated)
+	return nil
+}
+
+func TestConfigTxValidatedSerially(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/txvalidatortest")
+	ledgermgmt.InitializeTestEnv()
+	defer ledgermgmt.CleanupTestEnv()
+
+	gb, _ := test.MakeGenesisBlock("TestLedger")
+	ledger, _ := ledgermgmt.CreateLedger(gb)
+	defer ledger.Close()
+
+	var simResults [][]byte
+	for i := 0; i < 13; i++ {
+		simulator, _ := ledger.NewTxSimulator()
+		simulator.SetState("ns1", fmt.Sprintf("key%d", i), []byte("value"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		simResults = append(simResults, simRes)
+	}
+	block := testutil.ConstructBlock(t, 1, gb.Header.Hash(), simResults, true)
+	chCrtEnv, err := configtx.MakeChainCreationTransaction(genesisconfig.SampleConsortiumName, util2.GetTestChainID(), signer)
+	assert.NoError(t, err)
+	configEnv := &common.Envelope{
+		Payload: utils.MarshalOrPanic(&common.Payload{
+			Header: &common.Header{
+				ChannelHeader: utils.MarshalOrPanic(&common.ChannelHeader{
+					Type:      int32(common.HeaderType_CONFIG),
+					ChannelId: util2.GetTestChainID(),
+				}),
+				SignatureHeader: utils.MarshalOrPanic(&common.SignatureHeader{
+					Creator: signerSerialized,
+					Nonce:   utils.CreateNonceOrPanic(),
+				}),
+			},
+			Data: utils.MarshalOrPanic(&common.ConfigEnvelope{LastUpdate: chCrtEnv}),
+		}),
+	}
+	configEnv.Signature, _ = signer.Sign(configEnv.Payload)
+	configTx := utils.MarshalOrPanic(configEnv)
+	data := append([][]byte{}, block.Data.Data[:5]...)
+	data = append(data, configTx)
+	data = append(data, block.Data.Data[5:10]...)
+	data = append(data, configTx)
+	block.Data.Data = append(data, block.Data.Data[10:]...)
+
+	vscc := &countingVsccValidator{}
+	support := &configOrderSupport{Support: &mocktxvalidator.Support{LedgerVal: ledger}, vscc: vscc}
+	tValidator := &txValidator{support: support, vscc: vscc, sem: make(chan struct{}, 8)}
+	assert.NoError(t, tValidator.Validate(block))
+
+	// each config is applied once the transactions before it are validated, and before the ones after it
+	assert.Equal(t, []int{5, 10}, support.applied)
+	assert.Equal(t, []int{0, 0}, support.inFlight)
+	assert.Equal(t, 13, vscc.validated)
+	txsfltr := util.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	for i := range block.Data.Data {
+		assert.True(t, txsfltr.IsValid(i), "transaction %d should be valid", i)
+	}
+}
+
+func TestMarkTXIdDuplicates(t *testing.T) {
+	txids := []string{"a", "b", "", "a", "", "c", "b", "a"}
+	txsfltr := ledgerUtil.NewTxValidationFlags(len(txids))
+	txsfltr.SetFlag(1, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
+
+	markTXIdDuplicates(txids, txsfltr)
+
+	expectTxsFltr := ledgerUtil.NewTxValidationFlags(len(txids))
+	expectTxsFltr.SetFlag(1, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
+	expectTxsFltr.SetFlag(3, peer.TxValidationCode_DUPLICATE_TXID)
+	expectTxsFltr.SetFlag(6, peer.TxValidationCode_DUPLICATE_TXID)
+	expectTxsFltr.SetFlag(7, peer.TxValidationCode_DUPLICATE_TXID)
+	assert.EqualValues(t, expectTxsFltr, txsfltr)
+}
+
 func createCCUpgradeEnvelope(chainID, chaincodeName, chaincodeVersion string, signer msp.SigningIdentity) (*common.Envelope, error) {
 	creator, err := signer.Serialize()
 	if err != nil {
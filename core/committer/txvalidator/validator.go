@@ -18,6 +18,8 @@ package txvalidator
 
 import (
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/configtx"
@@ -35,6 +37,7 @@ import (
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
+	"github.com/spf13/viper"
 
 	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
@@ -74,7 +77,6 @@ type vsccValidator interface {
 // vscc chaincode and validate block transactions
 type vsccValidatorImpl struct {
 	support     Support
-	ccprovider  ccprovider.ChaincodeProvider
 	sccprovider sysccprovider.SystemChaincodeProvider
 }
 
@@ -84,6 +86,9 @@ type vsccValidatorImpl struct {
 type txValidator struct {
 	support Support
 	vscc    vsccValidator
+	// sem bounds the number of transactions of a
+	// block that are validated concurrently
+	sem chan struct{}
 }
 
 // VSCCInfoLookupFailureError error to indicate inability
@@ -130,11 +135,23 @@ func init() {
 // NewTxValidator creates new transactions validator
 func NewTxValidator(support Support) Validator {
 	// Encapsulates interface implementation
-	return &txValidator{support,
-		&vsccValidatorImpl{
+	return &txValidator{
+		support: support,
+		vscc: &vsccValidatorImpl{
 			support:     support,
-			ccprovider:  ccprovider.GetChaincodeProvider(),
-			sccprovider: sysccprovider.GetSystemChaincodeProvider()}}
+			sccprovider: sysccprovider.GetSystemChaincodeProvider()},
+		sem: make(chan struct{}, getValidatorPoolSize())}
+}
+
+// getValidatorPoolSize returns the number of transactions
+// validated in parallel, as configured in core.yaml; it
+// defaults to the number of CPUs of the machine
+func getValidatorPoolSize() int {
+	size := viper.GetInt("peer.validatorPoolSize")
+	if size <= 0 {
+		size = runtime.NumCPU()
+	}
+	return size
 }
 
 func (v *txValidator) chainExists(chain string) bool {
@@ -142,6 +159,17 @@ func (v *txValidator) chainExists(chain string) bool {
 	return true
 }
 
+// blockValidationResult holds the outcome of the
+// validation of a single transaction of a block
+type blockValidationResult struct {
+	tIdx                 int
+	validationCode       peer.TxValidationCode
+	txid                 string
+	txsChaincodeName     *sysccprovider.ChaincodeInstance
+	txsUpgradedChaincode *sysccprovider.ChaincodeInstance
+	err                  error
+}
+
 func (v *txValidator) Validate(block *common.Block) error {
 	logger.Debug("START Block Validation")
 	defer logger.Debug("END Block Validation")
@@ -151,113 +179,78 @@ func (v *txValidator) Validate(block *common.Block) error {
 	txsChaincodeNames := make(map[int]*sysccprovider.ChaincodeInstance)
 	// upgradedChaincodes records all the chaincodes that are upgrded in a block
 	txsUpgradedChaincodes := make(map[int]*sysccprovider.ChaincodeInstance)
-	for tIdx, d := range block.Data.Data {
-		if d != nil {
-			if env, err := utils.GetEnvelopeFromBlock(d); err != nil {
-				logger.Warningf("Error getting tx from block(%s)", err)
-				txsfltr.SetFlag(tIdx, peer.TxValidationCode_INVALID_OTHER_REASON)
-			} else if env != nil {
-				// validate the transaction: here we check that the transaction
-				// is properly formed, properly signed and that the security
-				// chain binding proposal to endorsements to tx holds. We do
-				// NOT check the validity of endorsements, though. That's a
-				// job for VSCC below
-				logger.Debug("Validating transaction peer.ValidateTransaction()")
-				var payload *common.Payload
-				var err error
-				var txResult peer.TxValidationCode
-
-				if payload, txResult = validation.ValidateTransaction(env); txResult != peer.TxValidationCode_VALID {
-					logger.Errorf("Invalid transaction with index %d", tIdx)
-					txsfltr.SetFlag(tIdx, txResult)
-					continue
-				}
+	// txids records the txid of each endorser transaction in a block
+	txids := make([]string, len(block.Data.Data))
+
+	sem := v.sem
+	if sem == nil {
+		sem = make(chan struct{}, 1)
+	}
+
+	// Transactions are validated concurrently by at most cap(sem)
+	// workers; the results are collected by index so that merging
+	// them below does not depend on the order in which they arrive.
+	// Config transactions change the configuration the other ones
+	// are validated against, hence each of them is validated and
+	// applied on its own, once the transactions preceding it in the
+	// block are validated and before the following ones are started
+	results := make(chan *blockValidationResult, len(block.Data.Data))
+	go func() {
+		var wg sync.WaitGroup
+		for tIdx, d := range block.Data.Data {
+			if isConfigTx(d) {
+				wg.Wait()
+				results <- v.validateTx(block, tIdx, d)
+				continue
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(tIdx int, d []byte) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				results <- v.validateTx(block, tIdx, d)
+			}(tIdx, d)
+		}
+	}()
 
-				chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
-				if err != nil {
-					logger.Warningf("Could not unmarshal channel header, err %s, skipping", err)
-					txsfltr.SetFlag(tIdx, peer.TxValidationCode_INVALID_OTHER_REASON)
-					continue
-				}
+	var err error
+	errPos := -1
+	for i := 0; i < len(block.Data.Data); i++ {
+		res := <-results
+
+		if res.err != nil {
+			// keep the error of the first failing transaction
+			// so that the outcome is the same for every peer
+			if errPos == -1 || res.tIdx < errPos {
+				err = res.err
+				errPos = res.tIdx
+			}
+			continue
+		}
 
-				channel := chdr.ChannelId
-				logger.Debugf("Transaction is for chain %s", channel)
+		txsfltr.SetFlag(res.tIdx, res.validationCode)
+		txids[res.tIdx] = res.txid
+		if res.txsChaincodeName != nil {
+			txsChaincodeNames[res.tIdx] = res.txsChaincodeName
+		}
+		if res.txsUpgradedChaincode != nil {
+			txsUpgradedChaincodes[res.tIdx] = res.txsUpgradedChaincode
+		}
+	}
 
-				if !v.chainExists(channel) {
-					logger.Errorf("Dropping transaction for non-existent chain %s", channel)
-					txsfltr.SetFlag(tIdx, peer.TxValidationCode_TARGET_CHAIN_NOT_FOUND)
-					continue
-				}
+	if err != nil {
+		logger.Errorf("Validation of transaction with index %d failed: %s", errPos, err)
+		return err
+	}
 
-				if common.HeaderType(chdr.Type) == common.HeaderType_ENDORSER_TRANSACTION {
-					// Check duplicate transactions
-					txID := chdr.TxId
-					if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil {
-						logger.Error("Duplicate transaction found, ", txID, ", skipping")
-						txsfltr.SetFlag(tIdx, peer.TxValidationCode_DUPLICATE_TXID)
-						continue
-					}
-
-					// Validate tx with vscc and policy
-					logger.Debug("Validating transaction vscc tx validate")
-					err, cde := v.vscc.VSCCValidateTx(payload, d, env)
-					if err != nil {
-						txID := txID
-						logger.Errorf("VSCCValidateTx for transaction txId = %s returned error %s", txID, err)
-						switch err.(type) {
-						case *VSCCExecutionFailureError:
-							return err
-						case *VSCCInfoLookupFailureError:
-							return err
-						default:
-							txsfltr.SetFlag(tIdx, cde)
-							continue
-						}
-					}
-
-					invokeCC, upgradeCC, err := v.getTxCCInstance(payload)
-					if err != nil {
-						logger.Errorf("Get chaincode instance from transaction txId = %s returned error %s", txID, err)
-						txsfltr.SetFlag(tIdx, peer.TxValidationCode_INVALID_OTHER_REASON)
-						continue
-					}
-					txsChaincodeNames[tIdx] = invokeCC
-					if upgradeCC != nil {
-						logger.Infof("Find chaincode upgrade transaction for chaincode %s on chain %s with new version %s", upgradeCC.ChaincodeName, upgradeCC.ChainID, upgradeCC.ChaincodeVersion)
-						txsUpgradedChaincodes[tIdx] = upgradeCC
-					}
-				} else if common.HeaderType(chdr.Type) == common.HeaderType_CONFIG {
-					configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
-					if err != nil {
-						err := fmt.Errorf("Error unmarshaling config which passed initial validity checks: %s", err)
-						logger.Critical(err)
-						return err
-					}
-
-					if err := v.support.Apply(configEnvelope); err != nil {
-						err := fmt.Errorf("Error validating config which passed initial validity checks: %s", err)
-						logger.Critical(err)
-						return err
-					}
-					logger.Debugf("config transaction received for chain %s", channel)
-				} else {
-					logger.Warningf("Unknown transaction type [%s] in block number [%d] transaction index [%d]",
-						common.HeaderType(chdr.Type), block.Header.Number, tIdx)
-					txsfltr.SetFlag(tIdx, peer.TxValidationCode_UNKNOWN_TX_TYPE)
-					continue
-				}
+	// Mark the transactions whose txid already appears earlier in the
+	// block; this has to happen in block order, the first occurrence wins
+	markTXIdDuplicates(txids, txsfltr)
 
-				if _, err := proto.Marshal(env); err != nil {
-					logger.Warningf("Cannot marshal transaction due to %s", err)
-					txsfltr.SetFlag(tIdx, peer.TxValidationCode_MARSHAL_TX_ERROR)
-					continue
-				}
-				// Succeeded to pass down here, transaction is valid
-				txsfltr.SetFlag(tIdx, peer.TxValidationCode_VALID)
-			} else {
-				logger.Warning("Nil tx from block")
-				txsfltr.SetFlag(tIdx, peer.TxValidationCode_NIL_ENVELOPE)
-			}
+	// Only upgrades which are still valid can invalidate other transactions
+	for tIdx := range txsUpgradedChaincodes {
+		if !txsfltr.IsValid(tIdx) {
+			delete(txsUpgradedChaincodes, tIdx)
 		}
 	}
 
@@ -271,6 +264,158 @@ func (v *txValidator) Validate(block *common.Block) error {
 	return nil
 }
 
+// isConfigTx returns whether the transaction is a config
+// transaction, if it is well formed enough to tell
+func isConfigTx(d []byte) bool {
+	if d == nil {
+		return false
+	}
+	env, err := utils.GetEnvelopeFromBlock(d)
+	if err != nil {
+		return false
+	}
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil {
+		return false
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		return false
+	}
+	return common.HeaderType(chdr.Type) == common.HeaderType_CONFIG
+}
+
+// markTXIdDuplicates invalidates every transaction whose txid
+// was already used by a preceding transaction of the same block
+func markTXIdDuplicates(txids []string, txsfltr ledgerUtil.TxValidationFlags) {
+	seen := make(map[string]bool)
+	for tIdx, txid := range txids {
+		if txid == "" {
+			continue
+		}
+
+		if seen[txid] {
+			logger.Errorf("Duplicate transaction found in block, %s, skipping", txid)
+			txsfltr.SetFlag(tIdx, peer.TxValidationCode_DUPLICATE_TXID)
+			continue
+		}
+		seen[txid] = true
+	}
+}
+
+// validateTx validates the transaction with index tIdx of the block; an
+// error in the result means that the whole block could not be validated
+func (v *txValidator) validateTx(block *common.Block, tIdx int, d []byte) *blockValidationResult {
+	if d == nil {
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_VALID}
+	}
+
+	env, err := utils.GetEnvelopeFromBlock(d)
+	if err != nil {
+		logger.Warningf("Error getting tx from block(%s)", err)
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_INVALID_OTHER_REASON}
+	}
+	if env == nil {
+		logger.Warning("Nil tx from block")
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_NIL_ENVELOPE}
+	}
+
+	// validate the transaction: here we check that the transaction
+	// is properly formed, properly signed and that the security
+	// chain binding proposal to endorsements to tx holds. We do
+	// NOT check the validity of endorsements, though. That's a
+	// job for VSCC below
+	logger.Debug("Validating transaction peer.ValidateTransaction()")
+	var payload *common.Payload
+	var txResult peer.TxValidationCode
+	var txID string
+	var invokeCC, upgradeCC *sysccprovider.ChaincodeInstance
+
+	if payload, txResult = validation.ValidateTransaction(env); txResult != peer.TxValidationCode_VALID {
+		logger.Errorf("Invalid transaction with index %d", tIdx)
+		return &blockValidationResult{tIdx: tIdx, validationCode: txResult}
+	}
+
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil {
+		logger.Warningf("Could not unmarshal channel header, err %s, skipping", err)
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_INVALID_OTHER_REASON}
+	}
+
+	channel := chdr.ChannelId
+	logger.Debugf("Transaction is for chain %s", channel)
+
+	if !v.chainExists(channel) {
+		logger.Errorf("Dropping transaction for non-existent chain %s", channel)
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_TARGET_CHAIN_NOT_FOUND}
+	}
+
+	if common.HeaderType(chdr.Type) == common.HeaderType_ENDORSER_TRANSACTION {
+		// Check duplicate transactions
+		txID = chdr.TxId
+		if _, err := v.support.Ledger().GetTransactionByID(txID); err == nil {
+			logger.Error("Duplicate transaction found, ", txID, ", skipping")
+			return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_DUPLICATE_TXID}
+		}
+
+		// Validate tx with vscc and policy
+		logger.Debug("Validating transaction vscc tx validate")
+		err, cde := v.vscc.VSCCValidateTx(payload, d, env)
+		if err != nil {
+			logger.Errorf("VSCCValidateTx for transaction txId = %s returned error %s", txID, err)
+			switch err.(type) {
+			case *VSCCExecutionFailureError:
+				return &blockValidationResult{tIdx: tIdx, err: err}
+			case *VSCCInfoLookupFailureError:
+				return &blockValidationResult{tIdx: tIdx, err: err}
+			default:
+				return &blockValidationResult{tIdx: tIdx, validationCode: cde, txid: txID}
+			}
+		}
+
+		invokeCC, upgradeCC, err = v.getTxCCInstance(payload)
+		if err != nil {
+			logger.Errorf("Get chaincode instance from transaction txId = %s returned error %s", txID, err)
+			return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_INVALID_OTHER_REASON, txid: txID}
+		}
+		if upgradeCC != nil {
+			logger.Infof("Find chaincode upgrade transaction for chaincode %s on chain %s with new version %s", upgradeCC.ChaincodeName, upgradeCC.ChainID, upgradeCC.ChaincodeVersion)
+		}
+	} else if common.HeaderType(chdr.Type) == common.HeaderType_CONFIG {
+		configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+		if err != nil {
+			err := fmt.Errorf("Error unmarshaling config which passed initial validity checks: %s", err)
+			logger.Critical(err)
+			return &blockValidationResult{tIdx: tIdx, err: err}
+		}
+
+		if err := v.support.Apply(configEnvelope); err != nil {
+			err := fmt.Errorf("Error validating config which passed initial validity checks: %s", err)
+			logger.Critical(err)
+			return &blockValidationResult{tIdx: tIdx, err: err}
+		}
+		logger.Debugf("config transaction received for chain %s", channel)
+	} else {
+		logger.Warningf("Unknown transaction type [%s] in block number [%d] transaction index [%d]",
+			common.HeaderType(chdr.Type), block.Header.Number, tIdx)
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_UNKNOWN_TX_TYPE}
+	}
+
+	if _, err := proto.Marshal(env); err != nil {
+		logger.Warningf("Cannot marshal transaction due to %s", err)
+		return &blockValidationResult{tIdx: tIdx, validationCode: peer.TxValidationCode_MARSHAL_TX_ERROR, txid: txID}
+	}
+
+	// Succeeded to pass down here, transaction is valid
+	return &blockValidationResult{
+		tIdx:                 tIdx,
+		validationCode:       peer.TxValidationCode_VALID,
+		txid:                 txID,
+		txsChaincodeName:     invokeCC,
+		txsUpgradedChaincode: upgradeCC,
+	}
+}
+
 // generateCCKey generates a unique identifier for chaincode in specific chain
 func (v *txValidator) generateCCKey(ccName, chainID string) string {
 	return fmt.Sprintf("%s/%s", ccName, chainID)
@@ -600,13 +745,16 @@ func (v *vsccValidatorImpl) VSCCValidateTx(payload *common.Payload, envBytes []b
 }
 
 func (v *vsccValidatorImpl) VSCCValidateTxForCC(envBytes []byte, txid, chid, vsccName, vsccVer string, policy []byte) error {
-	ctxt, err := v.ccprovider.GetContext(v.support.Ledger())
+	// a chaincode provider keeps the tx simulator of its context, so
+	// transactions validated in parallel must not share an instance
+	ccp := ccprovider.GetChaincodeProvider()
+	ctxt, err := ccp.GetContext(v.support.Ledger())
 	if err != nil {
 		msg := fmt.Sprintf("Cannot obtain context for txid=%s, err %s", txid, err)
 		logger.Errorf(msg)
 		return &VSCCExecutionFailureError{msg}
 	}
-	defer v.ccprovider.ReleaseContext()
+	defer ccp.ReleaseContext()
 
 	// build arguments for VSCC invocation
 	// args[0] - function name (not used now)
@@ -616,11 +764,11 @@ func (v *vsccValidatorImpl) VSCCValidateTxForCC(envBytes []byte, txid, chid, vsc
 
 	// get context to invoke VSCC
 	vscctxid := coreUtil.GenerateUUID()
-	cccid := v.ccprovider.GetCCContext(chid, vsccName, vsccVer, vscctxid, true, nil, nil)
+	cccid := ccp.GetCCContext(chid, vsccName, vsccVer, vscctxid, true, nil, nil)
 
 	// invoke VSCC
 	logger.Debug("Invoking VSCC txid", txid, "chaindID", chid)
-	res, _, err := v.ccprovider.ExecuteChaincode(ctxt, cccid, args)
+	res, _, err := ccp.ExecuteChaincode(ctxt, cccid, args)
 	if err != nil {
 		msg := fmt.Sprintf("Invoke VSCC failed for transaction txid=%s, error %s", txid, err)
 		logger.Errorf(msg)
@@ -0,0 +1,108 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccprovider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
+)
+
+// StatedbArtifactsDir is the directory of a chaincode package holding the
+// artifacts, such as index definitions, meant for the state database
+const StatedbArtifactsDir = "META-INF/statedb/"
+
+// CouchDBIndexesDir is the directory of a chaincode package holding the
+// CouchDB index definitions, one JSON file per index
+const CouchDBIndexesDir = StatedbArtifactsDir + "couchdb/indexes/"
+
+// ExtractStatedbArtifactsFromCodePackage returns the files found under META-INF/statedb
+// in the code package of a chaincode, keyed by their path relative to that directory.
+// Code packages that are not gzipped tar archives carry no statedb artifacts
+func ExtractStatedbArtifactsFromCodePackage(codePackage []byte) (map[string][]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(codePackage))
+	if err != nil {
+		ccproviderLogger.Debugf("code package is not gzipped (%s), no statedb artifacts", err)
+		return nil, nil
+	}
+	defer gr.Close()
+
+	dbArtifacts := make(map[string][]byte)
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading the code package: %s", err)
+		}
+		if header.Typeflag != tar.TypeReg && header.Typeflag != tar.TypeRegA {
+			continue
+		}
+		if !strings.HasPrefix(header.Name, StatedbArtifactsDir) {
+			continue
+		}
+		content, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s from the code package: %s", header.Name, err)
+		}
+		dbArtifacts[strings.TrimPrefix(header.Name, StatedbArtifactsDir)] = content
+	}
+	return dbArtifacts, nil
+}
+
+// ChaincodeInfoProviderImpl implements interface cceventmgmt.ChaincodeInfoProvider
+// on top of the chaincodes installed in the file system
+type ChaincodeInfoProviderImpl struct{}
+
+// GetChaincodeDefinition implements method in interface cceventmgmt.ChaincodeInfoProvider
+func (*ChaincodeInfoProviderImpl) GetChaincodeDefinition(lsccValue []byte) (*cceventmgmt.ChaincodeDefinition, error) {
+	cd := &ChaincodeData{}
+	if err := proto.Unmarshal(lsccValue, cd); err != nil {
+		return nil, fmt.Errorf("error unmarshalling chaincode data: %s", err)
+	}
+	return &cceventmgmt.ChaincodeDefinition{Name: cd.Name, Version: cd.Version}, nil
+}
+
+// RetrieveChaincodeArtifacts implements method in interface cceventmgmt.ChaincodeInfoProvider
+func (*ChaincodeInfoProviderImpl) RetrieveChaincodeArtifacts(chaincodeDefinition *cceventmgmt.ChaincodeDefinition) (bool, map[string][]byte, error) {
+	exists, err := ChaincodePackageExists(chaincodeDefinition.Name, chaincodeDefinition.Version)
+	if !exists {
+		if os.IsNotExist(err) {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+	ccpack, err := GetChaincodeFromFS(chaincodeDefinition.Name, chaincodeDefinition.Version)
+	if err != nil {
+		return false, nil, err
+	}
+	dbArtifacts, err := ExtractStatedbArtifactsFromCodePackage(ccpack.GetDepSpec().CodePackage)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, dbArtifacts, nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ccprovider
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func getCodePackage(t *testing.T, files map[string]string) []byte {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0100644, Size: int64(len(content))})
+		assert.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, gw.Close())
+	return buf.Bytes()
+}
+
+func TestExtractStatedbArtifactsFromCodePackage(t *testing.T) {
+	codePackage := getCodePackage(t, map[string]string{
+		"src/github.com/example/cc/cc.go":                  "package main",
+		"META-INF/statedb/couchdb/indexes/indexOwner.json": `{"index":{"fields":["owner"]}}`,
+		"META-INF/other/file.txt":                          "not a statedb artifact",
+	})
+
+	dbArtifacts, err := ExtractStatedbArtifactsFromCodePackage(codePackage)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"couchdb/indexes/indexOwner.json": []byte(`{"index":{"fields":["owner"]}}`)}, dbArtifacts)
+
+	// packages that are not gzipped, such as car packages, have no artifacts
+	dbArtifacts, err = ExtractStatedbArtifactsFromCodePackage([]byte("code"))
+	assert.NoError(t, err)
+	assert.Nil(t, dbArtifacts)
+
+	// a corrupted package is an error
+	_, err = ExtractStatedbArtifactsFromCodePackage(codePackage[:len(codePackage)/2])
+	assert.Error(t, err)
+}
+
+func TestChaincodeInfoProviderImpl(t *testing.T) {
+	ccdir := setupccdir()
+	defer os.RemoveAll(ccdir)
+
+	provider := &ChaincodeInfoProviderImpl{}
+
+	cd, err := provider.GetChaincodeDefinition(utils.MarshalOrPanic(&ChaincodeData{Name: "testcc", Version: "0", Escc: "escc"}))
+	assert.NoError(t, err)
+	assert.Equal(t, &cceventmgmt.ChaincodeDefinition{Name: "testcc", Version: "0"}, cd)
+
+	_, err = provider.GetChaincodeDefinition([]byte("barf"))
+	assert.Error(t, err)
+
+	installed, _, err := provider.RetrieveChaincodeArtifacts(cd)
+	assert.NoError(t, err)
+	assert.False(t, installed)
+
+	codePackage := getCodePackage(t, map[string]string{"META-INF/statedb/couchdb/indexes/indexOwner.json": "{}"})
+	cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: &pb.ChaincodeSpec{Type: 1, ChaincodeId: &pb.ChaincodeID{Name: "testcc", Version: "0"},
+		Input: &pb.ChaincodeInput{Args: [][]byte{[]byte("")}}}, CodePackage: codePackage}
+	_, _, _, err = processCDS(cds, true)
+	assert.NoError(t, err)
+
+	installed, dbArtifacts, err := provider.RetrieveChaincodeArtifacts(cd)
+	assert.NoError(t, err)
+	assert.True(t, installed)
+	assert.Equal(t, map[string][]byte{"couchdb/indexes/indexOwner.json": []byte("{}")}, dbArtifacts)
+}
@@ -40,6 +40,20 @@ type CCSupport interface {
 	HandleChaincodeStream(context.Context, ChaincodeStream) error
 }
 
+// ResourceViolationHandler may be implemented by the chaincode support side in
+// peer to be told when a chaincode container exceeds the resource profile it
+// was started with. The VM has stopped monitoring the container at that point
+type ResourceViolationHandler interface {
+	HandleResourceViolation(ccid CCID, reason string)
+}
+
+// ContainerExitHandler may be implemented by the chaincode support side in
+// peer to be told when a chaincode container exits, including when it is
+// stopped by the peer
+type ContainerExitHandler interface {
+	HandleContainerExit(ccid CCID, exitCode int)
+}
+
 // GetCCHandlerKey is used to pass CCSupport via context
 func GetCCHandlerKey() string {
 	return "CCHANDLER"
@@ -78,6 +78,12 @@ type dockerClient interface {
 	KillContainer(opts docker.KillContainerOptions) error
 	// RemoveContainer removes a docker container, returns an error in case of failure
 	RemoveContainer(opts docker.RemoveContainerOptions) error
+	// Stats sends the resource usage statistics of a docker container to the
+	// channel in opts, returns an error in case of failure
+	Stats(opts docker.StatsOptions) error
+	// WaitContainer blocks until a docker container exits and returns its exit
+	// code, returns an error in case of failure
+	WaitContainer(id string) (int, error)
 }
 
 // NewDockerVM returns a new DockerVM instance
@@ -153,9 +159,9 @@ func getDockerHostConfig() *docker.HostConfig {
 
 func (vm *DockerVM) createContainer(ctxt context.Context, client dockerClient,
 	imageID string, containerID string, args []string,
-	env []string, attachStdout bool) error {
+	env []string, attachStdout bool, hostConfig *docker.HostConfig) error {
 	config := docker.Config{Cmd: args, Image: imageID, Env: env, AttachStdout: attachStdout, AttachStderr: attachStdout}
-	copts := docker.CreateContainerOptions{Name: containerID, Config: &config, HostConfig: getDockerHostConfig()}
+	copts := docker.CreateContainerOptions{Name: containerID, Config: &config, HostConfig: hostConfig}
 	dockerLogger.Debugf("Create container: %s", containerID)
 	_, err := client.CreateContainer(copts)
 	if err != nil {
@@ -229,13 +235,14 @@ func (vm *DockerVM) Start(ctxt context.Context, ccid ccintf.CCID,
 	}
 
 	attachStdout := viper.GetBool("vm.docker.attachStdout")
+	hostConfig, profile := getChaincodeHostConfig(ccid)
 
 	//stop,force remove if necessary
 	dockerLogger.Debugf("Cleanup container %s", containerID)
 	vm.stopInternal(ctxt, client, containerID, 0, false, false)
 
 	dockerLogger.Debugf("Start container %s", containerID)
-	err = vm.createContainer(ctxt, client, imageID, containerID, args, env, attachStdout)
+	err = vm.createContainer(ctxt, client, imageID, containerID, args, env, attachStdout, hostConfig)
 	if err != nil {
 		//if image not found try to create image and retry
 		if err == docker.ErrNoSuchImage {
@@ -254,7 +261,7 @@ func (vm *DockerVM) Start(ctxt context.Context, ccid ccintf.CCID,
 				}
 
 				dockerLogger.Debug("start-recreated image successfully")
-				if err1 = vm.createContainer(ctxt, client, imageID, containerID, args, env, attachStdout); err1 != nil {
+				if err1 = vm.createContainer(ctxt, client, imageID, containerID, args, env, attachStdout, hostConfig); err1 != nil {
 					dockerLogger.Errorf("start-could not recreate container post recreate image: %s", err1)
 					return err1
 				}
@@ -352,6 +359,27 @@ func (vm *DockerVM) Start(ctxt context.Context, ccid ccintf.CCID,
 	}
 
 	dockerLogger.Debugf("Started container %s", containerID)
+
+	//tell ChaincodeSupport when the container exits
+	if handler, ok := ctxt.Value(ccintf.GetCCHandlerKey()).(ccintf.ContainerExitHandler); ok {
+		go func() {
+			exitCode, err := client.WaitContainer(containerID)
+			if err != nil {
+				dockerLogger.Debugf("Error waiting for container %s: %s", containerID, err)
+				return
+			}
+			dockerLogger.Debugf("Container %s exited with code %d", containerID, exitCode)
+			handler.HandleContainerExit(ccid, exitCode)
+		}()
+	}
+
+	//watch the container usage if it has a resource profile and there is
+	//someone to tell about it (ChaincodeSupport passes itself in the context)
+	if interval := getMonitorInterval(); profile != nil && interval > 0 {
+		if handler, ok := ctxt.Value(ccintf.GetCCHandlerKey()).(ccintf.ResourceViolationHandler); ok {
+			containerMonitor.watch(client, containerID, ccid, profile, interval, handler)
+		}
+	}
 	return nil
 }
 
@@ -376,6 +404,8 @@ func (vm *DockerVM) Stop(ctxt context.Context, ccid ccintf.CCID, timeout uint, d
 
 func (vm *DockerVM) stopInternal(ctxt context.Context, client dockerClient,
 	id string, timeout uint, dontkill bool, dontremove bool) error {
+	containerMonitor.unwatch(id)
+
 	err := client.StopContainer(id, timeout)
 	if err != nil {
 		dockerLogger.Debugf("Stop container %s(%s)", id, err)
@@ -293,10 +293,12 @@ func getMockClient() (dockerClient, error) {
 
 type mockClient struct {
 	noSuchImgErrReturned bool
+	// stats is the sample returned by Stats, an empty one if nil
+	stats *docker.Stats
 }
 
 var getClientErr, createErr, noSuchImgErr, buildErr, removeImgErr,
-	startErr, stopErr, killErr, removeErr bool
+	startErr, stopErr, killErr, removeErr, statsErr bool
 
 func (c *mockClient) CreateContainer(options docker.CreateContainerOptions) (*docker.Container, error) {
 	if createErr {
@@ -357,6 +359,23 @@ func (c *mockClient) RemoveContainer(opts docker.RemoveContainerOptions) error {
 	return nil
 }
 
+func (c *mockClient) Stats(opts docker.StatsOptions) error {
+	defer close(opts.Stats)
+	if statsErr {
+		return errors.New("Error getting container stats")
+	}
+	if c.stats == nil {
+		opts.Stats <- &docker.Stats{}
+	} else {
+		opts.Stats <- c.stats
+	}
+	return nil
+}
+
+func (c *mockClient) WaitContainer(id string) (int, error) {
+	return 0, nil
+}
+
 func formatInvalidChars(name string) (string, error) {
 	return "inv@lid*character$/", nil
 }
@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockercontroller
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/spf13/viper"
+)
+
+const defaultMonitorInterval = 10 * time.Second
+
+// containerMonitor watches every container started with a resource profile.
+// DockerVM instances are created per request, hence the package level monitor
+var containerMonitor = newResourceMonitor()
+
+// resourceMonitor periodically samples the usage of chaincode containers and
+// reports the ones exceeding their resource profile
+type resourceMonitor struct {
+	sync.Mutex
+	watched map[string]chan struct{}
+}
+
+func newResourceMonitor() *resourceMonitor {
+	return &resourceMonitor{watched: make(map[string]chan struct{})}
+}
+
+// getMonitorInterval returns the sampling interval of the resource monitor, or
+// 0 if monitoring is disabled
+func getMonitorInterval() time.Duration {
+	if !viper.GetBool("vm.docker.resourceMonitor.enabled") {
+		return 0
+	}
+	interval := viper.GetDuration("vm.docker.resourceMonitor.interval")
+	if interval <= 0 {
+		dockerLogger.Warningf("Invalid vm.docker.resourceMonitor.interval %s, defaulting to %s", interval, defaultMonitorInterval)
+		interval = defaultMonitorInterval
+	}
+	return interval
+}
+
+// watch starts sampling the container every interval. The first time its
+// usage exceeds the profile the handler is notified and the container is no
+// longer watched
+func (m *resourceMonitor) watch(client dockerClient, containerID string, ccid ccintf.CCID,
+	profile *ResourceProfile, interval time.Duration, handler ccintf.ResourceViolationHandler) {
+	m.Lock()
+	defer m.Unlock()
+
+	if done, ok := m.watched[containerID]; ok {
+		close(done)
+	}
+	done := make(chan struct{})
+	m.watched[containerID] = done
+
+	dockerLogger.Debugf("Monitoring container %s against resource profile %s every %s", containerID, profile.Name, interval)
+	go m.run(client, containerID, ccid, profile, interval, handler, done)
+}
+
+// unwatch stops sampling the container, if it is being watched
+func (m *resourceMonitor) unwatch(containerID string) {
+	m.Lock()
+	defer m.Unlock()
+
+	if done, ok := m.watched[containerID]; ok {
+		close(done)
+		delete(m.watched, containerID)
+	}
+}
+
+// release drops the watch on the container if done still identifies it.
+// Returns false if the watch has been stopped or replaced meanwhile
+func (m *resourceMonitor) release(containerID string, done chan struct{}) bool {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.watched[containerID] != done {
+		return false
+	}
+	delete(m.watched, containerID)
+	return true
+}
+
+func (m *resourceMonitor) run(client dockerClient, containerID string, ccid ccintf.CCID,
+	profile *ResourceProfile, interval time.Duration, handler ccintf.ResourceViolationHandler, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var prev *docker.Stats
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		stats, err := sampleStats(client, containerID, interval)
+		if err != nil {
+			dockerLogger.Debugf("Could not sample usage of container %s: %s", containerID, err)
+			continue
+		}
+
+		if reason := checkResourceUsage(profile, prev, stats); reason != "" {
+			// the handler restarts the chaincode, which watches the new
+			// container, so release this watch before notifying it
+			if m.release(containerID, done) {
+				dockerLogger.Warningf("Container %s exceeded resource profile %s: %s", containerID, profile.Name, reason)
+				handler.HandleResourceViolation(ccid, reason)
+			}
+			return
+		}
+		prev = stats
+	}
+}
+
+// sampleStats returns a single usage sample of the container
+func sampleStats(client dockerClient, containerID string, timeout time.Duration) (*docker.Stats, error) {
+	statsChan := make(chan *docker.Stats)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- client.Stats(docker.StatsOptions{ID: containerID, Stats: statsChan, Stream: false, Timeout: timeout})
+	}()
+
+	var stats *docker.Stats
+	for s := range statsChan {
+		stats = s
+	}
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		return nil, fmt.Errorf("no stats returned for container %s", containerID)
+	}
+	return stats, nil
+}
+
+// checkResourceUsage returns why the sample exceeds the profile, or an empty
+// string if it does not. prev is the previous sample of the same container,
+// nil for the first one
+func checkResourceUsage(profile *ResourceProfile, prev, cur *docker.Stats) string {
+	if profile.Memory > 0 {
+		// page cache is reclaimed by the kernel under pressure, so it does
+		// not count against the limit
+		usage := cur.MemoryStats.Usage
+		if cache := cur.MemoryStats.Stats.Cache; cache < usage {
+			usage -= cache
+		}
+		if usage >= uint64(profile.Memory) {
+			return fmt.Sprintf("memory usage of %d bytes reached the limit of %d bytes", usage, profile.Memory)
+		}
+	}
+
+	if profile.PidsLimit > 0 && cur.PidsStats.Current >= uint64(profile.PidsLimit) {
+		return fmt.Sprintf("%d processes reached the limit of %d", cur.PidsStats.Current, profile.PidsLimit)
+	}
+
+	if profile.CPUQuota > 0 && prev != nil {
+		periods := cur.CPUStats.ThrottlingData.Periods - prev.CPUStats.ThrottlingData.Periods
+		throttled := cur.CPUStats.ThrottlingData.ThrottledPeriods - prev.CPUStats.ThrottlingData.ThrottledPeriods
+		if periods > 0 && throttled >= periods {
+			return fmt.Sprintf("CPU quota of %d exhausted in all of the last %d periods", profile.CPUQuota, periods)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,127 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockercontroller
+
+import (
+	"fmt"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	"github.com/spf13/viper"
+)
+
+// ResourceProfile describes the resource limits and sandboxing options of the
+// containers of the chaincodes it applies to. Profiles are read from
+// vm.docker.resourceProfiles and are layered on top of vm.docker.hostConfig;
+// zero values leave the corresponding hostConfig setting untouched
+type ResourceProfile struct {
+	// Name identifies the profile in log messages
+	Name string
+	// Chaincodes lists the chaincodes the profile applies to, either as
+	// "name" (every version) or "name:version"
+	Chaincodes []string
+
+	Memory         int64
+	CPUShares      int64
+	CPUQuota       int64
+	CPUPeriod      int64
+	PidsLimit      int64
+	ReadonlyRootfs bool
+	CapDrop        []string
+	// SeccompProfile is the path of the seccomp profile the container is
+	// started with
+	SeccompProfile string
+}
+
+func getResourceProfiles() []ResourceProfile {
+	var profiles []ResourceProfile
+	if err := viper.UnmarshalKey("vm.docker.resourceProfiles", &profiles); err != nil {
+		dockerLogger.Warningf("load vm.docker.resourceProfiles failed, error: %s", err)
+		return nil
+	}
+	return profiles
+}
+
+// getResourceProfile returns the profile configured for the chaincode, or nil
+// if there is none. A profile naming the exact version takes precedence over
+// one naming the chaincode only
+func getResourceProfile(ccid ccintf.CCID) *ResourceProfile {
+	if ccid.ChaincodeSpec == nil || ccid.ChaincodeSpec.ChaincodeId == nil {
+		return nil
+	}
+	name := ccid.ChaincodeSpec.ChaincodeId.Name
+	versioned := fmt.Sprintf("%s:%s", name, ccid.Version)
+
+	var match *ResourceProfile
+	profiles := getResourceProfiles()
+	for i := range profiles {
+		for _, cc := range profiles[i].Chaincodes {
+			if cc == versioned {
+				return &profiles[i]
+			}
+			if cc == name && match == nil {
+				match = &profiles[i]
+			}
+		}
+	}
+	return match
+}
+
+// applyResourceProfile returns a copy of base with the limits of the profile
+// applied to it. base itself is never modified as it is shared by all
+// containers
+func applyResourceProfile(base *docker.HostConfig, profile *ResourceProfile) *docker.HostConfig {
+	if profile == nil {
+		return base
+	}
+	hc := *base
+	if profile.Memory > 0 {
+		hc.Memory = profile.Memory
+	}
+	if profile.CPUShares > 0 {
+		hc.CPUShares = profile.CPUShares
+	}
+	if profile.CPUQuota > 0 {
+		hc.CPUQuota = profile.CPUQuota
+	}
+	if profile.CPUPeriod > 0 {
+		hc.CPUPeriod = profile.CPUPeriod
+	}
+	if profile.PidsLimit > 0 {
+		hc.PidsLimit = profile.PidsLimit
+	}
+	if profile.ReadonlyRootfs {
+		hc.ReadonlyRootfs = true
+	}
+	if len(profile.CapDrop) > 0 {
+		hc.CapDrop = append(append([]string{}, base.CapDrop...), profile.CapDrop...)
+	}
+	if profile.SeccompProfile != "" {
+		hc.SecurityOpt = append(append([]string{}, base.SecurityOpt...), "seccomp="+profile.SeccompProfile)
+	}
+	return &hc
+}
+
+// getChaincodeHostConfig returns the host config a chaincode container is
+// created with, along with the resource profile that was applied (if any)
+func getChaincodeHostConfig(ccid ccintf.CCID) (*docker.HostConfig, *ResourceProfile) {
+	profile := getResourceProfile(ccid)
+	if profile != nil {
+		dockerLogger.Debugf("applying resource profile %s to chaincode %s:%s", profile.Name, ccid.ChaincodeSpec.ChaincodeId.Name, ccid.Version)
+	}
+	return applyResourceProfile(getDockerHostConfig(), profile), profile
+}
@@ -0,0 +1,208 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dockercontroller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fsouza/go-dockerclient"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/hyperledger/fabric/core/container/ccintf"
+	coreutil "github.com/hyperledger/fabric/core/testutil"
+	pb "github.com/hyperledger/fabric/protos/peer"
+)
+
+func newTestCCID(name, version string) ccintf.CCID {
+	return ccintf.CCID{ChaincodeSpec: &pb.ChaincodeSpec{ChaincodeId: &pb.ChaincodeID{Name: name}}, Version: version}
+}
+
+func setTestResourceProfiles() {
+	viper.Set("vm.docker.resourceProfiles", []map[string]interface{}{
+		{
+			"Name":       "small",
+			"Chaincodes": []string{"mycc", "othercc:1.0"},
+			"Memory":     268435456,
+			"PidsLimit":  64,
+			"CapDrop":    []string{"ALL"},
+		},
+		{
+			"Name":           "locked",
+			"Chaincodes":     []string{"mycc:2.0"},
+			"CpuQuota":       50000,
+			"CpuPeriod":      100000,
+			"ReadonlyRootfs": true,
+			"SeccompProfile": "/etc/hyperledger/fabric/seccomp.json",
+		},
+	})
+}
+
+func TestGetResourceProfile(t *testing.T) {
+	coreutil.SetupTestConfig()
+	setTestResourceProfiles()
+	defer viper.Set("vm.docker.resourceProfiles", nil)
+
+	profile := getResourceProfile(newTestCCID("mycc", "1.0"))
+	assert.NotNil(t, profile)
+	assert.Equal(t, "small", profile.Name)
+	assert.Equal(t, int64(268435456), profile.Memory)
+	assert.Equal(t, int64(64), profile.PidsLimit)
+
+	// the exact version wins over the chaincode name
+	profile = getResourceProfile(newTestCCID("mycc", "2.0"))
+	assert.NotNil(t, profile)
+	assert.Equal(t, "locked", profile.Name)
+	assert.Equal(t, int64(50000), profile.CPUQuota)
+	assert.True(t, profile.ReadonlyRootfs)
+
+	profile = getResourceProfile(newTestCCID("othercc", "1.0"))
+	assert.NotNil(t, profile)
+	assert.Equal(t, "small", profile.Name)
+
+	assert.Nil(t, getResourceProfile(newTestCCID("othercc", "1.1")))
+	assert.Nil(t, getResourceProfile(newTestCCID("unknown", "1.0")))
+}
+
+func TestApplyResourceProfile(t *testing.T) {
+	base := &docker.HostConfig{
+		NetworkMode: "host",
+		Memory:      2147483648,
+		CPUShares:   512,
+		CapDrop:     []string{"NET_RAW"},
+		SecurityOpt: []string{"no-new-privileges"},
+	}
+	assert.True(t, base == applyResourceProfile(base, nil))
+
+	hc := applyResourceProfile(base, &ResourceProfile{
+		Memory:         268435456,
+		CPUQuota:       50000,
+		PidsLimit:      64,
+		ReadonlyRootfs: true,
+		CapDrop:        []string{"ALL"},
+		SeccompProfile: "/etc/seccomp.json",
+	})
+	assert.Equal(t, "host", hc.NetworkMode)
+	assert.Equal(t, int64(268435456), hc.Memory)
+	assert.Equal(t, int64(512), hc.CPUShares)
+	assert.Equal(t, int64(50000), hc.CPUQuota)
+	assert.Equal(t, int64(64), hc.PidsLimit)
+	assert.True(t, hc.ReadonlyRootfs)
+	assert.Equal(t, []string{"NET_RAW", "ALL"}, hc.CapDrop)
+	assert.Equal(t, []string{"no-new-privileges", "seccomp=/etc/seccomp.json"}, hc.SecurityOpt)
+
+	// the shared base config is left untouched
+	assert.Equal(t, int64(2147483648), base.Memory)
+	assert.Equal(t, []string{"NET_RAW"}, base.CapDrop)
+	assert.Equal(t, []string{"no-new-privileges"}, base.SecurityOpt)
+}
+
+func TestCheckResourceUsage(t *testing.T) {
+	profile := &ResourceProfile{Memory: 1000, PidsLimit: 10, CPUQuota: 50000}
+
+	stats := &docker.Stats{}
+	stats.MemoryStats.Usage = 1200
+	stats.MemoryStats.Stats.Cache = 400
+	stats.PidsStats.Current = 3
+	assert.Empty(t, checkResourceUsage(profile, nil, stats))
+
+	stats.MemoryStats.Stats.Cache = 100
+	assert.Contains(t, checkResourceUsage(profile, nil, stats), "memory usage of 1100 bytes")
+
+	stats.MemoryStats.Usage = 500
+	stats.PidsStats.Current = 10
+	assert.Contains(t, checkResourceUsage(profile, nil, stats), "10 processes")
+
+	stats.PidsStats.Current = 3
+	prev := &docker.Stats{}
+	prev.CPUStats.ThrottlingData.Periods = 100
+	prev.CPUStats.ThrottlingData.ThrottledPeriods = 10
+	stats.CPUStats.ThrottlingData.Periods = 200
+	stats.CPUStats.ThrottlingData.ThrottledPeriods = 60
+	assert.Empty(t, checkResourceUsage(profile, prev, stats))
+
+	stats.CPUStats.ThrottlingData.ThrottledPeriods = 110
+	assert.Contains(t, checkResourceUsage(profile, prev, stats), "CPU quota of 50000")
+	// a single sample says nothing about throttling
+	assert.Empty(t, checkResourceUsage(profile, nil, stats))
+}
+
+type mockViolationHandler struct {
+	violations chan string
+}
+
+func (h *mockViolationHandler) HandleChaincodeStream(context.Context, ccintf.ChaincodeStream) error {
+	return nil
+}
+
+func (h *mockViolationHandler) HandleResourceViolation(ccid ccintf.CCID, reason string) {
+	h.violations <- ccid.GetName() + ": " + reason
+}
+
+func TestResourceMonitor(t *testing.T) {
+	coreutil.SetupTestConfig()
+	setTestResourceProfiles()
+	viper.Set("vm.docker.resourceMonitor.enabled", true)
+	viper.Set("vm.docker.resourceMonitor.interval", "10ms")
+	defer func() {
+		viper.Set("vm.docker.resourceProfiles", nil)
+		viper.Set("vm.docker.resourceMonitor.enabled", false)
+	}()
+
+	// every container is sampled through the client it was started with
+	stats := &docker.Stats{}
+	stats.MemoryStats.Usage = 268435456
+	getClientErr, createErr, noSuchImgErr, startErr, statsErr = false, false, false, false, false
+
+	handler := &mockViolationHandler{violations: make(chan string, 1)}
+	ctx := context.WithValue(context.Background(), ccintf.GetCCHandlerKey(), handler)
+	dvm := DockerVM{getClientFnc: func() (dockerClient, error) { return &mockClient{stats: stats}, nil }}
+
+	// chaincodes without a profile are not monitored
+	err := dvm.Start(ctx, newTestCCID("unknown", "1.0"), nil, nil, nil, nil)
+	assert.NoError(t, err)
+
+	err = dvm.Start(ctx, newTestCCID("mycc", "1.0"), nil, nil, nil, nil)
+	assert.NoError(t, err)
+
+	select {
+	case violation := <-handler.violations:
+		assert.Contains(t, violation, "mycc-1.0: memory usage of 268435456 bytes")
+	case <-time.After(5 * time.Second):
+		t.Fatal("resource violation was not reported")
+	}
+
+	containerMonitor.Lock()
+	assert.Len(t, containerMonitor.watched, 0)
+	containerMonitor.Unlock()
+
+	// stopping the container stops monitoring it
+	dvm.getClientFnc = getMockClient
+	err = dvm.Start(ctx, newTestCCID("mycc", "1.0"), nil, nil, nil, nil)
+	assert.NoError(t, err)
+	containerMonitor.Lock()
+	assert.Len(t, containerMonitor.watched, 1)
+	containerMonitor.Unlock()
+
+	err = dvm.Stop(ctx, newTestCCID("mycc", "1.0"), 0, false, false)
+	assert.NoError(t, err)
+	containerMonitor.Lock()
+	assert.Len(t, containerMonitor.watched, 0)
+	containerMonitor.Unlock()
+}
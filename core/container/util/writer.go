@@ -137,35 +137,47 @@ func WriteStreamToPackage(is io.Reader, localpath string, packagepath string, tw
 	if err != nil {
 		return fmt.Errorf("%s: %s", localpath, err)
 	}
-	header, err := tar.FileInfoHeader(info, localpath)
-	if err != nil {
-		return fmt.Errorf("Error getting FileInfoHeader: %s", err)
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s: not a regular file", localpath)
 	}
 
-	//Let's take the variance out of the tar, make headers identical by using zero time
-	oldname := header.Name
-	var zeroTime time.Time
-	header.AccessTime = zeroTime
-	header.ModTime = zeroTime
-	header.ChangeTime = zeroTime
-	header.Name = packagepath
-	header.Mode = 0100644
-
+	header := newDeterministicHeader(packagepath, info.Size())
 	if err = tw.WriteHeader(header); err != nil {
-		return fmt.Errorf("Error write header for (path: %s, oldname:%s,newname:%s,sz:%d) : %s", localpath, oldname, packagepath, header.Size, err)
+		return fmt.Errorf("Error write header for (path: %s, newname:%s,sz:%d) : %s", localpath, packagepath, header.Size, err)
 	}
 	if _, err := io.Copy(tw, is); err != nil {
-		return fmt.Errorf("Error copy (path: %s, oldname:%s,newname:%s,sz:%d) : %s", localpath, oldname, packagepath, header.Size, err)
+		return fmt.Errorf("Error copy (path: %s, newname:%s,sz:%d) : %s", localpath, packagepath, header.Size, err)
 	}
 
 	return nil
 }
 
+//WriteBytesToPackage writes the given payload to the tarball under name
 func WriteBytesToPackage(name string, payload []byte, tw *tar.Writer) error {
-	//Make headers identical by using zero time
-	var zeroTime time.Time
-	tw.WriteHeader(&tar.Header{Name: name, Size: int64(len(payload)), ModTime: zeroTime, AccessTime: zeroTime, ChangeTime: zeroTime})
-	tw.Write(payload)
+	header := newDeterministicHeader(name, int64(len(payload)))
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("Error write header for %s: %s", name, err)
+	}
+	if _, err := tw.Write(payload); err != nil {
+		return fmt.Errorf("Error writing %s: %s", name, err)
+	}
 
 	return nil
 }
+
+// newDeterministicHeader returns a tar header for a regular file which
+// carries nothing but the name and size. Modes, owners and timestamps are
+// normalized so that packaging the same files on different machines
+// produces byte-identical archives (and therefore identical hashes).
+func newDeterministicHeader(name string, size int64) *tar.Header {
+	var zeroTime time.Time
+	return &tar.Header{
+		Name:       filepath.ToSlash(name),
+		Size:       size,
+		Mode:       0100644,
+		Typeflag:   tar.TypeReg,
+		ModTime:    zeroTime,
+		AccessTime: zeroTime,
+		ChangeTime: zeroTime,
+	}
+}
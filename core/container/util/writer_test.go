@@ -25,6 +25,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -206,3 +207,34 @@ func Test_WriteBytesToPackage(t *testing.T) {
 	err := WriteBytesToPackage("foo", []byte("blah"), tw)
 	assert.NoError(t, err, "Error writing bytes to package")
 }
+
+func Test_WriteFileToPackageIsDeterministic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writertest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// same content, but different mode and modification time
+	file1 := filepath.Join(dir, "file1")
+	file2 := filepath.Join(dir, "file2")
+	assert.NoError(t, ioutil.WriteFile(file1, []byte("hello"), 0600))
+	assert.NoError(t, ioutil.WriteFile(file2, []byte("hello"), 0755))
+	old := time.Now().Add(-time.Hour)
+	assert.NoError(t, os.Chtimes(file2, old, old))
+
+	pack := func(path string) []byte {
+		buf := bytes.NewBuffer(nil)
+		tw := tar.NewWriter(buf)
+		assert.NoError(t, WriteFileToPackage(path, "src/file", tw))
+		assert.NoError(t, tw.Close())
+		return buf.Bytes()
+	}
+	assert.Equal(t, pack(file1), pack(file2), "Package bytes should not depend on file metadata")
+
+	tr := tar.NewReader(bytes.NewReader(pack(file2)))
+	header, err := tr.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0100644), header.Mode)
+	assert.Equal(t, byte(tar.TypeReg), header.Typeflag)
+	assert.Equal(t, 0, header.Uid)
+	assert.Equal(t, "", header.Uname)
+}
@@ -114,7 +114,7 @@ func (bc *broadcastClient) try(action func() (interface{}, error)) (interface{},
 }
 
 func (bc *broadcastClient) doAction(action func() (interface{}, error)) (interface{}, error) {
-	if bc.conn == nil {
+	if bc.conn == nil || bc.BlocksDeliverer == nil {
 		err := bc.connect()
 		if err != nil {
 			return nil, err
@@ -122,9 +122,10 @@ func (bc *broadcastClient) doAction(action func() (interface{}, error)) (interfa
 	}
 	resp, err := action()
 	if err != nil {
-		bc.Disconnect()
+		bc.closeStream()
 		return nil, err
 	}
+	bc.markHealthy()
 	return resp, nil
 }
 
@@ -136,21 +137,36 @@ func (bc *broadcastClient) sleep(duration time.Duration) {
 }
 
 func (bc *broadcastClient) connect() error {
+	if conn := bc.reusableConnection(); conn != nil {
+		logger.Debug("Re-establishing gRPC stream over the existing connection to", conn.endpoint)
+		if err := bc.openStream(conn.ClientConn, conn.endpoint); err == nil {
+			return nil
+		}
+		logger.Warning("Failed re-establishing gRPC stream with", conn.endpoint, ", reconnecting")
+		bc.Disconnect()
+	}
 	conn, endpoint, err := bc.prod.NewConnection()
 	logger.Debug("Connected to", endpoint)
 	if err != nil {
 		logger.Error("Failed obtaining connection:", err)
 		return err
 	}
+	return bc.openStream(conn, endpoint)
+}
+
+// openStream establishes a gRPC stream over the given connection
+// and runs the post-connection procedures on it
+func (bc *broadcastClient) openStream(conn *grpc.ClientConn, endpoint string) error {
 	ctx, cf := context.WithCancel(context.Background())
 	logger.Debug("Establishing gRPC stream with", endpoint, "...")
 	abc, err := bc.createClient(conn).Deliver(ctx)
 	if err != nil {
 		logger.Error("Connection to ", endpoint, "established but was unable to create gRPC stream:", err)
+		cf()
 		conn.Close()
 		return err
 	}
-	err = bc.afterConnect(conn, abc, cf)
+	err = bc.afterConnect(conn, endpoint, abc, cf)
 	if err == nil {
 		return nil
 	}
@@ -161,11 +177,11 @@ func (bc *broadcastClient) connect() error {
 	return err
 }
 
-func (bc *broadcastClient) afterConnect(conn *grpc.ClientConn, abc orderer.AtomicBroadcast_DeliverClient, cf context.CancelFunc) error {
+func (bc *broadcastClient) afterConnect(conn *grpc.ClientConn, endpoint string, abc orderer.AtomicBroadcast_DeliverClient, cf context.CancelFunc) error {
 	logger.Debug("Entering")
 	defer logger.Debug("Exiting")
 	bc.Lock()
-	bc.conn = &connection{ClientConn: conn, cancel: cf}
+	bc.conn = &connection{ClientConn: conn, cancel: cf, endpoint: endpoint}
 	bc.BlocksDeliverer = abc
 	if bc.shouldStop() {
 		bc.Unlock()
@@ -216,6 +232,45 @@ func (bc *broadcastClient) Close() {
 	bc.conn.Close()
 }
 
+// closeStream closes the current gRPC stream after a failed operation.
+// If the stream has served the client successfully before, the underlying
+// connection is kept so that the next attempt re-establishes the stream over it,
+// otherwise the connection is closed as well.
+func (bc *broadcastClient) closeStream() {
+	bc.Lock()
+	if bc.conn == nil || !bc.conn.healthy {
+		bc.Unlock()
+		bc.Disconnect()
+		return
+	}
+	defer bc.Unlock()
+	bc.conn.cancel()
+	bc.conn.healthy = false
+	bc.BlocksDeliverer = nil
+}
+
+// reusableConnection returns the connection of the last stream
+// if its stream has been closed but the connection has been kept,
+// or nil otherwise
+func (bc *broadcastClient) reusableConnection() *connection {
+	bc.Lock()
+	defer bc.Unlock()
+	if bc.conn == nil || bc.BlocksDeliverer != nil {
+		return nil
+	}
+	return bc.conn
+}
+
+// markHealthy marks the current connection as one
+// that successfully served the client
+func (bc *broadcastClient) markHealthy() {
+	bc.Lock()
+	defer bc.Unlock()
+	if bc.conn != nil {
+		bc.conn.healthy = true
+	}
+}
+
 // Disconnect makes the client close the existing connection
 func (bc *broadcastClient) Disconnect() {
 	logger.Debug("Entering")
@@ -233,7 +288,9 @@ func (bc *broadcastClient) Disconnect() {
 type connection struct {
 	sync.Once
 	*grpc.ClientConn
-	cancel context.CancelFunc
+	cancel   context.CancelFunc
+	endpoint string
+	healthy  bool
 }
 
 func (c *connection) Close() error {
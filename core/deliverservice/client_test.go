@@ -334,7 +334,54 @@ func testOrderingServiceCrashAndRecover(t *testing.T, bdc blocksDelivererConsume
 	abStream.shouldFail = true
 	err = bdc(bc)
 	assert.NoError(t, err)
-	assert.Equal(t, 2, cp.connAttempts)
+	// The stream served us before it failed, so it should have
+	// been re-established over the same connection
+	assert.Equal(t, 1, cp.connAttempts)
+	assert.Equal(t, 2, setupInvoked)
+}
+
+func TestOrderingServiceStreamReuseFailure(t *testing.T) {
+	testOrderingServiceStreamReuseFailure(t, blockDelivererConsumerWithRecv)
+	testOrderingServiceStreamReuseFailure(t, blockDelivererConsumerWithSend)
+	assert.Equal(t, 0, connNumber)
+}
+
+func testOrderingServiceStreamReuseFailure(t *testing.T, bdc blocksDelivererConsumer) {
+	// Scenario: The ordering service is OK at first usage of Recv/Send,
+	// but subsequent calls fail, and the stream cannot be re-established
+	// over the existing connection.
+	// A reconnect is needed and only then Recv/Send should succeed
+	cp := &connProducer{}
+	abStream := &abc{}
+	abcClient := &abclient{stream: abStream}
+	clFactory := func(*grpc.ClientConn) orderer.AtomicBroadcastClient {
+		return abcClient
+	}
+	setupInvoked := 0
+	setup := func(blocksprovider.BlocksDeliverer) error {
+		setupInvoked++
+		return nil
+	}
+	backoffStrategy := func(attemptNum int, elapsedTime time.Duration) (time.Duration, bool) {
+		// The first attempt fails on the broken stream, the second fails
+		// creating a stream both over the existing and over a new connection.
+		// Let the third attempt create a stream over a new connection
+		if attemptNum == 2 {
+			abcClient.shouldFail = false
+		}
+		return time.Duration(0), true
+	}
+	bc := NewBroadcastClient(cp, clFactory, setup, backoffStrategy)
+	defer bc.Close()
+	err := bdc(bc)
+	assert.NoError(t, err)
+	// Now fail the subsequent Recv/Send, and the creation of the stream
+	abStream.shouldFail = true
+	abcClient.stream = nil
+	abcClient.shouldFail = true
+	err = bdc(bc)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cp.connAttempts)
 	assert.Equal(t, 2, setupInvoked)
 }
 
@@ -367,8 +414,11 @@ func testOrderingServicePermanentCrash(t *testing.T, bdc blocksDelivererConsumer
 	defer bc.Close()
 	err := bdc(bc)
 	assert.NoError(t, err)
-	// Now fail the subsequent Recv/Send
+	// Now fail the subsequent Recv/Send, and the creation of streams
+	// and connections, since the ordering service is down
 	abStream.shouldFail = true
+	abcClient.stream = nil
+	abcClient.shouldFail = true
 	cp.shouldFail = true
 	err = bdc(bc)
 	assert.Error(t, err)
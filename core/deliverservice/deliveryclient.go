@@ -17,8 +17,10 @@ import (
 	"github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/core/deliverservice/blocksprovider"
 	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/op/go-logging"
+	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 )
 
@@ -31,6 +33,7 @@ func init() {
 var (
 	reConnectTotalTimeThreshold = time.Second * 60 * 5
 	connTimeout                 = time.Second * 3
+	fetchTimeout                = time.Second * 10
 	reConnectBackoffThreshold   = float64(time.Hour)
 )
 
@@ -53,6 +56,12 @@ type DeliverService interface {
 	// to channel peers.
 	StopDeliverForChannel(chainID string) error
 
+	// FetchBlocks retrieves the blocks of the channel with sequence numbers in the range
+	// [start...end] directly from the ordering service, regardless of whether blocks are
+	// delivered for the channel. It returns the blocks in order, and may return only
+	// a prefix of the range if the ordering service doesn't have all of them.
+	FetchBlocks(chainID string, start uint64, end uint64) ([]*common.Block, error)
+
 	// Stop terminates delivery service and closes the connection
 	Stop()
 }
@@ -84,6 +93,10 @@ type Config struct {
 	Gossip blocksprovider.GossipServiceAdapter
 	// Endpoints specifies the endpoints of the ordering service
 	Endpoints []string
+	// ReconnectTotalTimeThreshold, if set, overrides the total time the delivery
+	// of a channel may spend in reconnection attempts until it gives up,
+	// stops and calls its finalizer
+	ReconnectTotalTimeThreshold time.Duration
 }
 
 // NewDeliverService construction function to create and initialize
@@ -170,6 +183,67 @@ func (d *deliverServiceImpl) StopDeliverForChannel(chainID string) error {
 	return nil
 }
 
+// FetchBlocks retrieves the blocks of the channel with sequence numbers in the range
+// [start...end] from a random ordering service endpoint, over a dedicated stream
+func (d *deliverServiceImpl) FetchBlocks(chainID string, start uint64, end uint64) ([]*common.Block, error) {
+	d.lock.RLock()
+	stopping := d.stopping
+	d.lock.RUnlock()
+	if stopping {
+		return nil, fmt.Errorf("Delivery service is stopping, cannot fetch blocks of channel %s", chainID)
+	}
+	if start > end {
+		return nil, fmt.Errorf("Invalid range of blocks [%d...%d]", start, end)
+	}
+
+	connProd := comm.NewConnectionProducer(d.conf.ConnFactory(chainID), d.conf.Endpoints)
+	conn, endpoint, err := connProd.NewConnection()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+	stream, err := d.conf.ABCFactory(conn).Deliver(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating deliver stream to %s: %v", endpoint, err)
+	}
+	env, err := newSeekEnvelope(chainID, seekRange(start, end))
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.Send(env); err != nil {
+		return nil, fmt.Errorf("Failed requesting blocks [%d...%d] from %s: %v", start, end, endpoint, err)
+	}
+
+	var blocks []*common.Block
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return nil, fmt.Errorf("Failed receiving blocks [%d...%d] from %s: %v", start, end, endpoint, err)
+		}
+		switch t := msg.Type.(type) {
+		case *orderer.DeliverResponse_Block:
+			expected := start + uint64(len(blocks))
+			if t.Block.Header == nil || t.Block.Header.Number != expected {
+				return nil, fmt.Errorf("%s sent a block out of order, expected block %d", endpoint, expected)
+			}
+			blocks = append(blocks, t.Block)
+			if expected == end {
+				return blocks, nil
+			}
+		case *orderer.DeliverResponse_Status:
+			if t.Status == common.Status_SUCCESS || len(blocks) > 0 {
+				return blocks, nil
+			}
+			return nil, fmt.Errorf("%s returned status %v for blocks [%d...%d]", endpoint, t.Status, start, end)
+		default:
+			return nil, fmt.Errorf("%s sent an unknown response %v", endpoint, t)
+		}
+	}
+}
+
 // Stop all service and release resources
 func (d *deliverServiceImpl) Stop() {
 	d.lock.Lock()
@@ -189,8 +263,12 @@ func (d *deliverServiceImpl) newClient(chainID string, ledgerInfoProvider blocks
 	broadcastSetup := func(bd blocksprovider.BlocksDeliverer) error {
 		return requester.RequestBlocks(ledgerInfoProvider)
 	}
+	totalTimeThreshold := reConnectTotalTimeThreshold
+	if d.conf.ReconnectTotalTimeThreshold > 0 {
+		totalTimeThreshold = d.conf.ReconnectTotalTimeThreshold
+	}
 	backoffPolicy := func(attemptNum int, elapsedTime time.Duration) (time.Duration, bool) {
-		if elapsedTime.Nanoseconds() > reConnectTotalTimeThreshold.Nanoseconds() {
+		if elapsedTime.Nanoseconds() > totalTimeThreshold.Nanoseconds() {
 			return 0, false
 		}
 		sleepIncrement := float64(time.Millisecond * 500)
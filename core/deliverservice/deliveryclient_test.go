@@ -31,6 +31,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
+	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/orderer"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -389,6 +390,34 @@ func TestDeliverServiceShutdown(t *testing.T) {
 	time.Sleep(time.Second)
 }
 
+func TestDeliverServiceReconnectTotalTimeThreshold(t *testing.T) {
+	defer ensureNoGoroutineLeak(t)()
+	// Scenario: Start the delivery of a channel while no ordering service node is up,
+	// with a short total reconnection time threshold configured.
+	// The delivery is expected to give up and call its finalizer once the threshold passes.
+	service, err := NewDeliverService(&Config{
+		Endpoints:                   []string{"localhost:5615"},
+		Gossip:                      &mocks.MockGossipServiceAdapter{GossipBlockDisseminations: make(chan uint64)},
+		CryptoSvc:                   &mockMCS{},
+		ABCFactory:                  DefaultABCFactory,
+		ConnFactory:                 DefaultConnectionFactory,
+		ReconnectTotalTimeThreshold: time.Second,
+	})
+	assert.NoError(t, err)
+
+	finalized := make(chan struct{})
+	err = service.StartDeliverForChannel("TEST_CHAINID", &mocks.MockLedgerInfo{Height: uint64(100)}, func() {
+		close(finalized)
+	})
+	assert.NoError(t, err, "can't start delivery")
+	select {
+	case <-finalized:
+	case <-time.After(time.Second * 15):
+		assert.Fail(t, "Delivery didn't give up although the reconnection time threshold passed")
+	}
+	service.Stop()
+}
+
 func TestDeliverServiceBadConfig(t *testing.T) {
 	// Empty endpoints
 	service, err := NewDeliverService(&Config{
@@ -445,6 +474,64 @@ func TestDeliverServiceBadConfig(t *testing.T) {
 	assert.Nil(t, service)
 }
 
+func TestDeliverServiceFetchBlocks(t *testing.T) {
+	defer ensureNoGoroutineLeak(t)()
+	// The ordering service has blocks [0...7]
+	blocksDeliverer := &mocks.MockBlocksDeliverer{}
+	blocksDeliverer.MockRecv = func(mock *mocks.MockBlocksDeliverer) (*orderer.DeliverResponse, error) {
+		if mock.Pos > 7 {
+			return &orderer.DeliverResponse{
+				Type: &orderer.DeliverResponse_Status{Status: cb.Status_NOT_FOUND},
+			}, nil
+		}
+		return mocks.MockRecv(mock)
+	}
+	abcf := func(*grpc.ClientConn) orderer.AtomicBroadcastClient {
+		return &mocks.MockAtomicBroadcastClient{blocksDeliverer}
+	}
+	connFactory := func(_ string) func(string) (*grpc.ClientConn, error) {
+		return func(endpoint string) (*grpc.ClientConn, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			return newConnection(), nil
+		}
+	}
+	service, err := NewDeliverService(&Config{
+		Endpoints:   []string{"a"},
+		Gossip:      &mocks.MockGossipServiceAdapter{},
+		CryptoSvc:   &mockMCS{},
+		ABCFactory:  abcf,
+		ConnFactory: connFactory,
+	})
+	assert.NoError(t, err)
+
+	seqNums := func(blocks []*cb.Block) []uint64 {
+		var res []uint64
+		for _, block := range blocks {
+			res = append(res, block.Header.Number)
+		}
+		return res
+	}
+	blocks, err := service.FetchBlocks("TEST_CHAINID", 3, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{3, 4, 5}, seqNums(blocks))
+	// Only the blocks the ordering service has are returned
+	blocks, err = service.FetchBlocks("TEST_CHAINID", 6, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{6, 7}, seqNums(blocks))
+	_, err = service.FetchBlocks("TEST_CHAINID", 9, 10)
+	assert.Error(t, err)
+	_, err = service.FetchBlocks("TEST_CHAINID", 5, 3)
+	assert.Error(t, err)
+
+	service.Stop()
+	_, err = service.FetchBlocks("TEST_CHAINID", 3, 5)
+	assert.Error(t, err)
+	lock.Lock()
+	assert.Equal(t, 0, connNumber)
+	lock.Unlock()
+}
+
 func TestRetryPolicyOverflow(t *testing.T) {
 	connFactory := func(channelID string) func(endpoint string) (*grpc.ClientConn, error) {
 		return func(_ string) (*grpc.ClientConn, error) {
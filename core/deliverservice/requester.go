@@ -60,10 +60,7 @@ func (b *blocksRequester) seekOldest() error {
 		Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
 	}
 
-	//TODO- epoch and msgVersion may need to be obtained for nowfollowing usage in orderer/configupdate/configupdate.go
-	msgVersion := int32(0)
-	epoch := uint64(0)
-	env, err := utils.CreateSignedEnvelope(common.HeaderType_CONFIG_UPDATE, b.chainID, localmsp.NewSigner(), seekInfo, msgVersion, epoch)
+	env, err := newSeekEnvelope(b.chainID, seekInfo)
 	if err != nil {
 		return err
 	}
@@ -77,12 +74,26 @@ func (b *blocksRequester) seekLatestFromCommitter(height uint64) error {
 		Behavior: orderer.SeekInfo_BLOCK_UNTIL_READY,
 	}
 
-	//TODO- epoch and msgVersion may need to be obtained for nowfollowing usage in orderer/configupdate/configupdate.go
-	msgVersion := int32(0)
-	epoch := uint64(0)
-	env, err := utils.CreateSignedEnvelope(common.HeaderType_CONFIG_UPDATE, b.chainID, localmsp.NewSigner(), seekInfo, msgVersion, epoch)
+	env, err := newSeekEnvelope(b.chainID, seekInfo)
 	if err != nil {
 		return err
 	}
 	return b.client.Send(env)
 }
+
+// seekRange returns a SeekInfo that seeks the blocks with sequence numbers in the
+// range [start...end], and fails right away if the ordering service doesn't have them
+func seekRange(start uint64, end uint64) *orderer.SeekInfo {
+	return &orderer.SeekInfo{
+		Start:    &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: start}}},
+		Stop:     &orderer.SeekPosition{Type: &orderer.SeekPosition_Specified{Specified: &orderer.SeekSpecified{Number: end}}},
+		Behavior: orderer.SeekInfo_FAIL_IF_NOT_READY,
+	}
+}
+
+func newSeekEnvelope(chainID string, seekInfo *orderer.SeekInfo) (*common.Envelope, error) {
+	//TODO- epoch and msgVersion may need to be obtained for nowfollowing usage in orderer/configupdate/configupdate.go
+	msgVersion := int32(0)
+	epoch := uint64(0)
+	return utils.CreateSignedEnvelope(common.HeaderType_CONFIG_UPDATE, chainID, localmsp.NewSigner(), seekInfo, msgVersion, epoch)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cceventmgmt
+
+import "fmt"
+
+// ChaincodeDefinition captures the info about a chaincode that matters to the ledger components
+type ChaincodeDefinition struct {
+	Name    string
+	Version string
+}
+
+func (cdef *ChaincodeDefinition) String() string {
+	return fmt.Sprintf("Name=%s, Version=%s", cdef.Name, cdef.Version)
+}
+
+// ChaincodeLifecycleEventListener is implemented by the ledgers that need to act when a chaincode,
+// installed on the peer, gets deployed on them. For instance, a CouchDB state database creates
+// the indexes shipped in the chaincode package
+type ChaincodeLifecycleEventListener interface {
+	// HandleChaincodeDeploy is invoked with the statedb artifacts of the chaincode package, keyed
+	// by their path relative to META-INF/statedb
+	HandleChaincodeDeploy(chaincodeDefinition *ChaincodeDefinition, dbArtifacts map[string][]byte) error
+	// GetLSCCState returns the value committed by lscc for the chaincode, nil if the chaincode
+	// is not deployed on the ledger
+	GetLSCCState(chaincodeName string) ([]byte, error)
+}
+
+// ChaincodeInfoProvider gives access to the chaincode info kept outside the ledger,
+// it is implemented on the peer side
+type ChaincodeInfoProvider interface {
+	// GetChaincodeDefinition decodes the value committed by lscc for a deployed chaincode
+	GetChaincodeDefinition(lsccValue []byte) (*ChaincodeDefinition, error)
+	// RetrieveChaincodeArtifacts returns the statedb artifacts of the chaincode package,
+	// installed is false if the package is not installed on the peer
+	RetrieveChaincodeArtifacts(chaincodeDefinition *ChaincodeDefinition) (installed bool, dbArtifacts map[string][]byte, err error)
+}
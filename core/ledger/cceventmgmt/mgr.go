@@ -0,0 +1,147 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cceventmgmt
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/hyperledger/fabric/common/flogging"
+)
+
+var logger = flogging.MustGetLogger("cceventmgmt")
+
+var mgr = newMgr()
+
+// GetMgr returns the reference to the singleton event manager
+func GetMgr() *Mgr {
+	return mgr
+}
+
+// Mgr dispatches the chaincode deploy and install events to the ledgers. A chaincode's statedb
+// artifacts are processed on a ledger as soon as the chaincode is both deployed on the ledger and
+// installed on the peer, whichever comes last
+type Mgr struct {
+	infoProvider ChaincodeInfoProvider
+	listeners    map[string]ChaincodeLifecycleEventListener
+	rwlock       sync.RWMutex
+}
+
+func newMgr() *Mgr {
+	return &Mgr{listeners: make(map[string]ChaincodeLifecycleEventListener)}
+}
+
+// Initialize sets the provider of the chaincode info. Until it is called, the events are ignored
+func (m *Mgr) Initialize(infoProvider ChaincodeInfoProvider) {
+	m.rwlock.Lock()
+	defer m.rwlock.Unlock()
+	m.infoProvider = infoProvider
+}
+
+// Register registers the listener of a ledger
+func (m *Mgr) Register(ledgerID string, l ChaincodeLifecycleEventListener) {
+	m.rwlock.Lock()
+	defer m.rwlock.Unlock()
+	m.listeners[ledgerID] = l
+}
+
+// Unregister removes the listener of a ledger
+func (m *Mgr) Unregister(ledgerID string) {
+	m.rwlock.Lock()
+	defer m.rwlock.Unlock()
+	delete(m.listeners, ledgerID)
+}
+
+// HandleChaincodeDeploy is invoked by a ledger once it has committed the lscc updates of a block.
+// lsccUpdates contains the value written by lscc for each chaincode deployed or upgraded in the block
+func (m *Mgr) HandleChaincodeDeploy(ledgerID string, lsccUpdates map[string][]byte) error {
+	m.rwlock.RLock()
+	defer m.rwlock.RUnlock()
+
+	l, ok := m.listeners[ledgerID]
+	if !ok || m.infoProvider == nil {
+		logger.Debugf("Channel [%s]: no chaincode lifecycle listener, ignoring the deploy event", ledgerID)
+		return nil
+	}
+
+	var ccNames []string
+	for ccName := range lsccUpdates {
+		ccNames = append(ccNames, ccName)
+	}
+	sort.Strings(ccNames)
+
+	for _, ccName := range ccNames {
+		chaincodeDefinition, err := m.infoProvider.GetChaincodeDefinition(lsccUpdates[ccName])
+		if err != nil {
+			return err
+		}
+		installed, dbArtifacts, err := m.infoProvider.RetrieveChaincodeArtifacts(chaincodeDefinition)
+		if err != nil {
+			return err
+		}
+		if !installed {
+			logger.Infof("Channel [%s]: chaincode [%s] is not installed on the peer, its statedb artifacts will be processed upon install",
+				ledgerID, chaincodeDefinition)
+			continue
+		}
+		if err := l.HandleChaincodeDeploy(chaincodeDefinition, dbArtifacts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleChaincodeInstall is invoked when a chaincode gets installed on the peer. The statedb artifacts
+// are processed on every ledger where this version of the chaincode is deployed
+func (m *Mgr) HandleChaincodeInstall(chaincodeDefinition *ChaincodeDefinition, dbArtifacts map[string][]byte) error {
+	m.rwlock.RLock()
+	defer m.rwlock.RUnlock()
+
+	if m.infoProvider == nil {
+		return nil
+	}
+
+	var ledgerIDs []string
+	for ledgerID := range m.listeners {
+		ledgerIDs = append(ledgerIDs, ledgerID)
+	}
+	sort.Strings(ledgerIDs)
+
+	for _, ledgerID := range ledgerIDs {
+		l := m.listeners[ledgerID]
+		lsccValue, err := l.GetLSCCState(chaincodeDefinition.Name)
+		if err != nil {
+			return err
+		}
+		if lsccValue == nil {
+			continue
+		}
+		deployedDefinition, err := m.infoProvider.GetChaincodeDefinition(lsccValue)
+		if err != nil {
+			return err
+		}
+		if deployedDefinition.Version != chaincodeDefinition.Version {
+			logger.Debugf("Channel [%s]: chaincode [%s] is deployed with a different version [%s]",
+				ledgerID, chaincodeDefinition, deployedDefinition.Version)
+			continue
+		}
+		if err := l.HandleChaincodeDeploy(chaincodeDefinition, dbArtifacts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
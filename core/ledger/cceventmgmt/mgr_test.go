@@ -0,0 +1,103 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cceventmgmt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockInfoProvider struct {
+	installed map[string]map[string][]byte
+}
+
+// lscc values are encoded as name:version in these tests
+func (p *mockInfoProvider) GetChaincodeDefinition(lsccValue []byte) (*ChaincodeDefinition, error) {
+	parts := strings.Split(string(lsccValue), ":")
+	if len(parts) != 2 {
+		return nil, errors.New("invalid lscc value")
+	}
+	return &ChaincodeDefinition{Name: parts[0], Version: parts[1]}, nil
+}
+
+func (p *mockInfoProvider) RetrieveChaincodeArtifacts(chaincodeDefinition *ChaincodeDefinition) (bool, map[string][]byte, error) {
+	dbArtifacts, ok := p.installed[chaincodeDefinition.Name+":"+chaincodeDefinition.Version]
+	return ok, dbArtifacts, nil
+}
+
+type mockListener struct {
+	lsccState map[string][]byte
+	deployed  []string
+}
+
+func (l *mockListener) HandleChaincodeDeploy(chaincodeDefinition *ChaincodeDefinition, dbArtifacts map[string][]byte) error {
+	l.deployed = append(l.deployed, chaincodeDefinition.Name+":"+chaincodeDefinition.Version+"="+string(dbArtifacts["couchdb/indexes/index.json"]))
+	return nil
+}
+
+func (l *mockListener) GetLSCCState(chaincodeName string) ([]byte, error) {
+	return l.lsccState[chaincodeName], nil
+}
+
+func TestHandleChaincodeDeploy(t *testing.T) {
+	m := newMgr()
+	l := &mockListener{}
+	m.Register("ch1", l)
+
+	lsccUpdates := map[string][]byte{"cc1": []byte("cc1:1.0"), "cc2": []byte("cc2:1.0")}
+
+	// events are ignored until the manager is initialized
+	assert.NoError(t, m.HandleChaincodeDeploy("ch1", lsccUpdates))
+	assert.Empty(t, l.deployed)
+
+	m.Initialize(&mockInfoProvider{installed: map[string]map[string][]byte{
+		"cc1:1.0": {"couchdb/indexes/index.json": []byte("idx1")},
+	}})
+
+	// only the installed chaincodes are processed
+	assert.NoError(t, m.HandleChaincodeDeploy("ch1", lsccUpdates))
+	assert.Equal(t, []string{"cc1:1.0=idx1"}, l.deployed)
+
+	// ledgers without listener are ignored
+	assert.NoError(t, m.HandleChaincodeDeploy("ch2", lsccUpdates))
+
+	assert.Error(t, m.HandleChaincodeDeploy("ch1", map[string][]byte{"cc3": []byte("bad")}))
+
+	m.Unregister("ch1")
+	assert.NoError(t, m.HandleChaincodeDeploy("ch1", lsccUpdates))
+	assert.Len(t, l.deployed, 1)
+}
+
+func TestHandleChaincodeInstall(t *testing.T) {
+	m := newMgr()
+	m.Initialize(&mockInfoProvider{})
+	l1 := &mockListener{lsccState: map[string][]byte{"cc1": []byte("cc1:1.0")}}
+	l2 := &mockListener{lsccState: map[string][]byte{"cc1": []byte("cc1:2.0")}}
+	l3 := &mockListener{}
+	m.Register("ch1", l1)
+	m.Register("ch2", l2)
+	m.Register("ch3", l3)
+
+	dbArtifacts := map[string][]byte{"couchdb/indexes/index.json": []byte("idx1")}
+	assert.NoError(t, m.HandleChaincodeInstall(&ChaincodeDefinition{Name: "cc1", Version: "1.0"}, dbArtifacts))
+	assert.Equal(t, []string{"cc1:1.0=idx1"}, l1.deployed)
+	assert.Empty(t, l2.deployed)
+	assert.Empty(t, l3.deployed)
+}
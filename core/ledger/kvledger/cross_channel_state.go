@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/statebasedval"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+)
+
+const (
+	// crossChannelPollInterval is the interval at which the height of a channel is checked
+	// while waiting for it to reach the height observed by a cross channel read
+	crossChannelPollInterval = 100 * time.Millisecond
+	// crossChannelWaitLogInterval is the interval at which the wait is logged
+	crossChannelWaitLogInterval = 10 * time.Second
+)
+
+// crossChannelWaitTimeout is the time a channel is waited for to receive the blocks up to the height
+// observed by a cross channel read, after which the read is deemed invalid. Once the blocks are stored,
+// their commit to the state and history databases is waited for without limit, as it does not depend on
+// the other channels
+var crossChannelWaitTimeout = time.Minute
+
+// crossChannelState implements interface statebasedval.CrossChannelStateProvider on top of
+// the ledgers opened by a provider
+type crossChannelState struct {
+	idStore *idStore
+	lock    sync.RWMutex
+	ledgers map[string]*kvLedger
+}
+
+func newCrossChannelState(idStore *idStore) *crossChannelState {
+	return &crossChannelState{idStore: idStore, ledgers: make(map[string]*kvLedger)}
+}
+
+func (s *crossChannelState) register(l *kvLedger) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ledgers[l.ledgerID] = l
+}
+
+func (s *crossChannelState) unregister(l *kvLedger) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.ledgers[l.ledgerID] == l {
+		delete(s.ledgers, l.ledgerID)
+	}
+}
+
+// GetVersionAtHeight implements method in interface statebasedval.CrossChannelStateProvider
+func (s *crossChannelState) GetVersionAtHeight(channelID string, ns string, key string, blockHeight uint64) (*version.Height, error) {
+	if !ledgerconfig.IsHistoryDBEnabled() {
+		return nil, statebasedval.ErrHistoryDBRequired
+	}
+	if blockHeight == 0 {
+		return nil, nil
+	}
+	l, err := s.waitForHeight(channelID, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+	return l.getVersionAtHeight(ns, key, blockHeight)
+}
+
+// waitForHeight returns the ledger of the channel once its blocks up to the given height are committed,
+// or statebasedval.ErrHeightNotReached if the channel does not receive these blocks in time
+func (s *crossChannelState) waitForHeight(channelID string, blockHeight uint64) (*kvLedger, error) {
+	deadline := time.Now().Add(crossChannelWaitTimeout)
+	lastLog := time.Now()
+	for {
+		exists, err := s.idStore.ledgerIDExists(channelID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, statebasedval.ErrChannelNotAvailable
+		}
+		s.lock.RLock()
+		l := s.ledgers[channelID]
+		s.lock.RUnlock()
+		stored := false
+		if l != nil {
+			committed, err := l.hasCommittedHeight(blockHeight)
+			if err != nil {
+				return nil, err
+			}
+			if committed {
+				return l, nil
+			}
+			if stored, err = l.hasStoredHeight(blockHeight); err != nil {
+				return nil, err
+			}
+		}
+		if !stored && time.Now().After(deadline) {
+			logger.Warningf("Channel [%s] did not reach block height [%d] within %s for validating a cross channel read",
+				channelID, blockHeight, crossChannelWaitTimeout)
+			return nil, statebasedval.ErrHeightNotReached
+		}
+		if time.Since(lastLog) >= crossChannelWaitLogInterval {
+			logger.Warningf("Waiting for channel [%s] to reach block height [%d] for validating a cross channel read", channelID, blockHeight)
+			lastLog = time.Now()
+		}
+		time.Sleep(crossChannelPollInterval)
+	}
+}
+
+// hasStoredHeight tells whether the blocks up to the given height are stored in the block store
+func (l *kvLedger) hasStoredHeight(blockHeight uint64) (bool, error) {
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil {
+		return false, err
+	}
+	return info.Height >= blockHeight, nil
+}
+
+// hasCommittedHeight tells whether the blocks up to the given height are committed to the state and history databases
+func (l *kvLedger) hasCommittedHeight(blockHeight uint64) (bool, error) {
+	savepoint, err := l.txtmgmt.GetLastSavepoint()
+	if err != nil || savepoint == nil || savepoint.BlockNum+1 < blockHeight {
+		return false, err
+	}
+	savepoint, err = l.historyDB.GetLastSavepoint()
+	if err != nil || savepoint == nil || savepoint.BlockNum+1 < blockHeight {
+		return false, err
+	}
+	return true, nil
+}
+
+// getVersionAtHeight returns the version of a key as of the given block height, or nil if the key
+// did not exist at that height, as recorded by the history database
+func (l *kvLedger) getVersionAtHeight(ns string, key string, blockHeight uint64) (*version.Height, error) {
+	qe, err := l.NewHistoryQueryExecutor()
+	if err != nil {
+		return nil, err
+	}
+	itr, err := qe.GetHistoryForKeyWithOptions(ns, key,
+		&queryresult.HistoryQueryOptions{EndBlock: blockHeight - 1, HasEndBlock: true, NewestFirst: true})
+	if err != nil {
+		return nil, err
+	}
+	defer itr.Close()
+	result, err := itr.Next()
+	if err != nil || result == nil {
+		return nil, err
+	}
+	keyModification := result.(*queryresult.KeyModification)
+	if keyModification.IsDelete {
+		return nil, nil
+	}
+	return version.NewHeight(keyModification.BlockNum, keyModification.TxNum), nil
+}
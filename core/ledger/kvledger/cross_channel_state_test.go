@@ -0,0 +1,99 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/statebasedval"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrossChannelStateVersionAtHeight(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	bg, gb := testutil.NewBlockGenerator(t, "ledger1", false)
+	l, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer l.Close()
+	commit := func(simulate func(simulator ledger.TxSimulator)) {
+		simulator, _ := l.NewTxSimulator()
+		simulate(simulator)
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		assert.NoError(t, l.Commit(bg.NextBlock([][]byte{simRes})))
+	}
+	//block1
+	commit(func(simulator ledger.TxSimulator) {
+		simulator.SetState("ns1", "key1", []byte("value1"))
+		simulator.SetState("ns1", "key2", []byte("value1"))
+	})
+	//block2
+	commit(func(simulator ledger.TxSimulator) {
+		simulator.SetState("ns1", "key1", []byte("value2"))
+		simulator.DeleteState("ns1", "key2")
+	})
+
+	ccState := provider.(*Provider).crossChannelState
+	versionAtHeight := func(key string, blockHeight uint64) *version.Height {
+		v, err := ccState.GetVersionAtHeight("ledger1", "ns1", key, blockHeight)
+		assert.NoError(t, err)
+		return v
+	}
+	assert.Nil(t, versionAtHeight("key1", 0))
+	assert.Nil(t, versionAtHeight("key1", 1))
+	assert.Equal(t, version.NewHeight(1, 0), versionAtHeight("key1", 2))
+	assert.Equal(t, version.NewHeight(2, 0), versionAtHeight("key1", 3))
+	assert.Equal(t, version.NewHeight(1, 0), versionAtHeight("key2", 2))
+	assert.Nil(t, versionAtHeight("key2", 3))
+	assert.Nil(t, versionAtHeight("key3", 3))
+
+	// a height that is not yet committed is waited for
+	go func() {
+		time.Sleep(3 * crossChannelPollInterval)
+		//block3
+		commit(func(simulator ledger.TxSimulator) {
+			simulator.SetState("ns1", "key1", []byte("value3"))
+		})
+	}()
+	assert.Equal(t, version.NewHeight(3, 0), versionAtHeight("key1", 4))
+
+	_, err = ccState.GetVersionAtHeight("ledger2", "ns1", "key1", 1)
+	assert.Equal(t, statebasedval.ErrChannelNotAvailable, err)
+
+	// a height that is not reached in time is not waited for any longer
+	defer func(timeout time.Duration) { crossChannelWaitTimeout = timeout }(crossChannelWaitTimeout)
+	crossChannelWaitTimeout = 3 * crossChannelPollInterval
+	_, err = ccState.GetVersionAtHeight("ledger1", "ns1", "key1", 1<<62)
+	assert.Equal(t, statebasedval.ErrHeightNotReached, err)
+
+	// the version of a key at a past height can only be looked up in the history database
+	viper.Set("ledger.history.enableHistoryDatabase", false)
+	defer viper.Set("ledger.history.enableHistoryDatabase", true)
+	_, err = ccState.GetVersionAtHeight("ledger1", "ns1", "key3", 4)
+	assert.Equal(t, statebasedval.ErrHistoryDBRequired, err)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+// GetDBSavepoints returns the savepoints of the state database and of the history database of
+// the given ledger, that is the last block committed to them. A savepoint is nil when no block was
+// committed to the database, and the history savepoint is nil when the history database is disabled.
+// This is meant to be invoked while the peer is stopped
+func GetDBSavepoints(ledgerID string) (stateSavepoint *version.Height, historySavepoint *version.Height, err error) {
+	vdbProvider, historydbProvider, err := newDBProviders()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer vdbProvider.Close()
+	defer historydbProvider.Close()
+
+	vdb, err := vdbProvider.GetDBHandle(ledgerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stateSavepoint, err = vdb.GetLatestSavePoint(); err != nil {
+		return nil, nil, err
+	}
+	if !ledgerconfig.IsHistoryDBEnabled() {
+		return stateSavepoint, nil, nil
+	}
+	historyDB, err := historydbProvider.GetDBHandle(ledgerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if historySavepoint, err = historyDB.GetLastSavepoint(); err != nil {
+		return nil, nil, err
+	}
+	return stateSavepoint, historySavepoint, nil
+}
@@ -18,6 +18,7 @@ package historydb
 
 import (
 	"bytes"
+	"fmt"
 
 	"github.com/hyperledger/fabric/common/ledger/util"
 )
@@ -53,6 +54,88 @@ func ConstructPartialCompositeHistoryKey(ns string, key string, endkey bool) []b
 	return compositeKey
 }
 
+//ConstructHistoryRangeKey builds namespace~key, without the trailing separator, for use as
+// the bound of a range scan over the History Keys of several keys. An empty key denotes the
+// start of the namespace, or its end if endkey is true
+func ConstructHistoryRangeKey(ns string, key string, endkey bool) []byte {
+	var compositeKey []byte
+	compositeKey = append(compositeKey, []byte(ns)...)
+	compositeKey = append(compositeKey, compositeKeySep...)
+	if endkey && key == "" {
+		return append(compositeKey, []byte{0xff}...)
+	}
+	return append(compositeKey, []byte(key)...)
+}
+
+// HistoryKey is a reading of a History Key namespace~key~blocknum~trannum
+type HistoryKey struct {
+	Key      string
+	BlockNum uint64
+	TranNum  uint64
+}
+
+//ParseCompositeHistoryKey returns the readings of a History Key namespace~key~blocknum~trannum of
+// the given namespace. As a key may itself contain the separator, and as blocknum and trannum have
+// variable lengths, a History Key may read in more than one way, e.g. as the key "k" at some height
+// and as the key "k~x" at another. A reading is kept for each separator in the key part which is
+// followed by exactly two encoded numbers. The caller tells the readings apart by checking which
+// of the keys the transaction at blocknum and trannum did write
+func ParseCompositeHistoryKey(ns string, historyKey []byte) ([]*HistoryKey, error) {
+	keyStart := len(ns) + len(compositeKeySep)
+	var historyKeys []*HistoryKey
+	for sepIndex := keyStart; sepIndex < len(historyKey); sepIndex++ {
+		if historyKey[sepIndex] != compositeKeySep[0] {
+			continue
+		}
+		blockNum, tranNum, ok := decodeBlockNumTranNum(historyKey[sepIndex+1:])
+		if ok {
+			historyKeys = append(historyKeys, &HistoryKey{string(historyKey[keyStart:sepIndex]), blockNum, tranNum})
+		}
+	}
+	if len(historyKeys) == 0 {
+		return nil, fmt.Errorf("Invalid history key [%#v] for namespace [%s]", historyKey, ns)
+	}
+	return historyKeys, nil
+}
+
+//ParseCompositeHistoryKeyOfKey extracts the block number and the transaction number from a History Key
+// namespace~key~blocknum~trannum of the given key. It returns false if the History Key cannot be one of
+// the key, which is the case of most of the History Keys of the keys having key~ as a prefix
+func ParseCompositeHistoryKeyOfKey(ns string, key string, historyKey []byte) (uint64, uint64, bool) {
+	partialKey := ConstructPartialCompositeHistoryKey(ns, key, false)
+	if !bytes.HasPrefix(historyKey, partialKey) {
+		return 0, 0, false
+	}
+	return decodeBlockNumTranNum(historyKey[len(partialKey):])
+}
+
+// decodeBlockNumTranNum decodes the two numbers that the bytes must exactly consist of
+func decodeBlockNumTranNum(b []byte) (uint64, uint64, bool) {
+	blockNum, n, ok := decodeOrderPreservingVarUint64(b)
+	if !ok {
+		return 0, 0, false
+	}
+	tranNum, m, ok := decodeOrderPreservingVarUint64(b[n:])
+	if !ok || n+m != len(b) {
+		return 0, 0, false
+	}
+	return blockNum, tranNum, true
+}
+
+// decodeOrderPreservingVarUint64 decodes a number at the start of the bytes if they begin with
+// an encoding produced by util.EncodeOrderPreservingVarUint64
+func decodeOrderPreservingVarUint64(b []byte) (uint64, int, bool) {
+	if len(b) == 0 {
+		return 0, 0, false
+	}
+	size := int(b[0])
+	if size > 8 || len(b) < size+1 || (size > 0 && b[1] == 0x00) {
+		return 0, 0, false
+	}
+	number, n := util.DecodeOrderPreservingVarUint64(b)
+	return number, n, true
+}
+
 //SplitCompositeHistoryKey splits the key bytes using a separator
 func SplitCompositeHistoryKey(bytesToSplit []byte, separator []byte) ([]byte, []byte) {
 	split := bytes.SplitN(bytesToSplit, separator, 2)
@@ -46,3 +46,50 @@ func TestSplitCompositeKey(t *testing.T) {
 	// second position should hold the extra bytes that were split off
 	testutil.AssertEquals(t, extraBytes, []byte("extra bytes to split"))
 }
+
+func TestConstructHistoryRangeKey(t *testing.T) {
+	testutil.AssertEquals(t, ConstructHistoryRangeKey("ns1", "key1", false), []byte("ns1"+strKeySep+"key1"))
+	testutil.AssertEquals(t, ConstructHistoryRangeKey("ns1", "key1", true), []byte("ns1"+strKeySep+"key1"))
+	testutil.AssertEquals(t, ConstructHistoryRangeKey("ns1", "", false), []byte("ns1"+strKeySep))
+	testutil.AssertEquals(t, ConstructHistoryRangeKey("ns1", "", true), []byte("ns1"+strKeySep+string([]byte{0xff})))
+}
+
+func TestParseCompositeHistoryKey(t *testing.T) {
+	testCases := []struct {
+		key      string
+		blockNum uint64
+		tranNum  uint64
+	}{
+		{"key1", 0, 0},
+		{"key1", 1, 0},
+		{"key1", 256, 3},
+		{"key1", 1<<64 - 1, 1<<64 - 1},
+		// keys containing the separator, such as the composite keys
+		{strKeySep + "color" + strKeySep + "blue" + strKeySep, 7, 0},
+		{"key1" + strKeySep + string([]byte{0x01, 0x05}), 0, 0},
+	}
+	for _, tc := range testCases {
+		historyKey := ConstructCompositeHistoryKey("ns1", tc.key, tc.blockNum, tc.tranNum)
+		historyKeys, err := ParseCompositeHistoryKey("ns1", historyKey)
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertContains(t, historyKeys, &HistoryKey{tc.key, tc.blockNum, tc.tranNum})
+
+		blockNum, tranNum, ok := ParseCompositeHistoryKeyOfKey("ns1", tc.key, historyKey)
+		testutil.AssertEquals(t, ok, true)
+		testutil.AssertEquals(t, blockNum, tc.blockNum)
+		testutil.AssertEquals(t, tranNum, tc.tranNum)
+	}
+
+	// the key "key1~0x03~0x05" at height 0:0 reads as well as the key "key1" at height 0x050000:0
+	ambiguousKey := "key1" + strKeySep + string([]byte{0x03, 0x05})
+	historyKeys, err := ParseCompositeHistoryKey("ns1", ConstructCompositeHistoryKey("ns1", ambiguousKey, 0, 0))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, historyKeys, []*HistoryKey{{"key1", 0x050000, 0}, {ambiguousKey, 0, 0}})
+
+	// the History Key of "key1~x" is not one of "key1"
+	_, _, ok := ParseCompositeHistoryKeyOfKey("ns1", "key1", ConstructCompositeHistoryKey("ns1", "key1"+strKeySep+"x", 1, 0))
+	testutil.AssertEquals(t, ok, false)
+
+	_, err = ParseCompositeHistoryKey("ns1", []byte("ns1"+strKeySep+"key1"))
+	testutil.AssertError(t, err, "a history key without block and transaction numbers should be rejected")
+}
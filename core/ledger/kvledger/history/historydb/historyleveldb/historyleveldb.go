@@ -35,6 +35,9 @@ var logger = flogging.MustGetLogger("historyleveldb")
 var savePointKey = []byte{0x00}
 var emptyValue = []byte{}
 
+// dropBatchSize is the number of keys deleted in one leveldb batch when dropping a db
+const dropBatchSize = 1000
+
 // HistoryDBProvider implements interface HistoryDBProvider
 type HistoryDBProvider struct {
 	dbProvider *leveldbhelper.Provider
@@ -53,6 +56,12 @@ func (provider *HistoryDBProvider) GetDBHandle(dbName string) (historydb.History
 	return newHistoryDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
 
+// Drop deletes all the keys of a named database
+func (provider *HistoryDBProvider) Drop(dbName string) error {
+	logger.Infof("Dropping history database [%s]", dbName)
+	return provider.dbProvider.GetDBHandle(dbName).DeleteAll(dropBatchSize)
+}
+
 // Close closes the underlying db
 func (provider *HistoryDBProvider) Close() {
 	provider.dbProvider.Close()
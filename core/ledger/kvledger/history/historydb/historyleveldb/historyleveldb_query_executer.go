@@ -18,10 +18,11 @@ package historyleveldb
 
 import (
 	"errors"
+	"math"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
-	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
@@ -39,70 +40,180 @@ type LevelHistoryDBQueryExecutor struct {
 
 // GetHistoryForKey implements method in interface `ledger.HistoryQueryExecutor`
 func (q *LevelHistoryDBQueryExecutor) GetHistoryForKey(namespace string, key string) (commonledger.ResultsIterator, error) {
+	return q.GetHistoryForKeyWithOptions(namespace, key, nil)
+}
+
+// GetHistoryForKeyWithOptions implements method in interface `ledger.HistoryQueryExecutor`
+func (q *LevelHistoryDBQueryExecutor) GetHistoryForKeyWithOptions(namespace string, key string,
+	options *queryresult.HistoryQueryOptions) (commonledger.ResultsIterator, error) {
 
 	if ledgerconfig.IsHistoryDBEnabled() == false {
 		return nil, errors.New("History tracking not enabled - historyDatabase is false")
 	}
+	if options == nil {
+		options = &queryresult.HistoryQueryOptions{}
+	}
 
+	// the block bounds are turned into the bounds of the range scan, so that
+	// the history records outside of the requested blocks are never read
 	var compositeStartKey []byte
 	var compositeEndKey []byte
-	compositeStartKey = historydb.ConstructPartialCompositeHistoryKey(namespace, key, false)
-	compositeEndKey = historydb.ConstructPartialCompositeHistoryKey(namespace, key, true)
+	compositeStartKey = historydb.ConstructCompositeHistoryKey(namespace, key, options.StartBlock, 0)
+	if !options.HasEndBlock || options.EndBlock == math.MaxUint64 {
+		compositeEndKey = historydb.ConstructPartialCompositeHistoryKey(namespace, key, true)
+	} else {
+		compositeEndKey = historydb.ConstructCompositeHistoryKey(namespace, key, options.EndBlock+1, 0)
+	}
 
 	// range scan to find any history records starting with namespace~key
 	dbItr := q.historyDB.db.GetIterator(compositeStartKey, compositeEndKey)
-	return newHistoryScanner(compositeStartKey, namespace, key, dbItr, q.blockStore), nil
+	return newHistoryScanner(namespace, key, dbItr, q.blockStore, options), nil
+}
+
+// GetHistoryForKeyRange implements method in interface `ledger.HistoryQueryExecutor`
+func (q *LevelHistoryDBQueryExecutor) GetHistoryForKeyRange(namespace string, startKey string, endKey string,
+	options *queryresult.HistoryQueryOptions) (commonledger.ResultsIterator, error) {
+
+	if ledgerconfig.IsHistoryDBEnabled() == false {
+		return nil, errors.New("History tracking not enabled - historyDatabase is false")
+	}
+	if options == nil {
+		options = &queryresult.HistoryQueryOptions{}
+	}
+
+	// The history records of the keys in the range are interleaved in the history db,
+	// so the block bounds can only be applied on each record
+	compositeStartKey := historydb.ConstructHistoryRangeKey(namespace, startKey, false)
+	compositeEndKey := historydb.ConstructHistoryRangeKey(namespace, endKey, true)
+	dbItr := q.historyDB.db.GetIterator(compositeStartKey, compositeEndKey)
+	return newHistoryScanner(namespace, "", dbItr, q.blockStore, options), nil
 }
 
 //historyScanner implements ResultsIterator for iterating through history results
 type historyScanner struct {
-	compositePartialKey []byte //compositePartialKey includes namespace~key
-	namespace           string
-	key                 string
-	dbItr               iterator.Iterator
-	blockStore          blkstorage.BlockStore
+	namespace string
+	// key is empty for the history of a key range
+	key        string
+	dbItr      iterator.Iterator
+	blockStore blkstorage.BlockStore
+	options    *queryresult.HistoryQueryOptions
+	started    bool
+	// pending holds the readings of the current history record that are not yet processed
+	pending []*historydb.HistoryKey
 }
 
-func newHistoryScanner(compositePartialKey []byte, namespace string, key string,
-	dbItr iterator.Iterator, blockStore blkstorage.BlockStore) *historyScanner {
-	return &historyScanner{compositePartialKey, namespace, key, dbItr, blockStore}
+func newHistoryScanner(namespace string, key string, dbItr iterator.Iterator,
+	blockStore blkstorage.BlockStore, options *queryresult.HistoryQueryOptions) *historyScanner {
+	return &historyScanner{namespace: namespace, key: key, dbItr: dbItr, blockStore: blockStore, options: options}
 }
 
 func (scanner *historyScanner) Next() (commonledger.QueryResult, error) {
-	if !scanner.dbItr.Next() {
-		return nil, nil
-	}
-	historyKey := scanner.dbItr.Key() // history key is in the form namespace~key~blocknum~trannum
+	for {
+		if len(scanner.pending) == 0 {
+			if !scanner.moveNext() {
+				return nil, nil
+			}
+			historyKeys, err := scanner.readHistoryKey(scanner.dbItr.Key())
+			if err != nil {
+				return nil, err
+			}
+			scanner.pending = historyKeys
+			continue
+		}
+		historyKey := scanner.pending[0]
+		scanner.pending = scanner.pending[1:]
+		if !scanner.isWithinBlocks(historyKey.BlockNum) {
+			continue
+		}
+		logger.Debugf("Found history record for namespace:%s key:%s at blockNumTranNum %v:%v\n",
+			scanner.namespace, historyKey.Key, historyKey.BlockNum, historyKey.TranNum)
 
-	// SplitCompositeKey(namespace~key~blocknum~trannum, namespace~key~) will return the blocknum~trannum in second position
-	_, blockNumTranNumBytes := historydb.SplitCompositeHistoryKey(historyKey, scanner.compositePartialKey)
-	blockNum, bytesConsumed := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[0:])
-	tranNum, _ := util.DecodeOrderPreservingVarUint64(blockNumTranNumBytes[bytesConsumed:])
-	logger.Debugf("Found history record for namespace:%s key:%s at blockNumTranNum %v:%v\n",
-		scanner.namespace, scanner.key, blockNum, tranNum)
+		// Get the transaction from block storage that is associated with this history record
+		tranEnvelope, err := scanner.blockStore.RetrieveTxByBlockNumTranNum(historyKey.BlockNum, historyKey.TranNum)
+		if err == blkstorage.ErrNotFoundInIndex {
+			// there is no such transaction, the history record is one of another key
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	// Get the transaction from block storage that is associated with this history record
-	tranEnvelope, err := scanner.blockStore.RetrieveTxByBlockNumTranNum(blockNum, tranNum)
-	if err != nil {
-		return nil, err
+		// Get the txid, key write value, timestamp, and delete indicator associated with this transaction
+		keyModification, err := getKeyModificationFromTran(tranEnvelope, scanner.namespace, historyKey.Key)
+		if err != nil {
+			return nil, err
+		}
+		if keyModification == nil {
+			// the transaction did not write the key, the history record is one of another key
+			continue
+		}
+		if !scanner.isWithinTimes(keyModification.Timestamp) {
+			continue
+		}
+		keyModification.Key = historyKey.Key
+		keyModification.BlockNum = historyKey.BlockNum
+		keyModification.TxNum = historyKey.TranNum
+		logger.Debugf("Found historic key value for namespace:%s key:%s from transaction %s\n",
+			scanner.namespace, historyKey.Key, keyModification.TxId)
+		return keyModification, nil
 	}
+}
 
-	// Get the txid, key write value, timestamp, and delete indicator associated with this transaction
-	queryResult, err := getKeyModificationFromTran(tranEnvelope, scanner.namespace, scanner.key)
-	if err != nil {
-		return nil, err
+// readHistoryKey returns the possible readings of a history key namespace~key~blocknum~trannum.
+// The history of a key is read in a single way, and the history records of the other keys
+// which fall in the range scan of the key are left out
+func (scanner *historyScanner) readHistoryKey(historyKey []byte) ([]*historydb.HistoryKey, error) {
+	if scanner.key == "" {
+		return historydb.ParseCompositeHistoryKey(scanner.namespace, historyKey)
+	}
+	blockNum, tranNum, ok := historydb.ParseCompositeHistoryKeyOfKey(scanner.namespace, scanner.key, historyKey)
+	if !ok {
+		return nil, nil
 	}
-	logger.Debugf("Found historic key value for namespace:%s key:%s from transaction %s\n",
-		scanner.namespace, scanner.key, queryResult.(*queryresult.KeyModification).TxId)
-	return queryResult, nil
+	return []*historydb.HistoryKey{{Key: scanner.key, BlockNum: blockNum, TranNum: tranNum}}, nil
 }
 
 func (scanner *historyScanner) Close() {
 	scanner.dbItr.Release()
 }
 
-// getTxIDandKeyWriteValueFromTran inspects a transaction for writes to a given key
-func getKeyModificationFromTran(tranEnvelope *common.Envelope, namespace string, key string) (commonledger.QueryResult, error) {
+// moveNext moves the db iterator forward, or backward if the newest records are asked first
+func (scanner *historyScanner) moveNext() bool {
+	if !scanner.options.NewestFirst {
+		return scanner.dbItr.Next()
+	}
+	if !scanner.started {
+		scanner.started = true
+		return scanner.dbItr.Last()
+	}
+	return scanner.dbItr.Prev()
+}
+
+func (scanner *historyScanner) isWithinBlocks(blockNum uint64) bool {
+	if blockNum < scanner.options.StartBlock {
+		return false
+	}
+	return !scanner.options.HasEndBlock || blockNum <= scanner.options.EndBlock
+}
+
+func (scanner *historyScanner) isWithinTimes(ts *timestamp.Timestamp) bool {
+	if scanner.options.StartTime != nil && isBefore(ts, scanner.options.StartTime) {
+		return false
+	}
+	return scanner.options.EndTime == nil || !isBefore(scanner.options.EndTime, ts)
+}
+
+// isBefore tells whether the timestamp a is strictly before the timestamp b
+func isBefore(a, b *timestamp.Timestamp) bool {
+	if a.GetSeconds() != b.GetSeconds() {
+		return a.GetSeconds() < b.GetSeconds()
+	}
+	return a.GetNanos() < b.GetNanos()
+}
+
+// getKeyModificationFromTran inspects a transaction for writes to a given key.
+// A nil KeyModification is returned if the transaction does not write the key
+func getKeyModificationFromTran(tranEnvelope *common.Envelope, namespace string, key string) (*queryresult.KeyModification, error) {
 	logger.Debugf("Entering getKeyModificationFromTran()\n", namespace, key)
 
 	// extract action from the envelope
@@ -147,9 +258,9 @@ func getKeyModificationFromTran(tranEnvelope *common.Envelope, namespace string,
 						Timestamp: timestamp, IsDelete: kvWrite.IsDelete}, nil
 				}
 			} // end keys loop
-			return nil, errors.New("Key not found in namespace's writeset")
+			return nil, nil
 		} // end if
 	} //end namespaces loop
-	return nil, errors.New("Namespace not found in transaction's ReadWriteSets")
+	return nil, nil
 
 }
@@ -20,9 +20,14 @@ import (
 	"os"
 	"strconv"
 	"testing"
+	"unicode/utf8"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	configtxtest "github.com/hyperledger/fabric/common/configtx/test"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/util"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/ledger/queryresult"
@@ -231,6 +236,175 @@ func TestHistoryForInvalidTran(t *testing.T) {
 	testutil.AssertNil(t, kmod)
 }
 
+func TestHistoryWithOptions(t *testing.T) {
+
+	env := NewTestHistoryEnv(t)
+	defer env.cleanup()
+	store1, err := env.testBlockStorageEnv.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "Error upon provider.OpenBlockStore()")
+	defer store1.Shutdown()
+
+	bg, gb := testutil.NewBlockGenerator(t, "ledger1", false)
+	testutil.AssertNoError(t, store1.AddBlock(gb), "")
+	testutil.AssertNoError(t, env.testHistoryDB.Commit(gb), "")
+	// block i sets key1 to value<i>, block 3 also setting key1<separator>x, which shares the prefix of key1 in the history db
+	for i := 1; i <= 5; i++ {
+		commitHistoryTestBlock(t, env, store1, bg, func(simulator ledger.TxSimulator) {
+			simulator.SetState("ns1", "key1", []byte("value"+strconv.Itoa(i)))
+			if i == 3 {
+				simulator.SetState("ns1", "key1\x00x", []byte("other"))
+			}
+		})
+	}
+
+	qhistory, err := env.testHistoryDB.NewHistoryQueryExecutor(store1)
+	testutil.AssertNoError(t, err, "Error upon NewHistoryQueryExecutor")
+	history := historyReader(t)
+
+	// no options returns the whole history of the key alone
+	kms := history(qhistory.GetHistoryForKeyWithOptions("ns1", "key1", nil))
+	testutil.AssertEquals(t, getValues(kms), []string{"value1", "value2", "value3", "value4", "value5"})
+	for i, km := range kms {
+		testutil.AssertEquals(t, km.Key, "key1")
+		testutil.AssertEquals(t, km.BlockNum, uint64(i+1))
+		testutil.AssertEquals(t, km.TxNum, uint64(0))
+	}
+
+	// block range
+	kms = history(qhistory.GetHistoryForKeyWithOptions("ns1", "key1",
+		&queryresult.HistoryQueryOptions{StartBlock: 2, EndBlock: 4, HasEndBlock: true}))
+	testutil.AssertEquals(t, getValues(kms), []string{"value2", "value3", "value4"})
+	kms = history(qhistory.GetHistoryForKeyWithOptions("ns1", "key1",
+		&queryresult.HistoryQueryOptions{StartBlock: 4}))
+	testutil.AssertEquals(t, getValues(kms), []string{"value4", "value5"})
+
+	// newest first
+	kms = history(qhistory.GetHistoryForKeyWithOptions("ns1", "key1",
+		&queryresult.HistoryQueryOptions{EndBlock: 3, HasEndBlock: true, NewestFirst: true}))
+	testutil.AssertEquals(t, getValues(kms), []string{"value3", "value2", "value1"})
+
+	// time range, using the timestamps of the transactions
+	all := history(qhistory.GetHistoryForKey("ns1", "key1"))
+	kms = history(qhistory.GetHistoryForKeyWithOptions("ns1", "key1",
+		&queryresult.HistoryQueryOptions{StartTime: all[1].Timestamp, EndTime: all[3].Timestamp}))
+	for _, km := range kms {
+		testutil.AssertEquals(t, isBefore(km.Timestamp, all[1].Timestamp), false)
+		testutil.AssertEquals(t, isBefore(all[3].Timestamp, km.Timestamp), false)
+	}
+	testutil.AssertEquals(t, len(kms) >= 3, true)
+	kms = history(qhistory.GetHistoryForKeyWithOptions("ns1", "key1",
+		&queryresult.HistoryQueryOptions{EndTime: &timestamp.Timestamp{Seconds: all[0].Timestamp.Seconds - 1}}))
+	testutil.AssertEquals(t, len(kms), 0)
+}
+
+func TestHistoryForKeyRange(t *testing.T) {
+
+	env := NewTestHistoryEnv(t)
+	defer env.cleanup()
+	store1, err := env.testBlockStorageEnv.provider.OpenBlockStore("ledger1")
+	testutil.AssertNoError(t, err, "Error upon provider.OpenBlockStore()")
+	defer store1.Shutdown()
+
+	bg, gb := testutil.NewBlockGenerator(t, "ledger1", false)
+	testutil.AssertNoError(t, store1.AddBlock(gb), "")
+	testutil.AssertNoError(t, env.testHistoryDB.Commit(gb), "")
+	blueKey := "\x00color\x00blue\x00"
+	redKey := "\x00color\x00red\x00"
+	// the history record of ambiguousKey at block 1 also reads as one of key1 at block 0x05000101
+	ambiguousKey := "key1\x00\x04\x05"
+	//block1
+	commitHistoryTestBlock(t, env, store1, bg, func(simulator ledger.TxSimulator) {
+		simulator.SetState("ns1", "key1", []byte("key1_1"))
+		simulator.SetState("ns1", ambiguousKey, []byte("ambiguous_1"))
+		simulator.SetState("ns1", "key2", []byte("key2_1"))
+		simulator.SetState("ns1", blueKey, []byte("blue_1"))
+		simulator.SetState("ns2", "key1", []byte("ns2_1"))
+	})
+	//block2
+	commitHistoryTestBlock(t, env, store1, bg, func(simulator ledger.TxSimulator) {
+		simulator.DeleteState("ns1", "key2")
+		simulator.SetState("ns1", "key3", []byte("key3_2"))
+		simulator.SetState("ns1", redKey, []byte("red_2"))
+	})
+	//block3
+	commitHistoryTestBlock(t, env, store1, bg, func(simulator ledger.TxSimulator) {
+		simulator.SetState("ns1", "key1", []byte("key1_3"))
+	})
+
+	qhistory, err := env.testHistoryDB.NewHistoryQueryExecutor(store1)
+	testutil.AssertNoError(t, err, "Error upon NewHistoryQueryExecutor")
+	history := historyReader(t)
+
+	// the deleted key2 is part of the history of the range
+	kms := history(qhistory.GetHistoryForKeyRange("ns1", "key1", "key3", nil))
+	testutil.AssertEquals(t, getKeys(kms), []string{"key1", "key1", ambiguousKey, "key2", "key2"})
+	testutil.AssertEquals(t, getValues(kms), []string{"key1_1", "key1_3", "ambiguous_1", "key2_1", ""})
+	testutil.AssertEquals(t, kms[4].IsDelete, true)
+	kms = history(qhistory.GetHistoryForKey("ns1", "key1"))
+	testutil.AssertEquals(t, getValues(kms), []string{"key1_1", "key1_3"})
+
+	// unbounded range and block range
+	kms = history(qhistory.GetHistoryForKeyRange("ns1", "key", "", &queryresult.HistoryQueryOptions{StartBlock: 2}))
+	testutil.AssertEquals(t, getKeys(kms), []string{"key1", "key2", "key3"})
+	kms = history(qhistory.GetHistoryForKeyRange("ns1", "", "", &queryresult.HistoryQueryOptions{EndBlock: 1, HasEndBlock: true}))
+	testutil.AssertEquals(t, getKeys(kms), []string{blueKey, "key1", ambiguousKey, "key2"})
+	// a zero end block restricts the query to the genesis block
+	kms = history(qhistory.GetHistoryForKeyRange("ns1", "", "", &queryresult.HistoryQueryOptions{HasEndBlock: true}))
+	testutil.AssertEquals(t, len(kms), 0)
+
+	// newest first reverses the whole range
+	kms = history(qhistory.GetHistoryForKeyRange("ns1", "key", "", &queryresult.HistoryQueryOptions{NewestFirst: true}))
+	testutil.AssertEquals(t, getValues(kms), []string{"key3_2", "", "key2_1", "ambiguous_1", "key1_3", "key1_1"})
+
+	// composite key prefix
+	kms = history(qhistory.GetHistoryForKeyRange("ns1", "\x00color\x00", "\x00color\x00"+string(utf8.MaxRune), nil))
+	testutil.AssertEquals(t, getValues(kms), []string{"blue_1", "red_2"})
+}
+
+func commitHistoryTestBlock(t *testing.T, env *levelDBLockBasedHistoryEnv, store blkstorage.BlockStore,
+	bg *testutil.BlockGenerator, simulate func(simulator ledger.TxSimulator)) {
+	simulator, _ := env.txmgr.NewTxSimulator()
+	simulate(simulator)
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	block := bg.NextBlock([][]byte{simRes})
+	testutil.AssertNoError(t, store.AddBlock(block), "")
+	testutil.AssertNoError(t, env.testHistoryDB.Commit(block), "")
+}
+
+// historyReader returns a function collecting the results of a history query
+func historyReader(t *testing.T) func(itr commonledger.ResultsIterator, err error) []*queryresult.KeyModification {
+	return func(itr commonledger.ResultsIterator, err error) []*queryresult.KeyModification {
+		testutil.AssertNoError(t, err, "")
+		defer itr.Close()
+		var kms []*queryresult.KeyModification
+		for {
+			kmod, err := itr.Next()
+			testutil.AssertNoError(t, err, "")
+			if kmod == nil {
+				return kms
+			}
+			kms = append(kms, kmod.(*queryresult.KeyModification))
+		}
+	}
+}
+
+func getKeys(kms []*queryresult.KeyModification) []string {
+	keys := []string{}
+	for _, km := range kms {
+		keys = append(keys, km.Key)
+	}
+	return keys
+}
+
+func getValues(kms []*queryresult.KeyModification) []string {
+	values := []string{}
+	for _, km := range kms {
+		values = append(values, string(km.Value))
+	}
+	return values
+}
+
 //TestSavepoint tests that save points get written after each block and get returned via GetBlockNumfromSavepoint
 func TestHistoryDisabled(t *testing.T) {
 
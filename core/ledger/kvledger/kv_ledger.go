@@ -24,6 +24,7 @@ import (
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/txmgr"
@@ -38,31 +39,49 @@ var logger = flogging.MustGetLogger("kvledger")
 // KVLedger provides an implementation of `ledger.PeerLedger`.
 // This implementation provides a key-value based data model
 type kvLedger struct {
-	ledgerID   string
-	blockStore blkstorage.BlockStore
-	txtmgmt    txmgr.TxMgr
-	historyDB  historydb.HistoryDB
+	ledgerID    string
+	blockStore  blkstorage.BlockStore
+	versionedDB statedb.VersionedDB
+	txtmgmt     txmgr.TxMgr
+	historyDB   historydb.HistoryDB
+	// crossChannelState makes the state of the ledger available to the validation of the other ledgers
+	crossChannelState *crossChannelState
+	// stateListeners are notified of the state updates of the committed blocks
+	stateListeners []*stateListenerProxy
 }
 
 // NewKVLedger constructs new `KVLedger`
 func newKVLedger(ledgerID string, blockStore blkstorage.BlockStore,
-	versionedDB statedb.VersionedDB, historyDB historydb.HistoryDB) (*kvLedger, error) {
+	versionedDB statedb.VersionedDB, historyDB historydb.HistoryDB,
+	crossChannelState *crossChannelState,
+	stateListeners []ledger.StateListener, checkpoints *idStore) (*kvLedger, error) {
 
 	logger.Debugf("Creating KVLedger ledgerID=%s: ", ledgerID)
 
 	//Initialize transaction manager using state database
 	var txmgmt txmgr.TxMgr
-	txmgmt = lockbasedtxmgr.NewLockBasedTxMgr(versionedDB)
+	txmgmt = lockbasedtxmgr.NewLockBasedTxMgr(ledgerID, versionedDB, crossChannelState)
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying
 	// id store, blockstore, txmgr (state database), history database
-	l := &kvLedger{ledgerID, blockStore, txmgmt, historyDB}
+	l := &kvLedger{ledgerID: ledgerID, blockStore: blockStore, versionedDB: versionedDB, txtmgmt: txmgmt,
+		historyDB: historyDB, crossChannelState: crossChannelState}
+	for _, listener := range stateListeners {
+		l.stateListeners = append(l.stateListeners, newStateListenerProxy(ledgerID, listener, checkpoints, versionedDB))
+	}
+
+	// Process the statedb artifacts of the chaincodes deployed on this ledger,
+	// including the ones deployed by the blocks recommitted during recovery
+	cceventmgmt.GetMgr().Register(ledgerID, &ccEventListener{versionedDB})
 
 	//Recover both state DB and history DB if they are out of sync with block storage
 	if err := l.recoverDBs(); err != nil {
 		panic(fmt.Errorf(`Error during state DB recovery:%s`, err))
 	}
+	// The state listeners are recovered last, a failure only disables the listener
+	l.recoverStateListeners()
 
+	crossChannelState.register(l)
 	return l, nil
 }
 
@@ -84,6 +103,11 @@ func (l *kvLedger) recoverDBs() error {
 		if err != nil {
 			return err
 		}
+		if recoverFlag && firstBlockNum > lastAvailableBlockNum+1 {
+			// the block store has been rolled back without dropping the db
+			return fmt.Errorf("Data inconsistency: a db of ledger [%s] is ahead of the block store, which ends at block [%d]",
+				l.ledgerID, lastAvailableBlockNum)
+		}
 		if recoverFlag {
 			recoverers = append(recoverers, &recoverer{firstBlockNum, recoverable})
 		}
@@ -112,15 +136,22 @@ func (l *kvLedger) recoverDBs() error {
 		recoverers[0].recoverable, recoverers[1].recoverable)
 }
 
+// recommitProgressInterval is the number of recommitted blocks after which the progress is logged
+const recommitProgressInterval = 1000
+
 //recommitLostBlocks retrieves blocks in specified range and commit the write set to either
 //state DB or history DB or both
 func (l *kvLedger) recommitLostBlocks(firstBlockNum uint64, lastBlockNum uint64, recoverables ...recoverable) error {
 	var err error
 	var block *common.Block
+	logger.Infof("Recommitting blocks [%d] to [%d] of ledger [%s]", firstBlockNum, lastBlockNum, l.ledgerID)
 	for blockNumber := firstBlockNum; blockNumber <= lastBlockNum; blockNumber++ {
 		if block, err = l.GetBlockByNumber(blockNumber); err != nil {
 			return err
 		}
+		if blockNumber > firstBlockNum && (blockNumber-firstBlockNum)%recommitProgressInterval == 0 {
+			logger.Infof("Recommitted [%d] blocks of ledger [%s]", blockNumber-firstBlockNum, l.ledgerID)
+		}
 		for _, r := range recoverables {
 			if err := r.CommitLostBlock(block); err != nil {
 				return err
@@ -217,6 +248,8 @@ func (l *kvLedger) Commit(block *common.Block) error {
 	if err != nil {
 		return err
 	}
+	// the updates are kept for the state listeners, the txmgr releases them on commit
+	updates := l.txtmgmt.GetPreparedUpdates()
 
 	logger.Debugf("Channel [%s]: Committing block [%d] to storage", l.ledgerID, blockNo)
 	if err = l.blockStore.AddBlock(block); err != nil {
@@ -237,11 +270,37 @@ func (l *kvLedger) Commit(block *common.Block) error {
 		}
 	}
 
+	l.notifyStateListeners(blockNo, updates)
 	return nil
 }
 
 // Close closes `KVLedger`
 func (l *kvLedger) Close() {
+	l.crossChannelState.unregister(l)
+	cceventmgmt.GetMgr().Unregister(l.ledgerID)
 	l.blockStore.Shutdown()
 	l.txtmgmt.Shutdown()
 }
+
+// ccEventListener implements interface cceventmgmt.ChaincodeLifecycleEventListener
+type ccEventListener struct {
+	vdb statedb.VersionedDB
+}
+
+// HandleChaincodeDeploy implements method in interface cceventmgmt.ChaincodeLifecycleEventListener
+func (l *ccEventListener) HandleChaincodeDeploy(chaincodeDefinition *cceventmgmt.ChaincodeDefinition, dbArtifacts map[string][]byte) error {
+	indexCapable, ok := l.vdb.(statedb.IndexCapable)
+	if !ok || len(dbArtifacts) == 0 {
+		return nil
+	}
+	return indexCapable.ProcessIndexesForChaincodeDeploy(chaincodeDefinition.Name, dbArtifacts)
+}
+
+// GetLSCCState implements method in interface cceventmgmt.ChaincodeLifecycleEventListener
+func (l *ccEventListener) GetLSCCState(chaincodeName string) ([]byte, error) {
+	vv, err := l.vdb.GetState("lscc", chaincodeName)
+	if err != nil || vv == nil {
+		return nil, err
+	}
+	return vv.Value, nil
+}
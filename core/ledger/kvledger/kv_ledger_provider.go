@@ -24,13 +24,16 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage"
 	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/ledger/util"
 	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/history/historydb/historyleveldb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
-	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statecouchdb"
-	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
+	// the state database providers register themselves
+	_ "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statecouchdb"
+	_ "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
+	_ "github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/statesqldb"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -44,9 +47,15 @@ var (
 	ErrNonExistingLedgerID = errors.New("LedgerID does not exist")
 	// ErrLedgerNotOpened is thrown by a CloseLedger call if a ledger with the given id has not been opened
 	ErrLedgerNotOpened = errors.New("Ledger is not opened yet")
+	// ErrLedgerUnderDeletion is thrown by a MarkForDeletion call if another ledger is under deletion
+	ErrLedgerUnderDeletion = errors.New("Another ledger is under deletion")
 
 	underConstructionLedgerKey = []byte("underConstructionLedgerKey")
+	underDeletionLedgerKey     = []byte("underDeletionLedgerKey")
 	ledgerKeyPrefix            = []byte("l")
+	// stateListenerCheckpointKeyPrefix prefixes the keys of the state listener checkpoints, which are
+	// of the form <prefix><ledgerID>0x00<listener name>
+	stateListenerCheckpointKeyPrefix = []byte("s")
 )
 
 // Provider implements interface ledger.PeerLedgerProvider
@@ -55,6 +64,8 @@ type Provider struct {
 	blockStoreProvider blkstorage.BlockStoreProvider
 	vdbProvider        statedb.VersionedDBProvider
 	historydbProvider  historydb.HistoryDBProvider
+	stateListeners     []ledger.StateListener
+	crossChannelState  *crossChannelState
 }
 
 // NewProvider instantiates a new Provider.
@@ -63,10 +74,33 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 
 	logger.Info("Initializing ledger provider")
 
+	blockStoreConf, err := fsblkstorage.NewConfWithFormat(ledgerconfig.GetBlockStorePath(), ledgerconfig.GetMaxBlockfileSize(),
+		ledgerconfig.GetBlockfileFormat(), ledgerconfig.GetBlockfileCompression())
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize the ID store (inventory of chainIds/ledgerIds)
 	idStore := openIDStore(ledgerconfig.GetLedgerProviderPath())
 
 	// Initialize the block storage
+	blockStoreProvider := fsblkstorage.NewProvider(blockStoreConf, BlockStoreIndexConfig())
+
+	// Initialize the state database and the history database
+	vdbProvider, historydbProvider, err := newDBProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("ledger provider Initialized")
+	provider := &Provider{idStore, blockStoreProvider, vdbProvider, historydbProvider, nil, newCrossChannelState(idStore)}
+	provider.recoverUnderConstructionLedger()
+	provider.recoverUnderDeletionLedger()
+	return provider, nil
+}
+
+// BlockStoreIndexConfig returns the attributes indexed by the block stores of the ledgers
+func BlockStoreIndexConfig() *blkstorage.IndexConfig {
 	attrsToIndex := []blkstorage.IndexableAttr{
 		blkstorage.IndexableAttrBlockHash,
 		blkstorage.IndexableAttrBlockNum,
@@ -75,33 +109,19 @@ func NewProvider() (ledger.PeerLedgerProvider, error) {
 		blkstorage.IndexableAttrBlockTxID,
 		blkstorage.IndexableAttrTxValidationCode,
 	}
-	indexConfig := &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
-	blockStoreProvider := fsblkstorage.NewProvider(
-		fsblkstorage.NewConf(ledgerconfig.GetBlockStorePath(), ledgerconfig.GetMaxBlockfileSize()),
-		indexConfig)
-
-	// Initialize the versioned database (state database)
-	var vdbProvider statedb.VersionedDBProvider
-	if !ledgerconfig.IsCouchDBEnabled() {
-		logger.Debug("Constructing leveldb VersionedDBProvider")
-		vdbProvider = stateleveldb.NewVersionedDBProvider()
-	} else {
-		logger.Debug("Constructing CouchDB VersionedDBProvider")
-		var err error
-		vdbProvider, err = statecouchdb.NewVersionedDBProvider()
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	// Initialize the history database (index for history of values by key)
-	var historydbProvider historydb.HistoryDBProvider
-	historydbProvider = historyleveldb.NewHistoryDBProvider()
+	return &blkstorage.IndexConfig{AttrsToIndex: attrsToIndex}
+}
 
-	logger.Info("ledger provider Initialized")
-	provider := &Provider{idStore, blockStoreProvider, vdbProvider, historydbProvider}
-	provider.recoverUnderConstructionLedger()
-	return provider, nil
+// newDBProviders instantiates the providers of the versioned database (state database)
+// and of the history database (index for history of values by key)
+func newDBProviders() (statedb.VersionedDBProvider, historydb.HistoryDBProvider, error) {
+	stateDatabase := ledgerconfig.GetStateDatabase()
+	logger.Debugf("Constructing %s VersionedDBProvider", stateDatabase)
+	vdbProvider, err := statedb.NewVersionedDBProvider(stateDatabase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vdbProvider, historyleveldb.NewHistoryDBProvider(), nil
 }
 
 // Create implements the corresponding method from interface ledger.PeerLedgerProvider
@@ -174,7 +194,7 @@ func (provider *Provider) openInternal(ledgerID string) (ledger.PeerLedger, erro
 
 	// Create a kvLedger for this chain/ledger, which encasulates the underlying data stores
 	// (id store, blockstore, state database, history database)
-	l, err := newKVLedger(ledgerID, blockStore, vDB, historyDB)
+	l, err := newKVLedger(ledgerID, blockStore, vDB, historyDB, provider.crossChannelState, provider.stateListeners, provider.idStore)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +211,75 @@ func (provider *Provider) List() ([]string, error) {
 	return provider.idStore.getAllLedgerIds()
 }
 
+// MarkForDeletion implements the corresponding method from interface ledger.PeerLedgerProvider
+// The ledger is removed from the list of created ledgers along with setting an under deletion
+// flag (atomically). If a crash happens before the ledger gets deleted, the 'recoverUnderDeletionLedger'
+// function completes the deletion before declaring the provider to be usable
+func (provider *Provider) MarkForDeletion(ledgerID string) error {
+	exists, err := provider.idStore.ledgerIDExists(ledgerID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNonExistingLedgerID
+	}
+	underDeletionID, err := provider.idStore.getUnderDeletionFlag()
+	if err != nil {
+		return err
+	}
+	if underDeletionID != "" {
+		return ErrLedgerUnderDeletion
+	}
+	return provider.idStore.setUnderDeletionFlag(ledgerID)
+}
+
+// Delete implements the corresponding method from interface ledger.PeerLedgerProvider
+// The ledger is first marked for deletion, unless it already is. Then the data of the ledger
+// gets deleted from all the stores and the under deletion flag is unset
+func (provider *Provider) Delete(ledgerID string) error {
+	underDeletionID, err := provider.idStore.getUnderDeletionFlag()
+	if err != nil {
+		return err
+	}
+	if underDeletionID != ledgerID {
+		if err = provider.MarkForDeletion(ledgerID); err != nil {
+			return err
+		}
+	}
+	if err = provider.deleteLedgerData(ledgerID); err != nil {
+		return err
+	}
+	return provider.idStore.unsetUnderDeletionFlag()
+}
+
+// RegisterStateListener implements the corresponding method from interface ledger.PeerLedgerProvider
+func (provider *Provider) RegisterStateListener(listener ledger.StateListener) error {
+	for _, l := range provider.stateListeners {
+		if l.Name() == listener.Name() {
+			return fmt.Errorf("A state listener with name [%s] is already registered", listener.Name())
+		}
+	}
+	logger.Infof("Registering state listener [%s]", listener.Name())
+	provider.stateListeners = append(provider.stateListeners, listener)
+	return nil
+}
+
+// deleteLedgerData deletes the block store, the state database, the history database
+// and the state listener checkpoints of the ledger
+func (provider *Provider) deleteLedgerData(ledgerID string) error {
+	logger.Infof("Deleting the data of ledger [%s]", ledgerID)
+	if err := provider.idStore.deleteStateListenerCheckpoints(ledgerID); err != nil {
+		return err
+	}
+	if err := provider.vdbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	if err := provider.historydbProvider.Drop(ledgerID); err != nil {
+		return err
+	}
+	return provider.blockStoreProvider.Drop(ledgerID)
+}
+
 // Close implements the corresponding method from interface ledger.PeerLedgerProvider
 func (provider *Provider) Close() {
 	provider.idStore.close()
@@ -236,6 +325,20 @@ func (provider *Provider) recoverUnderConstructionLedger() {
 	return
 }
 
+// recoverUnderDeletionLedger checks whether the under deletion flag is set - this would be the case
+// if a crash had happened while a ledger was being deleted. Recovery deletes the remaining data of the
+// ledger and then clears the under deletion flag
+func (provider *Provider) recoverUnderDeletionLedger() {
+	ledgerID, err := provider.idStore.getUnderDeletionFlag()
+	panicOnErr(err, "Error while checking whether the under deletion flag is set")
+	if ledgerID == "" {
+		return
+	}
+	logger.Infof("ledger [%s] found as under deletion, completing its deletion", ledgerID)
+	panicOnErr(provider.deleteLedgerData(ledgerID), "Error while deleting the data of ledger [%s]", ledgerID)
+	panicOnErr(provider.idStore.unsetUnderDeletionFlag(), "Error while unsetting under deletion flag")
+}
+
 // runCleanup cleans up blockstorage, statedb, and historydb for what
 // may have got created during in-complete ledger creation
 func (provider *Provider) runCleanup(ledgerID string) error {
@@ -284,6 +387,57 @@ func (s *idStore) getUnderConstructionFlag() (string, error) {
 	return string(val), nil
 }
 
+// setUnderDeletionFlag removes the ledger from the created ledgers and sets the under deletion flag atomically
+func (s *idStore) setUnderDeletionFlag(ledgerID string) error {
+	batch := &leveldb.Batch{}
+	batch.Delete(s.encodeLedgerKey(ledgerID))
+	batch.Put(underDeletionLedgerKey, []byte(ledgerID))
+	return s.db.WriteBatch(batch, true)
+}
+
+func (s *idStore) unsetUnderDeletionFlag() error {
+	return s.db.Delete(underDeletionLedgerKey, true)
+}
+
+func (s *idStore) getUnderDeletionFlag() (string, error) {
+	val, err := s.db.Get(underDeletionLedgerKey)
+	if err != nil {
+		return "", err
+	}
+	return string(val), nil
+}
+
+func (s *idStore) setStateListenerCheckpoint(ledgerID string, listenerName string, blockNum uint64) error {
+	return s.db.Put(s.encodeStateListenerCheckpointKey(ledgerID, listenerName), util.EncodeOrderPreservingVarUint64(blockNum), true)
+}
+
+// getStateListenerCheckpoint returns the last block delivered to the listener
+// and false if the listener has not been notified of any block yet
+func (s *idStore) getStateListenerCheckpoint(ledgerID string, listenerName string) (uint64, bool, error) {
+	val, err := s.db.Get(s.encodeStateListenerCheckpointKey(ledgerID, listenerName))
+	if err != nil || val == nil {
+		return 0, false, err
+	}
+	blockNum, _ := util.DecodeOrderPreservingVarUint64(val)
+	return blockNum, true, nil
+}
+
+func (s *idStore) deleteStateListenerCheckpoints(ledgerID string) error {
+	startKey := s.encodeStateListenerCheckpointKey(ledgerID, "")
+	endKey := append([]byte{}, startKey...)
+	endKey[len(endKey)-1] = 0x01
+	itr := s.db.GetIterator(startKey, endKey)
+	defer itr.Release()
+	batch := &leveldb.Batch{}
+	for itr.Next() {
+		batch.Delete(append([]byte{}, itr.Key()...))
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+	return s.db.WriteBatch(batch, true)
+}
+
 func (s *idStore) createLedgerID(ledgerID string, gb *common.Block) error {
 	key := s.encodeLedgerKey(ledgerID)
 	var val []byte
@@ -316,16 +470,15 @@ func (s *idStore) ledgerIDExists(ledgerID string) (bool, error) {
 func (s *idStore) getAllLedgerIds() ([]string, error) {
 	var ids []string
 	itr := s.db.GetIterator(nil, nil)
-	itr.First()
-	for itr.Valid() {
-		if bytes.Equal(itr.Key(), underConstructionLedgerKey) {
+	defer itr.Release()
+	for itr.Next() {
+		if !bytes.HasPrefix(itr.Key(), ledgerKeyPrefix) {
 			continue
 		}
 		id := string(s.decodeLedgerID(itr.Key()))
 		ids = append(ids, id)
-		itr.Next()
 	}
-	return ids, nil
+	return ids, itr.Error()
 }
 
 func (s *idStore) close() {
@@ -336,6 +489,13 @@ func (s *idStore) encodeLedgerKey(ledgerID string) []byte {
 	return append(ledgerKeyPrefix, []byte(ledgerID)...)
 }
 
+func (s *idStore) encodeStateListenerCheckpointKey(ledgerID string, listenerName string) []byte {
+	key := append([]byte{}, stateListenerCheckpointKeyPrefix...)
+	key = append(key, []byte(ledgerID)...)
+	key = append(key, 0x00)
+	return append(key, []byte(listenerName)...)
+}
+
 func (s *idStore) decodeLedgerID(key []byte) string {
 	return string(key[len(ledgerKeyPrefix):])
 }
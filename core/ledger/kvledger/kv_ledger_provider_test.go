@@ -81,6 +81,42 @@ func TestLedgerProvider(t *testing.T) {
 	testutil.AssertEquals(t, err, ErrNonExistingLedgerID)
 }
 
+func TestLedgerProviderBlockfileFormat(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer viper.Set("ledger.blockchain.blockfileFormat", "legacy")
+	defer viper.Set("ledger.blockchain.blockfileCompression", "none")
+	viper.Set("ledger.blockchain.blockfileFormat", "checksummed")
+	viper.Set("ledger.blockchain.blockfileCompression", "snappy")
+	provider, err := NewProvider()
+	testutil.AssertNoError(t, err, "")
+	genesisBlock, _ := configtxtest.MakeGenesisBlock(constructTestLedgerID(1))
+	_, err = provider.Create(genesisBlock)
+	testutil.AssertNoError(t, err, "")
+	provider.Close()
+
+	// the genesis block is read back from the compressed block file
+	scanner, err := fsblkstorage.NewBlockScanner(ledgerconfig.GetBlockStorePath(), constructTestLedgerID(1))
+	testutil.AssertNoError(t, err, "")
+	defer scanner.Close()
+	block, err := scanner.Next()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, block, genesisBlock)
+
+	viper.Set("ledger.blockchain.blockfileCompression", "lz4")
+	_, err = NewProvider()
+	testutil.AssertError(t, err, "unsupported compression")
+}
+
+func TestUnknownStateDatabase(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	defer viper.Set("ledger.state.stateDatabase", "goleveldb")
+	viper.Set("ledger.state.stateDatabase", "unknown")
+	_, _, err := newDBProviders()
+	testutil.AssertError(t, err, "An unknown state database should not be replaced by another one")
+}
+
 func TestRecovery(t *testing.T) {
 	env := newTestEnv(t)
 	defer env.cleanup()
@@ -281,3 +317,66 @@ func TestLedgerBackup(t *testing.T) {
 func constructTestLedgerID(i int) string {
 	return fmt.Sprintf("ledger_%06d", i)
 }
+
+func TestLedgerDeletion(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	for i := 0; i < 2; i++ {
+		bg, gb := testutil.NewBlockGenerator(t, constructTestLedgerID(i), false)
+		l, err := provider.Create(gb)
+		testutil.AssertNoError(t, err, "")
+		simulator, _ := l.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte("value1"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		testutil.AssertNoError(t, l.Commit(bg.NextBlock([][]byte{simRes})), "")
+		l.Close()
+	}
+
+	testutil.AssertEquals(t, provider.Delete(constructTestLedgerID(2)), ErrNonExistingLedgerID)
+	testutil.AssertEquals(t, provider.MarkForDeletion(constructTestLedgerID(2)), ErrNonExistingLedgerID)
+
+	// a ledger marked for deletion is no longer listed and only one ledger can be under deletion at a time
+	testutil.AssertNoError(t, provider.MarkForDeletion(constructTestLedgerID(0)), "")
+	ledgerIDs, _ := provider.List()
+	testutil.AssertEquals(t, ledgerIDs, []string{constructTestLedgerID(1)})
+	testutil.AssertEquals(t, provider.MarkForDeletion(constructTestLedgerID(1)), ErrLedgerUnderDeletion)
+	testutil.AssertEquals(t, provider.Delete(constructTestLedgerID(1)), ErrLedgerUnderDeletion)
+
+	testutil.AssertNoError(t, provider.Delete(constructTestLedgerID(0)), "")
+	ledgerIDs, _ = provider.List()
+	testutil.AssertEquals(t, ledgerIDs, []string{constructTestLedgerID(1)})
+	_, err := provider.Open(constructTestLedgerID(0))
+	testutil.AssertEquals(t, err, ErrNonExistingLedgerID)
+
+	// the ledger can be created again from scratch
+	_, gb := testutil.NewBlockGenerator(t, constructTestLedgerID(0), false)
+	l, err := provider.Create(gb)
+	testutil.AssertNoError(t, err, "")
+	bcInfo, _ := l.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(1))
+	qe, _ := l.NewQueryExecutor()
+	val, err := qe.GetState("ns1", "key1")
+	qe.Done()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNil(t, val)
+	l.Close()
+
+	// assume a crash happens after the ledger got marked for deletion
+	testutil.AssertNoError(t, provider.MarkForDeletion(constructTestLedgerID(1)), "")
+	provider.Close()
+
+	// construct a new provider to invoke recovery
+	provider, err = NewProvider()
+	testutil.AssertNoError(t, err, "")
+	defer provider.Close()
+	flag, err := provider.(*Provider).idStore.getUnderDeletionFlag()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, flag, "")
+	ledgerIDs, _ = provider.List()
+	testutil.AssertEquals(t, ledgerIDs, []string{constructTestLedgerID(0)})
+	exists, err := provider.(*Provider).blockStoreProvider.Exists(constructTestLedgerID(1))
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertEquals(t, exists, false)
+}
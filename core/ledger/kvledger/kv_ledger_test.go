@@ -20,8 +20,10 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
 	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
 	"github.com/hyperledger/fabric/protos/common"
@@ -441,6 +443,98 @@ func TestKVLedgerDBRecovery(t *testing.T) {
 	simulator.Done()
 }
 
+type mockCCInfoProvider struct{}
+
+func (p *mockCCInfoProvider) GetChaincodeDefinition(lsccValue []byte) (*cceventmgmt.ChaincodeDefinition, error) {
+	return &cceventmgmt.ChaincodeDefinition{Name: "cc1", Version: string(lsccValue)}, nil
+}
+
+func (p *mockCCInfoProvider) RetrieveChaincodeArtifacts(chaincodeDefinition *cceventmgmt.ChaincodeDefinition) (bool, map[string][]byte, error) {
+	return true, map[string][]byte{"couchdb/indexes/index.json": []byte("{}")}, nil
+}
+
+type mockCCEventListener struct {
+	deployed chan *cceventmgmt.ChaincodeDefinition
+	// release, if not nil, holds back the processing of the deployments until closed
+	release chan struct{}
+}
+
+func (l *mockCCEventListener) HandleChaincodeDeploy(chaincodeDefinition *cceventmgmt.ChaincodeDefinition, dbArtifacts map[string][]byte) error {
+	if l.release != nil {
+		<-l.release
+	}
+	l.deployed <- chaincodeDefinition
+	return nil
+}
+
+func (l *mockCCEventListener) GetLSCCState(chaincodeName string) ([]byte, error) {
+	return nil, nil
+}
+
+func TestChaincodeDeployEvents(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+
+	cceventmgmt.GetMgr().Initialize(&mockCCInfoProvider{})
+	defer cceventmgmt.GetMgr().Initialize(nil)
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	ledger, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer ledger.Close()
+
+	// replace the listener the ledger registered with a spy, which processes
+	// the deployments once released
+	listener := &mockCCEventListener{deployed: make(chan *cceventmgmt.ChaincodeDefinition, 10), release: make(chan struct{})}
+	cceventmgmt.GetMgr().Register("testLedger", listener)
+
+	simulator, _ := ledger.NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	assert.NoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes})))
+
+	// the blocks keep being committed while the deployments are processed
+	for _, version := range []string{"1.0", "2.0"} {
+		simulator, _ = ledger.NewTxSimulator()
+		simulator.SetState("lscc", "cc1", []byte(version))
+		simulator.Done()
+		simRes, _ = simulator.GetTxSimulationResults()
+		assert.NoError(t, ledger.Commit(bg.NextBlock([][]byte{simRes})))
+	}
+	bcInfo, _ := ledger.GetBlockchainInfo()
+	assert.Equal(t, uint64(4), bcInfo.Height)
+	assert.Len(t, listener.deployed, 0)
+
+	// the deployments are processed in the order of their blocks
+	close(listener.release)
+	for _, version := range []string{"1.0", "2.0"} {
+		select {
+		case deployed := <-listener.deployed:
+			assert.Equal(t, &cceventmgmt.ChaincodeDefinition{Name: "cc1", Version: version}, deployed)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("deployment of cc1 %s was not processed", version)
+		}
+	}
+	assert.Len(t, listener.deployed, 0)
+
+	vdb, err := provider.(*Provider).vdbProvider.GetDBHandle("testLedger")
+	assert.NoError(t, err)
+	ledgerListener := &ccEventListener{vdb}
+	lsccValue, err := ledgerListener.GetLSCCState("cc1")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2.0"), lsccValue)
+	lsccValue, err = ledgerListener.GetLSCCState("cc2")
+	assert.NoError(t, err)
+	assert.Nil(t, lsccValue)
+
+	// leveldb does not process the statedb artifacts
+	assert.NoError(t, ledgerListener.HandleChaincodeDeploy(&cceventmgmt.ChaincodeDefinition{Name: "cc1", Version: "1.0"},
+		map[string][]byte{"couchdb/indexes/index.json": []byte("{}")}))
+}
+
 func TestLedgerWithCouchDbEnabledWithBinaryAndJSONData(t *testing.T) {
 
 	//call a helper method to load the core.yaml
@@ -0,0 +1,71 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+// RebuildDBs drops the state database and the history database of all the ledgers
+// and rebuilds them from the block store. The transactions are not validated again,
+// the validation flags stored in the block metadata decide which ones get applied.
+// The state database that gets rebuilt is the one configured, which allows to move
+// a peer from goleveldb to CouchDB. This is meant to be invoked while the peer is stopped
+func RebuildDBs() error {
+	idStore := openIDStore(ledgerconfig.GetLedgerProviderPath())
+	ledgerIDs, err := idStore.getAllLedgerIds()
+	idStore.close()
+	if err != nil {
+		return err
+	}
+
+	vdbProvider, historydbProvider, err := newDBProviders()
+	if err != nil {
+		return err
+	}
+	for _, ledgerID := range ledgerIDs {
+		if err = vdbProvider.Drop(ledgerID); err != nil {
+			break
+		}
+		if err = historydbProvider.Drop(ledgerID); err != nil {
+			break
+		}
+	}
+	vdbProvider.Close()
+	historydbProvider.Close()
+	if err != nil {
+		return err
+	}
+
+	// The dbs are rebuilt by the recovery that takes place when a ledger is opened. If a crash
+	// happens halfway, the recovery resumes from the last block that got committed to the dbs
+	provider, err := NewProvider()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	for _, ledgerID := range ledgerIDs {
+		logger.Infof("Rebuilding the dbs of ledger [%s]", ledgerID)
+		l, err := provider.Open(ledgerID)
+		if err != nil {
+			return err
+		}
+		l.Close()
+		logger.Infof("Rebuilt the dbs of ledger [%s]", ledgerID)
+	}
+	return nil
+}
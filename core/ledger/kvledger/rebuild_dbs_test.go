@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+)
+
+func TestRebuildDBs(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	numLedgers := 2
+	for i := 0; i < numLedgers; i++ {
+		bg, gb := testutil.NewBlockGenerator(t, constructTestLedgerID(i), false)
+		l, err := provider.Create(gb)
+		testutil.AssertNoError(t, err, "")
+		commitTestBlocks(t, l, bg, 5)
+
+		// a transaction flagged invalid by the committer is not applied
+		simulator, _ := l.NewTxSimulator()
+		simulator.SetState("ns1", "key", []byte("invalid_value"))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		block := bg.NextBlock([][]byte{simRes})
+		txsFilter := lutil.NewTxValidationFlags(len(block.Data.Data))
+		txsFilter.SetFlag(0, peer.TxValidationCode_ENDORSEMENT_POLICY_FAILURE)
+		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
+		testutil.AssertNoError(t, l.Commit(block), "")
+		l.Close()
+	}
+	provider.Close()
+
+	// corrupt the state db of the first ledger
+	vdbProvider, historydbProvider, err := newDBProviders()
+	testutil.AssertNoError(t, err, "")
+	vdb, _ := vdbProvider.GetDBHandle(constructTestLedgerID(0))
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key", []byte("corrupted_value"), version.NewHeight(6, 0))
+	batch.Put("ns1", "bogusKey", []byte("bogus_value"), version.NewHeight(6, 0))
+	testutil.AssertNoError(t, vdb.ApplyUpdates(batch, version.NewHeight(6, 0)), "")
+	vdbProvider.Close()
+	historydbProvider.Close()
+
+	testutil.AssertNoError(t, RebuildDBs(), "")
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	for i := 0; i < numLedgers; i++ {
+		l, err := provider.Open(constructTestLedgerID(i))
+		testutil.AssertNoError(t, err, "")
+		assertStateAndHistory(t, l, 5)
+		qe, _ := l.NewQueryExecutor()
+		val, err := qe.GetState("ns1", "bogusKey")
+		qe.Done()
+		testutil.AssertNoError(t, err, "")
+		testutil.AssertNil(t, val)
+		l.Close()
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+// RollbackKVLedger rolls back the ledger of the given id so that blockNum becomes its last block.
+// The state database and the history database are dropped and get rebuilt from the block store
+// the next time the ledger is opened. This is meant to be invoked while the peer is stopped
+func RollbackKVLedger(ledgerID string, blockNum uint64) error {
+	idStore := openIDStore(ledgerconfig.GetLedgerProviderPath())
+	defer idStore.close()
+	exists, err := idStore.ledgerIDExists(ledgerID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrNonExistingLedgerID
+	}
+	return rollbackKVLedgers([]string{ledgerID}, blockNum)
+}
+
+// ResetAllKVLedgers rolls back all the ledgers to their genesis block
+func ResetAllKVLedgers() error {
+	idStore := openIDStore(ledgerconfig.GetLedgerProviderPath())
+	defer idStore.close()
+	ledgerIDs, err := idStore.getAllLedgerIds()
+	if err != nil {
+		return err
+	}
+	return rollbackKVLedgers(ledgerIDs, 0)
+}
+
+func rollbackKVLedgers(ledgerIDs []string, blockNum uint64) error {
+	blockStorePath := ledgerconfig.GetBlockStorePath()
+	for _, ledgerID := range ledgerIDs {
+		if err := fsblkstorage.ValidateRollbackParams(blockStorePath, ledgerID, blockNum); err != nil {
+			return err
+		}
+	}
+
+	// The dbs are dropped before the block store gets truncated. If a crash happens in between,
+	// the dbs are merely rebuilt from the complete block store and the rollback can be invoked again
+	vdbProvider, historydbProvider, err := newDBProviders()
+	if err != nil {
+		return err
+	}
+	defer vdbProvider.Close()
+	defer historydbProvider.Close()
+	for _, ledgerID := range ledgerIDs {
+		logger.Infof("Rolling back ledger [%s] to block [%d]", ledgerID, blockNum)
+		if err := vdbProvider.Drop(ledgerID); err != nil {
+			return err
+		}
+		if err := historydbProvider.Drop(ledgerID); err != nil {
+			return err
+		}
+		if err := fsblkstorage.Rollback(blockStorePath, ledgerID, blockNum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackKVLedger(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	ledgerID := constructTestLedgerID(1)
+	bg, gb := testutil.NewBlockGenerator(t, ledgerID, false)
+	l, err := provider.Create(gb)
+	testutil.AssertNoError(t, err, "")
+	blocks := commitTestBlocks(t, l, bg, 10)
+	l.Close()
+	provider.Close()
+
+	testutil.AssertEquals(t, RollbackKVLedger(constructTestLedgerID(2), 5), ErrNonExistingLedgerID)
+	testutil.AssertError(t, RollbackKVLedger(ledgerID, 11), "block beyond the last block")
+	testutil.AssertNoError(t, RollbackKVLedger(ledgerID, 5), "")
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	l, err = provider.Open(ledgerID)
+	testutil.AssertNoError(t, err, "")
+	defer l.Close()
+
+	// the state and the history are those as of block 5
+	bcInfo, _ := l.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(6))
+	testutil.AssertEquals(t, bcInfo.CurrentBlockHash, blocks[4].Header.Hash())
+	assertStateAndHistory(t, l, 5)
+
+	// the removed blocks can be committed again
+	for _, b := range blocks[5:] {
+		testutil.AssertNoError(t, l.Commit(b), "")
+	}
+	assertStateAndHistory(t, l, 10)
+}
+
+func TestResetAllKVLedgers(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	numLedgers := 3
+	for i := 0; i < numLedgers; i++ {
+		bg, gb := testutil.NewBlockGenerator(t, constructTestLedgerID(i), false)
+		l, err := provider.Create(gb)
+		testutil.AssertNoError(t, err, "")
+		commitTestBlocks(t, l, bg, 5)
+		l.Close()
+	}
+	provider.Close()
+
+	testutil.AssertNoError(t, ResetAllKVLedgers(), "")
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	for i := 0; i < numLedgers; i++ {
+		l, err := provider.Open(constructTestLedgerID(i))
+		testutil.AssertNoError(t, err, "")
+		bcInfo, _ := l.GetBlockchainInfo()
+		testutil.AssertEquals(t, bcInfo.Height, uint64(1))
+		assertStateAndHistory(t, l, 0)
+		l.Close()
+	}
+}
+
+func TestOpenWithStateAheadOfBlockStore(t *testing.T) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	ledgerID := constructTestLedgerID(1)
+	bg, gb := testutil.NewBlockGenerator(t, ledgerID, false)
+	l, err := provider.Create(gb)
+	testutil.AssertNoError(t, err, "")
+	commitTestBlocks(t, l, bg, 5)
+	l.Close()
+	provider.Close()
+
+	// truncate the block store alone, leaving the dbs ahead of it
+	testutil.AssertNoError(t, fsblkstorage.Rollback(ledgerconfig.GetBlockStorePath(), ledgerID, 2), "")
+
+	provider, _ = NewProvider()
+	defer provider.Close()
+	assert.Panics(t, func() { provider.Open(ledgerID) }, "the state db is ahead of the block store")
+}
+
+// commitTestBlocks commits numBlocks blocks, the block i setting the key "key" to "value_i"
+func commitTestBlocks(t *testing.T, l ledger.PeerLedger, bg *testutil.BlockGenerator, numBlocks int) []*common.Block {
+	var blocks []*common.Block
+	for i := 1; i <= numBlocks; i++ {
+		simulator, _ := l.NewTxSimulator()
+		simulator.SetState("ns1", "key", []byte(fmt.Sprintf("value_%d", i)))
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		block := bg.NextBlock([][]byte{simRes})
+		testutil.AssertNoError(t, l.Commit(block), "")
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func assertStateAndHistory(t *testing.T, l ledger.PeerLedger, lastBlockNum int) {
+	qe, _ := l.NewQueryExecutor()
+	defer qe.Done()
+	val, err := qe.GetState("ns1", "key")
+	testutil.AssertNoError(t, err, "")
+	if lastBlockNum == 0 {
+		testutil.AssertNil(t, val)
+	} else {
+		testutil.AssertEquals(t, string(val), fmt.Sprintf("value_%d", lastBlockNum))
+	}
+
+	if !ledgerconfig.IsHistoryDBEnabled() {
+		return
+	}
+	hqe, err := l.NewHistoryQueryExecutor()
+	testutil.AssertNoError(t, err, "")
+	itr, err := hqe.GetHistoryForKey("ns1", "key")
+	testutil.AssertNoError(t, err, "")
+	defer itr.Close()
+	count := 0
+	for {
+		kmod, _ := itr.Next()
+		if kmod == nil {
+			break
+		}
+		count++
+		testutil.AssertEquals(t, string(kmod.(*queryresult.KeyModification).Value), fmt.Sprintf("value_%d", count))
+	}
+	testutil.AssertEquals(t, count, lastBlockNum)
+}
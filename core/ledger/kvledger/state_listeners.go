@@ -0,0 +1,169 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"sort"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/statebasedval"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// stateListenerProxy delivers the state updates of a ledger to a ledger.StateListener and checkpoints
+// the last block delivered successfully. It implements interface recoverable, so that the blocks
+// after the checkpoint are delivered again when the ledger is opened
+type stateListenerProxy struct {
+	ledgerID    string
+	listener    ledger.StateListener
+	namespaces  map[string]bool
+	checkpoints *idStore
+	// validator is used for preparing the updates of the blocks delivered again. The blocks were
+	// validated when first committed, hence the preparation does not read the state database
+	validator validator.Validator
+	// inSync tells whether the listener has been notified of all the blocks committed so far
+	inSync bool
+}
+
+func newStateListenerProxy(ledgerID string, listener ledger.StateListener,
+	checkpoints *idStore, vdb statedb.VersionedDB) *stateListenerProxy {
+	var namespaces map[string]bool
+	if interestedNamespaces := listener.InterestedInNamespaces(); len(interestedNamespaces) > 0 {
+		namespaces = make(map[string]bool)
+		for _, ns := range interestedNamespaces {
+			namespaces[ns] = true
+		}
+	}
+	return &stateListenerProxy{
+		ledgerID:    ledgerID,
+		listener:    listener,
+		namespaces:  namespaces,
+		checkpoints: checkpoints,
+		validator:   statebasedval.NewValidator(vdb, nil),
+	}
+}
+
+// ShouldRecover implements method in interface kvledger.recoverable
+func (p *stateListenerProxy) ShouldRecover(lastAvailableBlock uint64) (bool, uint64, error) {
+	checkpoint, found, err := p.checkpoints.getStateListenerCheckpoint(p.ledgerID, p.listener.Name())
+	if err != nil {
+		return false, 0, err
+	}
+	if !found {
+		return true, 0, nil
+	}
+	if checkpoint > lastAvailableBlock {
+		// the block store has been rolled back, the blocks committed
+		// from now on replace the ones the listener has been notified of
+		logger.Warningf("Channel [%s]: checkpoint of state listener [%s] at block [%d] is ahead of the block store, moving it back to block [%d]",
+			p.ledgerID, p.listener.Name(), checkpoint, lastAvailableBlock)
+		if err := p.checkpoints.setStateListenerCheckpoint(p.ledgerID, p.listener.Name(), lastAvailableBlock); err != nil {
+			return false, 0, err
+		}
+		checkpoint = lastAvailableBlock
+	}
+	return checkpoint != lastAvailableBlock, checkpoint + 1, nil
+}
+
+// CommitLostBlock implements method in interface kvledger.recoverable
+func (p *stateListenerProxy) CommitLostBlock(block *common.Block) error {
+	batch, err := p.validator.ValidateAndPrepareBatch(block, false)
+	if err != nil {
+		return err
+	}
+	return p.deliver(block.Header.Number, batch)
+}
+
+// deliver notifies the listener of the updates of the block and checkpoints the block
+func (p *stateListenerProxy) deliver(blockNum uint64, batch *statedb.UpdateBatch) error {
+	if err := p.listener.HandleStateUpdates(p.ledgerID, blockNum, p.filterUpdates(batch)); err != nil {
+		return err
+	}
+	return p.checkpoints.setStateListenerCheckpoint(p.ledgerID, p.listener.Name(), blockNum)
+}
+
+func (p *stateListenerProxy) filterUpdates(batch *statedb.UpdateBatch) ledger.StateUpdates {
+	updates := make(ledger.StateUpdates)
+	for _, ns := range batch.GetUpdatedNamespaces() {
+		if p.namespaces != nil && !p.namespaces[ns] {
+			continue
+		}
+		nsUpdates := batch.GetUpdates(ns)
+		keys := make([]string, 0, len(nsUpdates))
+		for key := range nsUpdates {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			vv := nsUpdates[key]
+			updates[ns] = append(updates[ns], &ledger.KVStateUpdate{
+				Key:      key,
+				Value:    vv.Value,
+				IsDelete: vv.Value == nil,
+				BlockNum: vv.Version.BlockNum,
+				TxNum:    vv.Version.TxNum,
+			})
+		}
+	}
+	return updates
+}
+
+// recoverStateListeners notifies each listener of the blocks committed after its checkpoint.
+// A listener that fails is not notified anymore until the ledger is reopened, as it would miss blocks
+func (l *kvLedger) recoverStateListeners() {
+	info, err := l.blockStore.GetBlockchainInfo()
+	if err != nil {
+		logger.Errorf("Channel [%s]: error while retrieving blockchain info, state listeners are disabled: %s", l.ledgerID, err)
+		return
+	}
+	if info.Height == 0 {
+		// the ledger is being created, the listeners are notified starting from the genesis block
+		for _, p := range l.stateListeners {
+			p.inSync = true
+		}
+		return
+	}
+	lastAvailableBlockNum := info.Height - 1
+	for _, p := range l.stateListeners {
+		recoverFlag, firstBlockNum, err := p.ShouldRecover(lastAvailableBlockNum)
+		if err == nil && recoverFlag {
+			err = l.recommitLostBlocks(firstBlockNum, lastAvailableBlockNum, p)
+		}
+		if err != nil {
+			logger.Errorf("Channel [%s]: error while notifying state listener [%s] of the committed blocks: %s",
+				l.ledgerID, p.listener.Name(), err)
+			continue
+		}
+		p.inSync = true
+	}
+}
+
+// notifyStateListeners notifies the listeners that are in sync of the updates of the block committed
+func (l *kvLedger) notifyStateListeners(blockNum uint64, batch *statedb.UpdateBatch) {
+	for _, p := range l.stateListeners {
+		if !p.inSync {
+			continue
+		}
+		if err := p.deliver(blockNum, batch); err != nil {
+			logger.Errorf("Channel [%s]: error while notifying state listener [%s] of block [%d], the listener is disabled until the ledger is reopened: %s",
+				l.ledgerID, p.listener.Name(), blockNum, err)
+			p.inSync = false
+		}
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvledger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStateListener struct {
+	name       string
+	namespaces []string
+	failAt     map[uint64]bool
+	blockNums  []uint64
+	updates    map[uint64]ledger.StateUpdates
+}
+
+func newMockStateListener(name string, namespaces ...string) *mockStateListener {
+	return &mockStateListener{name: name, namespaces: namespaces,
+		failAt: make(map[uint64]bool), updates: make(map[uint64]ledger.StateUpdates)}
+}
+
+func (l *mockStateListener) Name() string {
+	return l.name
+}
+
+func (l *mockStateListener) InterestedInNamespaces() []string {
+	return l.namespaces
+}
+
+func (l *mockStateListener) HandleStateUpdates(ledgerID string, blockNum uint64, updates ledger.StateUpdates) error {
+	if l.failAt[blockNum] {
+		return errors.New("mock error")
+	}
+	l.blockNums = append(l.blockNums, blockNum)
+	l.updates[blockNum] = updates
+	return nil
+}
+
+func TestStateListener(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	listener := newMockStateListener("listener", "ns1")
+	assert.NoError(t, provider.RegisterStateListener(listener))
+	assert.Error(t, provider.RegisterStateListener(newMockStateListener("listener")))
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	l, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer l.Close()
+
+	simulator, _ := l.NewTxSimulator()
+	simulator.SetState("ns1", "key2", []byte("value2"))
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.SetState("ns2", "key1", []byte("value1"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	assert.NoError(t, l.Commit(bg.NextBlock([][]byte{simRes})))
+
+	simulator, _ = l.NewTxSimulator()
+	simulator.DeleteState("ns1", "key1")
+	simulator.Done()
+	simRes, _ = simulator.GetTxSimulationResults()
+	assert.NoError(t, l.Commit(bg.NextBlock([][]byte{simRes})))
+
+	assert.Equal(t, []uint64{0, 1, 2}, listener.blockNums)
+	assert.Equal(t, ledger.StateUpdates{
+		"ns1": {
+			{Key: "key1", Value: []byte("value1"), BlockNum: 1, TxNum: 0},
+			{Key: "key2", Value: []byte("value2"), BlockNum: 1, TxNum: 0},
+		},
+	}, listener.updates[1])
+	assert.Equal(t, ledger.StateUpdates{
+		"ns1": {{Key: "key1", IsDelete: true, BlockNum: 2, TxNum: 0}},
+	}, listener.updates[2])
+}
+
+func TestStateListenerRecovery(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	failingListener := newMockStateListener("listener")
+	failingListener.failAt[2] = true
+	provider.RegisterStateListener(failingListener)
+
+	bg, gb := testutil.NewBlockGenerator(t, "testLedger", false)
+	l, _ := provider.Create(gb)
+	for i := 0; i < 3; i++ {
+		simulator, _ := l.NewTxSimulator()
+		simulator.SetState("ns1", "key1", []byte{byte(i)})
+		simulator.Done()
+		simRes, _ := simulator.GetTxSimulationResults()
+		assert.NoError(t, l.Commit(bg.NextBlock([][]byte{simRes})))
+	}
+	// the listener is not notified anymore after failing at block 2
+	assert.Equal(t, []uint64{0, 1}, failingListener.blockNums)
+	l.Close()
+	provider.Close()
+
+	// the listener is notified of the blocks it missed when the ledger is reopened
+	// and a listener registered later is notified of all the blocks
+	provider, _ = NewProvider()
+	defer provider.Close()
+	listener := newMockStateListener("listener")
+	newListener := newMockStateListener("newListener")
+	provider.RegisterStateListener(listener)
+	provider.RegisterStateListener(newListener)
+	l, _ = provider.Open("testLedger")
+	assert.Equal(t, []uint64{2, 3}, listener.blockNums)
+	assert.Equal(t, ledger.StateUpdates{
+		"ns1": {{Key: "key1", Value: []byte{2}, BlockNum: 3, TxNum: 0}},
+	}, listener.updates[3])
+	assert.Equal(t, []uint64{0, 1, 2, 3}, newListener.blockNums)
+	l.Close()
+
+	// the checkpoints are deleted along with the ledger
+	kvProvider := provider.(*Provider)
+	_, found, _ := kvProvider.idStore.getStateListenerCheckpoint("testLedger", "listener")
+	assert.True(t, found)
+	assert.NoError(t, provider.Delete("testLedger"))
+	_, found, _ = kvProvider.idStore.getStateListenerCheckpoint("testLedger", "listener")
+	assert.False(t, found)
+	_, found, _ = kvProvider.idStore.getStateListenerCheckpoint("testLedger", "newListener")
+	assert.False(t, found)
+}
+
+func TestStateListenerCheckpointAheadOfBlockStore(t *testing.T) {
+	env := newTestEnv(t)
+	defer env.cleanup()
+	provider, _ := NewProvider()
+	defer provider.Close()
+	kvProvider := provider.(*Provider)
+	proxy := newStateListenerProxy("testLedger", newMockStateListener("listener"), kvProvider.idStore, nil)
+
+	recoverFlag, firstBlockNum, err := proxy.ShouldRecover(5)
+	assert.NoError(t, err)
+	assert.True(t, recoverFlag)
+	assert.Equal(t, uint64(0), firstBlockNum)
+
+	// a checkpoint ahead of the block store, as after a rollback, is moved back
+	assert.NoError(t, kvProvider.idStore.setStateListenerCheckpoint("testLedger", "listener", 10))
+	recoverFlag, _, err = proxy.ShouldRecover(5)
+	assert.NoError(t, err)
+	assert.False(t, recoverFlag)
+	checkpoint, _, _ := kvProvider.idStore.getStateListenerCheckpoint("testLedger", "listener")
+	assert.Equal(t, uint64(5), checkpoint)
+
+	recoverFlag, firstBlockNum, _ = proxy.ShouldRecover(7)
+	assert.True(t, recoverFlag)
+	assert.Equal(t, uint64(6), firstBlockNum)
+}
@@ -26,16 +26,17 @@ import (
 var logger = flogging.MustGetLogger("rwsetutil")
 
 type nsRWs struct {
-	readMap          map[string]*kvrwset.KVRead //for mvcc validation
-	writeMap         map[string]*kvrwset.KVWrite
-	rangeQueriesMap  map[rangeQueryKey]*kvrwset.RangeQueryInfo //for phantom read validation
-	rangeQueriesKeys []rangeQueryKey
+	readMap           map[string]*kvrwset.KVRead //for mvcc validation
+	writeMap          map[string]*kvrwset.KVWrite
+	rangeQueriesMap   map[rangeQueryKey]*kvrwset.RangeQueryInfo //for phantom read validation
+	rangeQueriesKeys  []rangeQueryKey
+	crossChannelReads []*kvrwset.CrossChannelRead //for validation against the state of other channels
 }
 
 func newNsRWs() *nsRWs {
 	return &nsRWs{make(map[string]*kvrwset.KVRead),
 		make(map[string]*kvrwset.KVWrite),
-		make(map[rangeQueryKey]*kvrwset.RangeQueryInfo), nil}
+		make(map[rangeQueryKey]*kvrwset.RangeQueryInfo), nil, nil}
 }
 
 type rangeQueryKey struct {
@@ -77,6 +78,13 @@ func (rws *RWSetBuilder) AddToRangeQuerySet(ns string, rqi *kvrwset.RangeQueryIn
 	}
 }
 
+// AddToCrossChannelReadSet adds the details of a chaincode invocation on another channel
+// for performing the validation against the committed state of that channel
+func (rws *RWSetBuilder) AddToCrossChannelReadSet(ns string, ccr *kvrwset.CrossChannelRead) {
+	nsRWs := rws.getOrCreateNsRW(ns)
+	nsRWs.crossChannelReads = append(nsRWs.crossChannelReads, ccr)
+}
+
 // GetTxReadWriteSet returns the read-write set in the form that can be serialized
 func (rws *RWSetBuilder) GetTxReadWriteSet() *TxRwSet {
 	txRWSet := &TxRwSet{}
@@ -105,7 +113,8 @@ func (rws *RWSetBuilder) GetTxReadWriteSet() *TxRwSet {
 		for _, key := range nsReadWriteMap.rangeQueriesKeys {
 			rangeQueriesInfo = append(rangeQueriesInfo, rangeQueriesMap[key])
 		}
-		kvRWs := &kvrwset.KVRWSet{Reads: reads, Writes: writes, RangeQueriesInfo: rangeQueriesInfo}
+		kvRWs := &kvrwset.KVRWSet{Reads: reads, Writes: writes, RangeQueriesInfo: rangeQueriesInfo,
+			CrossChannelReads: nsReadWriteMap.crossChannelReads}
 		nsRWs := &NsRwSet{ns, kvRWs}
 		txRWSet.NsRwSets = append(txRWSet.NsRwSets, nsRWs)
 	}
@@ -74,3 +74,30 @@ func TestRWSetHolder(t *testing.T) {
 	t.Logf("Actual=%s\n Expected=%s", txRWSet, expectedTxRWSet)
 	testutil.AssertEquals(t, txRWSet, expectedTxRWSet)
 }
+
+func TestRWSetHolderCrossChannelReads(t *testing.T) {
+	rwSetBuilder := NewRWSetBuilder()
+	rwSetBuilder.AddToReadSet("ns1", "key1", version.NewHeight(1, 1))
+
+	ccr1 := &kvrwset.CrossChannelRead{ChannelId: "ch2", ChaincodeName: "cc2", BlockHeight: 5,
+		ResponseHash: []byte("hash1"),
+		NsReads:      []*kvrwset.NsReads{{Namespace: "cc2", Reads: []*kvrwset.KVRead{NewKVRead("key1", version.NewHeight(3, 1))}}}}
+	ccr2 := &kvrwset.CrossChannelRead{ChannelId: "ch3", ChaincodeName: "cc3", BlockHeight: 2,
+		ResponseHash: []byte("hash2")}
+	rwSetBuilder.AddToCrossChannelReadSet("ns1", ccr1)
+	rwSetBuilder.AddToCrossChannelReadSet("ns1", ccr2)
+
+	txRWSet := rwSetBuilder.GetTxReadWriteSet()
+	ns1RWSet := &NsRwSet{"ns1", &kvrwset.KVRWSet{
+		Reads:             []*kvrwset.KVRead{NewKVRead("key1", version.NewHeight(1, 1))},
+		CrossChannelReads: []*kvrwset.CrossChannelRead{ccr1, ccr2}}}
+	testutil.AssertEquals(t, txRWSet, &TxRwSet{[]*NsRwSet{ns1RWSet}})
+
+	// cross channel reads should survive the proto round trip
+	protoBytes, err := txRWSet.ToProtoBytes()
+	testutil.AssertNoError(t, err, "")
+	txRWSet1 := &TxRwSet{}
+	testutil.AssertNoError(t, txRWSet1.FromProtoBytes(protoBytes), "")
+	testutil.AssertEquals(t, len(txRWSet1.NsRwSets[0].KvRwSet.CrossChannelReads), 2)
+	testutil.AssertEquals(t, txRWSet1.NsRwSets[0].KvRwSet.CrossChannelReads[0].ChannelId, "ch2")
+}
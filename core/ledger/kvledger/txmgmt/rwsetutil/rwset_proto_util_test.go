@@ -42,18 +42,23 @@ func TestTxRWSetMarshalUnmarshal(t *testing.T) {
 			[]*kvrwset.KVRead{&kvrwset.KVRead{Key: "key1", Version: &kvrwset.Version{BlockNum: 1, TxNum: 1}}},
 			[]*kvrwset.RangeQueryInfo{rqi1},
 			[]*kvrwset.KVWrite{&kvrwset.KVWrite{Key: "key2", IsDelete: false, Value: []byte("value2")}},
+			[]*kvrwset.CrossChannelRead{&kvrwset.CrossChannelRead{ChannelId: "ch2", ChaincodeName: "cc2", BlockHeight: 2,
+				ResponseHash: []byte("Hash-1"), NsReads: []*kvrwset.NsReads{&kvrwset.NsReads{Namespace: "cc2",
+					Reads: []*kvrwset.KVRead{&kvrwset.KVRead{Key: "key5", Version: &kvrwset.Version{BlockNum: 1, TxNum: 1}}}}}}},
 		}},
 
 		&NsRwSet{"ns2", &kvrwset.KVRWSet{
 			[]*kvrwset.KVRead{&kvrwset.KVRead{Key: "key3", Version: &kvrwset.Version{BlockNum: 1, TxNum: 1}}},
 			[]*kvrwset.RangeQueryInfo{rqi2},
 			[]*kvrwset.KVWrite{&kvrwset.KVWrite{Key: "key3", IsDelete: false, Value: []byte("value3")}},
+			nil,
 		}},
 
 		&NsRwSet{"ns3", &kvrwset.KVRWSet{
 			[]*kvrwset.KVRead{&kvrwset.KVRead{Key: "key4", Version: &kvrwset.Version{BlockNum: 1, TxNum: 1}}},
 			nil,
 			[]*kvrwset.KVWrite{&kvrwset.KVWrite{Key: "key4", IsDelete: false, Value: []byte("value4")}},
+			nil,
 		}},
 	}
 
@@ -0,0 +1,70 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statedb
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// VersionedDBProviderFactory constructs a VersionedDBProvider
+type VersionedDBProviderFactory func() (VersionedDBProvider, error)
+
+var (
+	factoriesLock sync.RWMutex
+	factories     = make(map[string]VersionedDBProviderFactory)
+)
+
+// RegisterVersionedDBProvider makes a VersionedDBProvider available under the given name, which is the
+// value of ledger.state.stateDatabase that selects it. A provider is expected to register itself when
+// its package is initialized, hence a peer supports the providers whose packages it imports.
+// It panics if the name is already registered
+func RegisterVersionedDBProvider(name string, factory VersionedDBProviderFactory) {
+	factoriesLock.Lock()
+	defer factoriesLock.Unlock()
+	if factory == nil {
+		panic("statedb: nil factory registered for VersionedDBProvider " + name)
+	}
+	if _, ok := factories[name]; ok {
+		panic("statedb: VersionedDBProvider registered twice: " + name)
+	}
+	factories[name] = factory
+}
+
+// NewVersionedDBProvider constructs the VersionedDBProvider registered under the given name
+func NewVersionedDBProvider(name string) (VersionedDBProvider, error) {
+	factoriesLock.RLock()
+	factory, ok := factories[name]
+	factoriesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("Unknown state database [%s], the registered ones are %v", name, RegisteredVersionedDBProviders())
+	}
+	return factory()
+}
+
+// RegisteredVersionedDBProviders returns the sorted names of the registered VersionedDBProviders
+func RegisteredVersionedDBProviders() []string {
+	factoriesLock.RLock()
+	defer factoriesLock.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,44 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statedb
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterVersionedDBProvider(t *testing.T) {
+	RegisterVersionedDBProvider("testProvider", func() (VersionedDBProvider, error) {
+		return nil, errors.New("testProvider error")
+	})
+	assert.Contains(t, RegisteredVersionedDBProviders(), "testProvider")
+
+	_, err := NewVersionedDBProvider("testProvider")
+	assert.EqualError(t, err, "testProvider error")
+
+	_, err = NewVersionedDBProvider("unknownProvider")
+	assert.Error(t, err)
+
+	assert.Panics(t, func() {
+		RegisterVersionedDBProvider("testProvider", func() (VersionedDBProvider, error) { return nil, nil })
+	})
+	assert.Panics(t, func() {
+		RegisterVersionedDBProvider("nilProvider", nil)
+	})
+}
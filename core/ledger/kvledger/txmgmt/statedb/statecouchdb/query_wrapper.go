@@ -29,6 +29,8 @@ const jsonQuerySelector = "selector"
 const jsonQueryUseIndex = "use_index"
 const jsonQueryLimit = "limit"
 const jsonQuerySkip = "skip"
+const jsonIndexIndex = "index"
+const jsonIndexFields = "fields"
 
 var validOperators = []string{"$and", "$or", "$not", "$nor", "$all", "$elemMatch",
 	"$lt", "$lte", "$eq", "$ne", "$gte", "$gt", "$exits", "$type", "$in", "$nin",
@@ -112,6 +114,74 @@ func ApplyQueryWrapper(namespace, queryString string, queryLimit, querySkip int)
 
 }
 
+/*
+ApplyIndexWrapper parses an index definition shipped in a chaincode package
+the wrapper prepends the wrapper "data." to all fields of the index, so that
+the index applies to the fields the query wrapper produces
+
+- The "chaincodeid" field the queries are scoped to is not wrapped
+
+Example:
+
+Source Index Definition:
+{"index":{"fields":["chaincodeid","owner",{"size":"desc"}]},"ddoc":"indexOwnerDoc","name":"indexOwner","type":"json"}
+
+Result Wrapped Index Definition:
+{"ddoc":"indexOwnerDoc","index":{"fields":["chaincodeid","data.owner",{"data.size":"desc"}]},"name":"indexOwner","type":"json"}
+
+*/
+func ApplyIndexWrapper(indexDefinition string) (string, error) {
+
+	//create a generic map for the index json
+	jsonIndexMap := make(map[string]interface{})
+
+	//unmarshal the index definition into the generic map
+	decoder := json.NewDecoder(bytes.NewBuffer([]byte(indexDefinition)))
+	decoder.UseNumber()
+	err := decoder.Decode(&jsonIndexMap)
+	if err != nil {
+		return "", err
+	}
+
+	index, ok := jsonIndexMap[jsonIndexIndex].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("Index definition must contain an \"%s\" object", jsonIndexIndex)
+	}
+	fields, ok := index[jsonIndexFields].([]interface{})
+	if !ok || len(fields) == 0 {
+		return "", fmt.Errorf("Index definition must contain a non-empty \"%s.%s\" array", jsonIndexIndex, jsonIndexFields)
+	}
+
+	for i, field := range fields {
+		switch fieldType := field.(type) {
+
+		case string:
+			//a simple field name, wrap it and replace in the array
+			if fieldType != "chaincodeid" {
+				fields[i] = fmt.Sprintf("%v.%v", dataWrapper, fieldType)
+			}
+
+		case map[string]interface{}:
+			//a field with its sort direction, {"size":"desc"}
+			for key, value := range fieldType {
+				if key != "chaincodeid" {
+					wrapFieldName(fieldType, key, value)
+				}
+			}
+
+		default:
+			return "", fmt.Errorf("Invalid field in index definition: %v", field)
+		}
+	}
+
+	//Marshal the updated index definition
+	editedIndex, _ := json.Marshal(jsonIndexMap)
+
+	logger.Debugf("Rewritten index definition with data wrapper: %s", editedIndex)
+
+	return string(editedIndex), nil
+}
+
 //setNamespaceInSelector adds an additional hierarchy in the "selector"
 //{"owner": {"$eq": "tom"}}
 //would be mapped as (assuming a namespace of "marble"):
@@ -381,3 +381,33 @@ func TestQueryWithLargeInteger(t *testing.T) {
 	testutil.AssertEquals(t, strings.Count(wrappedQuery, "{\"$eq\":1000007}"), 1)
 
 }
+
+// TestIndexWrapper tests the wrapping of the fields of an index definition
+func TestIndexWrapper(t *testing.T) {
+
+	rawIndex := []byte(`{"index":{"fields":["chaincodeid","owner",{"size":"desc"},{"chaincodeid":"desc"}]},"ddoc":"indexOwnerDoc","name":"indexOwner","type":"json"}`)
+
+	wrappedIndex, err := ApplyIndexWrapper(string(rawIndex))
+
+	//Make sure the index definition did not throw an exception
+	testutil.AssertNoError(t, err, "Unexpected error thrown when for index JSON")
+
+	testutil.AssertEquals(t, wrappedIndex,
+		`{"ddoc":"indexOwnerDoc","index":{"fields":["chaincodeid","data.owner",{"data.size":"desc"},{"chaincodeid":"desc"}]},"name":"indexOwner","type":"json"}`)
+
+	//invalid json
+	_, err = ApplyIndexWrapper(`{"index":{"fields":["owner"]`)
+	testutil.AssertError(t, err, "Expected error for an invalid index JSON")
+
+	//missing index object
+	_, err = ApplyIndexWrapper(`{"name":"indexOwner"}`)
+	testutil.AssertError(t, err, "Expected error for a missing index object")
+
+	//missing fields
+	_, err = ApplyIndexWrapper(`{"index":{"fields":[]},"name":"indexOwner"}`)
+	testutil.AssertError(t, err, "Expected error for an empty fields array")
+
+	//invalid field
+	_, err = ApplyIndexWrapper(`{"index":{"fields":[10]},"name":"indexOwner"}`)
+	testutil.AssertError(t, err, "Expected error for an invalid field")
+}
@@ -0,0 +1,162 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statecouchdb
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+)
+
+// cachedState is the committed state of a key. The version is nil for a key that does not exist,
+// and the value is nil when only the version of an existing key was loaded
+type cachedState struct {
+	version *version.Height
+	value   []byte
+}
+
+// versionedValue returns the state as returned by GetState, or false when the value is not cached
+func (state *cachedState) versionedValue() (*statedb.VersionedValue, bool) {
+	if state.version == nil {
+		return nil, true
+	}
+	if state.value == nil {
+		return nil, false
+	}
+	return &statedb.VersionedValue{Value: state.value, Version: state.version}, true
+}
+
+type cacheEntry struct {
+	key   statedb.CompositeKey
+	state *cachedState
+}
+
+// stateCache is a LRU cache of the committed state of the keys of a channel. The entries are
+// replaced by ApplyUpdates with the committed writes. The entries read from CouchDB are only
+// added when no update was applied since the read started, so that a concurrent commit never
+// gets overwritten by the state it replaced
+type stateCache struct {
+	capacity int
+	entries  map[statedb.CompositeKey]*list.Element
+	lru      *list.List
+	// updateSeq is incremented by every update
+	updateSeq uint64
+	mux       sync.Mutex
+}
+
+// newStateCache returns a cache of the given number of keys, which is disabled when the capacity is 0
+func newStateCache(capacity int) *stateCache {
+	return &stateCache{capacity: capacity, entries: make(map[statedb.CompositeKey]*list.Element), lru: list.New()}
+}
+
+func (c *stateCache) enabled() bool {
+	return c.capacity > 0
+}
+
+// get returns the cached state of the key, if any
+func (c *stateCache) get(namespace, key string) (*cachedState, bool) {
+	if !c.enabled() {
+		return nil, false
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	element, ok := c.entries[statedb.CompositeKey{Namespace: namespace, Key: key}]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return element.Value.(*cacheEntry).state, true
+}
+
+// readSeq returns the sequence number to pass to fill before reading keys from CouchDB
+func (c *stateCache) readSeq() uint64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.updateSeq
+}
+
+// fill adds the state read from CouchDB, unless an update was applied since readSeq returned seq.
+// The value of an entry that is already cached is kept if the state read carries only the version
+func (c *stateCache) fill(seq uint64, namespace, key string, state *cachedState) {
+	if !c.enabled() {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if seq != c.updateSeq {
+		return
+	}
+	compositeKey := statedb.CompositeKey{Namespace: namespace, Key: key}
+	if element, ok := c.entries[compositeKey]; ok {
+		cached := element.Value.(*cacheEntry).state
+		if state.value == nil && version.AreSame(cached.version, state.version) {
+			return
+		}
+	}
+	c.set(compositeKey, state)
+}
+
+// update sets the committed state of the key, a nil version recording its deletion
+func (c *stateCache) update(namespace, key string, state *cachedState) {
+	if !c.enabled() {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.updateSeq++
+	c.set(statedb.CompositeKey{Namespace: namespace, Key: key}, state)
+}
+
+// remove evicts the key, whose committed state is unknown
+func (c *stateCache) remove(namespace, key string) {
+	if !c.enabled() {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.updateSeq++
+	compositeKey := statedb.CompositeKey{Namespace: namespace, Key: key}
+	if element, ok := c.entries[compositeKey]; ok {
+		c.lru.Remove(element)
+		delete(c.entries, compositeKey)
+	}
+}
+
+// clear evicts all the keys
+func (c *stateCache) clear() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.updateSeq++
+	c.entries = make(map[statedb.CompositeKey]*list.Element)
+	c.lru.Init()
+}
+
+func (c *stateCache) set(compositeKey statedb.CompositeKey, state *cachedState) {
+	if element, ok := c.entries[compositeKey]; ok {
+		element.Value.(*cacheEntry).state = state
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[compositeKey] = c.lru.PushFront(&cacheEntry{compositeKey, state})
+	for c.lru.Len() > c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
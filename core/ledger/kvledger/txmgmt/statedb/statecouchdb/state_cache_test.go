@@ -0,0 +1,102 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statecouchdb
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateCacheLRU(t *testing.T) {
+	cache := newStateCache(2)
+	cache.update("ns1", "key1", &cachedState{version.NewHeight(1, 1), []byte("value1")})
+	cache.update("ns1", "key2", &cachedState{version.NewHeight(1, 2), []byte("value2")})
+	// key1 becomes the most recently used, so key2 gets evicted
+	_, ok := cache.get("ns1", "key1")
+	assert.True(t, ok)
+	cache.update("ns1", "key3", &cachedState{})
+	_, ok = cache.get("ns1", "key2")
+	assert.False(t, ok)
+
+	state, ok := cache.get("ns1", "key1")
+	assert.True(t, ok)
+	vv, ok := state.versionedValue()
+	assert.True(t, ok)
+	assert.Equal(t, &statedb.VersionedValue{Value: []byte("value1"), Version: version.NewHeight(1, 1)}, vv)
+
+	// a key that does not exist is cached as such
+	state, ok = cache.get("ns1", "key3")
+	assert.True(t, ok)
+	vv, ok = state.versionedValue()
+	assert.True(t, ok)
+	assert.Nil(t, vv)
+
+	cache.remove("ns1", "key3")
+	_, ok = cache.get("ns1", "key3")
+	assert.False(t, ok)
+	cache.clear()
+	_, ok = cache.get("ns1", "key1")
+	assert.False(t, ok)
+}
+
+func TestStateCacheFill(t *testing.T) {
+	cache := newStateCache(10)
+
+	// the state read before an update is not cached
+	seq := cache.readSeq()
+	cache.update("ns1", "key1", &cachedState{version: version.NewHeight(2, 1)})
+	cache.fill(seq, "ns1", "key1", &cachedState{version.NewHeight(1, 1), []byte("old")})
+	state, _ := cache.get("ns1", "key1")
+	assert.Equal(t, version.NewHeight(2, 1), state.version)
+	_, ok := state.versionedValue()
+	assert.False(t, ok, "only the version of the key is known")
+
+	// the value read afterwards completes the entry
+	cache.fill(cache.readSeq(), "ns1", "key1", &cachedState{version.NewHeight(2, 1), []byte("new")})
+	state, _ = cache.get("ns1", "key1")
+	vv, ok := state.versionedValue()
+	assert.True(t, ok)
+	assert.Equal(t, []byte("new"), vv.Value)
+
+	// loading the version of a cached key keeps its value
+	cache.fill(cache.readSeq(), "ns1", "key1", &cachedState{version: version.NewHeight(2, 1)})
+	state, _ = cache.get("ns1", "key1")
+	assert.Equal(t, []byte("new"), state.value)
+}
+
+func TestStateCacheDisabled(t *testing.T) {
+	cache := newStateCache(0)
+	cache.update("ns1", "key1", &cachedState{version.NewHeight(1, 1), []byte("value1")})
+	cache.fill(cache.readSeq(), "ns1", "key2", &cachedState{})
+	_, ok := cache.get("ns1", "key1")
+	assert.False(t, ok)
+	_, ok = cache.get("ns1", "key2")
+	assert.False(t, ok)
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := parseVersion("12:3")
+	assert.NoError(t, err)
+	assert.Equal(t, version.NewHeight(12, 3), v)
+	for _, invalid := range []string{"", "12", "a:3", "12:b", "1:2:3"} {
+		_, err := parseVersion(invalid)
+		assert.Error(t, err, "version [%s] should have been rejected", invalid)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -40,10 +41,23 @@ var lastKeyIndicator = byte(0x01)
 
 var binaryWrapper = "valueBytes"
 
+//couchdbIndexesDir is where the CouchDB index definitions are found in the
+//statedb artifacts of a chaincode package
+const couchdbIndexesDir = "couchdb/indexes/"
+
 //querySkip is implemented for future use by query paging
 //currently defaulted to 0 and is not used
 var querySkip = 0
 
+// StateDatabaseName is the value of ledger.state.stateDatabase that selects this provider
+const StateDatabaseName = "CouchDB"
+
+func init() {
+	statedb.RegisterVersionedDBProvider(StateDatabaseName, func() (statedb.VersionedDBProvider, error) {
+		return NewVersionedDBProvider()
+	})
+}
+
 // VersionedDBProvider implements interface VersionedDBProvider
 type VersionedDBProvider struct {
 	couchInstance *couchdb.CouchInstance
@@ -82,6 +96,27 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	return vdb, nil
 }
 
+// Drop drops the CouchDB database backing a named database
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	provider.mux.Lock()
+	defer provider.mux.Unlock()
+
+	logger.Infof("Dropping state database [%s]", dbName)
+	vdb := provider.databases[dbName]
+	if vdb == nil {
+		var err error
+		if vdb, err = newVersionedDB(provider.couchInstance, dbName); err != nil {
+			return err
+		}
+	}
+	vdb.cache.clear()
+	if _, err := vdb.db.DropDatabase(); err != nil {
+		return err
+	}
+	delete(provider.databases, dbName)
+	return nil
+}
+
 // Close closes the underlying db instance
 func (provider *VersionedDBProvider) Close() {
 	// No close needed on Couch
@@ -91,6 +126,7 @@ func (provider *VersionedDBProvider) Close() {
 type VersionedDB struct {
 	db     *couchdb.CouchDatabase
 	dbName string
+	cache  *stateCache
 }
 
 // newVersionedDB constructs an instance of VersionedDB
@@ -100,7 +136,7 @@ func newVersionedDB(couchInstance *couchdb.CouchInstance, dbName string) (*Versi
 	if err != nil {
 		return nil, err
 	}
-	return &VersionedDB{db, dbName}, nil
+	return &VersionedDB{db, dbName, newStateCache(ledgerconfig.GetStateCacheSize())}, nil
 }
 
 // Open implements method in VersionedDB interface
@@ -114,6 +150,31 @@ func (vdb *VersionedDB) Close() {
 	// no need to close db since a shared couch instance is used
 }
 
+// ProcessIndexesForChaincodeDeploy implements method in IndexCapable interface.
+// The index definitions found under couchdb/indexes are wrapped the same way queries
+// are and created in the channel database, an index that already exists is left as is
+func (vdb *VersionedDB) ProcessIndexesForChaincodeDeploy(namespace string, dbArtifacts map[string][]byte) error {
+	var indexFiles []string
+	for path := range dbArtifacts {
+		if strings.HasPrefix(path, couchdbIndexesDir) && strings.HasSuffix(path, ".json") {
+			indexFiles = append(indexFiles, path)
+		}
+	}
+	sort.Strings(indexFiles)
+
+	for _, indexFile := range indexFiles {
+		indexDefinition, err := ApplyIndexWrapper(string(dbArtifacts[indexFile]))
+		if err != nil {
+			return fmt.Errorf("Invalid index definition [%s] for chaincode [%s]: %s", indexFile, namespace, err)
+		}
+		if _, err := vdb.db.CreateIndex(indexDefinition); err != nil {
+			return fmt.Errorf("Error creating index [%s] for chaincode [%s] on channel [%s]: %s", indexFile, namespace, vdb.dbName, err)
+		}
+		logger.Infof("Channel [%s]: index [%s] of chaincode [%s] is available", vdb.dbName, indexFile, namespace)
+	}
+	return nil
+}
+
 // ValidateKey implements method in VersionedDB interface
 func (vdb *VersionedDB) ValidateKey(key string) error {
 	if !utf8.ValidString(key) {
@@ -126,22 +187,92 @@ func (vdb *VersionedDB) ValidateKey(key string) error {
 func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
 	logger.Debugf("GetState(). ns=%s, key=%s", namespace, key)
 
+	if cached, ok := vdb.cache.get(namespace, key); ok {
+		if versionedValue, ok := cached.versionedValue(); ok {
+			return versionedValue, nil
+		}
+	}
+
 	compositeKey := constructCompositeKey(namespace, key)
 
+	seq := vdb.cache.readSeq()
 	couchDoc, _, err := vdb.db.ReadDoc(string(compositeKey))
 	if err != nil {
 		return nil, err
 	}
 	if couchDoc == nil {
+		vdb.cache.fill(seq, namespace, key, &cachedState{})
 		return nil, nil
 	}
 
 	//remove the data wrapper and return the value and version
 	returnValue, returnVersion := removeDataWrapper(couchDoc.JSONValue, couchDoc.Attachments)
+	vdb.cache.fill(seq, namespace, key, &cachedState{&returnVersion, returnValue})
 
 	return &statedb.VersionedValue{Value: returnValue, Version: &returnVersion}, nil
 }
 
+// LoadCommittedVersions implements method in BulkOptimizable interface.
+// The versions of the keys that are not cached are retrieved in a single _all_docs request
+func (vdb *VersionedDB) LoadCommittedVersions(keys []*statedb.CompositeKey) error {
+	if !vdb.cache.enabled() {
+		return nil
+	}
+	missingKeys := make(map[string]*statedb.CompositeKey)
+	var ids []string
+	for _, key := range keys {
+		if _, ok := vdb.cache.get(key.Namespace, key.Key); ok {
+			continue
+		}
+		id := string(constructCompositeKey(key.Namespace, key.Key))
+		if _, ok := missingKeys[id]; !ok {
+			missingKeys[id] = key
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	seq := vdb.cache.readSeq()
+	docMetadata, err := vdb.db.BatchRetrieveIDRevision(ids)
+	if err != nil {
+		return err
+	}
+	for _, doc := range docMetadata {
+		key, ok := missingKeys[doc.ID]
+		if !ok {
+			continue
+		}
+		delete(missingKeys, doc.ID)
+		// a deleted document has no version
+		if doc.Version == "" {
+			vdb.cache.fill(seq, key.Namespace, key.Key, &cachedState{})
+			continue
+		}
+		committedVersion, err := parseVersion(doc.Version)
+		if err != nil {
+			return err
+		}
+		vdb.cache.fill(seq, key.Namespace, key.Key, &cachedState{version: committedVersion})
+	}
+	// the documents that do not exist are not part of the response
+	for _, key := range missingKeys {
+		vdb.cache.fill(seq, key.Namespace, key.Key, &cachedState{})
+	}
+	logger.Debugf("Channel [%s]: loaded the committed versions of [%d] keys", vdb.dbName, len(ids))
+	return nil
+}
+
+// GetCachedVersion implements method in BulkOptimizable interface
+func (vdb *VersionedDB) GetCachedVersion(namespace, key string) (*version.Height, bool) {
+	cached, ok := vdb.cache.get(namespace, key)
+	if !ok {
+		return nil, false
+	}
+	return cached.version, true
+}
+
 func removeDataWrapper(wrappedValue []byte, attachments []*couchdb.Attachment) ([]byte, version.Height) {
 
 	//initialize the return value
@@ -175,20 +306,34 @@ func removeDataWrapper(wrappedValue []byte, attachments []*couchdb.Attachment) (
 
 	}
 
+	//create the version based on the blockNum and txNum
+	returnVersion, _ = parseVersion(fmt.Sprintf("%s", jsonResult["version"]))
+
+	return returnValue, *returnVersion
+
+}
+
+// parseVersion parses the version of a document, which is stored as "blockNum:txNum"
+func parseVersion(versionString string) (*version.Height, error) {
 	//create an array containing the blockNum and txNum
-	versionArray := strings.Split(fmt.Sprintf("%s", jsonResult["version"]), ":")
+	versionArray := strings.Split(versionString, ":")
+	if len(versionArray) != 2 {
+		return version.NewHeight(0, 0), fmt.Errorf("Invalid document version [%s]", versionString)
+	}
 
 	//convert the blockNum from String to unsigned int
-	blockNum, _ := strconv.ParseUint(versionArray[0], 10, 64)
+	blockNum, err := strconv.ParseUint(versionArray[0], 10, 64)
+	if err != nil {
+		return version.NewHeight(0, 0), fmt.Errorf("Invalid document version [%s]: %s", versionString, err)
+	}
 
 	//convert the txNum from String to unsigned int
-	txNum, _ := strconv.ParseUint(versionArray[1], 10, 64)
-
-	//create the version based on the blockNum and txNum
-	returnVersion = version.NewHeight(blockNum, txNum)
-
-	return returnValue, *returnVersion
+	txNum, err := strconv.ParseUint(versionArray[1], 10, 64)
+	if err != nil {
+		return version.NewHeight(0, 0), fmt.Errorf("Invalid document version [%s]: %s", versionString, err)
+	}
 
+	return version.NewHeight(blockNum, txNum), nil
 }
 
 // GetStateMultipleKeys implements method in VersionedDB interface
@@ -263,7 +408,11 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 			//convert nils to deletes
 			if vv.Value == nil {
 
-				vdb.db.DeleteDoc(string(compositeKey), "")
+				if err := vdb.db.DeleteDoc(string(compositeKey), ""); err != nil {
+					vdb.cache.remove(ns, k)
+				} else {
+					vdb.cache.update(ns, k, &cachedState{})
+				}
 
 			} else {
 				couchDoc := &couchdb.CouchDoc{}
@@ -288,9 +437,17 @@ func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version
 				// SaveDoc using couchdb client and use attachment to persist the binary data
 				rev, err := vdb.db.SaveDoc(string(compositeKey), "", couchDoc)
 				if err != nil {
+					vdb.cache.remove(ns, k)
 					logger.Errorf("Error during Commit(): %s\n", err.Error())
 					return err
 				}
+				// CouchDB may not return a json value byte for byte as it was written,
+				// so only the version is cached, as opposed to binary values
+				if couchDoc.Attachments != nil {
+					vdb.cache.update(ns, k, &cachedState{vv.Version, vv.Value})
+				} else {
+					vdb.cache.update(ns, k, &cachedState{version: vv.Version})
+				}
 				if rev != "" {
 					logger.Debugf("Saved document revision number: %s\n", rev)
 				}
@@ -27,6 +27,8 @@ import (
 type VersionedDBProvider interface {
 	// GetDBHandle returns a handle to a VersionedDB
 	GetDBHandle(id string) (VersionedDB, error)
+	// Drop deletes all the data of the VersionedDB with the given id. The db must not be in use
+	Drop(id string) error
 	// Close closes all the VersionedDB instances and releases any resources held by VersionedDBProvider
 	Close()
 }
@@ -60,6 +62,26 @@ type VersionedDB interface {
 	Close()
 }
 
+// IndexCapable is implemented by the VersionedDBs that can create the indexes
+// defined in the META-INF/statedb tree of a chaincode package
+type IndexCapable interface {
+	// ProcessIndexesForChaincodeDeploy creates the indexes defined by the package artifacts for the namespace.
+	// dbArtifacts is keyed by the path of the artifact relative to META-INF/statedb, for instance
+	// "couchdb/indexes/indexOwner.json". Artifacts meant for other db implementations are ignored
+	ProcessIndexesForChaincodeDeploy(namespace string, dbArtifacts map[string][]byte) error
+}
+
+// BulkOptimizable is implemented by the VersionedDBs for which reading the keys one at a time is expensive.
+// The validator loads the committed versions of all the keys read by the transactions of a block in bulk,
+// before validating them one by one against the cached versions
+type BulkOptimizable interface {
+	// LoadCommittedVersions loads the committed versions of the keys in the cache of the db
+	LoadCommittedVersions(keys []*CompositeKey) error
+	// GetCachedVersion returns the committed version of the key, which is nil for a key that does not exist.
+	// The boolean is false if the version of the key is not in the cache
+	GetCachedVersion(namespace, key string) (*version.Height, bool)
+}
+
 // CompositeKey encloses Namespace and Key components
 type CompositeKey struct {
 	Namespace string
@@ -34,6 +34,18 @@ var compositeKeySep = []byte{0x00}
 var lastKeyIndicator = byte(0x01)
 var savePointKey = []byte{0x00}
 
+// dropBatchSize is the number of keys deleted in one leveldb batch when dropping a db
+const dropBatchSize = 1000
+
+// StateDatabaseName is the value of ledger.state.stateDatabase that selects this provider
+const StateDatabaseName = "goleveldb"
+
+func init() {
+	statedb.RegisterVersionedDBProvider(StateDatabaseName, func() (statedb.VersionedDBProvider, error) {
+		return NewVersionedDBProvider(), nil
+	})
+}
+
 // VersionedDBProvider implements interface VersionedDBProvider
 type VersionedDBProvider struct {
 	dbProvider *leveldbhelper.Provider
@@ -52,6 +64,12 @@ func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.Version
 	return newVersionedDB(provider.dbProvider.GetDBHandle(dbName), dbName), nil
 }
 
+// Drop deletes all the keys of a named database
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	logger.Infof("Dropping state database [%s]", dbName)
+	return provider.dbProvider.GetDBHandle(dbName).DeleteAll(dropBatchSize)
+}
+
 // Close closes the underlying db
 func (provider *VersionedDBProvider) Close() {
 	provider.dbProvider.Close()
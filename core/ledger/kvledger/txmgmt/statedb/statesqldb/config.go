@@ -0,0 +1,39 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesqldb
+
+import (
+	"github.com/spf13/viper"
+)
+
+// SQLDBDef contains parameters
+type SQLDBDef struct {
+	// DriverName is the name of the database/sql driver, which selects the SQL dialect as well
+	DriverName string
+	// DataSourceName is the driver specific connection string
+	DataSourceName string
+	// MaxOpenConnections is the maximum number of open connections to the database, unlimited if not positive
+	MaxOpenConnections int
+}
+
+// GetSQLDBDefinition exposes the sqlDBConfig variables
+func GetSQLDBDefinition() *SQLDBDef {
+	driverName := viper.GetString("ledger.state.sqlDBConfig.driverName")
+	dataSourceName := viper.GetString("ledger.state.sqlDBConfig.dataSourceName")
+	maxOpenConnections := viper.GetInt("ledger.state.sqlDBConfig.maxOpenConnections")
+	return &SQLDBDef{driverName, dataSourceName, maxOpenConnections}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// dialect captures the differences between the SQL databases. The statements are written
+// with '?' placeholders, which are rebound to the placeholders of the database
+type dialect interface {
+	// schema returns the statements creating the tables, if they do not exist
+	schema() []string
+	// jsonParam returns the placeholder of the json column value in the upsert statement
+	jsonParam() string
+	// jsonField returns the expression extracting the field at the given path from the json column
+	jsonField(path []string) (string, []interface{}, error)
+	// jsonType returns the expression of the json type of the field at the given path, which
+	// is 'null' for a json null and NULL when the field does not exist
+	jsonType(path []string) (string, []interface{}, error)
+	// jsonValue returns the expression of a value to compare with a field of the json column
+	jsonValue(value interface{}) (string, interface{}, error)
+	// rebind replaces the '?' placeholders of the statement by the ones of the database
+	rebind(statement string) string
+}
+
+func newDialect(driverName string) (dialect, error) {
+	switch driverName {
+	case "sqlite3":
+		return &sqliteDialect{}, nil
+	case "postgres", "pgx":
+		return &postgresDialect{}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported SQL driver [%s], the supported ones are sqlite3 and postgres", driverName)
+	}
+}
+
+// sqliteDialect is the dialect of SQLite, with the json1 extension
+type sqliteDialect struct {
+}
+
+func (d *sqliteDialect) schema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS fabric_state (ledger_id TEXT NOT NULL, namespace TEXT NOT NULL, key BLOB NOT NULL,
+			value BLOB, json_value TEXT, block_num INTEGER NOT NULL, tx_num INTEGER NOT NULL,
+			PRIMARY KEY (ledger_id, namespace, key))`,
+		`CREATE TABLE IF NOT EXISTS fabric_savepoint (ledger_id TEXT NOT NULL PRIMARY KEY,
+			block_num INTEGER NOT NULL, tx_num INTEGER NOT NULL)`,
+	}
+}
+
+func (d *sqliteDialect) jsonParam() string {
+	return "?"
+}
+
+func (d *sqliteDialect) jsonField(path []string) (string, []interface{}, error) {
+	jsonPath, err := sqliteJSONPath(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return "json_extract(json_value, ?)", []interface{}{jsonPath}, nil
+}
+
+func (d *sqliteDialect) jsonType(path []string) (string, []interface{}, error) {
+	jsonPath, err := sqliteJSONPath(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return "json_type(json_value, ?)", []interface{}{jsonPath}, nil
+}
+
+// jsonValue binds scalars as such, since json_extract returns the SQL value of a json scalar,
+// and arrays and objects as minified json, which json_extract returns for them
+func (d *sqliteDialect) jsonValue(value interface{}) (string, interface{}, error) {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		jsonBytes, err := json.Marshal(value)
+		if err != nil {
+			return "", nil, err
+		}
+		return "json(?)", string(jsonBytes), nil
+	default:
+		return "?", value, nil
+	}
+}
+
+func (d *sqliteDialect) rebind(statement string) string {
+	return statement
+}
+
+// sqliteJSONPath returns the json path of the field, each element being quoted
+func sqliteJSONPath(path []string) (string, error) {
+	jsonPath := "$"
+	for _, element := range path {
+		if strings.ContainsAny(element, `"\`) {
+			return "", fmt.Errorf("Field name [%s] is not supported by the SQL state database", strings.Join(path, "."))
+		}
+		jsonPath += `."` + element + `"`
+	}
+	return jsonPath, nil
+}
+
+// postgresDialect is the dialect of PostgreSQL and of the databases compatible with its wire protocol
+type postgresDialect struct {
+}
+
+func (d *postgresDialect) schema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS fabric_state (ledger_id TEXT NOT NULL, namespace TEXT NOT NULL, key BYTEA NOT NULL,
+			value BYTEA, json_value JSONB, block_num BIGINT NOT NULL, tx_num BIGINT NOT NULL,
+			PRIMARY KEY (ledger_id, namespace, key))`,
+		`CREATE TABLE IF NOT EXISTS fabric_savepoint (ledger_id TEXT NOT NULL PRIMARY KEY,
+			block_num BIGINT NOT NULL, tx_num BIGINT NOT NULL)`,
+	}
+}
+
+func (d *postgresDialect) jsonParam() string {
+	return "CAST(? AS JSONB)"
+}
+
+func (d *postgresDialect) jsonField(path []string) (string, []interface{}, error) {
+	args := make([]interface{}, len(path))
+	for i, element := range path {
+		args[i] = element
+	}
+	return "jsonb_extract_path(json_value" + strings.Repeat(", CAST(? AS TEXT)", len(path)) + ")", args, nil
+}
+
+func (d *postgresDialect) jsonType(path []string) (string, []interface{}, error) {
+	field, args, err := d.jsonField(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return "jsonb_typeof(" + field + ")", args, nil
+}
+
+// jsonValue binds all the values as jsonb, which compares values of the same json type naturally
+func (d *postgresDialect) jsonValue(value interface{}) (string, interface{}, error) {
+	jsonBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return "CAST(? AS JSONB)", string(jsonBytes), nil
+}
+
+func (d *postgresDialect) rebind(statement string) string {
+	parts := strings.Split(statement, "?")
+	rebound := parts[0]
+	for i, part := range parts[1:] {
+		rebound += "$" + strconv.Itoa(i+1) + part
+	}
+	return rebound
+}
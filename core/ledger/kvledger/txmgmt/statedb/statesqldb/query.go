@@ -0,0 +1,393 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const jsonQuerySelector = "selector"
+const jsonQueryFields = "fields"
+const jsonQuerySort = "sort"
+const jsonQueryLimit = "limit"
+const jsonQuerySkip = "skip"
+const jsonQueryUseIndex = "use_index"
+
+var comparisonOperators = map[string]string{
+	"$eq": "=", "$ne": "<>", "$gt": ">", "$gte": ">=", "$lt": "<", "$lte": "<=",
+}
+
+// sqlQuery is a rich query translated to the clauses of a SQL statement
+type sqlQuery struct {
+	where   string
+	orderBy string
+	args    []interface{}
+	limit   int
+	skip    int
+	// fields are the paths of the fields the results are projected on, none for the whole values
+	fields [][]string
+}
+
+/*
+translateQuery translates a rich query, written for CouchDB, into the clauses of a SQL
+statement that evaluates it over the json column of the state table
+
+The selector supports the fields, nested or in dot notation, the operators $eq, $ne, $gt,
+$gte, $lt, $lte, $in, $nin and $exists, and the combinations $and, $or, $nor and $not.
+The fields, sort, limit and skip keys are supported as well, the limit being capped at
+queryLimit, while use_index is ignored
+
+Example:
+
+Source Query:
+{"selector":{"owner":"tom","size":{"$gt":5}},"sort":[{"size":"desc"}],"limit":10}
+
+Result for SQLite:
+WHERE (json_extract(json_value, ?) = ?) AND (json_extract(json_value, ?) > ?)
+ORDER BY json_extract(json_value, ?) DESC
+args: `$."owner"`, "tom", `$."size"`, 5, `$."size"`
+
+*/
+func translateQuery(d dialect, queryString string, queryLimit int) (*sqlQuery, error) {
+	jsonQueryMap := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(queryString), &jsonQueryMap); err != nil {
+		return nil, err
+	}
+	query := &sqlQuery{limit: queryLimit}
+	var orderByArgs []interface{}
+	for key, jsonValue := range jsonQueryMap {
+		var err error
+		switch key {
+		case jsonQuerySelector:
+			selector, ok := jsonValue.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("The selector of the query must be a JSON object")
+			}
+			query.where, query.args, err = translateSelector(d, selector, nil)
+		case jsonQueryFields:
+			query.fields, err = parseFields(jsonValue)
+		case jsonQuerySort:
+			query.orderBy, orderByArgs, err = translateSort(d, jsonValue)
+		case jsonQueryLimit:
+			var limit int
+			if limit, err = parseCount(key, jsonValue); err == nil && limit < query.limit {
+				query.limit = limit
+			}
+		case jsonQuerySkip:
+			query.skip, err = parseCount(key, jsonValue)
+		case jsonQueryUseIndex:
+		default:
+			err = fmt.Errorf("Query key [%s] is not supported by the SQL state database", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	query.args = append(query.args, orderByArgs...)
+	return query, nil
+}
+
+// translateSelector translates the conditions of a selector over the fields under the given path
+func translateSelector(d dialect, selector map[string]interface{}, path []string) (string, []interface{}, error) {
+	var clauses []string
+	var args []interface{}
+	for _, key := range sortedKeys(selector) {
+		var clause string
+		var clauseArgs []interface{}
+		var err error
+		switch key {
+		case "$and", "$or", "$nor":
+			clause, clauseArgs, err = translateCombination(d, key, selector[key], path)
+		case "$not":
+			subSelector, ok := selector[key].(map[string]interface{})
+			if !ok {
+				return "", nil, fmt.Errorf("Operator $not expects a JSON object")
+			}
+			if clause, clauseArgs, err = translateSelector(d, subSelector, path); err == nil {
+				clause = "(" + clause + ") IS NOT TRUE"
+			}
+		default:
+			if strings.HasPrefix(key, "$") {
+				return "", nil, fmt.Errorf("Operator [%s] is not supported by the SQL state database", key)
+			}
+			clause, clauseArgs, err = translateField(d, append(append([]string{}, path...), strings.Split(key, ".")...), selector[key])
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "("+clause+")")
+		args = append(args, clauseArgs...)
+	}
+	if len(clauses) == 0 {
+		return "1 = 1", nil, nil
+	}
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+func translateCombination(d dialect, operator string, value interface{}, path []string) (string, []interface{}, error) {
+	subSelectors, ok := value.([]interface{})
+	if !ok || len(subSelectors) == 0 {
+		return "", nil, fmt.Errorf("Operator [%s] expects a non empty JSON array", operator)
+	}
+	var clauses []string
+	var args []interface{}
+	for _, subSelector := range subSelectors {
+		subSelectorMap, ok := subSelector.(map[string]interface{})
+		if !ok {
+			return "", nil, fmt.Errorf("Operator [%s] expects an array of JSON objects", operator)
+		}
+		clause, clauseArgs, err := translateSelector(d, subSelectorMap, path)
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, "("+clause+")")
+		args = append(args, clauseArgs...)
+	}
+	switch operator {
+	case "$and":
+		return strings.Join(clauses, " AND "), args, nil
+	case "$or":
+		return strings.Join(clauses, " OR "), args, nil
+	default:
+		return "(" + strings.Join(clauses, " OR ") + ") IS NOT TRUE", args, nil
+	}
+}
+
+// translateField translates the condition on the field at the given path, which is either
+// a value to be equal to, an object of operators or a selector over the nested fields
+func translateField(d dialect, path []string, condition interface{}) (string, []interface{}, error) {
+	conditionMap, ok := condition.(map[string]interface{})
+	if !ok || !isOperatorsObject(conditionMap) {
+		if ok {
+			return translateSelector(d, conditionMap, path)
+		}
+		return translateOperator(d, path, "$eq", condition)
+	}
+	var clauses []string
+	var args []interface{}
+	for _, operator := range sortedKeys(conditionMap) {
+		clause, clauseArgs, err := translateOperator(d, path, operator, conditionMap[operator])
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, clause)
+		args = append(args, clauseArgs...)
+	}
+	return strings.Join(clauses, " AND "), args, nil
+}
+
+func translateOperator(d dialect, path []string, operator string, value interface{}) (string, []interface{}, error) {
+	if operator == "$exists" {
+		exists, ok := value.(bool)
+		if !ok {
+			return "", nil, fmt.Errorf("Operator $exists expects a boolean")
+		}
+		jsonType, args, err := d.jsonType(path)
+		if err != nil {
+			return "", nil, err
+		}
+		if exists {
+			return jsonType + " IS NOT NULL", args, nil
+		}
+		return jsonType + " IS NULL", args, nil
+	}
+
+	if value == nil && (operator == "$eq" || operator == "$ne") {
+		jsonType, args, err := d.jsonType(path)
+		if err != nil {
+			return "", nil, err
+		}
+		return jsonType + " " + comparisonOperators[operator] + " 'null'", args, nil
+	}
+
+	field, args, err := d.jsonField(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if sqlOperator, ok := comparisonOperators[operator]; ok {
+		expression, arg, err := comparableValue(d, operator, value)
+		if err != nil {
+			return "", nil, err
+		}
+		return field + " " + sqlOperator + " " + expression, append(args, arg), nil
+	}
+	if operator != "$in" && operator != "$nin" {
+		return "", nil, fmt.Errorf("Operator [%s] is not supported by the SQL state database", operator)
+	}
+	values, ok := value.([]interface{})
+	if !ok || len(values) == 0 {
+		return "", nil, fmt.Errorf("Operator [%s] expects a non empty JSON array", operator)
+	}
+	expressions := make([]string, len(values))
+	for i, v := range values {
+		expression, arg, err := comparableValue(d, operator, v)
+		if err != nil {
+			return "", nil, err
+		}
+		expressions[i] = expression
+		args = append(args, arg)
+	}
+	sqlOperator := " IN "
+	if operator == "$nin" {
+		sqlOperator = " NOT IN "
+	}
+	return field + sqlOperator + "(" + strings.Join(expressions, ", ") + ")", args, nil
+}
+
+func comparableValue(d dialect, operator string, value interface{}) (string, interface{}, error) {
+	if value == nil {
+		return "", nil, fmt.Errorf("Operator [%s] does not support null values", operator)
+	}
+	return d.jsonValue(value)
+}
+
+// translateSort translates the sort key, a JSON array of fields or of {"field": "asc|desc"} objects
+func translateSort(d dialect, value interface{}) (string, []interface{}, error) {
+	sortFields, ok := value.([]interface{})
+	if !ok {
+		return "", nil, fmt.Errorf("The sort of the query must be a JSON array")
+	}
+	var clauses []string
+	var args []interface{}
+	for _, sortField := range sortFields {
+		fieldName, direction := "", "ASC"
+		switch s := sortField.(type) {
+		case string:
+			fieldName = s
+		case map[string]interface{}:
+			if len(s) != 1 {
+				return "", nil, fmt.Errorf("Each sort object must have a single field")
+			}
+			for name, dir := range s {
+				fieldName = name
+				switch dir {
+				case "asc":
+				case "desc":
+					direction = "DESC"
+				default:
+					return "", nil, fmt.Errorf("Sort direction of field [%s] must be asc or desc", name)
+				}
+			}
+		default:
+			return "", nil, fmt.Errorf("The sort of the query must be an array of field names or objects")
+		}
+		field, fieldArgs, err := d.jsonField(strings.Split(fieldName, "."))
+		if err != nil {
+			return "", nil, err
+		}
+		clauses = append(clauses, field+" "+direction)
+		args = append(args, fieldArgs...)
+	}
+	return strings.Join(clauses, ", "), args, nil
+}
+
+func parseFields(value interface{}) ([][]string, error) {
+	fieldNames, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("The fields of the query must be a JSON array")
+	}
+	var fields [][]string
+	for _, fieldName := range fieldNames {
+		name, ok := fieldName.(string)
+		if !ok {
+			return nil, fmt.Errorf("The fields of the query must be strings")
+		}
+		fields = append(fields, strings.Split(name, "."))
+	}
+	return fields, nil
+}
+
+func parseCount(key string, value interface{}) (int, error) {
+	count, ok := value.(float64)
+	if !ok || count < 0 || count != float64(int(count)) {
+		return 0, fmt.Errorf("Query key [%s] must be a non negative integer", key)
+	}
+	return int(count), nil
+}
+
+// projectFields returns the json value restricted to the given fields. A value that is not a
+// json object is returned as is
+func projectFields(value []byte, fields [][]string) []byte {
+	document := make(map[string]interface{})
+	if err := json.Unmarshal(value, &document); err != nil {
+		return value
+	}
+	projection := make(map[string]interface{})
+	for _, field := range fields {
+		fieldValue, found := getField(document, field)
+		if !found {
+			continue
+		}
+		parent := projection
+		for _, element := range field[:len(field)-1] {
+			child, ok := parent[element].(map[string]interface{})
+			if !ok {
+				child = make(map[string]interface{})
+				parent[element] = child
+			}
+			parent = child
+		}
+		parent[field[len(field)-1]] = fieldValue
+	}
+	projectedValue, _ := json.Marshal(projection)
+	return projectedValue
+}
+
+func getField(document map[string]interface{}, field []string) (interface{}, bool) {
+	var value interface{} = document
+	for _, element := range field {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[element]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// isOperatorsObject tells whether all the keys of the object are operators
+func isOperatorsObject(object map[string]interface{}) bool {
+	if len(object) == 0 {
+		return false
+	}
+	for key := range object {
+		if !strings.HasPrefix(key, "$") || key == "$and" || key == "$or" || key == "$nor" || key == "$not" {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// limitClause returns the LIMIT and OFFSET clauses of the query
+func (q *sqlQuery) limitClause() string {
+	return " LIMIT " + strconv.Itoa(q.limit) + " OFFSET " + strconv.Itoa(q.skip)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateQuerySQLite(t *testing.T) {
+	query, err := translateQuery(&sqliteDialect{}, `{"selector":{"owner":"tom","size":{"$gt":5}},"sort":[{"size":"desc"}],"limit":10}`, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "(json_extract(json_value, ?) = ?) AND (json_extract(json_value, ?) > ?)", query.where)
+	assert.Equal(t, "json_extract(json_value, ?) DESC", query.orderBy)
+	assert.Equal(t, []interface{}{`$."owner"`, "tom", `$."size"`, float64(5), `$."size"`}, query.args)
+	assert.Equal(t, " LIMIT 10 OFFSET 0", query.limitClause())
+
+	// the limit is capped and nested fields are supported in both notations
+	query, err = translateQuery(&sqliteDialect{}, `{"selector":{"a":{"b":1},"c.d":{"$in":[1,[2]]}},"limit":1000,"skip":5}`, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "((json_extract(json_value, ?) = ?)) AND (json_extract(json_value, ?) IN (?, json(?)))", query.where)
+	assert.Equal(t, []interface{}{`$."a"."b"`, float64(1), `$."c"."d"`, float64(1), "[2]"}, query.args)
+	assert.Equal(t, " LIMIT 100 OFFSET 5", query.limitClause())
+}
+
+func TestTranslateQueryCombinations(t *testing.T) {
+	d := &sqliteDialect{}
+	where, args, err := translateSelector(d, map[string]interface{}{
+		"$or":  []interface{}{map[string]interface{}{"a": "x"}, map[string]interface{}{"b": "y"}},
+		"$not": map[string]interface{}{"c": float64(6)},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "(((json_extract(json_value, ?) = ?)) IS NOT TRUE) AND "+
+		"(((json_extract(json_value, ?) = ?)) OR ((json_extract(json_value, ?) = ?)))", where)
+	assert.Equal(t, []interface{}{`$."c"`, float64(6), `$."a"`, "x", `$."b"`, "y"}, args)
+
+	where, args, err = translateSelector(d, map[string]interface{}{
+		"a": map[string]interface{}{"$exists": false},
+		"b": map[string]interface{}{"$ne": nil},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "(json_type(json_value, ?) IS NULL) AND (json_type(json_value, ?) <> 'null')", where)
+	assert.Equal(t, []interface{}{`$."a"`, `$."b"`}, args)
+
+	where, _, err = translateSelector(d, map[string]interface{}{}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "1 = 1", where)
+}
+
+func TestTranslateQueryPostgres(t *testing.T) {
+	d := &postgresDialect{}
+	query, err := translateQuery(d, `{"selector":{"owner":"tom","size":{"$gte":5}},"sort":["size"]}`, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, "(jsonb_extract_path(json_value, CAST(? AS TEXT)) = CAST(? AS JSONB)) AND "+
+		"(jsonb_extract_path(json_value, CAST(? AS TEXT)) >= CAST(? AS JSONB))", query.where)
+	assert.Equal(t, []interface{}{"owner", `"tom"`, "size", "5", "size"}, query.args)
+	assert.Equal(t, "jsonb_extract_path(json_value, CAST(? AS TEXT)) ASC", query.orderBy)
+	assert.Equal(t, "SELECT $1, $2 WHERE a = $3", d.rebind("SELECT ?, ? WHERE a = ?"))
+}
+
+func TestTranslateQueryErrors(t *testing.T) {
+	d := &sqliteDialect{}
+	for _, query := range []string{
+		"this is an invalid query string",
+		`{"selector":"owner"}`,
+		`{"selector":{"owner":{"$regex":"^t"}}}`,
+		`{"selector":{"$gt":1}}`,
+		`{"selector":{"$and":[]}}`,
+		`{"selector":{"$or":["a"]}}`,
+		`{"selector":{"$not":[]}}`,
+		`{"selector":{"a":{"$gt":null}}}`,
+		`{"selector":{"a":{"$in":[]}}}`,
+		`{"selector":{"a":{"$exists":1}}}`,
+		`{"selector":{"a\"b":1}}`,
+		`{"selector":{},"sort":[{"a":"up"}]}`,
+		`{"selector":{},"sort":"a"}`,
+		`{"selector":{},"limit":-1}`,
+		`{"selector":{},"skip":1.5}`,
+		`{"selector":{},"fields":"a"}`,
+		`{"selector":{},"bookmark":"x"}`,
+	} {
+		_, err := translateQuery(d, query, 100)
+		assert.Error(t, err, "query %s should have been rejected", query)
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	value := []byte(`{"owner":"tom","size":5,"a":{"b":1,"c":2}}`)
+	assert.JSONEq(t, `{"owner":"tom","a":{"b":1}}`, string(projectFields(value, [][]string{{"owner"}, {"a", "b"}, {"missing"}})))
+	assert.Equal(t, []byte("not json"), projectFields([]byte("not json"), [][]string{{"owner"}}))
+}
+
+func TestJSONValue(t *testing.T) {
+	assert.Equal(t, `{"a":1}`, jsonValue([]byte(" { \"a\" : 1 } ")))
+	assert.Nil(t, jsonValue([]byte("[1]")))
+	assert.Nil(t, jsonValue([]byte("{invalid")))
+	assert.Nil(t, jsonValue([]byte{}))
+}
+
+func TestNewDialect(t *testing.T) {
+	_, err := newDialect("sqlite3")
+	assert.NoError(t, err)
+	_, err = newDialect("postgres")
+	assert.NoError(t, err)
+	_, err = newDialect("mysql")
+	assert.Error(t, err)
+}
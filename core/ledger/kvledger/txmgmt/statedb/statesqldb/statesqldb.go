@@ -0,0 +1,341 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesqldb
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+)
+
+var logger = flogging.MustGetLogger("statesqldb")
+
+// StateDatabaseName is the value of ledger.state.stateDatabase that selects this provider
+const StateDatabaseName = "SQL"
+
+func init() {
+	statedb.RegisterVersionedDBProvider(StateDatabaseName, func() (statedb.VersionedDBProvider, error) {
+		return NewVersionedDBProvider()
+	})
+}
+
+// VersionedDBProvider implements interface VersionedDBProvider.
+// The state of all the ledgers is kept in a single table of a SQL database, accessed through
+// database/sql. The driver must be linked into the peer, which is done by building the peer with
+// the tag of the driver: 'sqlite' for github.com/mattn/go-sqlite3 or 'postgres' for github.com/lib/pq
+type VersionedDBProvider struct {
+	db      *sql.DB
+	dialect dialect
+}
+
+// NewVersionedDBProvider instantiates VersionedDBProvider
+func NewVersionedDBProvider() (*VersionedDBProvider, error) {
+	sqlDBDef := GetSQLDBDefinition()
+	logger.Debugf("constructing SQL VersionedDBProvider with driver [%s]", sqlDBDef.DriverName)
+	dialect, err := newDialect(sqlDBDef.DriverName)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(sqlDBDef.DriverName, sqlDBDef.DataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(sqlDBDef.MaxOpenConnections)
+	for _, statement := range dialect.schema() {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("Error creating the tables of the SQL state database: %s", err)
+		}
+	}
+	return &VersionedDBProvider{db, dialect}, nil
+}
+
+// GetDBHandle gets the handle to a named database
+func (provider *VersionedDBProvider) GetDBHandle(dbName string) (statedb.VersionedDB, error) {
+	return &VersionedDB{provider.db, provider.dialect, dbName}, nil
+}
+
+// Drop deletes the rows of a named database
+func (provider *VersionedDBProvider) Drop(dbName string) error {
+	logger.Infof("Dropping state database [%s]", dbName)
+	tx, err := provider.db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, table := range []string{"fabric_state", "fabric_savepoint"} {
+		if _, err := tx.Exec(provider.dialect.rebind("DELETE FROM "+table+" WHERE ledger_id = ?"), dbName); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Close closes the underlying db
+func (provider *VersionedDBProvider) Close() {
+	provider.db.Close()
+}
+
+// VersionedDB implements VersionedDB interface
+type VersionedDB struct {
+	db      *sql.DB
+	dialect dialect
+	dbName  string
+}
+
+// Open implements method in VersionedDB interface
+func (vdb *VersionedDB) Open() error {
+	// do nothing because shared db is used
+	return nil
+}
+
+// Close implements method in VersionedDB interface
+func (vdb *VersionedDB) Close() {
+	// do nothing because shared db is used
+}
+
+// ValidateKey implements method in VersionedDB interface
+func (vdb *VersionedDB) ValidateKey(key string) error {
+	return nil
+}
+
+// GetState implements method in VersionedDB interface
+func (vdb *VersionedDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	logger.Debugf("GetState(). ns=%s, key=%s", namespace, key)
+	row := vdb.db.QueryRow(vdb.dialect.rebind(
+		"SELECT value, block_num, tx_num FROM fabric_state WHERE ledger_id = ? AND namespace = ? AND key = ?"),
+		vdb.dbName, namespace, []byte(key))
+	var value []byte
+	var blockNum, txNum int64
+	if err := row.Scan(&value, &blockNum, &txNum); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return newVersionedValue(value, blockNum, txNum), nil
+}
+
+// maxKeysPerStatement bounds the number of keys GetStateMultipleKeys passes to a single statement,
+// so that along with the ledger and namespace the parameters stay below the limit of 999 of SQLite
+const maxKeysPerStatement = 900
+
+// GetStateMultipleKeys implements method in VersionedDB interface.
+// The values are retrieved with one statement per maxKeysPerStatement keys
+func (vdb *VersionedDB) GetStateMultipleKeys(namespace string, keys []string) ([]*statedb.VersionedValue, error) {
+	vals := make([]*statedb.VersionedValue, len(keys))
+	valsByKey := make(map[string]*statedb.VersionedValue)
+	for start := 0; start < len(keys); start += maxKeysPerStatement {
+		end := start + maxKeysPerStatement
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := vdb.getStateKeys(namespace, keys[start:end], valsByKey); err != nil {
+			return nil, err
+		}
+	}
+	for i, key := range keys {
+		vals[i] = valsByKey[key]
+	}
+	return vals, nil
+}
+
+// getStateKeys adds the values of the given keys that exist to valsByKey, with a single statement
+func (vdb *VersionedDB) getStateKeys(namespace string, keys []string, valsByKey map[string]*statedb.VersionedValue) error {
+	args := []interface{}{vdb.dbName, namespace}
+	for _, key := range keys {
+		args = append(args, []byte(key))
+	}
+	rows, err := vdb.db.Query(vdb.dialect.rebind(
+		"SELECT key, value, block_num, tx_num FROM fabric_state WHERE ledger_id = ? AND namespace = ? AND key IN (?"+
+			strings.Repeat(", ?", len(keys)-1)+")"), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var key, value []byte
+		var blockNum, txNum int64
+		if err := rows.Scan(&key, &value, &blockNum, &txNum); err != nil {
+			return err
+		}
+		valsByKey[string(key)] = newVersionedValue(value, blockNum, txNum)
+	}
+	return rows.Err()
+}
+
+// GetStateRangeScanIterator implements method in VersionedDB interface
+// startKey is inclusive
+// endKey is exclusive
+func (vdb *VersionedDB) GetStateRangeScanIterator(namespace string, startKey string, endKey string) (statedb.ResultsIterator, error) {
+	statement := "SELECT key, value, block_num, tx_num FROM fabric_state WHERE ledger_id = ? AND namespace = ? AND key >= ?"
+	args := []interface{}{vdb.dbName, namespace, []byte(startKey)}
+	if endKey != "" {
+		statement += " AND key < ?"
+		args = append(args, []byte(endKey))
+	}
+	rows, err := vdb.db.Query(vdb.dialect.rebind(statement+" ORDER BY key"), args...)
+	if err != nil {
+		return nil, err
+	}
+	return &kvScanner{namespace, rows, nil}, nil
+}
+
+// ExecuteQuery implements method in VersionedDB interface.
+// The query is written for CouchDB and translated into SQL, see translateQuery for what is supported
+func (vdb *VersionedDB) ExecuteQuery(namespace, query string) (statedb.ResultsIterator, error) {
+	sqlQuery, err := translateQuery(vdb.dialect, query, ledgerconfig.GetQueryLimit())
+	if err != nil {
+		return nil, err
+	}
+	statement := "SELECT key, value, block_num, tx_num FROM fabric_state WHERE ledger_id = ? AND namespace = ? AND json_value IS NOT NULL"
+	args := []interface{}{vdb.dbName, namespace}
+	if sqlQuery.where != "" {
+		statement += " AND (" + sqlQuery.where + ")"
+	}
+	if sqlQuery.orderBy != "" {
+		statement += " ORDER BY " + sqlQuery.orderBy + ", key"
+	} else {
+		statement += " ORDER BY key"
+	}
+	statement += sqlQuery.limitClause()
+	logger.Debugf("Rewritten query for SQL: %s", statement)
+	rows, err := vdb.db.Query(vdb.dialect.rebind(statement), append(args, sqlQuery.args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &kvScanner{namespace, rows, sqlQuery.fields}, nil
+}
+
+// ApplyUpdates implements method in VersionedDB interface.
+// The updates and the savepoint are written in a single transaction
+func (vdb *VersionedDB) ApplyUpdates(batch *statedb.UpdateBatch, height *version.Height) error {
+	tx, err := vdb.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := vdb.applyUpdates(tx, batch, height); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (vdb *VersionedDB) applyUpdates(tx *sql.Tx, batch *statedb.UpdateBatch, height *version.Height) error {
+	deleteStmt, err := tx.Prepare(vdb.dialect.rebind("DELETE FROM fabric_state WHERE ledger_id = ? AND namespace = ? AND key = ?"))
+	if err != nil {
+		return err
+	}
+	defer deleteStmt.Close()
+	upsertStmt, err := tx.Prepare(vdb.dialect.rebind(
+		"INSERT INTO fabric_state (ledger_id, namespace, key, value, json_value, block_num, tx_num) VALUES (?, ?, ?, ?, " +
+			vdb.dialect.jsonParam() + ", ?, ?) ON CONFLICT (ledger_id, namespace, key) DO UPDATE SET value = excluded.value, " +
+			"json_value = excluded.json_value, block_num = excluded.block_num, tx_num = excluded.tx_num"))
+	if err != nil {
+		return err
+	}
+	defer upsertStmt.Close()
+
+	for _, ns := range batch.GetUpdatedNamespaces() {
+		for k, vv := range batch.GetUpdates(ns) {
+			logger.Debugf("Channel [%s]: Applying key=[%s] in namespace [%s]", vdb.dbName, k, ns)
+			if vv.Value == nil {
+				_, err = deleteStmt.Exec(vdb.dbName, ns, []byte(k))
+			} else {
+				_, err = upsertStmt.Exec(vdb.dbName, ns, []byte(k), vv.Value, jsonValue(vv.Value),
+					int64(vv.Version.BlockNum), int64(vv.Version.TxNum))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	_, err = tx.Exec(vdb.dialect.rebind(
+		"INSERT INTO fabric_savepoint (ledger_id, block_num, tx_num) VALUES (?, ?, ?) ON CONFLICT (ledger_id) "+
+			"DO UPDATE SET block_num = excluded.block_num, tx_num = excluded.tx_num"),
+		vdb.dbName, int64(height.BlockNum), int64(height.TxNum))
+	return err
+}
+
+// GetLatestSavePoint implements method in VersionedDB interface
+func (vdb *VersionedDB) GetLatestSavePoint() (*version.Height, error) {
+	row := vdb.db.QueryRow(vdb.dialect.rebind("SELECT block_num, tx_num FROM fabric_savepoint WHERE ledger_id = ?"), vdb.dbName)
+	var blockNum, txNum int64
+	if err := row.Scan(&blockNum, &txNum); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return version.NewHeight(uint64(blockNum), uint64(txNum)), nil
+}
+
+// jsonValue returns the value to store in the json column, the compacted value if it is
+// a json object and nil otherwise, in which case the value is not subject to rich queries
+func jsonValue(value []byte) interface{} {
+	if !bytes.HasPrefix(bytes.TrimSpace(value), []byte("{")) {
+		return nil
+	}
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, value); err != nil {
+		return nil
+	}
+	return buf.String()
+}
+
+func newVersionedValue(value []byte, blockNum int64, txNum int64) *statedb.VersionedValue {
+	if value == nil {
+		// an empty value may be read as NULL
+		value = []byte{}
+	}
+	return &statedb.VersionedValue{Value: value, Version: version.NewHeight(uint64(blockNum), uint64(txNum))}
+}
+
+type kvScanner struct {
+	namespace string
+	rows      *sql.Rows
+	fields    [][]string
+}
+
+func (scanner *kvScanner) Next() (statedb.QueryResult, error) {
+	if !scanner.rows.Next() {
+		return nil, scanner.rows.Err()
+	}
+	var key, value []byte
+	var blockNum, txNum int64
+	if err := scanner.rows.Scan(&key, &value, &blockNum, &txNum); err != nil {
+		return nil, err
+	}
+	vv := newVersionedValue(value, blockNum, txNum)
+	if len(scanner.fields) > 0 {
+		vv.Value = projectFields(vv.Value, scanner.fields)
+	}
+	return &statedb.VersionedKV{
+		CompositeKey:   statedb.CompositeKey{Namespace: scanner.namespace, Key: string(key)},
+		VersionedValue: *vv}, nil
+}
+
+func (scanner *kvScanner) Close() {
+	scanner.rows.Close()
+}
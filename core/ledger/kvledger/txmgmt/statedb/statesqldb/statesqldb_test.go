@@ -0,0 +1,234 @@
+// +build sqlite
+
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statesqldb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/commontests"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// testDBPath is the directory of the SQLite database the tests run against
+const testDBPath = "/tmp/fabric/ledgertests/kvledger/txmgmt/statedb/statesqldb"
+
+func TestMain(m *testing.M) {
+	viper.Set("ledger.state.sqlDBConfig.driverName", "sqlite3")
+	viper.Set("ledger.state.sqlDBConfig.dataSourceName",
+		"file:"+filepath.Join(testDBPath, "state.db")+"?_busy_timeout=5000&_journal_mode=WAL")
+	viper.Set("ledger.state.couchDBConfig.queryLimit", 10)
+	os.Exit(m.Run())
+}
+
+// testVDBEnv provides a SQLite backed versioned db for testing
+type testVDBEnv struct {
+	t          testing.TB
+	DBProvider *VersionedDBProvider
+}
+
+func newTestVDBEnv(t testing.TB) *testVDBEnv {
+	os.RemoveAll(testDBPath)
+	if err := os.MkdirAll(testDBPath, 0755); err != nil {
+		t.Fatalf("Err: %s", err)
+	}
+	dbProvider, err := NewVersionedDBProvider()
+	if err != nil {
+		t.Fatalf("Err: %s", err)
+	}
+	return &testVDBEnv{t, dbProvider}
+}
+
+func (env *testVDBEnv) cleanup() {
+	env.DBProvider.Close()
+	os.RemoveAll(testDBPath)
+}
+
+func TestBasicRW(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	commontests.TestBasicRW(t, env.DBProvider)
+}
+
+func TestMultiDBBasicRW(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	commontests.TestMultiDBBasicRW(t, env.DBProvider)
+}
+
+func TestDeletes(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	commontests.TestDeletes(t, env.DBProvider)
+}
+
+func TestIterator(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	commontests.TestIterator(t, env.DBProvider)
+}
+
+func TestQuery(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	commontests.TestQuery(t, env.DBProvider)
+}
+
+func TestGetStateMultipleKeys(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	commontests.TestGetStateMultipleKeys(t, env.DBProvider)
+}
+
+func TestGetStateManyKeys(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	db, _ := env.DBProvider.GetDBHandle("testgetstatemanykeys")
+	// more keys than SQLite accepts parameters in a single statement
+	keys := make([]string, 2*maxKeysPerStatement+1)
+	batch := statedb.NewUpdateBatch()
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key%d", i)
+		if i%2 == 0 {
+			batch.Put("ns1", keys[i], []byte(keys[i]), version.NewHeight(1, uint64(i)))
+		}
+	}
+	assert.NoError(t, db.ApplyUpdates(batch, version.NewHeight(1, uint64(len(keys)))))
+
+	vals, err := db.GetStateMultipleKeys("ns1", keys)
+	assert.NoError(t, err)
+	assert.Len(t, vals, len(keys))
+	for i, vv := range vals {
+		if i%2 == 0 {
+			assert.Equal(t, &statedb.VersionedValue{Value: []byte(keys[i]), Version: version.NewHeight(1, uint64(i))}, vv)
+		} else {
+			assert.Nil(t, vv)
+		}
+	}
+}
+
+func TestQuerySortLimitAndFields(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	db, _ := env.DBProvider.GetDBHandle("testquerysort")
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte(`{"owner":"tom","size":3}`), version.NewHeight(1, 1))
+	batch.Put("ns1", "key2", []byte(`{"owner":"jerry","size":1}`), version.NewHeight(1, 2))
+	batch.Put("ns1", "key3", []byte(`{"owner":"fred","size":2,"extra":{"color":"red"}}`), version.NewHeight(1, 3))
+	batch.Put("ns1", "key4", []byte("not json"), version.NewHeight(1, 4))
+	assert.NoError(t, db.ApplyUpdates(batch, version.NewHeight(1, 4)))
+
+	itr, err := db.ExecuteQuery("ns1", `{"selector":{"size":{"$gte":1}},"sort":[{"size":"desc"}],"limit":2,"fields":["owner"]}`)
+	assert.NoError(t, err)
+	var keys, values []string
+	for {
+		result, err := itr.Next()
+		assert.NoError(t, err)
+		if result == nil {
+			break
+		}
+		keys = append(keys, result.(*statedb.VersionedKV).Key)
+		values = append(values, string(result.(*statedb.VersionedKV).Value))
+	}
+	itr.Close()
+	assert.Equal(t, []string{"key1", "key3"}, keys)
+	assert.Equal(t, []string{`{"owner":"tom"}`, `{"owner":"fred"}`}, values)
+
+	// the limit of the configuration caps the results and values that are not json objects are not queried
+	itr, err = db.ExecuteQuery("ns1", `{"selector":{}}`)
+	assert.NoError(t, err)
+	count := 0
+	for result, _ := itr.Next(); result != nil; result, _ = itr.Next() {
+		count++
+	}
+	itr.Close()
+	assert.Equal(t, 3, count)
+
+	itr, err = db.ExecuteQuery("ns1", `{"selector":{"extra.color":"red","owner":{"$in":["fred","tom"]},"$not":{"size":3}}}`)
+	assert.NoError(t, err)
+	result, _ := itr.Next()
+	assert.Equal(t, "key3", result.(*statedb.VersionedKV).Key)
+	result, _ = itr.Next()
+	assert.Nil(t, result)
+	itr.Close()
+}
+
+func TestKeysAndValues(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	db, _ := env.DBProvider.GetDBHandle("testkeysandvalues")
+	batch := statedb.NewUpdateBatch()
+	// composite keys contain the 0x00 separator, and an empty value is not a delete
+	batch.Put("ns1", "\x00type\x00a\x00", []byte{}, version.NewHeight(1, 1))
+	batch.Put("ns1", "\x00type\x00b\x00", []byte{0xff, 0x00}, version.NewHeight(1, 2))
+	assert.NoError(t, db.ApplyUpdates(batch, version.NewHeight(1, 2)))
+
+	vv, err := db.GetState("ns1", "\x00type\x00a\x00")
+	assert.NoError(t, err)
+	assert.Equal(t, &statedb.VersionedValue{Value: []byte{}, Version: version.NewHeight(1, 1)}, vv)
+
+	itr, err := db.GetStateRangeScanIterator("ns1", "\x00type\x00", "\x00type\x00\xf4\x8f\xbf\xbf")
+	assert.NoError(t, err)
+	result, _ := itr.Next()
+	assert.Equal(t, "\x00type\x00a\x00", result.(*statedb.VersionedKV).Key)
+	result, _ = itr.Next()
+	assert.Equal(t, []byte{0xff, 0x00}, result.(*statedb.VersionedKV).Value)
+	result, _ = itr.Next()
+	assert.Nil(t, result)
+	itr.Close()
+}
+
+func TestDrop(t *testing.T) {
+	env := newTestVDBEnv(t)
+	defer env.cleanup()
+	db1, _ := env.DBProvider.GetDBHandle("testdrop1")
+	db2, _ := env.DBProvider.GetDBHandle("testdrop2")
+	for _, db := range []statedb.VersionedDB{db1, db2} {
+		batch := statedb.NewUpdateBatch()
+		batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 1))
+		assert.NoError(t, db.ApplyUpdates(batch, version.NewHeight(1, 1)))
+	}
+
+	assert.NoError(t, env.DBProvider.Drop("testdrop1"))
+	vv, err := db1.GetState("ns1", "key1")
+	assert.NoError(t, err)
+	assert.Nil(t, vv)
+	sp, err := db1.GetLatestSavePoint()
+	assert.NoError(t, err)
+	assert.Nil(t, sp)
+
+	vv, _ = db2.GetState("ns1", "key1")
+	assert.NotNil(t, vv)
+	sp, _ = db2.GetLatestSavePoint()
+	assert.Equal(t, version.NewHeight(1, 1), sp)
+}
+
+func TestRegistration(t *testing.T) {
+	env := newTestVDBEnv(t)
+	env.DBProvider.Close()
+	provider, err := statedb.NewVersionedDBProvider(StateDatabaseName)
+	assert.NoError(t, err)
+	provider.Close()
+	os.RemoveAll(testDBPath)
+}
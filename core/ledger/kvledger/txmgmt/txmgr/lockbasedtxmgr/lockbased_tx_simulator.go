@@ -21,6 +21,8 @@ import (
 
 	"github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 )
 
 // LockBasedTxSimulator is a transaction simulator used in `LockBasedTxMgr`
@@ -67,6 +69,16 @@ func (s *lockBasedTxSimulator) SetStateMultipleKeys(namespace string, kvs map[st
 	return nil
 }
 
+// SetCrossChannelRead implements method in interface `ledger.TxSimulator`
+func (s *lockBasedTxSimulator) SetCrossChannelRead(ns string, crossChannelRead *kvrwset.CrossChannelRead) error {
+	s.helper.checkDone()
+	if !ledgerconfig.IsHistoryDBEnabled() {
+		return errors.New("Cross channel reads require the history database to be enabled")
+	}
+	s.rwsetBuilder.AddToCrossChannelReadSet(ns, crossChannelRead)
+	return nil
+}
+
 // GetTxSimulationResults implements method in interface `ledger.TxSimulator`
 func (s *lockBasedTxSimulator) GetTxSimulationResults() ([]byte, error) {
 	logger.Debugf("Simulation completed, getting simulation results")
@@ -21,6 +21,7 @@ import (
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/validator/statebasedval"
@@ -30,20 +31,37 @@ import (
 
 var logger = flogging.MustGetLogger("lockbasedtxmgr")
 
+// lsccNamespace is the namespace where lscc records the chaincodes deployed on the channel
+const lsccNamespace = "lscc"
+
+// maxPendingDeployments is the number of blocks deploying chaincodes that can be committed
+// before the processing of their statedb artifacts holds back the commit of the next one
+const maxPendingDeployments = 100
+
 // LockBasedTxMgr a simple implementation of interface `txmgmt.TxMgr`.
 // This implementation uses a read-write lock to prevent conflicts between transaction simulation and committing
 type LockBasedTxMgr struct {
+	ledgerid     string
 	db           statedb.VersionedDB
 	validator    validator.Validator
 	batch        *statedb.UpdateBatch
 	currentBlock *common.Block
 	commitRWLock sync.RWMutex
+	// deployments queues the lscc updates of the committed blocks, which are processed
+	// in order by handleChaincodeDeployments without holding the commit lock
+	deployments     chan map[string][]byte
+	deploymentsDone chan struct{}
+	shutdownOnce    sync.Once
 }
 
-// NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr
-func NewLockBasedTxMgr(db statedb.VersionedDB) *LockBasedTxMgr {
+// NewLockBasedTxMgr constructs a new instance of NewLockBasedTxMgr.
+// crossChannelState is used for validating the reads performed by transactions on other channels and can be nil
+func NewLockBasedTxMgr(ledgerid string, db statedb.VersionedDB, crossChannelState statebasedval.CrossChannelStateProvider) *LockBasedTxMgr {
 	db.Open()
-	return &LockBasedTxMgr{db: db, validator: statebasedval.NewValidator(db)}
+	txmgr := &LockBasedTxMgr{ledgerid: ledgerid, db: db, validator: statebasedval.NewValidator(db, crossChannelState),
+		deployments: make(chan map[string][]byte, maxPendingDeployments), deploymentsDone: make(chan struct{})}
+	go txmgr.handleChaincodeDeployments()
+	return txmgr
 }
 
 // GetLastSavepoint returns the block num recorded in savepoint,
@@ -79,13 +97,37 @@ func (txmgr *LockBasedTxMgr) ValidateAndPrepare(block *common.Block, doMVCCValid
 	return err
 }
 
-// Shutdown implements method in interface `txmgmt.TxMgr`
+// GetPreparedUpdates implements method in interface `txmgmt.TxMgr`.
+// It returns the updates prepared by the last call to ValidateAndPrepare, or nil once they are committed
+func (txmgr *LockBasedTxMgr) GetPreparedUpdates() *statedb.UpdateBatch {
+	return txmgr.batch
+}
+
+// Shutdown implements method in interface `txmgmt.TxMgr`.
+// The chaincode deployments already committed are processed before the db is closed
 func (txmgr *LockBasedTxMgr) Shutdown() {
+	txmgr.shutdownOnce.Do(func() {
+		close(txmgr.deployments)
+		<-txmgr.deploymentsDone
+	})
 	txmgr.db.Close()
 }
 
 // Commit implements method in interface `txmgmt.TxMgr`
 func (txmgr *LockBasedTxMgr) Commit() error {
+	lsccUpdates, err := txmgr.commit()
+	if err != nil {
+		return err
+	}
+	if len(lsccUpdates) > 0 {
+		txmgr.deployments <- lsccUpdates
+	}
+	return nil
+}
+
+// commit applies the prepared updates under the commit lock and returns the chaincodes
+// deployed or upgraded by them
+func (txmgr *LockBasedTxMgr) commit() (map[string][]byte, error) {
 	logger.Debugf("Committing updates to state database")
 	txmgr.commitRWLock.Lock()
 	defer txmgr.commitRWLock.Unlock()
@@ -96,10 +138,29 @@ func (txmgr *LockBasedTxMgr) Commit() error {
 	defer func() { txmgr.batch = nil }()
 	if err := txmgr.db.ApplyUpdates(txmgr.batch,
 		version.NewHeight(txmgr.currentBlock.Header.Number, uint64(len(txmgr.currentBlock.Data.Data)-1))); err != nil {
-		return err
+		return nil, err
 	}
 	logger.Debugf("Updates committed to state database")
-	return nil
+	lsccUpdates := make(map[string][]byte)
+	for ccName, vv := range txmgr.batch.GetUpdates(lsccNamespace) {
+		if vv.Value != nil {
+			lsccUpdates[ccName] = vv.Value
+		}
+	}
+	return lsccUpdates, nil
+}
+
+// handleChaincodeDeployments notifies the chaincodes deployed or upgraded in the committed blocks,
+// so that their statedb artifacts get processed, such as the creation of CouchDB indexes. This
+// runs in the background so that block commit does not wait for it, and a failure is not fatal,
+// as the artifacts are processed again on the next install of the chaincode
+func (txmgr *LockBasedTxMgr) handleChaincodeDeployments() {
+	defer close(txmgr.deploymentsDone)
+	for lsccUpdates := range txmgr.deployments {
+		if err := cceventmgmt.GetMgr().HandleChaincodeDeploy(txmgr.ledgerid, lsccUpdates); err != nil {
+			logger.Errorf("Channel [%s]: error while processing the statedb artifacts of the deployed chaincodes: %s", txmgr.ledgerid, err)
+		}
+	}
 }
 
 // Rollback implements method in interface `txmgmt.TxMgr`
@@ -122,6 +183,7 @@ func (txmgr *LockBasedTxMgr) ShouldRecover(lastAvailableBlock uint64) (bool, uin
 // CommitLostBlock implements method in interface kvledger.Recoverer
 func (txmgr *LockBasedTxMgr) CommitLostBlock(block *common.Block) error {
 	logger.Debugf("Constructing updateSet for the block %d", block.Header.Number)
+	// the block was validated when first committed, its validation flags alone decide what gets applied
 	if err := txmgr.ValidateAndPrepare(block, false); err != nil {
 		return err
 	}
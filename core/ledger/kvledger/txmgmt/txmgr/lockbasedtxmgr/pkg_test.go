@@ -67,7 +67,7 @@ func (env *levelDBLockBasedEnv) init(t *testing.T, testLedgerID string) {
 	testDB, err := testDBEnv.DBProvider.GetDBHandle(testLedgerID)
 	testutil.AssertNoError(t, err, "")
 
-	txMgr := NewLockBasedTxMgr(testDB)
+	txMgr := NewLockBasedTxMgr(testLedgerID, testDB, nil)
 	env.testLedgerID = testLedgerID
 	env.testDBEnv = testDBEnv
 	env.testDB = testDB
@@ -117,7 +117,7 @@ func (env *couchDBLockBasedEnv) init(t *testing.T, testLedgerID string) {
 	testDB, err := testDBEnv.DBProvider.GetDBHandle(testLedgerID)
 	testutil.AssertNoError(t, err, "")
 
-	txMgr := NewLockBasedTxMgr(testDB)
+	txMgr := NewLockBasedTxMgr(testLedgerID, testDB, nil)
 	env.testLedgerID = testLedgerID
 	env.testDBEnv = testDBEnv
 	env.testDB = testDB
@@ -17,6 +17,9 @@ limitations under the License.
 package statebasedval
 
 import (
+	"crypto/sha256"
+	"errors"
+
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
@@ -30,15 +33,40 @@ import (
 
 var logger = flogging.MustGetLogger("statevalidator")
 
+// ErrChannelNotAvailable is returned by a CrossChannelStateProvider for a channel that the peer does not host
+var ErrChannelNotAvailable = errors.New("Channel not available on this peer")
+
+// ErrHeightNotReached is returned by a CrossChannelStateProvider for a channel that does not reach the block height in time
+var ErrHeightNotReached = errors.New("Channel did not reach the block height")
+
+// ErrHistoryDBRequired is returned by a CrossChannelStateProvider if the history database is disabled,
+// as the versions of the keys as of past block heights are looked up in it
+var ErrHistoryDBRequired = errors.New("The history database is required for validating cross channel reads")
+
+// CrossChannelStateProvider provides the committed state of the other channels on the peer.
+// This is used for validating the reads performed by a transaction on another channel via a chaincode invocation
+type CrossChannelStateProvider interface {
+	// GetVersionAtHeight returns the version of a key of the given channel as of the given block height,
+	// that is the version written by the last valid transaction in the blocks [0, blockHeight), or nil if the
+	// key did not exist at that height. It waits for the channel to commit the blocks up to that height, so
+	// that the result does not depend on the progress of the peer on the channel, unless the channel does not
+	// receive them in time, in which case ErrHeightNotReached is returned.
+	// ErrChannelNotAvailable is returned if the peer does not host the channel, and ErrHistoryDBRequired
+	// if the history database of the peer is disabled
+	GetVersionAtHeight(channelID string, ns string, key string, blockHeight uint64) (*version.Height, error)
+}
+
 // Validator validates a tx against the latest committed state
 // and preceding valid transactions with in the same block
 type Validator struct {
-	db statedb.VersionedDB
+	db                statedb.VersionedDB
+	crossChannelState CrossChannelStateProvider
 }
 
-// NewValidator constructs StateValidator
-func NewValidator(db statedb.VersionedDB) *Validator {
-	return &Validator{db}
+// NewValidator constructs StateValidator.
+// crossChannelState can be nil, in which case a transaction that contains cross channel reads is marked invalid
+func NewValidator(db statedb.VersionedDB, crossChannelState CrossChannelStateProvider) *Validator {
+	return &Validator{db, crossChannelState}
 }
 
 //validate endorser transaction
@@ -88,6 +116,12 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 		block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
 	}
 
+	if bulkOptimizable, ok := v.db.(statedb.BulkOptimizable); ok && doMVCCValidation {
+		if err := v.preLoadCommittedVersionOfRSet(bulkOptimizable, block, txsFilter); err != nil {
+			return nil, err
+		}
+	}
+
 	for txIndex, envBytes := range block.Data.Data {
 		if txsFilter.IsInvalid(txIndex) {
 			// Skiping invalid transaction
@@ -146,6 +180,56 @@ func (v *Validator) ValidateAndPrepareBatch(block *common.Block, doMVCCValidatio
 	return updates, nil
 }
 
+// preLoadCommittedVersionOfRSet loads in bulk the committed versions of the keys read by the
+// endorser transactions of the block, so that validateKVRead does not read them one at a time
+func (v *Validator) preLoadCommittedVersionOfRSet(db statedb.BulkOptimizable, block *common.Block, txsFilter util.TxValidationFlags) error {
+	var keys []*statedb.CompositeKey
+	loaded := make(map[statedb.CompositeKey]bool)
+	for txIndex, envBytes := range block.Data.Data {
+		if txsFilter.IsInvalid(txIndex) {
+			continue
+		}
+		env, err := putils.GetEnvelopeFromBlock(envBytes)
+		if err != nil {
+			return err
+		}
+		payload, err := putils.GetPayload(env)
+		if err != nil {
+			return err
+		}
+		chdr, err := putils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+		if err != nil {
+			return err
+		}
+		if common.HeaderType(chdr.Type) != common.HeaderType_ENDORSER_TRANSACTION {
+			continue
+		}
+		// the transactions that cannot be parsed are marked invalid by validateEndorserTX
+		respPayload, err := putils.GetActionFromEnvelope(envBytes)
+		if err != nil {
+			continue
+		}
+		txRWSet := &rwsetutil.TxRwSet{}
+		if err := txRWSet.FromProtoBytes(respPayload.Results); err != nil {
+			continue
+		}
+		for _, nsRWSet := range txRWSet.NsRwSets {
+			for _, kvRead := range nsRWSet.KvRwSet.Reads {
+				compositeKey := statedb.CompositeKey{Namespace: nsRWSet.NameSpace, Key: kvRead.Key}
+				if !loaded[compositeKey] {
+					loaded[compositeKey] = true
+					keys = append(keys, &compositeKey)
+				}
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	logger.Debugf("Loading the committed versions of [%d] keys read by block [%d]", len(keys), block.Header.Number)
+	return db.LoadCommittedVersions(keys)
+}
+
 func addWriteSetToBatch(txRWSet *rwsetutil.TxRwSet, txHeight *version.Height, batch *statedb.UpdateBatch) {
 	for _, nsRWSet := range txRWSet.NsRwSets {
 		ns := nsRWSet.NameSpace
@@ -175,6 +259,12 @@ func (v *Validator) validateTx(txRWSet *rwsetutil.TxRwSet, updates *statedb.Upda
 			}
 			return peer.TxValidationCode_PHANTOM_READ_CONFLICT, nil
 		}
+		if valid, err := v.validateCrossChannelReads(nsRWSet.KvRwSet.CrossChannelReads); !valid || err != nil {
+			if err != nil {
+				return peer.TxValidationCode(-1), err
+			}
+			return peer.TxValidationCode_CROSS_CHANNEL_READ_CONFLICT, nil
+		}
 	}
 	return peer.TxValidationCode_VALID, nil
 }
@@ -195,14 +285,10 @@ func (v *Validator) validateKVRead(ns string, kvRead *kvrwset.KVRead, updates *s
 	if updates.Exists(ns, kvRead.Key) {
 		return false, nil
 	}
-	versionedValue, err := v.db.GetState(ns, kvRead.Key)
+	committedVersion, err := v.getCommittedVersion(ns, kvRead.Key)
 	if err != nil {
 		return false, nil
 	}
-	var committedVersion *version.Height
-	if versionedValue != nil {
-		committedVersion = versionedValue.Version
-	}
 
 	if !version.AreSame(committedVersion, rwsetutil.NewVersion(kvRead.Version)) {
 		logger.Debugf("Version mismatch for key [%s:%s]. Committed version = [%s], Version in readSet [%s]",
@@ -212,6 +298,73 @@ func (v *Validator) validateKVRead(ns string, kvRead *kvrwset.KVRead, updates *s
 	return true, nil
 }
 
+// getCommittedVersion returns the committed version of the key, from the cache of the db when
+// it was loaded by preLoadCommittedVersionOfRSet
+func (v *Validator) getCommittedVersion(ns string, key string) (*version.Height, error) {
+	if bulkOptimizable, ok := v.db.(statedb.BulkOptimizable); ok {
+		if committedVersion, ok := bulkOptimizable.GetCachedVersion(ns, key); ok {
+			return committedVersion, nil
+		}
+	}
+	versionedValue, err := v.db.GetState(ns, key)
+	if err != nil || versionedValue == nil {
+		return nil, err
+	}
+	return versionedValue.Version, nil
+}
+
+func (v *Validator) validateCrossChannelReads(crossChannelReads []*kvrwset.CrossChannelRead) (bool, error) {
+	for _, ccRead := range crossChannelReads {
+		if valid, err := v.validateCrossChannelRead(ccRead); !valid || err != nil {
+			return valid, err
+		}
+	}
+	return true, nil
+}
+
+// validateCrossChannelRead checks the reads performed by a chaincode invocation on another channel against
+// the state of that channel as of the block height observed during simulation, rather than against its latest
+// state, so that all the peers reach the same result whatever their progress on the other channel. The response
+// of the invocation cannot be checked without executing the called chaincode again; it is determined by the
+// reads, which include the definition of the called chaincode, and its hash must be a SHA-256 digest so that
+// the endorsement covers it. A peer which does not host the other channel cannot validate the reads and
+// marks the transaction invalid, so all the peers of a channel must join the channels that its chaincodes call
+// and enable the history database. So does a peer which does not receive the blocks of the other channel up
+// to the observed height in time, which bounds the height an endorser can make the peers wait for.
+// Note that the updates in the current block are not relevant here as they belong to a different channel
+func (v *Validator) validateCrossChannelRead(ccRead *kvrwset.CrossChannelRead) (bool, error) {
+	logger.Debugf("validateCrossChannelRead: channel=%s, chaincode=%s, blockHeight=%d",
+		ccRead.ChannelId, ccRead.ChaincodeName, ccRead.BlockHeight)
+	if len(ccRead.ResponseHash) != sha256.Size {
+		logger.Debugf("Invalid response hash for the invocation of chaincode [%s] on channel [%s]",
+			ccRead.ChaincodeName, ccRead.ChannelId)
+		return false, nil
+	}
+	if v.crossChannelState == nil {
+		logger.Debugf("No cross channel state provider available for validating reads on channel [%s]", ccRead.ChannelId)
+		return false, nil
+	}
+	for _, nsReads := range ccRead.NsReads {
+		for _, kvRead := range nsReads.Reads {
+			committedVersion, err := v.crossChannelState.GetVersionAtHeight(ccRead.ChannelId, nsReads.Namespace, kvRead.Key, ccRead.BlockHeight)
+			if err == ErrChannelNotAvailable || err == ErrHeightNotReached || err == ErrHistoryDBRequired {
+				logger.Warningf("Cannot validate cross channel reads on channel [%s] at block height [%d]: %s",
+					ccRead.ChannelId, ccRead.BlockHeight, err)
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			if !version.AreSame(committedVersion, rwsetutil.NewVersion(kvRead.Version)) {
+				logger.Debugf("Version mismatch for key [%s:%s] on channel [%s] at block height [%d]. Committed version = [%s], Version in readSet [%s]",
+					nsReads.Namespace, kvRead.Key, ccRead.ChannelId, ccRead.BlockHeight, committedVersion, kvRead.Version)
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
 func (v *Validator) validateRangeQueries(ns string, rangeQueriesInfo []*kvrwset.RangeQueryInfo, updates *statedb.UpdateBatch) (bool, error) {
 	for _, rqi := range rangeQueriesInfo {
 		if valid, err := v.validateRangeQuery(ns, rqi, updates); !valid || err != nil {
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	commonutil "github.com/hyperledger/fabric/common/util"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/rwsetutil"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
 	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb/stateleveldb"
@@ -53,7 +54,7 @@ func TestValidator(t *testing.T) {
 	batch.Put("ns1", "key5", []byte("value5"), version.NewHeight(1, 4))
 	db.ApplyUpdates(batch, version.NewHeight(1, 4))
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	//rwset1 should be valid
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
@@ -87,7 +88,7 @@ func TestValidatorSkipInvalidTxs(t *testing.T) {
 	defer testDBEnv.Cleanup()
 	db, err := testDBEnv.DBProvider.GetDBHandle("TestDB")
 	testutil.AssertNoError(t, err, "")
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
 	rwsetBuilder1.AddToWriteSet("ns1", "key1", []byte("value1"))
@@ -102,6 +103,70 @@ func TestValidatorSkipInvalidTxs(t *testing.T) {
 		flags, []int{1})
 }
 
+// bulkOptimizableDB loads the committed versions of the keys from the wrapped db
+// and counts the keys read one at a time
+type bulkOptimizableDB struct {
+	statedb.VersionedDB
+	cachedVersions map[statedb.CompositeKey]*version.Height
+	loadedKeys     int
+	getStateCalls  int
+}
+
+func (db *bulkOptimizableDB) GetState(namespace string, key string) (*statedb.VersionedValue, error) {
+	db.getStateCalls++
+	return db.VersionedDB.GetState(namespace, key)
+}
+
+func (db *bulkOptimizableDB) LoadCommittedVersions(keys []*statedb.CompositeKey) error {
+	for _, key := range keys {
+		vv, err := db.VersionedDB.GetState(key.Namespace, key.Key)
+		if err != nil {
+			return err
+		}
+		var committedVersion *version.Height
+		if vv != nil {
+			committedVersion = vv.Version
+		}
+		db.cachedVersions[*key] = committedVersion
+		db.loadedKeys++
+	}
+	return nil
+}
+
+func (db *bulkOptimizableDB) GetCachedVersion(namespace, key string) (*version.Height, bool) {
+	committedVersion, ok := db.cachedVersions[statedb.CompositeKey{Namespace: namespace, Key: key}]
+	return committedVersion, ok
+}
+
+func TestValidatorBulkOptimizable(t *testing.T) {
+	testDBEnv := stateleveldb.NewTestVDBEnv(t)
+	defer testDBEnv.Cleanup()
+	vdb, err := testDBEnv.DBProvider.GetDBHandle("TestDB")
+	testutil.AssertNoError(t, err, "")
+	batch := statedb.NewUpdateBatch()
+	batch.Put("ns1", "key1", []byte("value1"), version.NewHeight(1, 0))
+	batch.Put("ns1", "key2", []byte("value2"), version.NewHeight(1, 1))
+	vdb.ApplyUpdates(batch, version.NewHeight(1, 1))
+
+	db := &bulkOptimizableDB{VersionedDB: vdb, cachedVersions: make(map[statedb.CompositeKey]*version.Height)}
+	validator := NewValidator(db, nil)
+
+	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder1.AddToReadSet("ns1", "key1", version.NewHeight(1, 0))
+	rwsetBuilder1.AddToReadSet("ns1", "key3", nil)
+	rwsetBuilder1.AddToWriteSet("ns1", "key1", []byte("value1_new"))
+	rwsetBuilder2 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder2.AddToReadSet("ns1", "key2", version.NewHeight(1, 0))
+	rwsetBuilder3 := rwsetutil.NewRWSetBuilder()
+	rwsetBuilder3.AddToReadSet("ns1", "key1", version.NewHeight(1, 0))
+	checkValidation(t, validator, []*rwsetutil.TxRwSet{rwsetBuilder1.GetTxReadWriteSet(),
+		rwsetBuilder2.GetTxReadWriteSet(), rwsetBuilder3.GetTxReadWriteSet()}, nil, []int{1, 2})
+
+	// the distinct keys read by the block are loaded at once and never read one at a time
+	testutil.AssertEquals(t, db.loadedKeys, 3)
+	testutil.AssertEquals(t, db.getStateCalls, 0)
+}
+
 func TestPhantomValidation(t *testing.T) {
 	testDBEnv := stateleveldb.NewTestVDBEnv(t)
 	defer testDBEnv.Cleanup()
@@ -118,7 +183,7 @@ func TestPhantomValidation(t *testing.T) {
 	batch.Put("ns1", "key5", []byte("value5"), version.NewHeight(1, 4))
 	db.ApplyUpdates(batch, version.NewHeight(1, 4))
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	//rwset1 should be valid
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
@@ -196,7 +261,7 @@ func TestPhantomHashBasedValidation(t *testing.T) {
 	batch.Put("ns1", "key9", []byte("value9"), version.NewHeight(1, 8))
 	db.ApplyUpdates(batch, version.NewHeight(1, 8))
 
-	validator := NewValidator(db)
+	validator := NewValidator(db, nil)
 
 	rwsetBuilder1 := rwsetutil.NewRWSetBuilder()
 	rqi1 := &kvrwset.RangeQueryInfo{StartKey: "key2", EndKey: "key9", ItrExhausted: true}
@@ -231,6 +296,100 @@ func TestPhantomHashBasedValidation(t *testing.T) {
 	checkValidation(t, validator, []*rwsetutil.TxRwSet{rwsetBuilder2.GetTxReadWriteSet()}, nil, []int{0})
 }
 
+type mockWrite struct {
+	height   *version.Height
+	isDelete bool
+}
+
+// mockCrossChannelState keeps the history of the keys of the other channels, which are at the given height
+type mockCrossChannelState struct {
+	writes map[string]map[string][]mockWrite
+	height uint64
+}
+
+func (m *mockCrossChannelState) GetVersionAtHeight(channelID string, ns string, key string, blockHeight uint64) (*version.Height, error) {
+	channelWrites, ok := m.writes[channelID]
+	if !ok {
+		return nil, ErrChannelNotAvailable
+	}
+	if blockHeight > m.height {
+		return nil, ErrHeightNotReached
+	}
+	var committedVersion *version.Height
+	for _, w := range channelWrites[ns+"/"+key] {
+		if w.height.BlockNum >= blockHeight {
+			break
+		}
+		committedVersion = w.height
+		if w.isDelete {
+			committedVersion = nil
+		}
+	}
+	return committedVersion, nil
+}
+
+func TestCrossChannelReadsValidation(t *testing.T) {
+	testDBEnv := stateleveldb.NewTestVDBEnv(t)
+	defer testDBEnv.Cleanup()
+
+	db, err := testDBEnv.DBProvider.GetDBHandle("TestDB")
+	testutil.AssertNoError(t, err, "")
+
+	// the history of the other channel: key2 gets updated in block 3 and key3 gets deleted in block 2
+	crossChannelState := &mockCrossChannelState{writes: map[string]map[string][]mockWrite{"otherChannel": {
+		"cc2/key1": {{version.NewHeight(1, 0), false}},
+		"cc2/key2": {{version.NewHeight(1, 1), false}, {version.NewHeight(3, 0), false}},
+		"cc2/key3": {{version.NewHeight(1, 2), false}, {version.NewHeight(2, 0), true}},
+	}}, height: 4}
+
+	newCrossChannelRead := func(channelID string, blockHeight uint64, kvReads ...*kvrwset.KVRead) *kvrwset.CrossChannelRead {
+		return &kvrwset.CrossChannelRead{ChannelId: channelID, ChaincodeName: "cc2", BlockHeight: blockHeight,
+			ResponseHash: commonutil.ComputeSHA256([]byte("response")), NsReads: []*kvrwset.NsReads{{Namespace: "cc2", Reads: kvReads}}}
+	}
+	checkCrossChannelRead := func(validator *Validator, ccRead *kvrwset.CrossChannelRead, valid bool) {
+		rwsetBuilder := rwsetutil.NewRWSetBuilder()
+		rwsetBuilder.AddToCrossChannelReadSet("ns1", ccRead)
+		expectedInvalidTxIndexes := []int{0}
+		if valid {
+			expectedInvalidTxIndexes = []int{}
+		}
+		checkValidation(t, validator, []*rwsetutil.TxRwSet{rwsetBuilder.GetTxReadWriteSet()}, nil, expectedInvalidTxIndexes)
+	}
+
+	validator := NewValidator(db, crossChannelState)
+
+	// the reads are checked as of the block height observed during simulation,
+	// whatever has been committed on the other channel since
+	checkCrossChannelRead(validator, newCrossChannelRead("otherChannel", 2,
+		rwsetutil.NewKVRead("key1", version.NewHeight(1, 0)), rwsetutil.NewKVRead("key2", version.NewHeight(1, 1)),
+		rwsetutil.NewKVRead("key3", version.NewHeight(1, 2)), rwsetutil.NewKVRead("key4", nil)), true)
+	checkCrossChannelRead(validator, newCrossChannelRead("otherChannel", 3,
+		rwsetutil.NewKVRead("key2", version.NewHeight(1, 1)), rwsetutil.NewKVRead("key3", nil)), true)
+
+	// key2 has a different version at height 4 and key3 exists at height 2
+	checkCrossChannelRead(validator, newCrossChannelRead("otherChannel", 4,
+		rwsetutil.NewKVRead("key2", version.NewHeight(1, 1))), false)
+	checkCrossChannelRead(validator, newCrossChannelRead("otherChannel", 2,
+		rwsetutil.NewKVRead("key3", nil)), false)
+
+	// the channel is not hosted by the peer
+	checkCrossChannelRead(validator, newCrossChannelRead("unknownChannel", 1,
+		rwsetutil.NewKVRead("key1", nil)), false)
+
+	// the other channel does not reach the block height
+	checkCrossChannelRead(validator, newCrossChannelRead("otherChannel", 1<<62,
+		rwsetutil.NewKVRead("key1", version.NewHeight(1, 0))), false)
+
+	// the response hash is not a digest
+	ccRead := newCrossChannelRead("otherChannel", 2, rwsetutil.NewKVRead("key1", version.NewHeight(1, 0)))
+	ccRead.ResponseHash = []byte("response")
+	checkCrossChannelRead(validator, ccRead, false)
+
+	// no cross channel state is available
+	checkCrossChannelRead(NewValidator(db, nil), newCrossChannelRead("otherChannel", 2,
+		rwsetutil.NewKVRead("key1", version.NewHeight(1, 0))), false)
+}
+
 func checkValidation(t *testing.T, validator *Validator, rwsets []*rwsetutil.TxRwSet,
 	alreadyMarkedFlags util.TxValidationFlags, expectedInvalidTxIndexes []int) {
 	simulationResults := [][]byte{}
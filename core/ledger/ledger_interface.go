@@ -19,6 +19,8 @@ package ledger
 import (
 	commonledger "github.com/hyperledger/fabric/common/ledger"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
+	"github.com/hyperledger/fabric/protos/ledger/rwset/kvrwset"
 	"github.com/hyperledger/fabric/protos/peer"
 )
 
@@ -34,6 +36,17 @@ type PeerLedgerProvider interface {
 	Exists(ledgerID string) (bool, error)
 	// List lists the ids of the existing ledgers
 	List() ([]string, error)
+	// MarkForDeletion removes the ledger with the given id from the existing ledgers and marks it
+	// as under deletion. Its data is deleted by a subsequent Delete call or, failing that, on the
+	// next start of the provider. Only one ledger can be under deletion at a time
+	MarkForDeletion(ledgerID string) error
+	// Delete deletes the ledger with the given id along with all its data, marking it for deletion
+	// first if it is not already. The ledger must have been closed
+	Delete(ledgerID string) error
+	// RegisterStateListener registers a listener that gets notified of the state updates
+	// of the blocks committed to the ledgers opened afterwards. The name of the listener
+	// must be unique among the registered listeners
+	RegisterStateListener(listener StateListener) error
 	// Close closes the PeerLedgerProvider
 	Close()
 }
@@ -102,6 +115,16 @@ type HistoryQueryExecutor interface {
 	// GetHistoryForKey retrieves the history of values for a key.
 	// The returned ResultsIterator contains results of type *KeyModification which is defined in protos/ledger/queryresult.
 	GetHistoryForKey(namespace string, key string) (commonledger.ResultsIterator, error)
+	// GetHistoryForKeyWithOptions retrieves the history of values for a key, restricted to
+	// the blocks and times given in the options and ordered as the options specify.
+	// A nil options is equivalent to GetHistoryForKey
+	GetHistoryForKeyWithOptions(namespace string, key string, options *queryresult.HistoryQueryOptions) (commonledger.ResultsIterator, error)
+	// GetHistoryForKeyRange retrieves the history of values for all the keys in the range
+	// [startKey, endKey), including the keys that are deleted by now. An empty startKey
+	// or endKey means an unbounded range on that side. The results are ordered by key and,
+	// for each key, by the position of the transaction in the chain (reversed altogether
+	// if the options ask for the newest first).
+	GetHistoryForKeyRange(namespace string, startKey string, endKey string, options *queryresult.HistoryQueryOptions) (commonledger.ResultsIterator, error)
 }
 
 // TxSimulator simulates a transaction on a consistent snapshot of the 'as recent state as possible'
@@ -116,6 +139,10 @@ type TxSimulator interface {
 	SetStateMultipleKeys(namespace string, kvs map[string][]byte) error
 	// ExecuteUpdate for supporting rich data model (see comments on QueryExecutor above)
 	ExecuteUpdate(query string) error
+	// SetCrossChannelRead records a read-only chaincode invocation on another channel made by the chaincode
+	// corresponding to the given namespace. The recorded reads are verified against the state of the called
+	// channel as of the recorded block height during validation
+	SetCrossChannelRead(namespace string, crossChannelRead *kvrwset.CrossChannelRead) error
 	// GetTxSimulationResults encapsulates the results of the transaction simulation.
 	// This should contain enough detail for
 	// - The update in the state that would be caused if the transaction is to be committed
@@ -125,3 +152,32 @@ type TxSimulator interface {
 	// of information in different way in order to support different data-models or optimize the information representations.
 	GetTxSimulationResults() ([]byte, error)
 }
+
+// StateListener allows to replicate the world state of a ledger outside of the peer.
+// The listener is notified of the updates of every block after they are committed to the state database.
+// The delivery is at-least-once: the last block handled successfully by a listener is checkpointed,
+// and the blocks after the checkpoint are delivered again when the ledger is opened. Hence, a listener
+// may be notified more than once of the same block and should apply the updates idempotently
+type StateListener interface {
+	// Name returns the name of the listener, under which its checkpoints are persisted
+	Name() string
+	// InterestedInNamespaces returns the namespaces the listener is interested in.
+	// An empty list means all the namespaces
+	InterestedInNamespaces() []string
+	// HandleStateUpdates is invoked with the updates of the given block, for the namespaces the listener
+	// is interested in. It is invoked for every block, even the ones with no such updates.
+	// When an error is returned, the listener is no more notified until the ledger is reopened
+	HandleStateUpdates(ledgerID string, blockNum uint64, updates StateUpdates) error
+}
+
+// StateUpdates maps a namespace to its updates, sorted by key
+type StateUpdates map[string][]*KVStateUpdate
+
+// KVStateUpdate is the update of a key by a valid transaction. BlockNum and TxNum are the version of the key
+type KVStateUpdate struct {
+	Key      string
+	Value    []byte
+	IsDelete bool
+	BlockNum uint64
+	TxNum    uint64
+}
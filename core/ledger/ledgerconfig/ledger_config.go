@@ -32,6 +32,14 @@ func IsCouchDBEnabled() bool {
 	return false
 }
 
+// GetStateDatabase returns the name of the state database provider, goleveldb by default
+func GetStateDatabase() string {
+	if stateDatabase := viper.GetString("ledger.state.stateDatabase"); stateDatabase != "" {
+		return stateDatabase
+	}
+	return "goleveldb"
+}
+
 // GetRootPath returns the filesystem path.
 // All ledger related contents are expected to be stored under this path
 func GetRootPath() string {
@@ -44,6 +52,12 @@ func GetLedgerProviderPath() string {
 	return filepath.Join(GetRootPath(), "ledgerProvider")
 }
 
+// GetFileLockPath returns the filesystem path of the lock held on the ledgers by the
+// peer while it runs, and by the commands that operate on the ledgers of a stopped peer
+func GetFileLockPath() string {
+	return filepath.Join(GetRootPath(), "fileLock")
+}
+
 // GetStateLevelDBPath returns the filesystem path that is used to maintain the state level db
 func GetStateLevelDBPath() string {
 	return filepath.Join(GetRootPath(), "stateLeveldb")
@@ -64,6 +78,23 @@ func GetMaxBlockfileSize() int {
 	return 64 * 1024 * 1024
 }
 
+// GetBlockfileFormat returns the format of the new block files, "legacy" or "checksummed"
+func GetBlockfileFormat() string {
+	if !viper.IsSet("ledger.blockchain.blockfileFormat") {
+		return "legacy"
+	}
+	return viper.GetString("ledger.blockchain.blockfileFormat")
+}
+
+// GetBlockfileCompression returns the compression of the blocks in the
+// checksummed block file format, "none" or "snappy"
+func GetBlockfileCompression() string {
+	if !viper.IsSet("ledger.blockchain.blockfileCompression") {
+		return "none"
+	}
+	return viper.GetString("ledger.blockchain.blockfileCompression")
+}
+
 //GetQueryLimit exposes the queryLimit variable
 func GetQueryLimit() int {
 	queryLimit := viper.GetInt("ledger.state.couchDBConfig.queryLimit")
@@ -74,11 +105,41 @@ func GetQueryLimit() int {
 	return queryLimit
 }
 
+// GetStateCacheSize returns the number of keys per channel whose committed state is cached
+// by the CouchDB state database, the cache being disabled when it is 0
+func GetStateCacheSize() int {
+	// if cacheSize was unset, default to 10000
+	if !viper.IsSet("ledger.state.couchDBConfig.cacheSize") {
+		return 10000
+	}
+	return viper.GetInt("ledger.state.couchDBConfig.cacheSize")
+}
+
 //IsHistoryDBEnabled exposes the historyDatabase variable
 func IsHistoryDBEnabled() bool {
 	return viper.GetBool("ledger.history.enableHistoryDatabase")
 }
 
+// IsJSONLinesStateListenerEnabled tells whether the state updates of the committed blocks
+// should be written to JSON lines files
+func IsJSONLinesStateListenerEnabled() bool {
+	return viper.GetBool("ledger.stateListeners.jsonLines.enabled")
+}
+
+// GetJSONLinesStateListenerPath returns the filesystem path of the directory the JSON lines files are written to
+func GetJSONLinesStateListenerPath() string {
+	if path := config.GetPath("ledger.stateListeners.jsonLines.fileSystemPath"); path != "" {
+		return path
+	}
+	return filepath.Join(GetRootPath(), "jsonLinesStateListener")
+}
+
+// GetJSONLinesStateListenerNamespaces returns the namespaces whose updates are written to the
+// JSON lines files, all of them if empty
+func GetJSONLinesStateListenerNamespaces() []string {
+	return viper.GetStringSlice("ledger.stateListeners.jsonLines.namespaces")
+}
+
 // IsQueryReadsHashingEnabled enables or disables computing of hash
 // of range query results for phantom item validation
 func IsQueryReadsHashingEnabled() bool {
@@ -46,6 +46,35 @@ func TestIsCouchDBEnabled(t *testing.T) {
 	testutil.AssertEquals(t, updatedValue, true) //test config returns true
 }
 
+func TestGetStateDatabase(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	viper.Set("ledger.state.stateDatabase", "")
+	testutil.AssertEquals(t, GetStateDatabase(), "goleveldb") //test empty config defaults to goleveldb
+	viper.Set("ledger.state.stateDatabase", "SQL")
+	testutil.AssertEquals(t, GetStateDatabase(), "SQL")
+}
+
+func TestGetStateCacheSize(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer ledgertestutil.ResetConfigToDefaultValues()
+	testutil.AssertEquals(t, GetStateCacheSize(), 10000)
+	viper.Set("ledger.state.couchDBConfig.cacheSize", 0)
+	testutil.AssertEquals(t, GetStateCacheSize(), 0)
+}
+
+func TestGetBlockfileFormat(t *testing.T) {
+	setUpCoreYAMLConfig()
+	defer viper.Set("ledger.blockchain.blockfileFormat", "legacy")
+	defer viper.Set("ledger.blockchain.blockfileCompression", "none")
+	testutil.AssertEquals(t, GetBlockfileFormat(), "legacy")
+	testutil.AssertEquals(t, GetBlockfileCompression(), "none")
+	viper.Set("ledger.blockchain.blockfileFormat", "checksummed")
+	viper.Set("ledger.blockchain.blockfileCompression", "snappy")
+	testutil.AssertEquals(t, GetBlockfileFormat(), "checksummed")
+	testutil.AssertEquals(t, GetBlockfileCompression(), "snappy")
+}
+
 func TestIsHistoryDBEnabledDefault(t *testing.T) {
 	setUpCoreYAMLConfig()
 	defaultValue := IsHistoryDBEnabled()
@@ -23,8 +23,10 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
 	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -37,29 +39,45 @@ var ErrLedgerAlreadyOpened = errors.New("Ledger already opened")
 // ErrLedgerMgmtNotInitialized is thrown when ledger mgmt is used before initializing this
 var ErrLedgerMgmtNotInitialized = errors.New("ledger mgmt should be initialized before using")
 
+// ErrLedgerMgmtInUse is thrown when a ledger is rolled back or rebuilt while ledger mgmt is in use
+var ErrLedgerMgmtInUse = errors.New("ledger mgmt is in use, ledgers can only be rolled back or rebuilt while the peer is stopped")
+
 var openedLedgers map[string]ledger.PeerLedger
 var ledgerProvider ledger.PeerLedgerProvider
+var fileLock *leveldbhelper.FileLock
 var lock sync.Mutex
 var initialized bool
 var once sync.Once
 
-// Initialize initializes ledgermgmt
-func Initialize() {
+// Initialize initializes ledgermgmt. The given state listeners are notified
+// of the state updates of the blocks committed to all the ledgers
+func Initialize(stateListeners ...ledger.StateListener) {
 	once.Do(func() {
-		initialize()
+		initialize(stateListeners)
 	})
 }
 
-func initialize() {
+func initialize(stateListeners []ledger.StateListener) {
 	logger.Info("Initializing ledger mgmt")
 	lock.Lock()
 	defer lock.Unlock()
+	// the lock keeps the ledgers from being rolled back or rebuilt by another
+	// process, such as the peer node commands, while they are in use
+	fileLock = leveldbhelper.NewFileLock(ledgerconfig.GetFileLockPath())
+	if err := fileLock.Lock(); err != nil {
+		panic(fmt.Errorf("Error in locking the ledgers, is another peer running? %s", err))
+	}
 	initialized = true
 	openedLedgers = make(map[string]ledger.PeerLedger)
 	provider, err := kvledger.NewProvider()
 	if err != nil {
 		panic(fmt.Errorf("Error in instantiating ledger provider: %s", err))
 	}
+	for _, listener := range stateListeners {
+		if err := provider.RegisterStateListener(listener); err != nil {
+			panic(fmt.Errorf("Error in registering state listener: %s", err))
+		}
+	}
 	ledgerProvider = provider
 	logger.Info("ledger mgmt initialized")
 }
@@ -121,6 +139,37 @@ func GetLedgerIDs() ([]string, error) {
 	return ledgerProvider.List()
 }
 
+// MarkLedgerForDeletion removes the ledger of the given id from the created ledgers. The ledger stays
+// opened, if it is, and its data gets deleted by a subsequent DeleteLedger call or, should that not
+// happen, on the next start
+func MarkLedgerForDeletion(id string) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if !initialized {
+		return ErrLedgerMgmtNotInitialized
+	}
+	logger.Infof("Marking ledger [%s] for deletion", id)
+	return ledgerProvider.MarkForDeletion(id)
+}
+
+// DeleteLedger closes the ledger of the given id, if opened, and deletes it along with all its data
+func DeleteLedger(id string) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if !initialized {
+		return ErrLedgerMgmtNotInitialized
+	}
+	if l, ok := openedLedgers[id]; ok {
+		l.(*closableLedger).closeWithoutLock()
+	}
+	logger.Infof("Deleting ledger [%s]", id)
+	if err := ledgerProvider.Delete(id); err != nil {
+		return err
+	}
+	logger.Infof("Deleted ledger [%s]", id)
+	return nil
+}
+
 // Close closes all the opened ledgers and any resources held for ledger management
 func Close() {
 	logger.Infof("Closing ledger mgmt")
@@ -133,10 +182,85 @@ func Close() {
 		l.(*closableLedger).closeWithoutLock()
 	}
 	ledgerProvider.Close()
+	fileLock.Unlock()
 	openedLedgers = nil
 	logger.Infof("ledger mgmt closed")
 }
 
+// RollbackLedger rolls back the ledger of the given id so that blockNum becomes its last block.
+// The blocks that are removed get pulled again from the ordering service or the other peers
+// once the peer is restarted
+func RollbackLedger(ledgerID string, blockNum uint64) error {
+	lock.Lock()
+	defer lock.Unlock()
+	if openedLedgers != nil {
+		return ErrLedgerMgmtInUse
+	}
+	unlock, err := lockStoppedLedgers()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	logger.Infof("Rolling back ledger [%s] to block [%d]", ledgerID, blockNum)
+	if err = kvledger.RollbackKVLedger(ledgerID, blockNum); err != nil {
+		return err
+	}
+	logger.Infof("Rolled back ledger [%s] to block [%d]", ledgerID, blockNum)
+	return nil
+}
+
+// ResetLedgers rolls back all the ledgers to their genesis block
+func ResetLedgers() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if openedLedgers != nil {
+		return ErrLedgerMgmtInUse
+	}
+	unlock, err := lockStoppedLedgers()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	logger.Info("Resetting all the ledgers to their genesis block")
+	if err = kvledger.ResetAllKVLedgers(); err != nil {
+		return err
+	}
+	logger.Info("Reset all the ledgers to their genesis block")
+	return nil
+}
+
+// RebuildDBs drops and rebuilds the state database and the history database of all
+// the ledgers from their blocks, without validating the transactions again
+func RebuildDBs() error {
+	lock.Lock()
+	defer lock.Unlock()
+	if openedLedgers != nil {
+		return ErrLedgerMgmtInUse
+	}
+	unlock, err := lockStoppedLedgers()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	logger.Info("Rebuilding the state and history databases of all the ledgers")
+	if err = kvledger.RebuildDBs(); err != nil {
+		return err
+	}
+	logger.Info("Rebuilt the state and history databases of all the ledgers")
+	return nil
+}
+
+// lockStoppedLedgers locks the ledgers of a stopped peer. It fails if the ledgers are
+// locked by a running peer, which keeps the LevelDB and block files it uses open
+func lockStoppedLedgers() (unlock func(), err error) {
+	l := leveldbhelper.NewFileLock(ledgerconfig.GetFileLockPath())
+	if err := l.Lock(); err != nil {
+		logger.Errorf("Ledgers are in use, the peer must be stopped: %s", err)
+		return nil, ErrLedgerMgmtInUse
+	}
+	return l.Unlock, nil
+}
+
 func wrapLedger(id string, l ledger.PeerLedger) ledger.PeerLedger {
 	return &closableLedger{id, l}
 }
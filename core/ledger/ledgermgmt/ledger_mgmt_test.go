@@ -24,7 +24,9 @@ import (
 
 	"github.com/hyperledger/fabric/common/configtx/test"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/ledger/util/leveldbhelper"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/spf13/viper"
 )
 
@@ -91,6 +93,97 @@ func TestLedgerMgmt(t *testing.T) {
 	Close()
 }
 
+func TestRollbackLedger(t *testing.T) {
+	InitializeTestEnv()
+	defer CleanupTestEnv()
+
+	bg, gb := testutil.NewBlockGenerator(t, constructTestLedgerID(0), false)
+	l, err := CreateLedger(gb)
+	testutil.AssertNoError(t, err, "")
+	for _, b := range bg.NextTestBlocks(5) {
+		testutil.AssertNoError(t, l.Commit(b), "")
+	}
+
+	// ledgers cannot be rolled back while in use
+	testutil.AssertEquals(t, RollbackLedger(constructTestLedgerID(0), 2), ErrLedgerMgmtInUse)
+	testutil.AssertEquals(t, ResetLedgers(), ErrLedgerMgmtInUse)
+	// nor while another process, such as a running peer, locks them
+	testutil.AssertError(t, leveldbhelper.NewFileLock(ledgerconfig.GetFileLockPath()).Lock(), "")
+	Close()
+	peerLock := leveldbhelper.NewFileLock(ledgerconfig.GetFileLockPath())
+	testutil.AssertNoError(t, peerLock.Lock(), "")
+	testutil.AssertEquals(t, RollbackLedger(constructTestLedgerID(0), 2), ErrLedgerMgmtInUse)
+	testutil.AssertEquals(t, ResetLedgers(), ErrLedgerMgmtInUse)
+	testutil.AssertEquals(t, RebuildDBs(), ErrLedgerMgmtInUse)
+	peerLock.Unlock()
+
+	testutil.AssertNoError(t, RollbackLedger(constructTestLedgerID(0), 2), "")
+	initialize(nil)
+	l, err = OpenLedger(constructTestLedgerID(0))
+	testutil.AssertNoError(t, err, "")
+	bcInfo, _ := l.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(3))
+	Close()
+
+	testutil.AssertNoError(t, ResetLedgers(), "")
+	initialize(nil)
+	l, err = OpenLedger(constructTestLedgerID(0))
+	testutil.AssertNoError(t, err, "")
+	bcInfo, _ = l.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(1))
+}
+
+func TestRebuildDBs(t *testing.T) {
+	InitializeTestEnv()
+	defer CleanupTestEnv()
+
+	bg, gb := testutil.NewBlockGenerator(t, constructTestLedgerID(0), false)
+	l, err := CreateLedger(gb)
+	testutil.AssertNoError(t, err, "")
+	for _, b := range bg.NextTestBlocks(3) {
+		testutil.AssertNoError(t, l.Commit(b), "")
+	}
+
+	testutil.AssertEquals(t, RebuildDBs(), ErrLedgerMgmtInUse)
+	Close()
+
+	testutil.AssertNoError(t, RebuildDBs(), "")
+	initialize(nil)
+	l, err = OpenLedger(constructTestLedgerID(0))
+	testutil.AssertNoError(t, err, "")
+	bcInfo, _ := l.GetBlockchainInfo()
+	testutil.AssertEquals(t, bcInfo.Height, uint64(4))
+}
+
+func TestDeleteLedger(t *testing.T) {
+	InitializeTestEnv()
+	defer CleanupTestEnv()
+
+	ledgers := make([]ledger.PeerLedger, 2)
+	for i := 0; i < 2; i++ {
+		gb, _ := test.MakeGenesisBlock(constructTestLedgerID(i))
+		l, err := CreateLedger(gb)
+		testutil.AssertNoError(t, err, "")
+		ledgers[i] = l
+	}
+
+	// an opened ledger is closed before getting deleted
+	testutil.AssertNoError(t, DeleteLedger(constructTestLedgerID(0)), "")
+	ids, _ := GetLedgerIDs()
+	testutil.AssertEquals(t, ids, []string{constructTestLedgerID(1)})
+	_, err := OpenLedger(constructTestLedgerID(0))
+	testutil.AssertError(t, err, "the ledger should have been deleted")
+	testutil.AssertError(t, DeleteLedger(constructTestLedgerID(0)), "the ledger should have been deleted")
+
+	// a ledger marked for deletion stays usable until it gets deleted
+	testutil.AssertNoError(t, MarkLedgerForDeletion(constructTestLedgerID(1)), "")
+	ids, _ = GetLedgerIDs()
+	testutil.AssertEquals(t, len(ids), 0)
+	_, err = ledgers[1].GetBlockchainInfo()
+	testutil.AssertNoError(t, err, "")
+	testutil.AssertNoError(t, DeleteLedger(constructTestLedgerID(1)), "")
+}
+
 func constructTestLedgerID(i int) string {
 	return fmt.Sprintf("ledger_%06d", i)
 }
@@ -0,0 +1,107 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledgerutil
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	ledgerUtil "github.com/hyperledger/fabric/core/ledger/util"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// Comparison is the result of the comparison of the ledgers of a channel on two peers
+type Comparison struct {
+	// Height and OtherHeight are the number of blocks compared in each ledger
+	Height      uint64
+	OtherHeight uint64
+	// Divergence describes the first block that differs between the ledgers.
+	// It is empty when the shorter ledger is identical to the start of the other one
+	Divergence string
+}
+
+// CompareLedgers compares the ledgers of the given channel in two block store directories, such as the
+// ledgersData/chains directory of a peer and a copy of the one of another peer. The blocks are compared
+// one after the other until they differ in their header or in the validation codes of their transactions
+func CompareLedgers(ledgerID, blockStorageDir, otherBlockStorageDir string) (*Comparison, error) {
+	scanner, err := fsblkstorage.NewBlockScanner(blockStorageDir, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+	otherScanner, err := fsblkstorage.NewBlockScanner(otherBlockStorageDir, ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	defer otherScanner.Close()
+
+	comparison := &Comparison{}
+	for {
+		block, err := scanner.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading block [%d] from [%s]: %s", comparison.Height, blockStorageDir, err)
+		}
+		otherBlock, err := otherScanner.Next()
+		if err != nil {
+			return nil, fmt.Errorf("Error reading block [%d] from [%s]: %s", comparison.OtherHeight, otherBlockStorageDir, err)
+		}
+		if block == nil || otherBlock == nil {
+			if block != nil {
+				comparison.Height += 1 + countBlocks(scanner)
+			}
+			if otherBlock != nil {
+				comparison.OtherHeight += 1 + countBlocks(otherScanner)
+			}
+			return comparison, nil
+		}
+		comparison.Height++
+		comparison.OtherHeight++
+		if comparison.Divergence = compareBlocks(block, otherBlock); comparison.Divergence != "" {
+			return comparison, nil
+		}
+	}
+}
+
+// countBlocks returns the number of blocks left to read, up to the first one that cannot be read
+func countBlocks(scanner *fsblkstorage.BlockScanner) uint64 {
+	count := uint64(0)
+	for block, err := scanner.Next(); block != nil && err == nil; block, err = scanner.Next() {
+		count++
+	}
+	return count
+}
+
+// compareBlocks describes how the two blocks differ, if they do
+func compareBlocks(block, otherBlock *common.Block) string {
+	blockNum := block.Header.Number
+	if !bytes.Equal(block.Header.Hash(), otherBlock.Header.Hash()) {
+		return fmt.Sprintf("Block [%d] differs: the header hashes are [%x] and [%x]", blockNum, block.Header.Hash(), otherBlock.Header.Hash())
+	}
+	txsFilter := ledgerUtil.TxValidationFlags(block.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	otherTxsFilter := ledgerUtil.TxValidationFlags(otherBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER])
+	if len(txsFilter) != len(otherTxsFilter) {
+		return fmt.Sprintf("Block [%d] has the same header but validation codes for %d and %d transactions", blockNum, len(txsFilter), len(otherTxsFilter))
+	}
+	for txNum := range txsFilter {
+		if txsFilter.Flag(txNum) != otherTxsFilter.Flag(txNum) {
+			return fmt.Sprintf("Block [%d] has the same header but transaction [%d] was validated as [%s] and [%s]",
+				blockNum, txNum, txsFilter.Flag(txNum), otherTxsFilter.Flag(txNum))
+		}
+	}
+	return ""
+}
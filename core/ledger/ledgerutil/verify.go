@@ -0,0 +1,196 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package ledgerutil provides offline tools to check the ledger of a channel,
+// which are meant to be used while the peer is stopped
+package ledgerutil
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/hyperledger/fabric/common/configtx"
+	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/policies"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/version"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+var logger = flogging.MustGetLogger("ledgerutil")
+
+// Report is the result of the verification of the ledger of a channel
+type Report struct {
+	LedgerID string
+	// Height is the number of blocks read from the block files
+	Height uint64
+	// Mismatches describes the inconsistencies found, there is none when the ledger is sound
+	Mismatches []string
+}
+
+// VerifyLedger verifies the ledger of the given channel. The blocks are read from the block files and
+// each of them must be chained to the previous one through its header, match the hash of its data and
+// be signed according to the block validation policy of the channel config in force. The entries of the
+// block index must point to the blocks and transactions in the files, and the savepoints of the state
+// and history databases must match the last block. The peer must be stopped
+func VerifyLedger(ledgerID string) (*Report, error) {
+	report := &Report{LedgerID: ledgerID}
+	v := &chainVerifier{report: report}
+	logger.Infof("Verifying the blocks of ledger [%s]", ledgerID)
+	blockStoreMismatches, err := fsblkstorage.VerifyBlockStore(ledgerconfig.GetBlockStorePath(), ledgerID,
+		kvledger.BlockStoreIndexConfig(), v.verifyBlock)
+	if err != nil {
+		return nil, err
+	}
+	report.Mismatches = append(report.Mismatches, blockStoreMismatches...)
+
+	logger.Infof("Verifying the savepoints of the databases of ledger [%s]", ledgerID)
+	stateSavepoint, historySavepoint, err := kvledger.GetDBSavepoints(ledgerID)
+	if err != nil {
+		return nil, err
+	}
+	verifySavepoint(report, "state", stateSavepoint)
+	if ledgerconfig.IsHistoryDBEnabled() {
+		verifySavepoint(report, "history", historySavepoint)
+	}
+	return report, nil
+}
+
+func (r *Report) addMismatch(format string, args ...interface{}) {
+	r.Mismatches = append(r.Mismatches, fmt.Sprintf(format, args...))
+}
+
+// verifySavepoint checks that the last block committed to a database is the last block of the block store
+func verifySavepoint(report *Report, dbName string, savepoint *version.Height) {
+	switch {
+	case savepoint == nil && report.Height > 0:
+		report.addMismatch("The %s database has no savepoint while the block store ends with block [%d]", dbName, report.Height-1)
+	case savepoint != nil && report.Height == 0:
+		report.addMismatch("The %s database savepoint is block [%d] while the block store is empty", dbName, savepoint.BlockNum)
+	case savepoint != nil && savepoint.BlockNum != report.Height-1:
+		report.addMismatch("The %s database savepoint is block [%d] while the block store ends with block [%d]",
+			dbName, savepoint.BlockNum, report.Height-1)
+	}
+}
+
+// chainVerifier verifies the blocks one after the other
+type chainVerifier struct {
+	report         *Report
+	previousHeader *common.BlockHeader
+	// configManager holds the channel config in force, it is nil
+	// when the config of the genesis block could not be loaded
+	configManager   configtxapi.Manager
+	lastConfigBlock uint64
+}
+
+func (v *chainVerifier) verifyBlock(block *common.Block) error {
+	blockNum := block.Header.Number
+	if v.previousHeader != nil && !bytes.Equal(block.Header.PreviousHash, v.previousHeader.Hash()) {
+		v.report.addMismatch("Block [%d] records the previous hash [%x] while the hash of block [%d] is [%x]",
+			blockNum, block.Header.PreviousHash, v.previousHeader.Number, v.previousHeader.Hash())
+	}
+	if block.Data == nil || !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		v.report.addMismatch("Block [%d] records the data hash [%x] which does not match its data", blockNum, block.Header.DataHash)
+	}
+	// the genesis block is not signed
+	if blockNum > 0 && v.configManager != nil {
+		v.verifySignatures(block)
+	}
+
+	isConfigBlock := utils.IsConfigBlock(block)
+	if isConfigBlock {
+		v.lastConfigBlock = blockNum
+	}
+	if lastConfigBlock, err := utils.GetLastConfigIndexFromBlock(block); err != nil {
+		v.report.addMismatch("Block [%d] has no valid last config metadata: %s", blockNum, err)
+	} else if lastConfigBlock != v.lastConfigBlock {
+		v.report.addMismatch("Block [%d] records block [%d] as the last config block while it is block [%d]",
+			blockNum, lastConfigBlock, v.lastConfigBlock)
+	}
+	if isConfigBlock {
+		v.applyConfig(block)
+	}
+
+	v.previousHeader = block.Header
+	v.report.Height++
+	return nil
+}
+
+// verifySignatures evaluates the block validation policy of the channel config in force against
+// the signatures of the block, the same way the peer does when it receives the block
+func (v *chainVerifier) verifySignatures(block *common.Block) {
+	blockNum := block.Header.Number
+	metadata, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		v.report.addMismatch("Signatures of block [%d] cannot be decoded: %s", blockNum, err)
+		return
+	}
+	if len(metadata.Signatures) == 0 {
+		v.report.addMismatch("Block [%d] is not signed", blockNum)
+		return
+	}
+	signatureSet := []*common.SignedData{}
+	for _, metadataSignature := range metadata.Signatures {
+		shdr, err := utils.GetSignatureHeader(metadataSignature.SignatureHeader)
+		if err != nil {
+			v.report.addMismatch("Signature header of block [%d] cannot be decoded: %s", blockNum, err)
+			return
+		}
+		signatureSet = append(signatureSet, &common.SignedData{
+			Identity:  shdr.Creator,
+			Data:      util.ConcatenateBytes(metadata.Value, metadataSignature.SignatureHeader, block.Header.Bytes()),
+			Signature: metadataSignature.Signature,
+		})
+	}
+	policy, _ := v.configManager.PolicyManager().GetPolicy(policies.BlockValidation)
+	if err = policy.Evaluate(signatureSet); err != nil {
+		v.report.addMismatch("Signatures of block [%d] do not satisfy the block validation policy: %s", blockNum, err)
+	}
+}
+
+// applyConfig makes the config of the block the one in force for the following blocks
+func (v *chainVerifier) applyConfig(block *common.Block) {
+	blockNum := block.Header.Number
+	envelope, err := utils.ExtractEnvelope(block, 0)
+	if err != nil {
+		v.report.addMismatch("Config transaction of block [%d] cannot be decoded: %s", blockNum, err)
+		return
+	}
+	if v.configManager == nil {
+		if v.configManager, err = configtx.NewManagerImpl(envelope, configtx.NewInitializer(), nil); err != nil {
+			v.report.addMismatch("Config of block [%d] cannot be loaded, the block signatures are not verified: %s", blockNum, err)
+		}
+		return
+	}
+	payload, err := utils.GetPayload(envelope)
+	if err != nil {
+		v.report.addMismatch("Config transaction of block [%d] cannot be decoded: %s", blockNum, err)
+		return
+	}
+	configEnvelope, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		v.report.addMismatch("Config transaction of block [%d] cannot be decoded: %s", blockNum, err)
+		return
+	}
+	if err = v.configManager.Apply(configEnvelope); err != nil {
+		v.report.addMismatch("Config of block [%d] cannot be applied to the config in force: %s", blockNum, err)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ledgerutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hyperledger/fabric/common/ledger/blkstorage/fsblkstorage"
+	"github.com/hyperledger/fabric/common/ledger/testutil"
+	"github.com/hyperledger/fabric/common/localmsp"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/core/ledger/kvledger"
+	"github.com/hyperledger/fabric/core/ledger/kvledger/txmgmt/statedb"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	ledgertestutil "github.com/hyperledger/fabric/core/ledger/testutil"
+	lutil "github.com/hyperledger/fabric/core/ledger/util"
+	msptesttools "github.com/hyperledger/fabric/msp/mgmt/testtools"
+	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	ledgertestutil.SetupCoreYAMLConfig()
+	viper.Set("peer.fileSystemPath", "/tmp/fabric/ledgertests/ledgerutil")
+	if err := msptesttools.LoadMSPSetupForTesting(); err != nil {
+		panic(fmt.Sprintf("Could not load the msp setup for testing: %s", err))
+	}
+	os.Exit(m.Run())
+}
+
+// signBlock adds the last config and signature metadata of the orderer to the block
+func signBlock(t *testing.T, block *common.Block, lastConfigBlock uint64) {
+	signer := localmsp.NewSigner()
+	block.Metadata.Metadata[common.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&common.Metadata{
+		Value: utils.MarshalOrPanic(&common.LastConfig{Index: lastConfigBlock}),
+	})
+	signatureHeader, err := signer.NewSignatureHeader()
+	assert.NoError(t, err)
+	blockSignature := &common.MetadataSignature{SignatureHeader: utils.MarshalOrPanic(signatureHeader)}
+	blockSignature.Signature, err = signer.Sign(util.ConcatenateBytes(nil, blockSignature.SignatureHeader, block.Header.Bytes()))
+	assert.NoError(t, err)
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&common.Metadata{
+		Signatures: []*common.MetadataSignature{blockSignature},
+	})
+}
+
+// createTestLedger commits a genesis block and numBlocks signed blocks to the ledger
+func createTestLedger(t *testing.T, ledgerID string, numBlocks int) []*common.Block {
+	provider, err := kvledger.NewProvider()
+	assert.NoError(t, err)
+	defer provider.Close()
+	bg, gb := testutil.NewBlockGenerator(t, ledgerID, false)
+	l, err := provider.Create(gb)
+	assert.NoError(t, err)
+	defer l.Close()
+	blocks := []*common.Block{gb}
+	for _, block := range bg.NextTestBlocks(numBlocks) {
+		signBlock(t, block, 0)
+		assert.NoError(t, l.Commit(block))
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+func TestVerifyLedger(t *testing.T) {
+	defer os.RemoveAll(ledgerconfig.GetRootPath())
+	createTestLedger(t, "ledger1", 5)
+
+	report, err := VerifyLedger("ledger1")
+	assert.NoError(t, err)
+	assert.Equal(t, &Report{LedgerID: "ledger1", Height: 6}, report)
+
+	// the savepoint of the state database no longer matches the block store
+	vdbProvider, err := statedb.NewVersionedDBProvider(ledgerconfig.GetStateDatabase())
+	assert.NoError(t, err)
+	assert.NoError(t, vdbProvider.Drop("ledger1"))
+	vdbProvider.Close()
+	report, err = VerifyLedger("ledger1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"The state database has no savepoint while the block store ends with block [5]"}, report.Mismatches)
+
+	_, err = VerifyLedger("nonexistent")
+	assert.Error(t, err)
+}
+
+func TestVerifyBlocks(t *testing.T) {
+	bg, gb := testutil.NewBlockGenerator(t, "ledger1", false)
+	blocks := bg.NextTestBlocks(4)
+	for _, block := range blocks[:3] {
+		signBlock(t, block, 0)
+	}
+	// the data of block 2 gets altered and block 4 is not signed
+	blocks[1].Data.Data[0] = []byte("altered data")
+	blocks[3].Metadata.Metadata[common.BlockMetadataIndex_LAST_CONFIG] = utils.MarshalOrPanic(&common.Metadata{
+		Value: utils.MarshalOrPanic(&common.LastConfig{Index: 3}),
+	})
+	// block 3 is signed but not chained to block 2
+	blocks[2].Header.PreviousHash = []byte("wrong hash")
+	signBlock(t, blocks[2], 0)
+
+	report := &Report{}
+	v := &chainVerifier{report: report}
+	for _, block := range append([]*common.Block{gb}, blocks...) {
+		assert.NoError(t, v.verifyBlock(block))
+	}
+	assert.Equal(t, uint64(5), report.Height)
+	assert.Len(t, report.Mismatches, 5)
+	assert.Contains(t, report.Mismatches[0], "Block [2] records the data hash")
+	assert.Contains(t, report.Mismatches[1], "Block [3] records the previous hash [77726f6e672068617368] while the hash of block [2] is")
+	assert.Contains(t, report.Mismatches[2], "Block [4] records the previous hash")
+	assert.Equal(t, "Block [4] is not signed", report.Mismatches[3])
+	assert.Equal(t, "Block [4] records block [3] as the last config block while it is block [0]", report.Mismatches[4])
+
+	// a signature of an identity that does not belong to the orderer org
+	block := bg.NextTestBlock(1, 10)
+	signBlock(t, block, 0)
+	metadata, _ := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_SIGNATURES)
+	metadata.Signatures[0].Signature = []byte("bad signature")
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+	assert.NoError(t, v.verifyBlock(block))
+	assert.Len(t, report.Mismatches, 6)
+	assert.Contains(t, report.Mismatches[5], "Signatures of block [5] do not satisfy the block validation policy")
+}
+
+func TestCompareLedgers(t *testing.T) {
+	defer os.RemoveAll(ledgerconfig.GetRootPath())
+	blocks := createTestLedger(t, "ledger1", 5)
+	otherDir := filepath.Join(ledgerconfig.GetRootPath(), "otherPeer")
+
+	// the other peer has the first blocks only
+	addBlocks(t, otherDir, "ledger1", blocks[:4])
+	comparison, err := CompareLedgers("ledger1", ledgerconfig.GetBlockStorePath(), otherDir)
+	assert.NoError(t, err)
+	assert.Equal(t, &Comparison{Height: 6, OtherHeight: 4}, comparison)
+
+	// and it validated a transaction of block 3 differently. The test transactions
+	// are not valid endorser transactions, so the ledger marked them all invalid
+	otherBlock := *blocks[3]
+	otherBlock.Metadata = &common.BlockMetadata{Metadata: append([][]byte{}, blocks[3].Metadata.Metadata...)}
+	txsFilter := append(lutil.TxValidationFlags{}, blocks[3].Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER]...)
+	txsFilter.SetFlag(2, peer.TxValidationCode_MVCC_READ_CONFLICT)
+	otherBlock.Metadata.Metadata[common.BlockMetadataIndex_TRANSACTIONS_FILTER] = txsFilter
+	os.RemoveAll(otherDir)
+	addBlocks(t, otherDir, "ledger1", append(append([]*common.Block{}, blocks[:3]...), &otherBlock, blocks[4]))
+	comparison, err = CompareLedgers("ledger1", ledgerconfig.GetBlockStorePath(), otherDir)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), comparison.Height)
+	assert.Equal(t, "Block [3] has the same header but transaction [2] was validated as "+
+		"[INVALID_OTHER_REASON] and [MVCC_READ_CONFLICT]", comparison.Divergence)
+
+	_, err = CompareLedgers("ledger1", ledgerconfig.GetBlockStorePath(), filepath.Join(otherDir, "nonexistent"))
+	assert.Error(t, err)
+}
+
+func addBlocks(t *testing.T, blockStorageDir, ledgerID string, blocks []*common.Block) {
+	provider := fsblkstorage.NewProvider(fsblkstorage.NewConf(blockStorageDir, 0), kvledger.BlockStoreIndexConfig())
+	defer provider.Close()
+	store, err := provider.OpenBlockStore(ledgerID)
+	assert.NoError(t, err)
+	defer store.Shutdown()
+	for _, block := range blocks {
+		assert.NoError(t, store.AddBlock(block))
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statelistener
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/core/ledger"
+)
+
+var logger = flogging.MustGetLogger("statelistener")
+
+// JSONLinesListenerName is the name of the JSON lines listener, under which its checkpoints are persisted
+const JSONLinesListenerName = "jsonLines"
+
+// Record is a line of the files written by the JSON lines listener.
+// Value is base64 encoded and omitted for a delete
+type Record struct {
+	BlockNum  uint64 `json:"blockNum"`
+	Namespace string `json:"namespace"`
+	Key       string `json:"key"`
+	Value     []byte `json:"value,omitempty"`
+	IsDelete  bool   `json:"isDelete"`
+	Version   struct {
+		BlockNum uint64 `json:"blockNum"`
+		TxNum    uint64 `json:"txNum"`
+	} `json:"version"`
+}
+
+// JSONLinesListener implements interface ledger.StateListener.
+// It appends the state updates of each ledger as JSON records, one per line, to the file <ledgerID>.jsonl
+// under its directory. As the delivery is at-least-once, the updates of a block may appear more than once
+// in a file, in which case the records of the last occurrence of the block supersede the previous ones
+type JSONLinesListener struct {
+	dirPath    string
+	namespaces []string
+}
+
+// NewJSONLinesListener constructs a JSONLinesListener that writes the updates of the given namespaces
+// (all of them if none given) to files under the given directory
+func NewJSONLinesListener(dirPath string, namespaces []string) (*JSONLinesListener, error) {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return nil, err
+	}
+	return &JSONLinesListener{dirPath, namespaces}, nil
+}
+
+// Name implements method in interface ledger.StateListener
+func (l *JSONLinesListener) Name() string {
+	return JSONLinesListenerName
+}
+
+// InterestedInNamespaces implements method in interface ledger.StateListener
+func (l *JSONLinesListener) InterestedInNamespaces() []string {
+	return l.namespaces
+}
+
+// HandleStateUpdates implements method in interface ledger.StateListener.
+// The file is synced before returning, so that the block does not get checkpointed before being persisted
+func (l *JSONLinesListener) HandleStateUpdates(ledgerID string, blockNum uint64, updates ledger.StateUpdates) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	logger.Debugf("Channel [%s]: writing the state updates of block [%d]", ledgerID, blockNum)
+	file, err := os.OpenFile(l.FilePath(ledgerID), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	namespaces := make([]string, 0, len(updates))
+	for ns := range updates {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, ns := range namespaces {
+		for _, update := range updates[ns] {
+			record := &Record{BlockNum: blockNum, Namespace: ns, Key: update.Key, Value: update.Value, IsDelete: update.IsDelete}
+			record.Version.BlockNum = update.BlockNum
+			record.Version.TxNum = update.TxNum
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// FilePath returns the path of the file the updates of the given ledger are written to
+func (l *JSONLinesListener) FilePath(ledgerID string) string {
+	return filepath.Join(l.dirPath, ledgerID+".jsonl")
+}
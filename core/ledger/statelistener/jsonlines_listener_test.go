@@ -0,0 +1,83 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package statelistener
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDirPath = "/tmp/fabric/statelistenertests"
+
+func readRecords(t *testing.T, path string) []*Record {
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	var records []*Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		record := &Record{}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), record))
+		records = append(records, record)
+	}
+	assert.NoError(t, scanner.Err())
+	return records
+}
+
+func TestJSONLinesListener(t *testing.T) {
+	os.RemoveAll(testDirPath)
+	defer os.RemoveAll(testDirPath)
+	listener, err := NewJSONLinesListener(testDirPath, []string{"ns1", "ns2"})
+	assert.NoError(t, err)
+	assert.Equal(t, JSONLinesListenerName, listener.Name())
+	assert.Equal(t, []string{"ns1", "ns2"}, listener.InterestedInNamespaces())
+
+	// a block with no updates does not create the file
+	assert.NoError(t, listener.HandleStateUpdates("ledger1", 0, ledger.StateUpdates{}))
+	_, err = os.Stat(listener.FilePath("ledger1"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, listener.HandleStateUpdates("ledger1", 1, ledger.StateUpdates{
+		"ns2": {{Key: "key1", Value: []byte("value2"), BlockNum: 1, TxNum: 1}},
+		"ns1": {{Key: "key1", Value: []byte("value1"), BlockNum: 1, TxNum: 0}},
+	}))
+	assert.NoError(t, listener.HandleStateUpdates("ledger1", 2, ledger.StateUpdates{
+		"ns1": {{Key: "key1", IsDelete: true, BlockNum: 2, TxNum: 0}},
+	}))
+	assert.NoError(t, listener.HandleStateUpdates("ledger2", 1, ledger.StateUpdates{
+		"ns1": {{Key: "key3", Value: []byte("value3"), BlockNum: 1, TxNum: 0}},
+	}))
+
+	records := readRecords(t, listener.FilePath("ledger1"))
+	assert.Len(t, records, 3)
+	assert.Equal(t, "ns1", records[0].Namespace)
+	assert.Equal(t, []byte("value1"), records[0].Value)
+	assert.Equal(t, "ns2", records[1].Namespace)
+	assert.Equal(t, uint64(1), records[1].Version.TxNum)
+	assert.Equal(t, uint64(2), records[2].BlockNum)
+	assert.True(t, records[2].IsDelete)
+	assert.Nil(t, records[2].Value)
+
+	records = readRecords(t, listener.FilePath("ledger2"))
+	assert.Len(t, records, 1)
+	assert.Equal(t, "key3", records[0].Key)
+}
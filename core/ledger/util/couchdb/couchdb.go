@@ -207,6 +207,31 @@ type Base64Attachment struct {
 	AttachmentData string `json:"data"`
 }
 
+//IndexResult contains the definition for a couchdb index
+type IndexResult struct {
+	DesignDocument string `json:"designdoc"`
+	Name           string `json:"name"`
+	Definition     string `json:"definition"`
+}
+
+//CreateIndexResponse contains the response of a create index request
+type CreateIndexResponse struct {
+	Result string `json:"result"`
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+}
+
+//listIndexResponse is used for processing REST responses to a list index request
+type listIndexResponse struct {
+	TotalRows int `json:"total_rows"`
+	Indexes   []struct {
+		DesignDocument string          `json:"ddoc"`
+		Name           string          `json:"name"`
+		Type           string          `json:"type"`
+		Definition     json.RawMessage `json:"def"`
+	} `json:"indexes"`
+}
+
 // closeResponseBody discards the body and then closes it to enable returning it to
 // connection pool
 func closeResponseBody(resp *http.Response) {
@@ -1164,6 +1189,145 @@ func (dbclient *CouchDatabase) BatchUpdateDocuments(documents []*CouchDoc) ([]*B
 
 }
 
+//ListIndex method lists the defined indexes for a database
+func (dbclient *CouchDatabase) ListIndex() ([]*IndexResult, error) {
+
+	logger.Debugf("Entering ListIndex()")
+
+	indexURL, err := url.Parse(dbclient.CouchInstance.conf.URL)
+	if err != nil {
+		logger.Errorf("URL parse error: %s", err.Error())
+		return nil, err
+	}
+
+	indexURL.Path = dbclient.DBName + "/_index/"
+
+	//get the number of retries
+	maxRetries := dbclient.CouchInstance.conf.MaxRetries
+
+	resp, _, err := dbclient.CouchInstance.handleRequest(http.MethodGet, indexURL.String(), nil, "", "", maxRetries, true)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+
+	//handle as JSON document
+	jsonResponseRaw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonResponse = &listIndexResponse{}
+
+	err = json.Unmarshal(jsonResponseRaw, jsonResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*IndexResult
+
+	for _, row := range jsonResponse.Indexes {
+
+		//the special index on _id is always defined and cannot be dropped, skip it
+		if row.Type == "special" {
+			continue
+		}
+
+		//the design document is returned as _design/<ddoc>, strip the prefix
+		designDoc := strings.TrimPrefix(row.DesignDocument, "_design/")
+
+		results = append(results, &IndexResult{DesignDocument: designDoc, Name: row.Name, Definition: string(row.Definition)})
+	}
+
+	logger.Debugf("Exiting ListIndex()")
+
+	return results, nil
+
+}
+
+//CreateIndex method provides a function creating an index
+//The index definition is in the format expected by the CouchDB _index API, for example
+//{"index":{"fields":["size"]},"ddoc":"indexSizeDoc","name":"indexSize","type":"json"}
+//Creating an index that already exists is not an error, the result of the response is "exists"
+func (dbclient *CouchDatabase) CreateIndex(indexdefinition string) (*CreateIndexResponse, error) {
+
+	logger.Debugf("Entering CreateIndex()  indexdefinition=%s", indexdefinition)
+
+	//Test to see if this is a valid JSON
+	if IsJSON(indexdefinition) != true {
+		return nil, fmt.Errorf("JSON format is not valid")
+	}
+
+	indexURL, err := url.Parse(dbclient.CouchInstance.conf.URL)
+	if err != nil {
+		logger.Errorf("URL parse error: %s", err.Error())
+		return nil, err
+	}
+
+	indexURL.Path = dbclient.DBName + "/_index"
+
+	//get the number of retries
+	maxRetries := dbclient.CouchInstance.conf.MaxRetries
+
+	resp, _, err := dbclient.CouchInstance.handleRequest(http.MethodPost, indexURL.String(), []byte(indexdefinition), "", "", maxRetries, true)
+	if err != nil {
+		return nil, err
+	}
+	defer closeResponseBody(resp)
+
+	//handle as JSON document
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	couchDBReturn := &CreateIndexResponse{}
+
+	err = json.Unmarshal(respBody, couchDBReturn)
+	if err != nil {
+		return nil, err
+	}
+
+	if couchDBReturn.Result == "created" {
+		logger.Infof("Created CouchDB index [%s] in state database [%s] using design document [%s]", couchDBReturn.Name, dbclient.DBName, couchDBReturn.ID)
+	} else {
+		logger.Infof("CouchDB index [%s] already exists in state database [%s]", couchDBReturn.Name, dbclient.DBName)
+	}
+
+	logger.Debugf("Exiting CreateIndex()")
+
+	return couchDBReturn, nil
+
+}
+
+//DeleteIndex method provides a function deleting an index
+func (dbclient *CouchDatabase) DeleteIndex(designdoc, indexname string) error {
+
+	logger.Debugf("Entering DeleteIndex()  designdoc=%s  indexname=%s", designdoc, indexname)
+
+	indexURL, err := url.Parse(dbclient.CouchInstance.conf.URL)
+	if err != nil {
+		logger.Errorf("URL parse error: %s", err.Error())
+		return err
+	}
+
+	indexURL.Path = dbclient.DBName + "/_index/" + designdoc + "/json/" + indexname
+
+	//get the number of retries
+	maxRetries := dbclient.CouchInstance.conf.MaxRetries
+
+	resp, _, err := dbclient.CouchInstance.handleRequest(http.MethodDelete, indexURL.String(), nil, "", "", maxRetries, true)
+	if err != nil {
+		return err
+	}
+	defer closeResponseBody(resp)
+
+	logger.Debugf("Exiting DeleteIndex()")
+
+	return nil
+
+}
+
 //handleRequestWithRevisionRetry method is a generic http request handler with
 //a retry for document revision conflict errors,
 //which may be detected during saves or deletes that timed out from client http perspective,
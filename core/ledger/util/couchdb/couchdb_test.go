@@ -846,6 +846,78 @@ func TestDBDeleteNonExistingDocument(t *testing.T) {
 	}
 }
 
+func TestIndexOperations(t *testing.T) {
+
+	if ledgerconfig.IsCouchDBEnabled() {
+
+		database := "testindexoperations"
+		err := cleanup(database)
+		testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to cleanup  Error: %s", err))
+		defer cleanup(database)
+
+		if err == nil {
+			//create a new instance and database object
+			couchInstance, err := CreateCouchInstance(couchDBDef.URL, couchDBDef.Username, couchDBDef.Password,
+				couchDBDef.MaxRetries, couchDBDef.MaxRetriesOnStartup, couchDBDef.RequestTimeout)
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create couch instance"))
+			db := CouchDatabase{CouchInstance: *couchInstance, DBName: database}
+
+			//create a new database
+			_, errdb := db.CreateDatabaseIfNotExist()
+			testutil.AssertNoError(t, errdb, fmt.Sprintf("Error when trying to create database"))
+
+			//no index is defined on a new database
+			indexes, err := db.ListIndex()
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to list indexes"))
+			testutil.AssertEquals(t, len(indexes), 0)
+
+			indexDefSize := `{"index":{"fields":[{"size":"desc"}]},"ddoc":"indexSizeSortDoc","name":"indexSizeSortName","type":"json"}`
+			resp, err := db.CreateIndex(indexDefSize)
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create an index"))
+			testutil.AssertEquals(t, resp.Result, "created")
+			testutil.AssertEquals(t, resp.Name, "indexSizeSortName")
+
+			//creating the same index again is not an error
+			resp, err = db.CreateIndex(indexDefSize)
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create an existing index"))
+			testutil.AssertEquals(t, resp.Result, "exists")
+
+			_, err = db.CreateIndex(`{"index":{"fields":["color"]},"ddoc":"indexColorDoc","name":"indexColorName","type":"json"}`)
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to create an index"))
+
+			//an invalid definition is rejected
+			_, err = db.CreateIndex(`{"index":{"fields":["color"]`)
+			testutil.AssertError(t, err, fmt.Sprintf("Error should have been thrown for an invalid index definition"))
+
+			indexes, err = db.ListIndex()
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to list indexes"))
+			testutil.AssertEquals(t, len(indexes), 2)
+			for _, index := range indexes {
+				switch index.Name {
+				case "indexSizeSortName":
+					testutil.AssertEquals(t, index.DesignDocument, "indexSizeSortDoc")
+				case "indexColorName":
+					testutil.AssertEquals(t, index.DesignDocument, "indexColorDoc")
+				default:
+					t.Fatalf("Unexpected index %s", index.Name)
+				}
+			}
+
+			err = db.DeleteIndex("indexSizeSortDoc", "indexSizeSortName")
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to delete an index"))
+
+			indexes, err = db.ListIndex()
+			testutil.AssertNoError(t, err, fmt.Sprintf("Error when trying to list indexes"))
+			testutil.AssertEquals(t, len(indexes), 1)
+			testutil.AssertEquals(t, indexes[0].Name, "indexColorName")
+
+			//deleting a missing index fails
+			err = db.DeleteIndex("indexSizeSortDoc", "indexSizeSortName")
+			testutil.AssertError(t, err, fmt.Sprintf("Error should have been thrown when deleting a missing index"))
+		}
+	}
+}
+
 func TestCouchDBVersion(t *testing.T) {
 
 	err := checkCouchDBVersion("2.0.0")
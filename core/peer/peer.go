@@ -256,6 +256,30 @@ func CreateChainFromBlock(cb *common.Block) error {
 	return createChain(cid, l, cb)
 }
 
+// LeaveChain makes the peer leave the chain. The ledger of the chain is marked for deletion
+// first, then the gossip and the blocks delivery of the chain are stopped and the ledger is
+// closed and deleted along with all its data. Once the ledger is marked, the leave completes
+// on the next start should the peer crash or the deletion fail
+func LeaveChain(cid string) error {
+	chains.Lock()
+	if _, ok := chains.list[cid]; !ok {
+		chains.Unlock()
+		return fmt.Errorf("Unknown chain ID, %s", cid)
+	}
+	if err := ledgermgmt.MarkLedgerForDeletion(cid); err != nil {
+		chains.Unlock()
+		return fmt.Errorf("Cannot mark the ledger of chain %s for deletion, due to %s", cid, err)
+	}
+	delete(chains.list, cid)
+	chains.Unlock()
+
+	service.GetGossipService().LeaveChannel(cid)
+	if err := ledgermgmt.DeleteLedger(cid); err != nil {
+		return fmt.Errorf("Cannot delete the ledger of chain %s, the deletion completes on the next start, due to %s", cid, err)
+	}
+	return nil
+}
+
 // MockCreateChain used for creating a ledger for a chain for tests
 // without havin to join
 func MockCreateChain(cid string) error {
@@ -504,12 +528,15 @@ func GetLocalIP() string {
 	return ""
 }
 
-// NewPeerClientConnectionWithAddress Returns a new grpc.ClientConn to the configured local PEER.
+// NewPeerClientConnectionWithAddress Returns a grpc.ClientConn to the given PEER.
+// The connection is taken from the pool of connections shared by the clients of
+// this process, it is kept for the lifetime of the process and must not be closed.
 func NewPeerClientConnectionWithAddress(peerAddress string) (*grpc.ClientConn, error) {
-	if comm.TLSEnabled() {
-		return comm.NewClientConnectionWithAddress(peerAddress, true, true, comm.InitTLSForPeer())
+	pc, err := comm.PeerConnectionPool().Acquire(peerAddress)
+	if err != nil {
+		return nil, err
 	}
-	return comm.NewClientConnectionWithAddress(peerAddress, true, false, nil)
+	return pc.ClientConn, nil
 }
 
 // GetChannelsInfo returns an array with information about all channels for
@@ -38,6 +38,7 @@ import (
 	"github.com/hyperledger/fabric/msp/mgmt/testtools"
 	peergossip "github.com/hyperledger/fabric/peer/gossip"
 	"github.com/hyperledger/fabric/peer/gossip/mocks"
+	"github.com/hyperledger/fabric/protos/common"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
@@ -58,6 +59,11 @@ func (ds *mockDeliveryClient) StopDeliverForChannel(chainID string) error {
 	return nil
 }
 
+// FetchBlocks retrieves blocks of a channel directly from the ordering service
+func (ds *mockDeliveryClient) FetchBlocks(chainID string, start uint64, end uint64) ([]*common.Block, error) {
+	return nil, nil
+}
+
 // Stop terminates delivery service and closes the connection
 func (*mockDeliveryClient) Stop() {
 
@@ -228,6 +234,12 @@ func TestCreateChainFromBlock(t *testing.T) {
 	if len(channels) != 1 {
 		t.Fatalf("incorrect number of channels")
 	}
+
+	// Leave the chain
+	assert.NoError(t, LeaveChain(testChainID), "Failed to leave the chain")
+	assert.Nil(t, GetLedger(testChainID), "the ledger of the chain should be gone")
+	assert.Len(t, GetChannelsInfo(), 0)
+	assert.Error(t, LeaveChain(testChainID), "the chain has already been left")
 }
 
 func TestNewPeerClientConnection(t *testing.T) {
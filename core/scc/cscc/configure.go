@@ -51,6 +51,7 @@ var cnflogger = flogging.MustGetLogger("cscc")
 // These are function names from Invoke first parameter
 const (
 	JoinChain      string = "JoinChain"
+	LeaveChain     string = "LeaveChain"
 	GetConfigBlock string = "GetConfigBlock"
 	GetChannels    string = "GetChannels"
 )
@@ -73,11 +74,12 @@ func (e *PeerConfiger) Init(stub shim.ChaincodeStubInterface) pb.Response {
 
 // Invoke is called for the following:
 // # to process joining a chain (called by app as a transaction proposal)
+// # to process leaving a chain (called by app as a transaction proposal)
 // # to get the current configuration block (called by app)
 // # to update the configuration block (called by commmitter)
 // Peer calls this function with 2 arguments:
-// # args[0] is the function name, which must be JoinChain, GetConfigBlock or
-// UpdateConfigBlock
+// # args[0] is the function name, which must be JoinChain, LeaveChain,
+// GetConfigBlock or UpdateConfigBlock
 // # args[1] is a configuration Block if args[0] is JoinChain or
 // UpdateConfigBlock; otherwise it is the chain id
 // TODO: Improve the scc interface to avoid marshal/unmarshal args
@@ -132,6 +134,14 @@ func (e *PeerConfiger) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		}
 
 		return joinChain(cid, block)
+	case LeaveChain:
+		// 2. check local MSP Admins policy
+		if err = e.policyChecker.CheckPolicyNoChannel(mgmt.Admins, sp); err != nil {
+			return shim.Error(fmt.Sprintf("\"LeaveChain\" request failed authorization check "+
+				"for channel [%s]: [%s]", args[1], err))
+		}
+
+		return leaveChain(string(args[1]))
 	case GetConfigBlock:
 		// 2. check the channel reader policy
 		if err = e.policyChecker.CheckPolicy(string(args[1]), policies.ChannelApplicationReaders, sp); err != nil {
@@ -201,6 +211,18 @@ func joinChain(chainID string, block *common.Block) pb.Response {
 	return shim.Success(nil)
 }
 
+// leaveChain makes the peer leave the specified chain and deletes its ledger
+func leaveChain(chainID string) pb.Response {
+	if chainID == "" {
+		return shim.Error("ChainID must not be empty.")
+	}
+	if err := peer.LeaveChain(chainID); err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(nil)
+}
+
 // Return the current configuration block for the specified chainID. If the
 // peer doesn't belong to the chain, return error
 func getConfigBlock(chainID []byte) pb.Response {
@@ -65,6 +65,11 @@ func (ds *mockDeliveryClient) StopDeliverForChannel(chainID string) error {
 	return nil
 }
 
+// FetchBlocks retrieves blocks of a channel directly from the ordering service
+func (ds *mockDeliveryClient) FetchBlocks(chainID string, start uint64, end uint64) ([]*cb.Block, error) {
+	return nil, nil
+}
+
 // Stop terminates delivery service and closes the connection
 func (*mockDeliveryClient) Stop() {
 
@@ -274,6 +279,30 @@ func TestConfigerInvokeJoinChainCorrectParams(t *testing.T) {
 	if len(cqr.GetChannels()) != 1 {
 		t.FailNow()
 	}
+
+	// LeaveChain must fail authorization with a bad signature
+	args = [][]byte{[]byte(LeaveChain), []byte(chainID)}
+	sProp.Signature = nil
+	res = stub.MockInvokeWithSignedProposal("4", args, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+	assert.True(t, strings.HasPrefix(res.Message, "\"LeaveChain\" request failed authorization check for channel"))
+	sProp.Signature = sProp.ProposalBytes
+
+	// LeaveChain of an unknown channel fails
+	res = stub.MockInvokeWithSignedProposal("4", [][]byte{[]byte(LeaveChain), []byte("unknownchannel")}, sProp)
+	assert.Equal(t, int32(shim.ERROR), res.Status)
+
+	// Successful path for LeaveChain, the peer is left with no channel
+	res = stub.MockInvokeWithSignedProposal("4", args, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	res = stub.MockInvokeWithSignedProposal("5", [][]byte{[]byte(GetChannels)}, sProp)
+	assert.Equal(t, int32(shim.OK), res.Status)
+	cqr = &pb.ChannelQueryResponse{}
+	assert.NoError(t, proto.Unmarshal(res.Payload, cqr))
+	assert.Len(t, cqr.GetChannels(), 0)
+	ids, err := ledgermgmt.GetLedgerIDs()
+	assert.NoError(t, err)
+	assert.Len(t, ids, 0)
 }
 
 func TestPeerConfiger_SubmittingOrdererGenesis(t *testing.T) {
@@ -17,8 +17,10 @@ limitations under the License.
 package lscc
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/cauthdsl"
@@ -27,6 +29,7 @@ import (
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
 	"github.com/hyperledger/fabric/core/policyprovider"
@@ -470,12 +473,36 @@ func (lscc *LifeCycleSysCC) executeInstall(stub shim.ChaincodeStubInterface, ccb
 		return err
 	}
 
+	dbArtifacts, err := ccprovider.ExtractStatedbArtifactsFromCodePackage(cds.CodePackage)
+	if err != nil {
+		return err
+	}
+	for path, content := range dbArtifacts {
+		if strings.HasSuffix(path, ".json") && !isJSON(content) {
+			return fmt.Errorf("Invalid statedb artifact %s%s: not a valid JSON", ccprovider.StatedbArtifactsDir, path)
+		}
+	}
+
 	//everything checks out..lets write the package to the FS
 	if err = ccpack.PutChaincodeToFS(); err != nil {
 		return fmt.Errorf("Error installing chaincode code %s:%s(%s)", cds.ChaincodeSpec.ChaincodeId.Name, cds.ChaincodeSpec.ChaincodeId.Version, err)
 	}
 
-	return err
+	//the chaincode may already be deployed on channels the peer has joined,
+	//process its statedb artifacts there. The install is not failed by this
+	if len(dbArtifacts) > 0 {
+		chaincodeDefinition := &cceventmgmt.ChaincodeDefinition{Name: cds.ChaincodeSpec.ChaincodeId.Name, Version: cds.ChaincodeSpec.ChaincodeId.Version}
+		if err := cceventmgmt.GetMgr().HandleChaincodeInstall(chaincodeDefinition, dbArtifacts); err != nil {
+			logger.Errorf("Error processing the statedb artifacts of chaincode %s: %s", chaincodeDefinition, err)
+		}
+	}
+
+	return nil
+}
+
+func isJSON(content []byte) bool {
+	var js map[string]interface{}
+	return json.Unmarshal(content, &js) == nil
 }
 
 // getInstantiationPolicy retrieves the instantiation policy from a SignedCDSPackage
@@ -35,6 +35,7 @@ import (
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/common/sysccprovider"
 	cutil "github.com/hyperledger/fabric/core/container/util"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
 	policymocks "github.com/hyperledger/fabric/core/policy/mocks"
@@ -174,6 +175,82 @@ func testInstall(t *testing.T, ccname string, version string, path string, expec
 	}
 }
 
+type mockCCInfoProvider struct{}
+
+func (p *mockCCInfoProvider) GetChaincodeDefinition(lsccValue []byte) (*cceventmgmt.ChaincodeDefinition, error) {
+	return &cceventmgmt.ChaincodeDefinition{Name: "example02", Version: string(lsccValue)}, nil
+}
+
+func (p *mockCCInfoProvider) RetrieveChaincodeArtifacts(chaincodeDefinition *cceventmgmt.ChaincodeDefinition) (bool, map[string][]byte, error) {
+	return false, nil, nil
+}
+
+type mockCCEventListener struct {
+	dbArtifacts map[string][]byte
+}
+
+func (l *mockCCEventListener) HandleChaincodeDeploy(chaincodeDefinition *cceventmgmt.ChaincodeDefinition, dbArtifacts map[string][]byte) error {
+	l.dbArtifacts = dbArtifacts
+	return nil
+}
+
+func (l *mockCCEventListener) GetLSCCState(chaincodeName string) ([]byte, error) {
+	return []byte("0"), nil
+}
+
+//TestInstallWithStatedbArtifacts tests the install of a package shipping statedb artifacts
+func TestInstallWithStatedbArtifacts(t *testing.T) {
+	scc := new(LifeCycleSysCC)
+	stub := shim.NewMockStub("lscc", scc)
+	res := stub.MockInit("1", nil)
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	identityDeserializer := &policymocks.MockIdentityDeserializer{[]byte("Alice"), []byte("msg1")}
+	policyManagerGetter := &policymocks.MockChannelPolicyManagerGetter{
+		Managers: map[string]policies.Manager{
+			"test": &policymocks.MockChannelPolicyManager{MockPolicy: &policymocks.MockPolicy{Deserializer: identityDeserializer}},
+		},
+	}
+	scc.policyChecker = policy.NewPolicyChecker(
+		policyManagerGetter,
+		identityDeserializer,
+		&policymocks.MockMSPPrincipalGetter{Principal: []byte("Alice")},
+	)
+	sProp, _ := utils.MockSignedEndorserProposalOrPanic("", &pb.ChaincodeSpec{}, []byte("Alice"), []byte("msg1"))
+	identityDeserializer.Msg = sProp.ProposalBytes
+	sProp.Signature = sProp.ProposalBytes
+
+	cceventmgmt.GetMgr().Initialize(&mockCCInfoProvider{})
+	defer cceventmgmt.GetMgr().Initialize(nil)
+	listener := &mockCCEventListener{}
+	cceventmgmt.GetMgr().Register("test", listener)
+	defer cceventmgmt.GetMgr().Unregister("test")
+
+	install := func(indexDefinition string) pb.Response {
+		codePackageBytes := bytes.NewBuffer(nil)
+		gz := gzip.NewWriter(codePackageBytes)
+		tw := tar.NewWriter(gz)
+		assert.NoError(t, cutil.WriteBytesToPackage("src/garbage.go", []byte("garbage"), tw))
+		assert.NoError(t, cutil.WriteBytesToPackage("META-INF/statedb/couchdb/indexes/indexOwner.json", []byte(indexDefinition), tw))
+		tw.Close()
+		gz.Close()
+
+		spec := &pb.ChaincodeSpec{Type: 1, ChaincodeId: &pb.ChaincodeID{Name: "example02", Path: "path", Version: "0"}, Input: &pb.ChaincodeInput{}}
+		cds := &pb.ChaincodeDeploymentSpec{ChaincodeSpec: spec, CodePackage: codePackageBytes.Bytes()}
+		return stub.MockInvokeWithSignedProposal("1", [][]byte{[]byte(INSTALL), utils.MarshalOrPanic(cds)}, sProp)
+	}
+
+	res = install(`{"index":{"fields":["owner"]`)
+	assert.NotEqual(t, int32(shim.OK), res.Status)
+	assert.Contains(t, res.Message, "Invalid statedb artifact META-INF/statedb/couchdb/indexes/indexOwner.json")
+	assert.Nil(t, listener.dbArtifacts)
+
+	res = install(`{"index":{"fields":["owner"]}}`)
+	defer os.Remove(lscctestpath + "/example02.0")
+	assert.Equal(t, int32(shim.OK), res.Status, res.Message)
+	// the chaincode is deployed on the ledger, its artifacts were processed
+	assert.Equal(t, map[string][]byte{"couchdb/indexes/indexOwner.json": []byte(`{"index":{"fields":["owner"]}}`)}, listener.dbArtifacts)
+}
+
 //TestReinstall tests the install function
 func TestReinstall(t *testing.T) {
 	scc := new(LifeCycleSysCC)
@@ -20,14 +20,18 @@ import (
 	"fmt"
 	"strconv"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/flogging"
+	commonledger "github.com/hyperledger/fabric/common/ledger"
 
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
 	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/policy"
 	"github.com/hyperledger/fabric/msp/mgmt"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	pb "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -37,6 +41,8 @@ import (
 // - GetBlockByNumber returns a block
 // - GetBlockByHash returns a block
 // - GetTransactionByID returns a transaction
+// - GetHistoryForKey returns the history of a key
+// - GetHistoryForKeyRange returns the history of a range of keys
 type LedgerQuerier struct {
 	policyChecker policy.PolicyChecker
 }
@@ -50,6 +56,9 @@ const (
 	GetBlockByHash     string = "GetBlockByHash"
 	GetTransactionByID string = "GetTransactionByID"
 	GetBlockByTxID     string = "GetBlockByTxID"
+
+	GetHistoryForKey      string = "GetHistoryForKey"
+	GetHistoryForKeyRange string = "GetHistoryForKeyRange"
 )
 
 // Init is called once per chain when the chain is created.
@@ -75,6 +84,11 @@ func (e *LedgerQuerier) Init(stub shim.ChaincodeStubInterface) pb.Response {
 // # GetBlockByNumber: Return the block specified by block number in args[2]
 // # GetBlockByHash: Return the block specified by block hash in args[2]
 // # GetTransactionByID: Return the transaction specified by ID in args[2]
+// # GetHistoryForKey: Return the history of the key args[3] of the chaincode args[2],
+//   restricted by the marshalled HistoryQueryOptions in the optional args[4]
+// # GetHistoryForKeyRange: Return the history of the keys in the range [args[3], args[4])
+//   of the chaincode args[2], restricted by the marshalled HistoryQueryOptions in the optional args[5]
+// The histories are returned as a marshalled KeyModifications
 func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 	args := stub.GetArgs()
 
@@ -118,6 +132,10 @@ func (e *LedgerQuerier) Invoke(stub shim.ChaincodeStubInterface) pb.Response {
 		return getChainInfo(targetLedger)
 	case GetBlockByTxID:
 		return getBlockByTxID(targetLedger, args[2])
+	case GetHistoryForKey:
+		return getHistoryForKey(targetLedger, args[2:])
+	case GetHistoryForKeyRange:
+		return getHistoryForKeyRange(targetLedger, args[2:])
 	}
 
 	return shim.Error(fmt.Sprintf("Requested function %s not found.", fname))
@@ -216,3 +234,81 @@ func getBlockByTxID(vledger ledger.PeerLedger, rawTxID []byte) pb.Response {
 
 	return shim.Success(bytes)
 }
+
+func getHistoryForKey(vledger ledger.PeerLedger, args [][]byte) pb.Response {
+	if len(args) < 2 || len(args) > 3 {
+		return shim.Error(fmt.Sprintf("Incorrect number of arguments for %s, expecting the namespace, the key and optionally the query options", GetHistoryForKey))
+	}
+	options, err := getHistoryQueryOptions(args[2:])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	hqe, err := vledger.NewHistoryQueryExecutor()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get history query executor, error %s", err))
+	}
+	itr, err := hqe.GetHistoryForKeyWithOptions(string(args[0]), string(args[1]), options)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get history for key %s, error %s", string(args[1]), err))
+	}
+	return getKeyModifications(itr)
+}
+
+func getHistoryForKeyRange(vledger ledger.PeerLedger, args [][]byte) pb.Response {
+	if len(args) < 3 || len(args) > 4 {
+		return shim.Error(fmt.Sprintf("Incorrect number of arguments for %s, expecting the namespace, the start key, the end key and optionally the query options", GetHistoryForKeyRange))
+	}
+	options, err := getHistoryQueryOptions(args[3:])
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+	hqe, err := vledger.NewHistoryQueryExecutor()
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get history query executor, error %s", err))
+	}
+	itr, err := hqe.GetHistoryForKeyRange(string(args[0]), string(args[1]), string(args[2]), options)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get history for key range [%s, %s), error %s", string(args[1]), string(args[2]), err))
+	}
+	return getKeyModifications(itr)
+}
+
+// getHistoryQueryOptions unmarshals the optional query options
+func getHistoryQueryOptions(args [][]byte) (*queryresult.HistoryQueryOptions, error) {
+	if len(args) == 0 || len(args[0]) == 0 {
+		return nil, nil
+	}
+	options := &queryresult.HistoryQueryOptions{}
+	if err := proto.Unmarshal(args[0], options); err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal history query options, error %s", err)
+	}
+	return options, nil
+}
+
+// getKeyModifications returns the results of a history query, which fails if they exceed the
+// query limit, the query options then have to narrow it down to fewer blocks
+func getKeyModifications(itr commonledger.ResultsIterator) pb.Response {
+	defer itr.Close()
+	queryLimit := ledgerconfig.GetQueryLimit()
+	kms := &queryresult.KeyModifications{}
+	for {
+		result, err := itr.Next()
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Failed to get history, error %s", err))
+		}
+		if result == nil {
+			break
+		}
+		if len(kms.KeyModifications) >= queryLimit {
+			return shim.Error(fmt.Sprintf("History exceeds the query limit of %d modifications, narrow it down with the start and end blocks of the query options", queryLimit))
+		}
+		kms.KeyModifications = append(kms.KeyModifications, result.(*queryresult.KeyModification))
+	}
+
+	bytes, err := utils.Marshal(kms)
+	if err != nil {
+		return shim.Error(err.Error())
+	}
+
+	return shim.Success(bytes)
+}
@@ -21,6 +21,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/common/ledger/testutil"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
@@ -28,6 +29,7 @@ import (
 	"github.com/hyperledger/fabric/core/policy"
 	policymocks "github.com/hyperledger/fabric/core/policy/mocks"
 	"github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	peer2 "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/spf13/viper"
@@ -250,6 +252,68 @@ func TestQueryGeneratedBlock(t *testing.T) {
 	}
 }
 
+func TestQueryGetHistory(t *testing.T) {
+	viper.Set("ledger.history.enableHistoryDatabase", true)
+	defer viper.Set("ledger.history.enableHistoryDatabase", false)
+	chainid := "mytestchainid9"
+	path := "/var/hyperledger/test9/"
+	stub, err := setupTestLedger(chainid, path)
+	defer os.RemoveAll(path)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	bg, _ := testutil.NewBlockGenerator(t, chainid, false)
+	simulator, _ := peer.GetLedger(chainid).NewTxSimulator()
+	simulator.SetState("ns1", "key1", []byte("value1"))
+	simulator.SetState("ns1", "key2", []byte("value2"))
+	simulator.Done()
+	simRes, _ := simulator.GetTxSimulationResults()
+	assert.NoError(t, peer.GetLedger(chainid).Commit(bg.NextBlock([][]byte{simRes})))
+
+	getKeyModifications := func(res peer2.Response) []*queryresult.KeyModification {
+		assert.Equal(t, int32(shim.OK), res.Status, "History query failed with err: %s", res.Message)
+		kms := &queryresult.KeyModifications{}
+		assert.NoError(t, proto.Unmarshal(res.Payload, kms))
+		return kms.KeyModifications
+	}
+
+	args := [][]byte{[]byte(GetHistoryForKey), []byte(chainid), []byte("ns1"), []byte("key1")}
+	kms := getKeyModifications(stub.MockInvoke("1", args))
+	assert.Len(t, kms, 1)
+	assert.Equal(t, "key1", kms[0].Key)
+	assert.Equal(t, []byte("value1"), kms[0].Value)
+	assert.Equal(t, uint64(1), kms[0].BlockNum)
+
+	args = [][]byte{[]byte(GetHistoryForKeyRange), []byte(chainid), []byte("ns1"), []byte(""), []byte("")}
+	kms = getKeyModifications(stub.MockInvoke("2", args))
+	assert.Len(t, kms, 2)
+	assert.Equal(t, "key2", kms[1].Key)
+
+	options := utils.MarshalOrPanic(&queryresult.HistoryQueryOptions{StartBlock: 2})
+	args = [][]byte{[]byte(GetHistoryForKeyRange), []byte(chainid), []byte("ns1"), []byte(""), []byte(""), options}
+	kms = getKeyModifications(stub.MockInvoke("3", args))
+	assert.Len(t, kms, 0)
+
+	args = [][]byte{[]byte(GetHistoryForKey), []byte(chainid), []byte("ns1"), []byte("key1"), []byte("bad options")}
+	res := stub.MockInvoke("4", args)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetHistoryForKey should have failed because of invalid options")
+
+	args = [][]byte{[]byte(GetHistoryForKeyRange), []byte(chainid), []byte("ns1"), []byte("key1")}
+	res = stub.MockInvoke("5", args)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetHistoryForKeyRange should have failed because the end key is missing")
+
+	// the results of a history query cannot exceed the query limit
+	viper.Set("ledger.state.couchDBConfig.queryLimit", 1)
+	defer viper.Set("ledger.state.couchDBConfig.queryLimit", 10000)
+	args = [][]byte{[]byte(GetHistoryForKeyRange), []byte(chainid), []byte("ns1"), []byte(""), []byte("")}
+	res = stub.MockInvoke("6", args)
+	assert.Equal(t, int32(shim.ERROR), res.Status, "GetHistoryForKeyRange should have failed because the history exceeds the query limit")
+	args = [][]byte{[]byte(GetHistoryForKey), []byte(chainid), []byte("ns1"), []byte("key1")}
+	kms = getKeyModifications(stub.MockInvoke("7", args))
+	assert.Len(t, kms, 1)
+}
+
 func addBlockForTesting(t *testing.T, chainid string) *common.Block {
 	bg, _ := testutil.NewBlockGenerator(t, chainid, false)
 	ledger := peer.GetLedger(chainid)
@@ -23,6 +23,7 @@ import (
 
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/core/chaincode/shim"
+	"github.com/hyperledger/fabric/protos/ledger/queryresult"
 	"github.com/hyperledger/fabric/protos/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -205,6 +206,18 @@ func (*mockStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterfac
 	panic("implement me")
 }
 
+func (*mockStub) GetHistoryForKeyWithOptions(key string, options *queryresult.HistoryQueryOptions) (shim.HistoryQueryIteratorInterface, error) {
+	panic("implement me")
+}
+
+func (*mockStub) GetHistoryForKeyRange(startKey, endKey string, options *queryresult.HistoryQueryOptions) (shim.HistoryQueryIteratorInterface, error) {
+	panic("implement me")
+}
+
+func (*mockStub) GetHistoryForPartialCompositeKey(objectType string, keys []string, options *queryresult.HistoryQueryOptions) (shim.HistoryQueryIteratorInterface, error) {
+	panic("implement me")
+}
+
 func (*mockStub) GetCreator() ([]byte, error) {
 	panic("implement me")
 }
@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
 
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/comm"
@@ -41,6 +40,7 @@ type EventsClient struct {
 	peerAddress string
 	regTimeout  time.Duration
 	stream      ehpb.Events_ChatClient
+	conn        *comm.PooledConn
 	adapter     EventAdapter
 }
 
@@ -54,15 +54,13 @@ func NewEventsClient(peerAddress string, regTimeout time.Duration, adapter Event
 		regTimeout = 60 * time.Second
 		err = fmt.Errorf("regTimeout > 60, setting to 60 sec")
 	}
-	return &EventsClient{sync.RWMutex{}, peerAddress, regTimeout, nil, adapter}, err
+	return &EventsClient{sync.RWMutex{}, peerAddress, regTimeout, nil, nil, adapter}, err
 }
 
-//newEventsClientConnectionWithAddress Returns a new grpc.ClientConn to the configured local PEER.
-func newEventsClientConnectionWithAddress(peerAddress string) (*grpc.ClientConn, error) {
-	if comm.TLSEnabled() {
-		return comm.NewClientConnectionWithAddress(peerAddress, true, true, comm.InitTLSForPeer())
-	}
-	return comm.NewClientConnectionWithAddress(peerAddress, true, false, nil)
+//newEventsClientConnectionWithAddress Returns a grpc.ClientConn to the configured local PEER
+//from the pool of connections shared by the clients of this process.
+func newEventsClientConnectionWithAddress(peerAddress string) (*comm.PooledConn, error) {
+	return comm.PeerConnectionPool().Acquire(peerAddress)
 }
 
 func (ec *EventsClient) send(emsg *ehpb.Event) error {
@@ -206,22 +204,28 @@ func (ec *EventsClient) Start() error {
 		return fmt.Errorf("could not create client conn to %s:%s", ec.peerAddress, err)
 	}
 
+	ec.conn = conn
+
 	ies, err := ec.adapter.GetInterestedEvents()
 	if err != nil {
+		ec.releaseConn()
 		return fmt.Errorf("error getting interested events:%s", err)
 	}
 
 	if len(ies) == 0 {
+		ec.releaseConn()
 		return fmt.Errorf("must supply interested events")
 	}
 
-	serverClient := ehpb.NewEventsClient(conn)
+	serverClient := ehpb.NewEventsClient(conn.ClientConn)
 	ec.stream, err = serverClient.Chat(context.Background())
 	if err != nil {
+		ec.releaseConn()
 		return fmt.Errorf("could not create client conn to %s:%s", ec.peerAddress, err)
 	}
 
 	if err = ec.register(ies); err != nil {
+		ec.releaseConn()
 		return err
 	}
 
@@ -232,6 +236,7 @@ func (ec *EventsClient) Start() error {
 
 //Stop terminates connection with event hub
 func (ec *EventsClient) Stop() error {
+	defer ec.releaseConn()
 	if ec.stream == nil {
 		// in case the steam/chat server has not been established earlier, we assume that it's closed, successfully
 		return nil
@@ -239,6 +244,16 @@ func (ec *EventsClient) Stop() error {
 	return ec.stream.CloseSend()
 }
 
+//releaseConn returns the connection to the event hub to the connection pool
+func (ec *EventsClient) releaseConn() {
+	ec.Lock()
+	defer ec.Unlock()
+	if ec.conn != nil {
+		ec.conn.Release()
+		ec.conn = nil
+	}
+}
+
 func getCreatorFromLocalMSP() ([]byte, error) {
 	localMsp := mspmgmt.GetLocalMSP()
 	if localMsp == nil {
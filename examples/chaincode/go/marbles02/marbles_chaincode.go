@@ -36,7 +36,11 @@ under the License.
 //   peer chaincode query -C myc1 -n marbles -c '{"Args":["queryMarblesByOwner","tom"]}'
 //   peer chaincode query -C myc1 -n marbles -c '{"Args":["queryMarbles","{\"selector\":{\"owner\":\"tom\"}}"]}'
 
-//The following examples demonstrate creating indexes on CouchDB
+//The indexes below are shipped with the chaincode, under META-INF/statedb/couchdb/indexes,
+//and created by the peer when the chaincode is installed and instantiated. In those
+//definitions the fields are not prefixed with the "data" wrapper, the peer adds it.
+//
+//The following examples demonstrate creating the same indexes by hand on CouchDB
 //Example hostname:port configurations
 //
 //Docker or vagrant environments:
@@ -0,0 +1,184 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/spf13/viper"
+)
+
+const (
+	compressionGzip = "gzip"
+
+	defMaxBatchSize  = 100
+	defMaxBatchBytes = 1024 * 1024
+	// minCompressionSize is the size below which batches aren't compressed,
+	// as compressing them isn't worth the CPU and the gzip header overhead
+	minCompressionSize = 1024
+	// maxDecompressedSize bounds the size a compressed batch can decompress to
+	maxDecompressedSize = 64 * 1024 * 1024
+)
+
+// features are the optional capabilities of a connection,
+// which are negotiated with the remote peer upon connection establishment
+type features struct {
+	// batching indicates whether several messages may be sent in a single envelope
+	batching bool
+	// compression is the compression algorithm of batches, empty if batches aren't compressed
+	compression string
+}
+
+// localFeatures returns the features this peer is configured to use
+// with remote peers that support them
+func localFeatures() features {
+	f := features{
+		batching: viper.GetBool("peer.gossip.comm.batching"),
+	}
+	if viper.GetBool("peer.gossip.comm.compression") {
+		f.compression = compressionGzip
+	}
+	return f
+}
+
+// advertise fills the given connection message with the features of this peer
+func (f features) advertise(connMsg *proto.ConnEstablish) {
+	connMsg.Batching = f.batching
+	if f.compression != "" {
+		connMsg.Compression = []string{f.compression}
+	}
+}
+
+// negotiate returns the features that both this peer and the remote peer
+// that sent the given connection message support
+func (f features) negotiate(remote *proto.ConnEstablish) features {
+	negotiated := features{
+		batching: f.batching && remote.Batching,
+	}
+	for _, algorithm := range remote.Compression {
+		if f.compression != "" && f.compression == algorithm {
+			negotiated.compression = algorithm
+		}
+	}
+	return negotiated
+}
+
+func maxBatchSize() int {
+	return util.GetIntOrDefault("peer.gossip.comm.maxBatchSize", defMaxBatchSize)
+}
+
+func maxBatchBytes() int {
+	return util.GetIntOrDefault("peer.gossip.comm.maxBatchBytes", defMaxBatchBytes)
+}
+
+// packBatch packs the given envelopes into a single envelope of a GossipBatch message,
+// which is compressed if compression is given. The batch is signed with a no-op signature,
+// since the connection itself is bound to the identity of the remote peer during the handshake.
+// Batching doesn't remove any signature: messages sent to a single peer are already
+// no-op signed, and the signed messages (alive, state info, leadership and identity
+// messages) carry the signature of their originator, which is verified by every peer
+// they are relayed to, and therefore can't be replaced by a signature of the batch.
+func packBatch(envelopes []*proto.Envelope, compression string) (*proto.Envelope, error) {
+	batch := &proto.GossipBatch{Envelopes: envelopes}
+	if compression != "" {
+		raw, err := pb.Marshal(batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) >= minCompressionSize {
+			compressed, err := compress(raw, compression)
+			if err != nil {
+				return nil, err
+			}
+			batch = &proto.GossipBatch{Compressed: compressed}
+		}
+	}
+	m := &proto.GossipMessage{
+		Tag:     proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_Batch{Batch: batch},
+	}
+	sMsg, err := m.NoopSign()
+	if err != nil {
+		return nil, err
+	}
+	return sMsg.Envelope, nil
+}
+
+// unpackBatch returns the envelopes of the given batch, decompressing it if needed
+func unpackBatch(batch *proto.GossipBatch) ([]*proto.Envelope, error) {
+	if len(batch.Compressed) == 0 {
+		return batch.Envelopes, nil
+	}
+	if len(batch.Envelopes) != 0 {
+		return nil, errors.New("batch contains both compressed and uncompressed envelopes")
+	}
+	raw, err := decompress(batch.Compressed)
+	if err != nil {
+		return nil, err
+	}
+	inner := &proto.GossipBatch{}
+	if err := pb.Unmarshal(raw, inner); err != nil {
+		return nil, fmt.Errorf("Failed unmarshaling decompressed batch: %v", err)
+	}
+	if len(inner.Compressed) != 0 {
+		return nil, errors.New("batch is compressed more than once")
+	}
+	return inner.Envelopes, nil
+}
+
+func compress(raw []byte, compression string) ([]byte, error) {
+	if compression != compressionGzip {
+		return nil, fmt.Errorf("unsupported compression %s", compression)
+	}
+	buff := &bytes.Buffer{}
+	w := gzip.NewWriter(buff)
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func decompress(compressed []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	raw, err := ioutil.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) > maxDecompressedSize {
+		return nil, fmt.Errorf("batch decompresses to more than %d bytes", maxDecompressedSize)
+	}
+	return raw, nil
+}
+
+func envelopeSize(envelope *proto.Envelope) int {
+	return pb.Size(envelope)
+}
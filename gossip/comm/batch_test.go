@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func createDataMsg(seqNum uint64, size int) *proto.SignedGossipMessage {
+	msg, _ := (&proto.GossipMessage{
+		Tag:   proto.GossipMessage_CHAN_AND_ORG,
+		Nonce: seqNum,
+		Content: &proto.GossipMessage_DataMsg{
+			DataMsg: &proto.DataMessage{
+				Payload: &proto.Payload{
+					SeqNum: seqNum,
+					Data:   bytes.Repeat([]byte{byte(seqNum)}, size),
+				},
+			},
+		},
+	}).NoopSign()
+	return msg
+}
+
+func TestPackUnpackBatch(t *testing.T) {
+	t.Parallel()
+	var envelopes []*proto.Envelope
+	for i := 0; i < 10; i++ {
+		envelopes = append(envelopes, createDataMsg(uint64(i), 512).Envelope)
+	}
+
+	for _, compression := range []string{"", compressionGzip} {
+		envelope, err := packBatch(envelopes, compression)
+		assert.NoError(t, err)
+		msg, err := envelope.ToGossipMessage()
+		assert.NoError(t, err)
+		assert.NotNil(t, msg.GetBatch())
+		if compression != "" {
+			assert.Empty(t, msg.GetBatch().Envelopes)
+			assert.True(t, len(envelope.Payload) < 10*512, "batch of repetitive data wasn't compressed")
+		}
+		unpacked, err := unpackBatch(msg.GetBatch())
+		assert.NoError(t, err)
+		assert.Len(t, unpacked, 10)
+		for i, e := range unpacked {
+			m, err := e.ToGossipMessage()
+			assert.NoError(t, err)
+			assert.Equal(t, uint64(i), m.GetDataMsg().Payload.SeqNum)
+		}
+	}
+
+	// Small batches aren't compressed
+	envelope, err := packBatch(envelopes[:1], compressionGzip)
+	assert.NoError(t, err)
+	msg, _ := envelope.ToGossipMessage()
+	assert.Len(t, msg.GetBatch().Envelopes, 1)
+
+	_, err = packBatch(envelopes, "lz4")
+	assert.Error(t, err)
+
+	// Malformed batches are rejected
+	_, err = unpackBatch(&proto.GossipBatch{Compressed: []byte{1, 2, 3}})
+	assert.Error(t, err)
+	_, err = unpackBatch(&proto.GossipBatch{Envelopes: envelopes, Compressed: []byte{1, 2, 3}})
+	assert.Error(t, err)
+	conn := &connection{}
+	_, err = conn.unpack(&proto.SignedGossipMessage{GossipMessage: &proto.GossipMessage{
+		Content: &proto.GossipMessage_Batch{Batch: &proto.GossipBatch{Envelopes: []*proto.Envelope{envelope}}},
+	}})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "nested")
+}
+
+func TestNegotiateFeatures(t *testing.T) {
+	t.Parallel()
+	remote := &proto.ConnEstablish{Batching: true, Compression: []string{"lz4", compressionGzip}}
+	assert.Equal(t, features{}, features{}.negotiate(remote))
+
+	local := features{batching: true, compression: compressionGzip}
+	assert.Equal(t, local, local.negotiate(remote))
+	assert.Equal(t, features{}, local.negotiate(&proto.ConnEstablish{Compression: []string{"lz4"}}))
+
+	connMsg := &proto.ConnEstablish{}
+	local.advertise(connMsg)
+	assert.Equal(t, local, local.negotiate(connMsg))
+}
+
+func TestLocalFeatures(t *testing.T) {
+	// Not parallel, as the features are configured globally
+	defer viper.Set("peer.gossip.comm.batching", false)
+	defer viper.Set("peer.gossip.comm.compression", false)
+	assert.Equal(t, features{}, localFeatures())
+	viper.Set("peer.gossip.comm.batching", true)
+	viper.Set("peer.gossip.comm.compression", true)
+	assert.Equal(t, features{batching: true, compression: compressionGzip}, localFeatures())
+}
+
+func TestBatchingAndCompression(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(5711, naiveSec)
+	comm2, _ := newCommInstance(5712, naiveSec)
+	comm3, _ := newCommInstance(5713, naiveSec)
+	defer comm1.Stop()
+	defer comm2.Stop()
+	defer comm3.Stop()
+	comm1.(*commImpl).features = features{batching: true, compression: compressionGzip}
+	comm2.(*commImpl).features = features{batching: true, compression: compressionGzip}
+
+	out := make(chan uint64, 100)
+	receive := func(ch <-chan proto.ReceivedMessage) {
+		for m := range ch {
+			payload := m.GetGossipMessage().GetDataMsg().Payload
+			if !bytes.Equal(bytes.Repeat([]byte{byte(payload.SeqNum)}, 2048), payload.Data) {
+				continue
+			}
+			out <- payload.SeqNum
+		}
+	}
+	go receive(comm2.Accept(acceptAll))
+	go receive(comm3.Accept(acceptAll))
+
+	// Don't send more messages than the send buffer holds, as the rest would be dropped
+	messages2Send := util.GetIntOrDefault("peer.gossip.sendBuffSize", defSendBuffSize)
+	for i := 0; i < messages2Send; i++ {
+		comm1.Send(createDataMsg(uint64(i), 2048), remotePeer(5712))
+	}
+	waitForMessages(t, out, messages2Send, "Didn't receive all messages")
+
+	conn, err := comm1.(*commImpl).connStore.getConnection(remotePeer(5712))
+	assert.NoError(t, err)
+	assert.Equal(t, features{batching: true, compression: compressionGzip}, conn.features)
+
+	// Probing the remote peer reuses the connection the messages were sent over
+	assert.NoError(t, comm1.Probe(remotePeer(5712)))
+	assert.Equal(t, 1, comm1.(*commImpl).connPool.Size())
+	_, err = comm1.Handshake(remotePeer(5712))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, comm1.(*commImpl).connPool.Size())
+
+	// Peers that don't support batching receive the messages one by one
+	for i := 0; i < 10; i++ {
+		comm1.Send(createDataMsg(uint64(i), 2048), remotePeer(5713))
+	}
+	waitForMessages(t, out, 10, "Didn't receive all messages")
+	conn, err = comm1.(*commImpl).connStore.getConnection(remotePeer(5713))
+	assert.NoError(t, err)
+	assert.Equal(t, features{}, conn.features)
+}
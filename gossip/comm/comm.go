@@ -49,6 +49,13 @@ type Comm interface {
 	// PresumedDead returns a read-only channel for node endpoints that are suspected to be offline
 	PresumedDead() <-chan common.PKIidType
 
+	// Blacklisted returns a read-only channel for PKI-IDs of peers that have been
+	// blacklisted for a while, due to exceeding the message rate limits repeatedly
+	Blacklisted() <-chan common.PKIidType
+
+	// IsBlacklisted returns whether the peer with the given PKI-ID is currently blacklisted
+	IsBlacklisted(pkiID common.PKIidType) bool
+
 	// CloseConn closes a connection to a certain endpoint
 	CloseConn(peer *RemotePeer)
 
@@ -27,6 +27,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	corecomm "github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/identity"
@@ -90,11 +91,15 @@ func NewCommInstanceWithServer(port int, idMapper identity.Mapper, peerIdentity
 		msgPublisher:   NewChannelDemultiplexer(),
 		lock:           &sync.RWMutex{},
 		deadEndpoints:  make(chan common.PKIidType, 100),
+		blacklisted:    make(chan common.PKIidType, 100),
+		msgLimiter:     newMsgRateLimiter(),
+		features:       localFeatures(),
 		stopping:       int32(0),
 		exitChan:       make(chan struct{}, 1),
 		subscriptions:  make([]chan proto.ReceivedMessage, 0),
 	}
 	commInst.connStore = newConnStore(commInst, commInst.logger)
+	commInst.connPool = corecomm.NewConnectionPool(commInst.dial)
 
 	if port > 0 {
 		commInst.stopWG.Add(1)
@@ -141,8 +146,12 @@ type commImpl struct {
 	opts           []grpc.DialOption
 	secureDialOpts func() []grpc.DialOption
 	connStore      *connectionStore
+	connPool       *corecomm.ConnectionPool
 	PKIID          []byte
 	deadEndpoints  chan common.PKIidType
+	blacklisted    chan common.PKIidType
+	msgLimiter     *msgRateLimiter
+	features       features
 	msgPublisher   *ChannelDeMultiplexer
 	lock           *sync.RWMutex
 	lsnr           net.Listener
@@ -154,13 +163,36 @@ type commImpl struct {
 	stopping       int32
 }
 
+func (c *commImpl) dial(endpoint string) (*grpc.ClientConn, error) {
+	var dialOpts []grpc.DialOption
+	dialOpts = append(dialOpts, c.secureDialOpts()...)
+	dialOpts = append(dialOpts, grpc.WithBlock())
+	dialOpts = append(dialOpts, c.opts...)
+	return grpc.Dial(endpoint, dialOpts...)
+}
+
+// acquireConn returns a pooled connection to the given endpoint
+// that is verified to be responsive
+func (c *commImpl) acquireConn(endpoint string) (*corecomm.PooledConn, proto.GossipClient, error) {
+	cc, err := c.connPool.Acquire(endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	cl := proto.NewGossipClient(cc.ClientConn)
+	if _, err = cl.Ping(context.Background(), &proto.Empty{}); err != nil {
+		// The connection might be broken, so make sure it is dialed again next time
+		cc.Discard()
+		return nil, nil, err
+	}
+	return cc, cl, nil
+}
+
 func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidType) (*connection, error) {
 	var err error
-	var cc *grpc.ClientConn
 	var stream proto.Gossip_GossipStreamClient
 	var pkiID common.PKIidType
 	var connInfo *proto.ConnectionInfo
-	var dialOpts []grpc.DialOption
+	var feats features
 
 	c.logger.Debug("Entering", endpoint, expectedPKIID)
 	defer c.logger.Debug("Exiting")
@@ -168,37 +200,36 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 	if c.isStopping() {
 		return nil, errors.New("Stopping")
 	}
-	dialOpts = append(dialOpts, c.secureDialOpts()...)
-	dialOpts = append(dialOpts, grpc.WithBlock())
-	dialOpts = append(dialOpts, c.opts...)
-	cc, err = grpc.Dial(endpoint, dialOpts...)
+	cc, cl, err := c.acquireConn(endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	cl := proto.NewGossipClient(cc)
-
-	if _, err = cl.Ping(context.Background(), &proto.Empty{}); err != nil {
-		cc.Close()
-		return nil, err
-	}
-
 	ctx, cf := context.WithCancel(context.Background())
 	if stream, err = cl.GossipStream(ctx); err == nil {
-		connInfo, err = c.authenticateRemotePeer(stream)
+		connInfo, feats, err = c.authenticateRemotePeer(stream)
 		if err == nil {
 			pkiID = connInfo.ID
 			if expectedPKIID != nil && !bytes.Equal(pkiID, expectedPKIID) {
 				// PKIID is nil when we don't know the remote PKI id's
 				c.logger.Warning("Remote endpoint claims to be a different peer, expected", expectedPKIID, "but got", pkiID)
-				cc.Close()
+				cf()
+				cc.Release()
 				return nil, errors.New("Authentication failure")
 			}
+			if c.msgLimiter.isBlacklisted(pkiID) {
+				c.logger.Warning("Remote endpoint", endpoint, "is blacklisted, refusing to connect to it")
+				cf()
+				cc.Release()
+				return nil, errors.New("Peer is blacklisted")
+			}
 			conn := newConnection(cl, cc, stream, nil)
 			conn.pkiID = pkiID
 			conn.info = connInfo
 			conn.logger = c.logger
 			conn.cancel = cf
+			conn.admit = c.admitMsg(pkiID)
+			conn.features = feats
 
 			h := func(m *proto.SignedGossipMessage) {
 				c.logger.Debug("Got message:", m)
@@ -214,7 +245,8 @@ func (c *commImpl) createConnection(endpoint string, expectedPKIID common.PKIidT
 		}
 		c.logger.Warning("Authentication failed:", err)
 	}
-	cc.Close()
+	cf()
+	cc.Release()
 	return nil, err
 }
 
@@ -258,51 +290,36 @@ func (c *commImpl) isStopping() bool {
 }
 
 func (c *commImpl) Probe(remotePeer *RemotePeer) error {
-	var dialOpts []grpc.DialOption
 	endpoint := remotePeer.Endpoint
 	pkiID := remotePeer.PKIID
 	if c.isStopping() {
 		return errors.New("Stopping")
 	}
 	c.logger.Debug("Entering, endpoint:", endpoint, "PKIID:", pkiID)
-	dialOpts = append(dialOpts, c.secureDialOpts()...)
-	dialOpts = append(dialOpts, grpc.WithBlock())
-	dialOpts = append(dialOpts, c.opts...)
-
-	cc, err := grpc.Dial(remotePeer.Endpoint, dialOpts...)
+	cc, _, err := c.acquireConn(endpoint)
 	if err != nil {
 		c.logger.Debug("Returning", err)
 		return err
 	}
-	defer cc.Close()
-	cl := proto.NewGossipClient(cc)
-	_, err = cl.Ping(context.Background(), &proto.Empty{})
-	c.logger.Debug("Returning", err)
-	return err
+	cc.Release()
+	c.logger.Debug("Returning", nil)
+	return nil
 }
 
 func (c *commImpl) Handshake(remotePeer *RemotePeer) (api.PeerIdentityType, error) {
-	var dialOpts []grpc.DialOption
-	dialOpts = append(dialOpts, c.secureDialOpts()...)
-	dialOpts = append(dialOpts, grpc.WithBlock())
-	dialOpts = append(dialOpts, c.opts...)
-
-	cc, err := grpc.Dial(remotePeer.Endpoint, dialOpts...)
+	cc, cl, err := c.acquireConn(remotePeer.Endpoint)
 	if err != nil {
 		return nil, err
 	}
-	defer cc.Close()
+	defer cc.Release()
 
-	cl := proto.NewGossipClient(cc)
-	if _, err = cl.Ping(context.Background(), &proto.Empty{}); err != nil {
-		return nil, err
-	}
-
-	stream, err := cl.GossipStream(context.Background())
+	ctx, cf := context.WithCancel(context.Background())
+	defer cf()
+	stream, err := cl.GossipStream(ctx)
 	if err != nil {
 		return nil, err
 	}
-	connInfo, err := c.authenticateRemotePeer(stream)
+	connInfo, _, err := c.authenticateRemotePeer(stream)
 	if err != nil {
 		c.logger.Warning("Authentication failed:", err)
 		return nil, err
@@ -310,6 +327,9 @@ func (c *commImpl) Handshake(remotePeer *RemotePeer) (api.PeerIdentityType, erro
 	if len(remotePeer.PKIID) > 0 && !bytes.Equal(connInfo.ID, remotePeer.PKIID) {
 		return nil, errors.New("PKI-ID of remote peer doesn't match expected PKI-ID")
 	}
+	if c.msgLimiter.isBlacklisted(connInfo.ID) {
+		return nil, errors.New("Peer is blacklisted")
+	}
 	return connInfo.Identity, nil
 }
 
@@ -352,6 +372,32 @@ func (c *commImpl) PresumedDead() <-chan common.PKIidType {
 	return c.deadEndpoints
 }
 
+func (c *commImpl) Blacklisted() <-chan common.PKIidType {
+	return c.blacklisted
+}
+
+func (c *commImpl) IsBlacklisted(pkiID common.PKIidType) bool {
+	return c.msgLimiter.isBlacklisted(pkiID)
+}
+
+// admitMsg returns a msgFilter that admits messages from the given peer as long as
+// the peer is within the message rate limits. Peers that keep exceeding
+// the limits are blacklisted and disconnected.
+func (c *commImpl) admitMsg(pkiID common.PKIidType) msgFilter {
+	return func(m *proto.SignedGossipMessage) bool {
+		admitted, blacklisted := c.msgLimiter.admit(pkiID, m)
+		if blacklisted {
+			c.logger.Warning(pkiID, "keeps exceeding the message rate limits, blacklisting it for", c.msgLimiter.blacklistDuration)
+			select {
+			case c.blacklisted <- pkiID:
+			default:
+			}
+			go c.disconnect(pkiID)
+		}
+		return admitted
+	}
+}
+
 func (c *commImpl) CloseConn(peer *RemotePeer) {
 	c.logger.Debug("Closing connection for", peer)
 	c.connStore.closeConn(peer)
@@ -380,6 +426,7 @@ func (c *commImpl) Stop() {
 	}
 	c.connStore.shutdown()
 	c.logger.Debug("Shut down connection store, connection count:", c.connStore.connNum())
+	c.connPool.Close()
 	c.exitChan <- struct{}{}
 	c.msgPublisher.Close()
 	c.logger.Debug("Shut down publisher")
@@ -403,7 +450,9 @@ func extractRemoteAddress(stream stream) string {
 	return remoteAddress
 }
 
-func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo, error) {
+// authenticateRemotePeer exchanges connection messages with the remote peer, and returns
+// the information about the remote peer along with the features both peers support
+func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo, features, error) {
 	ctx := stream.Context()
 	remoteAddress := extractRemoteAddress(stream)
 	remoteCertHash := extractCertificateHashFromContext(ctx)
@@ -428,12 +477,12 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 	// TLS enabled but not detected on other side
 	if useTLS && len(remoteCertHash) == 0 {
 		c.logger.Warningf("%s didn't send TLS certificate", remoteAddress)
-		return nil, errors.New("No TLS certificate")
+		return nil, features{}, errors.New("No TLS certificate")
 	}
 
 	cMsg, err = c.createConnectionMsg(c.PKIID, c.selfCertHash, c.peerIdentity, signer)
 	if err != nil {
-		return nil, err
+		return nil, features{}, err
 	}
 
 	c.logger.Debug("Sending", cMsg, "to", remoteAddress)
@@ -441,24 +490,24 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 	m, err := readWithTimeout(stream, util.GetDurationOrDefault("peer.gossip.connTimeout", defConnTimeout), remoteAddress)
 	if err != nil {
 		c.logger.Warningf("Failed reading messge from %s, reason: %v", remoteAddress, err)
-		return nil, err
+		return nil, features{}, err
 	}
 	receivedMsg := m.GetConn()
 	if receivedMsg == nil {
 		c.logger.Warning("Expected connection message from", remoteAddress, "but got", receivedMsg)
-		return nil, errors.New("Wrong type")
+		return nil, features{}, errors.New("Wrong type")
 	}
 
 	if receivedMsg.PkiId == nil {
 		c.logger.Warning("%s didn't send a pkiID", remoteAddress)
-		return nil, errors.New("No PKI-ID")
+		return nil, features{}, errors.New("No PKI-ID")
 	}
 
 	c.logger.Debug("Received", receivedMsg, "from", remoteAddress)
 	err = c.idMapper.Put(receivedMsg.PkiId, receivedMsg.Identity)
 	if err != nil {
 		c.logger.Warning("Identity store rejected", remoteAddress, ":", err)
-		return nil, err
+		return nil, features{}, err
 	}
 
 	connInfo := &proto.ConnectionInfo{
@@ -472,7 +521,7 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 		// If the remote peer sent its TLS certificate, make sure it actually matches the TLS cert
 		// that the peer used.
 		if !bytes.Equal(remoteCertHash, receivedMsg.TlsCertHash) {
-			return nil, fmt.Errorf("Expected %v in remote hash of TLS cert, but got %v", remoteCertHash, receivedMsg.TlsCertHash)
+			return nil, features{}, fmt.Errorf("Expected %v in remote hash of TLS cert, but got %v", remoteCertHash, receivedMsg.TlsCertHash)
 		}
 		verifier := func(peerIdentity []byte, signature, message []byte) error {
 			pkiID := c.idMapper.GetPKIidOfCert(api.PeerIdentityType(peerIdentity))
@@ -481,7 +530,7 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 		err = m.Verify(receivedMsg.Identity, verifier)
 		if err != nil {
 			c.logger.Error("Failed verifying signature from", remoteAddress, ":", err)
-			return nil, err
+			return nil, features{}, err
 		}
 		connInfo.Auth = &proto.AuthInfo{
 			Signature:  m.Signature,
@@ -491,18 +540,22 @@ func (c *commImpl) authenticateRemotePeer(stream stream) (*proto.ConnectionInfo,
 
 	c.logger.Debug("Authenticated", remoteAddress)
 
-	return connInfo, nil
+	return connInfo, c.features.negotiate(receivedMsg), nil
 }
 
 func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	if c.isStopping() {
 		return errors.New("Shutting down")
 	}
-	connInfo, err := c.authenticateRemotePeer(stream)
+	connInfo, feats, err := c.authenticateRemotePeer(stream)
 	if err != nil {
 		c.logger.Error("Authentication failed:", err)
 		return err
 	}
+	if c.msgLimiter.isBlacklisted(connInfo.ID) {
+		c.logger.Warning(extractRemoteAddress(stream), "is blacklisted, refusing its connection")
+		return errors.New("Peer is blacklisted")
+	}
 	c.logger.Debug("Servicing", extractRemoteAddress(stream))
 
 	conn := c.connStore.onConnected(stream, connInfo)
@@ -523,6 +576,8 @@ func (c *commImpl) GossipStream(stream proto.Gossip_GossipStreamServer) error {
 	}
 
 	conn.handler = h
+	conn.admit = c.admitMsg(connInfo.ID)
+	conn.features = feats
 
 	defer func() {
 		c.logger.Debug("Client", extractRemoteAddress(stream), " disconnected")
@@ -593,6 +648,7 @@ func (c *commImpl) createConnectionMsg(pkiID common.PKIidType, certHash []byte,
 			},
 		},
 	}
+	c.features.advertise(m.GetConn())
 	sMsg := &proto.SignedGossipMessage{
 		GossipMessage: m,
 	}
@@ -710,6 +710,50 @@ func TestPresumedDead(t *testing.T) {
 	}
 }
 
+func TestRateLimitBlacklist(t *testing.T) {
+	t.Parallel()
+	comm1, _ := newCommInstance(5611, naiveSec)
+	comm2, _ := newCommInstance(5612, naiveSec)
+	defer comm1.Stop()
+	defer comm2.Stop()
+	comm1.(*commImpl).msgLimiter = newTestMsgRateLimiter(5, 10, time.Minute)
+
+	m1 := comm1.Accept(acceptAll)
+	received := make(chan uint64, 100)
+	go func() {
+		for m := range m1 {
+			if m.GetGossipMessage().IsDataMsg() {
+				received <- m.GetGossipMessage().Nonce
+			}
+		}
+	}()
+
+	// comm2 floods comm1 with alive messages until it gets blacklisted
+	for i := 0; i < 100; i++ {
+		comm2.Send(createAliveMsg(), remotePeer(5611))
+	}
+	select {
+	case pkiID := <-comm1.Blacklisted():
+		assert.Equal(t, remotePeer(5612).PKIID, pkiID)
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "comm2 wasn't blacklisted in a timely manner")
+		return
+	}
+	assert.True(t, comm1.IsBlacklisted(remotePeer(5612).PKIID))
+	assert.False(t, comm2.IsBlacklisted(remotePeer(5611).PKIID))
+
+	// Messages of comm2 aren't received anymore, and comm1 refuses to handshake with it
+	comm2.Send(createGossipMsg(), remotePeer(5611))
+	select {
+	case <-received:
+		assert.Fail(t, "Received a message from a blacklisted peer")
+	case <-time.After(time.Second):
+	}
+	_, err := comm1.Handshake(remotePeer(5612))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "blacklisted")
+}
+
 func createGossipMsg() *proto.SignedGossipMessage {
 	msg, _ := (&proto.GossipMessage{
 		Tag:   proto.GossipMessage_EMPTY,
@@ -21,16 +21,18 @@ import (
 	"sync"
 	"sync/atomic"
 
+	corecomm "github.com/hyperledger/fabric/core/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
-	"google.golang.org/grpc"
 )
 
 type handler func(message *proto.SignedGossipMessage)
 
+type msgFilter func(message *proto.SignedGossipMessage) bool
+
 type connFactory interface {
 	createConnection(endpoint string, pkiID common.PKIidType) (*connection, error)
 }
@@ -192,7 +194,7 @@ func (cs *connectionStore) closeByPKIid(pkiID common.PKIidType) {
 	}
 }
 
-func newConnection(cl proto.GossipClient, c *grpc.ClientConn, cs proto.Gossip_GossipStreamClient, ss proto.Gossip_GossipStreamServer) *connection {
+func newConnection(cl proto.GossipClient, c *corecomm.PooledConn, cs proto.Gossip_GossipStreamClient, ss proto.Gossip_GossipStreamServer) *connection {
 	connection := &connection{
 		outBuff:      make(chan *msgSending, util.GetIntOrDefault("peer.gossip.sendBuffSize", defSendBuffSize)),
 		cl:           cl,
@@ -213,7 +215,9 @@ type connection struct {
 	logger       *logging.Logger                 // logger
 	pkiID        common.PKIidType                // pkiID of the remote endpoint
 	handler      handler                         // function to invoke upon a message reception
-	conn         *grpc.ClientConn                // gRPC connection to remote endpoint
+	admit        msgFilter                       // function that decides whether a received message is handled
+	features     features                        // optional capabilities negotiated with the remote endpoint
+	conn         *corecomm.PooledConn            // gRPC connection to remote endpoint
 	cl           proto.GossipClient              // gRPC stub of remote endpoint
 	clientStream proto.Gossip_GossipStreamClient // client-side stream to remote endpoint
 	serverStream proto.Gossip_GossipStreamServer // server-side stream to remote endpoint
@@ -240,7 +244,7 @@ func (conn *connection) close() {
 		conn.clientStream.CloseSend()
 	}
 	if conn.conn != nil {
-		conn.conn.Close()
+		conn.conn.Release()
 	}
 
 	if conn.cancel != nil {
@@ -304,27 +308,83 @@ func (conn *connection) serviceConnection() error {
 }
 
 func (conn *connection) writeToStream() {
+	// next is a message that didn't fit into the previous batch
+	var next *msgSending
 	for !conn.toDie() {
 		stream := conn.getStream()
 		if stream == nil {
 			conn.logger.Error(conn.pkiID, "Stream is nil, aborting!")
 			return
 		}
-		select {
-		case m := <-conn.outBuff:
-			err := stream.Send(m.envelope)
-			if err != nil {
+		m := next
+		next = nil
+		if m == nil {
+			select {
+			case m = <-conn.outBuff:
+			case stop := <-conn.stopChan:
+				conn.logger.Debug("Closing writing to stream")
+				conn.stopChan <- stop
+				return
+			}
+		}
+		if !conn.features.batching && conn.features.compression == "" {
+			if err := stream.Send(m.envelope); err != nil {
 				go m.onErr(err)
 				return
 			}
-		case stop := <-conn.stopChan:
-			conn.logger.Debug("Closing writing to stream")
-			conn.stopChan <- stop
+			continue
+		}
+		var batch []*msgSending
+		batch, next = conn.drain(m)
+		envelope, err := conn.pack(batch)
+		if err != nil {
+			conn.logger.Error(conn.pkiID, "Failed packing", len(batch), "messages:", err)
+			continue
+		}
+		if err := stream.Send(envelope); err != nil {
+			go batch[0].onErr(err)
 			return
 		}
 	}
 }
 
+// drain returns the given message along with the messages waiting in the send buffer,
+// as long as they fit into a single batch. The message that was taken from the buffer
+// but didn't fit into the batch is returned separately.
+func (conn *connection) drain(first *msgSending) ([]*msgSending, *msgSending) {
+	batch := []*msgSending{first}
+	if !conn.features.batching {
+		return batch, nil
+	}
+	maxSize, maxBytes := maxBatchSize(), maxBatchBytes()
+	size := envelopeSize(first.envelope)
+	for len(batch) < maxSize {
+		select {
+		case m := <-conn.outBuff:
+			size += envelopeSize(m.envelope)
+			if size > maxBytes {
+				return batch, m
+			}
+			batch = append(batch, m)
+		default:
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+// pack returns the envelope that carries the given messages to the remote endpoint
+func (conn *connection) pack(batch []*msgSending) (*proto.Envelope, error) {
+	if len(batch) == 1 && (conn.features.compression == "" || envelopeSize(batch[0].envelope) < minCompressionSize) {
+		return batch[0].envelope, nil
+	}
+	envelopes := make([]*proto.Envelope, len(batch))
+	for i, m := range batch {
+		envelopes[i] = m.envelope
+	}
+	return packBatch(envelopes, conn.features.compression)
+}
+
 func (conn *connection) readFromStream(errChan chan error, msgChan chan *proto.SignedGossipMessage) {
 	defer func() {
 		recover()
@@ -350,9 +410,49 @@ func (conn *connection) readFromStream(errChan chan error, msgChan chan *proto.S
 		if err != nil {
 			errChan <- err
 			conn.logger.Warning(conn.pkiID, "Got error, aborting:", err)
+			return
+		}
+		msgs, err := conn.unpack(msg)
+		if err != nil {
+			errChan <- err
+			conn.logger.Warning(conn.pkiID, "Got malformed batch, aborting:", err)
+			return
+		}
+		for _, msg := range msgs {
+			if conn.admit != nil && !conn.admit(msg) {
+				continue
+			}
+			msgChan <- msg
+		}
+	}
+}
+
+// unpack returns the messages carried by the given message,
+// which is either a batch of messages or a single message
+func (conn *connection) unpack(msg *proto.SignedGossipMessage) ([]*proto.SignedGossipMessage, error) {
+	batch := msg.GetBatch()
+	if batch == nil {
+		return []*proto.SignedGossipMessage{msg}, nil
+	}
+	envelopes, err := unpackBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]*proto.SignedGossipMessage, 0, len(envelopes))
+	for _, envelope := range envelopes {
+		if envelope == nil {
+			return nil, errors.New("batch contains a nil envelope")
+		}
+		m, err := envelope.ToGossipMessage()
+		if err != nil {
+			return nil, err
+		}
+		if m.GetBatch() != nil {
+			return nil, errors.New("batch contains a nested batch")
 		}
-		msgChan <- msg
+		msgs = append(msgs, m)
 	}
+	return msgs, nil
 }
 
 func (conn *connection) getStream() stream {
@@ -179,6 +179,16 @@ func (mock *commMock) PresumedDead() <-chan common.PKIidType {
 	return mock.deadChannel
 }
 
+// Blacklisted returns a read-only channel for PKI-IDs of peers that have been blacklisted
+func (mock *commMock) Blacklisted() <-chan common.PKIidType {
+	return make(chan common.PKIidType)
+}
+
+// IsBlacklisted returns false, as no peer is ever blacklisted
+func (mock *commMock) IsBlacklisted(pkiID common.PKIidType) bool {
+	return false
+}
+
 // CloseConn closes a connection to a certain endpoint
 func (mock *commMock) CloseConn(peer *comm.RemotePeer) {
 	// NOOP
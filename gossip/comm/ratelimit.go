@@ -0,0 +1,165 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+const (
+	defMembershipMsgRate   = 500
+	defPullMsgRate         = 500
+	defStateInfoMsgRate    = 500
+	defStateRequestMsgRate = 100
+
+	defViolationThreshold = 1000
+	defViolationWindow    = 10 * time.Second
+	defBlacklistDuration  = 5 * time.Minute
+)
+
+// msgClass is a class of messages that are rate limited together
+type msgClass int
+
+const (
+	unlimitedMsg msgClass = iota
+	membershipMsg
+	pullMsg
+	stateInfoMsg
+	stateRequestMsg
+)
+
+func classOf(m *proto.GossipMessage) msgClass {
+	switch {
+	case m.IsAliveMsg() || m.GetMemReq() != nil || m.GetMemRes() != nil:
+		return membershipMsg
+	case m.IsPullMsg():
+		return pullMsg
+	case m.IsStateInfoMsg() || m.IsStateInfoSnapshot() || m.IsStateInfoPullRequestMsg():
+		return stateInfoMsg
+	case m.GetStateRequest() != nil:
+		return stateRequestMsg
+	}
+	return unlimitedMsg
+}
+
+type violations struct {
+	count int
+	since time.Time
+}
+
+// msgRateLimiter limits the rate of messages received from each remote peer,
+// per class of messages. Messages exceeding the limits are dropped, and peers
+// that exceed the limits violationThreshold times within violationWindow are
+// blacklisted for blacklistDuration, during which all their messages are dropped.
+type msgRateLimiter struct {
+	sync.Mutex
+	limiters           map[msgClass]*util.RateLimiter
+	violationThreshold int
+	violationWindow    time.Duration
+	blacklistDuration  time.Duration
+	violations         map[string]*violations
+	blacklist          map[string]time.Time
+}
+
+// newMsgRateLimiter creates a msgRateLimiter according to the configuration.
+// The rate of a class is in messages per second, and a peer may send
+// twice as many messages of the class in a burst.
+// A negative rate disables the limit of the class.
+func newMsgRateLimiter() *msgRateLimiter {
+	rates := map[msgClass]int{
+		membershipMsg:   util.GetIntOrDefault("peer.gossip.rateLimit.membership", defMembershipMsgRate),
+		pullMsg:         util.GetIntOrDefault("peer.gossip.rateLimit.pull", defPullMsgRate),
+		stateInfoMsg:    util.GetIntOrDefault("peer.gossip.rateLimit.stateInfo", defStateInfoMsgRate),
+		stateRequestMsg: util.GetIntOrDefault("peer.gossip.rateLimit.stateRequest", defStateRequestMsgRate),
+	}
+	l := &msgRateLimiter{
+		limiters:           make(map[msgClass]*util.RateLimiter),
+		violationThreshold: util.GetIntOrDefault("peer.gossip.rateLimit.violationThreshold", defViolationThreshold),
+		violationWindow:    util.GetDurationOrDefault("peer.gossip.rateLimit.violationWindow", defViolationWindow),
+		blacklistDuration:  util.GetDurationOrDefault("peer.gossip.rateLimit.blacklistDuration", defBlacklistDuration),
+		violations:         make(map[string]*violations),
+		blacklist:          make(map[string]time.Time),
+	}
+	for class, rate := range rates {
+		if rate < 0 {
+			continue
+		}
+		l.limiters[class] = util.NewRateLimiter(float64(rate), float64(2*rate))
+	}
+	return l
+}
+
+// admit returns whether a message received from the given peer is within the
+// rate limits, and whether the peer got blacklisted due to the message
+func (l *msgRateLimiter) admit(pkiID common.PKIidType, m *proto.SignedGossipMessage) (admitted bool, blacklisted bool) {
+	if l.isBlacklisted(pkiID) {
+		return false, false
+	}
+	limiter, isLimited := l.limiters[classOf(m.GossipMessage)]
+	if !isLimited || limiter.Take(string(pkiID), 1) == 1 {
+		return true, false
+	}
+	return false, l.violate(pkiID)
+}
+
+// violate records a violation of the rate limits by the given peer,
+// and returns whether the peer got blacklisted as a result
+func (l *msgRateLimiter) violate(pkiID common.PKIidType) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	now := time.Now()
+	for id, v := range l.violations {
+		if now.Sub(v.since) > l.violationWindow {
+			delete(l.violations, id)
+		}
+	}
+
+	v, exists := l.violations[string(pkiID)]
+	if !exists {
+		v = &violations{since: now}
+		l.violations[string(pkiID)] = v
+	}
+	v.count++
+	if v.count < l.violationThreshold {
+		return false
+	}
+	delete(l.violations, string(pkiID))
+	l.blacklist[string(pkiID)] = now.Add(l.blacklistDuration)
+	return true
+}
+
+// isBlacklisted returns whether the given peer is currently blacklisted
+func (l *msgRateLimiter) isBlacklisted(pkiID common.PKIidType) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	expiration, exists := l.blacklist[string(pkiID)]
+	if !exists {
+		return false
+	}
+	if time.Now().After(expiration) {
+		delete(l.blacklist, string(pkiID))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package comm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMsgRateLimiter(rate int, violationThreshold int, blacklistDuration time.Duration) *msgRateLimiter {
+	l := &msgRateLimiter{
+		limiters:           make(map[msgClass]*util.RateLimiter),
+		violationThreshold: violationThreshold,
+		violationWindow:    time.Minute,
+		blacklistDuration:  blacklistDuration,
+		violations:         make(map[string]*violations),
+		blacklist:          make(map[string]time.Time),
+	}
+	for _, class := range []msgClass{membershipMsg, pullMsg, stateInfoMsg, stateRequestMsg} {
+		l.limiters[class] = util.NewRateLimiter(float64(rate), float64(2*rate))
+	}
+	return l
+}
+
+func createAliveMsg() *proto.SignedGossipMessage {
+	msg, _ := (&proto.GossipMessage{
+		Tag: proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_AliveMsg{
+			AliveMsg: &proto.AliveMessage{},
+		},
+	}).NoopSign()
+	return msg
+}
+
+func TestClassOf(t *testing.T) {
+	msgs := map[msgClass]*proto.GossipMessage{
+		membershipMsg:   {Content: &proto.GossipMessage_MemReq{MemReq: &proto.MembershipRequest{}}},
+		pullMsg:         {Content: &proto.GossipMessage_Hello{Hello: &proto.GossipHello{}}},
+		stateInfoMsg:    {Content: &proto.GossipMessage_StateInfoPullReq{StateInfoPullReq: &proto.StateInfoPullRequest{}}},
+		stateRequestMsg: {Content: &proto.GossipMessage_StateRequest{StateRequest: &proto.RemoteStateRequest{}}},
+		unlimitedMsg:    {Content: &proto.GossipMessage_StateResponse{StateResponse: &proto.RemoteStateResponse{}}},
+	}
+	for class, msg := range msgs {
+		assert.Equal(t, class, classOf(msg))
+	}
+	assert.Equal(t, membershipMsg, classOf(createAliveMsg().GossipMessage))
+	assert.Equal(t, unlimitedMsg, classOf(createGossipMsg().GossipMessage))
+}
+
+func TestMsgRateLimiter(t *testing.T) {
+	l := newTestMsgRateLimiter(1, 3, time.Minute)
+	p1 := common.PKIidType("p1")
+	p2 := common.PKIidType("p2")
+
+	// Messages up to the burst are admitted, and the rest are dropped
+	for i := 0; i < 2; i++ {
+		admitted, blacklisted := l.admit(p1, createAliveMsg())
+		assert.True(t, admitted)
+		assert.False(t, blacklisted)
+	}
+	admitted, blacklisted := l.admit(p1, createAliveMsg())
+	assert.False(t, admitted)
+	assert.False(t, blacklisted)
+
+	// Messages that aren't rate limited are admitted regardless
+	admitted, _ = l.admit(p1, createGossipMsg())
+	assert.True(t, admitted)
+	// Other peers are limited independently
+	admitted, _ = l.admit(p2, createAliveMsg())
+	assert.True(t, admitted)
+
+	// The third violation blacklists the peer
+	_, blacklisted = l.admit(p1, createAliveMsg())
+	assert.False(t, blacklisted)
+	_, blacklisted = l.admit(p1, createAliveMsg())
+	assert.True(t, blacklisted)
+	assert.True(t, l.isBlacklisted(p1))
+	assert.False(t, l.isBlacklisted(p2))
+
+	// Blacklisted peers have all of their messages dropped
+	admitted, blacklisted = l.admit(p1, createGossipMsg())
+	assert.False(t, admitted)
+	assert.False(t, blacklisted)
+}
+
+func TestMsgRateLimiterBlacklistExpiration(t *testing.T) {
+	l := newTestMsgRateLimiter(1, 1, time.Millisecond*100)
+	p := common.PKIidType("p")
+	for i := 0; i < 2; i++ {
+		l.admit(p, createAliveMsg())
+	}
+	_, blacklisted := l.admit(p, createAliveMsg())
+	assert.True(t, blacklisted)
+	assert.True(t, l.isBlacklisted(p))
+
+	time.Sleep(time.Millisecond * 200)
+	assert.False(t, l.isBlacklisted(p))
+	admitted, _ := l.admit(p, createGossipMsg())
+	assert.True(t, admitted)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sim
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/util"
+)
+
+// NewRealClock returns the wall clock, which delays messages by wall-clock time
+func NewRealClock() util.Clock {
+	return util.WallClock()
+}
+
+type timer struct {
+	clock *ManualClock
+	at    time.Time
+	seq   uint64
+	// fire is called by Advance once the clock reaches at, without holding the lock of the clock
+	fire func(now time.Time)
+}
+
+func (t *timer) Stop() bool {
+	return t.clock.remove(t)
+}
+
+type ticker struct {
+	clock  *ManualClock
+	period time.Duration
+	c      chan time.Time
+
+	lock    sync.Mutex
+	next    *timer
+	stopped bool
+}
+
+func (t *ticker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *ticker) Stop() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.stopped = true
+	t.next.Stop()
+}
+
+// tick sends the current time unless the previous tick wasn't received yet,
+// like the tickers of package time do, and schedules the next tick
+func (t *ticker) tick(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.stopped {
+		return
+	}
+	select {
+	case t.c <- now:
+	default:
+	}
+	t.next = t.clock.schedule(t.period, t.tick)
+}
+
+// timersByTime sorts timers by the time they fire at, and then by the order they were scheduled in
+type timersByTime []*timer
+
+func (ts timersByTime) Len() int      { return len(ts) }
+func (ts timersByTime) Swap(i, j int) { ts[i], ts[j] = ts[j], ts[i] }
+func (ts timersByTime) Less(i, j int) bool {
+	if ts[i].at.Equal(ts[j].at) {
+		return ts[i].seq < ts[j].seq
+	}
+	return ts[i].at.Before(ts[j].at)
+}
+
+// ManualClock is a Clock that only advances when told to. Set as the clock of
+// the gossip components by util.SetClock, it drives their timers along with the
+// delivery of the messages in flight, which makes simulations run in virtual time.
+type ManualClock struct {
+	lock   sync.Mutex
+	now    time.Time
+	seq    uint64
+	timers timersByTime
+}
+
+// NewManualClock returns a ManualClock that starts at the given time
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *ManualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// After returns a channel the current time is sent on once the clock advanced by the given duration
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	ch := make(chan time.Time, 1)
+	c.schedule(d, func(now time.Time) {
+		ch <- now
+	})
+	return ch
+}
+
+// AfterFunc calls f in its own goroutine once the clock advanced by the given duration
+func (c *ManualClock) AfterFunc(d time.Duration, f func()) util.Timer {
+	return c.schedule(d, func(time.Time) {
+		go f()
+	})
+}
+
+// NewTicker returns a Ticker that ticks every time the clock advanced by the given duration
+func (c *ManualClock) NewTicker(d time.Duration) util.Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	t := &ticker{clock: c, period: d, c: make(chan time.Time, 1)}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.next = c.schedule(d, t.tick)
+	return t
+}
+
+// schedule registers fire to be called once the clock advanced by the given duration,
+// or calls it right away if the duration isn't positive
+func (c *ManualClock) schedule(d time.Duration, fire func(now time.Time)) *timer {
+	c.lock.Lock()
+	t := &timer{clock: c, at: c.now.Add(d), seq: c.seq, fire: fire}
+	c.seq++
+	if d <= 0 {
+		now := c.now
+		c.lock.Unlock()
+		fire(now)
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.lock.Unlock()
+	return t
+}
+
+func (c *ManualClock) remove(t *timer) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by the given duration. The timers that
+// their time has come fire in the order of their time, each with the clock
+// set to its time, so that a ticker ticks as many times as it would have
+// if the clock had advanced gradually.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	end := c.now.Add(d)
+	for {
+		sort.Sort(c.timers)
+		if len(c.timers) == 0 || c.timers[0].at.After(end) {
+			break
+		}
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.at
+		c.lock.Unlock()
+		t.fire(t.at)
+		c.lock.Lock()
+	}
+	c.now = end
+	c.lock.Unlock()
+}
+
+// Pending returns the number of timers that are scheduled and didn't fire yet
+func (c *ManualClock) Pending() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
@@ -0,0 +1,448 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sim
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/op/go-logging"
+)
+
+const presumedDeadChanSize = 100
+
+// LinkConditions are the conditions of a link between two nodes of the network
+type LinkConditions struct {
+	// Latency is the minimal time a message spends in flight
+	Latency time.Duration
+	// Jitter is the maximal random time added to the latency of each message.
+	// Messages sent over the same link might be reordered if it is positive.
+	Jitter time.Duration
+	// Loss is the probability of a message to be dropped, between 0 and 1
+	Loss float64
+}
+
+// Stats are the counters of messages the network handled
+type Stats struct {
+	// Sent is the number of messages sent to reachable nodes
+	Sent uint64
+	// Delivered is the number of messages that reached their destination
+	Delivered uint64
+	// Dropped is the number of messages lost in flight, or sent to nodes
+	// that weren't reachable
+	Dropped uint64
+}
+
+type link struct {
+	from string
+	to   string
+}
+
+// Network is an in-memory network that connects instances of comm.Comm,
+// without opening sockets. The conditions of its links can be changed at any time,
+// and nodes can be partitioned from each other or disconnected altogether.
+type Network struct {
+	lock       sync.RWMutex
+	clock      util.Clock
+	conditions LinkConditions
+	links      map[link]LinkConditions
+	nodes      map[string]*node
+	partitions map[string]int
+	down       map[string]struct{}
+
+	randLock sync.Mutex
+	rand     *rand.Rand
+
+	sent      uint64
+	delivered uint64
+	dropped   uint64
+
+	logger *logging.Logger
+}
+
+// NewNetwork creates a network that delays messages using the given clock,
+// and that the links of have the given conditions unless set otherwise
+func NewNetwork(clock util.Clock, conditions LinkConditions) *Network {
+	return &Network{
+		clock:      clock,
+		conditions: conditions,
+		links:      make(map[link]LinkConditions),
+		nodes:      make(map[string]*node),
+		partitions: make(map[string]int),
+		down:       make(map[string]struct{}),
+		rand:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		logger:     util.GetLogger(util.LoggingSimModule, ""),
+	}
+}
+
+// NewComm attaches a node with the given endpoint and identity to the network,
+// and returns the communication module of the node
+func (n *Network) NewComm(endpoint string, identity api.PeerIdentityType, pkiID common.PKIidType) (comm.Comm, error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if existing, exists := n.nodes[endpoint]; exists && !existing.isStopped() {
+		return nil, fmt.Errorf("endpoint %s is already in use", endpoint)
+	}
+	nd := &node{
+		net:          n,
+		endpoint:     endpoint,
+		identity:     identity,
+		pkiID:        pkiID,
+		demux:        comm.NewChannelDemultiplexer(),
+		presumedDead: make(chan common.PKIidType, presumedDeadChanSize),
+		stopChan:     make(chan struct{}),
+	}
+	n.nodes[endpoint] = nd
+	return nd, nil
+}
+
+// SetConditions sets the conditions of the links that weren't set explicitly
+func (n *Network) SetConditions(conditions LinkConditions) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.conditions = conditions
+}
+
+// SetLinkConditions sets the conditions of the link from one endpoint to another.
+// The link in the opposite direction isn't affected.
+func (n *Network) SetLinkConditions(from string, to string, conditions LinkConditions) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.links[link{from: from, to: to}] = conditions
+}
+
+// Partition splits the network into the given groups of endpoints, such that
+// nodes of different groups can't reach each other. The endpoints that don't
+// appear in any group form a group of their own.
+func (n *Network) Partition(groups ...[]string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.partitions = make(map[string]int)
+	for i, group := range groups {
+		for _, endpoint := range group {
+			n.partitions[endpoint] = i + 1
+		}
+	}
+}
+
+// Heal removes all partitions of the network
+func (n *Network) Heal() {
+	n.Partition()
+}
+
+// Disconnect makes the node with the given endpoint unreachable,
+// and makes all other nodes unreachable from it
+func (n *Network) Disconnect(endpoint string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.down[endpoint] = struct{}{}
+}
+
+// Reconnect undoes a former disconnection of the node with the given endpoint
+func (n *Network) Reconnect(endpoint string) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	delete(n.down, endpoint)
+}
+
+// Stats returns the counters of the messages the network handled so far
+func (n *Network) Stats() Stats {
+	return Stats{
+		Sent:      atomic.LoadUint64(&n.sent),
+		Delivered: atomic.LoadUint64(&n.delivered),
+		Dropped:   atomic.LoadUint64(&n.dropped),
+	}
+}
+
+// route returns the node a message from the given node to the given peer reaches,
+// along with the conditions of the link between them
+func (n *Network) route(from *node, peer *comm.RemotePeer) (*node, LinkConditions, error) {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+	to, exists := n.nodes[peer.Endpoint]
+	if !exists || to.isStopped() {
+		return nil, LinkConditions{}, fmt.Errorf("%s is unreachable", peer.Endpoint)
+	}
+	if len(peer.PKIID) > 0 && !bytes.Equal(peer.PKIID, to.pkiID) {
+		return nil, LinkConditions{}, errors.New("PKI-ID of remote peer doesn't match expected PKI-ID")
+	}
+	if !n.reachable(from.endpoint, to.endpoint) {
+		return nil, LinkConditions{}, fmt.Errorf("%s is unreachable from %s", to.endpoint, from.endpoint)
+	}
+	conditions, exists := n.links[link{from: from.endpoint, to: to.endpoint}]
+	if !exists {
+		conditions = n.conditions
+	}
+	return to, conditions, nil
+}
+
+func (n *Network) reachable(from string, to string) bool {
+	if _, isDown := n.down[from]; isDown {
+		return false
+	}
+	if _, isDown := n.down[to]; isDown {
+		return false
+	}
+	return n.partitions[from] == n.partitions[to]
+}
+
+// transmit delivers a message from one node to another according to the
+// conditions of the link between them
+func (n *Network) transmit(from *node, to *node, conditions LinkConditions, envelope []byte) {
+	atomic.AddUint64(&n.sent, 1)
+	n.randLock.Lock()
+	lost := n.rand.Float64() < conditions.Loss
+	delay := conditions.Latency
+	if conditions.Jitter > 0 {
+		delay += time.Duration(n.rand.Int63n(int64(conditions.Jitter)))
+	}
+	n.randLock.Unlock()
+	if lost {
+		atomic.AddUint64(&n.dropped, 1)
+		return
+	}
+
+	n.clock.AfterFunc(delay, func() {
+		// The nodes might have been partitioned while the message was in flight
+		n.lock.RLock()
+		reachable := n.reachable(from.endpoint, to.endpoint)
+		n.lock.RUnlock()
+		if !reachable || to.isStopped() {
+			atomic.AddUint64(&n.dropped, 1)
+			return
+		}
+		atomic.AddUint64(&n.delivered, 1)
+		to.receive(from, envelope)
+	})
+}
+
+// node is a comm.Comm attached to a Network
+type node struct {
+	net           *Network
+	endpoint      string
+	identity      api.PeerIdentityType
+	pkiID         common.PKIidType
+	demux         *comm.ChannelDeMultiplexer
+	presumedDead  chan common.PKIidType
+	lock          sync.Mutex
+	subscriptions []chan proto.ReceivedMessage
+	stopChan      chan struct{}
+	stopping      int32
+	stopWG        sync.WaitGroup
+}
+
+// GetPKIid returns this instance's PKI id
+func (nd *node) GetPKIid() common.PKIidType {
+	return nd.pkiID
+}
+
+// Send sends a message to remote peers
+func (nd *node) Send(msg *proto.SignedGossipMessage, peers ...*comm.RemotePeer) {
+	if nd.isStopped() {
+		return
+	}
+	// The message is serialized right away, as it would have been sent over the wire,
+	// since the sender might modify it after it is sent
+	envelope, err := pb.Marshal(msg.Envelope)
+	if err != nil {
+		nd.net.logger.Error("Failed marshaling message:", err)
+		return
+	}
+	for _, peer := range peers {
+		to, conditions, err := nd.net.route(nd, peer)
+		if err != nil {
+			nd.net.logger.Debug("Failed sending to", peer, "reason:", err)
+			atomic.AddUint64(&nd.net.dropped, 1)
+			nd.disconnect(peer.PKIID)
+			continue
+		}
+		nd.net.transmit(nd, to, conditions, envelope)
+	}
+}
+
+// Probe returns nil if the remote peer is reachable, and an error otherwise
+func (nd *node) Probe(peer *comm.RemotePeer) error {
+	if nd.isStopped() {
+		return errors.New("Stopping")
+	}
+	_, _, err := nd.net.route(nd, &comm.RemotePeer{Endpoint: peer.Endpoint})
+	return err
+}
+
+// Handshake returns the identity of the remote peer if it is reachable
+func (nd *node) Handshake(peer *comm.RemotePeer) (api.PeerIdentityType, error) {
+	if nd.isStopped() {
+		return nil, errors.New("Stopping")
+	}
+	to, _, err := nd.net.route(nd, peer)
+	if err != nil {
+		return nil, err
+	}
+	return to.identity, nil
+}
+
+// Accept returns a dedicated read-only channel for messages sent by other nodes that match a certain predicate
+func (nd *node) Accept(acceptor common.MessageAcceptor) <-chan proto.ReceivedMessage {
+	genericChan := nd.demux.AddChannel(acceptor)
+	specificChan := make(chan proto.ReceivedMessage, 10)
+
+	nd.lock.Lock()
+	defer nd.lock.Unlock()
+	if nd.isStopped() {
+		return specificChan
+	}
+	nd.subscriptions = append(nd.subscriptions, specificChan)
+
+	nd.stopWG.Add(1)
+	go func() {
+		defer nd.stopWG.Done()
+		for {
+			select {
+			case msg, ok := <-genericChan:
+				if !ok {
+					return
+				}
+				select {
+				case specificChan <- msg.(proto.ReceivedMessage):
+				case <-nd.stopChan:
+					return
+				}
+			case <-nd.stopChan:
+				return
+			}
+		}
+	}()
+	return specificChan
+}
+
+// PresumedDead returns a read-only channel for PKI-IDs of peers that messages couldn't be sent to
+func (nd *node) PresumedDead() <-chan common.PKIidType {
+	return nd.presumedDead
+}
+
+// Blacklisted returns a read-only channel for PKI-IDs of blacklisted peers.
+// The network doesn't rate limit messages, hence no peer is ever blacklisted.
+func (nd *node) Blacklisted() <-chan common.PKIidType {
+	return make(chan common.PKIidType)
+}
+
+// IsBlacklisted returns false, as no peer is ever blacklisted
+func (nd *node) IsBlacklisted(pkiID common.PKIidType) bool {
+	return false
+}
+
+// CloseConn does nothing, as there are no connections to close
+func (nd *node) CloseConn(peer *comm.RemotePeer) {
+}
+
+// Stop detaches the node from the network
+func (nd *node) Stop() {
+	if !atomic.CompareAndSwapInt32(&nd.stopping, 0, 1) {
+		return
+	}
+	nd.lock.Lock()
+	close(nd.stopChan)
+	nd.lock.Unlock()
+	nd.demux.Close()
+	// The subscriptions are closed only once nothing is forwarded to them anymore
+	nd.stopWG.Wait()
+	for _, ch := range nd.subscriptions {
+		close(ch)
+	}
+}
+
+func (nd *node) isStopped() bool {
+	return atomic.LoadInt32(&nd.stopping) == 1
+}
+
+func (nd *node) disconnect(pkiID common.PKIidType) {
+	if len(pkiID) == 0 {
+		return
+	}
+	select {
+	case nd.presumedDead <- pkiID:
+	default:
+	}
+}
+
+// receive publishes a message that arrived from the given node to the subscribers of the node
+func (nd *node) receive(from *node, envelope []byte) {
+	env := &proto.Envelope{}
+	if err := pb.Unmarshal(envelope, env); err != nil {
+		nd.net.logger.Warning("Failed unmarshaling message from", from.endpoint, ":", err)
+		return
+	}
+	m, err := env.ToGossipMessage()
+	if err != nil {
+		nd.net.logger.Warning("Failed parsing message from", from.endpoint, ":", err)
+		return
+	}
+	nd.demux.DeMultiplex(&receivedMessage{
+		SignedGossipMessage: m,
+		from:                from,
+		to:                  nd,
+		connInfo: &proto.ConnectionInfo{
+			ID:       from.pkiID,
+			Identity: from.identity,
+			Endpoint: from.endpoint,
+		},
+	})
+}
+
+// receivedMessage is a message received over the network
+type receivedMessage struct {
+	*proto.SignedGossipMessage
+	from     *node
+	to       *node
+	connInfo *proto.ConnectionInfo
+}
+
+// Respond sends a message back to the node the message was received from
+func (m *receivedMessage) Respond(msg *proto.GossipMessage) {
+	sMsg, err := msg.NoopSign()
+	if err != nil {
+		m.to.net.logger.Error("Failed creating SignedGossipMessage:", err)
+		return
+	}
+	m.to.Send(sMsg, &comm.RemotePeer{Endpoint: m.from.endpoint, PKIID: m.from.pkiID})
+}
+
+// GetGossipMessage returns the inner GossipMessage
+func (m *receivedMessage) GetGossipMessage() *proto.SignedGossipMessage {
+	return m.SignedGossipMessage
+}
+
+// GetSourceEnvelope returns the Envelope the message was constructed with
+func (m *receivedMessage) GetSourceEnvelope() *proto.Envelope {
+	return m.Envelope
+}
+
+// GetConnectionInfo returns information about the node that sent the message
+func (m *receivedMessage) GetConnectionInfo() *proto.ConnectionInfo {
+	return m.connInfo
+}
@@ -0,0 +1,224 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sim
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm"
+	"github.com/hyperledger/fabric/gossip/common"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestComm(t *testing.T, net *Network, endpoint string) comm.Comm {
+	c, err := net.NewComm(endpoint, []byte(endpoint), common.PKIidType(endpoint))
+	assert.NoError(t, err)
+	return c
+}
+
+func stateRequest(seq uint64) *proto.SignedGossipMessage {
+	sMsg, _ := (&proto.GossipMessage{
+		Nonce: seq,
+		Tag:   proto.GossipMessage_EMPTY,
+		Content: &proto.GossipMessage_StateRequest{
+			StateRequest: &proto.RemoteStateRequest{StartSeqNum: seq, EndSeqNum: seq},
+		},
+	}).NoopSign()
+	return sMsg
+}
+
+func all(interface{}) bool {
+	return true
+}
+
+func remotePeer(endpoint string) *comm.RemotePeer {
+	return &comm.RemotePeer{Endpoint: endpoint, PKIID: common.PKIidType(endpoint)}
+}
+
+func receive(t *testing.T, ch <-chan proto.ReceivedMessage) proto.ReceivedMessage {
+	select {
+	case msg := <-ch:
+		return msg
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "Didn't receive a message")
+		return nil
+	}
+}
+
+func assertNothingReceived(t *testing.T, ch <-chan proto.ReceivedMessage) {
+	select {
+	case msg := <-ch:
+		assert.Fail(t, "Received an unexpected message", msg)
+	case <-time.After(time.Millisecond * 200):
+	}
+}
+
+func TestSendAndRespond(t *testing.T) {
+	net := NewNetwork(NewRealClock(), LinkConditions{})
+	a := newTestComm(t, net, "a")
+	defer a.Stop()
+	b := newTestComm(t, net, "b")
+	defer b.Stop()
+
+	_, err := net.NewComm("a", []byte("a"), common.PKIidType("a"))
+	assert.Error(t, err, "Endpoint is already in use")
+
+	inA := a.Accept(all)
+	inB := b.Accept(all)
+	a.Send(stateRequest(1), remotePeer("b"))
+	msg := receive(t, inB)
+	assert.Equal(t, uint64(1), msg.GetGossipMessage().Nonce)
+	assert.Equal(t, common.PKIidType("a"), msg.GetConnectionInfo().ID)
+	assert.Equal(t, "a", string(msg.GetConnectionInfo().Identity))
+
+	msg.Respond(stateRequest(2).GossipMessage)
+	assert.Equal(t, uint64(2), receive(t, inA).GetGossipMessage().Nonce)
+
+	identity, err := a.Handshake(&comm.RemotePeer{Endpoint: "b"})
+	assert.NoError(t, err)
+	assert.Equal(t, "b", string(identity))
+	_, err = a.Handshake(&comm.RemotePeer{Endpoint: "b", PKIID: common.PKIidType("c")})
+	assert.Error(t, err)
+	assert.Error(t, a.Probe(remotePeer("c")))
+
+	stats := net.Stats()
+	assert.Equal(t, uint64(2), stats.Sent)
+	assert.Equal(t, uint64(2), stats.Delivered)
+}
+
+func TestLatencyAndLoss(t *testing.T) {
+	clock := NewManualClock(time.Now())
+	net := NewNetwork(clock, LinkConditions{Latency: time.Second})
+	a := newTestComm(t, net, "a")
+	defer a.Stop()
+	b := newTestComm(t, net, "b")
+	defer b.Stop()
+	inB := b.Accept(all)
+
+	a.Send(stateRequest(1), remotePeer("b"))
+	assertNothingReceived(t, inB)
+	clock.Advance(time.Millisecond * 999)
+	assertNothingReceived(t, inB)
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, uint64(1), receive(t, inB).GetGossipMessage().Nonce)
+	assert.Equal(t, 0, clock.Pending())
+
+	// A lossy link in one direction doesn't affect the other direction
+	net.SetLinkConditions("a", "b", LinkConditions{Loss: 1})
+	inA := a.Accept(all)
+	for i := 0; i < 10; i++ {
+		a.Send(stateRequest(uint64(i)), remotePeer("b"))
+	}
+	b.Send(stateRequest(100), remotePeer("a"))
+	clock.Advance(time.Second)
+	assert.Equal(t, uint64(100), receive(t, inA).GetGossipMessage().Nonce)
+	assertNothingReceived(t, inB)
+	assert.Equal(t, uint64(10), net.Stats().Dropped)
+}
+
+func TestManualClockTimers(t *testing.T) {
+	start := time.Now()
+	clock := NewManualClock(start)
+	after := clock.After(time.Second)
+	fired := make(chan time.Time, 1)
+	clock.AfterFunc(time.Second, func() {
+		fired <- clock.Now()
+	})
+	stopped := clock.AfterFunc(time.Second, func() {
+		t.Fatal("A stopped timer shouldn't fire")
+	})
+	assert.True(t, stopped.Stop())
+	ticker := clock.NewTicker(300 * time.Millisecond)
+
+	clock.Advance(999 * time.Millisecond)
+	assert.Len(t, after, 0)
+	// The ticks that weren't received are dropped, like the ones of package time
+	assert.Equal(t, start.Add(300*time.Millisecond), <-ticker.C())
+	assert.Len(t, ticker.C(), 0)
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-after)
+	select {
+	case now := <-fired:
+		assert.Equal(t, start.Add(time.Second), now)
+	case <-time.After(time.Second):
+		t.Fatal("Timer didn't fire")
+	}
+	assert.False(t, stopped.Stop())
+
+	ticker.Stop()
+	clock.Advance(time.Second)
+	assert.Len(t, ticker.C(), 0)
+	assert.Equal(t, 0, clock.Pending())
+}
+
+func TestPartitionAndDisconnect(t *testing.T) {
+	net := NewNetwork(NewRealClock(), LinkConditions{})
+	a := newTestComm(t, net, "a")
+	defer a.Stop()
+	b := newTestComm(t, net, "b")
+	defer b.Stop()
+	c := newTestComm(t, net, "c")
+	defer c.Stop()
+	inB := b.Accept(all)
+
+	net.Partition([]string{"a"})
+	assert.Error(t, a.Probe(remotePeer("b")))
+	assert.NoError(t, c.Probe(remotePeer("b")))
+	a.Send(stateRequest(1), remotePeer("b"))
+	assertNothingReceived(t, inB)
+	// The sender presumes the unreachable peer is dead
+	select {
+	case pkiID := <-a.PresumedDead():
+		assert.Equal(t, common.PKIidType("b"), pkiID)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Peer wasn't presumed dead")
+	}
+
+	net.Heal()
+	a.Send(stateRequest(2), remotePeer("b"))
+	assert.Equal(t, uint64(2), receive(t, inB).GetGossipMessage().Nonce)
+
+	net.Disconnect("b")
+	assert.Error(t, a.Probe(remotePeer("b")))
+	assert.Error(t, b.Probe(remotePeer("c")))
+	net.Reconnect("b")
+	assert.NoError(t, a.Probe(remotePeer("b")))
+}
+
+func TestStop(t *testing.T) {
+	net := NewNetwork(NewRealClock(), LinkConditions{})
+	a := newTestComm(t, net, "a")
+	defer a.Stop()
+	b := newTestComm(t, net, "b")
+	inB := b.Accept(all)
+	a.Send(stateRequest(1), remotePeer("b"))
+
+	b.Stop()
+	// Stopping twice is harmless
+	b.Stop()
+	for range inB {
+	}
+	assert.Error(t, a.Probe(remotePeer("b")))
+
+	// The endpoint of a stopped node can be reused, e.g. by a restarted peer
+	b = newTestComm(t, net, "b")
+	defer b.Stop()
+	assert.NoError(t, a.Probe(remotePeer("b")))
+}
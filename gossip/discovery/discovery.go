@@ -17,7 +17,9 @@ limitations under the License.
 package discovery
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hyperledger/fabric/gossip/common"
 	proto "github.com/hyperledger/fabric/protos/gossip"
@@ -107,6 +109,10 @@ type PeerIdentification struct {
 
 type identifier func() (*PeerIdentification, error)
 
+// ErrConnectionAborted is returned by an identifier in order to make
+// the discovery instance stop attempting to connect to the remote peer
+var ErrConnectionAborted = errors.New("connection attempts aborted")
+
 // Discovery is the interface that represents a discovery module
 type Discovery interface {
 
@@ -119,7 +125,7 @@ type Discovery interface {
 	// UpdateMetadata updates this instance's metadata
 	UpdateMetadata([]byte)
 
-	// UpdateEndpoint updates this instance's endpoint
+	// UpdateEndpoint updates this instance's endpoint, and announces it to other peers
 	UpdateEndpoint(string)
 
 	// Stops this instance
@@ -128,6 +134,10 @@ type Discovery interface {
 	// GetMembership returns the alive members in the view
 	GetMembership() []NetworkMember
 
+	// LastAlive returns the time an alive message of the given member was last received,
+	// or the zero time if the member is not considered alive
+	LastAlive(PKIID common.PKIidType) time.Time
+
 	// InitiateSync makes the instance ask a given number of peers
 	// for their membership information
 	InitiateSync(peerNum int)
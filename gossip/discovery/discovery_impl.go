@@ -139,7 +139,8 @@ func NewDiscoveryService(self NetworkMember, comm CommService, crypt CryptoServi
 // Lookup returns a network member, or nil if not found
 func (d *gossipDiscoveryImpl) Lookup(PKIID common.PKIidType) *NetworkMember {
 	if bytes.Equal(PKIID, d.self.PKIid) {
-		return &d.self
+		self := d.Self()
+		return &self
 	}
 	d.lock.RLock()
 	defer d.lock.RUnlock()
@@ -160,12 +161,16 @@ func (d *gossipDiscoveryImpl) Connect(member NetworkMember, id identifier) {
 	go func() {
 		for i := 0; i < maxConnectionAttempts && !d.toDie(); i++ {
 			id, err := id()
+			if err == ErrConnectionAborted {
+				d.logger.Info("Stopped connecting to", member)
+				return
+			}
 			if err != nil {
 				if d.toDie() {
 					return
 				}
 				d.logger.Warning("Could not connect to", member, ":", err)
-				time.Sleep(getReconnectInterval())
+				util.Sleep(getReconnectInterval())
 				continue
 			}
 			peer := &NetworkMember{
@@ -198,7 +203,7 @@ func (d *gossipDiscoveryImpl) Connect(member NetworkMember, id identifier) {
 
 func (d *gossipDiscoveryImpl) isMyOwnEndpoint(endpoint string) bool {
 	return endpoint == fmt.Sprintf("127.0.0.1:%d", d.port) || endpoint == fmt.Sprintf("localhost:%d", d.port) ||
-		endpoint == d.self.InternalEndpoint || endpoint == d.self.Endpoint
+		endpoint == d.Self().InternalEndpoint || endpoint == d.Self().Endpoint
 }
 
 func (d *gossipDiscoveryImpl) validateSelfConfig() {
@@ -231,7 +236,7 @@ func (d *gossipDiscoveryImpl) sendUntilAcked(peer *NetworkMember, message *proto
 		if _, timeoutErr := sub.Listen(); timeoutErr == nil {
 			return
 		}
-		time.Sleep(getReconnectInterval())
+		util.Sleep(getReconnectInterval())
 	}
 }
 
@@ -495,13 +500,15 @@ func (d *gossipDiscoveryImpl) handleAliveMessage(m *proto.SignedGossipMessage) {
 	pkiID := m.GetAliveMsg().Membership.PkiId
 	if equalPKIid(pkiID, d.self.PKIid) {
 		d.logger.Debug("Got alive message about ourselves,", m)
-		diffExternalEndpoint := d.self.Endpoint != m.GetAliveMsg().Membership.Endpoint
+		self := d.Self()
+		diffExternalEndpoint := self.Endpoint != m.GetAliveMsg().Membership.Endpoint
 		var diffInternalEndpoint bool
 		secretEnvelope := m.GetSecretEnvelope()
 		if secretEnvelope != nil && secretEnvelope.InternalEndpoint() != "" {
-			diffInternalEndpoint = secretEnvelope.InternalEndpoint() != d.self.InternalEndpoint
+			diffInternalEndpoint = secretEnvelope.InternalEndpoint() != self.InternalEndpoint
 		}
-		if diffInternalEndpoint || diffExternalEndpoint {
+		// Alive messages we sent before our endpoint was updated may still be circulating
+		if (diffInternalEndpoint || diffExternalEndpoint) && !d.isOwnAliveMsg(m.GetAliveMsg()) {
 			d.logger.Error("Bad configuration detected: Received AliveMessage from a peer with the same PKI-ID as myself:", m.GossipMessage)
 		}
 
@@ -559,6 +566,13 @@ func (d *gossipDiscoveryImpl) handleAliveMessage(m *proto.SignedGossipMessage) {
 	// else, ignore the message because it is too old
 }
 
+// isOwnAliveMsg returns whether the given alive message was created by this instance
+func (d *gossipDiscoveryImpl) isOwnAliveMsg(am *proto.AliveMessage) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return am.Timestamp != nil && am.Timestamp.IncNum == uint64(d.incTime) && am.Timestamp.SeqNum <= d.seqNum
+}
+
 func (d *gossipDiscoveryImpl) resurrectMember(am *proto.SignedGossipMessage, t proto.PeerTime) {
 	d.logger.Info("Entering, AliveMessage:", am, "t:", t)
 	defer d.logger.Info("Exiting")
@@ -568,7 +582,7 @@ func (d *gossipDiscoveryImpl) resurrectMember(am *proto.SignedGossipMessage, t p
 	member := am.GetAliveMsg().Membership
 	pkiID := member.PkiId
 	d.aliveLastTS[string(pkiID)] = &timestamp{
-		lastSeen: time.Now(),
+		lastSeen: util.Now(),
 		seqNum:   t.SeqNum,
 		incTime:  tsToTime(t.IncNum),
 	}
@@ -614,7 +628,7 @@ func (d *gossipDiscoveryImpl) periodicalReconnectToDead() {
 
 		wg.Wait()
 		d.logger.Debug("Sleeping", getReconnectInterval())
-		time.Sleep(getReconnectInterval())
+		util.Sleep(getReconnectInterval())
 	}
 }
 
@@ -668,7 +682,7 @@ func (d *gossipDiscoveryImpl) periodicalCheckAlive() {
 	defer d.logger.Debug("Stopped")
 
 	for !d.toDie() {
-		time.Sleep(getAliveExpirationCheckInterval())
+		util.Sleep(getAliveExpirationCheckInterval())
 		dead := d.getDeadMembers()
 		if len(dead) > 0 {
 			d.logger.Debugf("Got %v dead members: %v", len(dead), dead)
@@ -717,7 +731,7 @@ func (d *gossipDiscoveryImpl) getDeadMembers() []common.PKIidType {
 
 	dead := []common.PKIidType{}
 	for id, last := range d.aliveLastTS {
-		elapsedNonAliveTime := time.Since(last.lastSeen)
+		elapsedNonAliveTime := util.Since(last.lastSeen)
 		if elapsedNonAliveTime.Nanoseconds() > getAliveExpirationTimeout().Nanoseconds() {
 			d.logger.Warning("Haven't heard from", []byte(id), "for", elapsedNonAliveTime)
 			dead = append(dead, common.PKIidType(id))
@@ -731,7 +745,7 @@ func (d *gossipDiscoveryImpl) periodicalSendAlive() {
 
 	for !d.toDie() {
 		d.logger.Debug("Sleeping", getAliveTimeInterval())
-		time.Sleep(getAliveTimeInterval())
+		util.Sleep(getAliveTimeInterval())
 		msg, err := d.createAliveMessage(true)
 		if err != nil {
 			d.logger.Warning("Failed creating alive message:", err)
@@ -828,7 +842,7 @@ func (d *gossipDiscoveryImpl) learnExistingMembers(aliveArr []*proto.SignedGossi
 			// update existing aliveness data
 			alive := d.aliveLastTS[string(am.Membership.PkiId)]
 			alive.incTime = tsToTime(am.Timestamp.IncNum)
-			alive.lastSeen = time.Now()
+			alive.lastSeen = util.Now()
 			alive.seqNum = am.Timestamp.SeqNum
 
 			if am := d.aliveMembership.MsgByID(m.GetAliveMsg().Membership.PkiId); am == nil {
@@ -857,7 +871,7 @@ func (d *gossipDiscoveryImpl) learnNewMembers(aliveMembers []*proto.SignedGossip
 		}
 		d.aliveLastTS[string(am.GetAliveMsg().Membership.PkiId)] = &timestamp{
 			incTime:  tsToTime(am.GetAliveMsg().Timestamp.IncNum),
-			lastSeen: time.Now(),
+			lastSeen: util.Now(),
 			seqNum:   am.GetAliveMsg().Timestamp.SeqNum,
 		}
 
@@ -871,7 +885,7 @@ func (d *gossipDiscoveryImpl) learnNewMembers(aliveMembers []*proto.SignedGossip
 		}
 		d.deadLastTS[string(dm.GetAliveMsg().Membership.PkiId)] = &timestamp{
 			incTime:  tsToTime(dm.GetAliveMsg().Timestamp.IncNum),
-			lastSeen: time.Now(),
+			lastSeen: util.Now(),
 			seqNum:   dm.GetAliveMsg().Timestamp.SeqNum,
 		}
 
@@ -928,6 +942,17 @@ func (d *gossipDiscoveryImpl) GetMembership() []NetworkMember {
 
 }
 
+// LastAlive returns the time an alive message of the given member was last received,
+// or the zero time if the member is not considered alive
+func (d *gossipDiscoveryImpl) LastAlive(PKIID common.PKIidType) time.Time {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if ts, exists := d.aliveLastTS[string(PKIID)]; exists {
+		return ts.lastSeen
+	}
+	return time.Time{}
+}
+
 func tsToTime(ts uint64) time.Time {
 	return time.Unix(int64(0), int64(ts))
 }
@@ -940,12 +965,24 @@ func (d *gossipDiscoveryImpl) UpdateMetadata(md []byte) {
 
 func (d *gossipDiscoveryImpl) UpdateEndpoint(endpoint string) {
 	d.lock.Lock()
-	defer d.lock.Unlock()
-
 	d.self.Endpoint = endpoint
+	d.lock.Unlock()
+
+	if d.toDie() {
+		return
+	}
+	// Announce the new endpoint right away instead of waiting for the next alive message
+	msg, err := d.createAliveMessage(true)
+	if err != nil {
+		d.logger.Warning("Failed creating alive message:", err)
+		return
+	}
+	d.comm.Gossip(msg)
 }
 
 func (d *gossipDiscoveryImpl) Self() NetworkMember {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
 	return NetworkMember{
 		Endpoint:         d.self.Endpoint,
 		Metadata:         d.self.Metadata,
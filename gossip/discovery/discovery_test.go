@@ -472,6 +472,42 @@ func TestUpdate(t *testing.T) {
 	stopInstances(t, instances)
 }
 
+func TestConnectAborted(t *testing.T) {
+	t.Parallel()
+	inst := createDiscoveryInstance(7631, "d1", []string{})
+	defer inst.Stop()
+
+	var attempts int32
+	inst.Connect(NetworkMember{Endpoint: "localhost:7632", InternalEndpoint: "localhost:7632"}, func() (*PeerIdentification, error) {
+		atomic.AddInt32(&attempts, 1)
+		return nil, ErrConnectionAborted
+	})
+	// Connection attempts are made every reconnect interval, unless aborted
+	time.Sleep(getReconnectInterval() * 3)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestOwnStaleAliveMsg(t *testing.T) {
+	t.Parallel()
+	inst := createDiscoveryInstance(7633, "d1", []string{})
+	defer inst.Stop()
+	d := inst.discoveryImpl()
+
+	staleMsg, err := d.createAliveMessage(true)
+	assert.NoError(t, err)
+	inst.UpdateEndpoint("localhost:5533")
+	assert.Equal(t, "localhost:5533", inst.Self().Endpoint)
+	assert.True(t, d.isOwnAliveMsg(staleMsg.GetAliveMsg()))
+
+	// An alive message of another peer that has the same PKI-ID isn't ours
+	foreignMsg, _ := d.createAliveMessage(true)
+	foreignMsg.GetAliveMsg().Timestamp.IncNum++
+	assert.False(t, d.isOwnAliveMsg(foreignMsg.GetAliveMsg()))
+	foreignMsg.GetAliveMsg().Timestamp.IncNum--
+	foreignMsg.GetAliveMsg().Timestamp.SeqNum += 100
+	assert.False(t, d.isOwnAliveMsg(foreignMsg.GetAliveMsg()))
+}
+
 func TestInitiateSync(t *testing.T) {
 	t.Parallel()
 	nodeNum := 10
@@ -524,6 +560,12 @@ func TestExpiration(t *testing.T) {
 
 	assertMembership(t, instances[:len(instances)-2], nodeNum-3)
 
+	// The alive members were seen recently while the expired ones are no longer tracked as alive
+	for _, member := range instances[0].GetMembership() {
+		assert.WithinDuration(t, time.Now(), instances[0].LastAlive(member.PKIid), time.Minute)
+	}
+	assert.True(t, instances[0].LastAlive(instances[nodeNum-1].Self().PKIid).IsZero())
+
 	stopAction := &sync.WaitGroup{}
 	for i, inst := range instances {
 		if i+2 == nodeNum {
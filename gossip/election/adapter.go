@@ -44,6 +44,10 @@ func (mi *msgImpl) IsDeclaration() bool {
 	return mi.msg.GetLeadershipMsg().IsDeclaration
 }
 
+func (mi *msgImpl) LedgerHeight() uint64 {
+	return mi.msg.GetLeadershipMsg().LedgerHeight
+}
+
 type peerImpl struct {
 	member discovery.NetworkMember
 }
@@ -52,6 +56,13 @@ func (pi *peerImpl) ID() peerID {
 	return peerID(pi.member.PKIid)
 }
 
+// LedgerInfo provides the height of the ledger of the channel,
+// which is used to rank the candidates for leadership
+type LedgerInfo interface {
+	// LedgerHeight returns current local ledger height
+	LedgerHeight() (uint64, error)
+}
+
 type gossip interface {
 	// Peers returns the NetworkMembers considered alive
 	Peers() []discovery.NetworkMember
@@ -75,14 +86,18 @@ type adapterImpl struct {
 
 	channel common.ChainID
 
+	ledgerInfo LedgerInfo
+
 	logger *logging.Logger
 
 	doneCh   chan struct{}
 	stopOnce *sync.Once
 }
 
-// NewAdapter creates new leader election adapter
-func NewAdapter(gossip gossip, pkiid common.PKIidType, channel common.ChainID) LeaderElectionAdapter {
+// NewAdapter creates new leader election adapter.
+// The ledgerInfo is used to advertise the ledger height of the peer
+// in leadership messages, and may be nil
+func NewAdapter(gossip gossip, pkiid common.PKIidType, channel common.ChainID, ledgerInfo LedgerInfo) LeaderElectionAdapter {
 	return &adapterImpl{
 		gossip:    gossip,
 		selfPKIid: pkiid,
@@ -92,6 +107,8 @@ func NewAdapter(gossip gossip, pkiid common.PKIidType, channel common.ChainID) L
 
 		channel: channel,
 
+		ledgerInfo: ledgerInfo,
+
 		logger: util.GetLogger(util.LoggingElectionModule, ""),
 
 		doneCh:   make(chan struct{}),
@@ -141,6 +158,7 @@ func (ai *adapterImpl) CreateMessage(isDeclaration bool) Msg {
 			IncNum: ai.incTime,
 			SeqNum: seqNum,
 		},
+		LedgerHeight: ai.ledgerHeight(),
 	}
 
 	msg := &proto.GossipMessage{
@@ -152,6 +170,20 @@ func (ai *adapterImpl) CreateMessage(isDeclaration bool) Msg {
 	return &msgImpl{msg}
 }
 
+// ledgerHeight returns the current ledger height of the peer,
+// or 0 if it cannot be obtained
+func (ai *adapterImpl) ledgerHeight() uint64 {
+	if ai.ledgerInfo == nil {
+		return 0
+	}
+	height, err := ai.ledgerInfo.LedgerHeight()
+	if err != nil {
+		ai.logger.Warning("Failed obtaining ledger height of channel", string(ai.channel), ":", err)
+		return 0
+	}
+	return height
+}
+
 func (ai *adapterImpl) Peers() []Peer {
 	peers := ai.gossip.Peers()
 
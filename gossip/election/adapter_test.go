@@ -45,7 +45,7 @@ func TestNewAdapter(t *testing.T) {
 	peersCluster := newClusterOfPeers("0")
 	peersCluster.addPeer("peer0", mockGossip)
 
-	NewAdapter(mockGossip, selfNetworkMember.PKIid, []byte("channel0"))
+	NewAdapter(mockGossip, selfNetworkMember.PKIid, []byte("channel0"), nil)
 }
 
 func TestAdapterImpl_CreateMessage(t *testing.T) {
@@ -56,7 +56,7 @@ func TestAdapterImpl_CreateMessage(t *testing.T) {
 	}
 	mockGossip := newGossip("peer0", selfNetworkMember)
 
-	adapter := NewAdapter(mockGossip, selfNetworkMember.PKIid, []byte("channel0"))
+	adapter := NewAdapter(mockGossip, selfNetworkMember.PKIid, []byte("channel0"), &mockLedgerInfo{height: 10})
 	msg := adapter.CreateMessage(true)
 
 	if !msg.(*msgImpl).msg.IsLeadershipMsg() {
@@ -67,6 +67,10 @@ func TestAdapterImpl_CreateMessage(t *testing.T) {
 		t.Error("Newly created msg should be Declaration msg")
 	}
 
+	if msg.LedgerHeight() != 10 {
+		t.Error("Newly created msg should carry the ledger height of the peer")
+	}
+
 	msg = adapter.CreateMessage(false)
 
 	if !msg.(*msgImpl).msg.IsLeadershipMsg() {
@@ -78,6 +82,31 @@ func TestAdapterImpl_CreateMessage(t *testing.T) {
 	}
 }
 
+func TestAdapterImpl_CreateMessageLedgerHeightFailure(t *testing.T) {
+	selfNetworkMember := &discovery.NetworkMember{
+		Endpoint: "p0",
+		Metadata: []byte{},
+		PKIid:    []byte{byte(0)},
+	}
+	mockGossip := newGossip("peer0", selfNetworkMember)
+
+	adapter := NewAdapter(mockGossip, selfNetworkMember.PKIid, []byte("channel0"), &mockLedgerInfo{err: fmt.Errorf("ledger is closed")})
+	msg := adapter.CreateMessage(false)
+
+	if msg.LedgerHeight() != 0 {
+		t.Error("Newly created msg should carry a zero ledger height if the ledger height is unavailable")
+	}
+}
+
+type mockLedgerInfo struct {
+	height uint64
+	err    error
+}
+
+func (li *mockLedgerInfo) LedgerHeight() (uint64, error) {
+	return li.height, li.err
+}
+
 func TestAdapterImpl_Peers(t *testing.T) {
 	_, adapters := createCluster(0, 1, 2, 3, 4, 5)
 
@@ -291,7 +320,7 @@ func createCluster(peers ...int) (*clusterOfPeers, map[string]*adapterImpl) {
 		}
 
 		mockGossip := newGossip(peerEndpoint, peerMember)
-		adapter := NewAdapter(mockGossip, peerMember.PKIid, []byte("channel0"))
+		adapter := NewAdapter(mockGossip, peerMember.PKIid, []byte("channel0"), nil)
 		adapters[peerEndpoint] = adapter.(*adapterImpl)
 		cluster.addPeer(peerEndpoint, mockGossip)
 	}
@@ -29,14 +29,17 @@ import (
 
 // Gossip leader election module
 // Algorithm properties:
-// - Peers break symmetry by comparing IDs
+// - Peers break symmetry by comparing their ledger heights,
+//   and then by comparing IDs. A peer with a higher ledger height,
+//   or with the same ledger height and a lower ID, is a better candidate
 // - Each peer is either a leader or a follower,
-//   and the aim is to have exactly 1 leader if the membership view
-//   is the same for all peers
+//   and the aim is to have exactly N leaders (N=1 by default) if the
+//   membership view is the same for all peers
 // - If the network is partitioned into 2 or more sets, the number of leaders
-//   is the number of network partitions, but when the partition heals,
-//   only 1 leader should be left eventually
-// - Peers communicate by gossiping leadership proposal or declaration messages
+//   is up to N times the number of network partitions, but when the partition heals,
+//   only N leaders should be left eventually
+// - Peers communicate by gossiping leadership proposal or declaration messages,
+//   that carry the ledger height of the sender
 
 // The Algorithm, in pseudo code:
 //
@@ -46,7 +49,8 @@ import (
 //
 // Invariant:
 //	Peer listens for messages from remote peers
-//	and whenever it receives a leadership declaration,
+//	and whenever it has received leadership declarations
+//	from N different peers within a time threshold,
 //	leaderKnown is set to true
 //
 // Startup():
@@ -60,22 +64,22 @@ import (
 // 			LeaderElection()
 //		If you are the leader:
 //			Broadcast leadership declaration
-//			If a leadership declaration was received from
-// 			a peer with a lower ID,
+//			If leadership declarations were received from
+// 			N better candidates,
 //			become a follower
 //		Else, you're a follower:
-//			If haven't received a leadership declaration within
+//			If haven't received leadership declarations from N peers within
 // 			a time threshold:
 //				set leaderKnown to false
 //
 // LeaderElection():
 // 	Gossip leadership proposal message
 //	Collect messages from other peers sent within a time period
-//	If received a leadership declaration:
+//	If received leadership declarations from N peers:
 //		return
 //	Iterate over all proposal messages collected.
-// 	If a proposal message from a peer with an ID lower
-// 	than yourself was received, return.
+// 	If proposal messages from as many better candidates as the number
+// 	of leaders missing were received, return.
 //	Else, declare yourself a leader
 
 // LeaderElectionAdapter is used by the leader election module
@@ -87,7 +91,8 @@ type LeaderElectionAdapter interface {
 	// Accept returns a channel that emits messages
 	Accept() <-chan Msg
 
-	// CreateProposalMessage
+	// CreateMessage creates a leadership proposal or declaration message
+	// that carries the current ledger height of the peer
 	CreateMessage(isDeclaration bool) Msg
 
 	// Peers returns a list of peers considered alive
@@ -101,6 +106,10 @@ type LeaderElectionService interface {
 	// IsLeader returns whether this peer is a leader or not
 	IsLeader() bool
 
+	// Leader returns the ID of the best candidate among the peers
+	// currently known to be leaders, or nil if no leader is known
+	Leader() []byte
+
 	// Stop stops the LeaderElectionService
 	Stop()
 
@@ -125,6 +134,30 @@ type Msg interface {
 	IsProposal() bool
 	// IsDeclaration returns whether this message is a leadership declaration
 	IsDeclaration() bool
+	// LedgerHeight returns the ledger height of the peer sent the message
+	LedgerHeight() uint64
+}
+
+// candidate is a peer competing for, or holding the leadership
+type candidate struct {
+	id     peerID
+	height uint64
+}
+
+// betterThan returns whether the candidate should be preferred over the given one
+// as a leader, i.e. it has a higher ledger height, or the same ledger height
+// and a lower ID
+func (c candidate) betterThan(o candidate) bool {
+	if c.height != o.height {
+		return c.height > o.height
+	}
+	return bytes.Compare(c.id, o.id) < 0
+}
+
+// declaration is a leadership declaration received from a remote peer
+type declaration struct {
+	height   uint64
+	lastSeen time.Time
 }
 
 func noopCallback(_ bool) {
@@ -137,7 +170,9 @@ func NewLeaderElectionService(adapter LeaderElectionAdapter, id string, callback
 	}
 	le := &leaderElectionSvcImpl{
 		id:            peerID(id),
-		proposals:     util.NewSet(),
+		proposals:     make(map[string]uint64),
+		declarations:  make(map[string]*declaration),
+		leaderCount:   getLeaderCount(),
 		adapter:       adapter,
 		stopChan:      make(chan struct{}, 1),
 		interruptChan: make(chan struct{}, 1),
@@ -155,8 +190,11 @@ func NewLeaderElectionService(adapter LeaderElectionAdapter, id string, callback
 
 // leaderElectionSvcImpl is an implementation of a LeaderElectionService
 type leaderElectionSvcImpl struct {
-	id        peerID
-	proposals *util.Set
+	id           peerID
+	height       uint64
+	proposals    map[string]uint64
+	declarations map[string]*declaration
+	leaderCount  int
 	sync.Mutex
 	stopChan      chan struct{}
 	interruptChan chan struct{}
@@ -169,7 +207,7 @@ type leaderElectionSvcImpl struct {
 	adapter       LeaderElectionAdapter
 	logger        *logging.Logger
 	callback      leadershipCallback
-	yieldTimer    *time.Timer
+	yieldTimer    util.Timer
 }
 
 func (le *leaderElectionSvcImpl) start() {
@@ -209,13 +247,31 @@ func (le *leaderElectionSvcImpl) handleMessage(msg Msg) {
 	defer le.Unlock()
 
 	if msg.IsProposal() {
-		le.proposals.Add(string(msg.SenderID()))
+		le.proposals[string(msg.SenderID())] = msg.LedgerHeight()
 	} else if msg.IsDeclaration() {
+		le.declarations[string(msg.SenderID())] = &declaration{
+			height:   msg.LedgerHeight(),
+			lastSeen: util.Now(),
+		}
+		leaders := le.aliveLeaders()
+		if len(leaders) < le.leaderCount {
+			return
+		}
 		atomic.StoreInt32(&le.leaderExists, int32(1))
 		if le.sleeping && len(le.interruptChan) == 0 {
 			le.interruptChan <- struct{}{}
 		}
-		if bytes.Compare(msg.SenderID(), le.id) < 0 && le.IsLeader() {
+		if !le.IsLeader() {
+			return
+		}
+		self := candidate{id: le.id, height: le.height}
+		betterLeaders := 0
+		for _, l := range leaders {
+			if l.betterThan(self) {
+				betterLeaders++
+			}
+		}
+		if betterLeaders >= le.leaderCount {
 			le.stopBeingLeader()
 		}
 	} else {
@@ -237,7 +293,7 @@ func (le *leaderElectionSvcImpl) waitForInterrupt(timeout time.Duration) {
 	case <-le.interruptChan:
 	case <-le.stopChan:
 		le.stopChan <- struct{}{}
-	case <-time.After(timeout):
+	case <-util.After(timeout):
 	}
 
 	le.Lock()
@@ -252,7 +308,7 @@ func (le *leaderElectionSvcImpl) waitForInterrupt(timeout time.Duration) {
 func (le *leaderElectionSvcImpl) run() {
 	defer le.stopWG.Done()
 	for !le.shouldStop() {
-		if !le.isLeaderExists() {
+		if !le.IsLeader() && !le.isLeaderExists() {
 			le.leaderElection()
 		}
 		// If we are yielding and some leader has been elected,
@@ -283,10 +339,10 @@ func (le *leaderElectionSvcImpl) leaderElection() {
 	le.propose()
 	// Collect other proposals
 	le.waitForInterrupt(getLeaderElectionDuration())
-	// If someone declared itself as a leader, give up
+	// If enough peers declared themselves as leaders, give up
 	// on trying to become a leader too
 	if le.isLeaderExists() {
-		le.logger.Debug(le.id, ": Some peer is already a leader")
+		le.logger.Debug(le.id, ": Enough peers are already leaders")
 		return
 	}
 
@@ -294,18 +350,31 @@ func (le *leaderElectionSvcImpl) leaderElection() {
 		le.logger.Debug(le.id, ": Aborting leader election because yielding")
 		return
 	}
-	// Leader doesn't exist, let's see if there is a better candidate than us
-	// for being a leader
-	for _, o := range le.proposals.ToArray() {
-		id := o.(string)
-		if bytes.Compare(peerID(id), le.id) < 0 {
-			return
+	// Not enough leaders exist, let's see if there are enough better candidates
+	// than us to fill the missing leaders
+	le.Lock()
+	self := candidate{id: le.id, height: le.height}
+	leaders := le.aliveLeaders()
+	isLeader := make(map[string]struct{})
+	for _, l := range leaders {
+		isLeader[string(l.id)] = struct{}{}
+	}
+	betterCandidates := 0
+	for id, height := range le.proposals {
+		if _, exists := isLeader[id]; exists {
+			continue
+		}
+		if (candidate{id: peerID(id), height: height}).betterThan(self) {
+			betterCandidates++
 		}
 	}
-	// If we got here, there is no one that proposed being a leader
-	// that's a better candidate than us.
+	le.Unlock()
+	if betterCandidates >= le.leaderCount-len(leaders) {
+		return
+	}
+	// If we got here, there are fewer better candidates that proposed
+	// being a leader than the number of missing leaders.
 	le.beLeader()
-	atomic.StoreInt32(&le.leaderExists, int32(1))
 }
 
 // propose sends a leadership proposal message to remote peers
@@ -313,6 +382,7 @@ func (le *leaderElectionSvcImpl) propose() {
 	le.logger.Debug(le.id, ": Entering")
 	le.logger.Debug(le.id, ": Exiting")
 	leadershipProposal := le.adapter.CreateMessage(false)
+	le.setHeight(leadershipProposal.LedgerHeight())
 	le.adapter.Gossip(leadershipProposal)
 }
 
@@ -320,10 +390,12 @@ func (le *leaderElectionSvcImpl) follower() {
 	le.logger.Debug(le.id, ": Entering")
 	defer le.logger.Debug(le.id, ": Exiting")
 
-	le.proposals.Clear()
+	le.Lock()
+	le.proposals = make(map[string]uint64)
+	le.Unlock()
 	atomic.StoreInt32(&le.leaderExists, int32(0))
 	select {
-	case <-time.After(getLeaderAliveThreshold()):
+	case <-util.After(getLeaderAliveThreshold()):
 	case <-le.stopChan:
 		le.stopChan <- struct{}{}
 	}
@@ -331,21 +403,44 @@ func (le *leaderElectionSvcImpl) follower() {
 
 func (le *leaderElectionSvcImpl) leader() {
 	leaderDeclaration := le.adapter.CreateMessage(true)
+	le.setHeight(leaderDeclaration.LedgerHeight())
 	le.adapter.Gossip(leaderDeclaration)
 	le.waitForInterrupt(getLeadershipDeclarationInterval())
 }
 
+// setHeight records the ledger height this peer advertised last
+func (le *leaderElectionSvcImpl) setHeight(height uint64) {
+	le.Lock()
+	defer le.Unlock()
+	le.height = height
+}
+
+// aliveLeaders returns the remote peers that declared themselves as leaders
+// within the leader alive threshold, and forgets the rest.
+// Should be called while holding the lock
+func (le *leaderElectionSvcImpl) aliveLeaders() []candidate {
+	var leaders []candidate
+	for id, d := range le.declarations {
+		if util.Since(d.lastSeen) > getLeaderAliveThreshold() {
+			delete(le.declarations, id)
+			continue
+		}
+		leaders = append(leaders, candidate{id: peerID(id), height: d.height})
+	}
+	return leaders
+}
+
 // waitForMembershipStabilization waits for membership view to stabilize
 // or until a time limit expires, or until a peer declares itself as a leader
 func (le *leaderElectionSvcImpl) waitForMembershipStabilization(timeLimit time.Duration) {
 	le.logger.Debug(le.id, ": Entering")
 	defer le.logger.Debug(le.id, ": Exiting, peers found", len(le.adapter.Peers()))
-	endTime := time.Now().Add(timeLimit)
+	endTime := util.Now().Add(timeLimit)
 	viewSize := len(le.adapter.Peers())
 	for !le.shouldStop() {
-		time.Sleep(getMembershipSampleInterval())
+		util.Sleep(getMembershipSampleInterval())
 		newSize := len(le.adapter.Peers())
-		if newSize == viewSize || time.Now().After(endTime) || le.isLeaderExists() {
+		if newSize == viewSize || util.Now().After(endTime) || le.isLeaderExists() {
 			return
 		}
 		viewSize = newSize
@@ -381,6 +476,27 @@ func (le *leaderElectionSvcImpl) IsLeader() bool {
 	return isLeader
 }
 
+// Leader returns the ID of the best candidate among the peers
+// currently known to be leaders, or nil if no leader is known
+func (le *leaderElectionSvcImpl) Leader() []byte {
+	le.Lock()
+	defer le.Unlock()
+	var best *candidate
+	if le.IsLeader() {
+		best = &candidate{id: le.id, height: le.height}
+	}
+	for _, l := range le.aliveLeaders() {
+		if best == nil || l.betterThan(*best) {
+			l := l
+			best = &l
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.id
+}
+
 func (le *leaderElectionSvcImpl) beLeader() {
 	le.logger.Debug(le.id, ": Becoming a leader")
 	atomic.StoreInt32(&le.isLeader, int32(1))
@@ -424,7 +540,7 @@ func (le *leaderElectionSvcImpl) Yield() {
 	// Clear the leader exists flag since it could be that we are the leader
 	atomic.StoreInt32(&le.leaderExists, int32(0))
 	// Clear the yield flag in any case afterwards
-	le.yieldTimer = time.AfterFunc(getLeaderAliveThreshold()*6, func() {
+	le.yieldTimer = util.AfterFunc(getLeaderAliveThreshold()*6, func() {
 		atomic.StoreInt32(&le.yield, int32(0))
 	})
 }
@@ -455,6 +571,12 @@ func SetLeaderAliveThreshold(t time.Duration) {
 	viper.Set("peer.gossip.election.leaderAliveThreshold", t)
 }
 
+// SetLeaderCount configures the number of peers that are to be leaders
+// at the same time
+func SetLeaderCount(count int) {
+	viper.Set("peer.gossip.election.leaderCount", count)
+}
+
 // SetLeaderElectionDuration configures expected leadership election duration,
 // interval to wait until leader election will be completed
 func SetLeaderElectionDuration(t time.Duration) {
@@ -473,6 +595,19 @@ func getLeaderAliveThreshold() time.Duration {
 	return util.GetDurationOrDefault("peer.gossip.election.leaderAliveThreshold", time.Second*10)
 }
 
+func getLeaderCount() int {
+	if count := viper.GetInt("peer.gossip.election.leaderCount"); count > 0 {
+		return count
+	}
+	return 1
+}
+
+// GetFailoverThreshold returns the time a leader spends reconnecting
+// to the ordering service before it relinquishes its leadership
+func GetFailoverThreshold() time.Duration {
+	return util.GetDurationOrDefault("peer.gossip.election.failoverThreshold", time.Second*10)
+}
+
 func getLeadershipDeclarationInterval() time.Duration {
 	return time.Duration(getLeaderAliveThreshold() / 2)
 }
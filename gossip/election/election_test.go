@@ -47,6 +47,7 @@ func init() {
 type msg struct {
 	sender   string
 	proposal bool
+	height   uint64
 }
 
 func (m *msg) SenderID() peerID {
@@ -61,10 +62,15 @@ func (m *msg) IsDeclaration() bool {
 	return !m.proposal
 }
 
+func (m *msg) LedgerHeight() uint64 {
+	return m.height
+}
+
 type peer struct {
 	mockedMethods map[string]struct{}
 	mock.Mock
 	id                 string
+	height             uint64
 	peers              map[string]*peer
 	sharedLock         *sync.RWMutex
 	msgChan            chan Msg
@@ -114,7 +120,7 @@ func (p *peer) Accept() <-chan Msg {
 }
 
 func (p *peer) CreateMessage(isDeclaration bool) Msg {
-	return &msg{proposal: !isDeclaration, sender: p.id}
+	return &msg{proposal: !isDeclaration, sender: p.id, height: atomic.LoadUint64(&p.height)}
 }
 
 func (p *peer) Peers() []Peer {
@@ -211,6 +217,13 @@ func TestInitPeersAtSameTime(t *testing.T) {
 	assert.True(t, isP0leader, "p0 isn't a leader. Leaders are: %v", leaders)
 	assert.Len(t, leaders, 1, "More than 1 leader elected")
 	waitForBoolFunc(t, peers[len(peers)-1].isLeaderFromCallback, true, "Leadership callback result is wrong for ", peers[len(peers)-1].id)
+
+	for _, p := range peers {
+		leaderIsP0 := func() bool {
+			return string(p.Leader()) == "p0"
+		}
+		waitForBoolFunc(t, leaderIsP0, true, "Leader is wrong for ", p.id)
+	}
 }
 
 func TestInitPeersStartAtIntervals(t *testing.T) {
@@ -427,6 +440,51 @@ func TestPartition(t *testing.T) {
 
 }
 
+func TestLedgerHeightRanking(t *testing.T) {
+	t.Parallel()
+	// Scenario: Peers are spawned at the same time, and the peer with
+	// the highest ID has the highest ledger height
+	// expected outcome: the peer with the highest ledger height is the leader
+	peers := createPeers(0, 3, 2, 1, 0)
+	atomic.StoreUint64(&peers[0].height, 10)
+	time.Sleep(getStartupGracePeriod() + getLeaderElectionDuration())
+	leaders := waitForLeaderElection(t, peers)
+	assert.Len(t, leaders, 1, "Only 1 leader should have been elected")
+	assert.Equal(t, "p3", leaders[0])
+
+	for _, p := range peers {
+		leaderIsP3 := func() bool {
+			return string(p.Leader()) == "p3"
+		}
+		waitForBoolFunc(t, leaderIsP3, true, "Leader is wrong for ", p.id)
+	}
+}
+
+func TestMultipleLeaders(t *testing.T) {
+	// Scenario: 2 leaders are configured, and peers are spawned at the same time.
+	// After a while, one of the leaders stops
+	// expected outcome: the 2 peers with the lowest IDs are the leaders,
+	// and after one of them stops, the next peer takes over
+	SetLeaderCount(2)
+	peers := createPeers(0, 4, 3, 2, 1, 0)
+	SetLeaderCount(1)
+	time.Sleep(getStartupGracePeriod() + getLeaderElectionDuration())
+	leaders := waitForMultipleLeadersElection(t, peers, 2)
+	assert.Len(t, leaders, 2, "2 leaders should have been elected")
+	assert.Contains(t, leaders, "p0")
+	assert.Contains(t, leaders, "p1")
+
+	peers[len(peers)-1].Stop()
+	time.Sleep(getLeadershipDeclarationInterval() + getLeaderAliveThreshold()*3)
+	leaders = waitForMultipleLeadersElection(t, peers[:len(peers)-1], 2)
+	assert.Len(t, leaders, 2, "2 leaders should have been elected")
+	assert.Contains(t, leaders, "p1")
+	assert.Contains(t, leaders, "p2")
+	for _, p := range peers[:len(peers)-1] {
+		p.Stop()
+	}
+}
+
 func TestConfigFromFile(t *testing.T) {
 	preStartupGracePeriod := getStartupGracePeriod()
 	preMembershipSampleInterval := getMembershipSampleInterval()
@@ -448,6 +506,8 @@ func TestConfigFromFile(t *testing.T) {
 	assert.Equal(t, time.Second*10, getLeaderAliveThreshold())
 	assert.Equal(t, time.Second*5, getLeaderElectionDuration())
 	assert.Equal(t, getLeaderAliveThreshold()/2, getLeadershipDeclarationInterval())
+	assert.Equal(t, 1, getLeaderCount())
+	assert.Equal(t, time.Second*10, GetFailoverThreshold())
 
 	//Verify reading the values from config file
 	viper.Reset()
@@ -463,6 +523,8 @@ func TestConfigFromFile(t *testing.T) {
 	assert.Equal(t, time.Second*10, getLeaderAliveThreshold())
 	assert.Equal(t, time.Second*5, getLeaderElectionDuration())
 	assert.Equal(t, getLeaderAliveThreshold()/2, getLeadershipDeclarationInterval())
+	assert.Equal(t, 1, getLeaderCount())
+	assert.Equal(t, time.Second*10, GetFailoverThreshold())
 }
 
 func waitForBoolFunc(t *testing.T, f func() bool, expectedValue bool, msgAndArgs ...interface{}) {
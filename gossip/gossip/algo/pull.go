@@ -130,7 +130,7 @@ func NewPullEngineWithFilter(participant PullAdapter, sleepTime time.Duration, d
 
 	go func() {
 		for !engine.toDie() {
-			time.Sleep(sleepTime)
+			util.Sleep(sleepTime)
 			if engine.toDie() {
 				return
 			}
@@ -195,7 +195,7 @@ func (engine *PullEngine) initiatePull() {
 	}
 
 	digestWaitTime := util.GetDurationOrDefault("peer.gossip.digestWaitTime", defDigestWaitTime)
-	time.AfterFunc(digestWaitTime, func() {
+	util.AfterFunc(digestWaitTime, func() {
 		engine.processIncomingDigests()
 	})
 }
@@ -224,7 +224,7 @@ func (engine *PullEngine) processIncomingDigests() {
 	}
 
 	responseWaitTime := util.GetDurationOrDefault("peer.gossip.responseWaitTime", defResponseWaitTime)
-	time.AfterFunc(responseWaitTime, engine.endPull)
+	util.AfterFunc(responseWaitTime, engine.endPull)
 
 }
 
@@ -281,7 +281,7 @@ func (engine *PullEngine) OnHello(nonce uint64, context interface{}) {
 	engine.incomingNONCES.Add(nonce)
 
 	requestWaitTime := util.GetDurationOrDefault("peer.gossip.requestWaitTime", defRequestWaitTime)
-	time.AfterFunc(requestWaitTime, func() {
+	util.AfterFunc(requestWaitTime, func() {
 		engine.incomingNONCES.Remove(nonce)
 	})
 
@@ -21,6 +21,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/hyperledger/fabric/gossip/util"
 )
 
 type emitBatchCallback func([]interface{})
@@ -68,7 +70,7 @@ func newBatchingEmitter(iterations, burstSize int, latency time.Duration, cb emi
 
 func (p *batchingEmitterImpl) periodicEmit() {
 	for !p.toDie() {
-		time.Sleep(p.delay)
+		util.Sleep(p.delay)
 		p.lock.Lock()
 		p.emit()
 		p.lock.Unlock()
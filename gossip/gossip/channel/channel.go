@@ -56,6 +56,9 @@ type GossipChannel interface {
 	// GetPeers returns a list of peers with metadata as published by them
 	GetPeers() []discovery.NetworkMember
 
+	// Status returns a snapshot of the state of the channel
+	Status() Status
+
 	// IsMemberInChan checks whether the given member is eligible to be in the channel
 	IsMemberInChan(member discovery.NetworkMember) bool
 
@@ -84,6 +87,17 @@ type GossipChannel interface {
 	Stop()
 }
 
+// Status is a snapshot of the state of a GossipChannel
+type Status struct {
+	// Peers are the peers of the channel, with metadata as published by them
+	Peers []discovery.NetworkMember
+	// Number of messages held by the stores of the channel
+	BlockMsgStoreSize     int
+	BlockPullSize         int
+	StateInfoMsgStoreSize int
+	LeaderMsgStoreSize    int
+}
+
 // Adapter enables the gossipChannel
 // to communicate with gossipServiceImpl.
 type Adapter interface {
@@ -134,8 +148,8 @@ type gossipChannel struct {
 	chainID                   common.ChainID
 	blocksPuller              pull.Mediator
 	logger                    *logging.Logger
-	stateInfoPublishScheduler *time.Ticker
-	stateInfoRequestScheduler *time.Ticker
+	stateInfoPublishScheduler util.Ticker
+	stateInfoRequestScheduler util.Ticker
 	memFilter                 *membershipFilter
 }
 
@@ -166,8 +180,8 @@ func NewGossipChannel(pkiID common.PKIidType, org api.OrgIdentityType, mcs api.M
 		logger:                    util.GetLogger(util.LoggingChannelModule, adapter.GetConf().ID),
 		stopChan:                  make(chan struct{}, 1),
 		shouldGossipStateInfo:     int32(0),
-		stateInfoPublishScheduler: time.NewTicker(adapter.GetConf().PublishStateInfoInterval),
-		stateInfoRequestScheduler: time.NewTicker(adapter.GetConf().RequestStateInfoInterval),
+		stateInfoPublishScheduler: util.NewTicker(adapter.GetConf().PublishStateInfoInterval),
+		stateInfoRequestScheduler: util.NewTicker(adapter.GetConf().RequestStateInfoInterval),
 		orgs:    []api.OrgIdentityType{},
 		chainID: chainID,
 	}
@@ -201,9 +215,9 @@ func NewGossipChannel(pkiID common.PKIidType, org api.OrgIdentityType, mcs api.M
 	gc.ConfigureChannel(joinMsg)
 
 	// Periodically publish state info
-	go gc.periodicalInvocation(gc.publishStateInfo, gc.stateInfoPublishScheduler.C)
+	go gc.periodicalInvocation(gc.publishStateInfo, gc.stateInfoPublishScheduler.C())
 	// Periodically request state info
-	go gc.periodicalInvocation(gc.requestStateInfo, gc.stateInfoRequestScheduler.C)
+	go gc.periodicalInvocation(gc.requestStateInfo, gc.stateInfoRequestScheduler.C())
 	return gc
 }
 
@@ -248,6 +262,17 @@ func (gc *gossipChannel) GetPeers() []discovery.NetworkMember {
 	return members
 }
 
+// Status returns a snapshot of the state of the channel
+func (gc *gossipChannel) Status() Status {
+	return Status{
+		Peers:                 gc.GetPeers(),
+		BlockMsgStoreSize:     gc.blockMsgStore.Size(),
+		BlockPullSize:         gc.blocksPuller.Size(),
+		StateInfoMsgStoreSize: gc.stateInfoMsgStore.MessageStore.Size(),
+		LeaderMsgStoreSize:    gc.leaderMsgStore.Size(),
+	}
+}
+
 func (gc *gossipChannel) requestStateInfo() {
 	req, err := gc.createStateInfoRequest()
 	if err != nil {
@@ -691,7 +716,7 @@ func newStateInfoCache(sweepInterval time.Duration, hasExpired func(interface{})
 			select {
 			case <-s.stopChan:
 				return
-			case <-time.After(sweepInterval):
+			case <-util.After(sweepInterval):
 				s.Purge(hasExpired)
 			}
 		}
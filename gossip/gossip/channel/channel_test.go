@@ -828,6 +828,12 @@ func TestChannelAddToMessageStore(t *testing.T) {
 
 	gc.HandleMessage(&receivedMsg{msg: createStateInfoMsg(10, pkiIDInOrg1, channelA), PKIID: pkiIDInOrg1})
 	assert.True(t, gc.EligibleForChannel(discovery.NetworkMember{PKIid: pkiIDInOrg1}))
+
+	status := gc.Status()
+	assert.Equal(t, 2, status.BlockMsgStoreSize)
+	assert.Equal(t, 2, status.BlockPullSize)
+	assert.Equal(t, 1, status.StateInfoMsgStoreSize)
+	assert.Equal(t, 0, status.LeaderMsgStoreSize)
 }
 
 func TestChannelBlockExpiration(t *testing.T) {
@@ -1435,6 +1441,7 @@ func TestChannelGetPeers(t *testing.T) {
 	gc.HandleMessage(&receivedMsg{PKIID: pkiIDInOrg1, msg: createStateInfoMsg(1, pkiIDinOrg2, channelA)})
 	assert.Len(t, gc.GetPeers(), 1)
 	assert.Equal(t, pkiIDInOrg1, gc.GetPeers()[0].PKIid)
+	assert.Equal(t, gc.GetPeers(), gc.Status().Peers)
 
 	gc.HandleMessage(&receivedMsg{msg: createStateInfoMsg(10, pkiIDInOrg1ButNotEligible, channelA), PKIID: pkiIDInOrg1ButNotEligible})
 	cs.On("VerifyByChannel", mock.Anything).Return(errors.New("Not eligible"))
@@ -103,6 +103,20 @@ func (cs *channelState) getGossipChannelByChainID(chainID common.ChainID) channe
 	return cs.channels[string(chainID)]
 }
 
+// channelsByID returns the channels, by channel name
+func (cs *channelState) channelsByID() map[string]channel.GossipChannel {
+	channels := make(map[string]channel.GossipChannel)
+	if cs.isStopping() {
+		return channels
+	}
+	cs.RLock()
+	defer cs.RUnlock()
+	for chainID, gc := range cs.channels {
+		channels[chainID] = gc
+	}
+	return channels
+}
+
 func (cs *channelState) joinChannel(joinMsg api.JoinChannelMessage, chainID common.ChainID) {
 	if cs.isStopping() {
 		return
@@ -119,6 +133,18 @@ func (cs *channelState) joinChannel(joinMsg api.JoinChannelMessage, chainID comm
 	}
 }
 
+func (cs *channelState) leaveChannel(chainID common.ChainID) {
+	if cs.isStopping() {
+		return
+	}
+	cs.Lock()
+	defer cs.Unlock()
+	if gc, exists := cs.channels[string(chainID)]; exists {
+		gc.Stop()
+		delete(cs.channels, string(chainID))
+	}
+}
+
 type gossipAdapterImpl struct {
 	*gossipServiceImpl
 	discovery.Discovery
@@ -61,14 +61,77 @@ type Gossip interface {
 	// JoinChan makes the Gossip instance join a channel
 	JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID)
 
+	// LeaveChan makes the Gossip instance leave a channel
+	LeaveChan(chainID common.ChainID)
+
 	// SuspectPeers makes the gossip instance validate identities of suspected peers, and close
 	// any connections to peers with identities that are found invalid
 	SuspectPeers(s api.PeerSuspector)
 
+	// Status returns a snapshot of the membership and channel state of the gossip instance
+	Status() *Status
+
+	// UpdateBootstrapPeers adds and removes bootstrap peers, and connects to the added ones.
+	// Removed bootstrap peers that were already connected to remain members of the view.
+	UpdateBootstrapPeers(add []string, remove []string) error
+
+	// BootstrapPeers returns the bootstrap peers of the gossip instance
+	BootstrapPeers() []string
+
+	// UpdateExternalEndpoint changes the endpoint the peer publishes to peers of other organizations,
+	// announces it to the other peers and connects to the anchor peers of the channels it is in
+	UpdateExternalEndpoint(endpoint string) error
+
 	// Stop stops the gossip component
 	Stop()
 }
 
+// Status is a snapshot of the membership and channel state of a gossip instance
+type Status struct {
+	// Self is this instance
+	Self MemberStatus
+	// Peers are the NetworkMembers considered alive
+	Peers []MemberStatus
+	// Channels are the channels the instance joined, by channel name
+	Channels map[string]*ChannelStatus
+	// Identities are the identities of peers held by the instance
+	Identities []IdentityStatus
+	// Number of messages held by the stores that are not tied to a channel
+	CertStoreSize         int
+	StateInfoMsgStoreSize int
+}
+
+// MemberStatus is a NetworkMember along with its organization and the
+// time it was last seen alive
+type MemberStatus struct {
+	discovery.NetworkMember
+	Org       api.OrgIdentityType
+	LastAlive time.Time
+}
+
+// ChannelStatus is the state of a channel as seen by a gossip instance
+type ChannelStatus struct {
+	// Peers are the NetworkMembers considered alive and subscribed to the channel,
+	// with the metadata they published in the channel
+	Peers []MemberStatus
+	// Leader is the PKI-ID of the leader of the organization in the channel.
+	// The gossip instance doesn't run the leader election, hence Leader is left
+	// for the caller to fill
+	Leader common.PKIidType
+	// Number of messages held by the stores of the channel
+	BlockMsgStoreSize     int
+	BlockPullSize         int
+	StateInfoMsgStoreSize int
+	LeaderMsgStoreSize    int
+}
+
+// IdentityStatus is an identity of a peer held by a gossip instance
+type IdentityStatus struct {
+	PKIid    common.PKIidType
+	Org      api.OrgIdentityType
+	Identity api.PeerIdentityType
+}
+
 // Config is the configuration of the gossip component
 type Config struct {
 	BindPort            int      // Port we bind to, used only for tests
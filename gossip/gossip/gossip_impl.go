@@ -21,7 +21,10 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
+	"sort"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -76,6 +79,9 @@ type gossipServiceImpl struct {
 	disSecAdap        *discoverySecurityAdapter
 	mcs               api.MessageCryptoService
 	stateInfoMsgStore msgstore.MessageStore
+	configLock        sync.RWMutex
+	bootstrapPeers    map[string]struct{}
+	joinMsgs          map[string]api.JoinChannelMessage // latest JoinChannel message of each channel
 }
 
 // NewGossipService creates a gossip instance attached to a gRPC server
@@ -97,6 +103,14 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 		lgr.Error("Failed instntiating communication layer:", err)
 		return nil
 	}
+	return NewGossipServiceWithComm(conf, c, secAdvisor, mcs, idMapper, selfIdentity)
+}
+
+// NewGossipServiceWithComm creates a gossip instance that communicates with other peers
+// through the given communication module, e.g. an in-memory network in simulations
+func NewGossipServiceWithComm(conf *Config, c comm.Comm, secAdvisor api.SecurityAdvisor,
+	mcs api.MessageCryptoService, idMapper identity.Mapper, selfIdentity api.PeerIdentityType) Gossip {
+	lgr := util.GetLogger(util.LoggingGossipModule, conf.ID)
 
 	g := &gossipServiceImpl{
 		selfOrg:               secAdvisor.OrgByPeerIdentity(selfIdentity),
@@ -113,7 +127,12 @@ func NewGossipService(conf *Config, s *grpc.Server, secAdvisor api.SecurityAdvis
 		toDieChan:             make(chan struct{}, 1),
 		stopFlag:              int32(0),
 		stopSignal:            &sync.WaitGroup{},
-		includeIdentityPeriod: time.Now().Add(conf.PublishCertPeriod),
+		includeIdentityPeriod: util.Now().Add(conf.PublishCertPeriod),
+		bootstrapPeers:        make(map[string]struct{}),
+		joinMsgs:              make(map[string]api.JoinChannelMessage),
+	}
+	for _, endpoint := range conf.BootstrapPeers {
+		g.bootstrapPeers[endpoint] = struct{}{}
 	}
 	g.stateInfoMsgStore = g.newStateInfoMsgStore()
 
@@ -158,7 +177,10 @@ func (g *gossipServiceImpl) selfNetworkMember() discovery.NetworkMember {
 		InternalEndpoint: g.conf.InternalEndpoint,
 	}
 	if g.disc != nil {
-		self.Metadata = g.disc.Self().Metadata
+		// The external endpoint might have been updated since the instance was created
+		discSelf := g.disc.Self()
+		self.Metadata = discSelf.Metadata
+		self.Endpoint = discSelf.Endpoint
 	}
 	return self
 }
@@ -195,11 +217,141 @@ func (g *gossipServiceImpl) JoinChan(joinMsg api.JoinChannelMessage, chainID com
 	// joinMsg is supposed to have been already verified
 	g.chanState.joinChannel(joinMsg, chainID)
 
+	g.configLock.Lock()
+	g.joinMsgs[string(chainID)] = joinMsg
+	g.configLock.Unlock()
+
 	for _, org := range joinMsg.Members() {
 		g.learnAnchorPeers(org, joinMsg.AnchorPeersOf(org))
 	}
 }
 
+// LeaveChan makes the Gossip instance leave a channel
+func (g *gossipServiceImpl) LeaveChan(chainID common.ChainID) {
+	g.chanState.leaveChannel(chainID)
+
+	g.configLock.Lock()
+	delete(g.joinMsgs, string(chainID))
+	g.configLock.Unlock()
+}
+
+// UpdateBootstrapPeers adds and removes bootstrap peers, and connects to the added ones.
+// Removed bootstrap peers that were already connected to remain members of the view.
+func (g *gossipServiceImpl) UpdateBootstrapPeers(add []string, remove []string) error {
+	for _, endpoint := range append(add, remove...) {
+		if err := validateEndpoint(endpoint); err != nil {
+			return err
+		}
+	}
+
+	var added []string
+	g.configLock.Lock()
+	for _, endpoint := range remove {
+		delete(g.bootstrapPeers, endpoint)
+	}
+	for _, endpoint := range add {
+		if _, exists := g.bootstrapPeers[endpoint]; exists {
+			continue
+		}
+		g.bootstrapPeers[endpoint] = struct{}{}
+		added = append(added, endpoint)
+	}
+	g.configLock.Unlock()
+
+	if len(add) > 0 || len(remove) > 0 {
+		g.logger.Info("Added bootstrap peers", added, "and removed bootstrap peers", remove)
+	}
+	for _, endpoint := range added {
+		g.connect2BootstrapPeer(endpoint)
+	}
+	return nil
+}
+
+// BootstrapPeers returns the bootstrap peers of the gossip instance
+func (g *gossipServiceImpl) BootstrapPeers() []string {
+	g.configLock.RLock()
+	defer g.configLock.RUnlock()
+	endpoints := make([]string, 0, len(g.bootstrapPeers))
+	for endpoint := range g.bootstrapPeers {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+func (g *gossipServiceImpl) isBootstrapPeer(endpoint string) bool {
+	g.configLock.RLock()
+	defer g.configLock.RUnlock()
+	_, exists := g.bootstrapPeers[endpoint]
+	return exists
+}
+
+// UpdateExternalEndpoint changes the endpoint the peer publishes to peers of other organizations,
+// announces it to the other peers and connects to the anchor peers of the channels it is in.
+// An empty endpoint makes the peer inaccessible outside of its organization.
+func (g *gossipServiceImpl) UpdateExternalEndpoint(endpoint string) error {
+	if endpoint != "" {
+		if err := validateEndpoint(endpoint); err != nil {
+			return err
+		}
+	}
+	if g.disc.Self().Endpoint == endpoint {
+		return nil
+	}
+	g.logger.Info("Updating external endpoint to", endpoint)
+	g.disc.UpdateEndpoint(endpoint)
+	if endpoint == "" {
+		g.logger.Warning("External endpoint is empty, peer will not be accessible outside of its organization")
+		return nil
+	}
+
+	// Anchor peers of other organizations are skipped when there is no external endpoint,
+	// and the ones that are connected to learn about the new endpoint from the membership request
+	g.configLock.RLock()
+	joinMsgs := make([]api.JoinChannelMessage, 0, len(g.joinMsgs))
+	for _, joinMsg := range g.joinMsgs {
+		joinMsgs = append(joinMsgs, joinMsg)
+	}
+	g.configLock.RUnlock()
+	for _, joinMsg := range joinMsgs {
+		for _, org := range joinMsg.Members() {
+			g.learnAnchorPeers(org, joinMsg.AnchorPeersOf(org))
+		}
+	}
+	return nil
+}
+
+// isAnchorPeer returns whether the given endpoint is an anchor peer
+// in the latest configuration of any of the channels
+func (g *gossipServiceImpl) isAnchorPeer(endpoint string) bool {
+	g.configLock.RLock()
+	defer g.configLock.RUnlock()
+	for _, joinMsg := range g.joinMsgs {
+		for _, org := range joinMsg.Members() {
+			for _, ap := range joinMsg.AnchorPeersOf(org) {
+				if fmt.Sprintf("%s:%d", ap.Host, ap.Port) == endpoint {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func validateEndpoint(endpoint string) error {
+	host, port, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return fmt.Errorf("Endpoint %s isn't formatted as 'host:port': %v", endpoint, err)
+	}
+	if host == "" {
+		return fmt.Errorf("Endpoint %s has no host", endpoint)
+	}
+	if p, err := strconv.ParseUint(port, 10, 16); err != nil || p == 0 {
+		return fmt.Errorf("Endpoint %s has an invalid port", endpoint)
+	}
+	return nil
+}
+
 // SuspectPeers makes the gossip instance validate identities of suspected peers, and close
 // any connections to peers with identities that are found invalid
 func (g *gossipServiceImpl) SuspectPeers(isSuspected api.PeerSuspector) {
@@ -229,7 +381,7 @@ func (g *gossipServiceImpl) periodicalIdentityValidation(suspectFunc api.PeerSus
 		case s := <-g.toDieChan:
 			g.toDieChan <- s
 			return
-		case <-time.After(interval):
+		case <-util.After(interval):
 			g.SuspectPeers(suspectFunc)
 		}
 	}
@@ -258,6 +410,10 @@ func (g *gossipServiceImpl) learnAnchorPeers(orgOfAnchorPeers api.OrgIdentityTyp
 			continue
 		}
 		identifier := func() (*discovery.PeerIdentification, error) {
+			if !g.isAnchorPeer(endpoint) {
+				// The channel configuration was updated, and the endpoint is no longer an anchor peer
+				return nil, discovery.ErrConnectionAborted
+			}
 			remotePeerIdentity, err := g.comm.Handshake(&comm.RemotePeer{Endpoint: endpoint})
 			if err != nil {
 				g.logger.Warning("Deep probe of", endpoint, "failed:", err)
@@ -299,18 +455,38 @@ func (g *gossipServiceImpl) handlePresumedDead() {
 	}
 }
 
+// handleBlacklisted removes the peers that the communication layer blacklisted for
+// exceeding the message rate limits from the membership, by presuming them dead.
+// Their alive messages are rejected while they are blacklisted, so that they
+// don't re-join the membership through other peers before the blacklist expires.
+func (g *gossipServiceImpl) handleBlacklisted() {
+	defer g.logger.Debug("Exiting")
+	g.stopSignal.Add(1)
+	defer g.stopSignal.Done()
+	for {
+		select {
+		case s := <-g.toDieChan:
+			g.toDieChan <- s
+			return
+		case pkiID := <-g.comm.Blacklisted():
+			g.presumedDead <- pkiID
+		}
+	}
+}
+
 func (g *gossipServiceImpl) syncDiscovery() {
 	g.logger.Debug("Entering discovery sync with interval", g.conf.PullInterval)
 	defer g.logger.Debug("Exiting discovery sync loop")
 	for !g.toDie() {
 		g.disc.InitiateSync(g.conf.PullPeerNum)
-		time.Sleep(g.conf.PullInterval)
+		util.Sleep(g.conf.PullInterval)
 	}
 }
 
 func (g *gossipServiceImpl) start() {
 	go g.syncDiscovery()
 	go g.handlePresumedDead()
+	go g.handleBlacklisted()
 
 	msgSelector := func(msg interface{}) bool {
 		gMsg, isGossipMsg := msg.(proto.ReceivedMessage)
@@ -676,6 +852,50 @@ func (g *gossipServiceImpl) PeersOfChannel(channel common.ChainID) []discovery.N
 	return gc.GetPeers()
 }
 
+// Status returns a snapshot of the membership and channel state of the gossip instance
+func (g *gossipServiceImpl) Status() *Status {
+	status := &Status{
+		Self:                  g.memberStatus(g.disc.Self()),
+		Peers:                 []MemberStatus{},
+		Channels:              make(map[string]*ChannelStatus),
+		Identities:            []IdentityStatus{},
+		CertStoreSize:         g.certStore.pull.Size(),
+		StateInfoMsgStoreSize: g.stateInfoMsgStore.Size(),
+	}
+	for _, member := range g.disc.GetMembership() {
+		status.Peers = append(status.Peers, g.memberStatus(member))
+	}
+	for chainID, gc := range g.chanState.channelsByID() {
+		chanStatus := gc.Status()
+		status.Channels[chainID] = &ChannelStatus{
+			Peers:                 []MemberStatus{},
+			BlockMsgStoreSize:     chanStatus.BlockMsgStoreSize,
+			BlockPullSize:         chanStatus.BlockPullSize,
+			StateInfoMsgStoreSize: chanStatus.StateInfoMsgStoreSize,
+			LeaderMsgStoreSize:    chanStatus.LeaderMsgStoreSize,
+		}
+		for _, member := range chanStatus.Peers {
+			status.Channels[chainID].Peers = append(status.Channels[chainID].Peers, g.memberStatus(member))
+		}
+	}
+	for pkiID, identity := range g.idMapper.Identities() {
+		status.Identities = append(status.Identities, IdentityStatus{
+			PKIid:    common.PKIidType(pkiID),
+			Org:      g.secAdvisor.OrgByPeerIdentity(identity),
+			Identity: identity,
+		})
+	}
+	return status
+}
+
+func (g *gossipServiceImpl) memberStatus(member discovery.NetworkMember) MemberStatus {
+	return MemberStatus{
+		NetworkMember: member,
+		Org:           g.getOrgOfPeer(member.PKIid),
+		LastAlive:     g.disc.LastAlive(member.PKIid),
+	}
+}
+
 // Stop stops the gossip component
 func (g *gossipServiceImpl) Stop() {
 	if g.toDie() {
@@ -727,7 +947,9 @@ func (g *gossipServiceImpl) UpdateChannelMetadata(md []byte, chainID common.Chai
 // can be used to send a reply back to the sender
 func (g *gossipServiceImpl) Accept(acceptor common.MessageAcceptor, passThrough bool) (<-chan *proto.GossipMessage, <-chan proto.ReceivedMessage) {
 	if passThrough {
-		return nil, g.comm.Accept(acceptor)
+		return nil, g.comm.Accept(func(o interface{}) bool {
+			return acceptor(o) && g.eligibleForStateRequest(o.(proto.ReceivedMessage))
+		})
 	}
 	acceptByType := func(o interface{}) bool {
 		if o, isGossipMsg := o.(*proto.GossipMessage); isGossipMsg {
@@ -759,6 +981,25 @@ func (g *gossipServiceImpl) Accept(acceptor common.MessageAcceptor, passThrough
 	return outCh, nil
 }
 
+// eligibleForStateRequest returns whether the given message isn't a state request,
+// or is a state request sent by a peer eligible for the channel of the request
+func (g *gossipServiceImpl) eligibleForStateRequest(m proto.ReceivedMessage) bool {
+	msg := m.GetGossipMessage()
+	if msg.GetStateRequest() == nil {
+		return true
+	}
+	gc := g.chanState.getGossipChannelByChainID(msg.Channel)
+	if gc == nil {
+		g.logger.Debug("Received state request for channel", string(msg.Channel), "which we're not in")
+		return false
+	}
+	if !gc.EligibleForChannel(discovery.NetworkMember{PKIid: m.GetConnectionInfo().ID}) {
+		g.logger.Warning("Peer", m.GetConnectionInfo().ID, "isn't eligible for channel", string(msg.Channel), ", dropping its state request")
+		return false
+	}
+	return true
+}
+
 func selectOnlyDiscoveryMessages(m interface{}) bool {
 	msg, isGossipMsg := m.(proto.ReceivedMessage)
 	if !isGossipMsg {
@@ -900,6 +1141,11 @@ func (sa *discoverySecurityAdapter) ValidateAliveMsg(m *proto.SignedGossipMessag
 		return false
 	}
 
+	if sa.c.IsBlacklisted(am.Membership.PkiId) {
+		sa.logger.Debug("Rejecting alive message of blacklisted peer", am.Membership)
+		return false
+	}
+
 	var identity api.PeerIdentityType
 
 	// If identity is included inside AliveMessage
@@ -931,7 +1177,7 @@ func (sa *discoverySecurityAdapter) SignMessage(m *proto.GossipMessage, internal
 	signer := func(msg []byte) ([]byte, error) {
 		return sa.mcs.Sign(msg)
 	}
-	if m.IsAliveMsg() && time.Now().Before(sa.includeIdentityPeriod) {
+	if m.IsAliveMsg() && util.Now().Before(sa.includeIdentityPeriod) {
 		m.GetAliveMsg().Identity = sa.identity
 	}
 	sMsg := &proto.SignedGossipMessage{
@@ -1043,29 +1289,34 @@ func (g *gossipServiceImpl) sameOrgOrOurOrgPullFilter(msg proto.ReceivedMessage)
 }
 
 func (g *gossipServiceImpl) connect2BootstrapPeers() {
-	for _, endpoint := range g.conf.BootstrapPeers {
-		endpoint := endpoint
-		identifier := func() (*discovery.PeerIdentification, error) {
-			remotePeerIdentity, err := g.comm.Handshake(&comm.RemotePeer{Endpoint: endpoint})
-			if err != nil {
-				return nil, err
-			}
-			sameOrg := bytes.Equal(g.selfOrg, g.secAdvisor.OrgByPeerIdentity(remotePeerIdentity))
-			if !sameOrg {
-				return nil, fmt.Errorf("%s isn't in our organization, cannot be a bootstrap peer", endpoint)
-			}
-			pkiID := g.mcs.GetPKIidOfCert(remotePeerIdentity)
-			if len(pkiID) == 0 {
-				return nil, fmt.Errorf("Wasn't able to extract PKI-ID of remote peer with identity of %v", remotePeerIdentity)
-			}
-			return &discovery.PeerIdentification{ID: pkiID, SelfOrg: sameOrg}, nil
-		}
-		g.disc.Connect(discovery.NetworkMember{
-			InternalEndpoint: endpoint,
-			Endpoint:         endpoint,
-		}, identifier)
+	for _, endpoint := range g.BootstrapPeers() {
+		g.connect2BootstrapPeer(endpoint)
 	}
+}
 
+func (g *gossipServiceImpl) connect2BootstrapPeer(endpoint string) {
+	identifier := func() (*discovery.PeerIdentification, error) {
+		if !g.isBootstrapPeer(endpoint) {
+			return nil, discovery.ErrConnectionAborted
+		}
+		remotePeerIdentity, err := g.comm.Handshake(&comm.RemotePeer{Endpoint: endpoint})
+		if err != nil {
+			return nil, err
+		}
+		sameOrg := bytes.Equal(g.selfOrg, g.secAdvisor.OrgByPeerIdentity(remotePeerIdentity))
+		if !sameOrg {
+			return nil, fmt.Errorf("%s isn't in our organization, cannot be a bootstrap peer", endpoint)
+		}
+		pkiID := g.mcs.GetPKIidOfCert(remotePeerIdentity)
+		if len(pkiID) == 0 {
+			return nil, fmt.Errorf("Wasn't able to extract PKI-ID of remote peer with identity of %v", remotePeerIdentity)
+		}
+		return &discovery.PeerIdentification{ID: pkiID, SelfOrg: sameOrg}, nil
+	}
+	g.disc.Connect(discovery.NetworkMember{
+		InternalEndpoint: endpoint,
+		Endpoint:         endpoint,
+	}, identifier)
 }
 
 func (g *gossipServiceImpl) createStateInfoMsg(metadata []byte, chainID common.ChainID) (*proto.SignedGossipMessage, error) {
@@ -200,8 +200,18 @@ func bootPeers(portPrefix int, ids ...int) []string {
 }
 
 func newGossipInstanceWithCustomMCS(portPrefix int, id int, maxMsgCount int, mcs api.MessageCryptoService, boot ...int) Gossip {
+	conf := newTestConfig(portPrefix, id, maxMsgCount, boot...)
+	selfId := api.PeerIdentityType(conf.InternalEndpoint)
+	idMapper := identity.NewIdentityMapper(mcs, selfId)
+	g := NewGossipServiceWithServer(conf, &orgCryptoService{}, mcs, idMapper,
+		selfId, nil)
+
+	return g
+}
+
+func newTestConfig(portPrefix int, id int, maxMsgCount int, boot ...int) *Config {
 	port := id + portPrefix
-	conf := &Config{
+	return &Config{
 		BindPort:                   port,
 		BootstrapPeers:             bootPeers(portPrefix, boot...),
 		ID:                         fmt.Sprintf("p%d", id),
@@ -218,12 +228,6 @@ func newGossipInstanceWithCustomMCS(portPrefix int, id int, maxMsgCount int, mcs
 		PublishStateInfoInterval:   time.Duration(1) * time.Second,
 		RequestStateInfoInterval:   time.Duration(1) * time.Second,
 	}
-	selfId := api.PeerIdentityType(conf.InternalEndpoint)
-	idMapper := identity.NewIdentityMapper(mcs, selfId)
-	g := NewGossipServiceWithServer(conf, &orgCryptoService{}, mcs, idMapper,
-		selfId, nil)
-
-	return g
 }
 
 func newGossipInstance(portPrefix int, id int, maxMsgCount int, boot ...int) Gossip {
@@ -609,6 +613,17 @@ func TestDissemination(t *testing.T) {
 		assert.Equal(t, 1, receivedLeadershipMessages[i])
 	}
 
+	status := boot.Status()
+	assert.Equal(t, boot.(*gossipServiceImpl).comm.GetPKIid(), status.Self.PKIid)
+	assert.Len(t, status.Peers, n)
+	for _, member := range status.Peers {
+		assert.False(t, member.LastAlive.IsZero())
+	}
+	assert.Len(t, status.Identities, n+1)
+	assert.Len(t, status.Channels, 1)
+	assert.Len(t, status.Channels["A"].Peers, n)
+	assert.Equal(t, msgsCount2Send, status.Channels["A"].BlockMsgStoreSize)
+
 	t.Log("Stopping peers")
 
 	stop := func() {
@@ -1042,6 +1057,201 @@ func TestIdentityExpiration(t *testing.T) {
 	stopPeers(peers)
 }
 
+func TestLeaveChannel(t *testing.T) {
+	t.Parallel()
+	portPrefix := 9610
+	p := newGossipInstance(portPrefix, 0, 100)
+	defer p.Stop()
+
+	p.JoinChan(&joinChanMsg{}, common.ChainID("A"))
+	p.JoinChan(&joinChanMsg{}, common.ChainID("B"))
+	chanState := p.(*gossipServiceImpl).chanState
+	assert.NotNil(t, chanState.getGossipChannelByChainID(common.ChainID("A")))
+
+	p.LeaveChan(common.ChainID("A"))
+	assert.Nil(t, chanState.getGossipChannelByChainID(common.ChainID("A")))
+	assert.NotNil(t, chanState.getGossipChannelByChainID(common.ChainID("B")))
+	// leaving a channel twice is harmless
+	p.LeaveChan(common.ChainID("A"))
+}
+
+func TestStateRequestEligibility(t *testing.T) {
+	t.Parallel()
+	// Scenario: g1 and g2 are in channel A, and g3 isn't.
+	// g2 and g3 both send state requests for channel A to g1.
+	// Expected output: g1 should only get the state request of g2.
+
+	portPrefix := 14610
+	g1 := newGossipInstance(portPrefix, 0, 100)
+	g2 := newGossipInstance(portPrefix, 1, 100, 0)
+	g3 := newGossipInstance(portPrefix, 2, 100, 0)
+	defer g1.Stop()
+	defer g2.Stop()
+	defer g3.Stop()
+
+	for _, g := range []Gossip{g1, g2} {
+		g.JoinChan(&joinChanMsg{}, common.ChainID("A"))
+		g.UpdateChannelMetadata([]byte("bla bla"), common.ChainID("A"))
+	}
+	waitUntilOrFail(t, checkPeersMembership(t, []Gossip{g1, g2, g3}, 2))
+	waitUntilOrFail(t, func() bool {
+		return len(g1.PeersOfChannel(common.ChainID("A"))) == 1
+	})
+
+	_, stateRequests := g1.Accept(func(o interface{}) bool {
+		return o.(proto.ReceivedMessage).GetGossipMessage().GetStateRequest() != nil
+	}, true)
+
+	stateRequest := &proto.GossipMessage{
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte("A"),
+		Content: &proto.GossipMessage_StateRequest{
+			StateRequest: &proto.RemoteStateRequest{StartSeqNum: 1, EndSeqNum: 1},
+		},
+	}
+	g1Peer := &comm.RemotePeer{Endpoint: fmt.Sprintf("localhost:%d", portPrefix), PKIID: common.PKIidType(fmt.Sprintf("localhost:%d", portPrefix))}
+
+	g3.Send(stateRequest, g1Peer)
+	select {
+	case <-time.After(time.Second):
+	case <-stateRequests:
+		assert.Fail(t, "Received a state request from a peer that isn't in the channel")
+	}
+
+	g2.Send(stateRequest, g1Peer)
+	select {
+	case <-time.After(time.Second * 5):
+		assert.Fail(t, "Didn't receive a state request from a peer in the channel on time")
+	case msg := <-stateRequests:
+		assert.Equal(t, common.PKIidType(fmt.Sprintf("localhost:%d", portPrefix+1)), msg.GetConnectionInfo().ID)
+	}
+}
+
+func TestDynamicBootstrapAndExternalEndpoint(t *testing.T) {
+	t.Parallel()
+	portPrefix := 15610
+	g0 := newGossipInstance(portPrefix, 0, 100)
+	g1 := newGossipInstance(portPrefix, 1, 100)
+	defer stopPeers([]Gossip{g0, g1})
+
+	assert.Empty(t, g1.BootstrapPeers())
+	assert.Error(t, g1.UpdateBootstrapPeers([]string{"localhost"}, nil))
+	assert.Error(t, g1.UpdateBootstrapPeers(nil, []string{"localhost:0"}))
+	assert.Empty(t, g1.BootstrapPeers())
+
+	// Adding a bootstrap peer makes the peers connect to each other
+	assert.NoError(t, g1.UpdateBootstrapPeers(bootPeers(portPrefix, 0, 2), nil))
+	assert.Equal(t, bootPeers(portPrefix, 0, 2), g1.BootstrapPeers())
+	waitUntilOrFail(t, checkPeersMembership(t, []Gossip{g0, g1}, 1))
+
+	// Removing bootstrap peers doesn't affect the membership
+	assert.NoError(t, g1.UpdateBootstrapPeers(nil, bootPeers(portPrefix, 0, 2)))
+	assert.Empty(t, g1.BootstrapPeers())
+	assert.False(t, g1.(*gossipServiceImpl).isBootstrapPeer(bootPeers(portPrefix, 2)[0]))
+	assert.Len(t, g0.Peers(), 1)
+
+	// A new external endpoint is announced to the other peers
+	assert.Error(t, g1.UpdateExternalEndpoint("5.6.7.8"))
+	assert.NoError(t, g1.UpdateExternalEndpoint("5.6.7.8:15611"))
+	assert.Equal(t, "5.6.7.8:15611", g1.(*gossipServiceImpl).selfNetworkMember().Endpoint)
+	waitUntilOrFail(t, func() bool {
+		peers := g0.Peers()
+		return len(peers) == 1 && peers[0].Endpoint == "5.6.7.8:15611"
+	})
+}
+
+func TestAnchorPeersOfUpdatedConfig(t *testing.T) {
+	t.Parallel()
+	portPrefix := 16610
+	p := newGossipInstance(portPrefix, 0, 100)
+	defer p.Stop()
+	g := p.(*gossipServiceImpl)
+
+	anchorPeer := api.AnchorPeer{Host: "localhost", Port: portPrefix + 1}
+	p.JoinChan(&joinChanMsg{members2AnchorPeers: map[string][]api.AnchorPeer{
+		string(orgInChannelA): {anchorPeer},
+	}}, common.ChainID("A"))
+	assert.True(t, g.isAnchorPeer(fmt.Sprintf("localhost:%d", portPrefix+1)))
+
+	// Once the anchor peer is removed from the channel config, it isn't connected to anymore
+	p.JoinChan(&joinChanMsg{members2AnchorPeers: map[string][]api.AnchorPeer{
+		string(orgInChannelA): {},
+	}}, common.ChainID("A"))
+	assert.False(t, g.isAnchorPeer(fmt.Sprintf("localhost:%d", portPrefix+1)))
+
+	p.JoinChan(&joinChanMsg{members2AnchorPeers: map[string][]api.AnchorPeer{
+		string(orgInChannelA): {anchorPeer},
+	}}, common.ChainID("B"))
+	assert.True(t, g.isAnchorPeer(fmt.Sprintf("localhost:%d", portPrefix+1)))
+	p.LeaveChan(common.ChainID("B"))
+	assert.False(t, g.isAnchorPeer(fmt.Sprintf("localhost:%d", portPrefix+1)))
+}
+
+// blacklistingComm is a Comm that blacklists peers on demand
+type blacklistingComm struct {
+	comm.Comm
+	blacklisted chan common.PKIidType
+	lock        sync.RWMutex
+	blacklist   map[string]struct{}
+}
+
+func (bc *blacklistingComm) Blacklisted() <-chan common.PKIidType {
+	return bc.blacklisted
+}
+
+func (bc *blacklistingComm) IsBlacklisted(pkiID common.PKIidType) bool {
+	bc.lock.RLock()
+	defer bc.lock.RUnlock()
+	_, blacklisted := bc.blacklist[string(pkiID)]
+	return blacklisted
+}
+
+func (bc *blacklistingComm) setBlacklisted(pkiID common.PKIidType, blacklisted bool) {
+	bc.lock.Lock()
+	if blacklisted {
+		bc.blacklist[string(pkiID)] = struct{}{}
+	} else {
+		delete(bc.blacklist, string(pkiID))
+	}
+	bc.lock.Unlock()
+	if blacklisted {
+		bc.blacklisted <- pkiID
+	}
+}
+
+func TestBlacklistedPeerLeavesMembership(t *testing.T) {
+	t.Parallel()
+	portPrefix := 17610
+	conf := newTestConfig(portPrefix, 0, 100)
+	mcs := &naiveCryptoService{}
+	selfId := api.PeerIdentityType(conf.InternalEndpoint)
+	idMapper := identity.NewIdentityMapper(mcs, selfId)
+	c, err := createCommWithServer(conf.BindPort, idMapper, selfId, nil)
+	assert.NoError(t, err)
+	bc := &blacklistingComm{Comm: c, blacklisted: make(chan common.PKIidType, 1), blacklist: make(map[string]struct{})}
+	g0 := NewGossipServiceWithComm(conf, bc, &orgCryptoService{}, mcs, idMapper, selfId)
+	g1 := newGossipInstance(portPrefix, 1, 100, 0)
+	g2 := newGossipInstance(portPrefix, 2, 100, 0)
+	defer stopPeers([]Gossip{g0, g1, g2})
+	waitUntilOrFail(t, checkPeersMembership(t, []Gossip{g0, g1, g2}, 2))
+
+	// The blacklisted peer is still alive for the other peers,
+	// but it doesn't re-join the membership through them
+	blacklisted := common.PKIidType(fmt.Sprintf("localhost:%d", portPrefix+1))
+	bc.setBlacklisted(blacklisted, true)
+	waitUntilOrFail(t, func() bool {
+		return len(g0.Peers()) == 1
+	})
+	time.Sleep(3 * time.Second)
+	assert.Len(t, g0.Peers(), 1)
+	assert.Equal(t, common.PKIidType(fmt.Sprintf("localhost:%d", portPrefix+2)), g0.Peers()[0].PKIid)
+	assert.Len(t, g2.Peers(), 2)
+
+	// Once the blacklist expires, the peer re-joins the membership
+	bc.setBlacklisted(blacklisted, false)
+	waitUntilOrFail(t, checkPeersMembership(t, []Gossip{g0, g1, g2}, 2))
+}
+
 func TestEndedGoroutines(t *testing.T) {
 	t.Parallel()
 	testWG.Wait()
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
 )
 
 var noopLock = func() {}
@@ -148,7 +149,7 @@ func (s *messageStoreImpl) Add(message interface{}) bool {
 		}
 	}
 
-	s.messages = append(s.messages, &msg{data: message, created: time.Now()})
+	s.messages = append(s.messages, &msg{data: message, created: util.Now()})
 	return true
 }
 
@@ -218,13 +219,13 @@ func (s *messageStoreImpl) expireMessages() {
 	for i := 0; i < n; i++ {
 		m := s.messages[i]
 		if !m.expired {
-			if time.Since(m.created) > s.msgTTL {
+			if util.Since(m.created) > s.msgTTL {
 				m.expired = true
 				s.expireMsgCallback(m.data)
 				s.expiredCount++
 			}
 		} else {
-			if time.Since(m.created) > (s.msgTTL * 2) {
+			if util.Since(m.created) > (s.msgTTL * 2) {
 				s.messages = append(s.messages[:i], s.messages[i+1:]...)
 				n--
 				i--
@@ -251,11 +252,11 @@ func (s *messageStoreImpl) expirationRoutine() {
 		select {
 		case <-s.doneCh:
 			return
-		case <-time.After(s.expirationCheckInterval()):
+		case <-util.After(s.expirationCheckInterval()):
 			hasMessageExpired := func(m *msg) bool {
-				if !m.expired && time.Since(m.created) > s.msgTTL {
+				if !m.expired && util.Since(m.created) > s.msgTTL {
 					return true
-				} else if time.Since(m.created) > (s.msgTTL * 2) {
+				} else if util.Since(m.created) > (s.msgTTL * 2) {
 					return true
 				}
 				return false
@@ -110,6 +110,9 @@ type Mediator interface {
 
 	// HandleMessage handles a message from some remote peer
 	HandleMessage(msg proto.ReceivedMessage)
+
+	// Size returns the number of messages the Mediator holds
+	Size() int
 }
 
 // pullMediatorImpl is an implementation of Mediator
@@ -235,6 +238,13 @@ func (p *pullMediatorImpl) Remove(digest string) {
 	p.engine.Remove(digest)
 }
 
+// Size returns the number of messages the Mediator holds
+func (p *pullMediatorImpl) Size() int {
+	p.RLock()
+	defer p.RUnlock()
+	return len(p.itemID2Msg)
+}
+
 // SelectPeers returns a slice of peers which the engine will initiate the protocol with
 func (p *pullMediatorImpl) SelectPeers() []string {
 	remotePeers := SelectEndpoints(p.config.PeerCountToSelect, p.MemSvc.GetMembership())
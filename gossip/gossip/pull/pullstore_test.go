@@ -256,8 +256,11 @@ func TestAddAndRemove(t *testing.T) {
 	inst1.mediator.Remove("0")
 	inst2.items.Remove(uint64(0))
 
+	assert.Equal(t, msgCount-1, inst1.mediator.Size())
+
 	// Add a message to inst1
 	inst1.mediator.Add(dataMsg(10))
+	assert.Equal(t, msgCount, inst1.mediator.Size())
 
 	// Ensure instance 2 got new message
 	waitUntilOrFail(t, func() bool { return inst2.items.Exists(uint64(10)) })
@@ -26,6 +26,7 @@ import (
 
 	"github.com/hyperledger/fabric/gossip/api"
 	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
 )
 
 var (
@@ -59,6 +60,9 @@ type Mapper interface {
 	// peer identities have been revoked, expired or haven't been used
 	// for a long time
 	ListInvalidIdentities(isSuspected api.PeerSuspector) []common.PKIidType
+
+	// Identities returns the identities held by the Mapper, by PKI-ID
+	Identities() map[string]api.PeerIdentityType
 }
 
 // identityMapperImpl is a struct that implements Mapper
@@ -156,10 +160,22 @@ func (is *identityMapperImpl) ListInvalidIdentities(isSuspected api.PeerSuspecto
 	return revokedIds
 }
 
+// Identities returns the identities held by the Mapper, by PKI-ID.
+// Listing the identities doesn't count as using them
+func (is *identityMapperImpl) Identities() map[string]api.PeerIdentityType {
+	is.RLock()
+	defer is.RUnlock()
+	identities := make(map[string]api.PeerIdentityType, len(is.pkiID2Cert))
+	for pkiID, storedIdentity := range is.pkiID2Cert {
+		identities[pkiID] = storedIdentity.peerIdentity
+	}
+	return identities
+}
+
 // validateIdentities returns a list of identities that have been revoked, expired or haven't been
 // used for a long time
 func (is *identityMapperImpl) validateIdentities(isSuspected api.PeerSuspector) []common.PKIidType {
-	now := time.Now()
+	now := util.Now()
 	is.RLock()
 	defer is.RUnlock()
 	var revokedIds []common.PKIidType
@@ -185,13 +201,13 @@ type storedIdentity struct {
 
 func newStoredIdentity(identity api.PeerIdentityType) *storedIdentity {
 	return &storedIdentity{
-		lastAccessTime: time.Now().UnixNano(),
+		lastAccessTime: util.Now().UnixNano(),
 		peerIdentity:   identity,
 	}
 }
 
 func (si *storedIdentity) fetchIdentity() api.PeerIdentityType {
-	atomic.StoreInt64(&si.lastAccessTime, time.Now().UnixNano())
+	atomic.StoreInt64(&si.lastAccessTime, util.Now().UnixNano())
 	return si.peerIdentity
 }
 
@@ -112,6 +112,17 @@ func TestGet(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestIdentities(t *testing.T) {
+	idStore := NewIdentityMapper(msgCryptoService, dummyID)
+	identity := []byte("yacovm")
+	pkiID := msgCryptoService.GetPKIidOfCert(api.PeerIdentityType(identity))
+	assert.NoError(t, idStore.Put(pkiID, identity))
+	assert.Equal(t, map[string]api.PeerIdentityType{
+		string(msgCryptoService.GetPKIidOfCert(dummyID)): dummyID,
+		string(pkiID): api.PeerIdentityType(identity),
+	}, idStore.Identities())
+}
+
 func TestVerify(t *testing.T) {
 	idStore := NewIdentityMapper(msgCryptoService, dummyID)
 	identity := []byte("yacovm")
@@ -17,6 +17,7 @@ limitations under the License.
 package service
 
 import (
+	"errors"
 	"sync"
 
 	"github.com/hyperledger/fabric/core/committer"
@@ -51,6 +52,8 @@ type GossipService interface {
 	NewConfigEventer() ConfigProcessor
 	// InitializeChannel allocates the state provider and should be invoked once per channel per execution
 	InitializeChannel(chainID string, committer committer.Committer, endpoints []string)
+	// LeaveChannel stops the state provider, the leader election and the blocks delivery of the channel
+	LeaveChannel(chainID string)
 	// GetBlock returns block for given chain
 	GetBlock(chainID string, index uint64) *common.Block
 	// AddPayload appends message payload to for given chain
@@ -68,13 +71,19 @@ type deliveryFactoryImpl struct {
 
 // Returns an instance of delivery client
 func (*deliveryFactoryImpl) Service(g GossipService, endpoints []string, mcs api.MessageCryptoService) (deliverclient.DeliverService, error) {
-	return deliverclient.NewDeliverService(&deliverclient.Config{
+	conf := &deliverclient.Config{
 		CryptoSvc:   mcs,
 		Gossip:      g,
 		Endpoints:   endpoints,
 		ConnFactory: deliverclient.DefaultConnectionFactory,
 		ABCFactory:  deliverclient.DefaultABCFactory,
-	})
+	}
+	// When leaders are elected dynamically, a leader that cannot reach the ordering service
+	// gives up early and yields its leadership, so that another peer takes over
+	if viper.GetBool("peer.gossip.useLeaderElection") {
+		conf.ReconnectTotalTimeThreshold = election.GetFailoverThreshold()
+	}
+	return deliverclient.NewDeliverService(conf)
 }
 
 type gossipServiceImpl struct {
@@ -176,7 +185,7 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 	defer g.lock.Unlock()
 	// Initialize new state provider for given committer
 	logger.Debug("Creating state provider for chainID", chainID)
-	g.chains[chainID] = state.NewGossipStateProvider(chainID, g, committer, g.mcs)
+	g.chains[chainID] = state.NewGossipStateProvider(chainID, g, committer, g.mcs, &ordererFetcher{g: g, chainID: chainID})
 	if g.deliveryService == nil {
 		var err error
 		g.deliveryService, err = g.deliveryFactory.Service(gossipServiceInstance, endpoints, g.mcs)
@@ -203,7 +212,7 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 
 		if leaderElection {
 			logger.Debug("Delivery uses dynamic leader election mechanism, channel", chainID)
-			g.leaderElection[chainID] = g.newLeaderElectionComponent(chainID, g.onStatusChangeFactory(chainID, committer))
+			g.leaderElection[chainID] = g.newLeaderElectionComponent(chainID, committer, g.onStatusChangeFactory(chainID, committer))
 		} else if isStaticOrgLeader {
 			logger.Debug("This peer is configured to connect to ordering service for blocks delivery, channel", chainID)
 			g.deliveryService.StartDeliverForChannel(chainID, committer, func() {})
@@ -215,6 +224,32 @@ func (g *gossipServiceImpl) InitializeChannel(chainID string, committer committe
 	}
 }
 
+// LeaveChannel stops the state provider, the leader election and the blocks delivery of the channel,
+// and makes the peer stop gossiping about the channel
+func (g *gossipServiceImpl) LeaveChannel(chainID string) {
+	g.lock.Lock()
+	stateProvider := g.chains[chainID]
+	delete(g.chains, chainID)
+	le := g.leaderElection[chainID]
+	delete(g.leaderElection, chainID)
+	g.lock.Unlock()
+
+	// stopped without holding the lock as the leader election callbacks take it
+	logger.Info("Leaving channel", chainID)
+	if le != nil {
+		le.Stop()
+	}
+	if g.deliveryService != nil {
+		if err := g.deliveryService.StopDeliverForChannel(chainID); err != nil {
+			logger.Debug("Blocks delivery was not running for channel", chainID)
+		}
+	}
+	if stateProvider != nil {
+		stateProvider.Stop()
+	}
+	g.LeaveChan(gossipCommon.ChainID(chainID))
+}
+
 // configUpdated constructs a joinChannelMessage and sends it to the gossipSvc
 func (g *gossipServiceImpl) configUpdated(config Config) {
 	myOrg := string(g.secAdv.OrgByPeerIdentity(api.PeerIdentityType(g.peerIdentity)))
@@ -255,6 +290,22 @@ func (g *gossipServiceImpl) AddPayload(chainID string, payload *proto.Payload) e
 	return g.chains[chainID].AddPayload(payload)
 }
 
+// Status returns a snapshot of the membership and channel state of the gossip component,
+// along with the leader of the organization in each channel
+func (g *gossipServiceImpl) Status() *gossip.Status {
+	status := g.gossipSvc.Status()
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+	for chainID, chanStatus := range status.Channels {
+		if le, exists := g.leaderElection[chainID]; exists {
+			chanStatus.Leader = le.Leader()
+		} else if viper.GetBool("peer.gossip.orgLeader") {
+			chanStatus.Leader = g.idMapper.GetPKIidOfCert(g.peerIdentity)
+		}
+	}
+	return status
+}
+
 // Stop stops the gossip component
 func (g *gossipServiceImpl) Stop() {
 	g.lock.Lock()
@@ -274,9 +325,27 @@ func (g *gossipServiceImpl) Stop() {
 	}
 }
 
-func (g *gossipServiceImpl) newLeaderElectionComponent(chainID string, callback func(bool)) election.LeaderElectionService {
+// ordererFetcher fetches blocks of a channel from the ordering service
+// through the delivery service of the peer
+type ordererFetcher struct {
+	g       *gossipServiceImpl
+	chainID string
+}
+
+// FetchBlocks fetches the blocks in the range [start...end] from the ordering service
+func (f *ordererFetcher) FetchBlocks(start uint64, end uint64) ([]*common.Block, error) {
+	f.g.lock.RLock()
+	ds := f.g.deliveryService
+	f.g.lock.RUnlock()
+	if ds == nil {
+		return nil, errors.New("delivery service isn't available")
+	}
+	return ds.FetchBlocks(f.chainID, start, end)
+}
+
+func (g *gossipServiceImpl) newLeaderElectionComponent(chainID string, ledgerInfo election.LedgerInfo, callback func(bool)) election.LeaderElectionService {
 	PKIid := g.idMapper.GetPKIidOfCert(g.peerIdentity)
-	adapter := election.NewAdapter(g, PKIid, gossipCommon.ChainID(chainID))
+	adapter := election.NewAdapter(g, PKIid, gossipCommon.ChainID(chainID), ledgerInfo)
 	return election.NewLeaderElectionService(adapter, string(PKIid), callback)
 }
 
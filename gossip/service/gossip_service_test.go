@@ -18,6 +18,7 @@ package service
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -146,6 +147,27 @@ func TestLeaderElectionWithDeliverClient(t *testing.T) {
 
 	assert.Equal(t, 1, startsNum, "Only for one peer delivery client should start")
 
+	// All peers eventually report the elected peer as the leader of the channel
+	var leaderPKIID gossipCommon.PKIidType
+	for i := 0; i < n; i++ {
+		if services[i].IsLeader() {
+			leaderPKIID = gossips[i].(*gossipServiceImpl).idMapper.GetPKIidOfCert(gossips[i].(*gossipServiceImpl).peerIdentity)
+		}
+	}
+	leaderReported := func() bool {
+		for i := 0; i < n; i++ {
+			if !bytes.Equal(leaderPKIID, gossips[i].Status().Channels[channelName].Leader) {
+				return false
+			}
+		}
+		return true
+	}
+	end := time.Now().Add(time.Second * 30)
+	for !leaderReported() && time.Now().Before(end) {
+		time.Sleep(time.Second)
+	}
+	assert.True(t, leaderReported(), "All peers should report the leader of the channel")
+
 	stopPeers(gossips)
 }
 
@@ -296,6 +318,10 @@ func (ds *mockDeliverService) StopDeliverForChannel(chainID string) error {
 	return nil
 }
 
+func (ds *mockDeliverService) FetchBlocks(chainID string, start uint64, end uint64) ([]*common.Block, error) {
+	return nil, errors.New("not supported")
+}
+
 func (ds *mockDeliverService) Stop() {
 }
 
@@ -352,7 +378,7 @@ func TestLeaderElectionWithRealGossip(t *testing.T) {
 
 	for i := 0; i < n; i++ {
 		services[i] = &electionService{nil, false, 0}
-		services[i].LeaderElectionService = gossips[i].(*gossipServiceImpl).newLeaderElectionComponent(channelName, services[i].callback)
+		services[i].LeaderElectionService = gossips[i].(*gossipServiceImpl).newLeaderElectionComponent(channelName, nil, services[i].callback)
 	}
 
 	logger.Warning("Waiting for leader election")
@@ -378,7 +404,7 @@ func TestLeaderElectionWithRealGossip(t *testing.T) {
 
 	for idx, i := range secondChannelPeerIndexes {
 		secondChannelServices[idx] = &electionService{nil, false, 0}
-		secondChannelServices[idx].LeaderElectionService = gossips[i].(*gossipServiceImpl).newLeaderElectionComponent(secondChannelName, secondChannelServices[idx].callback)
+		secondChannelServices[idx].LeaderElectionService = gossips[i].(*gossipServiceImpl).newLeaderElectionComponent(secondChannelName, nil, secondChannelServices[idx].callback)
 	}
 
 	assert.True(t, waitForLeaderElection(t, secondChannelServices, time.Second*30, time.Second*2), "One leader should be selected for chanB")
@@ -26,6 +26,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
 	"github.com/hyperledger/fabric/gossip/util"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/hyperledger/fabric/protos/peer"
@@ -52,6 +53,22 @@ func (*gossipMock) SuspectPeers(s api.PeerSuspector) {
 	panic("implement me")
 }
 
+func (*gossipMock) Status() *gossip.Status {
+	panic("implement me")
+}
+
+func (*gossipMock) UpdateBootstrapPeers(add []string, remove []string) error {
+	panic("implement me")
+}
+
+func (*gossipMock) BootstrapPeers() []string {
+	panic("implement me")
+}
+
+func (*gossipMock) UpdateExternalEndpoint(endpoint string) error {
+	panic("implement me")
+}
+
 func (*gossipMock) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
 	panic("implement me")
 }
@@ -84,6 +101,10 @@ func (g *gossipMock) JoinChan(joinMsg api.JoinChannelMessage, chainID common.Cha
 	g.Called(joinMsg, chainID)
 }
 
+func (*gossipMock) LeaveChan(chainID common.ChainID) {
+	panic("implement me")
+}
+
 func (*gossipMock) Stop() {
 	panic("implement me")
 }
@@ -0,0 +1,121 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/common"
+)
+
+const peerPort = 7051
+
+// Endpoint returns the endpoint of the given peer of the given organization
+func Endpoint(org int, peer int) string {
+	return fmt.Sprintf("peer%d.%s:%d", peer, orgName(org), peerPort)
+}
+
+func orgName(org int) string {
+	return fmt.Sprintf("org%d", org)
+}
+
+func channelName(channel int) string {
+	return fmt.Sprintf("channel%d", channel)
+}
+
+// cryptoService signs and verifies messages naively, as the simulation measures
+// the dissemination of messages and not the cost of cryptography.
+// The identity of a peer is its endpoint, and it is also its PKI-ID.
+type cryptoService struct{}
+
+// GetPKIidOfCert returns the PKI-ID of a peer's identity
+func (*cryptoService) GetPKIidOfCert(peerIdentity api.PeerIdentityType) common.PKIidType {
+	return common.PKIidType(peerIdentity)
+}
+
+// VerifyBlock returns nil, as blocks are produced by the simulation itself
+func (*cryptoService) VerifyBlock(chainID common.ChainID, seqNum uint64, signedBlock []byte) error {
+	return nil
+}
+
+// Sign returns a copy of the message as its signature
+func (*cryptoService) Sign(msg []byte) ([]byte, error) {
+	sig := make([]byte, len(msg))
+	copy(sig, msg)
+	return sig, nil
+}
+
+// Verify checks that the signature is a copy of the message
+func (*cryptoService) Verify(peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	if !bytes.Equal(signature, message) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+// VerifyByChannel checks that the signature is a copy of the message
+func (cs *cryptoService) VerifyByChannel(chainID common.ChainID, peerIdentity api.PeerIdentityType, signature, message []byte) error {
+	return cs.Verify(peerIdentity, signature, message)
+}
+
+// ValidateIdentity returns nil, as all identities are valid
+func (*cryptoService) ValidateIdentity(peerIdentity api.PeerIdentityType) error {
+	return nil
+}
+
+// securityAdvisor derives the organization of a peer from its endpoint
+type securityAdvisor struct{}
+
+// OrgByPeerIdentity returns the organization of the peer with the given identity
+func (*securityAdvisor) OrgByPeerIdentity(identity api.PeerIdentityType) api.OrgIdentityType {
+	host := strings.SplitN(string(identity), ":", 2)[0]
+	parts := strings.SplitN(host, ".", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	return api.OrgIdentityType(parts[1])
+}
+
+// joinChannelMessage lists the organizations of a channel, with the
+// first peer of each organization as its anchor peer
+type joinChannelMessage struct {
+	orgs []int
+}
+
+// SequenceNumber returns the sequence number of the configuration block
+// the message is derived from
+func (*joinChannelMessage) SequenceNumber() uint64 {
+	return 1
+}
+
+// Members returns the organizations of the channel
+func (jcm *joinChannelMessage) Members() []api.OrgIdentityType {
+	var members []api.OrgIdentityType
+	for _, org := range jcm.orgs {
+		members = append(members, api.OrgIdentityType(orgName(org)))
+	}
+	return members
+}
+
+// AnchorPeersOf returns the anchor peers of the given organization
+func (jcm *joinChannelMessage) AnchorPeersOf(org api.OrgIdentityType) []api.AnchorPeer {
+	return []api.AnchorPeer{{Host: fmt.Sprintf("peer0.%s", org), Port: peerPort}}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/hyperledger/fabric/protos/common"
+)
+
+// ledger is an in-memory committer.Committer that records the time
+// each block is committed at
+type ledger struct {
+	lock        sync.RWMutex
+	blocks      []*common.Block
+	committedAt []time.Time
+}
+
+func newLedger(genesis *common.Block) *ledger {
+	return &ledger{
+		blocks:      []*common.Block{genesis},
+		committedAt: []time.Time{util.Now()},
+	}
+}
+
+// Commit appends the block to the ledger
+func (l *ledger) Commit(block *common.Block) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if block.Header.Number != uint64(len(l.blocks)) {
+		return errors.New("block is out of order")
+	}
+	l.blocks = append(l.blocks, block)
+	l.committedAt = append(l.committedAt, util.Now())
+	return nil
+}
+
+// LedgerHeight returns the number of blocks in the ledger
+func (l *ledger) LedgerHeight() (uint64, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return uint64(len(l.blocks)), nil
+}
+
+// GetBlocks returns the blocks with the given sequence numbers that are in the ledger
+func (l *ledger) GetBlocks(blockSeqs []uint64) []*common.Block {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	var blocks []*common.Block
+	for _, seqNum := range blockSeqs {
+		if seqNum < uint64(len(l.blocks)) {
+			blocks = append(blocks, l.blocks[seqNum])
+		}
+	}
+	return blocks
+}
+
+// Close does nothing, as there are no resources to release
+func (l *ledger) Close() {
+}
+
+// commitTime returns the time the block with the given sequence number was committed at,
+// and false if it wasn't committed yet
+func (l *ledger) commitTime(seqNum uint64) (time.Time, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	if seqNum >= uint64(len(l.committedAt)) {
+		return time.Time{}, false
+	}
+	return l.committedAt[seqNum], true
+}
+
+// orderer produces the blocks of a channel, and serves them to the leaders of the channel.
+// It doesn't communicate over the simulated network, as the simulation measures
+// the dissemination of blocks among the peers.
+type orderer struct {
+	lock       sync.Mutex
+	blocks     []*common.Block
+	producedAt []time.Time
+	// newBlock is closed and replaced whenever a block is produced
+	newBlock chan struct{}
+}
+
+func newOrderer() *orderer {
+	return &orderer{
+		blocks:     []*common.Block{common.NewBlock(0, []byte{})},
+		producedAt: []time.Time{util.Now()},
+		newBlock:   make(chan struct{}),
+	}
+}
+
+func (o *orderer) genesis() *common.Block {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.blocks[0]
+}
+
+// produce appends a new block to the chain
+func (o *orderer) produce() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	prev := o.blocks[len(o.blocks)-1]
+	block := common.NewBlock(uint64(len(o.blocks)), prev.Header.Hash())
+	block.Data.Data = [][]byte{[]byte("transaction")}
+	block.Header.DataHash = block.Data.Hash()
+	o.blocks = append(o.blocks, block)
+	o.producedAt = append(o.producedAt, util.Now())
+	close(o.newBlock)
+	o.newBlock = make(chan struct{})
+}
+
+// block returns the block with the given sequence number, or a channel
+// that is closed once the next block is produced if there is no such block yet
+func (o *orderer) block(seqNum uint64) (*common.Block, <-chan struct{}) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if seqNum < uint64(len(o.blocks)) {
+		return o.blocks[seqNum], nil
+	}
+	return nil, o.newBlock
+}
+
+// productionTime returns the time the block with the given sequence number was produced at
+func (o *orderer) productionTime(seqNum uint64) time.Time {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	return o.producedAt[seqNum]
+}
+
+// FetchBlocks returns the blocks in the range [start...end] that were produced
+func (o *orderer) FetchBlocks(start uint64, end uint64) ([]*common.Block, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if start >= uint64(len(o.blocks)) {
+		return nil, errors.New("blocks weren't produced yet")
+	}
+	if end >= uint64(len(o.blocks)) {
+		end = uint64(len(o.blocks)) - 1
+	}
+	return append([]*common.Block(nil), o.blocks[start:end+1]...), nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"fmt"
+	"sync"
+
+	pb "github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/gossip/api"
+	"github.com/hyperledger/fabric/gossip/comm/sim"
+	"github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/election"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/identity"
+	"github.com/hyperledger/fabric/gossip/state"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+// peer is a simulated peer, which runs the gossip component along with the
+// state transfer and the leader election of each channel it joined
+type peer struct {
+	org      int
+	endpoint string
+	gossip   gossip.Gossip
+	channels map[string]*peerChannel
+}
+
+// peerChannel is the state of a channel in a simulated peer
+type peerChannel struct {
+	name     string
+	ledger   *ledger
+	orderer  *orderer
+	state    state.GossipStateProvider
+	election election.LeaderElectionService
+
+	lock sync.Mutex
+	// stopDelivery stops the delivery of blocks from the orderer,
+	// and is nil if the peer isn't a leader
+	stopDelivery chan struct{}
+	deliveryWG   sync.WaitGroup
+}
+
+func newPeer(net *sim.Network, conf gossip.Config, org int, id int, bootstrapPeers []string) (*peer, error) {
+	endpoint := Endpoint(org, id)
+	selfIdentity := api.PeerIdentityType(endpoint)
+	mcs := &cryptoService{}
+	c, err := net.NewComm(endpoint, selfIdentity, mcs.GetPKIidOfCert(selfIdentity))
+	if err != nil {
+		return nil, err
+	}
+
+	conf.ID = endpoint
+	conf.InternalEndpoint = endpoint
+	conf.ExternalEndpoint = endpoint
+	conf.BootstrapPeers = bootstrapPeers
+	idMapper := identity.NewIdentityMapper(mcs, selfIdentity)
+	g := gossip.NewGossipServiceWithComm(&conf, c, &securityAdvisor{}, mcs, idMapper, selfIdentity)
+	return &peer{
+		org:      org,
+		endpoint: endpoint,
+		gossip:   g,
+		channels: make(map[string]*peerChannel),
+	}, nil
+}
+
+// joinChannel makes the peer join the channel of the given organizations, whose blocks
+// are produced by the given orderer. If ordererFallback is true, blocks that no
+// peer has are fetched from the orderer.
+func (p *peer) joinChannel(name string, orgs []int, o *orderer, ordererFallback bool) {
+	p.gossip.JoinChan(&joinChannelMessage{orgs: orgs}, common.ChainID(name))
+
+	ch := &peerChannel{
+		name:    name,
+		ledger:  newLedger(o.genesis()),
+		orderer: o,
+	}
+	var fetcher state.OrdererFetcher
+	if ordererFallback {
+		fetcher = o
+	}
+	ch.state = state.NewGossipStateProvider(name, p.gossip, ch.ledger, &cryptoService{}, fetcher)
+	pkiID := common.PKIidType(p.endpoint)
+	adapter := election.NewAdapter(p.gossip, pkiID, common.ChainID(name), ch.ledger)
+	ch.election = election.NewLeaderElectionService(adapter, string(pkiID), func(isLeader bool) {
+		if isLeader {
+			ch.startDelivery(p.gossip)
+		} else {
+			ch.stopDeliveryIfActive()
+		}
+	})
+	p.channels[name] = ch
+}
+
+// startDelivery makes the peer pull blocks from the orderer and disseminate
+// them to the other peers, as the leader of its organization does
+func (ch *peerChannel) startDelivery(g gossip.Gossip) {
+	ch.lock.Lock()
+	defer ch.lock.Unlock()
+	if ch.stopDelivery != nil {
+		return
+	}
+	stop := make(chan struct{})
+	ch.stopDelivery = stop
+	ch.deliveryWG.Add(1)
+	go ch.deliver(g, stop)
+}
+
+func (ch *peerChannel) stopDeliveryIfActive() {
+	ch.lock.Lock()
+	stop := ch.stopDelivery
+	ch.stopDelivery = nil
+	ch.lock.Unlock()
+	if stop != nil {
+		close(stop)
+	}
+	ch.deliveryWG.Wait()
+}
+
+func (ch *peerChannel) deliver(g gossip.Gossip, stop chan struct{}) {
+	defer ch.deliveryWG.Done()
+	next, _ := ch.ledger.LedgerHeight()
+	for {
+		block, newBlock := ch.orderer.block(next)
+		if block == nil {
+			select {
+			case <-newBlock:
+				continue
+			case <-stop:
+				return
+			}
+		}
+		blockBytes, err := pb.Marshal(block)
+		if err != nil {
+			panic(fmt.Sprintf("failed marshaling block %d: %v", next, err))
+		}
+		payload := &proto.Payload{SeqNum: next, Data: blockBytes}
+		if err := ch.state.AddPayload(payload); err != nil {
+			logger.Debug("Failed adding payload of", next, "because:", err)
+		}
+		g.Gossip(&proto.GossipMessage{
+			Tag:     proto.GossipMessage_CHAN_AND_ORG,
+			Channel: []byte(ch.name),
+			Content: &proto.GossipMessage_DataMsg{
+				DataMsg: &proto.DataMessage{Payload: payload},
+			},
+		})
+		next++
+
+		select {
+		case <-stop:
+			return
+		default:
+		}
+	}
+}
+
+func (p *peer) isLeader(channel string) bool {
+	return p.channels[channel].election.IsLeader()
+}
+
+func (p *peer) stop() {
+	for _, ch := range p.channels {
+		ch.election.Stop()
+		ch.stopDeliveryIfActive()
+		ch.state.Stop()
+	}
+	p.gossip.Stop()
+}
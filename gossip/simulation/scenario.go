@@ -0,0 +1,460 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm/sim"
+	"github.com/hyperledger/fabric/gossip/gossip"
+	"github.com/hyperledger/fabric/gossip/util"
+)
+
+const (
+	defTimeout  = time.Minute
+	defTimeStep = 10 * time.Millisecond
+	defSpeedup  = 1
+)
+
+var logger = util.GetLogger(util.LoggingSimulationModule, "")
+
+// Scenario describes a network of peers and the workload it is subjected to.
+// Peer i of organization j listens on the endpoint returned by Endpoint(j, i).
+// The timings of discovery and leader election are set by the package level
+// setters of the discovery and election packages, and not by the scenario.
+//
+// Scenarios run in virtual time: the clock of the gossip components and of the
+// network is a sim.ManualClock, which the scenario advances step by step.
+// Blocks are produced and faults are applied between steps, so their order
+// doesn't depend on the load of the machine, and so are the durations reported.
+type Scenario struct {
+	// Orgs is the number of organizations
+	Orgs int
+	// PeersPerOrg is the number of peers in each organization
+	PeersPerOrg int
+	// Channels is the number of channels
+	Channels int
+	// OrgsPerChannel is the number of organizations in each channel, or 0 for all.
+	// Channel i consists of organizations i, i+1, ..., i+OrgsPerChannel-1 modulo Orgs.
+	OrgsPerChannel int
+	// Blocks is the number of blocks the orderer produces for each channel
+	Blocks int
+	// BlockInterval is the time between consecutive blocks of a channel
+	BlockInterval time.Duration
+	// Network are the conditions of all links of the network
+	Network sim.LinkConditions
+	// Gossip is the configuration of the gossip component of all peers.
+	// The ID, endpoints and bootstrap peers are set for each peer separately.
+	Gossip gossip.Config
+	// OrdererFallback makes the peers fetch blocks from the orderer
+	// when no peer has them
+	OrdererFallback bool
+	// Faults are injected into the network while blocks are produced
+	Faults []Fault
+	// Timeout bounds each phase of the scenario in virtual time, one minute if zero
+	Timeout time.Duration
+	// TimeStep is the virtual time the clock advances by at each step, 10ms if zero
+	TimeStep time.Duration
+	// Speedup is the ratio of virtual time to wall-clock time, 1 if zero.
+	// The peers have to keep up with the clock for the simulation to be
+	// faithful, which depends on their number and on the available CPU.
+	Speedup float64
+}
+
+// Fault is a change of the network that is applied at a given virtual
+// time after the first block is produced, before the blocks due at that time
+type Fault struct {
+	After time.Duration
+	Apply func(*sim.Network)
+}
+
+// Report holds the results of running a scenario. Durations are in virtual time
+type Report struct {
+	// Membership is the time it took, since the peers started, for every peer to learn
+	// about all peers of its organization and of the organizations it shares a channel with
+	Membership time.Duration
+	// Leadership is the time it took, since the peers started, for every
+	// organization to have a leader in each of its channels
+	Leadership time.Duration
+	// Dissemination is the time it took, after the first block was produced,
+	// for all peers to commit all blocks
+	Dissemination time.Duration
+	// MeanBlockLatency and MaxBlockLatency are the mean and the maximal time
+	// it took a peer to commit a block since it was produced
+	MeanBlockLatency time.Duration
+	MaxBlockLatency  time.Duration
+	// Messages are the counters of messages the network handled
+	Messages sim.Stats
+}
+
+func (r *Report) String() string {
+	return fmt.Sprintf("membership: %v, leadership: %v, dissemination: %v, "+
+		"block latency: mean %v max %v, messages: sent %d delivered %d dropped %d",
+		r.Membership, r.Leadership, r.Dissemination, r.MeanBlockLatency, r.MaxBlockLatency,
+		r.Messages.Sent, r.Messages.Delivered, r.Messages.Dropped)
+}
+
+// DefaultGossipConfig returns the gossip configuration
+// that peers use by default
+func DefaultGossipConfig() gossip.Config {
+	return gossip.Config{
+		MaxBlockCountToStore:       100,
+		MaxPropagationBurstLatency: 10 * time.Millisecond,
+		MaxPropagationBurstSize:    10,
+		PropagateIterations:        1,
+		PropagatePeerNum:           3,
+		PullInterval:               4 * time.Second,
+		PullPeerNum:                3,
+		PublishCertPeriod:          10 * time.Second,
+		RequestStateInfoInterval:   4 * time.Second,
+		PublishStateInfoInterval:   4 * time.Second,
+	}
+}
+
+// Run runs the scenario on a simulated network, and reports how long it took
+// the network to converge. It returns an error if the scenario is invalid or
+// if any of its phases didn't complete in time.
+// Scenarios are run one at a time, as the clock of the gossip components is global.
+func Run(s Scenario) (*Report, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	if s.Timeout == 0 {
+		s.Timeout = defTimeout
+	}
+	if s.TimeStep == 0 {
+		s.TimeStep = defTimeStep
+	}
+	if s.Speedup == 0 {
+		s.Speedup = defSpeedup
+	}
+
+	runLock.Lock()
+	defer runLock.Unlock()
+	clock := sim.NewManualClock(time.Now())
+	util.SetClock(clock)
+	defer util.SetClock(nil)
+
+	r := &runner{
+		Scenario: s,
+		clock:    clock,
+		net:      sim.NewNetwork(clock, s.Network),
+		orderers: make(map[string]*orderer),
+	}
+	defer r.stop()
+	report := &Report{}
+
+	start := clock.Now()
+	if err := r.startPeers(); err != nil {
+		return nil, err
+	}
+	// Peers of different organizations learn about each other
+	// through the anchor peers of the channels they join
+	r.joinChannels()
+	if err := r.await("membership", r.membershipConverged); err != nil {
+		return nil, err
+	}
+	report.Membership = clock.Now().Sub(start)
+	if err := r.await("leadership", r.leadersElected); err != nil {
+		return nil, err
+	}
+	report.Leadership = clock.Now().Sub(start)
+
+	start = clock.Now()
+	r.produceBlocks()
+	if err := r.await("dissemination", r.blocksCommitted); err != nil {
+		return nil, err
+	}
+	report.Dissemination = clock.Now().Sub(start)
+	report.MeanBlockLatency, report.MaxBlockLatency = r.blockLatencies()
+	report.Messages = r.net.Stats()
+
+	logger.Info("Scenario completed:", report)
+	return report, nil
+}
+
+func (s Scenario) validate() error {
+	if s.Orgs < 1 || s.PeersPerOrg < 1 || s.Channels < 1 {
+		return errors.New("scenario must have at least one organization, peer and channel")
+	}
+	if s.OrgsPerChannel < 0 || s.OrgsPerChannel > s.Orgs {
+		return fmt.Errorf("orgs per channel must be between 0 and %d", s.Orgs)
+	}
+	if s.Blocks < 0 {
+		return errors.New("number of blocks can't be negative")
+	}
+	if s.TimeStep < 0 || s.Speedup < 0 {
+		return errors.New("time step and speedup can't be negative")
+	}
+	for _, f := range s.Faults {
+		if f.Apply == nil {
+			return errors.New("fault must have an Apply function")
+		}
+	}
+	return nil
+}
+
+// channelOrgs returns the organizations of the given channel
+func (s Scenario) channelOrgs(channel int) []int {
+	n := s.OrgsPerChannel
+	if n == 0 {
+		n = s.Orgs
+	}
+	orgs := make([]int, n)
+	for i := range orgs {
+		orgs[i] = (channel + i) % s.Orgs
+	}
+	return orgs
+}
+
+// runLock serializes the runs of scenarios
+var runLock sync.Mutex
+
+type runner struct {
+	Scenario
+	clock    *sim.ManualClock
+	net      *sim.Network
+	peers    []*peer
+	orderers map[string]*orderer
+	// events are the blocks and faults that are due, in the order of their time
+	events []event
+}
+
+// event is a block production or a fault, which happens at a given virtual time
+type event struct {
+	at    time.Time
+	apply func()
+}
+
+type eventsByTime []event
+
+func (e eventsByTime) Len() int           { return len(e) }
+func (e eventsByTime) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e eventsByTime) Less(i, j int) bool { return e[i].at.Before(e[j].at) }
+
+// startPeers creates all peers. Each peer bootstraps
+// from the first peer of its organization.
+func (r *runner) startPeers() error {
+	for org := 0; org < r.Orgs; org++ {
+		for id := 0; id < r.PeersPerOrg; id++ {
+			var bootstrapPeers []string
+			if id != 0 {
+				bootstrapPeers = []string{Endpoint(org, 0)}
+			} else if r.PeersPerOrg > 1 {
+				bootstrapPeers = []string{Endpoint(org, 1)}
+			}
+			p, err := newPeer(r.net, r.Gossip, org, id, bootstrapPeers)
+			if err != nil {
+				return err
+			}
+			r.peers = append(r.peers, p)
+		}
+	}
+	return nil
+}
+
+func (r *runner) joinChannels() {
+	for channel := 0; channel < r.Channels; channel++ {
+		name := channelName(channel)
+		orgs := r.channelOrgs(channel)
+		o := newOrderer()
+		r.orderers[name] = o
+		for _, p := range r.peers {
+			if containsOrg(orgs, p.org) {
+				p.joinChannel(name, orgs, o, r.OrdererFallback)
+			}
+		}
+	}
+}
+
+// membershipConverged returns whether every peer knows all peers of its organization
+// and of the organizations it shares a channel with
+func (r *runner) membershipConverged() bool {
+	for _, p := range r.peers {
+		known := make(map[string]struct{})
+		for _, member := range p.gossip.Peers() {
+			known[string(member.PKIid)] = struct{}{}
+		}
+		for _, other := range r.peers {
+			if other == p || !r.shareChannel(p.org, other.org) {
+				continue
+			}
+			if _, exists := known[other.endpoint]; !exists {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// leadersElected returns whether every organization has a leader in each of its channels
+func (r *runner) leadersElected() bool {
+	for channel := 0; channel < r.Channels; channel++ {
+		name := channelName(channel)
+		for _, org := range r.channelOrgs(channel) {
+			hasLeader := false
+			for _, p := range r.peers {
+				if p.org == org && p.isLeader(name) {
+					hasLeader = true
+					break
+				}
+			}
+			if !hasLeader {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// produceBlocks schedules the blocks of all channels and the faults of the scenario,
+// and produces the first blocks
+func (r *runner) produceBlocks() {
+	start := r.clock.Now()
+	for _, f := range r.Faults {
+		apply := f.Apply
+		r.events = append(r.events, event{at: start.Add(f.After), apply: func() {
+			apply(r.net)
+		}})
+	}
+	for _, o := range r.orderers {
+		for i := 0; i < r.Blocks; i++ {
+			r.events = append(r.events, event{at: start.Add(time.Duration(i) * r.BlockInterval), apply: o.produce})
+		}
+	}
+	// faults come first among the events of the same time
+	sort.Stable(eventsByTime(r.events))
+	r.applyDueEvents()
+}
+
+// applyDueEvents produces the blocks and applies the faults whose time has come
+func (r *runner) applyDueEvents() {
+	now := r.clock.Now()
+	for len(r.events) > 0 && !r.events[0].at.After(now) {
+		r.events[0].apply()
+		r.events = r.events[1:]
+	}
+}
+
+// step advances the clock by a time step, after the wall-clock time that takes at the
+// speedup of the scenario, and applies the events that became due
+func (r *runner) step() {
+	time.Sleep(time.Duration(float64(r.TimeStep) / r.Speedup))
+	r.clock.Advance(r.TimeStep)
+	r.applyDueEvents()
+}
+
+// blocksCommitted returns whether all peers committed all blocks of their channels
+func (r *runner) blocksCommitted() bool {
+	for _, p := range r.peers {
+		for _, ch := range p.channels {
+			height, _ := ch.ledger.LedgerHeight()
+			if height < uint64(r.Blocks)+1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (r *runner) blockLatencies() (time.Duration, time.Duration) {
+	var sum, max time.Duration
+	var count int64
+	for _, p := range r.peers {
+		for _, ch := range p.channels {
+			for seqNum := uint64(1); seqNum <= uint64(r.Blocks); seqNum++ {
+				committedAt, _ := ch.ledger.commitTime(seqNum)
+				latency := committedAt.Sub(ch.orderer.productionTime(seqNum))
+				sum += latency
+				count++
+				if latency > max {
+					max = latency
+				}
+			}
+		}
+	}
+	if count == 0 {
+		return 0, 0
+	}
+	return sum / time.Duration(count), max
+}
+
+// await advances the clock step by step until the given condition holds, and returns
+// an error if it didn't hold within the timeout of the scenario
+func (r *runner) await(phase string, condition func() bool) error {
+	deadline := r.clock.Now().Add(r.Timeout)
+	for !condition() {
+		if r.clock.Now().After(deadline) {
+			return fmt.Errorf("%s didn't converge within %v", phase, r.Timeout)
+		}
+		r.step()
+	}
+	logger.Info(phase, "converged")
+	return nil
+}
+
+func (r *runner) shareChannel(org1 int, org2 int) bool {
+	if org1 == org2 {
+		return true
+	}
+	for channel := 0; channel < r.Channels; channel++ {
+		orgs := r.channelOrgs(channel)
+		if containsOrg(orgs, org1) && containsOrg(orgs, org2) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *runner) stop() {
+	// the peers wait for timers of the clock while they stop
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go func() {
+		for {
+			select {
+			case <-stopped:
+				return
+			case <-time.After(time.Duration(float64(r.TimeStep) / r.Speedup)):
+				r.clock.Advance(r.TimeStep)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, p := range r.peers {
+		wg.Add(1)
+		go func(p *peer) {
+			defer wg.Done()
+			p.stop()
+		}(p)
+	}
+	wg.Wait()
+}
+
+func containsOrg(orgs []int, org int) bool {
+	for _, o := range orgs {
+		if o == org {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm/sim"
+	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/election"
+	"github.com/hyperledger/fabric/gossip/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	util.SetupTestLogging()
+	aliveTimeInterval := 200 * time.Millisecond
+	discovery.SetAliveTimeInterval(aliveTimeInterval)
+	discovery.SetAliveExpirationCheckInterval(aliveTimeInterval)
+	discovery.SetAliveExpirationTimeout(aliveTimeInterval * 10)
+	discovery.SetReconnectInterval(aliveTimeInterval)
+	election.SetStartupGracePeriod(time.Second)
+	election.SetMembershipSampleInterval(100 * time.Millisecond)
+	election.SetLeaderAliveThreshold(time.Second)
+	election.SetLeaderElectionDuration(500 * time.Millisecond)
+}
+
+func testScenario() Scenario {
+	conf := DefaultGossipConfig()
+	conf.PullInterval = time.Second
+	conf.PublishStateInfoInterval = 200 * time.Millisecond
+	conf.RequestStateInfoInterval = 200 * time.Millisecond
+	return Scenario{
+		Orgs:           3,
+		PeersPerOrg:    3,
+		Channels:       2,
+		OrgsPerChannel: 2,
+		Blocks:         10,
+		BlockInterval:  50 * time.Millisecond,
+		Network:        sim.LinkConditions{Latency: time.Millisecond, Jitter: time.Millisecond},
+		Gossip:         conf,
+		Timeout:        30 * time.Second,
+		TimeStep:       5 * time.Millisecond,
+		Speedup:        2,
+	}
+}
+
+func TestRun(t *testing.T) {
+	report, err := Run(testScenario())
+	assert.NoError(t, err)
+	assert.True(t, report.Membership > 0)
+	assert.True(t, report.Leadership > 0)
+	assert.True(t, report.Dissemination > 0)
+	assert.True(t, report.MeanBlockLatency > 0)
+	assert.True(t, report.MaxBlockLatency >= report.MeanBlockLatency)
+	assert.True(t, report.Messages.Delivered > 0)
+}
+
+func TestRunWithFaults(t *testing.T) {
+	s := testScenario()
+	s.Network.Loss = 0.05
+	s.OrdererFallback = true
+	s.BlockInterval = 150 * time.Millisecond
+	// Partition the first organization from the rest of the network
+	// and disconnect one of its peers while blocks are produced
+	s.Faults = []Fault{
+		{After: 0, Apply: func(net *sim.Network) {
+			net.Partition(
+				[]string{Endpoint(0, 0), Endpoint(0, 1), Endpoint(0, 2)},
+				[]string{Endpoint(1, 0), Endpoint(1, 1), Endpoint(1, 2), Endpoint(2, 0), Endpoint(2, 1), Endpoint(2, 2)})
+		}},
+		{After: 100 * time.Millisecond, Apply: func(net *sim.Network) {
+			net.Disconnect(Endpoint(0, 2))
+		}},
+		{After: time.Second, Apply: func(net *sim.Network) {
+			net.Heal()
+			net.Reconnect(Endpoint(0, 2))
+		}},
+	}
+	report, err := Run(s)
+	assert.NoError(t, err)
+	// the last blocks are produced after the network heals
+	assert.True(t, report.Dissemination >= time.Duration(s.Blocks-1)*s.BlockInterval)
+	assert.True(t, report.Messages.Dropped > 0)
+}
+
+func TestRunInvalidScenario(t *testing.T) {
+	_, err := Run(Scenario{Orgs: 1, PeersPerOrg: 1})
+	assert.Error(t, err)
+
+	s := testScenario()
+	s.OrgsPerChannel = s.Orgs + 1
+	_, err = Run(s)
+	assert.Error(t, err)
+
+	s = testScenario()
+	s.Faults = []Fault{{After: time.Second}}
+	_, err = Run(s)
+	assert.Error(t, err)
+}
+
+func TestChannelOrgs(t *testing.T) {
+	s := Scenario{Orgs: 3, OrgsPerChannel: 2}
+	assert.Equal(t, []int{0, 1}, s.channelOrgs(0))
+	assert.Equal(t, []int{2, 0}, s.channelOrgs(2))
+	s.OrgsPerChannel = 0
+	assert.Equal(t, []int{1, 2, 0}, s.channelOrgs(1))
+}
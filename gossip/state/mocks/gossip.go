@@ -21,6 +21,7 @@ import (
 	"github.com/hyperledger/fabric/gossip/comm"
 	"github.com/hyperledger/fabric/gossip/common"
 	"github.com/hyperledger/fabric/gossip/discovery"
+	"github.com/hyperledger/fabric/gossip/gossip"
 	proto "github.com/hyperledger/fabric/protos/gossip"
 	"github.com/stretchr/testify/mock"
 )
@@ -33,6 +34,22 @@ func (*GossipMock) SuspectPeers(s api.PeerSuspector) {
 	panic("implement me")
 }
 
+func (*GossipMock) Status() *gossip.Status {
+	panic("implement me")
+}
+
+func (*GossipMock) UpdateBootstrapPeers(add []string, remove []string) error {
+	panic("implement me")
+}
+
+func (*GossipMock) BootstrapPeers() []string {
+	panic("implement me")
+}
+
+func (*GossipMock) UpdateExternalEndpoint(endpoint string) error {
+	panic("implement me")
+}
+
 func (g *GossipMock) Send(msg *proto.GossipMessage, peers ...*comm.RemotePeer) {
 	g.Called(msg, peers)
 }
@@ -69,5 +86,8 @@ func (g *GossipMock) Accept(acceptor common.MessageAcceptor, passThrough bool) (
 func (g *GossipMock) JoinChan(joinMsg api.JoinChannelMessage, chainID common.ChainID) {
 }
 
+func (g *GossipMock) LeaveChan(chainID common.ChainID) {
+}
+
 func (*GossipMock) Stop() {
 }
@@ -44,6 +44,10 @@ type PayloadsBuffer interface {
 	// Get current buffer size
 	Size() int
 
+	// Returns the range of missing sequence numbers between the next expected
+	// sequence number and the lowest one stored in the buffer, if there is such a gap
+	Gap() (uint64, uint64, bool)
+
 	// Channel to indicate event when new payload pushed with sequence
 	// number equal to the next expected value.
 	Ready() chan struct{}
@@ -138,6 +142,25 @@ func (b *PayloadsBufferImpl) Size() int {
 	return len(b.buf)
 }
 
+// Gap returns the range [start...end] of sequence numbers of the payloads that are missing
+// for the buffered payloads to be popped, and false if no payload is missing
+func (b *PayloadsBufferImpl) Gap() (uint64, uint64, bool) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	next := b.Next()
+	if len(b.buf) == 0 || b.buf[next] != nil {
+		return 0, 0, false
+	}
+	lowest := uint64(0)
+	for seqNum := range b.buf {
+		if lowest == 0 || seqNum < lowest {
+			lowest = seqNum
+		}
+	}
+	return next, lowest - 1, true
+}
+
 // Close cleanups resources and channels in maintained
 func (b *PayloadsBufferImpl) Close() {
 	close(b.readyChan)
@@ -169,3 +169,26 @@ func TestPayloadsBufferImpl_ConcurrentPush(t *testing.T) {
 	// Buffer size has to be only one
 	assert.Equal(t, 1, buffer.Size())
 }
+
+func TestPayloadsBufferImpl_Gap(t *testing.T) {
+	buffer := NewPayloadsBuffer(5)
+	_, _, exists := buffer.Gap()
+	assert.False(t, exists, "An empty buffer has no gap")
+
+	for _, seqNum := range []uint64{12, 9, 15} {
+		payload, err := randomPayloadWithSeqNum(seqNum)
+		assert.NoError(t, err)
+		assert.NoError(t, buffer.Push(payload))
+	}
+	start, end, exists := buffer.Gap()
+	assert.True(t, exists)
+	assert.Equal(t, uint64(5), start)
+	assert.Equal(t, uint64(8), end)
+
+	// Once the next expected payload is in the buffer, nothing is missing
+	payload, err := randomPayloadWithSeqNum(5)
+	assert.NoError(t, err)
+	assert.NoError(t, buffer.Push(payload))
+	_, _, exists = buffer.Gap()
+	assert.False(t, exists)
+}
@@ -54,6 +54,24 @@ const (
 	defAntiEntropyInterval             = 10 * time.Second
 	defAntiEntropyStateResponseTimeout = 3 * time.Second
 	defAntiEntropyBatchSize            = 10
+	defAntiEntropyMaxBatchSize         = 100
+	defAntiEntropyBatchTargetTime      = time.Second
+	defAntiEntropyTickInterval         = 100 * time.Millisecond
+
+	// defGapCheckInterval is the interval at which the ledger height is compared
+	// with the blocks known to exist, in order to detect gaps
+	defGapCheckInterval = 200 * time.Millisecond
+	// defGapGracePeriod is the time a gap has to persist before it is pulled,
+	// as blocks disseminated via gossip arrive out of order
+	defGapGracePeriod = 500 * time.Millisecond
+
+	// defServedBlocksPerSecond is the default number of blocks per second
+	// served to each peer that requests blocks via state transfer
+	defServedBlocksPerSecond = 200
+
+	// throughputSmoothing is the weight of the most recent measurement
+	// in the estimated throughput of a peer
+	throughputSmoothing = 0.5
 
 	defChannelBufferSize     = 100
 	defAntiEntropyMaxRetries = 3
@@ -86,6 +104,15 @@ type GossipAdapter interface {
 	PeersOfChannel(common2.ChainID) []discovery.NetworkMember
 }
 
+// OrdererFetcher fetches blocks of the channel directly from the ordering service.
+// It is used to fill gaps that no peer of the channel can fill, e.g. when the peer
+// is partitioned from the peers that have the missing blocks
+type OrdererFetcher interface {
+	// FetchBlocks returns the blocks with sequence numbers in the range [start...end] in order,
+	// or only a prefix of them if the ordering service doesn't have all of them
+	FetchBlocks(start uint64, end uint64) ([]*common.Block, error)
+}
+
 // GossipStateProviderImpl the implementation of the GossipStateProvider interface
 // the struct to handle in memory sliding window of
 // new ledger block to be acquired by hyper ledger
@@ -120,6 +147,21 @@ type GossipStateProviderImpl struct {
 	once sync.Once
 
 	stateTransferActive int32
+
+	// throughput maps PKI-IDs of peers to the rate they serve blocks at.
+	// It is accessed only by the anti-entropy routine
+	throughput map[string]*peerThroughput
+
+	// limiter bounds the rate blocks are served to each remote peer
+	limiter *util.RateLimiter
+
+	// orderer fetches the blocks that no peer can provide, might be nil
+	orderer OrdererFetcher
+
+	// gapStart and gapDetectedAt track the gap that was detected last, and are
+	// accessed only by the anti-entropy routine
+	gapStart      uint64
+	gapDetectedAt time.Time
 }
 
 var logger *logging.Logger // package-level logger
@@ -128,8 +170,11 @@ func init() {
 	logger = util.GetLogger(util.LoggingStateModule, "")
 }
 
-// NewGossipStateProvider creates initialized instance of gossip state provider
-func NewGossipStateProvider(chainID string, g GossipAdapter, committer committer.Committer, mcs api.MessageCryptoService) GossipStateProvider {
+// NewGossipStateProvider creates initialized instance of gossip state provider.
+// Blocks that no peer of the channel has are fetched from the given orderer fetcher,
+// unless it is nil.
+func NewGossipStateProvider(chainID string, g GossipAdapter, committer committer.Committer, mcs api.MessageCryptoService,
+	orderer OrdererFetcher) GossipStateProvider {
 	logger := util.GetLogger(util.LoggingStateModule, "")
 
 	gossipChan, _ := g.Accept(func(message interface{}) bool {
@@ -205,6 +250,12 @@ func NewGossipStateProvider(chainID string, g GossipAdapter, committer committer
 		stateTransferActive: 0,
 
 		once: sync.Once{},
+
+		throughput: make(map[string]*peerThroughput),
+
+		limiter: util.NewRateLimiter(float64(servedBlocksPerSecond()), defAntiEntropyMaxBatchSize),
+
+		orderer: orderer,
 	}
 
 	nodeMetastate := NewNodeMetastate(height - 1)
@@ -226,7 +277,7 @@ func NewGossipStateProvider(chainID string, g GossipAdapter, committer committer
 	go s.listen()
 	// Deliver in order messages into the incoming channel
 	go s.deliverPayloads()
-	// Execute anti entropy to fill missing gaps
+	// Execute anti entropy and detect gaps in order to fill them
 	go s.antiEntropy()
 	// Taking care of state request messages
 	go s.processStateRequests()
@@ -301,22 +352,24 @@ func (s *GossipStateProviderImpl) processStateRequests() {
 }
 
 // Handle state request message, validate batch size, read current leader state to
-// obtain required blocks, build response message and send it back
+// obtain required blocks, build response message and send it back.
+// The number of blocks served to each peer is rate limited, therefore the
+// response may carry only a prefix of the requested blocks.
 func (s *GossipStateProviderImpl) handleStateRequest(msg proto.ReceivedMessage) {
 	if msg == nil {
 		return
 	}
 	request := msg.GetGossipMessage().GetStateRequest()
 
-	batchSize := request.EndSeqNum - request.StartSeqNum
-	if batchSize > defAntiEntropyBatchSize {
-		logger.Errorf("Requesting blocks batchSize size (%d) greater than configured allowed"+
-			" (%d) batching for anti-entropy. Ignoring request...", batchSize, defAntiEntropyBatchSize)
+	if request.StartSeqNum > request.EndSeqNum {
+		logger.Errorf("Invalid sequence interval [%d...%d], ignoring request...", request.StartSeqNum, request.EndSeqNum)
 		return
 	}
 
-	if request.StartSeqNum > request.EndSeqNum {
-		logger.Errorf("Invalid sequence interval [%d...%d], ignoring request...", request.StartSeqNum, request.EndSeqNum)
+	batchSize := request.EndSeqNum - request.StartSeqNum
+	if batchSize > defAntiEntropyMaxBatchSize {
+		logger.Errorf("Requesting blocks batchSize size (%d) greater than configured allowed"+
+			" (%d) batching for anti-entropy. Ignoring request...", batchSize, defAntiEntropyMaxBatchSize)
 		return
 	}
 
@@ -331,6 +384,18 @@ func (s *GossipStateProviderImpl) handleStateRequest(msg proto.ReceivedMessage)
 	}
 
 	endSeqNum := min(currentHeight, request.EndSeqNum)
+	if endSeqNum >= request.StartSeqNum {
+		var requester string
+		if connInfo := msg.GetConnectionInfo(); connInfo != nil {
+			requester = string(connInfo.ID)
+		}
+		allowed := s.limiter.Take(requester, endSeqNum-request.StartSeqNum+1)
+		if allowed == 0 {
+			logger.Warningf("Peer %s exceeded the rate of blocks served via state transfer, ignoring request...", requester)
+			return
+		}
+		endSeqNum = request.StartSeqNum + allowed - 1
+	}
 
 	response := &proto.RemoteStateResponse{Payloads: make([]*proto.Payload, 0)}
 	for seqNum := request.StartSeqNum; seqNum <= endSeqNum; seqNum++ {
@@ -461,30 +526,123 @@ func (s *GossipStateProviderImpl) antiEntropy() {
 	defer s.done.Done()
 	defer logger.Debug("State Provider stopped, stopping anti entropy procedure.")
 
+	antiEntropyTicker := util.NewTicker(defAntiEntropyInterval)
+	defer antiEntropyTicker.Stop()
+	gapTicker := util.NewTicker(defGapCheckInterval)
+	defer gapTicker.Stop()
+
 	for {
 		select {
 		case <-s.stopCh:
 			s.stopCh <- struct{}{}
 			return
-		case <-time.After(defAntiEntropyInterval):
-			current, err := s.committer.LedgerHeight()
-			if err != nil {
-				// Unable to read from ledger continue to the next round
-				logger.Error("Cannot obtain ledger height, due to", err)
+		case <-antiEntropyTicker.C():
+			if start, end, exists := s.findGap(); exists {
+				s.fillGap(start, end)
+			}
+		case <-gapTicker.C():
+			start, end, exists := s.findGap()
+			if !exists {
+				s.gapDetectedAt = time.Time{}
 				continue
 			}
-			if current == 0 {
-				logger.Error("Ledger reported block height of 0 but this should be impossible")
+			// A gap is pulled right away only if it persists, in order not to request
+			// blocks that are on their way via gossip
+			if s.gapDetectedAt.IsZero() || s.gapStart != start {
+				s.gapStart, s.gapDetectedAt = start, util.Now()
 				continue
 			}
-			max := s.maxAvailableLedgerHeight()
-
-			if current-1 >= max {
+			if util.Since(s.gapDetectedAt) < defGapGracePeriod {
 				continue
 			}
+			logger.Infof("Detected missing blocks [%d...%d] for chainID %s, pulling them", start, end, s.chainID)
+			s.fillGap(start, end)
+			s.gapDetectedAt = util.Now()
+		}
+	}
+}
+
+// findGap returns the range of blocks that are missing from the ledger while known to exist,
+// either since other peers advertise a higher ledger height or since blocks with higher
+// sequence numbers wait in the payloads buffer, and false if there is no such range
+func (s *GossipStateProviderImpl) findGap() (uint64, uint64, bool) {
+	current, err := s.committer.LedgerHeight()
+	if err != nil {
+		// Unable to read from ledger continue to the next round
+		logger.Error("Cannot obtain ledger height, due to", err)
+		return 0, 0, false
+	}
+	if current == 0 {
+		logger.Error("Ledger reported block height of 0 but this should be impossible")
+		return 0, 0, false
+	}
 
-			s.requestBlocksInRange(uint64(current), uint64(max))
+	end, exists := uint64(0), false
+	if height := s.maxAvailableLedgerHeight(); current-1 < height {
+		end, exists = height, true
+	}
+	if _, last, missing := s.payloads.Gap(); missing && last >= current && last > end {
+		end, exists = last, true
+	}
+	return current, end, exists
+}
+
+// fillGap acquires the blocks in the range [start...end] from other peers, and falls back
+// to the ordering service for the blocks that no peer could provide
+func (s *GossipStateProviderImpl) fillGap(start uint64, end uint64) {
+	err := s.requestBlocksInRange(start, end)
+	if err == nil {
+		return
+	}
+	logger.Warningf("Wasn't able to get blocks [%d...%d] from peers: %v", start, end, err)
+	if s.orderer == nil {
+		return
+	}
+	s.fetchFromOrderer(max(start, s.payloads.Next()), end)
+}
+
+// fetchFromOrderer fetches the blocks in the range [start...end] directly from the
+// ordering service in batches, and pushes them into the payloads buffer
+func (s *GossipStateProviderImpl) fetchFromOrderer(start uint64, end uint64) {
+	for start <= end {
+		select {
+		case <-s.stopCh:
+			s.stopCh <- struct{}{}
+			return
+		default:
+		}
+
+		batchEnd := min(end, start+defAntiEntropyMaxBatchSize-1)
+		logger.Debugf("Fetching blocks [%d...%d] of chainID %s from the ordering service", start, batchEnd, s.chainID)
+		blocks, err := s.orderer.FetchBlocks(start, batchEnd)
+		if err != nil {
+			logger.Warningf("Wasn't able to fetch blocks [%d...%d] from the ordering service: %v", start, batchEnd, err)
+			return
 		}
+		if len(blocks) == 0 {
+			return
+		}
+		for _, block := range blocks {
+			if block.Header == nil {
+				logger.Warning("Ordering service returned a block without a header")
+				return
+			}
+			blockBytes, err := pb.Marshal(block)
+			if err != nil {
+				logger.Errorf("Could not marshal block: %s", err)
+				return
+			}
+			seqNum := block.Header.Number
+			if err := s.mcs.VerifyBlock(common2.ChainID(s.chainID), seqNum, blockBytes); err != nil {
+				logger.Warningf("Error verifying block with sequence number %d, due to %s", seqNum, err)
+				return
+			}
+			if err := s.addPayload(&proto.Payload{SeqNum: seqNum, Data: blockBytes}, blocking); err != nil {
+				// The block has probably arrived via gossip in the meantime
+				logger.Debugf("Payload with sequence number %d wasn't added to payload buffer: %v", seqNum, err)
+			}
+		}
+		start = blocks[len(blocks)-1].Header.Number + 1
 	}
 }
 
@@ -502,60 +660,61 @@ func (s *GossipStateProviderImpl) maxAvailableLedgerHeight() uint64 {
 	return max
 }
 
-// GetBlocksInRange capable to acquire blocks with sequence
-// numbers in the range [start...end].
-func (s *GossipStateProviderImpl) requestBlocksInRange(start uint64, end uint64) {
+// requestBlocksInRange acquires blocks with sequence numbers in the range [start...end].
+// Disjoint ranges of blocks are requested from several peers in parallel, each one
+// sized according to the throughput of the peer it is requested from. Responses are
+// verified and pushed into the payloads buffer as they arrive, which in turn
+// commits the blocks in order.
+// An error is returned if some of the blocks couldn't be acquired from any peer.
+func (s *GossipStateProviderImpl) requestBlocksInRange(start uint64, end uint64) error {
 	atomic.StoreInt32(&s.stateTransferActive, 1)
 	defer atomic.StoreInt32(&s.stateTransferActive, 0)
 
-	for prev := start; prev <= end; {
-		next := min(end, prev+defAntiEntropyBatchSize)
+	s.pruneThroughput()
+	fetcher := newRangeFetcher(s, start, end)
+	ticker := util.NewTicker(defAntiEntropyTickInterval)
+	defer ticker.Stop()
 
-		gossipMsg := s.stateRequestMessage(prev, next)
-
-		responseReceived := false
-		tryCounts := 0
-
-		for !responseReceived {
-			if tryCounts > defAntiEntropyMaxRetries {
-				logger.Warningf("Wasn't  able to get blocks in range [%d...%d], after %d retries",
-					prev, next, tryCounts)
-				return
-			}
-			// Select peers to ask for blocks
-			peer, err := s.selectPeerToRequestFrom(next)
-			if err != nil {
-				logger.Warningf("Cannot send state request for blocks in range [%d...%d], due to",
-					prev, next, err)
-				return
-			}
+	for !fetcher.done() {
+		if r := fetcher.exhausted(); r != nil {
+			return fmt.Errorf("blocks in range %s weren't acquired after %d retries", r, r.retries)
+		}
+		if err := fetcher.dispatch(); err != nil {
+			return err
+		}
 
-			logger.Debugf("State transfer, with peer %s, requesting blocks in range [%d...%d], "+
-				"for chainID %s", peer.Endpoint, prev, next, s.chainID)
+		select {
+		case msg := <-s.stateResponseCh:
+			fetcher.handleResponse(msg)
+		case <-ticker.C():
+			fetcher.expire()
+		case <-s.stopCh:
+			s.stopCh <- struct{}{}
+			return nil
+		}
+	}
+	return nil
+}
 
-			s.gossip.Send(gossipMsg, peer)
-			tryCounts++
+// throughputOf returns the throughput measured for the given peer
+func (s *GossipStateProviderImpl) throughputOf(peer *comm.RemotePeer) *peerThroughput {
+	pt, exists := s.throughput[string(peer.PKIID)]
+	if !exists {
+		pt = newPeerThroughput()
+		s.throughput[string(peer.PKIID)] = pt
+	}
+	return pt
+}
 
-			// Wait until timeout or response arrival
-			select {
-			case msg := <-s.stateResponseCh:
-				if msg.GetGossipMessage().Nonce != gossipMsg.Nonce {
-					continue
-				}
-				// Got corresponding response for state request, can continue
-				index, err := s.handleStateResponse(msg)
-				if err != nil {
-					logger.Warningf("Wasn't able to process state response for "+
-						"blocks [%d...%d], due to %s", prev, next, err)
-					continue
-				}
-				prev = index + 1
-				responseReceived = true
-			case <-time.After(defAntiEntropyStateResponseTimeout):
-			case <-s.stopCh:
-				s.stopCh <- struct{}{}
-				return
-			}
+// pruneThroughput forgets the throughput of peers that are no longer in the channel
+func (s *GossipStateProviderImpl) pruneThroughput() {
+	alive := make(map[string]struct{})
+	for _, member := range s.gossip.PeersOfChannel(common2.ChainID(s.chainID)) {
+		alive[string(member.PKIid)] = struct{}{}
+	}
+	for pkiID := range s.throughput {
+		if _, exists := alive[pkiID]; !exists {
+			delete(s.throughput, pkiID)
 		}
 	}
 }
@@ -575,47 +734,6 @@ func (s *GossipStateProviderImpl) stateRequestMessage(beginSeq uint64, endSeq ui
 	}
 }
 
-// Select peer which has required blocks to ask missing blocks from
-func (s *GossipStateProviderImpl) selectPeerToRequestFrom(height uint64) (*comm.RemotePeer, error) {
-	// Filter peers which posses required range of missing blocks
-	peers := s.filterPeers(s.hasRequiredHeight(height))
-
-	n := len(peers)
-	if n == 0 {
-		return nil, errors.New("there are no peers to ask for missing blocks from")
-	}
-
-	// Select peers to ask for blocks
-	return peers[util.RandomInt(n)], nil
-}
-
-// filterPeers return list of peers which aligns the predicate provided
-func (s *GossipStateProviderImpl) filterPeers(predicate func(peer discovery.NetworkMember) bool) []*comm.RemotePeer {
-	var peers []*comm.RemotePeer
-
-	for _, member := range s.gossip.PeersOfChannel(common2.ChainID(s.chainID)) {
-		if predicate(member) {
-			peers = append(peers, &comm.RemotePeer{Endpoint: member.PreferredEndpoint(), PKIID: member.PKIid})
-		}
-	}
-
-	return peers
-}
-
-// hasRequiredHeight returns predicate which is capable to filter peers with ledger height above than indicated
-// by provided input parameter
-func (s *GossipStateProviderImpl) hasRequiredHeight(height uint64) func(peer discovery.NetworkMember) bool {
-	return func(peer discovery.NetworkMember) bool {
-		if nodeMetadata, err := FromBytes(peer.Metadata); err != nil {
-			logger.Errorf("Unable to de-serialize node meta state, error = %s", err)
-		} else if nodeMetadata.LedgerHeight >= height {
-			return true
-		}
-
-		return false
-	}
-}
-
 // GetBlock return ledger block given its sequence number as a parameter
 func (s *GossipStateProviderImpl) GetBlock(index uint64) *common.Block {
 	// Try to read missing block from the ledger, should return no nil with
@@ -655,7 +773,7 @@ func (s *GossipStateProviderImpl) addPayload(payload *proto.Payload, blockingMod
 	}
 
 	for blockingMode && s.payloads.Size() > defMaxBlockDistance*2 {
-		time.Sleep(enqueueRetryInterval)
+		util.Sleep(enqueueRetryInterval)
 	}
 
 	return s.payloads.Push(payload)
@@ -684,6 +802,19 @@ func (s *GossipStateProviderImpl) commitBlock(block *common.Block) error {
 	return nil
 }
 
+// servedBlocksPerSecond returns the number of blocks per second
+// served to each peer that requests blocks via state transfer
+func servedBlocksPerSecond() int {
+	return util.GetIntOrDefault("peer.gossip.state.servedBlocksPerSecond", defServedBlocksPerSecond)
+}
+
 func min(a uint64, b uint64) uint64 {
 	return b ^ ((a ^ b) & (-(uint64(a-b) >> 63)))
 }
+
+func max(a uint64, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
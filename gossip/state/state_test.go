@@ -252,7 +252,7 @@ func newPeerNodeWithGossip(config *gossip.Config, committer committer.Committer,
 	// Initialize pseudo peer simulator, which has only three
 	// basic parts
 
-	sp := NewGossipStateProvider(util.GetTestChainID(), g, committer, cs)
+	sp := NewGossipStateProvider(util.GetTestChainID(), g, committer, cs, nil)
 	if sp == nil {
 		return nil
 	}
@@ -277,6 +277,7 @@ func TestNilDirectMsg(t *testing.T) {
 	g := &mocks.GossipMock{}
 	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
 	g.On("Accept", mock.Anything, true).Return(nil, make(chan proto.ReceivedMessage))
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
 	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
 	defer p.shutdown()
 	p.s.handleStateRequest(nil)
@@ -294,6 +295,7 @@ func TestNilAddPayload(t *testing.T) {
 	g := &mocks.GossipMock{}
 	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
 	g.On("Accept", mock.Anything, true).Return(nil, make(chan proto.ReceivedMessage))
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
 	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
 	defer p.shutdown()
 	err := p.s.AddPayload(nil)
@@ -307,6 +309,7 @@ func TestAddPayloadLedgerUnavailable(t *testing.T) {
 	g := &mocks.GossipMock{}
 	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
 	g.On("Accept", mock.Anything, true).Return(nil, make(chan proto.ReceivedMessage))
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
 	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
 	defer p.shutdown()
 	// Simulate a problem in the ledger
@@ -398,6 +401,280 @@ func TestLargeBlockGap(t *testing.T) {
 	}
 }
 
+func TestParallelStateTransfer(t *testing.T) {
+	// Scenario: the peer knows of 3 peers that have a ledger height much higher
+	// than itself (300 blocks higher).
+	// The peer should request disjoint ranges of blocks from all of them in parallel,
+	// and commit the blocks in order.
+
+	mc := &mockCommitter{}
+	blocksPassedToLedger := make(chan uint64, 300)
+	mc.On("Commit", mock.Anything).Run(func(arg mock.Arguments) {
+		blocksPassedToLedger <- arg.Get(0).(*pcomm.Block).Header.Number
+	})
+	msgsFromPeer := make(chan proto.ReceivedMessage)
+	mc.On("LedgerHeight", mock.Anything).Return(uint64(1), nil)
+	g := &mocks.GossipMock{}
+	metaState := NewNodeMetastate(300)
+	md, _ := metaState.Bytes()
+	var membership []discovery.NetworkMember
+	for _, id := range []string{"a", "b", "c"} {
+		membership = append(membership, discovery.NetworkMember{
+			PKIid:    common.PKIidType(id),
+			Endpoint: id,
+			Metadata: md,
+		})
+	}
+	g.On("PeersOfChannel", mock.Anything).Return(membership)
+	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+	g.On("Accept", mock.Anything, true).Return(nil, msgsFromPeer)
+
+	var lock sync.Mutex
+	requestedFrom := make(map[string]struct{})
+	var requested []*proto.RemoteStateRequest
+	g.On("Send", mock.Anything, mock.Anything).Run(func(arguments mock.Arguments) {
+		msg := arguments.Get(0).(*proto.GossipMessage)
+		peer := arguments.Get(1).([]*comm.RemotePeer)[0]
+		req := msg.GetStateRequest()
+		lock.Lock()
+		requestedFrom[peer.Endpoint] = struct{}{}
+		requested = append(requested, req)
+		lock.Unlock()
+
+		res := &proto.GossipMessage{
+			Nonce:   msg.Nonce,
+			Channel: []byte(util.GetTestChainID()),
+			Content: &proto.GossipMessage_StateResponse{
+				StateResponse: &proto.RemoteStateResponse{},
+			},
+		}
+		for seq := req.StartSeqNum; seq <= req.EndSeqNum; seq++ {
+			rawblock := pcomm.NewBlock(seq, []byte{})
+			b, _ := pb.Marshal(rawblock)
+			res.GetStateResponse().Payloads = append(res.GetStateResponse().Payloads, &proto.Payload{
+				SeqNum: seq,
+				Data:   b,
+			})
+		}
+		sMsg, _ := res.NoopSign()
+		// Respond asynchronously, as remote peers would
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			msgsFromPeer <- &comm.ReceivedMessageImpl{
+				SignedGossipMessage: sMsg,
+			}
+		}()
+	})
+	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
+	defer p.shutdown()
+
+	for expectedSequence := 1; expectedSequence <= 300; expectedSequence++ {
+		select {
+		case blockSeq := <-blocksPassedToLedger:
+			assert.Equal(t, expectedSequence, int(blockSeq))
+		case <-time.After(defAntiEntropyInterval * 2):
+			t.Fatalf("Didn't receive block %d", expectedSequence)
+		}
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Len(t, requestedFrom, 3, "Blocks should have been requested from all peers")
+	// No block should have been requested more than once
+	for i, r1 := range requested {
+		for _, r2 := range requested[i+1:] {
+			overlap := r1.StartSeqNum <= r2.EndSeqNum && r2.StartSeqNum <= r1.EndSeqNum
+			assert.False(t, overlap, "Ranges [%d...%d] and [%d...%d] overlap",
+				r1.StartSeqNum, r1.EndSeqNum, r2.StartSeqNum, r2.EndSeqNum)
+		}
+	}
+}
+
+func TestGapPulledFromPeers(t *testing.T) {
+	// Scenario: a peer advertises a ledger height higher than the ledger height
+	// of the peer. The gap should be pulled right away, and not only in the
+	// next anti-entropy round.
+
+	mc := &mockCommitter{}
+	blocksPassedToLedger := make(chan uint64, 10)
+	mc.On("Commit", mock.Anything).Run(func(arg mock.Arguments) {
+		blocksPassedToLedger <- arg.Get(0).(*pcomm.Block).Header.Number
+	})
+	msgsFromPeer := make(chan proto.ReceivedMessage)
+	mc.On("LedgerHeight", mock.Anything).Return(uint64(1), nil)
+	g := &mocks.GossipMock{}
+	md, _ := NewNodeMetastate(5).Bytes()
+	membership := []discovery.NetworkMember{
+		{
+			PKIid:    common.PKIidType("a"),
+			Endpoint: "a",
+			Metadata: md,
+		}}
+	g.On("PeersOfChannel", mock.Anything).Return(membership)
+	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+	g.On("Accept", mock.Anything, true).Return(nil, msgsFromPeer)
+	g.On("Send", mock.Anything, mock.Anything).Run(func(arguments mock.Arguments) {
+		msg := arguments.Get(0).(*proto.GossipMessage)
+		req := msg.GetStateRequest()
+		res := &proto.GossipMessage{
+			Nonce:   msg.Nonce,
+			Channel: []byte(util.GetTestChainID()),
+			Content: &proto.GossipMessage_StateResponse{
+				StateResponse: &proto.RemoteStateResponse{},
+			},
+		}
+		for seq := req.StartSeqNum; seq <= req.EndSeqNum; seq++ {
+			b, _ := pb.Marshal(pcomm.NewBlock(seq, []byte{}))
+			res.GetStateResponse().Payloads = append(res.GetStateResponse().Payloads, &proto.Payload{
+				SeqNum: seq,
+				Data:   b,
+			})
+		}
+		sMsg, _ := res.NoopSign()
+		go func() {
+			msgsFromPeer <- &comm.ReceivedMessageImpl{
+				SignedGossipMessage: sMsg,
+			}
+		}()
+	})
+	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
+	defer p.shutdown()
+
+	for expectedSequence := 1; expectedSequence <= 5; expectedSequence++ {
+		select {
+		case blockSeq := <-blocksPassedToLedger:
+			assert.Equal(t, expectedSequence, int(blockSeq))
+		case <-time.After(defAntiEntropyInterval / 2):
+			t.Fatalf("Block %d wasn't pulled before the anti-entropy round", expectedSequence)
+		}
+	}
+}
+
+type ordererFetcherMock struct {
+	sync.Mutex
+	requested [][2]uint64
+}
+
+func (o *ordererFetcherMock) FetchBlocks(start uint64, end uint64) ([]*pcomm.Block, error) {
+	o.Lock()
+	o.requested = append(o.requested, [2]uint64{start, end})
+	o.Unlock()
+	var blocks []*pcomm.Block
+	for seq := start; seq <= end; seq++ {
+		blocks = append(blocks, pcomm.NewBlock(seq, []byte{}))
+	}
+	return blocks, nil
+}
+
+func TestGapFetchedFromOrderer(t *testing.T) {
+	// Scenario: blocks arrive via gossip with a gap before them, and no peer
+	// has the missing blocks. The missing blocks should be fetched from the
+	// ordering service right away, and only them.
+
+	mc := &mockCommitter{}
+	blocksPassedToLedger := make(chan uint64, 10)
+	mc.On("Commit", mock.Anything).Run(func(arg mock.Arguments) {
+		blocksPassedToLedger <- arg.Get(0).(*pcomm.Block).Header.Number
+	})
+	mc.On("LedgerHeight", mock.Anything).Return(uint64(1), nil)
+	g := &mocks.GossipMock{}
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
+	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+	g.On("Accept", mock.Anything, true).Return(nil, make(chan proto.ReceivedMessage))
+	orderer := &ordererFetcherMock{}
+	sp := NewGossipStateProvider(util.GetTestChainID(), g, mc, &cryptoServiceMock{acceptor: noopPeerIdentityAcceptor}, orderer)
+	defer sp.Stop()
+
+	for seq := uint64(3); seq <= 5; seq++ {
+		b, _ := pb.Marshal(pcomm.NewBlock(seq, []byte{}))
+		assert.NoError(t, sp.(*GossipStateProviderImpl).addPayload(&proto.Payload{SeqNum: seq, Data: b}, nonBlocking))
+	}
+
+	for expectedSequence := 1; expectedSequence <= 5; expectedSequence++ {
+		select {
+		case blockSeq := <-blocksPassedToLedger:
+			assert.Equal(t, expectedSequence, int(blockSeq))
+		case <-time.After(defAntiEntropyInterval / 2):
+			t.Fatalf("Block %d wasn't fetched before the anti-entropy round", expectedSequence)
+		}
+	}
+	orderer.Lock()
+	defer orderer.Unlock()
+	assert.Equal(t, [][2]uint64{{1, 2}}, orderer.requested)
+}
+
+type stateRequestMsg struct {
+	*proto.SignedGossipMessage
+	connInfo  *proto.ConnectionInfo
+	responses chan *proto.GossipMessage
+}
+
+func (m *stateRequestMsg) Respond(msg *proto.GossipMessage) {
+	m.responses <- msg
+}
+
+func (m *stateRequestMsg) GetGossipMessage() *proto.SignedGossipMessage {
+	return m.SignedGossipMessage
+}
+
+func (m *stateRequestMsg) GetSourceEnvelope() *proto.Envelope {
+	return m.Envelope
+}
+
+func (m *stateRequestMsg) GetConnectionInfo() *proto.ConnectionInfo {
+	return m.connInfo
+}
+
+func TestStateRequestRateLimit(t *testing.T) {
+	mc := &mockCommitter{}
+	mc.On("LedgerHeight", mock.Anything).Return(uint64(200), nil)
+	mc.On("GetBlocks", mock.Anything).Return([]*pcomm.Block{pcomm.NewBlock(1, []byte{})})
+	g := &mocks.GossipMock{}
+	g.On("Accept", mock.Anything, false).Return(make(<-chan *proto.GossipMessage), nil)
+	g.On("Accept", mock.Anything, true).Return(nil, make(chan proto.ReceivedMessage))
+	g.On("PeersOfChannel", mock.Anything).Return([]discovery.NetworkMember{})
+	p := newPeerNodeWithGossip(newGossipConfig(0), mc, noopPeerIdentityAcceptor, g)
+	defer p.shutdown()
+
+	// Tokens are refilled so slowly that they are never refilled during the test
+	p.s.limiter = gutil.NewRateLimiter(0.001, defAntiEntropyMaxBatchSize)
+
+	// request sends a state request from the given peer, and returns
+	// the sequence numbers of the blocks in the response, if any was sent
+	request := func(peer string, start uint64, end uint64) []uint64 {
+		sMsg, _ := p.s.stateRequestMessage(start, end).NoopSign()
+		msg := &stateRequestMsg{
+			SignedGossipMessage: sMsg,
+			connInfo:            &proto.ConnectionInfo{ID: common.PKIidType(peer)},
+			responses:           make(chan *proto.GossipMessage, 1),
+		}
+		p.s.handleStateRequest(msg)
+		select {
+		case res := <-msg.responses:
+			var seqs []uint64
+			for _, payload := range res.GetStateResponse().Payloads {
+				seqs = append(seqs, payload.SeqNum)
+			}
+			return seqs
+		default:
+			return nil
+		}
+	}
+
+	assert.Len(t, request("a", 1, 60), 60)
+	// Only the blocks the peer has tokens for are served
+	seqs := request("a", 61, 120)
+	assert.Len(t, seqs, 40)
+	assert.Equal(t, uint64(61), seqs[0])
+	assert.Equal(t, uint64(100), seqs[39])
+	// A peer with no tokens left isn't served at all
+	assert.Nil(t, request("a", 101, 120))
+	// Other peers are served regardless
+	assert.Len(t, request("b", 1, 30), 30)
+	// Requests exceeding the maximum batch size are ignored
+	assert.Nil(t, request("c", 1, 1+defAntiEntropyMaxBatchSize+1))
+}
+
 func TestOverPopulation(t *testing.T) {
 	// Scenario: Add to the state provider blocks
 	// with a gap in between, and ensure that the payload buffer
@@ -909,7 +1186,11 @@ func TestGossipStateProvider_TestStateMessages(t *testing.T) {
 
 	chainID := common.ChainID(util.GetTestChainID())
 
+	// State requests are only accepted from peers eligible for the channel
+	// of the request, hence the request has to be associated with the channel
 	peer.g.Send(&proto.GossipMessage{
+		Tag:     proto.GossipMessage_CHAN_OR_ORG,
+		Channel: []byte(chainID),
 		Content: &proto.GossipMessage_StateRequest{&proto.RemoteStateRequest{0, 1}},
 	}, &comm.RemotePeer{peer.g.PeersOfChannel(chainID)[0].Endpoint, peer.g.PeersOfChannel(chainID)[0].PKIid})
 	logger.Info("Waiting until peers exchange messages")
@@ -0,0 +1,350 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm"
+	common2 "github.com/hyperledger/fabric/gossip/common"
+	"github.com/hyperledger/fabric/gossip/util"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+)
+
+// blockRange is a range of blocks [start...end] that is requested
+// from a single remote peer
+type blockRange struct {
+	start   uint64
+	end     uint64
+	retries int
+}
+
+func (r blockRange) String() string {
+	return fmt.Sprintf("[%d...%d]", r.start, r.end)
+}
+
+// pendingRequest is a state request which was sent to a remote peer
+// and a response for it hasn't arrived yet
+type pendingRequest struct {
+	blockRange
+	peer   *comm.RemotePeer
+	sentAt time.Time
+}
+
+// peerThroughput tracks the rate at which a remote peer serves blocks,
+// and derives from it the size of the next batch to request from the peer
+type peerThroughput struct {
+	// blocksPerSec is an exponentially weighted moving average of the
+	// measured rate, zero if no response was measured yet
+	blocksPerSec float64
+	batchSize    uint64
+}
+
+func newPeerThroughput() *peerThroughput {
+	return &peerThroughput{batchSize: defAntiEntropyBatchSize}
+}
+
+// update takes into account a response carrying the given amount
+// of blocks, which arrived after the given time since the request was sent.
+// The next batch is sized such that the peer is expected to serve it within
+// defAntiEntropyBatchTargetTime, and is at most doubled each time.
+func (pt *peerThroughput) update(blocks uint64, elapsed time.Duration) {
+	if elapsed < time.Millisecond {
+		elapsed = time.Millisecond
+	}
+	rate := float64(blocks) / elapsed.Seconds()
+	if pt.blocksPerSec == 0 {
+		pt.blocksPerSec = rate
+	} else {
+		pt.blocksPerSec = throughputSmoothing*rate + (1-throughputSmoothing)*pt.blocksPerSec
+	}
+
+	batchSize := uint64(pt.blocksPerSec * defAntiEntropyBatchTargetTime.Seconds())
+	if batchSize > 2*pt.batchSize {
+		batchSize = 2 * pt.batchSize
+	}
+	pt.batchSize = clampBatchSize(batchSize)
+}
+
+// penalize halves the batch size and the estimated rate of a peer
+// that failed to serve a request
+func (pt *peerThroughput) penalize() {
+	pt.blocksPerSec /= 2
+	pt.batchSize = clampBatchSize(pt.batchSize / 2)
+}
+
+func clampBatchSize(batchSize uint64) uint64 {
+	if batchSize < 1 {
+		return 1
+	}
+	if batchSize > defAntiEntropyMaxBatchSize {
+		return defAntiEntropyMaxBatchSize
+	}
+	return batchSize
+}
+
+// rangeFetcher splits the blocks of a single anti-entropy round into disjoint ranges,
+// and keeps track of the ranges requested from remote peers. At most one request
+// is in flight towards each peer, and the blocks requested never exceed
+// defMaxBlockDistance blocks beyond the next block expected by the payloads buffer,
+// so that the buffer keeps committing blocks in order without growing unboundedly.
+type rangeFetcher struct {
+	s *GossipStateProviderImpl
+	// queue holds ranges that have to be requested again, ordered by their start
+	queue []blockRange
+	// next is the first block that isn't a part of any range yet
+	next uint64
+	end  uint64
+	// pending maps nonces of the requests sent to the requests themselves
+	pending map[uint64]*pendingRequest
+	// busy holds the PKI-IDs of the peers that a request is pending for
+	busy map[string]struct{}
+}
+
+func newRangeFetcher(s *GossipStateProviderImpl, start uint64, end uint64) *rangeFetcher {
+	return &rangeFetcher{
+		s:       s,
+		next:    start,
+		end:     end,
+		pending: make(map[uint64]*pendingRequest),
+		busy:    make(map[string]struct{}),
+	}
+}
+
+// done returns whether all blocks of the round were either received
+// or have arrived by other means
+func (f *rangeFetcher) done() bool {
+	f.prune()
+	return len(f.queue) == 0 && f.next > f.end && len(f.pending) == 0
+}
+
+// prune drops the blocks below the next block expected by the payloads buffer
+// from the ranges that are still to be requested
+func (f *rangeFetcher) prune() {
+	next := f.s.payloads.Next()
+	if f.next < next {
+		f.next = next
+	}
+	queue := f.queue[:0]
+	for _, r := range f.queue {
+		if r.end < next {
+			continue
+		}
+		if r.start < next {
+			r.start = next
+		}
+		queue = append(queue, r)
+	}
+	f.queue = queue
+}
+
+// dispatch sends requests for the ranges within the window to idle peers
+// that have the blocks of the ranges. It returns an error if there are
+// no peers to request the blocks from, and no request is pending.
+func (f *rangeFetcher) dispatch() error {
+	window := f.s.payloads.Next() + defMaxBlockDistance
+	for {
+		r, requeued := f.nextRange()
+		if r == nil || r.start >= window {
+			return nil
+		}
+		// A range that was requested already must be requested in full,
+		// whereas a new range is fitted to the height of the selected peer
+		required := r.start
+		if requeued {
+			required = r.end
+		}
+		peer, height := f.selectPeer(required)
+		if peer == nil {
+			if len(f.pending) == 0 {
+				return fmt.Errorf("there are no peers to ask for blocks %s from", r)
+			}
+			return nil
+		}
+		if !requeued {
+			batchSize := f.s.throughputOf(peer).batchSize
+			r.end = min(r.end, min(r.start+batchSize-1, min(height, window-1)))
+			f.next = r.end + 1
+		} else {
+			f.queue = f.queue[1:]
+		}
+		f.send(*r, peer)
+	}
+}
+
+// nextRange returns the lowest range that is to be requested next, and whether it
+// was requested before. A new range spans until the last block of the round.
+func (f *rangeFetcher) nextRange() (*blockRange, bool) {
+	if len(f.queue) > 0 {
+		r := f.queue[0]
+		return &r, true
+	}
+	if f.next > f.end {
+		return nil, false
+	}
+	return &blockRange{start: f.next, end: f.end}, false
+}
+
+func (f *rangeFetcher) send(r blockRange, peer *comm.RemotePeer) {
+	gossipMsg := f.s.stateRequestMessage(r.start, r.end)
+	logger.Debugf("State transfer, with peer %s, requesting blocks in range %s, "+
+		"for chainID %s", peer.Endpoint, r, f.s.chainID)
+
+	f.pending[gossipMsg.Nonce] = &pendingRequest{blockRange: r, peer: peer, sentAt: util.Now()}
+	f.busy[string(peer.PKIID)] = struct{}{}
+	f.s.gossip.Send(gossipMsg, peer)
+}
+
+// selectPeer selects an idle peer that has the block with the given sequence number,
+// and returns it along with its ledger height. Peers that their throughput
+// wasn't measured yet are preferred, and then peers with the highest throughput.
+func (f *rangeFetcher) selectPeer(seqNum uint64) (*comm.RemotePeer, uint64) {
+	var candidates []*comm.RemotePeer
+	var heights []uint64
+	for _, member := range f.s.gossip.PeersOfChannel(common2.ChainID(f.s.chainID)) {
+		if _, isBusy := f.busy[string(member.PKIid)]; isBusy {
+			continue
+		}
+		nodeMetastate, err := FromBytes(member.Metadata)
+		if err != nil {
+			logger.Errorf("Unable to de-serialize node meta state, error = %s", err)
+			continue
+		}
+		if nodeMetastate.LedgerHeight < seqNum {
+			continue
+		}
+		candidates = append(candidates, &comm.RemotePeer{Endpoint: member.PreferredEndpoint(), PKIID: member.PKIid})
+		heights = append(heights, nodeMetastate.LedgerHeight)
+	}
+
+	n := len(candidates)
+	if n == 0 {
+		return nil, 0
+	}
+	// Start from a random candidate in order to break ties randomly
+	offset := util.RandomInt(n)
+	best, bestScore := -1, float64(0)
+	for i := 0; i < n; i++ {
+		j := (offset + i) % n
+		score := f.s.throughputOf(candidates[j]).blocksPerSec
+		if score == 0 {
+			score = math.Inf(1)
+		}
+		if best == -1 || score > bestScore {
+			best, bestScore = j, score
+		}
+	}
+	return candidates[best], heights[best]
+}
+
+// handleResponse processes a state response, and re-queues the blocks of
+// the corresponding request that the response didn't carry
+func (f *rangeFetcher) handleResponse(msg proto.ReceivedMessage) {
+	nonce := msg.GetGossipMessage().Nonce
+	req, exists := f.pending[nonce]
+	if !exists {
+		logger.Debug("Received state response with nonce", nonce, "that doesn't match any pending request")
+		return
+	}
+	f.complete(nonce)
+	throughput := f.s.throughputOf(req.peer)
+
+	if err := validateStateResponse(msg, req.blockRange); err != nil {
+		logger.Warningf("Peer %s sent an invalid state response for blocks %s: %v", req.peer.Endpoint, req.blockRange, err)
+		throughput.penalize()
+		f.retry(req.blockRange)
+		return
+	}
+
+	max, err := f.s.handleStateResponse(msg)
+	if err != nil {
+		logger.Warningf("Wasn't able to process state response for blocks %s, due to %s", req.blockRange, err)
+		throughput.penalize()
+		f.retry(req.blockRange)
+		return
+	}
+	throughput.update(uint64(len(msg.GetGossipMessage().GetStateResponse().Payloads)), util.Since(req.sentAt))
+
+	if max < req.end {
+		// The peer served only a part of the range, probably due to rate limiting
+		f.requeue(blockRange{start: max + 1, end: req.end, retries: req.retries})
+	}
+}
+
+// expire re-queues the ranges of the requests that weren't responded in time
+func (f *rangeFetcher) expire() {
+	for nonce, req := range f.pending {
+		if util.Since(req.sentAt) < defAntiEntropyStateResponseTimeout {
+			continue
+		}
+		logger.Warningf("Peer %s didn't respond in time to state request for blocks %s", req.peer.Endpoint, req.blockRange)
+		f.complete(nonce)
+		f.s.throughputOf(req.peer).penalize()
+		f.retry(req.blockRange)
+	}
+}
+
+// exhausted returns the range that was retried too many times, if any
+func (f *rangeFetcher) exhausted() *blockRange {
+	for _, r := range f.queue {
+		if r.retries > defAntiEntropyMaxRetries {
+			return &r
+		}
+	}
+	return nil
+}
+
+func (f *rangeFetcher) complete(nonce uint64) {
+	delete(f.busy, string(f.pending[nonce].peer.PKIID))
+	delete(f.pending, nonce)
+}
+
+func (f *rangeFetcher) retry(r blockRange) {
+	r.retries++
+	f.requeue(r)
+}
+
+func (f *rangeFetcher) requeue(r blockRange) {
+	i := 0
+	for i < len(f.queue) && f.queue[i].start < r.start {
+		i++
+	}
+	f.queue = append(f.queue, blockRange{})
+	copy(f.queue[i+1:], f.queue[i:])
+	f.queue[i] = r
+}
+
+// validateStateResponse checks that the payloads of a state response
+// are within the range of blocks that was requested
+func validateStateResponse(msg proto.ReceivedMessage, r blockRange) error {
+	payloads := msg.GetGossipMessage().GetStateResponse().GetPayloads()
+	if len(payloads) == 0 {
+		return errors.New("response has no payloads")
+	}
+	for _, payload := range payloads {
+		if payload == nil {
+			return errors.New("response contains a nil payload")
+		}
+		if payload.SeqNum < r.start || payload.SeqNum > r.end {
+			return fmt.Errorf("block %d isn't within the requested range", payload.SeqNum)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/gossip/comm"
+	proto "github.com/hyperledger/fabric/protos/gossip"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerThroughput(t *testing.T) {
+	pt := newPeerThroughput()
+	assert.Equal(t, uint64(defAntiEntropyBatchSize), pt.batchSize)
+
+	// A fast peer gets its batch doubled at most each time
+	pt.update(10, 10*time.Millisecond)
+	assert.Equal(t, float64(1000), pt.blocksPerSec)
+	assert.Equal(t, uint64(2*defAntiEntropyBatchSize), pt.batchSize)
+	for i := 0; i < 10; i++ {
+		pt.update(pt.batchSize, 10*time.Millisecond)
+	}
+	assert.Equal(t, uint64(defAntiEntropyMaxBatchSize), pt.batchSize)
+
+	// A slow peer gets a batch it is expected to serve within the target time
+	pt = newPeerThroughput()
+	pt.update(10, 2*time.Second)
+	assert.Equal(t, uint64(5), pt.batchSize)
+	pt.update(5, 2*time.Second)
+	assert.Equal(t, float64(3.75), pt.blocksPerSec)
+	assert.Equal(t, uint64(3), pt.batchSize)
+
+	// A failing peer gets its batch halved, but never below a single block
+	pt.penalize()
+	assert.Equal(t, uint64(1), pt.batchSize)
+	pt.penalize()
+	assert.Equal(t, uint64(1), pt.batchSize)
+}
+
+func TestRangeFetcherRequeue(t *testing.T) {
+	f := newRangeFetcher(nil, 1, 100)
+	f.requeue(blockRange{start: 50, end: 60})
+	f.requeue(blockRange{start: 10, end: 20})
+	f.retry(blockRange{start: 30, end: 40, retries: defAntiEntropyMaxRetries})
+	assert.Equal(t, []blockRange{
+		{start: 10, end: 20},
+		{start: 30, end: 40, retries: defAntiEntropyMaxRetries + 1},
+		{start: 50, end: 60},
+	}, f.queue)
+
+	r, requeued := f.nextRange()
+	assert.True(t, requeued)
+	assert.Equal(t, blockRange{start: 10, end: 20}, *r)
+	assert.Equal(t, &blockRange{start: 30, end: 40, retries: defAntiEntropyMaxRetries + 1}, f.exhausted())
+}
+
+func TestRangeFetcherExpire(t *testing.T) {
+	f := newRangeFetcher(nil, 1, 100)
+	f.s = &GossipStateProviderImpl{throughput: make(map[string]*peerThroughput)}
+	peer := &comm.RemotePeer{Endpoint: "a", PKIID: []byte("a")}
+	f.pending[1] = &pendingRequest{blockRange: blockRange{start: 1, end: 10}, peer: peer, sentAt: time.Now()}
+	f.pending[2] = &pendingRequest{blockRange: blockRange{start: 11, end: 20}, peer: peer,
+		sentAt: time.Now().Add(-defAntiEntropyStateResponseTimeout)}
+	f.busy["a"] = struct{}{}
+
+	f.expire()
+	assert.Len(t, f.pending, 1)
+	assert.Contains(t, f.pending, uint64(1))
+	assert.Equal(t, []blockRange{{start: 11, end: 20, retries: 1}}, f.queue)
+	assert.Equal(t, uint64(defAntiEntropyBatchSize/2), f.s.throughputOf(peer).batchSize)
+}
+
+func TestValidateStateResponse(t *testing.T) {
+	response := func(seqs ...uint64) proto.ReceivedMessage {
+		res := &proto.RemoteStateResponse{}
+		for _, seq := range seqs {
+			res.Payloads = append(res.Payloads, &proto.Payload{SeqNum: seq})
+		}
+		sMsg, _ := (&proto.GossipMessage{
+			Content: &proto.GossipMessage_StateResponse{StateResponse: res},
+		}).NoopSign()
+		return &comm.ReceivedMessageImpl{SignedGossipMessage: sMsg}
+	}
+
+	r := blockRange{start: 10, end: 20}
+	assert.NoError(t, validateStateResponse(response(10, 11, 12), r))
+	assert.NoError(t, validateStateResponse(response(20), r))
+	assert.Error(t, validateStateResponse(response(), r))
+	assert.Error(t, validateStateResponse(response(9, 10), r))
+	assert.Error(t, validateStateResponse(response(20, 21), r))
+}
@@ -0,0 +1,142 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the source of time of the timers of the gossip components,
+// such as the alive and expiration checks of discovery, the leader election,
+// the pull rounds, the message stores and the anti-entropy of state transfer.
+// It is the wall clock unless replaced by SetClock, which simulations do
+// in order to run the gossip components in virtual time.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After returns a channel the current time is sent on once the given duration elapses
+	After(d time.Duration) <-chan time.Time
+	// AfterFunc calls f in its own goroutine once the given duration elapses
+	AfterFunc(d time.Duration, f func()) Timer
+	// NewTicker returns a Ticker that ticks every given duration
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is a call scheduled by Clock.AfterFunc
+type Timer interface {
+	// Stop prevents the call from happening, and returns
+	// false if it already happened or was stopped
+	Stop() bool
+}
+
+// Ticker sends the current time periodically
+type Ticker interface {
+	// C returns the channel the ticks are sent on
+	C() <-chan time.Time
+	// Stop stops the ticks
+	Stop()
+}
+
+type wallClock struct{}
+
+type wallTicker struct {
+	*time.Ticker
+}
+
+func (t wallTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+func (wallClock) Now() time.Time {
+	return time.Now()
+}
+
+func (wallClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (wallClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+func (wallClock) NewTicker(d time.Duration) Ticker {
+	return wallTicker{time.NewTicker(d)}
+}
+
+// clockHolder wraps the clock so that clocks of different types can be stored in an atomic.Value
+type clockHolder struct {
+	Clock
+}
+
+var clock atomic.Value
+
+func init() {
+	clock.Store(clockHolder{WallClock()})
+}
+
+// WallClock returns the Clock of the system
+func WallClock() Clock {
+	return wallClock{}
+}
+
+// SetClock replaces the clock of the gossip components, or restores
+// the wall clock if c is nil. It should be called before the components
+// are created, as the timers they started keep using the former clock
+func SetClock(c Clock) {
+	if c == nil {
+		c = WallClock()
+	}
+	clock.Store(clockHolder{c})
+}
+
+// GetClock returns the clock of the gossip components
+func GetClock() Clock {
+	return clock.Load().(clockHolder).Clock
+}
+
+// Now returns the current time of the clock of the gossip components
+func Now() time.Time {
+	return GetClock().Now()
+}
+
+// Since returns the time elapsed since t according to the clock of the gossip components
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+// Sleep pauses the current goroutine for the given duration of the clock of the gossip components
+func Sleep(d time.Duration) {
+	<-GetClock().After(d)
+}
+
+// After waits for the given duration of the clock of the gossip
+// components to elapse, and then sends the current time on the returned channel
+func After(d time.Duration) <-chan time.Time {
+	return GetClock().After(d)
+}
+
+// AfterFunc calls f in its own goroutine once the given duration
+// of the clock of the gossip components elapses
+func AfterFunc(d time.Duration, f func()) Timer {
+	return GetClock().AfterFunc(d, f)
+}
+
+// NewTicker returns a Ticker that ticks every given duration of the clock of the gossip components
+func NewTicker(d time.Duration) Ticker {
+	return GetClock().NewTicker(d)
+}
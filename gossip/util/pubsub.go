@@ -57,7 +57,7 @@ type subscription struct {
 // subscription's TTL passed
 func (s *subscription) Listen() (interface{}, error) {
 	select {
-	case <-time.After(s.ttl):
+	case <-After(s.ttl):
 		return nil, errors.New("timed out")
 	case item := <-s.c:
 		return item, nil
@@ -113,7 +113,7 @@ func (ps *PubSub) Subscribe(topic string, ttl time.Duration) Subscription {
 	s.Add(sub)
 
 	// When the timeout expires, remove the subscription
-	time.AfterFunc(ttl, func() {
+	AfterFunc(ttl, func() {
 		ps.unSubscribe(sub)
 	})
 	return sub
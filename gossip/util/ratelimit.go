@@ -0,0 +1,97 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter bounds the rate at which each key (e.g a remote peer)
+// consumes a resource, by keeping a token bucket per key. Tokens are
+// refilled at a constant rate up to the size of the burst.
+type RateLimiter struct {
+	sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewRateLimiter creates a RateLimiter that refills rate tokens per second
+// for each key, and allows each key to accumulate up to burst tokens
+func NewRateLimiter(rate float64, burst float64) *RateLimiter {
+	return &RateLimiter{
+		rate:    rate,
+		burst:   burst,
+		buckets: make(map[string]*tokenBucket),
+		now:     Now,
+	}
+}
+
+// Take consumes up to n tokens from the bucket of the given key,
+// and returns the number of tokens actually consumed
+func (rl *RateLimiter) Take(key string, n uint64) uint64 {
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.purge(now)
+
+	bucket, exists := rl.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: rl.burst, lastRefill: now}
+		rl.buckets[key] = bucket
+	}
+	rl.refill(bucket, now)
+
+	taken := uint64(bucket.tokens)
+	if taken > n {
+		taken = n
+	}
+	bucket.tokens -= float64(taken)
+	return taken
+}
+
+func (rl *RateLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	bucket.tokens += elapsed * rl.rate
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.lastRefill = now
+}
+
+// purge removes the buckets that have been refilled completely by now,
+// since they are equivalent to buckets of keys never seen before.
+// This keeps the limiter from accumulating buckets of keys no longer in use.
+func (rl *RateLimiter) purge(now time.Time) {
+	fillTime := time.Duration(rl.burst / rl.rate * float64(time.Second))
+	for key, bucket := range rl.buckets {
+		if now.Sub(bucket.lastRefill) >= fillTime {
+			delete(rl.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	rl := NewRateLimiter(10, 20)
+	rl.now = func() time.Time {
+		return now
+	}
+
+	// A peer can consume its whole burst at once
+	assert.Equal(t, uint64(15), rl.Take("a", 15))
+	assert.Equal(t, uint64(5), rl.Take("a", 15))
+	assert.Equal(t, uint64(0), rl.Take("a", 1))
+	// Each key has a bucket of its own
+	assert.Equal(t, uint64(20), rl.Take("b", 30))
+
+	// Tokens are refilled according to the rate
+	now = now.Add(500 * time.Millisecond)
+	assert.Equal(t, uint64(5), rl.Take("a", 10))
+	assert.Equal(t, uint64(0), rl.Take("a", 10))
+
+	// Tokens don't accumulate beyond the burst
+	now = now.Add(time.Minute)
+	assert.Equal(t, uint64(20), rl.Take("a", 30))
+}
+
+func TestRateLimiterPurge(t *testing.T) {
+	now := time.Now()
+	rl := NewRateLimiter(10, 20)
+	rl.now = func() time.Time {
+		return now
+	}
+
+	rl.Take("a", 20)
+	rl.Take("b", 20)
+	assert.Len(t, rl.buckets, 2)
+
+	// The bucket of "b" is refilled completely by the time "a" takes tokens again,
+	// so it is forgotten
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, uint64(20), rl.Take("a", 20))
+	assert.Len(t, rl.buckets, 1)
+	assert.Contains(t, rl.buckets, "a")
+}
@@ -0,0 +1,1242 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bft
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/common/config"
+	"github.com/hyperledger/fabric/common/configtx"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
+	"github.com/hyperledger/fabric/orderer/multichain"
+	cb "github.com/hyperledger/fabric/protos/common"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/bft")
+
+const (
+	// msgChanSize is the number of received messages buffered for a chain before further messages are dropped
+	msgChanSize = 1000
+
+	// backlogSize is the number of messages kept per consenter for views and sequence numbers ahead of the chain
+	backlogSize = 100
+
+	// syncBatchSize is the maximum number of blocks sent in a sync response
+	syncBatchSize = 10
+)
+
+type consenter struct {
+	conf      localconfig.BFT
+	transport Transport
+}
+
+// New creates a new consenter for the BFT consensus scheme.
+// The BFT consensus scheme orders messages among the consenters listed in the channel config,
+// and tolerates f arbitrarily faulty consenters out of 3f+1. The consenters agree on every block
+// in the three phases of PBFT, and every block carries the signatures of a quorum of consenters,
+// which the peers check against the BlockValidation policy of the channel.
+// The consenters agree on one block at a time, and the set of consenters is fixed for the lifetime
+// of a channel, config transactions which change it are rejected.
+func New(conf localconfig.BFT, transport Transport) multichain.Consenter {
+	return &consenter{
+		conf:      conf,
+		transport: transport,
+	}
+}
+
+func (bft *consenter) HandleChain(support multichain.ConsenterSupport, metadata *cb.Metadata) (multichain.Chain, error) {
+	return newChain(bft.conf, bft.transport, support)
+}
+
+type message struct {
+	env *ab.BFTEnvelope
+	msg *ab.BFTMessage
+}
+
+type request struct {
+	env     *cb.Envelope
+	ordered bool // whether the request was passed to the blockcutter, as the primary
+}
+
+// slot holds the messages for the sequence number and view being agreed upon
+type slot struct {
+	prePrepare *message
+	block      *cb.Block
+	committers []filter.Committer
+	prepares   map[uint64]*message
+	commits    map[uint64]*ab.BFTCommit
+	sentCommit bool
+}
+
+type chain struct {
+	conf      localconfig.BFT
+	transport Transport
+	support   multichain.ConsenterSupport
+
+	consenters []*ab.BFTConsenter
+	identities []msp.Identity
+	replicas   map[string]uint64 // the replica IDs by serialized identity
+	id         uint64
+	f          int
+	quorum     int
+
+	sendChan chan *cb.Envelope
+	msgChan  chan *ab.BFTEnvelope
+	exitChan chan struct{}
+
+	height         uint64
+	lastHeader     *cb.BlockHeader
+	lastSignatures *cb.Metadata
+
+	view         uint64
+	inViewChange bool
+	target       uint64 // the view the chain is changing to
+	viewChanges  map[uint64]map[uint64]*message
+	newViewSent  uint64
+	changeStart  time.Time
+
+	slot     *slot
+	prepared *ab.BFTPrepared // the proof that a quorum accepted the proposal for the current height, if any
+
+	pending      map[string]*request
+	pendingOrder []string
+	timer        time.Time // when the outstanding requests started waiting, zero if none is
+	batches      [][]*cb.Envelope
+	batchTimer   <-chan time.Time
+
+	views       map[uint64]uint64 // the highest view each replica was seen working in
+	heights     map[uint64]uint64 // the highest height each replica was seen working at
+	behindSince time.Time
+	lastSync    time.Time
+
+	loopback []*message
+	backlog  map[uint64][]*message
+}
+
+func newChain(conf localconfig.BFT, transport Transport, support multichain.ConsenterSupport) (*chain, error) {
+	consenters := support.SharedConfig().BFTConsenters()
+	if len(consenters) == 0 {
+		return nil, fmt.Errorf("No BFT consenters are defined for chain %s", support.ChainID())
+	}
+
+	shdr, err := support.NewSignatureHeader()
+	if err != nil {
+		return nil, fmt.Errorf("Failed creating a signature header: %s", err)
+	}
+
+	c := &chain{
+		conf:        conf,
+		transport:   transport,
+		support:     support,
+		consenters:  consenters,
+		identities:  make([]msp.Identity, len(consenters)),
+		replicas:    make(map[string]uint64),
+		id:          uint64(len(consenters)),
+		f:           (len(consenters) - 1) / 3,
+		quorum:      config.BFTQuorum(len(consenters)),
+		sendChan:    make(chan *cb.Envelope),
+		msgChan:     make(chan *ab.BFTEnvelope, msgChanSize),
+		exitChan:    make(chan struct{}),
+		viewChanges: make(map[uint64]map[uint64]*message),
+		pending:     make(map[string]*request),
+		views:       make(map[uint64]uint64),
+		heights:     make(map[uint64]uint64),
+		backlog:     make(map[uint64][]*message),
+	}
+
+	for i, consenter := range consenters {
+		c.identities[i], err = support.MSPManager().DeserializeIdentity(consenter.Identity)
+		if err != nil {
+			return nil, fmt.Errorf("Failed deserializing the identity of BFT consenter %s:%d: %s", consenter.Host, consenter.Port, err)
+		}
+		c.replicas[string(consenter.Identity)] = uint64(i)
+		if bytes.Equal(consenter.Identity, shdr.Creator) {
+			c.id = uint64(i)
+		}
+	}
+	if c.id == uint64(len(consenters)) {
+		return nil, fmt.Errorf("This orderer is not one of the BFT consenters of chain %s", support.ChainID())
+	}
+
+	c.height = support.Height()
+	if c.height > 0 {
+		last := ledger.GetBlock(support.Reader(), c.height-1)
+		if last == nil {
+			return nil, fmt.Errorf("Failed reading the last block of chain %s", support.ChainID())
+		}
+		c.lastHeader = last.Header
+		c.lastSignatures, _ = utils.GetMetadataFromBlock(last, cb.BlockMetadataIndex_SIGNATURES)
+	}
+	c.resetSlot()
+
+	return c, nil
+}
+
+func (c *chain) Start() {
+	c.transport.Register(c.support.ChainID(), c)
+	go c.main()
+}
+
+func (c *chain) Halt() {
+	select {
+	case <-c.exitChan:
+		// Allow multiple halts without panic
+	default:
+		c.transport.Deregister(c.support.ChainID())
+		close(c.exitChan)
+	}
+}
+
+// Enqueue accepts a message and returns true on acceptance, or false on shutdown
+func (c *chain) Enqueue(env *cb.Envelope) bool {
+	select {
+	case c.sendChan <- env:
+		return true
+	case <-c.exitChan:
+		return false
+	}
+}
+
+// Errored only closes on exit
+func (c *chain) Errored() <-chan struct{} {
+	return c.exitChan
+}
+
+// Step accepts a message from another consenter, the message is dropped if the chain is busy
+func (c *chain) Step(env *ab.BFTEnvelope) {
+	select {
+	case c.msgChan <- env:
+	default:
+		logger.Warningf("[channel: %s] Dropping BFT message, the chain is busy", c.support.ChainID())
+	}
+}
+
+func (c *chain) main() {
+	ticker := time.NewTicker(c.tickInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case env := <-c.sendChan:
+			c.receive(env)
+		case env := <-c.msgChan:
+			m, err := c.verify(env)
+			if err != nil {
+				logger.Warningf("[channel: %s] Rejecting BFT message: %s", c.support.ChainID(), err)
+				continue
+			}
+			if m.msg.Replica == c.id {
+				continue
+			}
+			c.handle(m)
+		case <-c.batchTimer:
+			c.batchTimer = nil
+			batch, _ := c.support.BlockCutter().Cut()
+			if len(batch) == 0 {
+				logger.Warningf("Batch timer expired with no pending requests, this might indicate a bug")
+				continue
+			}
+			logger.Debugf("[channel: %s] Batch timer expired, proposing block", c.support.ChainID())
+			c.batches = append(c.batches, batch)
+			c.propose()
+		case now := <-ticker.C:
+			c.tick(now)
+		case <-c.exitChan:
+			logger.Debugf("[channel: %s] Exiting", c.support.ChainID())
+			return
+		}
+
+		// Handle the messages this consenter sent to itself
+		for len(c.loopback) > 0 {
+			m := c.loopback[0]
+			c.loopback = c.loopback[1:]
+			c.handle(m)
+		}
+	}
+}
+
+func (c *chain) tickInterval() time.Duration {
+	interval := c.conf.RequestTimeout / 10
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	return interval
+}
+
+func (c *chain) tick(now time.Time) {
+	switch {
+	case c.inViewChange && now.Sub(c.changeStart) >= c.conf.ViewChangeTimeout:
+		logger.Warningf("[channel: %s] Changing to view %d timed out", c.support.ChainID(), c.target)
+		c.startViewChange(c.target + 1)
+	case !c.inViewChange && !c.timer.IsZero() && now.Sub(c.timer) >= c.conf.RequestTimeout:
+		logger.Warningf("[channel: %s] Requests timed out in view %d, suspecting primary %d", c.support.ChainID(), c.view, c.primary())
+		c.startViewChange(c.view + 1)
+	}
+	c.sync(false)
+}
+
+func (c *chain) primary() uint64 {
+	return c.view % uint64(len(c.consenters))
+}
+
+func (c *chain) isPrimary() bool {
+	return !c.inViewChange && c.primary() == c.id
+}
+
+// receive handles a message enqueued with this consenter
+func (c *chain) receive(env *cb.Envelope) {
+	raw := utils.MarshalOrPanic(env)
+	key := string(util.ComputeSHA256(raw))
+	if _, ok := c.pending[key]; !ok {
+		c.addPending(key, env)
+	}
+	if c.isPrimary() {
+		c.order(key)
+		return
+	}
+	if !c.inViewChange {
+		c.send(c.primary(), &ab.BFTMessage{Type: &ab.BFTMessage_Request{Request: &ab.BFTRequest{Payload: raw}}})
+	}
+}
+
+func (c *chain) addPending(key string, env *cb.Envelope) {
+	c.pending[key] = &request{env: env}
+	c.pendingOrder = append(c.pendingOrder, key)
+	if c.timer.IsZero() {
+		c.timer = time.Now()
+	}
+}
+
+func (c *chain) removePending(raw []byte) {
+	delete(c.pending, string(util.ComputeSHA256(raw)))
+}
+
+// order passes a pending request to the blockcutter, as the primary
+func (c *chain) order(key string) {
+	req, ok := c.pending[key]
+	if !ok || req.ordered {
+		return
+	}
+	req.ordered = true
+
+	batches, _, ok, pending := c.support.BlockCutter().Ordered(req.env)
+	if !ok {
+		logger.Warningf("[channel: %s] Dropping invalid request", c.support.ChainID())
+		delete(c.pending, key)
+		return
+	}
+	c.batches = append(c.batches, batches...)
+	if len(batches) > 0 {
+		c.batchTimer = nil
+	}
+	if pending && c.batchTimer == nil {
+		c.batchTimer = time.After(c.support.SharedConfig().BatchTimeout())
+	}
+	c.propose()
+}
+
+// propose sends the next batch to the other consenters, as the primary,
+// once the block for the previous batch was committed
+func (c *chain) propose() {
+	for c.isPrimary() && c.slot.prePrepare == nil && len(c.batches) > 0 {
+		batch := c.batches[0]
+		c.batches = c.batches[1:]
+
+		// The batch was cut before the previous blocks were committed, skip the requests
+		// those blocks hold, and validate the others again
+		var data [][]byte
+		for _, env := range batch {
+			raw := utils.MarshalOrPanic(env)
+			if _, ok := c.pending[string(util.ComputeSHA256(raw))]; !ok {
+				continue
+			}
+			if _, err := c.applyFilters(env); err != nil {
+				logger.Warningf("[channel: %s] Dropping request which is no longer valid: %s", c.support.ChainID(), err)
+				c.removePending(raw)
+				continue
+			}
+			data = append(data, raw)
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		block := c.nextBlock(data)
+		logger.Debugf("[channel: %s] Proposing block %d in view %d", c.support.ChainID(), c.height, c.view)
+		c.broadcast(&ab.BFTMessage{Type: &ab.BFTMessage_PrePrepare{PrePrepare: &ab.BFTPrePrepare{
+			View:      c.view,
+			Seq:       c.height,
+			Envelopes: data,
+			Digest:    block.Header.Hash(),
+		}}})
+	}
+}
+
+// applyFilters applies the filters of the chain to an envelope, and rejects the config transactions
+// which change the BFT consenters. The running consenters keep the consenters and the quorum they were
+// started with, so the blocks they sign after such a config would not satisfy its BlockValidation policy
+func (c *chain) applyFilters(env *cb.Envelope) (filter.Committer, error) {
+	committer, err := c.support.Filters().Apply(env)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkConsenters(env); err != nil {
+		return nil, err
+	}
+	return committer, nil
+}
+
+// checkConsenters returns an error if the envelope is a config transaction whose BFT consenters
+// differ from those of the chain
+func (c *chain) checkConsenters(env *cb.Envelope) error {
+	payload, err := utils.UnmarshalPayload(env.Payload)
+	if err != nil || payload.Header == nil {
+		return nil
+	}
+	chdr, err := utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+	if err != nil || chdr.Type != int32(cb.HeaderType_CONFIG) {
+		return nil
+	}
+	configEnv, err := configtx.UnmarshalConfigEnvelope(payload.Data)
+	if err != nil {
+		return err
+	}
+	if configEnv.Config == nil || configEnv.Config.ChannelGroup == nil {
+		return fmt.Errorf("Config transaction holds no config")
+	}
+	consenters := &ab.BFTConsenters{}
+	if ordererGroup, ok := configEnv.Config.ChannelGroup.Groups[config.OrdererGroupKey]; ok {
+		if value, ok := ordererGroup.Values[config.BFTConsentersKey]; ok {
+			if err := proto.Unmarshal(value.Value, consenters); err != nil {
+				return fmt.Errorf("Error unmarshaling the BFT consenters: %s", err)
+			}
+		}
+	}
+	if !proto.Equal(consenters, &ab.BFTConsenters{Consenters: c.consenters}) {
+		return fmt.Errorf("Config transaction changes the BFT consenters of chain %s, which the running consenters cannot follow", c.support.ChainID())
+	}
+	return nil
+}
+
+// stepDown drops the batches of the primary, the requests stay pending
+func (c *chain) stepDown() {
+	c.support.BlockCutter().Cut()
+	c.batches = nil
+	c.batchTimer = nil
+	for _, req := range c.pending {
+		req.ordered = false
+	}
+}
+
+func (c *chain) nextBlock(data [][]byte) *cb.Block {
+	var previousHash []byte
+	if c.lastHeader != nil {
+		previousHash = c.lastHeader.Hash()
+	}
+	block := cb.NewBlock(c.height, previousHash)
+	block.Data.Data = data
+	block.Header.DataHash = block.Data.Hash()
+	return block
+}
+
+func (c *chain) sign(msg *ab.BFTMessage) *ab.BFTEnvelope {
+	msg.Replica = c.id
+	payload := utils.MarshalOrPanic(msg)
+	return &ab.BFTEnvelope{
+		Channel:   c.support.ChainID(),
+		Payload:   payload,
+		Signature: utils.SignOrPanic(c.support, payload),
+	}
+}
+
+func (c *chain) send(to uint64, msg *ab.BFTMessage) {
+	c.transport.Send(endpoint(c.consenters[to]), c.sign(msg))
+}
+
+// broadcast sends the message to the other consenters, and to this consenter
+func (c *chain) broadcast(msg *ab.BFTMessage) {
+	env := c.sign(msg)
+	for i, consenter := range c.consenters {
+		if uint64(i) != c.id {
+			c.transport.Send(endpoint(consenter), env)
+		}
+	}
+	c.loopback = append(c.loopback, &message{env: env, msg: msg})
+}
+
+func endpoint(consenter *ab.BFTConsenter) string {
+	return fmt.Sprintf("%s:%d", consenter.Host, consenter.Port)
+}
+
+// verify checks that the envelope was signed by the consenter that sent it
+func (c *chain) verify(env *ab.BFTEnvelope) (*message, error) {
+	if env == nil {
+		return nil, fmt.Errorf("Missing envelope")
+	}
+	if env.Channel != c.support.ChainID() {
+		return nil, fmt.Errorf("Message for channel %s", env.Channel)
+	}
+	msg := &ab.BFTMessage{}
+	if err := proto.Unmarshal(env.Payload, msg); err != nil {
+		return nil, fmt.Errorf("Malformed message: %s", err)
+	}
+	if msg.Replica >= uint64(len(c.consenters)) {
+		return nil, fmt.Errorf("Unknown replica %d", msg.Replica)
+	}
+	if err := c.identities[msg.Replica].Verify(env.Payload, env.Signature); err != nil {
+		return nil, fmt.Errorf("Invalid signature of replica %d: %s", msg.Replica, err)
+	}
+	return &message{env: env, msg: msg}, nil
+}
+
+func (c *chain) handle(m *message) {
+	switch t := m.msg.Type.(type) {
+	case *ab.BFTMessage_Request:
+		c.handleRequest(m, t.Request)
+	case *ab.BFTMessage_PrePrepare:
+		if c.inSlot(m, t.PrePrepare.View, t.PrePrepare.Seq) {
+			c.handlePrePrepare(m, t.PrePrepare)
+		}
+	case *ab.BFTMessage_Prepare:
+		if c.inSlot(m, t.Prepare.View, t.Prepare.Seq) {
+			c.handlePrepare(m, t.Prepare)
+		}
+	case *ab.BFTMessage_Commit:
+		if c.inSlot(m, t.Commit.View, t.Commit.Seq) {
+			c.handleCommit(m, t.Commit)
+		}
+	case *ab.BFTMessage_ViewChange:
+		c.handleViewChange(m, t.ViewChange)
+	case *ab.BFTMessage_NewView:
+		c.handleNewView(m, t.NewView)
+	case *ab.BFTMessage_SyncRequest:
+		c.handleSyncRequest(m, t.SyncRequest)
+	case *ab.BFTMessage_SyncResponse:
+		c.handleSyncResponse(t.SyncResponse)
+	default:
+		logger.Warningf("[channel: %s] Unknown BFT message type %T from replica %d", c.support.ChainID(), m.msg.Type, m.msg.Replica)
+	}
+}
+
+func (c *chain) handleRequest(m *message, req *ab.BFTRequest) {
+	env, err := utils.UnmarshalEnvelope(req.Payload)
+	if err != nil {
+		logger.Warningf("[channel: %s] Malformed request from replica %d: %s", c.support.ChainID(), m.msg.Replica, err)
+		return
+	}
+	key := string(util.ComputeSHA256(req.Payload))
+	if _, ok := c.pending[key]; !ok {
+		c.addPending(key, env)
+	}
+	if c.isPrimary() {
+		c.order(key)
+	}
+}
+
+// inSlot returns whether a message of the agreement belongs to the current view and
+// sequence number, messages for later views or sequence numbers are kept for later
+func (c *chain) inSlot(m *message, view, seq uint64) bool {
+	if seq < c.height || view < c.view {
+		return false
+	}
+
+	c.observe(m.msg.Replica, view, seq)
+	c.adoptView()
+	if seq < c.height || view < c.view {
+		return false
+	}
+
+	if view > c.view || seq > c.height {
+		replica := m.msg.Replica
+		c.backlog[replica] = append(c.backlog[replica], m)
+		if len(c.backlog[replica]) > backlogSize {
+			c.backlog[replica] = c.backlog[replica][1:]
+		}
+		return false
+	}
+	// Messages of the view being left are dropped
+	return !c.inViewChange
+}
+
+// processBacklog handles the messages kept for the view and sequence number the chain moved to
+func (c *chain) processBacklog() {
+	backlog := c.backlog
+	c.backlog = make(map[uint64][]*message)
+	for _, messages := range backlog {
+		for _, m := range messages {
+			c.handle(m)
+		}
+	}
+}
+
+func (c *chain) observe(replica, view, seq uint64) {
+	if v, ok := c.views[replica]; !ok || view > v {
+		c.views[replica] = view
+	}
+	c.observeHeight(replica, seq)
+}
+
+func (c *chain) observeHeight(replica, height uint64) {
+	if height > c.heights[replica] {
+		c.heights[replica] = height
+	}
+}
+
+// adoptView moves to the highest view that f+1 consenters are working in, at least one of which is not
+// faulty, should this consenter have missed the new view, or be changing views on its own
+func (c *chain) adoptView() {
+	if len(c.views) < c.f+1 {
+		return
+	}
+	views := make([]uint64, 0, len(c.views))
+	for _, view := range c.views {
+		views = append(views, view)
+	}
+	sort.Sort(sort.Reverse(uint64Slice(views)))
+	view := views[c.f]
+
+	if view < c.view || (view == c.view && !c.inViewChange) {
+		return
+	}
+	logger.Infof("[channel: %s] Moving to view %d which %d consenters are working in", c.support.ChainID(), view, c.f+1)
+	c.installView(view)
+	c.resume()
+}
+
+func (c *chain) resetSlot() {
+	c.slot = &slot{
+		prepares: make(map[uint64]*message),
+		commits:  make(map[uint64]*ab.BFTCommit),
+	}
+}
+
+func (c *chain) handlePrePrepare(m *message, pp *ab.BFTPrePrepare) {
+	if m.msg.Replica != c.primary() || c.slot.prePrepare != nil {
+		return
+	}
+	if len(pp.Envelopes) == 0 {
+		logger.Warningf("[channel: %s] Rejecting empty proposal of primary %d", c.support.ChainID(), m.msg.Replica)
+		return
+	}
+
+	committers := make([]filter.Committer, len(pp.Envelopes))
+	for i, raw := range pp.Envelopes {
+		env, err := utils.UnmarshalEnvelope(raw)
+		if err != nil {
+			logger.Warningf("[channel: %s] Rejecting proposal of primary %d with malformed envelope: %s", c.support.ChainID(), m.msg.Replica, err)
+			return
+		}
+		committers[i], err = c.applyFilters(env)
+		if err != nil {
+			logger.Warningf("[channel: %s] Rejecting proposal of primary %d with invalid envelope: %s", c.support.ChainID(), m.msg.Replica, err)
+			return
+		}
+		if committers[i].Isolated() && len(pp.Envelopes) > 1 {
+			logger.Warningf("[channel: %s] Rejecting proposal of primary %d, an isolated envelope is batched with others", c.support.ChainID(), m.msg.Replica)
+			return
+		}
+	}
+
+	block := c.nextBlock(pp.Envelopes)
+	if !bytes.Equal(block.Header.Hash(), pp.Digest) {
+		logger.Warningf("[channel: %s] Rejecting proposal of primary %d whose digest does not match", c.support.ChainID(), m.msg.Replica)
+		return
+	}
+
+	c.slot.prePrepare = m
+	c.slot.block = block
+	c.slot.committers = committers
+	if c.timer.IsZero() {
+		c.timer = time.Now()
+	}
+
+	c.broadcast(&ab.BFTMessage{Type: &ab.BFTMessage_Prepare{Prepare: &ab.BFTPrepare{
+		View:   pp.View,
+		Seq:    pp.Seq,
+		Digest: pp.Digest,
+	}}})
+	c.checkPrepared()
+}
+
+func (c *chain) handlePrepare(m *message, prepare *ab.BFTPrepare) {
+	if _, ok := c.slot.prepares[m.msg.Replica]; ok {
+		return
+	}
+	c.slot.prepares[m.msg.Replica] = m
+	c.checkPrepared()
+}
+
+// checkPrepared sends a commit, along with the signature of the block, once a quorum accepted the proposal
+func (c *chain) checkPrepared() {
+	s := c.slot
+	if s.prePrepare == nil || s.sentCommit {
+		return
+	}
+	digest := s.prePrepare.msg.GetPrePrepare().Digest
+
+	var prepares []*ab.BFTEnvelope
+	for i := range c.consenters {
+		if m, ok := s.prepares[uint64(i)]; ok && bytes.Equal(m.msg.GetPrepare().Digest, digest) {
+			prepares = append(prepares, m.env)
+		}
+	}
+	if len(prepares) < c.quorum {
+		return
+	}
+
+	s.sentCommit = true
+	c.prepared = &ab.BFTPrepared{
+		PrePrepare: s.prePrepare.env,
+		Prepares:   prepares[:c.quorum],
+	}
+
+	signature := &cb.MetadataSignature{
+		SignatureHeader: utils.MarshalOrPanic(utils.NewSignatureHeaderOrPanic(c.support)),
+	}
+	// Note, the signature is over a nil metadata value, as the peers verify the block signatures
+	signature.Signature = utils.SignOrPanic(c.support, util.ConcatenateBytes(nil, signature.SignatureHeader, s.block.Header.Bytes()))
+
+	c.broadcast(&ab.BFTMessage{Type: &ab.BFTMessage_Commit{Commit: &ab.BFTCommit{
+		View:      c.view,
+		Seq:       c.height,
+		Digest:    digest,
+		Signature: signature,
+	}}})
+}
+
+func (c *chain) handleCommit(m *message, commit *ab.BFTCommit) {
+	if _, ok := c.slot.commits[m.msg.Replica]; ok {
+		return
+	}
+	c.slot.commits[m.msg.Replica] = commit
+	c.checkCommitted()
+}
+
+// checkCommitted writes the block once a quorum signed it
+func (c *chain) checkCommitted() {
+	s := c.slot
+	if !s.sentCommit {
+		return
+	}
+	digest := s.prePrepare.msg.GetPrePrepare().Digest
+
+	var signatures []*cb.MetadataSignature
+	for i := range c.consenters {
+		commit, ok := s.commits[uint64(i)]
+		if !ok || !bytes.Equal(commit.Digest, digest) {
+			continue
+		}
+		if err := c.verifyBlockSignature(uint64(i), s.block.Header, commit.Signature); err != nil {
+			logger.Warningf("[channel: %s] Invalid block signature from replica %d: %s", c.support.ChainID(), i, err)
+			delete(s.commits, uint64(i))
+			continue
+		}
+		signatures = append(signatures, commit.Signature)
+	}
+	if len(signatures) < c.quorum {
+		return
+	}
+
+	block := s.block
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(&cb.Metadata{Signatures: signatures[:c.quorum]})
+	logger.Debugf("[channel: %s] Committing block %d in view %d", c.support.ChainID(), block.Header.Number, c.view)
+	c.support.WriteBlock(block, s.committers, nil)
+	c.advance(block)
+}
+
+func (c *chain) verifyBlockSignature(replica uint64, header *cb.BlockHeader, signature *cb.MetadataSignature) error {
+	if signature == nil {
+		return fmt.Errorf("Missing signature")
+	}
+	shdr, err := utils.GetSignatureHeader(signature.SignatureHeader)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(shdr.Creator, c.consenters[replica].Identity) {
+		return fmt.Errorf("Signature creator is not replica %d", replica)
+	}
+	return c.identities[replica].Verify(util.ConcatenateBytes(nil, signature.SignatureHeader, header.Bytes()), signature.Signature)
+}
+
+// verifyQuorum checks that the block signatures were made by a quorum of distinct consenters
+func (c *chain) verifyQuorum(header *cb.BlockHeader, metadata *cb.Metadata) error {
+	if header == nil || metadata == nil {
+		return fmt.Errorf("Missing block header or signatures")
+	}
+	signers := make(map[uint64]struct{})
+	for _, signature := range metadata.Signatures {
+		shdr, err := utils.GetSignatureHeader(signature.SignatureHeader)
+		if err != nil {
+			continue
+		}
+		replica, ok := c.replicas[string(shdr.Creator)]
+		if !ok {
+			continue
+		}
+		if err := c.verifyBlockSignature(replica, header, signature); err != nil {
+			continue
+		}
+		signers[replica] = struct{}{}
+	}
+	if len(signers) < c.quorum {
+		return fmt.Errorf("Block %d is signed by %d consenters, %d are required", header.Number, len(signers), c.quorum)
+	}
+	return nil
+}
+
+// advance moves the chain past the committed block
+func (c *chain) advance(block *cb.Block) {
+	c.height = block.Header.Number + 1
+	c.lastHeader = block.Header
+	c.lastSignatures, _ = utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	c.prepared = nil
+	c.resetSlot()
+	c.behindSince = time.Time{}
+
+	for _, raw := range block.Data.Data {
+		c.removePending(raw)
+	}
+	c.compactPending()
+	c.resetTimer()
+
+	c.processBacklog()
+	c.propose()
+}
+
+func (c *chain) compactPending() {
+	order := c.pendingOrder[:0]
+	for _, key := range c.pendingOrder {
+		if _, ok := c.pending[key]; ok {
+			order = append(order, key)
+		}
+	}
+	c.pendingOrder = order
+}
+
+// resetTimer restarts the request timer after progress was made, if requests are still waiting
+func (c *chain) resetTimer() {
+	if len(c.pending) > 0 {
+		c.timer = time.Now()
+	} else {
+		c.timer = time.Time{}
+	}
+}
+
+// startViewChange suspects the primary of the current view and asks the other consenters to change to the given view
+func (c *chain) startViewChange(view uint64) {
+	if view <= c.view || (c.inViewChange && view <= c.target) {
+		return
+	}
+	logger.Infof("[channel: %s] Changing from view %d to view %d", c.support.ChainID(), c.view, view)
+
+	if c.isPrimary() {
+		c.stepDown()
+	}
+	c.inViewChange = true
+	c.target = view
+	c.changeStart = time.Now()
+	c.resetSlot()
+	c.views = make(map[uint64]uint64)
+
+	vc := &ab.BFTViewChange{
+		View:     view,
+		Height:   c.height,
+		Prepared: c.prepared,
+	}
+	if c.lastHeader != nil {
+		vc.LastHeader = c.lastHeader
+		vc.LastSignatures = c.lastSignatures
+	}
+	c.broadcast(&ab.BFTMessage{Type: &ab.BFTMessage_ViewChange{ViewChange: vc}})
+}
+
+func (c *chain) handleViewChange(m *message, vc *ab.BFTViewChange) {
+	if vc.View <= c.view {
+		return
+	}
+	if err := c.verifyViewChange(vc); err != nil {
+		logger.Warningf("[channel: %s] Rejecting view change from replica %d: %s", c.support.ChainID(), m.msg.Replica, err)
+		return
+	}
+	c.observeHeight(m.msg.Replica, vc.Height)
+
+	if c.viewChanges[vc.View] == nil {
+		c.viewChanges[vc.View] = make(map[uint64]*message)
+	}
+	c.viewChanges[vc.View][m.msg.Replica] = m
+
+	c.joinViewChange()
+	c.sendNewView(vc.View)
+}
+
+// joinViewChange changes views once f+1 consenters asked for a later view, at least one of which is not faulty
+func (c *chain) joinViewChange() {
+	current := c.view
+	if c.inViewChange {
+		current = c.target
+	}
+
+	requested := make(map[uint64]uint64)
+	for view, vcs := range c.viewChanges {
+		if view <= current {
+			continue
+		}
+		for replica := range vcs {
+			if v, ok := requested[replica]; !ok || view < v {
+				requested[replica] = view
+			}
+		}
+	}
+	if len(requested) < c.f+1 {
+		return
+	}
+
+	views := make([]uint64, 0, len(requested))
+	for _, view := range requested {
+		views = append(views, view)
+	}
+	sort.Sort(uint64Slice(views))
+	c.startViewChange(views[0])
+}
+
+// sendNewView starts the given view, as its primary, once a quorum asked to change to it
+func (c *chain) sendNewView(view uint64) {
+	if !c.inViewChange || c.target != view || view%uint64(len(c.consenters)) != c.id || c.newViewSent >= view {
+		return
+	}
+	if len(c.viewChanges[view]) < c.quorum {
+		return
+	}
+
+	var envs []*ab.BFTEnvelope
+	var vcs []*ab.BFTViewChange
+	for i := range c.consenters {
+		if m, ok := c.viewChanges[view][uint64(i)]; ok && len(envs) < c.quorum {
+			envs = append(envs, m.env)
+			vcs = append(vcs, m.msg.GetViewChange())
+		}
+	}
+
+	nv := &ab.BFTNewView{
+		View:        view,
+		ViewChanges: envs,
+	}
+	height, proposal := selectProposal(vcs)
+	if proposal != nil {
+		nv.PrePrepare = c.sign(&ab.BFTMessage{Type: &ab.BFTMessage_PrePrepare{PrePrepare: &ab.BFTPrePrepare{
+			View:      view,
+			Seq:       height,
+			Envelopes: proposal.Envelopes,
+			Digest:    proposal.Digest,
+		}}})
+	}
+
+	c.newViewSent = view
+	logger.Infof("[channel: %s] Starting view %d as primary", c.support.ChainID(), view)
+	c.broadcast(&ab.BFTMessage{Type: &ab.BFTMessage_NewView{NewView: nv}})
+}
+
+// selectProposal returns the highest height of the view changes, along with the proposal that a quorum
+// accepted at that height in the latest view, if any. Such a proposal might have been committed by some
+// consenters, so the new view has to propose it again.
+func selectProposal(vcs []*ab.BFTViewChange) (uint64, *ab.BFTPrePrepare) {
+	var height uint64
+	for _, vc := range vcs {
+		if vc.Height > height {
+			height = vc.Height
+		}
+	}
+
+	var proposal *ab.BFTPrePrepare
+	for _, vc := range vcs {
+		if vc.Height != height || vc.Prepared == nil {
+			continue
+		}
+		msg := &ab.BFTMessage{}
+		if err := proto.Unmarshal(vc.Prepared.PrePrepare.Payload, msg); err != nil {
+			continue
+		}
+		pp := msg.GetPrePrepare()
+		if pp != nil && (proposal == nil || pp.View > proposal.View) {
+			proposal = pp
+		}
+	}
+	return height, proposal
+}
+
+// verifyViewChange checks the proofs carried by a view change, the signatures of its last block and
+// the proposal accepted by a quorum
+func (c *chain) verifyViewChange(vc *ab.BFTViewChange) error {
+	if vc.Height > 1 {
+		if vc.LastHeader == nil || vc.LastHeader.Number != vc.Height-1 {
+			return fmt.Errorf("Missing header of block %d", vc.Height-1)
+		}
+		if err := c.verifyQuorum(vc.LastHeader, vc.LastSignatures); err != nil {
+			return err
+		}
+	}
+
+	if vc.Prepared == nil {
+		return nil
+	}
+	ppm, err := c.verify(vc.Prepared.PrePrepare)
+	if err != nil {
+		return err
+	}
+	pp := ppm.msg.GetPrePrepare()
+	if pp == nil || pp.Seq != vc.Height || pp.View >= vc.View || ppm.msg.Replica != pp.View%uint64(len(c.consenters)) {
+		return fmt.Errorf("Invalid prepared proposal")
+	}
+
+	prepared := make(map[uint64]struct{})
+	for _, env := range vc.Prepared.Prepares {
+		m, err := c.verify(env)
+		if err != nil {
+			return err
+		}
+		prepare := m.msg.GetPrepare()
+		if prepare == nil || prepare.View != pp.View || prepare.Seq != pp.Seq || !bytes.Equal(prepare.Digest, pp.Digest) {
+			return fmt.Errorf("Invalid prepare of replica %d", m.msg.Replica)
+		}
+		prepared[m.msg.Replica] = struct{}{}
+	}
+	if len(prepared) < c.quorum {
+		return fmt.Errorf("The proposal was accepted by %d consenters, %d are required", len(prepared), c.quorum)
+	}
+	return nil
+}
+
+func (c *chain) handleNewView(m *message, nv *ab.BFTNewView) {
+	if nv.View <= c.view || (c.inViewChange && nv.View < c.target) || m.msg.Replica != nv.View%uint64(len(c.consenters)) {
+		return
+	}
+
+	vcs := make([]*ab.BFTViewChange, 0, len(nv.ViewChanges))
+	senders := make(map[uint64]uint64)
+	for _, env := range nv.ViewChanges {
+		vcm, err := c.verify(env)
+		if err != nil {
+			logger.Warningf("[channel: %s] Rejecting new view %d: %s", c.support.ChainID(), nv.View, err)
+			return
+		}
+		vc := vcm.msg.GetViewChange()
+		if vc == nil || vc.View != nv.View {
+			logger.Warningf("[channel: %s] Rejecting new view %d with a view change for another view", c.support.ChainID(), nv.View)
+			return
+		}
+		if err := c.verifyViewChange(vc); err != nil {
+			logger.Warningf("[channel: %s] Rejecting new view %d: %s", c.support.ChainID(), nv.View, err)
+			return
+		}
+		senders[vcm.msg.Replica] = vc.Height
+		vcs = append(vcs, vc)
+	}
+	if len(senders) < c.quorum {
+		logger.Warningf("[channel: %s] Rejecting new view %d with %d view changes, %d are required", c.support.ChainID(), nv.View, len(senders), c.quorum)
+		return
+	}
+
+	var ppm *message
+	height, proposal := selectProposal(vcs)
+	if proposal != nil || nv.PrePrepare != nil {
+		var err error
+		if nv.PrePrepare == nil {
+			err = fmt.Errorf("Missing proposal")
+		} else if ppm, err = c.verify(nv.PrePrepare); err == nil {
+			pp := ppm.msg.GetPrePrepare()
+			if proposal == nil || pp == nil || ppm.msg.Replica != m.msg.Replica || pp.View != nv.View || pp.Seq != height ||
+				!bytes.Equal(pp.Digest, proposal.Digest) || !equalEnvelopes(pp.Envelopes, proposal.Envelopes) {
+				err = fmt.Errorf("The proposal does not match the view changes")
+			}
+		}
+		if err != nil {
+			logger.Warningf("[channel: %s] Rejecting new view %d: %s", c.support.ChainID(), nv.View, err)
+			return
+		}
+	}
+
+	logger.Infof("[channel: %s] Moving to view %d, replica %d is the primary", c.support.ChainID(), nv.View, m.msg.Replica)
+	for replica, height := range senders {
+		c.observeHeight(replica, height)
+	}
+	c.installView(nv.View)
+	if ppm != nil {
+		c.handle(ppm)
+	}
+	c.resume()
+	if height > c.height {
+		c.sync(true)
+	}
+}
+
+func equalEnvelopes(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// installView moves the chain to the given view
+func (c *chain) installView(view uint64) {
+	if c.isPrimary() {
+		c.stepDown()
+	}
+	c.view = view
+	c.inViewChange = false
+	c.resetSlot()
+	for v := range c.viewChanges {
+		if v <= view {
+			delete(c.viewChanges, v)
+		}
+	}
+	c.resetTimer()
+	c.processBacklog()
+}
+
+// resume passes the pending requests to the primary of the view
+func (c *chain) resume() {
+	for _, key := range c.pendingOrder {
+		req, ok := c.pending[key]
+		if !ok {
+			continue
+		}
+		if c.isPrimary() {
+			c.order(key)
+		} else if !c.inViewChange {
+			c.send(c.primary(), &ab.BFTMessage{Type: &ab.BFTMessage_Request{Request: &ab.BFTRequest{Payload: utils.MarshalOrPanic(req.env)}}})
+		}
+	}
+}
+
+// sync asks the consenters that are ahead for the blocks this consenter is missing, once it has been
+// behind for a while, or right away if forced
+func (c *chain) sync(force bool) {
+	var ahead []uint64
+	for replica, height := range c.heights {
+		if height > c.height {
+			ahead = append(ahead, replica)
+		}
+	}
+	if len(ahead) == 0 {
+		c.behindSince = time.Time{}
+		return
+	}
+
+	now := time.Now()
+	if c.behindSince.IsZero() {
+		c.behindSince = now
+	}
+	interval := c.conf.RequestTimeout / 4
+	if !force && (now.Sub(c.behindSince) < interval || now.Sub(c.lastSync) < interval) {
+		return
+	}
+
+	c.lastSync = now
+	logger.Debugf("[channel: %s] Asking replicas %v for the blocks from %d", c.support.ChainID(), ahead, c.height)
+	for _, replica := range ahead {
+		c.send(replica, &ab.BFTMessage{Type: &ab.BFTMessage_SyncRequest{SyncRequest: &ab.BFTSyncRequest{Height: c.height}}})
+	}
+}
+
+func (c *chain) handleSyncRequest(m *message, req *ab.BFTSyncRequest) {
+	resp := &ab.BFTSyncResponse{}
+	for number := req.Height; number < c.height && len(resp.Blocks) < syncBatchSize; number++ {
+		block := ledger.GetBlock(c.support.Reader(), number)
+		if block == nil {
+			break
+		}
+		resp.Blocks = append(resp.Blocks, block)
+	}
+	if len(resp.Blocks) > 0 {
+		c.send(m.msg.Replica, &ab.BFTMessage{Type: &ab.BFTMessage_SyncResponse{SyncResponse: resp}})
+	}
+}
+
+// handleSyncResponse writes the blocks this consenter is missing, once it checked that a quorum signed them
+func (c *chain) handleSyncResponse(resp *ab.BFTSyncResponse) {
+	progress := false
+	for _, block := range resp.Blocks {
+		if block.Header == nil || block.Data == nil || block.Header.Number < c.height {
+			continue
+		}
+		if block.Header.Number > c.height {
+			break
+		}
+		if err := c.verifySyncedBlock(block); err != nil {
+			logger.Warningf("[channel: %s] Rejecting synced block %d: %s", c.support.ChainID(), block.Header.Number, err)
+			return
+		}
+
+		var committers []filter.Committer
+		for _, raw := range block.Data.Data {
+			env, err := utils.UnmarshalEnvelope(raw)
+			if err != nil {
+				logger.Panicf("[channel: %s] Synced block %d, signed by a quorum, holds a malformed envelope: %s", c.support.ChainID(), block.Header.Number, err)
+			}
+			committer, err := c.applyFilters(env)
+			if err != nil {
+				logger.Errorf("[channel: %s] Synced block %d, signed by a quorum, holds an envelope which is not valid: %s", c.support.ChainID(), block.Header.Number, err)
+				continue
+			}
+			committers = append(committers, committer)
+		}
+
+		logger.Debugf("[channel: %s] Writing synced block %d", c.support.ChainID(), block.Header.Number)
+		c.support.WriteBlock(block, committers, nil)
+		c.advance(block)
+		progress = true
+	}
+	if progress {
+		c.sync(true)
+	}
+}
+
+func (c *chain) verifySyncedBlock(block *cb.Block) error {
+	if c.lastHeader != nil && !bytes.Equal(block.Header.PreviousHash, c.lastHeader.Hash()) {
+		return fmt.Errorf("Previous hash does not match")
+	}
+	if !bytes.Equal(block.Header.DataHash, block.Data.Hash()) {
+		return fmt.Errorf("Data hash does not match")
+	}
+	metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+	if err != nil {
+		return err
+	}
+	return c.verifyQuorum(block.Header, metadata)
+}
+
+type uint64Slice []uint64
+
+func (s uint64Slice) Len() int           { return len(s) }
+func (s uint64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s uint64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,511 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric/common/config"
+	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/blockcutter"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
+	cb "github.com/hyperledger/fabric/protos/common"
+	mspprotos "github.com/hyperledger/fabric/protos/msp"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/hyperledger/fabric/protos/utils"
+
+	logging "github.com/op/go-logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	logging.SetLevel(logging.INFO, "")
+}
+
+const testChainID = "testchain"
+
+var testConf = localconfig.BFT{
+	RequestTimeout:    500 * time.Millisecond,
+	ViewChangeTimeout: time.Second,
+}
+
+// The identities sign with the hash of the identity and the message
+func fakeSignature(id, msg []byte) []byte {
+	hash := sha256.Sum256(util.ConcatenateBytes(id, msg))
+	return hash[:]
+}
+
+type fakeIdentity struct {
+	id []byte
+}
+
+func (fi *fakeIdentity) GetIdentifier() *msp.IdentityIdentifier {
+	return &msp.IdentityIdentifier{Mspid: "TESTMSP", Id: string(fi.id)}
+}
+
+func (fi *fakeIdentity) GetMSPIdentifier() string {
+	return "TESTMSP"
+}
+
+func (fi *fakeIdentity) Validate() error {
+	return nil
+}
+
+func (fi *fakeIdentity) GetOrganizationalUnits() []*msp.OUIdentifier {
+	return nil
+}
+
+func (fi *fakeIdentity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(fakeSignature(fi.id, msg), sig) {
+		return fmt.Errorf("Invalid signature")
+	}
+	return nil
+}
+
+func (fi *fakeIdentity) Serialize() ([]byte, error) {
+	return fi.id, nil
+}
+
+func (fi *fakeIdentity) SatisfiesPrincipal(principal *mspprotos.MSPPrincipal) error {
+	return nil
+}
+
+type fakeMSPManager struct{}
+
+func (fm *fakeMSPManager) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	return &fakeIdentity{id: serializedIdentity}, nil
+}
+
+func (fm *fakeMSPManager) Setup(msps []msp.MSP) error {
+	return nil
+}
+
+func (fm *fakeMSPManager) GetMSPs() (map[string]msp.MSP, error) {
+	return nil, nil
+}
+
+type testSupport struct {
+	identity     []byte
+	sharedConfig *mockconfig.Orderer
+	filters      *filter.RuleSet
+	cutter       blockcutter.Receiver
+
+	lock   sync.Mutex
+	ledger ledger.ReadWriter
+	blocks []*cb.Block
+}
+
+func newTestSupport(identity []byte, consenters []*ab.BFTConsenter) *testSupport {
+	sharedConfig := &mockconfig.Orderer{
+		BatchSizeVal:     &ab.BatchSize{MaxMessageCount: 5, AbsoluteMaxBytes: 1024 * 1024, PreferredMaxBytes: 1024 * 1024},
+		BatchTimeoutVal:  50 * time.Millisecond,
+		BFTConsentersVal: consenters,
+	}
+	filters := filter.NewRuleSet([]filter.Rule{filter.EmptyRejectRule, filter.AcceptRule})
+	rl, _ := ramledger.New(10).GetOrCreate(testChainID)
+	rl.Append(cb.NewBlock(0, nil))
+	return &testSupport{
+		identity:     identity,
+		sharedConfig: sharedConfig,
+		filters:      filters,
+		cutter:       blockcutter.NewReceiverImpl(sharedConfig, filters),
+		ledger:       rl,
+	}
+}
+
+func (ts *testSupport) NewSignatureHeader() (*cb.SignatureHeader, error) {
+	return &cb.SignatureHeader{Creator: ts.identity, Nonce: util.GenerateBytesUUID()}, nil
+}
+
+func (ts *testSupport) Sign(message []byte) ([]byte, error) {
+	return fakeSignature(ts.identity, message), nil
+}
+
+func (ts *testSupport) BlockCutter() blockcutter.Receiver {
+	return ts.cutter
+}
+
+func (ts *testSupport) SharedConfig() config.Orderer {
+	return ts.sharedConfig
+}
+
+func (ts *testSupport) CreateNextBlock(messages []*cb.Envelope) *cb.Block {
+	return ledger.CreateNextBlock(ts.ledger, messages)
+}
+
+func (ts *testSupport) WriteBlock(block *cb.Block, committers []filter.Committer, encodedMetadataValue []byte) *cb.Block {
+	for _, committer := range committers {
+		committer.Commit()
+	}
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	if err := ts.ledger.Append(block); err != nil {
+		panic(err)
+	}
+	ts.blocks = append(ts.blocks, block)
+	return block
+}
+
+func (ts *testSupport) ChainID() string {
+	return testChainID
+}
+
+func (ts *testSupport) Height() uint64 {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	return ts.ledger.Height()
+}
+
+func (ts *testSupport) Reader() ledger.Reader {
+	return ts.ledger
+}
+
+func (ts *testSupport) Filters() *filter.RuleSet {
+	return ts.filters
+}
+
+func (ts *testSupport) MSPManager() msp.MSPManager {
+	return &fakeMSPManager{}
+}
+
+// written returns the blocks written by the consenter
+func (ts *testSupport) written() []*cb.Block {
+	ts.lock.Lock()
+	defer ts.lock.Unlock()
+	return append([]*cb.Block(nil), ts.blocks...)
+}
+
+type cluster struct {
+	network    *LocalNetwork
+	consenters []*ab.BFTConsenter
+	supports   []*testSupport
+	chains     []*chain
+}
+
+func newCluster(t *testing.T, n int) *cluster {
+	cl := &cluster{network: NewLocalNetwork()}
+	for i := 0; i < n; i++ {
+		cl.consenters = append(cl.consenters, &ab.BFTConsenter{
+			Host:     fmt.Sprintf("orderer%d", i),
+			Port:     7050,
+			Identity: []byte(fmt.Sprintf("consenter%d", i)),
+		})
+	}
+	for i := 0; i < n; i++ {
+		cl.supports = append(cl.supports, newTestSupport(cl.consenters[i].Identity, cl.consenters))
+		cl.chains = append(cl.chains, nil)
+		cl.start(t, i)
+	}
+	return cl
+}
+
+func (cl *cluster) start(t *testing.T, i int) {
+	c, err := New(testConf, cl.network.Transport(endpoint(cl.consenters[i]))).HandleChain(cl.supports[i], nil)
+	if err != nil {
+		t.Fatalf("Failed creating chain of consenter %d: %s", i, err)
+	}
+	cl.chains[i] = c.(*chain)
+	cl.chains[i].Start()
+}
+
+func (cl *cluster) halt() {
+	for _, c := range cl.chains {
+		c.Halt()
+	}
+}
+
+func testEnvelope(i int) *cb.Envelope {
+	return &cb.Envelope{Payload: []byte(fmt.Sprintf("TEST_MESSAGE_%d", i))}
+}
+
+// awaitEnvelopes waits until the given consenters wrote blocks holding the given number of envelopes
+func (cl *cluster) awaitEnvelopes(t *testing.T, count int, consenters ...int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for _, i := range consenters {
+		for {
+			written := 0
+			for _, block := range cl.supports[i].written() {
+				written += len(block.Data.Data)
+			}
+			if written == count {
+				break
+			}
+			if written > count || time.Now().After(deadline) {
+				t.Fatalf("Consenter %d wrote %d envelopes, expected %d", i, written, count)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// assertAgreement checks that the given consenters wrote the same blocks, each signed by a quorum of consenters
+func (cl *cluster) assertAgreement(t *testing.T, consenters ...int) {
+	expected := cl.supports[consenters[0]].written()
+	for _, i := range consenters {
+		blocks := cl.supports[i].written()
+		assert.Len(t, blocks, len(expected), "Consenter %d wrote a different number of blocks", i)
+		for j, block := range blocks {
+			if j < len(expected) {
+				assert.Equal(t, expected[j].Header.Hash(), block.Header.Hash(), "Consenter %d wrote a different block %d", i, block.Header.Number)
+			}
+			metadata, err := utils.GetMetadataFromBlock(block, cb.BlockMetadataIndex_SIGNATURES)
+			assert.NoError(t, err)
+			assert.NoError(t, cl.chains[i].verifyQuorum(block.Header, metadata), "Block %d of consenter %d is not signed by a quorum", block.Header.Number, i)
+		}
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	for i := 0; i < 12; i++ {
+		assert.True(t, cl.chains[i%4].Enqueue(testEnvelope(i)))
+	}
+	cl.awaitEnvelopes(t, 12, 0, 1, 2, 3)
+	cl.assertAgreement(t, 0, 1, 2, 3)
+
+	var envelopes [][]byte
+	for _, block := range cl.supports[0].written() {
+		envelopes = append(envelopes, block.Data.Data...)
+	}
+	for i := 0; i < 12; i++ {
+		assert.Contains(t, envelopes, utils.MarshalOrPanic(testEnvelope(i)), "Envelope %d was not ordered", i)
+	}
+}
+
+func TestSingleConsenter(t *testing.T) {
+	cl := newCluster(t, 1)
+	defer cl.halt()
+
+	cl.chains[0].Enqueue(testEnvelope(0))
+	cl.awaitEnvelopes(t, 1, 0)
+	cl.assertAgreement(t, 0)
+}
+
+func TestCrashedBackup(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	cl.chains[3].Halt()
+	cl.network.Disconnect(endpoint(cl.consenters[3]))
+
+	for i := 0; i < 6; i++ {
+		cl.chains[i%3].Enqueue(testEnvelope(i))
+	}
+	cl.awaitEnvelopes(t, 6, 0, 1, 2)
+
+	// The restarted consenter catches up with the blocks it missed
+	cl.network.Connect(endpoint(cl.consenters[3]))
+	cl.start(t, 3)
+	for i := 6; i < 12; i++ {
+		cl.chains[i%4].Enqueue(testEnvelope(i))
+	}
+	cl.awaitEnvelopes(t, 12, 0, 1, 2, 3)
+	cl.assertAgreement(t, 0, 1, 2, 3)
+}
+
+func TestCrashedPrimary(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	cl.chains[0].Enqueue(testEnvelope(0))
+	cl.awaitEnvelopes(t, 1, 0, 1, 2, 3)
+
+	cl.chains[0].Halt()
+	cl.network.Disconnect(endpoint(cl.consenters[0]))
+
+	// The backups change to a view with another primary once the request times out
+	for i := 1; i < 7; i++ {
+		cl.chains[1+i%3].Enqueue(testEnvelope(i))
+	}
+	cl.awaitEnvelopes(t, 7, 1, 2, 3)
+	cl.assertAgreement(t, 1, 2, 3)
+
+	// The restarted consenter catches up with the view and the blocks it missed
+	cl.network.Connect(endpoint(cl.consenters[0]))
+	cl.start(t, 0)
+	for i := 7; i < 11; i++ {
+		cl.chains[i%4].Enqueue(testEnvelope(i))
+	}
+	cl.awaitEnvelopes(t, 11, 0, 1, 2, 3)
+	cl.assertAgreement(t, 0, 1, 2, 3)
+}
+
+func TestTooManyCrashed(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	for _, i := range []int{2, 3} {
+		cl.chains[i].Halt()
+		cl.network.Disconnect(endpoint(cl.consenters[i]))
+	}
+
+	cl.chains[0].Enqueue(testEnvelope(0))
+	time.Sleep(testConf.RequestTimeout)
+	assert.Len(t, cl.supports[0].written(), 0, "Two consenters out of four should not commit blocks")
+	assert.Len(t, cl.supports[1].written(), 0, "Two consenters out of four should not commit blocks")
+}
+
+func TestEquivocatingPrimary(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	// The primary of the first view is byzantine: it proposes one block to the first two
+	// backups and a conflicting block to the third one, and prepares both of them
+	primary := cl.chains[0]
+	primary.Halt()
+	propose := func(env *cb.Envelope) []*ab.BFTMessage {
+		data := [][]byte{utils.MarshalOrPanic(env)}
+		digest := primary.nextBlock(data).Header.Hash()
+		return []*ab.BFTMessage{
+			{Type: &ab.BFTMessage_PrePrepare{PrePrepare: &ab.BFTPrePrepare{View: 0, Seq: 1, Envelopes: data, Digest: digest}}},
+			{Type: &ab.BFTMessage_Prepare{Prepare: &ab.BFTPrepare{View: 0, Seq: 1, Digest: digest}}},
+		}
+	}
+	proposals := map[int][]*ab.BFTMessage{
+		1: propose(testEnvelope(0)),
+		2: propose(testEnvelope(0)),
+		3: propose(testEnvelope(1)),
+	}
+	for backup, msgs := range proposals {
+		for _, msg := range msgs {
+			primary.send(uint64(backup), msg)
+		}
+	}
+
+	// Only the first block gathers a quorum of prepares, the backups commit it
+	// in the next view once the request times out, and none of them commits the other
+	cl.awaitEnvelopes(t, 1, 1, 2, 3)
+	cl.assertAgreement(t, 1, 2, 3)
+	assert.Equal(t, utils.MarshalOrPanic(testEnvelope(0)), cl.supports[3].written()[0].Data.Data[0])
+
+	for i := 2; i < 6; i++ {
+		cl.chains[1+i%3].Enqueue(testEnvelope(i))
+	}
+	cl.awaitEnvelopes(t, 5, 1, 2, 3)
+	cl.assertAgreement(t, 1, 2, 3)
+}
+
+func TestForgedMessage(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	// A consenter cannot impersonate another one
+	env := cl.chains[1].sign(&ab.BFTMessage{Type: &ab.BFTMessage_Prepare{Prepare: &ab.BFTPrepare{}}})
+	_, err := cl.chains[0].verify(env)
+	assert.NoError(t, err)
+
+	msg := &ab.BFTMessage{Replica: 2, Type: &ab.BFTMessage_Prepare{Prepare: &ab.BFTPrepare{}}}
+	env.Payload = utils.MarshalOrPanic(msg)
+	_, err = cl.chains[0].verify(env)
+	assert.Error(t, err)
+
+	env = cl.chains[1].sign(msg)
+	msg.Replica = 4
+	env.Payload = utils.MarshalOrPanic(msg)
+	_, err = cl.chains[0].verify(env)
+	assert.Error(t, err, "Messages from unknown replicas should be rejected")
+}
+
+func TestVerifyQuorum(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	header := cb.NewBlock(1, []byte("previous")).Header
+	sign := func(i int) *cb.MetadataSignature {
+		shdr, _ := cl.supports[i].NewSignatureHeader()
+		signature := &cb.MetadataSignature{SignatureHeader: utils.MarshalOrPanic(shdr)}
+		signature.Signature, _ = cl.supports[i].Sign(util.ConcatenateBytes(nil, signature.SignatureHeader, header.Bytes()))
+		return signature
+	}
+
+	assert.NoError(t, cl.chains[0].verifyQuorum(header, &cb.Metadata{Signatures: []*cb.MetadataSignature{sign(0), sign(1), sign(3)}}))
+	assert.Error(t, cl.chains[0].verifyQuorum(header, &cb.Metadata{Signatures: []*cb.MetadataSignature{sign(0), sign(1)}}))
+	assert.Error(t, cl.chains[0].verifyQuorum(header, &cb.Metadata{Signatures: []*cb.MetadataSignature{sign(0), sign(1), sign(1)}}), "Signatures of the same consenter should count once")
+
+	forged := sign(2)
+	forged.Signature = []byte("forged")
+	assert.Error(t, cl.chains[0].verifyQuorum(header, &cb.Metadata{Signatures: []*cb.MetadataSignature{sign(0), sign(1), forged}}))
+}
+
+func TestNotAConsenter(t *testing.T) {
+	consenters := []*ab.BFTConsenter{{Host: "orderer0", Port: 7050, Identity: []byte("consenter0")}}
+	_, err := New(testConf, NewLocalNetwork().Transport("orderer1:7050")).HandleChain(newTestSupport([]byte("consenter1"), consenters), nil)
+	assert.Error(t, err)
+
+	_, err = New(testConf, NewLocalNetwork().Transport("orderer1:7050")).HandleChain(newTestSupport([]byte("consenter1"), nil), nil)
+	assert.Error(t, err)
+}
+
+func TestEnqueueAfterHalt(t *testing.T) {
+	cl := newCluster(t, 1)
+	cl.halt()
+	assert.False(t, cl.chains[0].Enqueue(testEnvelope(0)))
+	select {
+	case <-cl.chains[0].Errored():
+	default:
+		t.Fatalf("Expected Errored to be closed by halt")
+	}
+}
+
+func configEnvelope(consenters []*ab.BFTConsenter) *cb.Envelope {
+	group := cb.NewConfigGroup()
+	group.Groups[config.OrdererGroupKey] = cb.NewConfigGroup()
+	group.Groups[config.OrdererGroupKey].Values[config.BFTConsentersKey] = &cb.ConfigValue{
+		Value: utils.MarshalOrPanic(&ab.BFTConsenters{Consenters: consenters}),
+	}
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+			Type:      int32(cb.HeaderType_CONFIG),
+			ChannelId: testChainID,
+		})},
+		Data: utils.MarshalOrPanic(&cb.ConfigEnvelope{Config: &cb.Config{ChannelGroup: group}}),
+	})}
+}
+
+func TestConsentersChangeRejected(t *testing.T) {
+	cl := newCluster(t, 4)
+	defer cl.halt()
+
+	changed := configEnvelope(cl.consenters[:3])
+	for i := range cl.chains {
+		_, err := cl.chains[i].applyFilters(changed)
+		assert.Error(t, err, "Consenter %d should reject a config which changes the consenters", i)
+	}
+
+	cl.chains[0].Enqueue(testEnvelope(0))
+	cl.chains[0].Enqueue(changed)
+	cl.chains[0].Enqueue(configEnvelope(cl.consenters))
+	cl.chains[0].Enqueue(testEnvelope(1))
+	cl.awaitEnvelopes(t, 3, 0, 1, 2, 3)
+	cl.assertAgreement(t, 0, 1, 2, 3)
+
+	for _, block := range cl.supports[0].written() {
+		assert.NotContains(t, block.Data.Data, utils.MarshalOrPanic(changed), "The config which changes the consenters should not be ordered")
+	}
+}
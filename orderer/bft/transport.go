@@ -0,0 +1,210 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bft
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/comm"
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	// sendQueueSize is the number of messages queued for a consenter before further messages are dropped
+	sendQueueSize = 1000
+
+	sendTimeout = 5 * time.Second
+)
+
+// Handler handles the messages that a Transport receives for a chain
+type Handler interface {
+	// Step passes a message to the chain, it must not block
+	Step(env *ab.BFTEnvelope)
+}
+
+// Transport carries the messages of the BFT consensus protocol among the consenters of the chains
+type Transport interface {
+	// Register routes the messages received for the given chain to the handler
+	Register(chainID string, handler Handler)
+
+	// Deregister stops routing the messages received for the given chain
+	Deregister(chainID string)
+
+	// Send sends a message to the consenter at the given endpoint, on a best effort basis
+	Send(endpoint string, env *ab.BFTEnvelope)
+}
+
+type router struct {
+	lock     sync.RWMutex
+	handlers map[string]Handler
+}
+
+func newRouter() router {
+	return router{handlers: make(map[string]Handler)}
+}
+
+func (r *router) Register(chainID string, handler Handler) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.handlers[chainID] = handler
+}
+
+func (r *router) Deregister(chainID string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.handlers, chainID)
+}
+
+func (r *router) route(env *ab.BFTEnvelope) bool {
+	r.lock.RLock()
+	handler, ok := r.handlers[env.Channel]
+	r.lock.RUnlock()
+	if !ok {
+		return false
+	}
+	handler.Step(env)
+	return true
+}
+
+// LocalNetwork connects the transports of consenters that run in the same process, such as in tests
+type LocalNetwork struct {
+	lock       sync.RWMutex
+	transports map[string]*localTransport
+}
+
+type localTransport struct {
+	router
+	network   *LocalNetwork
+	connected bool
+}
+
+// NewLocalNetwork creates a network without any consenter
+func NewLocalNetwork() *LocalNetwork {
+	return &LocalNetwork{transports: make(map[string]*localTransport)}
+}
+
+// Transport returns the transport of the consenter at the given endpoint, creating it if needed
+func (ln *LocalNetwork) Transport(endpoint string) Transport {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	lt, ok := ln.transports[endpoint]
+	if !ok {
+		lt = &localTransport{router: newRouter(), network: ln, connected: true}
+		ln.transports[endpoint] = lt
+	}
+	return lt
+}
+
+// Disconnect cuts the consenter at the given endpoint off the network,
+// the messages it sends and the messages sent to it are dropped
+func (ln *LocalNetwork) Disconnect(endpoint string) {
+	ln.setConnected(endpoint, false)
+}
+
+// Connect reconnects the consenter at the given endpoint to the network
+func (ln *LocalNetwork) Connect(endpoint string) {
+	ln.setConnected(endpoint, true)
+}
+
+func (ln *LocalNetwork) setConnected(endpoint string, connected bool) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+	if lt, ok := ln.transports[endpoint]; ok {
+		lt.connected = connected
+	}
+}
+
+func (lt *localTransport) Send(endpoint string, env *ab.BFTEnvelope) {
+	lt.network.lock.RLock()
+	dest, ok := lt.network.transports[endpoint]
+	connected := ok && lt.connected && dest.connected
+	lt.network.lock.RUnlock()
+	if !connected {
+		return
+	}
+	dest.route(proto.Clone(env).(*ab.BFTEnvelope))
+}
+
+// GRPCTransport carries the messages of the BFT consensus protocol over the BFT gRPC service,
+// it is registered as the BFT service of the orderer and dials the other consenters
+type GRPCTransport struct {
+	router
+	creds  credentials.TransportCredentials
+	lock   sync.Mutex
+	queues map[string]chan *ab.BFTEnvelope
+}
+
+// NewGRPCTransport creates a new GRPCTransport which dials the other consenters with the given
+// credentials, or without TLS if the credentials are nil
+func NewGRPCTransport(creds credentials.TransportCredentials) *GRPCTransport {
+	return &GRPCTransport{
+		router: newRouter(),
+		creds:  creds,
+		queues: make(map[string]chan *ab.BFTEnvelope),
+	}
+}
+
+// Step passes a message received from another consenter to its chain
+func (gt *GRPCTransport) Step(ctx context.Context, env *ab.BFTEnvelope) (*ab.BFTStepResponse, error) {
+	if !gt.route(env) {
+		return nil, fmt.Errorf("Unknown channel %s", env.Channel)
+	}
+	return &ab.BFTStepResponse{}, nil
+}
+
+// Send queues the message for the consenter at the given endpoint, the message is dropped if the queue is full
+func (gt *GRPCTransport) Send(endpoint string, env *ab.BFTEnvelope) {
+	gt.lock.Lock()
+	queue, ok := gt.queues[endpoint]
+	if !ok {
+		queue = make(chan *ab.BFTEnvelope, sendQueueSize)
+		gt.queues[endpoint] = queue
+		go gt.deliver(endpoint, queue)
+	}
+	gt.lock.Unlock()
+
+	select {
+	case queue <- env:
+	default:
+		logger.Warningf("Dropping message for %s on channel %s, the send queue is full", endpoint, env.Channel)
+	}
+}
+
+func (gt *GRPCTransport) deliver(endpoint string, queue chan *ab.BFTEnvelope) {
+	var client ab.BFTClient
+	for env := range queue {
+		if client == nil {
+			conn, err := comm.NewClientConnectionWithAddress(endpoint, false, gt.creds != nil, gt.creds)
+			if err != nil {
+				logger.Warningf("Failed connecting to %s: %s", endpoint, err)
+				continue
+			}
+			client = ab.NewBFTClient(conn)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		_, err := client.Step(ctx, env)
+		cancel()
+		if err != nil {
+			logger.Debugf("Failed sending message to %s on channel %s: %s", endpoint, env.Channel, err)
+		}
+	}
+}
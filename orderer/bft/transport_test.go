@@ -0,0 +1,81 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bft
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	ab "github.com/hyperledger/fabric/protos/orderer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type channelHandler chan *ab.BFTEnvelope
+
+func (ch channelHandler) Step(env *ab.BFTEnvelope) {
+	ch <- env
+}
+
+func TestLocalNetwork(t *testing.T) {
+	network := NewLocalNetwork()
+	sender := network.Transport("orderer0:7050")
+	handler := make(channelHandler, 1)
+	network.Transport("orderer1:7050").Register(testChainID, handler)
+
+	env := &ab.BFTEnvelope{Channel: testChainID, Payload: []byte("payload")}
+	sender.Send("orderer1:7050", env)
+	assert.Equal(t, env, <-handler)
+
+	network.Disconnect("orderer1:7050")
+	sender.Send("orderer1:7050", env)
+	assert.Len(t, handler, 0, "Messages to disconnected consenters should be dropped")
+
+	network.Connect("orderer1:7050")
+	sender.Send("orderer1:7050", &ab.BFTEnvelope{Channel: "otherchain"})
+	assert.Len(t, handler, 0, "Messages for other chains should not be routed to the handler")
+	sender.Send("orderer1:7050", env)
+	assert.Equal(t, env, <-handler)
+}
+
+func TestGRPCTransport(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	receiver := NewGRPCTransport(nil)
+	ab.RegisterBFTServer(server, receiver)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	handler := make(channelHandler, 1)
+	receiver.Register(testChainID, handler)
+
+	env := &ab.BFTEnvelope{Channel: testChainID, Payload: []byte("payload"), Signature: []byte("signature")}
+	NewGRPCTransport(nil).Send(lis.Addr().String(), env)
+	select {
+	case received := <-handler:
+		assert.Equal(t, env.Payload, received.Payload)
+		assert.Equal(t, env.Signature, received.Signature)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Message was not received")
+	}
+
+	receiver.Deregister(testChainID)
+	_, err = receiver.Step(nil, env)
+	assert.Error(t, err, "Messages for unknown chains should be rejected")
+}
@@ -59,6 +59,10 @@ type Support interface {
 
 	// Filters returns the set of broadcast filters for this chain
 	Filters() *filter.RuleSet
+
+	// IngressFilters returns the set of filters applied after the broadcast filters to the messages
+	// received by broadcast only, the returned committer is invoked once the message is enqueued
+	IngressFilters() *filter.RuleSet
 }
 
 type handlerImpl struct {
@@ -145,10 +149,19 @@ func (bh *handlerImpl) Handle(srv ab.AtomicBroadcast_BroadcastServer) error {
 			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
 		}
 
+		ingressCommitter, filterErr := support.IngressFilters().Apply(msg)
+
+		if filterErr != nil {
+			logger.Warningf("[channel: %s] Rejecting broadcast message because of ingress filter error: %s", chdr.ChannelId, filterErr)
+			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_BAD_REQUEST})
+		}
+
 		if !support.Enqueue(msg) {
 			return srv.Send(&ab.BroadcastResponse{Status: cb.Status_SERVICE_UNAVAILABLE})
 		}
 
+		ingressCommitter.Commit()
+
 		if logger.IsEnabledFor(logging.DEBUG) {
 			logger.Debugf("[channel: %s] Broadcast has successfully enqueued message of type %s", chdr.ChannelId, cb.HeaderType_name[chdr.Type])
 		}
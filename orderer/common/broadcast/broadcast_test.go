@@ -119,14 +119,22 @@ func (mm *mockSupportManager) Process(configTx *cb.Envelope) (*cb.Envelope, erro
 }
 
 type mockSupport struct {
-	filters       *filter.RuleSet
-	rejectEnqueue bool
+	filters        *filter.RuleSet
+	ingressFilters *filter.RuleSet
+	rejectEnqueue  bool
 }
 
 func (ms *mockSupport) Filters() *filter.RuleSet {
 	return ms.filters
 }
 
+func (ms *mockSupport) IngressFilters() *filter.RuleSet {
+	if ms.ingressFilters == nil {
+		return filter.NewRuleSet([]filter.Rule{filter.AcceptRule})
+	}
+	return ms.ingressFilters
+}
+
 // Enqueue sends a message for ordering
 func (ms *mockSupport) Enqueue(env *cb.Envelope) bool {
 	return !ms.rejectEnqueue
@@ -308,6 +316,51 @@ func TestRejected(t *testing.T) {
 	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status, "Should have rejected CONFIG_UPDATE")
 }
 
+type mockCommitter struct {
+	committed int
+}
+
+func (mc *mockCommitter) Commit()        { mc.committed++ }
+func (mc *mockCommitter) Isolated() bool { return false }
+
+type acceptOnceRule struct {
+	committer *mockCommitter
+}
+
+func (r *acceptOnceRule) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+	if r.committer.committed > 0 {
+		return filter.Reject, nil
+	}
+	return filter.Accept, r.committer
+}
+
+func TestIngressFilters(t *testing.T) {
+	mm, mSysChain := getMockSupportManager()
+	committer := &mockCommitter{}
+	mSysChain.ingressFilters = filter.NewRuleSet([]filter.Rule{&acceptOnceRule{committer: committer}})
+	bh := NewHandlerImpl(mm)
+	m := newMockB()
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	mSysChain.rejectEnqueue = true
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SERVICE_UNAVAILABLE, reply.Status)
+	assert.Equal(t, 0, committer.committed, "Ingress committer should not be invoked when the message is not enqueued")
+
+	go bh.Handle(m)
+	mSysChain.rejectEnqueue = false
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status)
+	assert.Equal(t, 1, committer.committed, "Ingress committer should be invoked once the message is enqueued")
+
+	m.recvChan <- makeMessage(systemChain, []byte("Some bytes"))
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status, "Should have rejected the message by ingress filter")
+}
+
 func TestBadStreamRecv(t *testing.T) {
 	bh := NewHandlerImpl(nil)
 	assert.Error(t, bh.Handle(&erroneousRecvMockB{}), "Should catch unexpected stream error")
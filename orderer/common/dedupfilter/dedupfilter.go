@@ -0,0 +1,222 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedupfilter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/op/go-logging"
+)
+
+var logger = logging.MustGetLogger("orderer/common/dedupfilter")
+
+// Filter rejects stale and duplicate messages received by broadcast, before they are ordered.
+// As its outcome depends on the local clock and on the transactions seen by this orderer, it must
+// not be applied when the messages are ordered, where every orderer has to reach the same outcome.
+type Filter struct {
+	window time.Duration
+	cache  *txIDCache
+	now    func() time.Time
+}
+
+// New creates a new deduplication filter, which rejects messages whose channel header timestamp
+// is further than the window from the current time, and endorser transactions whose ID was
+// received or ordered on the chain within the window. The IDs are kept in a cache of up to
+// cacheSize IDs, which is rebuilt from the blocks of the chain that fall within the window.
+func New(window time.Duration, cacheSize int, reader ledger.Reader) *Filter {
+	df := &Filter{
+		window: window,
+		cache:  newTxIDCache(cacheSize),
+		now:    time.Now,
+	}
+	df.rebuild(reader)
+	return df
+}
+
+// Apply rejects stale and duplicate messages, accepting endorser transactions along with a committer
+// that records their ID, which should be invoked once the message has been enqueued for ordering,
+// and forwarding other messages
+func (df *Filter) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+	chdr, err := channelHeader(message)
+	if err != nil {
+		logger.Debugf("Forwarding message without channel header: %s", err)
+		return filter.Forward, nil
+	}
+
+	if chdr.Timestamp != nil {
+		timestamp := time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos))
+		now := df.now()
+		if timestamp.Before(now.Add(-df.window)) || timestamp.After(now.Add(df.window)) {
+			logger.Warningf("Rejecting message with timestamp %s, which is outside the window of %s around %s", timestamp, df.window, now)
+			return filter.Reject, nil
+		}
+	}
+
+	txID, ok := endorserTxID(chdr)
+	if !ok {
+		return filter.Forward, nil
+	}
+	if df.cache.contains(txID) {
+		logger.Warningf("Rejecting duplicate transaction %s", txID)
+		return filter.Reject, nil
+	}
+	return filter.Accept, &txIDCommitter{cache: df.cache, txID: txID}
+}
+
+// OrderedRule returns a rule which records the IDs of the endorser transactions that are ordered,
+// including those received by other orderers, once they are written to a block. It accepts every
+// endorser transaction whatever the state of the cache, so that the outcome of the rules applied
+// when ordering does not depend on it, and it must therefore come last before the accept rule.
+func (df *Filter) OrderedRule() filter.Rule {
+	return &orderedRule{cache: df.cache}
+}
+
+type orderedRule struct {
+	cache *txIDCache
+}
+
+// Apply accepts endorser transactions along with a committer that records their ID,
+// and forwards other messages
+func (r *orderedRule) Apply(message *cb.Envelope) (filter.Action, filter.Committer) {
+	chdr, err := channelHeader(message)
+	if err != nil {
+		return filter.Forward, nil
+	}
+	txID, ok := endorserTxID(chdr)
+	if !ok {
+		return filter.Forward, nil
+	}
+	return filter.Accept, &txIDCommitter{cache: r.cache, txID: txID}
+}
+
+// rebuild adds the IDs of the endorser transactions of the blocks within the window to the cache,
+// reading the chain back from its last block
+func (df *Filter) rebuild(reader ledger.Reader) {
+	start := df.now().Add(-df.window)
+
+	var txIDs []string
+	for number := reader.Height(); number > 0 && len(txIDs) < df.cache.size; number-- {
+		block := ledger.GetBlock(reader, number-1)
+		if block == nil || block.Data == nil {
+			break
+		}
+
+		within := false
+		for _, data := range block.Data.Data {
+			env, err := utils.UnmarshalEnvelope(data)
+			if err != nil {
+				continue
+			}
+			chdr, err := channelHeader(env)
+			if err != nil || chdr.Timestamp == nil || time.Unix(chdr.Timestamp.Seconds, int64(chdr.Timestamp.Nanos)).Before(start) {
+				continue
+			}
+			within = true
+			if txID, ok := endorserTxID(chdr); ok {
+				txIDs = append(txIDs, txID)
+			}
+		}
+		if !within && len(block.Data.Data) > 0 {
+			break
+		}
+	}
+
+	// The blocks were read back from the last one, add the oldest transactions first
+	for i := len(txIDs) - 1; i >= 0; i-- {
+		df.cache.add(txIDs[i])
+	}
+	logger.Debugf("Rebuilt transaction ID cache with %d IDs", len(txIDs))
+}
+
+func channelHeader(message *cb.Envelope) (*cb.ChannelHeader, error) {
+	payload, err := utils.UnmarshalPayload(message.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Header == nil {
+		return nil, fmt.Errorf("Missing header")
+	}
+	return utils.UnmarshalChannelHeader(payload.Header.ChannelHeader)
+}
+
+func endorserTxID(chdr *cb.ChannelHeader) (string, bool) {
+	if chdr.Type != int32(cb.HeaderType_ENDORSER_TRANSACTION) || chdr.TxId == "" {
+		return "", false
+	}
+	return chdr.TxId, true
+}
+
+type txIDCommitter struct {
+	cache *txIDCache
+	txID  string
+}
+
+// Commit records the ID of the enqueued or ordered transaction
+func (tc *txIDCommitter) Commit() {
+	tc.cache.add(tc.txID)
+}
+
+// Isolated returns false, endorser transactions may be batched with others
+func (tc *txIDCommitter) Isolated() bool {
+	return false
+}
+
+// txIDCache holds up to size transaction IDs, evicting the oldest ones first
+type txIDCache struct {
+	lock sync.Mutex
+	size int
+	ids  map[string]struct{}
+	ring []string
+	next int
+}
+
+func newTxIDCache(size int) *txIDCache {
+	return &txIDCache{
+		size: size,
+		ids:  make(map[string]struct{}),
+		ring: make([]string, 0, size),
+	}
+}
+
+func (tc *txIDCache) contains(txID string) bool {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	_, ok := tc.ids[txID]
+	return ok
+}
+
+func (tc *txIDCache) add(txID string) {
+	tc.lock.Lock()
+	defer tc.lock.Unlock()
+	if _, ok := tc.ids[txID]; ok || tc.size <= 0 {
+		return
+	}
+	if len(tc.ring) < tc.size {
+		tc.ring = append(tc.ring, txID)
+	} else {
+		delete(tc.ids, tc.ring[tc.next])
+		tc.ring[tc.next] = txID
+		tc.next = (tc.next + 1) % tc.size
+	}
+	tc.ids[txID] = struct{}{}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dedupfilter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric/orderer/common/filter"
+	ordererledger "github.com/hyperledger/fabric/orderer/ledger"
+	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	cb "github.com/hyperledger/fabric/protos/common"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const window = 10 * time.Minute
+
+func makeMessage(headerType cb.HeaderType, txID string, ts time.Time) *cb.Envelope {
+	chdr := &cb.ChannelHeader{
+		Type:      int32(headerType),
+		ChannelId: "testchain",
+		TxId:      txID,
+		Timestamp: &timestamp.Timestamp{Seconds: ts.Unix(), Nanos: int32(ts.Nanosecond())},
+	}
+	return &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+		Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(chdr)},
+	})}
+}
+
+func newTestLedger() ordererledger.ReadWriter {
+	rl, _ := ramledger.New(100).GetOrCreate("testchain")
+	rl.Append(cb.NewBlock(0, nil))
+	return rl
+}
+
+func TestTimestampWindow(t *testing.T) {
+	now := time.Now()
+	df := New(window, 10, newTestLedger())
+	df.now = func() time.Time { return now }
+	rs := filter.NewRuleSet([]filter.Rule{df, filter.AcceptRule})
+
+	t.Run("Within", func(t *testing.T) {
+		_, err := rs.Apply(makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "within", now.Add(-window/2)))
+		assert.NoError(t, err)
+	})
+	t.Run("TooOld", func(t *testing.T) {
+		_, err := rs.Apply(makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "tooold", now.Add(-window-time.Second)))
+		assert.Error(t, err)
+	})
+	t.Run("TooNew", func(t *testing.T) {
+		_, err := rs.Apply(makeMessage(cb.HeaderType_CONFIG_UPDATE, "", now.Add(window+time.Second)))
+		assert.Error(t, err)
+	})
+	t.Run("NoTimestamp", func(t *testing.T) {
+		msg := &cb.Envelope{Payload: utils.MarshalOrPanic(&cb.Payload{
+			Header: &cb.Header{ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{Type: int32(cb.HeaderType_CONFIG_UPDATE)})},
+		})}
+		action, _ := df.Apply(msg)
+		assert.EqualValues(t, filter.Forward, action, "Messages without timestamp should be forwarded")
+	})
+	t.Run("NoHeader", func(t *testing.T) {
+		action, _ := df.Apply(&cb.Envelope{Payload: []byte("garbage")})
+		assert.EqualValues(t, filter.Forward, action, "Malformed messages should be forwarded")
+	})
+}
+
+func TestDuplicateTxID(t *testing.T) {
+	df := New(window, 10, newTestLedger())
+
+	msg := makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "tx", time.Now())
+	action, committer := df.Apply(msg)
+	assert.EqualValues(t, filter.Accept, action)
+	assert.False(t, committer.Isolated())
+
+	action, _ = df.Apply(msg)
+	assert.EqualValues(t, filter.Accept, action, "Transactions should not be rejected before they are enqueued")
+
+	committer.Commit()
+	action, _ = df.Apply(msg)
+	assert.EqualValues(t, filter.Reject, action, "Transactions should be rejected once they are enqueued")
+
+	action, _ = df.Apply(makeMessage(cb.HeaderType_CONFIG, "tx", time.Now()))
+	assert.EqualValues(t, filter.Forward, action, "Only endorser transactions should be checked for duplicates")
+}
+
+func TestOrderedRule(t *testing.T) {
+	df := New(window, 10, newTestLedger())
+	ordered := df.OrderedRule()
+
+	stale := makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "stale", time.Now().Add(-2*window))
+	var committer filter.Committer
+	for i := 0; i < 2; i++ {
+		var action filter.Action
+		action, committer = ordered.Apply(stale)
+		assert.EqualValues(t, filter.Accept, action, "Ordered transactions should always be accepted")
+	}
+
+	action, _ := df.Apply(makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "stale", time.Now()))
+	assert.EqualValues(t, filter.Accept, action, "Transactions should not be recorded before they are written to a block")
+
+	committer.Commit()
+	action, _ = df.Apply(makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "stale", time.Now()))
+	assert.EqualValues(t, filter.Reject, action, "Transactions ordered by other orderers should be rejected")
+
+	action, _ = ordered.Apply(makeMessage(cb.HeaderType_CONFIG, "config", time.Now()))
+	assert.EqualValues(t, filter.Forward, action, "Other messages should be forwarded")
+
+	action, _ = ordered.Apply(&cb.Envelope{Payload: []byte("garbage")})
+	assert.EqualValues(t, filter.Forward, action, "Malformed messages should be forwarded")
+}
+
+func TestCacheEviction(t *testing.T) {
+	cache := newTxIDCache(3)
+	for i := 0; i < 5; i++ {
+		cache.add(fmt.Sprintf("tx%d", i))
+	}
+	cache.add("tx4")
+
+	for i := 0; i < 2; i++ {
+		assert.False(t, cache.contains(fmt.Sprintf("tx%d", i)), "tx%d should have been evicted", i)
+	}
+	for i := 2; i < 5; i++ {
+		assert.True(t, cache.contains(fmt.Sprintf("tx%d", i)), "tx%d should be cached", i)
+	}
+	assert.Len(t, cache.ids, 3)
+}
+
+func TestRebuild(t *testing.T) {
+	rl := newTestLedger()
+	now := time.Now()
+	blocks := [][]*cb.Envelope{
+		{makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "old", now.Add(-2*window))},
+		{makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "tx1", now.Add(-window/2)), makeMessage(cb.HeaderType_CONFIG, "config", now)},
+		{makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "tx2", now), makeMessage(cb.HeaderType_ENDORSER_TRANSACTION, "tx3", now)},
+	}
+	for _, envs := range blocks {
+		rl.Append(ordererledger.CreateNextBlock(rl, envs))
+	}
+
+	df := New(window, 10, rl)
+	for _, txID := range []string{"tx1", "tx2", "tx3"} {
+		assert.True(t, df.cache.contains(txID), "%s should have been read back from the ledger", txID)
+	}
+	assert.False(t, df.cache.contains("old"), "Transactions before the window should not be read back")
+	assert.False(t, df.cache.contains("config"), "Only endorser transactions should be cached")
+
+	df = New(window, 2, rl)
+	assert.True(t, df.cache.contains("tx2"))
+	assert.True(t, df.cache.contains("tx3"))
+	assert.False(t, df.cache.contains("tx1"), "The cache should hold the latest transactions")
+}
@@ -64,6 +64,7 @@ type TopLevel struct {
 	FileLedger FileLedger
 	RAMLedger  RAMLedger
 	Kafka      Kafka
+	BFT        BFT
 }
 
 // General contains config which should be common among all orderer types.
@@ -76,6 +77,7 @@ type General struct {
 	GenesisProfile string
 	GenesisFile    string
 	Profile        Profile
+	Dedup          Dedup
 	LogLevel       string
 	LogFormat      string
 	LocalMSPDir    string
@@ -99,6 +101,13 @@ type Profile struct {
 	Address string
 }
 
+// Dedup contains configuration for the filter which rejects stale and duplicate transactions.
+type Dedup struct {
+	Enabled    bool
+	TimeWindow time.Duration
+	CacheSize  int
+}
+
 // FileLedger contains configuration for the file-based ledger.
 type FileLedger struct {
 	Location string
@@ -161,6 +170,12 @@ type Consumer struct {
 	RetryBackoff time.Duration
 }
 
+// BFT contains configuration for the byzantine fault tolerant orderer.
+type BFT struct {
+	RequestTimeout    time.Duration
+	ViewChangeTimeout time.Duration
+}
+
 var defaults = TopLevel{
 	General: General{
 		LedgerType:     "file",
@@ -173,6 +188,11 @@ var defaults = TopLevel{
 			Enabled: false,
 			Address: "0.0.0.0:6060",
 		},
+		Dedup: Dedup{
+			Enabled:    false,
+			TimeWindow: 15 * time.Minute,
+			CacheSize:  100000,
+		},
 		LogLevel:    "INFO",
 		LogFormat:   "%{color}%{time:2006-01-02 15:04:05.000 MST} [%{module}] %{shortfunc} -> %{level:.4s} %{id:03x}%{color:reset} %{message}",
 		LocalMSPDir: "msp",
@@ -215,6 +235,10 @@ var defaults = TopLevel{
 			Enabled: false,
 		},
 	},
+	BFT: BFT{
+		RequestTimeout:    10 * time.Second,
+		ViewChangeTimeout: 20 * time.Second,
+	},
 }
 
 // Load parses the orderer.yaml file and environment, producing a struct suitable for config use
@@ -293,6 +317,13 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			logger.Infof("Profiling enabled and General.Profile.Address unset, setting to %s", defaults.General.Profile.Address)
 			c.General.Profile.Address = defaults.General.Profile.Address
 
+		case c.General.Dedup.Enabled && c.General.Dedup.TimeWindow == 0*time.Second:
+			logger.Infof("Dedup enabled and General.Dedup.TimeWindow unset, setting to %v", defaults.General.Dedup.TimeWindow)
+			c.General.Dedup.TimeWindow = defaults.General.Dedup.TimeWindow
+		case c.General.Dedup.Enabled && c.General.Dedup.CacheSize == 0:
+			logger.Infof("Dedup enabled and General.Dedup.CacheSize unset, setting to %d", defaults.General.Dedup.CacheSize)
+			c.General.Dedup.CacheSize = defaults.General.Dedup.CacheSize
+
 		case c.General.LocalMSPDir == "":
 			logger.Infof("General.LocalMSPDir unset, setting to %s", defaults.General.LocalMSPDir)
 			c.General.LocalMSPDir = defaults.General.LocalMSPDir
@@ -349,6 +380,13 @@ func (c *TopLevel) completeInitialization(configDir string) {
 			logger.Infof("Kafka.Version unset, setting to %v", defaults.Kafka.Version)
 			c.Kafka.Version = defaults.Kafka.Version
 
+		case c.BFT.RequestTimeout == 0*time.Second:
+			logger.Infof("BFT.RequestTimeout unset, setting to %v", defaults.BFT.RequestTimeout)
+			c.BFT.RequestTimeout = defaults.BFT.RequestTimeout
+		case c.BFT.ViewChangeTimeout == 0*time.Second:
+			logger.Infof("BFT.ViewChangeTimeout unset, setting to %v", defaults.BFT.ViewChangeTimeout)
+			c.BFT.ViewChangeTimeout = defaults.BFT.ViewChangeTimeout
+
 		default:
 			return
 		}
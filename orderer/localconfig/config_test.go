@@ -116,3 +116,10 @@ func TestProfileConfig(t *testing.T) {
 	uconf.completeInitialization(DummyPath)
 	assert.Equal(t, defaults.General.Profile.Address, uconf.General.Profile.Address, "Expected profile address to be filled with default value")
 }
+
+func TestDedupConfig(t *testing.T) {
+	uconf := &TopLevel{General: General{Dedup: Dedup{Enabled: true}}}
+	uconf.completeInitialization(DummyPath)
+	assert.Equal(t, defaults.General.Dedup.TimeWindow, uconf.General.Dedup.TimeWindow, "Expected dedup time window to be filled with default value")
+	assert.Equal(t, defaults.General.Dedup.CacheSize, uconf.General.Dedup.CacheSize, "Expected dedup cache size to be filled with default value")
+}
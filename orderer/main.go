@@ -17,6 +17,8 @@ limitations under the License.
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -30,6 +32,7 @@ import (
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/flogging"
 	"github.com/hyperledger/fabric/core/comm"
+	"github.com/hyperledger/fabric/orderer/bft"
 	"github.com/hyperledger/fabric/orderer/common/bootstrap/file"
 	"github.com/hyperledger/fabric/orderer/kafka"
 	"github.com/hyperledger/fabric/orderer/ledger"
@@ -45,6 +48,7 @@ import (
 	"github.com/hyperledger/fabric/common/localmsp"
 	mspmgmt "github.com/hyperledger/fabric/msp/mgmt"
 	logging "github.com/op/go-logging"
+	"google.golang.org/grpc/credentials"
 	"gopkg.in/alecthomas/kingpin.v2"
 )
 
@@ -72,9 +76,11 @@ func main() {
 		grpcServer := initializeGrpcServer(conf)
 		initializeLocalMsp(conf)
 		signer := localmsp.NewSigner()
-		manager := initializeMultiChainManager(conf, signer)
+		bftTransport := initializeBFTTransport(conf)
+		manager := initializeMultiChainManager(conf, signer, bftTransport)
 		server := NewServer(manager, signer)
 		ab.RegisterAtomicBroadcastServer(grpcServer.Server(), server)
+		ab.RegisterBFTServer(grpcServer.Server(), bftTransport)
 		logger.Info("Beginning to serve requests")
 		grpcServer.Start()
 	// "version" command
@@ -204,7 +210,35 @@ func initializeLocalMsp(conf *config.TopLevel) {
 	}
 }
 
-func initializeMultiChainManager(conf *config.TopLevel, signer crypto.LocalSigner) multichain.Manager {
+// initializeBFTTransport creates the transport among the BFT consenters, which dials the other
+// consenters with the TLS settings of the orderer
+func initializeBFTTransport(conf *config.TopLevel) *bft.GRPCTransport {
+	if !conf.General.TLS.Enabled {
+		return bft.NewGRPCTransport(nil)
+	}
+
+	certPool := x509.NewCertPool()
+	for _, rootCA := range conf.General.TLS.RootCAs {
+		root, err := ioutil.ReadFile(rootCA)
+		if err != nil {
+			logger.Fatalf("Failed to load ServerRootCAs file '%s' (%s)", rootCA, err)
+		}
+		if !certPool.AppendCertsFromPEM(root) {
+			logger.Fatalf("Failed to parse ServerRootCAs file '%s'", rootCA)
+		}
+	}
+	tlsConfig := &tls.Config{RootCAs: certPool}
+	if conf.General.TLS.ClientAuthEnabled {
+		cert, err := tls.LoadX509KeyPair(conf.General.TLS.Certificate, conf.General.TLS.PrivateKey)
+		if err != nil {
+			logger.Fatalf("Failed to load the TLS key pair of the orderer (%s)", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return bft.NewGRPCTransport(credentials.NewTLS(tlsConfig))
+}
+
+func initializeMultiChainManager(conf *config.TopLevel, signer crypto.LocalSigner, bftTransport bft.Transport) multichain.Manager {
 	lf, _ := createLedgerFactory(conf)
 	// Are we bootstrapping?
 	if len(lf.ChainIDs()) == 0 {
@@ -216,6 +250,7 @@ func initializeMultiChainManager(conf *config.TopLevel, signer crypto.LocalSigne
 	consenters := make(map[string]multichain.Consenter)
 	consenters["solo"] = solo.New()
 	consenters["kafka"] = kafka.New(conf.Kafka.TLS, conf.Kafka.Retry, conf.Kafka.Version)
+	consenters["bft"] = bft.New(conf.BFT, bftTransport)
 
-	return multichain.NewManagerImpl(lf, consenters, signer)
+	return multichain.NewManagerImpl(lf, consenters, signer, conf.General.Dedup)
 }
@@ -240,7 +240,7 @@ func TestInitializeMultiChainManager(t *testing.T) {
 	}
 	assert.NotPanics(t, func() {
 		initializeLocalMsp(conf)
-		initializeMultiChainManager(conf, localmsp.NewSigner())
+		initializeMultiChainManager(conf, localmsp.NewSigner(), initializeBFTTransport(conf))
 	})
 }
 
@@ -19,8 +19,10 @@ package multichain
 import (
 	"github.com/hyperledger/fabric/common/config"
 	mockconfig "github.com/hyperledger/fabric/common/mocks/config"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
 	"github.com/hyperledger/fabric/orderer/common/filter"
+	"github.com/hyperledger/fabric/orderer/ledger"
 	mockblockcutter "github.com/hyperledger/fabric/orderer/mocks/blockcutter"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -50,6 +52,15 @@ type ConsenterSupport struct {
 
 	// NextBlockVal stores the block created by the most recent CreateNextBlock() call
 	NextBlockVal *cb.Block
+
+	// ReaderVal is the value returned by Reader()
+	ReaderVal ledger.Reader
+
+	// FiltersVal is the value returned by Filters()
+	FiltersVal *filter.RuleSet
+
+	// MSPManagerVal is the value returned by MSPManager()
+	MSPManagerVal msp.MSPManager
 }
 
 // BlockCutter returns BlockCutterVal
@@ -95,6 +106,21 @@ func (mcs *ConsenterSupport) Height() uint64 {
 	return mcs.HeightVal
 }
 
+// Reader returns ReaderVal
+func (mcs *ConsenterSupport) Reader() ledger.Reader {
+	return mcs.ReaderVal
+}
+
+// Filters returns FiltersVal
+func (mcs *ConsenterSupport) Filters() *filter.RuleSet {
+	return mcs.FiltersVal
+}
+
+// MSPManager returns MSPManagerVal
+func (mcs *ConsenterSupport) MSPManager() msp.MSPManager {
+	return mcs.MSPManagerVal
+}
+
 // Sign returns the bytes passed in
 func (mcs *ConsenterSupport) Sign(message []byte) ([]byte, error) {
 	return message, nil
@@ -21,13 +21,15 @@ import (
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/common/util"
+	"github.com/hyperledger/fabric/msp"
 	"github.com/hyperledger/fabric/orderer/common/blockcutter"
-	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/common/configtxfilter"
+	"github.com/hyperledger/fabric/orderer/common/dedupfilter"
 	"github.com/hyperledger/fabric/orderer/common/filter"
 	"github.com/hyperledger/fabric/orderer/common/sigfilter"
 	"github.com/hyperledger/fabric/orderer/common/sizefilter"
 	"github.com/hyperledger/fabric/orderer/ledger"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 )
@@ -73,9 +75,22 @@ type ConsenterSupport interface {
 	BlockCutter() blockcutter.Receiver
 	SharedConfig() config.Orderer
 	CreateNextBlock(messages []*cb.Envelope) *cb.Block
+	// WriteBlock commits the block to the ledger after signing it, unless the block already
+	// carries signatures, such as those of a quorum of consenters that agreed on it
 	WriteBlock(block *cb.Block, committers []filter.Committer, encodedMetadataValue []byte) *cb.Block
 	ChainID() string // ChainID returns the chain ID this specific consenter instance is associated with
 	Height() uint64  // Returns the number of blocks on the chain this specific consenter instance is associated with
+
+	// Reader returns the chain Reader for the chain
+	Reader() ledger.Reader
+
+	// Filters returns the set of broadcast filters for this chain, for consenters
+	// that have to validate messages ordered by other consenters
+	Filters() *filter.RuleSet
+
+	// MSPManager returns the MSP manager of the chain, for consenters
+	// that have to verify the signatures of other consenters
+	MSPManager() msp.MSPManager
 }
 
 // ChainSupport provides a wrapper for the resources backing a chain
@@ -86,13 +101,17 @@ type ChainSupport interface {
 	// PolicyManager returns the current policy manager as specified by the chain config
 	PolicyManager() policies.Manager
 
-	// Reader returns the chain Reader for the chain
-	Reader() ledger.Reader
-
 	// Errored returns whether the backing consenter has errored
 	Errored() <-chan struct{}
 
-	broadcast.Support
+	// Enqueue accepts a message and returns true on acceptance, or false on shutdown.
+	// Along with the Filters of the ConsenterSupport, it makes up the broadcast.Support
+	Enqueue(env *cb.Envelope) bool
+
+	// IngressFilters returns the set of filters applied to the messages received by broadcast
+	// only, which are not applied again when the messages are ordered
+	IngressFilters() *filter.RuleSet
+
 	ConsenterSupport
 
 	// Sequence returns the current config sequence number
@@ -104,16 +123,18 @@ type ChainSupport interface {
 
 type chainSupport struct {
 	*ledgerResources
-	chain         Chain
-	cutter        blockcutter.Receiver
-	filters       *filter.RuleSet
-	signer        crypto.LocalSigner
-	lastConfig    uint64
-	lastConfigSeq uint64
+	chain          Chain
+	cutter         blockcutter.Receiver
+	filters        *filter.RuleSet
+	ingressFilters *filter.RuleSet
+	signer         crypto.LocalSigner
+	lastConfig     uint64
+	lastConfigSeq  uint64
 }
 
 func newChainSupport(
 	filters *filter.RuleSet,
+	ingressFilters *filter.RuleSet,
 	ledgerResources *ledgerResources,
 	consenters map[string]Consenter,
 	signer crypto.LocalSigner,
@@ -130,6 +151,7 @@ func newChainSupport(
 		ledgerResources: ledgerResources,
 		cutter:          cutter,
 		filters:         filters,
+		ingressFilters:  ingressFilters,
 		signer:          signer,
 	}
 
@@ -164,28 +186,42 @@ func newChainSupport(
 	return cs
 }
 
-// createStandardFilters creates the set of filters for a normal (non-system) chain
-func createStandardFilters(ledgerResources *ledgerResources) *filter.RuleSet {
-	return filter.NewRuleSet([]filter.Rule{
+// createStandardFilters creates the set of filters for a normal (non-system) chain, along with
+// the set of filters applied only to the messages received by broadcast
+func createStandardFilters(ledgerResources *ledgerResources, dedup localconfig.Dedup) (*filter.RuleSet, *filter.RuleSet) {
+	return withDedupRules([]filter.Rule{
 		filter.EmptyRejectRule,
 		sizefilter.MaxBytesRule(ledgerResources.SharedConfig()),
 		sigfilter.New(policies.ChannelWriters, ledgerResources.PolicyManager()),
 		configtxfilter.NewFilter(ledgerResources),
-		filter.AcceptRule,
-	})
+	}, ledgerResources, dedup)
 
 }
 
-// createSystemChainFilters creates the set of filters for the ordering system chain
-func createSystemChainFilters(ml *multiLedger, ledgerResources *ledgerResources) *filter.RuleSet {
-	return filter.NewRuleSet([]filter.Rule{
+// createSystemChainFilters creates the set of filters for the ordering system chain, along with
+// the set of filters applied only to the messages received by broadcast
+func createSystemChainFilters(ml *multiLedger, ledgerResources *ledgerResources) (*filter.RuleSet, *filter.RuleSet) {
+	return withDedupRules([]filter.Rule{
 		filter.EmptyRejectRule,
 		sizefilter.MaxBytesRule(ledgerResources.SharedConfig()),
 		sigfilter.New(policies.ChannelWriters, ledgerResources.PolicyManager()),
 		newSystemChainFilter(ledgerResources, ml),
 		configtxfilter.NewFilter(ledgerResources),
-		filter.AcceptRule,
-	})
+	}, ledgerResources, ml.dedup)
+}
+
+// withDedupRules terminates the given rules with the accept rule and returns them along with the
+// ingress rules. When enabled, the stale and duplicate transaction filter is an ingress rule, as
+// its outcome depends on the local clock, and the given rules only record the ordered transactions
+// once their block is written
+func withDedupRules(rules []filter.Rule, ledgerResources *ledgerResources, dedup localconfig.Dedup) (*filter.RuleSet, *filter.RuleSet) {
+	var ingressRules []filter.Rule
+	if dedup.Enabled {
+		dedupFilter := dedupfilter.New(dedup.TimeWindow, dedup.CacheSize, ledgerResources.ledger)
+		rules = append(rules, dedupFilter.OrderedRule())
+		ingressRules = append(ingressRules, dedupFilter)
+	}
+	return filter.NewRuleSet(append(rules, filter.AcceptRule)), filter.NewRuleSet(append(ingressRules, filter.AcceptRule))
 }
 
 func (cs *chainSupport) start() {
@@ -204,6 +240,10 @@ func (cs *chainSupport) Filters() *filter.RuleSet {
 	return cs.filters
 }
 
+func (cs *chainSupport) IngressFilters() *filter.RuleSet {
+	return cs.ingressFilters
+}
+
 func (cs *chainSupport) BlockCutter() blockcutter.Receiver {
 	return cs.cutter
 }
@@ -279,7 +319,9 @@ func (cs *chainSupport) WriteBlock(block *cb.Block, committers []filter.Committe
 	if encodedMetadataValue != nil {
 		block.Metadata.Metadata[cb.BlockMetadataIndex_ORDERER] = utils.MarshalOrPanic(&cb.Metadata{Value: encodedMetadataValue})
 	}
-	cs.addBlockSignature(block)
+	if len(block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES]) == 0 {
+		cs.addBlockSignature(block)
+	}
 	cs.addLastConfigSignature(block)
 
 	err := cs.ledger.Append(block)
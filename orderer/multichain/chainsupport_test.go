@@ -96,6 +96,19 @@ func TestWriteBlockSignatures(t *testing.T) {
 	assert.NotNil(t, actual, "Block should have block signature")
 }
 
+func TestWriteBlockKeepsSignatures(t *testing.T) {
+	ml := &mockLedgerReadWriter{}
+	cm := &mockconfigtx.Manager{}
+	cs := &chainSupport{ledgerResources: &ledgerResources{configResources: &configResources{Manager: cm}, ledger: ml}, signer: mockCrypto()}
+
+	expected := &cb.Metadata{Signatures: []*cb.MetadataSignature{{SignatureHeader: []byte("header"), Signature: []byte("signature")}}}
+	block := cb.NewBlock(0, nil)
+	block.Metadata.Metadata[cb.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(expected)
+
+	actual := utils.GetMetadataFromBlockOrPanic(cs.WriteBlock(block, nil, nil), cb.BlockMetadataIndex_SIGNATURES)
+	assert.True(t, proto.Equal(expected, actual), "Existing block signatures should not be replaced")
+}
+
 func TestWriteBlockOrdererMetadata(t *testing.T) {
 	ml := &mockLedgerReadWriter{}
 	cm := &mockconfigtx.Manager{}
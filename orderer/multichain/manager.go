@@ -13,6 +13,7 @@ import (
 	configtxapi "github.com/hyperledger/fabric/common/configtx/api"
 	"github.com/hyperledger/fabric/common/policies"
 	"github.com/hyperledger/fabric/orderer/ledger"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/op/go-logging"
@@ -65,6 +66,7 @@ type multiLedger struct {
 	signer          crypto.LocalSigner
 	systemChannelID string
 	systemChannel   *chainSupport
+	dedup           localconfig.Dedup
 }
 
 func getConfigTx(reader ledger.Reader) *cb.Envelope {
@@ -81,13 +83,15 @@ func getConfigTx(reader ledger.Reader) *cb.Envelope {
 	return utils.ExtractEnvelopeOrPanic(configBlock, 0)
 }
 
-// NewManagerImpl produces an instance of a Manager
-func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consenter, signer crypto.LocalSigner) Manager {
+// NewManagerImpl produces an instance of a Manager, the dedup configuration
+// controls whether stale and duplicate transactions are filtered on every chain
+func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consenter, signer crypto.LocalSigner, dedup localconfig.Dedup) Manager {
 	ml := &multiLedger{
 		chains:        make(map[string]*chainSupport),
 		ledgerFactory: ledgerFactory,
 		consenters:    consenters,
 		signer:        signer,
+		dedup:         dedup,
 	}
 
 	existingChains := ledgerFactory.ChainIDs()
@@ -107,7 +111,9 @@ func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consente
 			if ml.systemChannelID != "" {
 				logger.Panicf("There appear to be two system chains %s and %s", ml.systemChannelID, chainID)
 			}
-			chain := newChainSupport(createSystemChainFilters(ml, ledgerResources),
+			filters, ingressFilters := createSystemChainFilters(ml, ledgerResources)
+			chain := newChainSupport(filters,
+				ingressFilters,
 				ledgerResources,
 				consenters,
 				signer)
@@ -119,7 +125,9 @@ func NewManagerImpl(ledgerFactory ledger.Factory, consenters map[string]Consente
 			defer chain.start()
 		} else {
 			logger.Debugf("Starting chain: %s", chainID)
-			chain := newChainSupport(createStandardFilters(ledgerResources),
+			filters, ingressFilters := createStandardFilters(ledgerResources, ml.dedup)
+			chain := newChainSupport(filters,
+				ingressFilters,
 				ledgerResources,
 				consenters,
 				signer)
@@ -176,7 +184,8 @@ func (ml *multiLedger) newChain(configtx *cb.Envelope) {
 		newChains[key] = value
 	}
 
-	cs := newChainSupport(createStandardFilters(ledgerResources), ledgerResources, ml.consenters, ml.signer)
+	filters, ingressFilters := createStandardFilters(ledgerResources, ml.dedup)
+	cs := newChainSupport(filters, ingressFilters, ledgerResources, ml.consenters, ml.signer)
 	chainID := ledgerResources.ChainID()
 
 	logger.Infof("Created and starting new chain %s", chainID)
@@ -8,19 +8,23 @@ package multichain
 
 import (
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/hyperledger/fabric/common/config"
 	"github.com/hyperledger/fabric/common/configtx"
 	genesisconfig "github.com/hyperledger/fabric/common/configtx/tool/localconfig"
 	"github.com/hyperledger/fabric/common/configtx/tool/provisional"
 	mockcrypto "github.com/hyperledger/fabric/common/mocks/crypto"
 	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/orderer/common/broadcast"
 	"github.com/hyperledger/fabric/orderer/ledger"
 	ramledger "github.com/hyperledger/fabric/orderer/ledger/ram"
+	localconfig "github.com/hyperledger/fabric/orderer/localconfig"
 	cb "github.com/hyperledger/fabric/protos/common"
 	ab "github.com/hyperledger/fabric/protos/orderer"
 	"github.com/hyperledger/fabric/protos/utils"
@@ -28,6 +32,7 @@ import (
 	mmsp "github.com/hyperledger/fabric/common/mocks/msp"
 	logging "github.com/op/go-logging"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 )
 
 var conf, singleMSPConf, noConsortiumConf *genesisconfig.Profile
@@ -136,7 +141,7 @@ func TestNoSystemChain(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto()) }, "Should have panicked when starting without a system chain")
+	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{}) }, "Should have panicked when starting without a system chain")
 }
 
 // This test checks to make sure that the orderer refuses to come up if there are multiple system channels
@@ -154,7 +159,7 @@ func TestMultiSystemChannel(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto()) }, "Two system channels should have caused panic")
+	assert.Panics(t, func() { NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{}) }, "Two system channels should have caused panic")
 }
 
 // This test checks to make sure that the orderer creates different type of filters given different type of channel
@@ -182,7 +187,7 @@ func TestFilterCreation(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{})
 
 	_, ok := manager.GetChain(provisional.TestChainID)
 	assert.True(t, ok, "Should have found chain: %d", provisional.TestChainID)
@@ -232,7 +237,7 @@ func TestManagerImpl(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{})
 
 	_, ok := manager.GetChain("Fake")
 	assert.False(t, ok, "Should not have found a chain that was not created")
@@ -262,12 +267,108 @@ func TestManagerImpl(t *testing.T) {
 	}
 }
 
+type mockBroadcastSupport struct {
+	Manager
+}
+
+func (mbs mockBroadcastSupport) GetChain(chainID string) (broadcast.Support, bool) {
+	return mbs.Manager.GetChain(chainID)
+}
+
+func (mbs mockBroadcastSupport) Process(configTx *cb.Envelope) (*cb.Envelope, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+type mockBroadcastStream struct {
+	grpc.ServerStream
+	recvChan chan *cb.Envelope
+	sendChan chan *ab.BroadcastResponse
+}
+
+func (m *mockBroadcastStream) Send(br *ab.BroadcastResponse) error {
+	m.sendChan <- br
+	return nil
+}
+
+func (m *mockBroadcastStream) Recv() (*cb.Envelope, error) {
+	msg, ok := <-m.recvChan
+	if !ok {
+		return nil, io.EOF
+	}
+	return msg, nil
+}
+
+func makeEndorserTx(chainID string, txID string) *cb.Envelope {
+	now := time.Now()
+	payload := &cb.Payload{
+		Header: &cb.Header{
+			ChannelHeader: utils.MarshalOrPanic(&cb.ChannelHeader{
+				Type:      int32(cb.HeaderType_ENDORSER_TRANSACTION),
+				ChannelId: chainID,
+				TxId:      txID,
+				Timestamp: &timestamp.Timestamp{Seconds: now.Unix(), Nanos: int32(now.Nanosecond())},
+			}),
+			SignatureHeader: utils.MarshalOrPanic(&cb.SignatureHeader{}),
+		},
+		Data: []byte(txID),
+	}
+	return &cb.Envelope{
+		Payload: utils.MarshalOrPanic(payload),
+	}
+}
+
+// Broadcasts transactions through the filters of the chain support, with the stale and duplicate transaction filter enabled
+func TestBroadcastDedup(t *testing.T) {
+	lf, rl := NewRAMLedgerAndFactory(10)
+
+	consenters := make(map[string]Consenter)
+	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
+
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{Enabled: true, TimeWindow: time.Minute, CacheSize: 100})
+	chainSupport, _ := manager.GetChain(provisional.TestChainID)
+
+	bh := broadcast.NewHandlerImpl(mockBroadcastSupport{Manager: manager})
+	m := &mockBroadcastStream{recvChan: make(chan *cb.Envelope), sendChan: make(chan *ab.BroadcastResponse)}
+	defer close(m.recvChan)
+	go bh.Handle(m)
+
+	m.recvChan <- makeEndorserTx(provisional.TestChainID, "tx")
+	reply := <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status, "The first copy of a transaction should be accepted")
+
+	m.recvChan <- makeEndorserTx(provisional.TestChainID, "tx")
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status, "A transaction should be rejected once enqueued")
+	go bh.Handle(m)
+
+	// The other transactions of the block are ordered as if they were received by other orderers
+	for i := 1; i < int(conf.Orderer.BatchSize.MaxMessageCount); i++ {
+		chainSupport.Enqueue(makeEndorserTx(provisional.TestChainID, fmt.Sprintf("ordered%d", i)))
+	}
+
+	it, _ := rl.Iterator(&ab.SeekPosition{Type: &ab.SeekPosition_Specified{Specified: &ab.SeekSpecified{Number: 1}}})
+	select {
+	case <-it.ReadyChan():
+	case <-time.After(time.Second):
+		t.Fatalf("Block 1 not produced after timeout")
+	}
+
+	m.recvChan <- makeEndorserTx(provisional.TestChainID, "ordered1")
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_BAD_REQUEST, reply.Status, "A transaction should be rejected once ordered")
+	go bh.Handle(m)
+
+	m.recvChan <- makeEndorserTx(provisional.TestChainID, "fresh")
+	reply = <-m.sendChan
+	assert.Equal(t, cb.Status_SUCCESS, reply.Status, "A new transaction should be accepted")
+}
+
 func TestNewChannelConfig(t *testing.T) {
 	lf, _ := NewRAMLedgerAndFactoryWithMSP()
 
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{})
 
 	t.Run("BadPayload", func(t *testing.T) {
 		_, err := manager.NewChannelConfig(&cb.Envelope{Payload: []byte("bad payload")})
@@ -518,7 +619,7 @@ func TestMismatchedChannelIDs(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{})
 
 	_, err = manager.NewChannelConfig(createTx)
 	assert.Error(t, err, "Mismatched channel IDs")
@@ -536,7 +637,7 @@ func TestNewChain(t *testing.T) {
 	consenters := make(map[string]Consenter)
 	consenters[conf.Orderer.OrdererType] = &mockConsenter{}
 
-	manager := NewManagerImpl(lf, consenters, mockCrypto())
+	manager := NewManagerImpl(lf, consenters, mockCrypto(), localconfig.Dedup{})
 
 	envConfigUpdate, err := configtx.MakeChainCreationTransaction(newChainID, genesisconfig.SampleConsortiumName, mockSigningIdentity)
 	assert.NoError(t, err, "Constructing chain creation tx")
@@ -628,7 +729,7 @@ func TestNewChain(t *testing.T) {
 func testRestartedChainSupport(t *testing.T, cs ChainSupport, consenters map[string]Consenter, expectedLastConfigSeq uint64) {
 	ccs, ok := cs.(*chainSupport)
 	assert.True(t, ok, "Casting error")
-	rcs := newChainSupport(ccs.filters, ccs.ledgerResources, consenters, mockCrypto())
+	rcs := newChainSupport(ccs.filters, ccs.ingressFilters, ccs.ledgerResources, consenters, mockCrypto())
 	assert.Equal(t, expectedLastConfigSeq, rcs.lastConfigSeq, "On restart, incorrect lastConfigSeq")
 }
 
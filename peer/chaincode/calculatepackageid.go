@@ -0,0 +1,139 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	"github.com/hyperledger/fabric/core/common/ccprovider"
+	"github.com/hyperledger/fabric/protos/utils"
+)
+
+var chaincodeCalculatePackageIDCmd *cobra.Command
+
+const calculatePackageIDCmdName = "calculatepackageid"
+const calculatePackageIDDesc = "Calculate the identity of a chaincode package without contacting a peer."
+
+// packageIdentity is the offline computed identity of a chaincode package
+type packageIdentity struct {
+	Name    string
+	Version string
+	// CodeHash is the hash of the code package alone. It is the same for the
+	// raw and the signed package built from the same sources
+	CodeHash []byte
+	// ID is the fingerprint the peer computes on install
+	ID []byte
+}
+
+// calculatePackageIDCmd returns the cobra command for computing a package identity
+func calculatePackageIDCmd(cdsFact ccDepSpecFactory) *cobra.Command {
+	chaincodeCalculatePackageIDCmd = &cobra.Command{
+		Use:   calculatePackageIDCmdName + " [ccpackfile]",
+		Short: calculatePackageIDDesc,
+		Long: calculatePackageIDDesc + " Either an existing package file is given " +
+			"or the package is built from the -n, -v, -p and -l flags.",
+		ValidArgs: []string{"0", "1"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("invalid number of args (the package file should be the only arg)")
+			}
+			//UT will supply its own mock factory
+			if cdsFact == nil {
+				cdsFact = defaultCDSFactory
+			}
+			return calculatePackageID(cmd, args, cdsFact)
+		},
+	}
+	flagList := []string{
+		"lang",
+		"path",
+		"name",
+		"version",
+	}
+	attachFlags(chaincodeCalculatePackageIDCmd, flagList)
+
+	return chaincodeCalculatePackageIDCmd
+}
+
+func calculatePackageID(cmd *cobra.Command, args []string, cdsFact ccDepSpecFactory) error {
+	var b []byte
+	var err error
+	if len(args) == 1 {
+		b, err = ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+	} else {
+		spec, err := getChaincodeSpec(cmd)
+		if err != nil {
+			return err
+		}
+		cds, err := cdsFact(spec)
+		if err != nil {
+			return fmt.Errorf("Error getting chaincode code %s: %s", chainFuncName, err)
+		}
+		b = utils.MarshalOrPanic(cds)
+	}
+
+	pid, err := getPackageIdentity(b)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Name: %s\n", pid.Name)
+	fmt.Printf("Version: %s\n", pid.Version)
+	fmt.Printf("Code hash: %s\n", hex.EncodeToString(pid.CodeHash))
+	fmt.Printf("Package ID: %s\n", hex.EncodeToString(pid.ID))
+
+	return nil
+}
+
+// getPackageIdentity computes the identity of a raw or signed chaincode
+// package exactly the way the peer does on install
+func getPackageIdentity(b []byte) (*packageIdentity, error) {
+	ccpack, err := ccprovider.GetCCPackage(b)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading chaincode package: %s", err)
+	}
+
+	cd := ccpack.GetChaincodeData()
+
+	var codeHash []byte
+	switch ccpack.(type) {
+	case *ccprovider.CDSPackage:
+		data := &ccprovider.CDSData{}
+		if err = proto.Unmarshal(cd.Data, data); err != nil {
+			return nil, fmt.Errorf("Error unmarshalling package data: %s", err)
+		}
+		codeHash = data.CodeHash
+	case *ccprovider.SignedCDSPackage:
+		data := &ccprovider.SignedCDSData{}
+		if err = proto.Unmarshal(cd.Data, data); err != nil {
+			return nil, fmt.Errorf("Error unmarshalling package data: %s", err)
+		}
+		codeHash = data.CodeHash
+	default:
+		return nil, fmt.Errorf("Unknown chaincode package type %T", ccpack)
+	}
+
+	return &packageIdentity{Name: cd.Name, Version: cd.Version, CodeHash: codeHash, ID: ccpack.GetId()}, nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculatePackageID(t *testing.T) {
+	pdir := newTempDir()
+	defer os.RemoveAll(pdir)
+
+	// raw and signed packages built from the same code share the code hash
+	rawfile := pdir + "/raw.pack"
+	err := createSignedCDSPackage([]string{"-n", "somecc", "-p", "some/go/package", "-v", "0", rawfile}, false)
+	assert.NoError(t, err)
+	signedfile := pdir + "/signed.pack"
+	err = createSignedCDSPackage([]string{"-n", "somecc", "-p", "some/go/package", "-v", "0", "-s", signedfile}, false)
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(rawfile)
+	assert.NoError(t, err)
+	rawID, err := getPackageIdentity(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "somecc", rawID.Name)
+	assert.Equal(t, "0", rawID.Version)
+	assert.NotEmpty(t, rawID.CodeHash)
+	assert.NotEmpty(t, rawID.ID)
+
+	b, err = ioutil.ReadFile(signedfile)
+	assert.NoError(t, err)
+	signedID, err := getPackageIdentity(b)
+	assert.NoError(t, err)
+	assert.Equal(t, rawID.CodeHash, signedID.CodeHash)
+
+	// recomputing the identity is stable
+	b, err = ioutil.ReadFile(rawfile)
+	assert.NoError(t, err)
+	again, err := getPackageIdentity(b)
+	assert.NoError(t, err)
+	assert.Equal(t, rawID, again)
+
+	_, err = getPackageIdentity([]byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestCalculatePackageIDCmd(t *testing.T) {
+	pdir := newTempDir()
+	defer os.RemoveAll(pdir)
+
+	ccpackfile := pdir + "/ccpack.file"
+	err := createSignedCDSPackage([]string{"-n", "somecc", "-p", "some/go/package", "-v", "0", ccpackfile}, false)
+	assert.NoError(t, err)
+
+	// from an existing package file
+	cmd := calculatePackageIDCmd(mockCDSFactory)
+	addFlags(cmd)
+	cmd.SetArgs([]string{ccpackfile})
+	assert.NoError(t, cmd.Execute())
+
+	// built offline from the flags
+	cmd = calculatePackageIDCmd(mockCDSFactory)
+	addFlags(cmd)
+	cmd.SetArgs([]string{"-n", "somecc", "-p", "some/go/package", "-v", "0"})
+	assert.NoError(t, cmd.Execute())
+
+	// missing package file
+	cmd = calculatePackageIDCmd(mockCDSFactory)
+	addFlags(cmd)
+	cmd.SetArgs([]string{pdir + "/nonexistent"})
+	assert.Error(t, cmd.Execute())
+
+	// too many args
+	cmd = calculatePackageIDCmd(mockCDSFactory)
+	addFlags(cmd)
+	cmd.SetArgs([]string{ccpackfile, ccpackfile})
+	assert.Error(t, cmd.Execute())
+}
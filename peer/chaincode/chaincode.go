@@ -28,8 +28,8 @@ import (
 
 const (
 	chainFuncName = "chaincode"
-	shortDes      = "Operate a chaincode: install|instantiate|invoke|package|query|signpackage|upgrade."
-	longDes       = "Operate a chaincode: install|instantiate|invoke|package|query|signpackage|upgrade."
+	shortDes      = "Operate a chaincode: install|instantiate|invoke|list|package|query|signpackage|upgrade."
+	longDes       = "Operate a chaincode: install|instantiate|invoke|list|package|query|signpackage|upgrade."
 )
 
 var logger = flogging.MustGetLogger("chaincodeCmd")
@@ -46,9 +46,11 @@ func addFlags(cmd *cobra.Command) {
 func Cmd(cf *ChaincodeCmdFactory) *cobra.Command {
 	addFlags(chaincodeCmd)
 
+	chaincodeCmd.AddCommand(calculatePackageIDCmd(nil))
 	chaincodeCmd.AddCommand(installCmd(cf))
 	chaincodeCmd.AddCommand(instantiateCmd(cf))
 	chaincodeCmd.AddCommand(invokeCmd(cf))
+	chaincodeCmd.AddCommand(listCmd(cf))
 	chaincodeCmd.AddCommand(packageCmd(cf, nil))
 	chaincodeCmd.AddCommand(queryCmd(cf))
 	chaincodeCmd.AddCommand(signpackageCmd(cf))
@@ -167,7 +167,8 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 	}
 
 	if cmd.Name() == instantiateCmdName || cmd.Name() == installCmdName ||
-		cmd.Name() == upgradeCmdName || cmd.Name() == packageCmdName {
+		cmd.Name() == upgradeCmdName || cmd.Name() == packageCmdName ||
+		cmd.Name() == calculatePackageIDCmdName {
 		if chaincodeVersion == common.UndefinedParamValue {
 			return fmt.Errorf("Chaincode version is not provided for %s", cmd.Name())
 		}
@@ -217,7 +218,8 @@ func checkChaincodeCmdParams(cmd *cobra.Command) error {
 			return errors.New("Non-empty JSON chaincode parameters must contain the following keys: 'Args' or 'Function' and 'Args'")
 		}
 	} else {
-		if cmd == nil || (cmd != chaincodeInstallCmd && cmd != chaincodePackageCmd) {
+		if cmd == nil || (cmd != chaincodeInstallCmd && cmd != chaincodePackageCmd &&
+			cmd != chaincodeCalculatePackageIDCmd) {
 			return errors.New("Empty JSON chaincode parameters must contain the following keys: 'Args' or 'Function' and 'Args'")
 		}
 	}
@@ -230,6 +232,7 @@ type ChaincodeCmdFactory struct {
 	EndorserClient  pb.EndorserClient
 	Signer          msp.SigningIdentity
 	BroadcastClient common.BroadcastClient
+	AdminClient     pb.AdminClient
 }
 
 // InitCmdFactory init the ChaincodeCmdFactory with default clients
@@ -0,0 +1,164 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric/core/scc/lscc"
+	"github.com/hyperledger/fabric/peer/common"
+	pcommon "github.com/hyperledger/fabric/protos/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var chaincodeListCmd *cobra.Command
+
+var getInstalledChaincodes bool
+var getInstantiatedChaincodes bool
+var getRunningChaincodes bool
+
+// listCmd returns the cobra command for Chaincode List
+func listCmd(cf *ChaincodeCmdFactory) *cobra.Command {
+	chaincodeListCmd = &cobra.Command{
+		Use:   "list",
+		Short: fmt.Sprintf("Get the %ss on the peer.", chainFuncName),
+		Long: fmt.Sprintf("Get the %ss installed on the peer with --installed, the %ss instantiated on the channel "+
+			"with --instantiated, or the %s runtimes launched by the peer with their state with --running.",
+			chainFuncName, chainFuncName, chainFuncName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return chaincodeList(cmd, cf)
+		},
+	}
+	flagList := []string{
+		"channelID",
+	}
+	attachFlags(chaincodeListCmd, flagList)
+
+	chaincodeListCmd.Flags().BoolVarP(&getInstalledChaincodes, "installed", "", false,
+		"Get the installed chaincodes on a peer")
+	chaincodeListCmd.Flags().BoolVarP(&getInstantiatedChaincodes, "instantiated", "", false,
+		"Get the instantiated chaincodes on a channel")
+	chaincodeListCmd.Flags().BoolVarP(&getRunningChaincodes, "running", "", false,
+		"Get the state of the chaincode runtimes launched by the peer")
+
+	return chaincodeListCmd
+}
+
+func chaincodeList(cmd *cobra.Command, cf *ChaincodeCmdFactory) error {
+	selected := 0
+	for _, flag := range []bool{getInstalledChaincodes, getInstantiatedChaincodes, getRunningChaincodes} {
+		if flag {
+			selected++
+		}
+	}
+	if selected != 1 {
+		return fmt.Errorf("Must explicitly specify one of \"--installed\", \"--instantiated\" or \"--running\"")
+	}
+	if getInstantiatedChaincodes && chainID == "" {
+		return fmt.Errorf("The required parameter 'channelID' is empty. Rerun the command with -C flag")
+	}
+
+	var err error
+	if cf == nil {
+		if cf, err = InitCmdFactory(!getRunningChaincodes, false); err != nil {
+			return err
+		}
+	}
+	if getRunningChaincodes {
+		return listRunningChaincodes(cf)
+	}
+
+	creator, err := cf.Signer.Serialize()
+	if err != nil {
+		return fmt.Errorf("Error serializing identity for %s: %s", cf.Signer.GetIdentifier(), err)
+	}
+	function, channel := lscc.GETINSTALLEDCHAINCODES, ""
+	if getInstantiatedChaincodes {
+		function, channel = lscc.GETCHAINCODES, chainID
+	}
+	invocation := &pb.ChaincodeInvocationSpec{ChaincodeSpec: &pb.ChaincodeSpec{
+		Type:        pb.ChaincodeSpec_GOLANG,
+		ChaincodeId: &pb.ChaincodeID{Name: "lscc"},
+		Input:       &pb.ChaincodeInput{Args: [][]byte{[]byte(function)}}}}
+	prop, _, err := utils.CreateProposalFromCIS(pcommon.HeaderType_ENDORSER_TRANSACTION, channel, invocation, creator)
+	if err != nil {
+		return fmt.Errorf("Error creating proposal %s: %s", chainFuncName, err)
+	}
+	signedProp, err := utils.GetSignedProposal(prop, cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal %s: %s", chainFuncName, err)
+	}
+	proposalResponse, err := cf.EndorserClient.ProcessProposal(context.Background(), signedProp)
+	if err != nil {
+		return fmt.Errorf("Error endorsing %s: %s", chainFuncName, err)
+	}
+	if proposalResponse.Response == nil || proposalResponse.Response.Status != int32(pcommon.Status_SUCCESS) {
+		return fmt.Errorf("Bad response from the peer: %v", proposalResponse.Response)
+	}
+
+	cqr := &pb.ChaincodeQueryResponse{}
+	if err = proto.Unmarshal(proposalResponse.Response.Payload, cqr); err != nil {
+		return err
+	}
+	if getInstalledChaincodes {
+		fmt.Println("Get installed chaincodes on peer:")
+	} else {
+		fmt.Printf("Get instantiated chaincodes on channel %s:\n", chainID)
+	}
+	for _, chaincode := range cqr.Chaincodes {
+		fmt.Printf("%v\n", chaincode)
+	}
+	return nil
+}
+
+// listRunningChaincodes prints the state of the chaincode runtimes returned by the
+// Admin service of the peer, which requires an admin of its local MSP
+func listRunningChaincodes(cf *ChaincodeCmdFactory) error {
+	adminClient := cf.AdminClient
+	if adminClient == nil {
+		var err error
+		if adminClient, err = common.GetAdminClient(); err != nil {
+			return fmt.Errorf("Error getting admin client: %s", err)
+		}
+	}
+
+	signedProp, err := utils.CreateSignedAdminProposal(cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal: %s", err)
+	}
+	runtimes, err := adminClient.GetChaincodeRuntimes(context.Background(), signedProp)
+	if err != nil {
+		return fmt.Errorf("Error getting chaincode runtimes from local peer: %s", err)
+	}
+	if len(runtimes.Runtimes) == 0 {
+		fmt.Println("No chaincode runtimes")
+		return nil
+	}
+	fmt.Println("Chaincode runtimes:")
+	for _, rt := range runtimes.Runtimes {
+		line := fmt.Sprintf("Name: %s, Version: %s, State: %s, Restarts: %d", rt.Name, rt.Version, rt.State, rt.RestartCount)
+		if rt.LastError != "" {
+			line += fmt.Sprintf(", Last error: %s", rt.LastError)
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chaincode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+type mockRuntimesAdminClient struct {
+	pb.AdminClient
+	runtimes *pb.ChaincodeRuntimes
+	err      error
+	// signedProp is the proposal of the last request
+	signedProp *pb.SignedProposal
+}
+
+func (m *mockRuntimesAdminClient) GetChaincodeRuntimes(ctx context.Context, in *pb.SignedProposal, opts ...grpc.CallOption) (*pb.ChaincodeRuntimes, error) {
+	m.signedProp = in
+	return m.runtimes, m.err
+}
+
+func TestListCmd(t *testing.T) {
+	InitMSP()
+	signer, err := common.GetDefaultSigner()
+	assert.NoError(t, err, "Get default signer error")
+
+	cqr := &pb.ChaincodeQueryResponse{Chaincodes: []*pb.ChaincodeInfo{{Name: "mycc", Version: "1.0", Path: "path"}}}
+	mockResponse := &pb.ProposalResponse{
+		Response:    &pb.Response{Status: 200, Payload: utils.MarshalOrPanic(cqr)},
+		Endorsement: &pb.Endorsement{},
+	}
+	mockCF := &ChaincodeCmdFactory{EndorserClient: common.GetMockEndorserClient(mockResponse, nil), Signer: signer}
+
+	// Failure case: no list specified
+	cmd := listCmd(mockCF)
+	cmd.SetArgs([]string{})
+	assert.Error(t, cmd.Execute(), "Expected error executing list command without a list")
+
+	// Failure case: several lists specified
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--installed", "--instantiated"})
+	assert.Error(t, cmd.Execute(), "Expected error executing list command with several lists")
+
+	// Success case: installed chaincodes
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--installed"})
+	assert.NoError(t, cmd.Execute(), "Run chaincode list cmd error")
+
+	// Success case: instantiated chaincodes
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--instantiated", "-C", "mychannel"})
+	assert.NoError(t, cmd.Execute(), "Run chaincode list cmd error")
+
+	// Failure case: instantiated chaincodes without a channel
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--instantiated", "-C", ""})
+	assert.Error(t, cmd.Execute(), "Expected error executing list command without a channel")
+
+	// Failure case: the peer rejects the query
+	mockResponse.Response = &pb.Response{Status: 500, Message: "access denied"}
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--installed"})
+	assert.Error(t, cmd.Execute(), "Expected error executing list command")
+}
+
+func TestListRunningCmd(t *testing.T) {
+	InitMSP()
+	signer, err := common.GetDefaultSigner()
+	assert.NoError(t, err, "Get default signer error")
+
+	adminClient := &mockRuntimesAdminClient{runtimes: &pb.ChaincodeRuntimes{Runtimes: []*pb.ChaincodeRuntime{
+		{Name: "mycc", Version: "1.0", State: pb.ChaincodeRuntime_RUNNING},
+		{Name: "othercc", Version: "2.0", State: pb.ChaincodeRuntime_CRASHED, RestartCount: 3, LastError: "chaincode stream terminated"},
+	}}}
+	mockCF := &ChaincodeCmdFactory{AdminClient: adminClient, Signer: signer}
+
+	// Success case: the request is signed by the local identity
+	cmd := listCmd(mockCF)
+	cmd.SetArgs([]string{"--running"})
+	assert.NoError(t, cmd.Execute(), "Run chaincode list cmd error")
+	assert.NotNil(t, adminClient.signedProp)
+	prop, err := utils.GetProposal(adminClient.signedProp.ProposalBytes)
+	assert.NoError(t, err)
+	hdr, err := utils.GetHeader(prop.Header)
+	assert.NoError(t, err)
+	shdr, err := utils.GetSignatureHeader(hdr.SignatureHeader)
+	assert.NoError(t, err)
+	creator, err := signer.Serialize()
+	assert.NoError(t, err)
+	assert.Equal(t, creator, shdr.Creator)
+
+	// Success case: no runtimes
+	adminClient.runtimes = &pb.ChaincodeRuntimes{}
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--running"})
+	assert.NoError(t, cmd.Execute(), "Run chaincode list cmd error")
+
+	// Failure case: the peer returns an error
+	adminClient.err = errors.New("Authorization for GetChaincodeRuntimes has been denied")
+	cmd = listCmd(mockCF)
+	cmd.SetArgs([]string{"--running"})
+	assert.Error(t, cmd.Execute(), "Expected error executing list command")
+}
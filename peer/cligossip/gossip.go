@@ -0,0 +1,67 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cligossip
+
+import (
+	"github.com/hyperledger/fabric/common/flogging"
+	"github.com/hyperledger/fabric/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/spf13/cobra"
+)
+
+const gossipFuncName = "gossip"
+
+var logger = flogging.MustGetLogger("cli/gossip")
+
+// GossipCmdFactory holds the clients used by GossipCmd
+type GossipCmdFactory struct {
+	AdminClient pb.AdminClient
+	Signer      msp.SigningIdentity
+}
+
+// InitCmdFactory init the GossipCmdFactory with default admin client and signer
+func InitCmdFactory() (*GossipCmdFactory, error) {
+	adminClient, err := common.GetAdminClient()
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := common.GetDefaultSigner()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GossipCmdFactory{
+		AdminClient: adminClient,
+		Signer:      signer,
+	}, nil
+}
+
+// Cmd returns the cobra command for Gossip
+func Cmd(cf *GossipCmdFactory) *cobra.Command {
+	gossipCmd.AddCommand(statusCmd(cf))
+	gossipCmd.AddCommand(updateCmd(cf))
+
+	return gossipCmd
+}
+
+var gossipCmd = &cobra.Command{
+	Use:   gossipFuncName,
+	Short: "Inspect and reconfigure the gossip component of the peer: status|update.",
+	Long:  "Inspect and reconfigure the gossip component of the peer: status|update.",
+}
@@ -0,0 +1,132 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cligossip
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	mockmsp "github.com/hyperledger/fabric/common/mocks/msp"
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/stretchr/testify/assert"
+)
+
+var testGossipStatus = &pb.GossipStatus{
+	Self: &pb.GossipMember{Endpoint: "p1:7051", InternalEndpoint: "p1:7051", PkiId: []byte("p1"), Org: "Org1MSP"},
+	Peers: []*pb.GossipMember{
+		{Endpoint: "p2:7051", InternalEndpoint: "p2.internal:7051", PkiId: []byte("p2"), Org: "Org1MSP",
+			LastAlive: &timestamp.Timestamp{Seconds: 1500000000}},
+	},
+	Channels: []*pb.GossipChannelStatus{
+		{
+			ChannelId: "chA",
+			Peers: []*pb.GossipMember{{Endpoint: "p2:7051", PkiId: []byte("p2"), Org: "Org1MSP", LedgerHeight: 10,
+				LastAlive: &timestamp.Timestamp{Seconds: 1500000000}}},
+			Leader:            []byte("p2"),
+			BlockMsgStoreSize: 3,
+			BlockPullSize:     2,
+		},
+		{ChannelId: "chB"},
+	},
+	Identities:            []*pb.GossipIdentity{{PkiId: []byte("p1"), Org: "Org1MSP"}, {PkiId: []byte("p2"), Org: "Org1MSP"}},
+	CertStoreSize:         2,
+	StateInfoMsgStoreSize: 1,
+}
+
+func initGossipTest(t *testing.T, err error) *GossipCmdFactory {
+	signer, signerErr := mockmsp.NewNoopMsp().GetDefaultSigningIdentity()
+	assert.NoError(t, signerErr)
+	return &GossipCmdFactory{
+		AdminClient: common.GetMockGossipAdminClient(testGossipStatus, err),
+		Signer:      signer,
+	}
+}
+
+func TestStatus(t *testing.T) {
+	defer func() { statusChannelID = common.UndefinedParamValue }()
+	cf := initGossipTest(t, nil)
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, status(cf, out))
+	assert.Equal(t, `Self: Endpoint: p1:7051, PKI-ID: 7031, Org: Org1MSP
+Cert store size: 2, State info message store size: 1
+Alive peers (1):
+  Endpoint: p2:7051, Internal endpoint: p2.internal:7051, PKI-ID: 7032, Org: Org1MSP, Last alive: 2017-07-14T02:40:00Z
+Channel chA: Leader: 7032, Block message store size: 3, Block pull store size: 2, State info message store size: 0, Leader message store size: 0
+  Peers (1):
+    Endpoint: p2:7051, PKI-ID: 7032, Org: Org1MSP, Last alive: 2017-07-14T02:40:00Z, Ledger height: 10
+Channel chB: Leader: none, Block message store size: 0, Block pull store size: 0, State info message store size: 0, Leader message store size: 0
+  Peers (0):
+Identities (2):
+  PKI-ID: 7031, Org: Org1MSP
+  PKI-ID: 7032, Org: Org1MSP
+`, out.String())
+
+	out.Reset()
+	statusChannelID = "chB"
+	assert.NoError(t, status(cf, out))
+	assert.Equal(t, `Channel chB: Leader: none, Block message store size: 0, Block pull store size: 0, State info message store size: 0, Leader message store size: 0
+  Peers (0):
+`, out.String())
+
+	statusChannelID = "chC"
+	assert.Error(t, status(cf, out))
+}
+
+func TestStatusCmd(t *testing.T) {
+	cmd := statusCmd(initGossipTest(t, nil))
+	cmd.SetArgs([]string{"-c", "chA"})
+	assert.NoError(t, cmd.Execute())
+	statusChannelID = common.UndefinedParamValue
+
+	// the peer denies access to the gossip status
+	cmd = statusCmd(initGossipTest(t, errors.New("access denied")))
+	cmd.SetArgs([]string{})
+	assert.Error(t, cmd.Execute())
+}
+
+func TestUpdate(t *testing.T) {
+	signer, err := mockmsp.NewNoopMsp().GetDefaultSigningIdentity()
+	assert.NoError(t, err)
+	cf := &GossipCmdFactory{
+		AdminClient: common.GetMockGossipConfigAdminClient(&pb.GossipConfig{
+			BootstrapPeers:   []string{"p2:7051", "p3:7051"},
+			ExternalEndpoint: "p1.example.com:7051",
+		}, nil),
+		Signer: signer,
+	}
+
+	out := &bytes.Buffer{}
+	assert.NoError(t, updateConfig(cf, &pb.GossipConfigUpdate{AddBootstrapPeers: []string{"p3:7051"}}, out))
+	assert.Equal(t, `External endpoint: p1.example.com:7051
+Bootstrap peers: p2:7051, p3:7051
+`, out.String())
+
+	cmd := updateCmd(cf)
+	cmd.SetArgs([]string{"-a", "p3:7051,p4:7051", "-r", "p2:7051", "-e", "p1.example.com:7051"})
+	assert.NoError(t, cmd.Execute())
+	assert.Equal(t, []string{"p3:7051", "p4:7051"}, addBootstrapPeers)
+	assert.Equal(t, []string{"p2:7051"}, removeBootstrapPeers)
+	assert.Equal(t, "p1.example.com:7051", externalEndpoint)
+
+	// the peer denies access to the gossip config
+	cf.AdminClient = common.GetMockGossipConfigAdminClient(nil, errors.New("access denied"))
+	assert.Error(t, updateConfig(cf, &pb.GossipConfigUpdate{}, out))
+}
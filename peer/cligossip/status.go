@@ -0,0 +1,125 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cligossip
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hyperledger/fabric/peer/common"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var statusChannelID string
+
+func statusCmd(cf *GossipCmdFactory) *cobra.Command {
+	var gossipStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Dumps the membership and channel state of the gossip component of the peer.",
+		Long: `Dumps the peers considered alive by the gossip component of the peer, with their endpoints, PKI-IDs, ` +
+			`organizations and the time they were last seen alive, the peers, ledger heights and leader of each channel, ` +
+			`the identities of the cert store and the sizes of the message stores. Requires an admin of the local MSP.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return status(cf, os.Stdout)
+		},
+	}
+	gossipStatusCmd.Flags().StringVarP(&statusChannelID, "channelID", "c", common.UndefinedParamValue,
+		"Channel whose state is dumped. The state of all the channels is dumped if omitted.")
+
+	return gossipStatusCmd
+}
+
+func status(cf *GossipCmdFactory, out io.Writer) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory()
+		if err != nil {
+			return err
+		}
+	}
+
+	signedProp, err := utils.CreateSignedAdminProposal(cf.Signer)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal: %s", err)
+	}
+	gossipStatus, err := cf.AdminClient.GetGossipStatus(context.Background(), signedProp)
+	if err != nil {
+		return fmt.Errorf("Error getting gossip status from local peer: %s", err)
+	}
+	logger.Debugf("Gossip status: %s", gossipStatus)
+
+	if statusChannelID == common.UndefinedParamValue {
+		printStatus(out, gossipStatus)
+		return nil
+	}
+	for _, channel := range gossipStatus.Channels {
+		if channel.ChannelId == statusChannelID {
+			printChannel(out, channel)
+			return nil
+		}
+	}
+	return fmt.Errorf("The peer has not joined channel [%s]", statusChannelID)
+}
+
+func printStatus(out io.Writer, gossipStatus *pb.GossipStatus) {
+	fmt.Fprintf(out, "Self: %s\n", formatMember(gossipStatus.Self))
+	fmt.Fprintf(out, "Cert store size: %d, State info message store size: %d\n",
+		gossipStatus.CertStoreSize, gossipStatus.StateInfoMsgStoreSize)
+	fmt.Fprintf(out, "Alive peers (%d):\n", len(gossipStatus.Peers))
+	for _, member := range gossipStatus.Peers {
+		fmt.Fprintf(out, "  %s\n", formatMember(member))
+	}
+	for _, channel := range gossipStatus.Channels {
+		printChannel(out, channel)
+	}
+	fmt.Fprintf(out, "Identities (%d):\n", len(gossipStatus.Identities))
+	for _, identity := range gossipStatus.Identities {
+		fmt.Fprintf(out, "  PKI-ID: %x, Org: %s\n", identity.PkiId, identity.Org)
+	}
+}
+
+func printChannel(out io.Writer, channel *pb.GossipChannelStatus) {
+	leader := "none"
+	if len(channel.Leader) > 0 {
+		leader = fmt.Sprintf("%x", channel.Leader)
+	}
+	fmt.Fprintf(out, "Channel %s: Leader: %s, Block message store size: %d, Block pull store size: %d, "+
+		"State info message store size: %d, Leader message store size: %d\n", channel.ChannelId, leader,
+		channel.BlockMsgStoreSize, channel.BlockPullSize, channel.StateInfoMsgStoreSize, channel.LeaderMsgStoreSize)
+	fmt.Fprintf(out, "  Peers (%d):\n", len(channel.Peers))
+	for _, member := range channel.Peers {
+		fmt.Fprintf(out, "    %s, Ledger height: %d\n", formatMember(member), member.LedgerHeight)
+	}
+}
+
+func formatMember(member *pb.GossipMember) string {
+	line := fmt.Sprintf("Endpoint: %s", member.Endpoint)
+	if member.InternalEndpoint != "" && member.InternalEndpoint != member.Endpoint {
+		line += fmt.Sprintf(", Internal endpoint: %s", member.InternalEndpoint)
+	}
+	line += fmt.Sprintf(", PKI-ID: %x, Org: %s", member.PkiId, member.Org)
+	if member.LastAlive != nil {
+		lastAlive := time.Unix(member.LastAlive.Seconds, int64(member.LastAlive.Nanos)).UTC()
+		line += fmt.Sprintf(", Last alive: %s", lastAlive.Format(time.RFC3339))
+	}
+	return line
+}
@@ -0,0 +1,94 @@
+/*
+Copyright IBM Corp. 2016 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cligossip
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	pb "github.com/hyperledger/fabric/protos/peer"
+	"github.com/hyperledger/fabric/protos/utils"
+	"github.com/spf13/cobra"
+	"golang.org/x/net/context"
+)
+
+var (
+	addBootstrapPeers    []string
+	removeBootstrapPeers []string
+	externalEndpoint     string
+)
+
+const externalEndpointFlag = "externalEndpoint"
+
+func updateCmd(cf *GossipCmdFactory) *cobra.Command {
+	var gossipUpdateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "Updates the bootstrap peers and the external endpoint of the gossip component of the peer.",
+		Long: `Adds and removes bootstrap peers of the gossip component of the peer, and changes the endpoint ` +
+			`the peer publishes to peers of other organizations, without restarting the peer. The changes are not ` +
+			`persisted to the configuration of the peer. Requires an admin of the local MSP.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			update := &pb.GossipConfigUpdate{
+				AddBootstrapPeers:      addBootstrapPeers,
+				RemoveBootstrapPeers:   removeBootstrapPeers,
+				UpdateExternalEndpoint: cmd.Flags().Changed(externalEndpointFlag),
+				ExternalEndpoint:       externalEndpoint,
+			}
+			return updateConfig(cf, update, os.Stdout)
+		},
+	}
+	flags := gossipUpdateCmd.Flags()
+	flags.StringSliceVarP(&addBootstrapPeers, "addBootstrap", "a", nil,
+		"Comma separated endpoints of bootstrap peers to add.")
+	flags.StringSliceVarP(&removeBootstrapPeers, "removeBootstrap", "r", nil,
+		"Comma separated endpoints of bootstrap peers to remove.")
+	flags.StringVarP(&externalEndpoint, externalEndpointFlag, "e", "",
+		"Endpoint published to peers of other organizations. An empty endpoint makes the peer inaccessible outside of its organization.")
+
+	return gossipUpdateCmd
+}
+
+func updateConfig(cf *GossipCmdFactory, update *pb.GossipConfigUpdate, out io.Writer) error {
+	var err error
+	if cf == nil {
+		cf, err = InitCmdFactory()
+		if err != nil {
+			return err
+		}
+	}
+
+	payload, err := proto.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("Error marshaling gossip config update: %s", err)
+	}
+	signedProp, err := utils.CreateSignedAdminProposalWithPayload(cf.Signer, payload)
+	if err != nil {
+		return fmt.Errorf("Error creating signed proposal: %s", err)
+	}
+	gossipConfig, err := cf.AdminClient.UpdateGossipConfig(context.Background(), signedProp)
+	if err != nil {
+		return fmt.Errorf("Error updating gossip config of local peer: %s", err)
+	}
+	logger.Debugf("Gossip config: %s", gossipConfig)
+
+	fmt.Fprintf(out, "External endpoint: %s\n", gossipConfig.ExternalEndpoint)
+	fmt.Fprintf(out, "Bootstrap peers: %s\n", strings.Join(gossipConfig.BootstrapPeers, ", "))
+	return nil
+}
@@ -62,9 +62,21 @@ func GetMockAdminClient(err error) pb.AdminClient {
 	return &mockAdminClient{err: err}
 }
 
+// GetMockGossipAdminClient return an admin client returning the specified GossipStatus and err(nil or error)
+func GetMockGossipAdminClient(gossipStatus *pb.GossipStatus, err error) pb.AdminClient {
+	return &mockAdminClient{gossipStatus: gossipStatus, err: err}
+}
+
+// GetMockGossipConfigAdminClient return an admin client returning the specified GossipConfig and err(nil or error)
+func GetMockGossipConfigAdminClient(gossipConfig *pb.GossipConfig, err error) pb.AdminClient {
+	return &mockAdminClient{gossipConfig: gossipConfig, err: err}
+}
+
 type mockAdminClient struct {
-	status *pb.ServerStatus
-	err    error
+	status       *pb.ServerStatus
+	gossipStatus *pb.GossipStatus
+	gossipConfig *pb.GossipConfig
+	err          error
 }
 
 func (m *mockAdminClient) GetStatus(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*pb.ServerStatus, error) {
@@ -94,3 +106,15 @@ func (m *mockAdminClient) SetModuleLogLevel(ctx context.Context, in *pb.LogLevel
 func (m *mockAdminClient) RevertLogLevels(ctx context.Context, in *empty.Empty, opts ...grpc.CallOption) (*empty.Empty, error) {
 	return &empty.Empty{}, m.err
 }
+
+func (m *mockAdminClient) GetChaincodeRuntimes(ctx context.Context, in *pb.SignedProposal, opts ...grpc.CallOption) (*pb.ChaincodeRuntimes, error) {
+	return &pb.ChaincodeRuntimes{}, m.err
+}
+
+func (m *mockAdminClient) GetGossipStatus(ctx context.Context, in *pb.SignedProposal, opts ...grpc.CallOption) (*pb.GossipStatus, error) {
+	return m.gossipStatus, m.err
+}
+
+func (m *mockAdminClient) UpdateGossipConfig(ctx context.Context, in *pb.SignedProposal, opts ...grpc.CallOption) (*pb.GossipConfig, error) {
+	return m.gossipConfig, m.err
+}
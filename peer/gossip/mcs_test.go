@@ -17,12 +17,16 @@ limitations under the License.
 package gossip
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric/bccsp"
 	"github.com/hyperledger/fabric/bccsp/factory"
+	"github.com/hyperledger/fabric/common/cauthdsl"
 	"github.com/hyperledger/fabric/common/crypto"
 	"github.com/hyperledger/fabric/common/localmsp"
 	mockscrypto "github.com/hyperledger/fabric/common/mocks/crypto"
@@ -33,6 +37,7 @@ import (
 	"github.com/hyperledger/fabric/msp/mgmt"
 	"github.com/hyperledger/fabric/peer/gossip/mocks"
 	"github.com/hyperledger/fabric/protos/common"
+	mspproto "github.com/hyperledger/fabric/protos/msp"
 	protospeer "github.com/hyperledger/fabric/protos/peer"
 	"github.com/hyperledger/fabric/protos/utils"
 	"github.com/stretchr/testify/assert"
@@ -210,6 +215,85 @@ func TestVerifyBlock(t *testing.T) {
 	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, nil))
 }
 
+// quorumDeserializer deserializes identities which only satisfy the principal of their own identity
+type quorumDeserializer struct{}
+
+func (d *quorumDeserializer) DeserializeIdentity(serializedIdentity []byte) (msp.Identity, error) {
+	return &quorumIdentity{id: serializedIdentity}, nil
+}
+
+type quorumIdentity struct {
+	mocks.Identity
+	id []byte
+}
+
+func (id *quorumIdentity) SatisfiesPrincipal(principal *mspproto.MSPPrincipal) error {
+	if principal.PrincipalClassification != mspproto.MSPPrincipal_IDENTITY || !bytes.Equal(principal.Principal, id.id) {
+		return errors.New("Principal not satisfied")
+	}
+	return nil
+}
+
+func (id *quorumIdentity) Verify(msg []byte, sig []byte) error {
+	if !bytes.Equal(msg, sig) {
+		return errors.New("Invalid Signature")
+	}
+	return nil
+}
+
+func TestVerifyBlockQuorum(t *testing.T) {
+	// The block validation policy of a BFT orderer requires the signatures of 3 out of 4 consenters
+	var consenters [][]byte
+	var signers []crypto.LocalSigner
+	for i := 0; i < 4; i++ {
+		consenters = append(consenters, []byte(fmt.Sprintf("Orderer%d", i)))
+		signers = append(signers, &mockscrypto.LocalSigner{Identity: consenters[i]})
+	}
+	policy, _, err := cauthdsl.NewPolicyProvider(&quorumDeserializer{}).NewPolicy(utils.MarshalOrPanic(cauthdsl.SignedByNOutOfGivenIdentities(3, consenters)))
+	assert.NoError(t, err)
+
+	msgCryptoService := NewMCS(
+		&mocks.ChannelPolicyManagerGetterWithManager{
+			map[string]policies.Manager{"C": &mocks.ChannelPolicyManager{policy}},
+		},
+		signers[0],
+		&mocks.DeserializersManager{LocalDeserializer: &quorumDeserializer{}},
+	)
+
+	assert.NoError(t, msgCryptoService.VerifyBlock([]byte("C"), 42, mockSignedBlock(t, "C", 42, signers[0], signers[1], signers[3])))
+	assert.NoError(t, msgCryptoService.VerifyBlock([]byte("C"), 42, mockSignedBlock(t, "C", 42, signers...)))
+	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, mockSignedBlock(t, "C", 42, signers[0], signers[1])))
+	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, mockSignedBlock(t, "C", 42, signers[0], signers[1], signers[1])),
+		"Signatures of the same consenter should count once")
+	assert.Error(t, msgCryptoService.VerifyBlock([]byte("C"), 42, mockSignedBlock(t, "C", 42, signers[0], signers[1],
+		&mockscrypto.LocalSigner{Identity: []byte("Intruder")})))
+}
+
+// mockSignedBlock returns a block signed by all the given signers
+func mockSignedBlock(t *testing.T, channel string, seqNum uint64, signers ...crypto.LocalSigner) []byte {
+	blockRaw, _ := mockBlock(t, channel, seqNum, signers[0], nil)
+	block, err := utils.GetBlockFromBlockBytes(blockRaw)
+	assert.NoError(t, err)
+	metadata, err := utils.GetMetadataFromBlock(block, common.BlockMetadataIndex_SIGNATURES)
+	assert.NoError(t, err)
+
+	for _, signer := range signers[1:] {
+		shdr, err := signer.NewSignatureHeader()
+		assert.NoError(t, err, "Failed generating signature header")
+		blockSignature := &common.MetadataSignature{
+			SignatureHeader: utils.MarshalOrPanic(shdr),
+		}
+		blockSignature.Signature, err = signer.Sign(util.ConcatenateBytes(metadata.Value, blockSignature.SignatureHeader, block.Header.Bytes()))
+		assert.NoError(t, err, "Failed signing block")
+		metadata.Signatures = append(metadata.Signatures, blockSignature)
+	}
+	block.Metadata.Metadata[common.BlockMetadataIndex_SIGNATURES] = utils.MarshalOrPanic(metadata)
+
+	blockRaw, err = proto.Marshal(block)
+	assert.NoError(t, err, "Failed marshalling block")
+	return blockRaw
+}
+
 func mockBlock(t *testing.T, channel string, seqNum uint64, localSigner crypto.LocalSigner, dataHash []byte) ([]byte, []byte) {
 	block := common.NewBlock(seqNum, nil)
 
@@ -0,0 +1,37 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/spf13/cobra"
+)
+
+func rebuildDBsCmd() *cobra.Command {
+	return nodeRebuildDBsCmd
+}
+
+var nodeRebuildDBsCmd = &cobra.Command{
+	Use:   "rebuild-dbs",
+	Short: "Rebuilds the state and history databases from the blocks.",
+	Long: `Drops the state and history databases of all the channels and rebuilds them from the committed blocks, ` +
+		`using the validation results recorded in the blocks. The state database configured in ledger.state.stateDatabase ` +
+		`is the one rebuilt, which allows to move an existing peer to CouchDB. The peer must be stopped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return ledgermgmt.RebuildDBs()
+	},
+}
@@ -0,0 +1,49 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+)
+
+var rollbackChannelID string
+var rollbackBlockNumber uint64
+
+func rollbackCmd() *cobra.Command {
+	flags := nodeRollbackCmd.Flags()
+	flags.StringVarP(&rollbackChannelID, "channelID", "c", common.UndefinedParamValue, "Channel to roll back.")
+	flags.Uint64VarP(&rollbackBlockNumber, "blockNumber", "b", 0, "Block number to which the channel is rolled back.")
+
+	return nodeRollbackCmd
+}
+
+var nodeRollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Rolls back a channel to a block.",
+	Long: `Rolls back the ledger of a channel to the given block number. The state and history databases are ` +
+		`rebuilt from the remaining blocks. The peer must be stopped, it pulls the removed blocks again once restarted.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if rollbackChannelID == common.UndefinedParamValue {
+			return fmt.Errorf("Must supply channel ID")
+		}
+		return ledgermgmt.RollbackLedger(rollbackChannelID, rollbackBlockNumber)
+	},
+}
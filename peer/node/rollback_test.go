@@ -0,0 +1,37 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRollbackCmd(t *testing.T) {
+	viper.Set("peer.fileSystemPath", "/tmp/hyperledger/rollbacktest")
+	defer os.RemoveAll("/tmp/hyperledger/rollbacktest")
+
+	cmd := rollbackCmd()
+	cmd.SetArgs([]string{"-b", "1"})
+	assert.Error(t, cmd.Execute(), "the channel ID is missing")
+
+	cmd.SetArgs([]string{"-c", "nonexistent", "-b", "1"})
+	assert.Error(t, cmd.Execute(), "the channel does not exist")
+}
@@ -26,7 +26,11 @@ import (
 	"github.com/hyperledger/fabric/core/common/ccprovider"
 	"github.com/hyperledger/fabric/core/config"
 	"github.com/hyperledger/fabric/core/endorser"
+	"github.com/hyperledger/fabric/core/ledger"
+	"github.com/hyperledger/fabric/core/ledger/cceventmgmt"
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
 	"github.com/hyperledger/fabric/core/ledger/ledgermgmt"
+	"github.com/hyperledger/fabric/core/ledger/statelistener"
 	"github.com/hyperledger/fabric/core/peer"
 	"github.com/hyperledger/fabric/core/scc"
 	"github.com/hyperledger/fabric/events/producer"
@@ -83,9 +87,27 @@ func initSysCCs() {
 	logger.Infof("Deployed system chaincodess")
 }
 
+// createStateListeners returns the state listeners enabled in the configuration
+func createStateListeners() ([]ledger.StateListener, error) {
+	var listeners []ledger.StateListener
+	if ledgerconfig.IsJSONLinesStateListenerEnabled() {
+		listener, err := statelistener.NewJSONLinesListener(ledgerconfig.GetJSONLinesStateListenerPath(),
+			ledgerconfig.GetJSONLinesStateListenerNamespaces())
+		if err != nil {
+			return nil, fmt.Errorf("Error creating the JSON lines state listener: %s", err)
+		}
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
 func serve(args []string) error {
 	logger.Infof("Starting %s", version.GetInfo())
-	ledgermgmt.Initialize()
+	stateListeners, err := createStateListeners()
+	if err != nil {
+		return err
+	}
+	ledgermgmt.Initialize(stateListeners...)
 	// Parameter overrides must be processed before any parameters are
 	// cached. Failures to cache cause the server to terminate immediately.
 	if chaincodeDevMode {
@@ -133,6 +155,10 @@ func serve(args []string) error {
 	// enable the cache of chaincode info
 	ccprovider.EnableCCInfoCache()
 
+	// process the statedb artifacts, such as the CouchDB indexes, of the chaincodes
+	// deployed on the channels, including the ones replayed while joining a channel
+	cceventmgmt.GetMgr().Initialize(&ccprovider.ChaincodeInfoProviderImpl{})
+
 	ccSrv, ccEpFunc := createChaincodeServer(peerServer, listenAddr)
 	registerChaincodeSupport(ccSrv.Server(), ccEpFunc)
 	go ccSrv.Start()
@@ -0,0 +1,87 @@
+/*
+Copyright IBM Corp. 2017 All Rights Reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+		 http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric/core/ledger/ledgerconfig"
+	"github.com/hyperledger/fabric/core/ledger/ledgerutil"
+	"github.com/hyperledger/fabric/peer/common"
+	"github.com/spf13/cobra"
+)
+
+var verifyChannelID string
+var verifyCompareWith string
+
+func verifyCmd() *cobra.Command {
+	flags := nodeVerifyCmd.Flags()
+	flags.StringVarP(&verifyChannelID, "channelID", "c", common.UndefinedParamValue, "Channel whose ledger is verified.")
+	flags.StringVarP(&verifyCompareWith, "compareWith", "", "",
+		"Block store directory (ledgersData/chains) copied from another peer, whose blocks are compared with the ones of this peer.")
+
+	return nodeVerifyCmd
+}
+
+var nodeVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verifies the integrity of the ledger of a channel.",
+	Long: `Verifies that the blocks of the ledger of a channel are chained to one another, match the hash of their data ` +
+		`and are signed by the orderers according to the channel config, that the block index points to the blocks ` +
+		`and that the state and history databases are at the last block. With --compareWith, the blocks are also ` +
+		`compared with the ones of another peer to find where the ledgers diverge. The peer must be stopped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyChannelID == common.UndefinedParamValue {
+			return fmt.Errorf("Must supply channel ID")
+		}
+		return verify()
+	},
+}
+
+func verify() error {
+	report, err := ledgerutil.VerifyLedger(verifyChannelID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Ledger of channel [%s]: %d blocks verified\n", verifyChannelID, report.Height)
+	for _, mismatch := range report.Mismatches {
+		fmt.Println(mismatch)
+	}
+
+	diverged := false
+	if verifyCompareWith != "" {
+		comparison, err := ledgerutil.CompareLedgers(verifyChannelID, ledgerconfig.GetBlockStorePath(), verifyCompareWith)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Compared with [%s]: %d blocks against %d blocks\n", verifyCompareWith, comparison.Height, comparison.OtherHeight)
+		if comparison.Divergence != "" {
+			fmt.Println(comparison.Divergence)
+			diverged = true
+		} else {
+			fmt.Println("The shorter ledger is identical to the start of the other one")
+		}
+	}
+
+	if len(report.Mismatches) > 0 {
+		return fmt.Errorf("Ledger of channel [%s] has %d inconsistencies", verifyChannelID, len(report.Mismatches))
+	}
+	if diverged {
+		return fmt.Errorf("Ledger of channel [%s] diverges from the one in [%s]", verifyChannelID, verifyCompareWith)
+	}
+	return nil
+}
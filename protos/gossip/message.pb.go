@@ -16,6 +16,7 @@ It has these top-level messages:
 	StateInfoSnapshot
 	StateInfoPullRequest
 	ConnEstablish
+	GossipBatch
 	PeerIdentity
 	DataRequest
 	GossipHello
@@ -296,6 +297,7 @@ type GossipMessage struct {
 	//	*GossipMessage_StateResponse
 	//	*GossipMessage_LeadershipMsg
 	//	*GossipMessage_PeerIdentity
+	//	*GossipMessage_Batch
 	Content isGossipMessage_Content `protobuf_oneof:"content"`
 }
 
@@ -359,6 +361,9 @@ type GossipMessage_LeadershipMsg struct {
 type GossipMessage_PeerIdentity struct {
 	PeerIdentity *PeerIdentity `protobuf:"bytes,21,opt,name=peer_identity,json=peerIdentity,oneof"`
 }
+type GossipMessage_Batch struct {
+	Batch *GossipBatch `protobuf:"bytes,22,opt,name=batch,oneof"`
+}
 
 func (*GossipMessage_AliveMsg) isGossipMessage_Content()         {}
 func (*GossipMessage_MemReq) isGossipMessage_Content()           {}
@@ -377,6 +382,7 @@ func (*GossipMessage_StateRequest) isGossipMessage_Content()     {}
 func (*GossipMessage_StateResponse) isGossipMessage_Content()    {}
 func (*GossipMessage_LeadershipMsg) isGossipMessage_Content()    {}
 func (*GossipMessage_PeerIdentity) isGossipMessage_Content()     {}
+func (*GossipMessage_Batch) isGossipMessage_Content()            {}
 
 func (m *GossipMessage) GetContent() isGossipMessage_Content {
 	if m != nil {
@@ -525,6 +531,13 @@ func (m *GossipMessage) GetPeerIdentity() *PeerIdentity {
 	return nil
 }
 
+func (m *GossipMessage) GetBatch() *GossipBatch {
+	if x, ok := m.GetContent().(*GossipMessage_Batch); ok {
+		return x.Batch
+	}
+	return nil
+}
+
 // XXX_OneofFuncs is for the internal use of the proto package.
 func (*GossipMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
 	return _GossipMessage_OneofMarshaler, _GossipMessage_OneofUnmarshaler, _GossipMessage_OneofSizer, []interface{}{
@@ -545,6 +558,7 @@ func (*GossipMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer)
 		(*GossipMessage_StateResponse)(nil),
 		(*GossipMessage_LeadershipMsg)(nil),
 		(*GossipMessage_PeerIdentity)(nil),
+		(*GossipMessage_Batch)(nil),
 	}
 }
 
@@ -637,6 +651,11 @@ func _GossipMessage_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
 		if err := b.EncodeMessage(x.PeerIdentity); err != nil {
 			return err
 		}
+	case *GossipMessage_Batch:
+		b.EncodeVarint(22<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Batch); err != nil {
+			return err
+		}
 	case nil:
 	default:
 		return fmt.Errorf("GossipMessage.Content has unexpected type %T", x)
@@ -783,6 +802,14 @@ func _GossipMessage_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.
 		err := b.DecodeMessage(msg)
 		m.Content = &GossipMessage_PeerIdentity{msg}
 		return true, err
+	case 22: // content.batch
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(GossipBatch)
+		err := b.DecodeMessage(msg)
+		m.Content = &GossipMessage_Batch{msg}
+		return true, err
 	default:
 		return false, nil
 	}
@@ -877,6 +904,11 @@ func _GossipMessage_OneofSizer(msg proto.Message) (n int) {
 		n += proto.SizeVarint(21<<3 | proto.WireBytes)
 		n += proto.SizeVarint(uint64(s))
 		n += s
+	case *GossipMessage_Batch:
+		s := proto.Size(x.Batch)
+		n += proto.SizeVarint(22<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
 	case nil:
 	default:
 		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
@@ -974,6 +1006,10 @@ type ConnEstablish struct {
 	PkiId       []byte `protobuf:"bytes,1,opt,name=pki_id,json=pkiId,proto3" json:"pki_id,omitempty"`
 	Identity    []byte `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
 	TlsCertHash []byte `protobuf:"bytes,3,opt,name=tls_cert_hash,json=tlsCertHash,proto3" json:"tls_cert_hash,omitempty"`
+	// Optional features of the communication layer
+	// that the peer is willing to use on the connection
+	Batching    bool     `protobuf:"varint,4,opt,name=batching" json:"batching,omitempty"`
+	Compression []string `protobuf:"bytes,5,rep,name=compression" json:"compression,omitempty"`
 }
 
 func (m *ConnEstablish) Reset()                    { *m = ConnEstablish{} }
@@ -1002,6 +1038,49 @@ func (m *ConnEstablish) GetTlsCertHash() []byte {
 	return nil
 }
 
+func (m *ConnEstablish) GetBatching() bool {
+	if m != nil {
+		return m.Batching
+	}
+	return false
+}
+
+func (m *ConnEstablish) GetCompression() []string {
+	if m != nil {
+		return m.Compression
+	}
+	return nil
+}
+
+// GossipBatch carries several messages sent to the same peer
+// as a single message, in order to save bandwidth on the link
+type GossipBatch struct {
+	Envelopes []*Envelope `protobuf:"bytes,1,rep,name=envelopes" json:"envelopes,omitempty"`
+	// compressed holds a marshaled GossipBatch compressed with the
+	// algorithm both peers agreed upon at the handshake.
+	// If it is set, envelopes is empty.
+	Compressed []byte `protobuf:"bytes,2,opt,name=compressed,proto3" json:"compressed,omitempty"`
+}
+
+func (m *GossipBatch) Reset()                    { *m = GossipBatch{} }
+func (m *GossipBatch) String() string            { return proto.CompactTextString(m) }
+func (*GossipBatch) ProtoMessage()               {}
+func (*GossipBatch) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *GossipBatch) GetEnvelopes() []*Envelope {
+	if m != nil {
+		return m.Envelopes
+	}
+	return nil
+}
+
+func (m *GossipBatch) GetCompressed() []byte {
+	if m != nil {
+		return m.Compressed
+	}
+	return nil
+}
+
 // PeerIdentity defines the identity of the peer
 // Used to make other peers learn of the identity
 // of a certain peer
@@ -1014,7 +1093,7 @@ type PeerIdentity struct {
 func (m *PeerIdentity) Reset()                    { *m = PeerIdentity{} }
 func (m *PeerIdentity) String() string            { return proto.CompactTextString(m) }
 func (*PeerIdentity) ProtoMessage()               {}
-func (*PeerIdentity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*PeerIdentity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
 func (m *PeerIdentity) GetPkiId() []byte {
 	if m != nil {
@@ -1048,7 +1127,7 @@ type DataRequest struct {
 func (m *DataRequest) Reset()                    { *m = DataRequest{} }
 func (m *DataRequest) String() string            { return proto.CompactTextString(m) }
 func (*DataRequest) ProtoMessage()               {}
-func (*DataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*DataRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
 
 func (m *DataRequest) GetNonce() uint64 {
 	if m != nil {
@@ -1082,7 +1161,7 @@ type GossipHello struct {
 func (m *GossipHello) Reset()                    { *m = GossipHello{} }
 func (m *GossipHello) String() string            { return proto.CompactTextString(m) }
 func (*GossipHello) ProtoMessage()               {}
-func (*GossipHello) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*GossipHello) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
 
 func (m *GossipHello) GetNonce() uint64 {
 	if m != nil {
@@ -1116,7 +1195,7 @@ type DataUpdate struct {
 func (m *DataUpdate) Reset()                    { *m = DataUpdate{} }
 func (m *DataUpdate) String() string            { return proto.CompactTextString(m) }
 func (*DataUpdate) ProtoMessage()               {}
-func (*DataUpdate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*DataUpdate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
 
 func (m *DataUpdate) GetNonce() uint64 {
 	if m != nil {
@@ -1150,7 +1229,7 @@ type DataDigest struct {
 func (m *DataDigest) Reset()                    { *m = DataDigest{} }
 func (m *DataDigest) String() string            { return proto.CompactTextString(m) }
 func (*DataDigest) ProtoMessage()               {}
-func (*DataDigest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*DataDigest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *DataDigest) GetNonce() uint64 {
 	if m != nil {
@@ -1181,7 +1260,7 @@ type DataMessage struct {
 func (m *DataMessage) Reset()                    { *m = DataMessage{} }
 func (m *DataMessage) String() string            { return proto.CompactTextString(m) }
 func (*DataMessage) ProtoMessage()               {}
-func (*DataMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*DataMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 func (m *DataMessage) GetPayload() *Payload {
 	if m != nil {
@@ -1199,7 +1278,7 @@ type Payload struct {
 func (m *Payload) Reset()                    { *m = Payload{} }
 func (m *Payload) String() string            { return proto.CompactTextString(m) }
 func (*Payload) ProtoMessage()               {}
-func (*Payload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*Payload) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
 func (m *Payload) GetSeqNum() uint64 {
 	if m != nil {
@@ -1226,7 +1305,7 @@ type AliveMessage struct {
 func (m *AliveMessage) Reset()                    { *m = AliveMessage{} }
 func (m *AliveMessage) String() string            { return proto.CompactTextString(m) }
 func (*AliveMessage) ProtoMessage()               {}
-func (*AliveMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*AliveMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 func (m *AliveMessage) GetMembership() *Member {
 	if m != nil {
@@ -1255,12 +1334,13 @@ type LeadershipMessage struct {
 	PkiId         []byte    `protobuf:"bytes,1,opt,name=pki_id,json=pkiId,proto3" json:"pki_id,omitempty"`
 	Timestamp     *PeerTime `protobuf:"bytes,2,opt,name=timestamp" json:"timestamp,omitempty"`
 	IsDeclaration bool      `protobuf:"varint,3,opt,name=is_declaration,json=isDeclaration" json:"is_declaration,omitempty"`
+	LedgerHeight  uint64    `protobuf:"varint,4,opt,name=ledger_height,json=ledgerHeight" json:"ledger_height,omitempty"`
 }
 
 func (m *LeadershipMessage) Reset()                    { *m = LeadershipMessage{} }
 func (m *LeadershipMessage) String() string            { return proto.CompactTextString(m) }
 func (*LeadershipMessage) ProtoMessage()               {}
-func (*LeadershipMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*LeadershipMessage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *LeadershipMessage) GetPkiId() []byte {
 	if m != nil {
@@ -1283,6 +1363,13 @@ func (m *LeadershipMessage) GetIsDeclaration() bool {
 	return false
 }
 
+func (m *LeadershipMessage) GetLedgerHeight() uint64 {
+	if m != nil {
+		return m.LedgerHeight
+	}
+	return 0
+}
+
 // PeerTime defines the logical time of a peer's life
 type PeerTime struct {
 	IncNum uint64 `protobuf:"varint,1,opt,name=inc_num,json=incNum" json:"inc_num,omitempty"`
@@ -1292,7 +1379,7 @@ type PeerTime struct {
 func (m *PeerTime) Reset()                    { *m = PeerTime{} }
 func (m *PeerTime) String() string            { return proto.CompactTextString(m) }
 func (*PeerTime) ProtoMessage()               {}
-func (*PeerTime) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*PeerTime) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *PeerTime) GetIncNum() uint64 {
 	if m != nil {
@@ -1318,7 +1405,7 @@ type MembershipRequest struct {
 func (m *MembershipRequest) Reset()                    { *m = MembershipRequest{} }
 func (m *MembershipRequest) String() string            { return proto.CompactTextString(m) }
 func (*MembershipRequest) ProtoMessage()               {}
-func (*MembershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*MembershipRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *MembershipRequest) GetSelfInformation() *Envelope {
 	if m != nil {
@@ -1343,7 +1430,7 @@ type MembershipResponse struct {
 func (m *MembershipResponse) Reset()                    { *m = MembershipResponse{} }
 func (m *MembershipResponse) String() string            { return proto.CompactTextString(m) }
 func (*MembershipResponse) ProtoMessage()               {}
-func (*MembershipResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+func (*MembershipResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
 
 func (m *MembershipResponse) GetAlive() []*Envelope {
 	if m != nil {
@@ -1370,7 +1457,7 @@ type Member struct {
 func (m *Member) Reset()                    { *m = Member{} }
 func (m *Member) String() string            { return proto.CompactTextString(m) }
 func (*Member) ProtoMessage()               {}
-func (*Member) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+func (*Member) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
 
 func (m *Member) GetEndpoint() string {
 	if m != nil {
@@ -1400,7 +1487,7 @@ type Empty struct {
 func (m *Empty) Reset()                    { *m = Empty{} }
 func (m *Empty) String() string            { return proto.CompactTextString(m) }
 func (*Empty) ProtoMessage()               {}
-func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+func (*Empty) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
 
 // RemoteStateRequest is used to ask a set of blocks
 // from a remote peer
@@ -1412,7 +1499,7 @@ type RemoteStateRequest struct {
 func (m *RemoteStateRequest) Reset()                    { *m = RemoteStateRequest{} }
 func (m *RemoteStateRequest) String() string            { return proto.CompactTextString(m) }
 func (*RemoteStateRequest) ProtoMessage()               {}
-func (*RemoteStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+func (*RemoteStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
 
 func (m *RemoteStateRequest) GetStartSeqNum() uint64 {
 	if m != nil {
@@ -1437,7 +1524,7 @@ type RemoteStateResponse struct {
 func (m *RemoteStateResponse) Reset()                    { *m = RemoteStateResponse{} }
 func (m *RemoteStateResponse) String() string            { return proto.CompactTextString(m) }
 func (*RemoteStateResponse) ProtoMessage()               {}
-func (*RemoteStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+func (*RemoteStateResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
 
 func (m *RemoteStateResponse) GetPayloads() []*Payload {
 	if m != nil {
@@ -1455,6 +1542,7 @@ func init() {
 	proto.RegisterType((*StateInfoSnapshot)(nil), "gossip.StateInfoSnapshot")
 	proto.RegisterType((*StateInfoPullRequest)(nil), "gossip.StateInfoPullRequest")
 	proto.RegisterType((*ConnEstablish)(nil), "gossip.ConnEstablish")
+	proto.RegisterType((*GossipBatch)(nil), "gossip.GossipBatch")
 	proto.RegisterType((*PeerIdentity)(nil), "gossip.PeerIdentity")
 	proto.RegisterType((*DataRequest)(nil), "gossip.DataRequest")
 	proto.RegisterType((*GossipHello)(nil), "gossip.GossipHello")
@@ -1620,91 +1708,97 @@ var _Gossip_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("gossip/message.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1366 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xb4, 0x57, 0xdd, 0x6f, 0xdc, 0x44,
-	0x10, 0x3f, 0x27, 0xf7, 0x39, 0xf7, 0x91, 0xcb, 0x26, 0x05, 0x13, 0x2a, 0x88, 0x2c, 0x5a, 0x05,
-	0x52, 0x2e, 0x55, 0xca, 0x47, 0xa5, 0x82, 0xd0, 0x25, 0x77, 0xe4, 0x22, 0x7a, 0x49, 0xe4, 0xa4,
-	0x82, 0xf2, 0x62, 0x6d, 0xce, 0x13, 0x9f, 0xa9, 0xbd, 0x76, 0xbc, 0x7b, 0x85, 0x3c, 0x22, 0xde,
-	0x78, 0xe1, 0x95, 0x3f, 0x17, 0x79, 0xd7, 0xf6, 0xd9, 0x75, 0x52, 0xa9, 0x95, 0x78, 0xf3, 0x7c,
-	0xfc, 0x66, 0x66, 0x67, 0xe7, 0x63, 0x0d, 0x9b, 0x4e, 0xc0, 0xb9, 0x1b, 0xee, 0xf9, 0xc8, 0x39,
-	0x75, 0x70, 0x10, 0x46, 0x81, 0x08, 0x48, 0x5d, 0x71, 0x8d, 0xbf, 0x34, 0x68, 0x8e, 0xd9, 0x6b,
-	0xf4, 0x82, 0x10, 0x89, 0x0e, 0x8d, 0x90, 0xde, 0x78, 0x01, 0xb5, 0x75, 0x6d, 0x5b, 0xdb, 0xe9,
-	0x98, 0x29, 0x49, 0xee, 0x43, 0x8b, 0xbb, 0x0e, 0xa3, 0x62, 0x11, 0xa1, 0xbe, 0x22, 0x65, 0x4b,
-	0x06, 0xf9, 0x01, 0xd6, 0x38, 0xce, 0x22, 0x14, 0x16, 0x26, 0xa6, 0xf4, 0xd5, 0x6d, 0x6d, 0xa7,
-	0xbd, 0xff, 0xc1, 0x40, 0xb9, 0x19, 0x9c, 0x4b, 0x71, 0xea, 0xc8, 0xec, 0xf1, 0x02, 0x6d, 0x4c,
-	0xa0, 0x57, 0xd4, 0x78, 0xdf, 0x50, 0x8c, 0x21, 0xd4, 0x95, 0x25, 0xf2, 0x08, 0xfa, 0x2e, 0x13,
-	0x18, 0x31, 0xea, 0x8d, 0x99, 0x1d, 0x06, 0x2e, 0x13, 0xd2, 0x54, 0x6b, 0x52, 0x31, 0x4b, 0x92,
-	0x83, 0x16, 0x34, 0x66, 0x01, 0x13, 0xc8, 0x84, 0xf1, 0x6f, 0x0b, 0xba, 0x47, 0x32, 0xec, 0xa9,
-	0x4a, 0x19, 0xd9, 0x84, 0x1a, 0x0b, 0xd8, 0x0c, 0x25, 0xbe, 0x6a, 0x2a, 0x22, 0x0e, 0x71, 0x36,
-	0xa7, 0x8c, 0xa1, 0x97, 0x84, 0x91, 0x92, 0x64, 0x17, 0x56, 0x05, 0x75, 0x64, 0x0e, 0x7a, 0xfb,
-	0x1f, 0xa5, 0x39, 0x28, 0xd8, 0x1c, 0x5c, 0x50, 0xc7, 0x8c, 0xb5, 0xc8, 0x13, 0x68, 0x51, 0xcf,
-	0x7d, 0x8d, 0x96, 0xcf, 0x1d, 0xbd, 0x26, 0xd3, 0xb6, 0x99, 0x42, 0x86, 0xb1, 0x20, 0x41, 0x4c,
-	0x2a, 0x66, 0x53, 0x2a, 0x4e, 0xb9, 0x43, 0xbe, 0x82, 0x86, 0x8f, 0xbe, 0x15, 0xe1, 0xb5, 0x5e,
-	0x97, 0x90, 0xcc, 0xcb, 0x14, 0xfd, 0x4b, 0x8c, 0xf8, 0xdc, 0x0d, 0x4d, 0xbc, 0x5e, 0x20, 0x17,
-	0x93, 0x8a, 0x59, 0xf7, 0xd1, 0x37, 0xf1, 0x9a, 0x7c, 0x9d, 0xa2, 0xb8, 0xde, 0x90, 0xa8, 0xad,
-	0xdb, 0x50, 0x3c, 0x0c, 0x18, 0xc7, 0x0c, 0xc6, 0xc9, 0x63, 0x68, 0xda, 0x54, 0x50, 0x19, 0x60,
-	0x53, 0xe2, 0x36, 0x52, 0xdc, 0x88, 0x0a, 0xba, 0x8c, 0xaf, 0x11, 0xab, 0xc5, 0xe1, 0xed, 0x42,
-	0x6d, 0x8e, 0x9e, 0x17, 0xe8, 0xad, 0xa2, 0xba, 0x4a, 0xc1, 0x24, 0x16, 0x4d, 0x2a, 0xa6, 0xd2,
-	0x21, 0x7b, 0x89, 0x79, 0xdb, 0x75, 0x74, 0x90, 0xfa, 0x24, 0x6f, 0x7e, 0xe4, 0x3a, 0xea, 0x14,
-	0xd2, 0xfa, 0xc8, 0x75, 0xb2, 0x78, 0xe2, 0xd3, 0xb7, 0xcb, 0xf1, 0x2c, 0xcf, 0x2d, 0x11, 0xea,
-	0xe0, 0x6d, 0x89, 0x58, 0x84, 0x36, 0x15, 0xa8, 0x77, 0xca, 0x5e, 0x5e, 0x48, 0xc9, 0xa4, 0x62,
-	0x82, 0x9d, 0x51, 0xe4, 0x01, 0xd4, 0xd0, 0x0f, 0xc5, 0x8d, 0xde, 0x95, 0x80, 0x6e, 0x0a, 0x18,
-	0xc7, 0xcc, 0xf8, 0x00, 0x52, 0x4a, 0x76, 0xa1, 0x3a, 0x0b, 0x18, 0xd3, 0x7b, 0x52, 0xeb, 0x5e,
-	0xaa, 0x75, 0x18, 0x30, 0x36, 0xe6, 0x82, 0x5e, 0x7a, 0x2e, 0x9f, 0x4f, 0x2a, 0xa6, 0x54, 0x22,
-	0xfb, 0x00, 0x5c, 0x50, 0x81, 0x96, 0xcb, 0xae, 0x02, 0x7d, 0x4d, 0x42, 0xd6, 0xb3, 0x36, 0x89,
-	0x25, 0xc7, 0xec, 0x2a, 0xce, 0x4e, 0x8b, 0xa7, 0x04, 0x39, 0x80, 0x9e, 0xc2, 0x70, 0x46, 0x43,
-	0x3e, 0x0f, 0x84, 0xde, 0x2f, 0x5e, 0x7a, 0x86, 0x3b, 0x4f, 0x14, 0x26, 0x15, 0xb3, 0x2b, 0x21,
-	0x29, 0x83, 0x4c, 0x61, 0x63, 0xe9, 0xd7, 0x0a, 0x17, 0x9e, 0x27, 0xf3, 0xb7, 0x2e, 0x0d, 0xdd,
-	0x2f, 0x19, 0x3a, 0x5b, 0x78, 0xde, 0x32, 0x91, 0x7d, 0xfe, 0x06, 0x9f, 0x0c, 0x41, 0xd9, 0x8f,
-	0x8d, 0xc4, 0x4a, 0x3a, 0x29, 0x16, 0x94, 0x89, 0x7e, 0x20, 0x50, 0x9a, 0x5b, 0x9a, 0xe9, 0xf0,
-	0x1c, 0x4d, 0x46, 0xe9, 0xa9, 0xa2, 0xa4, 0xe4, 0xf4, 0x0d, 0x69, 0xe3, 0xe3, 0x5b, 0x6d, 0x64,
-	0x55, 0xd9, 0xe5, 0x79, 0x46, 0x9c, 0x1b, 0x0f, 0xa9, 0xad, 0x8a, 0x57, 0x96, 0xe8, 0x66, 0x31,
-	0x37, 0xcf, 0x33, 0xe9, 0xb2, 0x50, 0xbb, 0x4b, 0x48, 0x5c, 0xae, 0xcf, 0xa0, 0x1b, 0x22, 0x46,
-	0x96, 0x6b, 0x23, 0x13, 0xae, 0xb8, 0xd1, 0xef, 0x15, 0xdb, 0xf0, 0x0c, 0x31, 0x3a, 0x4e, 0x64,
-	0xf1, 0x31, 0xc2, 0x1c, 0x6d, 0x58, 0xb0, 0x7a, 0x41, 0x1d, 0xd2, 0x85, 0xd6, 0x8b, 0x93, 0xd1,
-	0xf8, 0xc7, 0xe3, 0x93, 0xf1, 0xa8, 0x5f, 0x21, 0x2d, 0xa8, 0x8d, 0xa7, 0x67, 0x17, 0x2f, 0xfb,
-	0x1a, 0xe9, 0x40, 0xf3, 0xd4, 0x3c, 0xb2, 0x4e, 0x4f, 0x9e, 0xbf, 0xec, 0xaf, 0xc4, 0x7a, 0x87,
-	0x93, 0xe1, 0x89, 0x22, 0x57, 0x49, 0x1f, 0x3a, 0x92, 0x1c, 0x9e, 0x8c, 0xac, 0x53, 0xf3, 0xa8,
-	0x5f, 0x25, 0x6b, 0xd0, 0x56, 0x0a, 0xa6, 0x64, 0xd4, 0xf2, 0xa3, 0xe9, 0x1f, 0x0d, 0x5a, 0xd9,
-	0x15, 0x91, 0x2d, 0x68, 0xfa, 0x28, 0x68, 0x5c, 0xb0, 0xc9, 0x90, 0xcc, 0x68, 0x32, 0x80, 0x96,
-	0x70, 0x7d, 0xe4, 0x82, 0xfa, 0xa1, 0x1c, 0x4f, 0xed, 0xfd, 0x7e, 0xfe, 0x38, 0x17, 0xae, 0x8f,
-	0xe6, 0x52, 0x85, 0xdc, 0x83, 0x7a, 0xf8, 0xca, 0xb5, 0x5c, 0x5b, 0x4e, 0xad, 0x8e, 0x59, 0x0b,
-	0x5f, 0xb9, 0xc7, 0x36, 0xf9, 0x14, 0xda, 0xc9, 0x50, 0xb3, 0xa6, 0xc3, 0x43, 0xbd, 0x2a, 0x65,
-	0x90, 0xb0, 0xa6, 0xc3, 0x43, 0x63, 0x08, 0xeb, 0xa5, 0xe2, 0x23, 0x8f, 0xa0, 0x89, 0x1e, 0xfa,
-	0xc8, 0x04, 0xd7, 0xb5, 0xed, 0xd5, 0xbc, 0xef, 0x6c, 0x05, 0x64, 0x1a, 0xc6, 0xb7, 0xb0, 0x79,
-	0x5b, 0xd9, 0xbd, 0xe9, 0x5b, 0x2b, 0xf9, 0xbe, 0x82, 0x6e, 0xa1, 0xc7, 0x72, 0x87, 0xd0, 0xf2,
-	0x87, 0xd8, 0x82, 0x66, 0x76, 0xb3, 0x6a, 0x52, 0x67, 0x34, 0x31, 0xa0, 0x2b, 0x3c, 0x6e, 0xcd,
-	0x30, 0x12, 0xd6, 0x9c, 0xf2, 0x79, 0x72, 0xfc, 0xb6, 0xf0, 0xf8, 0x21, 0x46, 0x62, 0x42, 0xf9,
-	0xdc, 0x78, 0x01, 0x9d, 0x7c, 0x05, 0xdc, 0xe5, 0x86, 0x40, 0x35, 0x36, 0x93, 0xb8, 0x90, 0xdf,
-	0x85, 0x2b, 0x5a, 0x2d, 0x5e, 0x91, 0xe1, 0x43, 0x3b, 0x37, 0xae, 0xee, 0x5e, 0x32, 0xb6, 0x1c,
-	0x80, 0x5c, 0x5f, 0xd9, 0x5e, 0xdd, 0x69, 0x99, 0x29, 0x49, 0x06, 0xd0, 0xf4, 0xb9, 0x63, 0x89,
-	0x9b, 0x64, 0xdb, 0xf6, 0x96, 0x53, 0x30, 0xce, 0xe2, 0x94, 0x3b, 0x17, 0x37, 0x21, 0x9a, 0x0d,
-	0x5f, 0x7d, 0x18, 0x01, 0xb4, 0x73, 0xe3, 0xf7, 0x0e, 0x77, 0xf9, 0x78, 0x57, 0x4a, 0x25, 0xf5,
-	0x6e, 0x0e, 0xff, 0x00, 0x58, 0x4e, 0xd6, 0x3b, 0xfc, 0x7d, 0x06, 0xd5, 0xc4, 0xd7, 0xed, 0x55,
-	0x52, 0x7d, 0x2f, 0xcf, 0x9e, 0xf2, 0xac, 0x36, 0xc7, 0xff, 0x9e, 0xd8, 0xa7, 0xea, 0x1e, 0xd3,
-	0xc7, 0xc2, 0xe7, 0xc5, 0x97, 0x4b, 0x7b, 0x7f, 0x2d, 0x43, 0x2b, 0x76, 0xf6, 0x94, 0x31, 0xbe,
-	0x81, 0x46, 0xc2, 0x23, 0x1f, 0x42, 0x83, 0xe3, 0xb5, 0xc5, 0x16, 0x7e, 0x12, 0x66, 0x9d, 0xe3,
-	0xf5, 0xc9, 0xc2, 0x8f, 0xab, 0x2a, 0x77, 0x1b, 0xf2, 0xdb, 0xf8, 0x5b, 0x83, 0x4e, 0xfe, 0x69,
-	0x40, 0x06, 0x00, 0x7e, 0xb6, 0xc1, 0x13, 0xb7, 0xbd, 0xe2, 0x6e, 0x37, 0x73, 0x1a, 0xef, 0x3c,
-	0x1d, 0xf2, 0x1d, 0x54, 0x2d, 0x76, 0x90, 0xf1, 0xa7, 0x06, 0xeb, 0xa5, 0x19, 0x7b, 0x57, 0x8f,
-	0xbc, 0xab, 0xe3, 0x07, 0xd0, 0x73, 0xb9, 0x65, 0xe3, 0xcc, 0xa3, 0x11, 0x15, 0x6e, 0xc0, 0xe4,
-	0x8d, 0x34, 0xcd, 0xae, 0xcb, 0x47, 0x4b, 0xa6, 0xf1, 0x1d, 0x34, 0x53, 0x74, 0x9c, 0x49, 0x97,
-	0xcd, 0xf2, 0x99, 0x74, 0xd9, 0x2c, 0xce, 0x64, 0x2e, 0xc5, 0x2b, 0xf9, 0x14, 0x1b, 0x57, 0xb0,
-	0x5e, 0x7a, 0x35, 0x91, 0x67, 0xd0, 0xe7, 0xe8, 0x5d, 0xc9, 0x75, 0x19, 0xf9, 0xca, 0xb7, 0x56,
-	0x0c, 0x38, 0xab, 0xd2, 0xb5, 0x58, 0xf3, 0x78, 0xa9, 0x18, 0x97, 0xdc, 0x2b, 0x16, 0xfc, 0xce,
-	0x64, 0x69, 0x75, 0x4c, 0x45, 0x18, 0x97, 0x40, 0xca, 0xef, 0x2c, 0xf2, 0x10, 0x6a, 0xf2, 0x59,
-	0x77, 0xe7, 0xa4, 0x54, 0x62, 0xd9, 0x2a, 0x48, 0xed, 0xb7, 0xb4, 0x0a, 0x52, 0xdb, 0xf8, 0x19,
-	0xea, 0xca, 0x47, 0x7c, 0x67, 0x58, 0x78, 0xf7, 0x9a, 0x19, 0xfd, 0xd6, 0x36, 0xbf, 0x7d, 0x13,
-	0x18, 0x0d, 0xa8, 0xc9, 0x67, 0x8f, 0xf1, 0x0b, 0x90, 0xf2, 0x72, 0x8f, 0xe7, 0x28, 0x17, 0x34,
-	0x12, 0x56, 0xb1, 0x8a, 0xdb, 0x92, 0x79, 0xae, 0x4a, 0xf9, 0x13, 0x68, 0x23, 0xb3, 0xad, 0xe2,
-	0x25, 0xb4, 0x90, 0xd9, 0x4a, 0x6e, 0x1c, 0xc0, 0xc6, 0x2d, 0x2b, 0x9f, 0xec, 0x42, 0x33, 0x69,
-	0x98, 0x74, 0x9b, 0x94, 0x3a, 0x2a, 0x53, 0xf8, 0xe2, 0x7b, 0x68, 0xe7, 0x9a, 0xf4, 0xcd, 0xad,
-	0xdc, 0x85, 0xd6, 0xc1, 0xf3, 0xd3, 0xc3, 0x9f, 0xac, 0xe9, 0xf9, 0x51, 0x5f, 0x8b, 0x97, 0xef,
-	0xf1, 0x68, 0x7c, 0x72, 0x71, 0x7c, 0xf1, 0x52, 0x72, 0x56, 0xf6, 0x7f, 0x83, 0xba, 0x1a, 0x92,
-	0xe4, 0x29, 0x74, 0xd4, 0xd7, 0xb9, 0x88, 0x90, 0xfa, 0xa4, 0x94, 0xf0, 0xad, 0x12, 0xc7, 0xa8,
-	0xec, 0x68, 0x8f, 0x35, 0xf2, 0x10, 0xaa, 0x67, 0x2e, 0x73, 0x48, 0xf1, 0xb9, 0xb8, 0x55, 0x24,
-	0x8d, 0xca, 0xc1, 0x97, 0xbf, 0xee, 0x3a, 0xae, 0x98, 0x2f, 0x2e, 0x07, 0xb3, 0xc0, 0xdf, 0x9b,
-	0xdf, 0x84, 0x18, 0x79, 0x68, 0x3b, 0x18, 0xed, 0x5d, 0xd1, 0xcb, 0xc8, 0x9d, 0xed, 0xc9, 0x3f,
-	0x35, 0xbe, 0xa7, 0x60, 0x97, 0x75, 0x49, 0x3e, 0xf9, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x74, 0x25,
-	0x05, 0xf1, 0xd0, 0x0d, 0x00, 0x00,
+	// 1457 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0x5f, 0x6f, 0xdb, 0x46,
+	0x12, 0x17, 0xad, 0xff, 0x43, 0x49, 0x96, 0xd7, 0x4e, 0x8e, 0xe7, 0x0b, 0x72, 0x06, 0xef, 0x12,
+	0xf8, 0xce, 0x39, 0x39, 0x70, 0xae, 0x6d, 0x80, 0xb4, 0x28, 0x64, 0x4b, 0xb5, 0x8c, 0x46, 0xb6,
+	0x41, 0x3b, 0x68, 0x53, 0xa0, 0x20, 0xd6, 0xe2, 0x9a, 0x62, 0x43, 0x2e, 0x69, 0xee, 0x2a, 0xad,
+	0x9f, 0xfb, 0xd6, 0x97, 0x7e, 0x89, 0xa2, 0xdf, 0xa4, 0xdf, 0xab, 0xd8, 0x5d, 0xfe, 0x8d, 0xe4,
+	0x00, 0x09, 0xd0, 0x37, 0xce, 0xff, 0xd9, 0xd9, 0xdf, 0xcc, 0x0e, 0x61, 0xcb, 0x0d, 0x19, 0xf3,
+	0xa2, 0xfd, 0x80, 0x30, 0x86, 0x5d, 0x32, 0x88, 0xe2, 0x90, 0x87, 0xa8, 0xa1, 0xb8, 0xe6, 0xcf,
+	0x1a, 0xb4, 0xc6, 0xf4, 0x2d, 0xf1, 0xc3, 0x88, 0x20, 0x03, 0x9a, 0x11, 0xbe, 0xf5, 0x43, 0xec,
+	0x18, 0xda, 0x8e, 0xb6, 0xdb, 0xb1, 0x52, 0x12, 0x3d, 0x80, 0x36, 0xf3, 0x5c, 0x8a, 0xf9, 0x22,
+	0x26, 0xc6, 0x9a, 0x94, 0xe5, 0x0c, 0xf4, 0x25, 0xac, 0x33, 0x32, 0x8b, 0x09, 0xb7, 0x49, 0xe2,
+	0xca, 0xa8, 0xee, 0x68, 0xbb, 0xfa, 0xc1, 0xfd, 0x81, 0x0a, 0x33, 0xb8, 0x90, 0xe2, 0x34, 0x90,
+	0xd5, 0x63, 0x25, 0xda, 0x9c, 0x40, 0xaf, 0xac, 0xf1, 0xb1, 0xa9, 0x98, 0x43, 0x68, 0x28, 0x4f,
+	0xe8, 0x09, 0xf4, 0x3d, 0xca, 0x49, 0x4c, 0xb1, 0x3f, 0xa6, 0x4e, 0x14, 0x7a, 0x94, 0x4b, 0x57,
+	0xed, 0x49, 0xc5, 0x5a, 0x92, 0x1c, 0xb6, 0xa1, 0x39, 0x0b, 0x29, 0x27, 0x94, 0x9b, 0x7f, 0xb4,
+	0xa1, 0x7b, 0x2c, 0xd3, 0x9e, 0xaa, 0x92, 0xa1, 0x2d, 0xa8, 0xd3, 0x90, 0xce, 0x88, 0xb4, 0xaf,
+	0x59, 0x8a, 0x10, 0x29, 0xce, 0xe6, 0x98, 0x52, 0xe2, 0x27, 0x69, 0xa4, 0x24, 0xda, 0x83, 0x2a,
+	0xc7, 0xae, 0xac, 0x41, 0xef, 0xe0, 0xef, 0x69, 0x0d, 0x4a, 0x3e, 0x07, 0x97, 0xd8, 0xb5, 0x84,
+	0x16, 0x7a, 0x06, 0x6d, 0xec, 0x7b, 0x6f, 0x89, 0x1d, 0x30, 0xd7, 0xa8, 0xcb, 0xb2, 0x6d, 0xa5,
+	0x26, 0x43, 0x21, 0x48, 0x2c, 0x26, 0x15, 0xab, 0x25, 0x15, 0xa7, 0xcc, 0x45, 0xff, 0x87, 0x66,
+	0x40, 0x02, 0x3b, 0x26, 0x37, 0x46, 0x43, 0x9a, 0x64, 0x51, 0xa6, 0x24, 0xb8, 0x22, 0x31, 0x9b,
+	0x7b, 0x91, 0x45, 0x6e, 0x16, 0x84, 0xf1, 0x49, 0xc5, 0x6a, 0x04, 0x24, 0xb0, 0xc8, 0x0d, 0xfa,
+	0x24, 0xb5, 0x62, 0x46, 0x53, 0x5a, 0x6d, 0xaf, 0xb2, 0x62, 0x51, 0x48, 0x19, 0xc9, 0xcc, 0x18,
+	0x7a, 0x0a, 0x2d, 0x07, 0x73, 0x2c, 0x13, 0x6c, 0x49, 0xbb, 0xcd, 0xd4, 0x6e, 0x84, 0x39, 0xce,
+	0xf3, 0x6b, 0x0a, 0x35, 0x91, 0xde, 0x1e, 0xd4, 0xe7, 0xc4, 0xf7, 0x43, 0xa3, 0x5d, 0x56, 0x57,
+	0x25, 0x98, 0x08, 0xd1, 0xa4, 0x62, 0x29, 0x1d, 0xb4, 0x9f, 0xb8, 0x77, 0x3c, 0xd7, 0x00, 0xa9,
+	0x8f, 0x8a, 0xee, 0x47, 0x9e, 0xab, 0x4e, 0x21, 0xbd, 0x8f, 0x3c, 0x37, 0xcb, 0x47, 0x9c, 0x5e,
+	0x5f, 0xce, 0x27, 0x3f, 0xb7, 0xb4, 0x50, 0x07, 0xd7, 0xa5, 0xc5, 0x22, 0x72, 0x30, 0x27, 0x46,
+	0x67, 0x39, 0xca, 0x2b, 0x29, 0x99, 0x54, 0x2c, 0x70, 0x32, 0x0a, 0x3d, 0x82, 0x3a, 0x09, 0x22,
+	0x7e, 0x6b, 0x74, 0xa5, 0x41, 0x37, 0x35, 0x18, 0x0b, 0xa6, 0x38, 0x80, 0x94, 0xa2, 0x3d, 0xa8,
+	0xcd, 0x42, 0x4a, 0x8d, 0x9e, 0xd4, 0xba, 0x97, 0x6a, 0x1d, 0x85, 0x94, 0x8e, 0x19, 0xc7, 0x57,
+	0xbe, 0xc7, 0xe6, 0x93, 0x8a, 0x25, 0x95, 0xd0, 0x01, 0x00, 0xe3, 0x98, 0x13, 0xdb, 0xa3, 0xd7,
+	0xa1, 0xb1, 0x2e, 0x4d, 0x36, 0xb2, 0x36, 0x11, 0x92, 0x13, 0x7a, 0x2d, 0xaa, 0xd3, 0x66, 0x29,
+	0x81, 0x0e, 0xa1, 0xa7, 0x6c, 0x18, 0xc5, 0x11, 0x9b, 0x87, 0xdc, 0xe8, 0x97, 0x2f, 0x3d, 0xb3,
+	0xbb, 0x48, 0x14, 0x26, 0x15, 0xab, 0x2b, 0x4d, 0x52, 0x06, 0x9a, 0xc2, 0x66, 0x1e, 0xd7, 0x8e,
+	0x16, 0xbe, 0x2f, 0xeb, 0xb7, 0x21, 0x1d, 0x3d, 0x58, 0x72, 0x74, 0xbe, 0xf0, 0xfd, 0xbc, 0x90,
+	0x7d, 0xf6, 0x0e, 0x1f, 0x0d, 0x41, 0xf9, 0xb7, 0x63, 0xa5, 0x64, 0xa0, 0x32, 0xa0, 0x2c, 0x12,
+	0x84, 0x9c, 0x48, 0x77, 0xb9, 0x9b, 0x0e, 0x2b, 0xd0, 0x68, 0x94, 0x9e, 0x2a, 0x4e, 0x20, 0x67,
+	0x6c, 0x4a, 0x1f, 0xff, 0x58, 0xe9, 0x23, 0x43, 0x65, 0x97, 0x15, 0x19, 0xa2, 0x36, 0x3e, 0xc1,
+	0x8e, 0x02, 0xaf, 0x84, 0xe8, 0x56, 0xb9, 0x36, 0x2f, 0x33, 0x69, 0x0e, 0xd4, 0x6e, 0x6e, 0x22,
+	0xe0, 0xfa, 0x02, 0xba, 0x11, 0x21, 0xb1, 0xed, 0x39, 0x84, 0x72, 0x8f, 0xdf, 0x1a, 0xf7, 0xca,
+	0x6d, 0x78, 0x4e, 0x48, 0x7c, 0x92, 0xc8, 0xc4, 0x31, 0xa2, 0x02, 0x2d, 0xb0, 0x7e, 0x85, 0xf9,
+	0x6c, 0x6e, 0xdc, 0x5f, 0x85, 0xf5, 0x43, 0x21, 0x12, 0x50, 0x91, 0x3a, 0xa6, 0x0d, 0xd5, 0x4b,
+	0xec, 0xa2, 0x2e, 0xb4, 0x5f, 0x9d, 0x8e, 0xc6, 0x5f, 0x9d, 0x9c, 0x8e, 0x47, 0xfd, 0x0a, 0x6a,
+	0x43, 0x7d, 0x3c, 0x3d, 0xbf, 0x7c, 0xdd, 0xd7, 0x50, 0x07, 0x5a, 0x67, 0xd6, 0xb1, 0x7d, 0x76,
+	0xfa, 0xf2, 0x75, 0x7f, 0x4d, 0xe8, 0x1d, 0x4d, 0x86, 0xa7, 0x8a, 0xac, 0xa2, 0x3e, 0x74, 0x24,
+	0x39, 0x3c, 0x1d, 0xd9, 0x67, 0xd6, 0x71, 0xbf, 0x86, 0xd6, 0x41, 0x57, 0x0a, 0x96, 0x64, 0xd4,
+	0x8b, 0x73, 0xec, 0x57, 0x0d, 0xda, 0xd9, 0x7d, 0xa2, 0x6d, 0x68, 0x05, 0x84, 0x63, 0x81, 0xee,
+	0x64, 0xa2, 0x66, 0x34, 0x1a, 0x40, 0x9b, 0x7b, 0x01, 0x61, 0x1c, 0x07, 0x91, 0x9c, 0x65, 0xfa,
+	0x41, 0xbf, 0x78, 0xf6, 0x4b, 0x2f, 0x20, 0x56, 0xae, 0x82, 0xee, 0x41, 0x23, 0x7a, 0xe3, 0xd9,
+	0x9e, 0x23, 0x47, 0x5c, 0xc7, 0xaa, 0x47, 0x6f, 0xbc, 0x13, 0x07, 0xfd, 0x13, 0xf4, 0x64, 0x02,
+	0xda, 0xd3, 0xe1, 0x91, 0x51, 0x93, 0x32, 0x48, 0x58, 0xd3, 0xe1, 0x91, 0x39, 0x84, 0x8d, 0x25,
+	0xa4, 0xa2, 0x27, 0xd0, 0x22, 0x3e, 0x09, 0x08, 0xe5, 0xcc, 0xd0, 0x76, 0xaa, 0xc5, 0xd8, 0xd9,
+	0x7b, 0x91, 0x69, 0x98, 0x9f, 0xc1, 0xd6, 0x2a, 0x8c, 0xbe, 0x1b, 0x5b, 0x5b, 0x8a, 0xfd, 0x9b,
+	0x06, 0xdd, 0x52, 0x47, 0x16, 0x4e, 0xa1, 0x15, 0x4f, 0xb1, 0x0d, 0xad, 0x0c, 0x07, 0x6a, 0xae,
+	0x67, 0x34, 0x32, 0xa1, 0xcb, 0x7d, 0x66, 0xcf, 0x48, 0xcc, 0xed, 0x39, 0x66, 0xf3, 0xe4, 0xfc,
+	0x3a, 0xf7, 0xd9, 0x11, 0x89, 0xf9, 0x04, 0xb3, 0xb9, 0xb0, 0x97, 0x77, 0xed, 0x51, 0x57, 0x96,
+	0xa0, 0x65, 0x65, 0x34, 0xda, 0x01, 0x7d, 0x16, 0x06, 0x51, 0x4c, 0x18, 0xf3, 0x42, 0x6a, 0xd4,
+	0x77, 0xaa, 0xbb, 0x6d, 0xab, 0xc8, 0x32, 0xbf, 0x07, 0xbd, 0x00, 0x1c, 0x71, 0x33, 0xe9, 0x93,
+	0x7a, 0x77, 0x75, 0x72, 0x15, 0xf4, 0x10, 0x20, 0xf5, 0x46, 0x9c, 0x24, 0xfd, 0x02, 0xc7, 0x7c,
+	0x05, 0x9d, 0x22, 0x98, 0xef, 0xaa, 0x01, 0x82, 0x9a, 0x38, 0x63, 0xe2, 0x40, 0x7e, 0x97, 0x00,
+	0x54, 0x2d, 0x03, 0xc8, 0x0c, 0x40, 0x2f, 0x4c, 0xde, 0xbb, 0xdf, 0x4b, 0x47, 0xce, 0x72, 0x66,
+	0xac, 0xc9, 0x83, 0xa7, 0x24, 0x1a, 0x40, 0x2b, 0x60, 0xae, 0xcd, 0x6f, 0x93, 0xc5, 0xa1, 0x97,
+	0x77, 0x91, 0xb8, 0xe3, 0x29, 0x73, 0x2f, 0x6f, 0x23, 0x62, 0x35, 0x03, 0xf5, 0x61, 0x86, 0xa0,
+	0x17, 0x5e, 0x92, 0x3b, 0xc2, 0x15, 0xf3, 0x5d, 0x5b, 0x02, 0xfc, 0x87, 0x05, 0xfc, 0x09, 0x20,
+	0x7f, 0x24, 0xee, 0x88, 0xf7, 0x6f, 0xa8, 0x25, 0xb1, 0x56, 0xdf, 0x52, 0xed, 0xa3, 0x22, 0xfb,
+	0x00, 0xf9, 0x23, 0xf8, 0x97, 0x17, 0xf6, 0xb9, 0xba, 0xc7, 0x74, 0xef, 0xf9, 0x4f, 0x79, 0x09,
+	0xd3, 0x0f, 0xd6, 0x33, 0x6b, 0xc5, 0xce, 0xb6, 0x32, 0xf3, 0x53, 0x68, 0x26, 0x3c, 0xf4, 0x37,
+	0x68, 0x32, 0x72, 0x63, 0xd3, 0x45, 0x90, 0xa4, 0xd9, 0x60, 0xe4, 0xe6, 0x74, 0x11, 0x08, 0x54,
+	0x15, 0x6e, 0x43, 0x7e, 0x9b, 0xbf, 0x68, 0xd0, 0x29, 0x6e, 0x39, 0x68, 0x00, 0x10, 0x64, 0xcb,
+	0x48, 0x12, 0xb6, 0x57, 0x5e, 0x53, 0xac, 0x82, 0xc6, 0x07, 0xcf, 0xae, 0x62, 0x7b, 0xd7, 0xca,
+	0xed, 0x6d, 0xfe, 0xae, 0xc1, 0xc6, 0xd2, 0x73, 0x71, 0x57, 0x8f, 0x7c, 0x68, 0xe0, 0x47, 0xd0,
+	0xf3, 0x98, 0xed, 0x90, 0x99, 0x8f, 0x63, 0xcc, 0x45, 0xfb, 0x57, 0xe5, 0x74, 0xe8, 0x7a, 0x6c,
+	0x94, 0x33, 0xd1, 0xbf, 0xa0, 0xeb, 0x13, 0xc7, 0x25, 0xb1, 0x3d, 0x27, 0x9e, 0x3b, 0xe7, 0x32,
+	0xc9, 0x9a, 0xd5, 0x51, 0xcc, 0x89, 0xe4, 0x99, 0x9f, 0x43, 0x2b, 0x0d, 0x21, 0xca, 0xed, 0xd1,
+	0x59, 0xb1, 0xdc, 0x1e, 0x9d, 0x89, 0x72, 0x17, 0xee, 0x61, 0xad, 0x78, 0x0f, 0xe6, 0x35, 0x6c,
+	0x2c, 0x6d, 0x89, 0xe8, 0x05, 0xf4, 0x19, 0xf1, 0xaf, 0xe5, 0x7a, 0x10, 0x07, 0x2a, 0x41, 0x6d,
+	0x47, 0x5b, 0x09, 0xe5, 0x75, 0xa1, 0x79, 0x92, 0x2b, 0x0a, 0x5c, 0xbe, 0xa1, 0xe1, 0x8f, 0x54,
+	0xe2, 0xaf, 0x63, 0x29, 0xc2, 0xbc, 0x02, 0xb4, 0xbc, 0x57, 0xa2, 0xc7, 0x50, 0x97, 0x6b, 0xec,
+	0x9d, 0xe3, 0x4c, 0x89, 0x65, 0x3f, 0x11, 0xec, 0xbc, 0xa7, 0x9f, 0x08, 0x76, 0xcc, 0x6f, 0xa0,
+	0xa1, 0x62, 0x88, 0x8b, 0x25, 0xa5, 0x3d, 0xdf, 0xca, 0xe8, 0xf7, 0xce, 0x82, 0xd5, 0x8f, 0x99,
+	0xd9, 0x84, 0xba, 0x5c, 0xf3, 0xcc, 0x6f, 0x01, 0x2d, 0x2f, 0x33, 0xe2, 0x25, 0x60, 0x1c, 0xc7,
+	0xdc, 0x2e, 0x43, 0x5d, 0x97, 0xcc, 0x0b, 0x85, 0xf7, 0x87, 0xa0, 0x13, 0xea, 0xd8, 0xe5, 0x4b,
+	0x68, 0x13, 0xea, 0x28, 0xb9, 0x79, 0x08, 0x9b, 0x2b, 0x56, 0x1c, 0xb4, 0x07, 0xad, 0xa4, 0xab,
+	0xd2, 0x91, 0xbf, 0xd4, 0x76, 0x99, 0xc2, 0x7f, 0xbf, 0x00, 0xbd, 0xd0, 0xc9, 0xef, 0x2e, 0x16,
+	0x5d, 0x68, 0x1f, 0xbe, 0x3c, 0x3b, 0xfa, 0xda, 0x9e, 0x5e, 0x1c, 0xf7, 0x35, 0xb1, 0x3f, 0x9c,
+	0x8c, 0xc6, 0xa7, 0x97, 0x27, 0x97, 0xaf, 0x25, 0x67, 0xed, 0xe0, 0x07, 0x68, 0xa8, 0x49, 0x8a,
+	0x9e, 0x43, 0x47, 0x7d, 0x5d, 0xf0, 0x98, 0xe0, 0x00, 0x2d, 0x15, 0x7c, 0x7b, 0x89, 0x63, 0x56,
+	0x76, 0xb5, 0xa7, 0x1a, 0x7a, 0x0c, 0xb5, 0x73, 0xf1, 0xb8, 0x95, 0xd7, 0xe3, 0xed, 0x32, 0x69,
+	0x56, 0x0e, 0xff, 0xf7, 0xdd, 0x9e, 0xeb, 0xf1, 0xf9, 0xe2, 0x6a, 0x30, 0x0b, 0x83, 0xfd, 0xf9,
+	0x6d, 0x44, 0x62, 0x05, 0xea, 0xfd, 0x6b, 0x7c, 0x15, 0x7b, 0xb3, 0x7d, 0xf9, 0x67, 0xca, 0xf6,
+	0x95, 0xd9, 0x55, 0x43, 0x92, 0xcf, 0xfe, 0x1c, 0x00, 0xf1, 0x3b, 0x37, 0x34, 0xc0, 0x0e, 0x00,
+	0x00,
 }
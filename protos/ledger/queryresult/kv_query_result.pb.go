@@ -10,6 +10,8 @@ It is generated from these files:
 It has these top-level messages:
 	KV
 	KeyModification
+	KeyModifications
+	HistoryQueryOptions
 */
 package queryresult
 
@@ -63,12 +65,16 @@ func (m *KV) GetValue() []byte {
 }
 
 // KeyModification -- QueryResult for history query. Holds a transaction ID, value,
-// timestamp, and delete marker which resulted from a history query.
+// timestamp, and delete marker which resulted from a history query, along with the
+// modified key and the position of the transaction in the chain.
 type KeyModification struct {
 	TxId      string                     `protobuf:"bytes,1,opt,name=tx_id,json=txId" json:"tx_id,omitempty"`
 	Value     []byte                     `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
 	Timestamp *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=timestamp" json:"timestamp,omitempty"`
 	IsDelete  bool                       `protobuf:"varint,4,opt,name=is_delete,json=isDelete" json:"is_delete,omitempty"`
+	Key       string                     `protobuf:"bytes,5,opt,name=key" json:"key,omitempty"`
+	BlockNum  uint64                     `protobuf:"varint,6,opt,name=block_num,json=blockNum" json:"block_num,omitempty"`
+	TxNum     uint64                     `protobuf:"varint,7,opt,name=tx_num,json=txNum" json:"tx_num,omitempty"`
 }
 
 func (m *KeyModification) Reset()                    { *m = KeyModification{} }
@@ -104,31 +110,145 @@ func (m *KeyModification) GetIsDelete() bool {
 	return false
 }
 
+func (m *KeyModification) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *KeyModification) GetBlockNum() uint64 {
+	if m != nil {
+		return m.BlockNum
+	}
+	return 0
+}
+
+func (m *KeyModification) GetTxNum() uint64 {
+	if m != nil {
+		return m.TxNum
+	}
+	return 0
+}
+
+// KeyModifications -- list of KeyModification returned by the history queries of qscc
+type KeyModifications struct {
+	KeyModifications []*KeyModification `protobuf:"bytes,1,rep,name=key_modifications,json=keyModifications" json:"key_modifications,omitempty"`
+}
+
+func (m *KeyModifications) Reset()                    { *m = KeyModifications{} }
+func (m *KeyModifications) String() string            { return proto.CompactTextString(m) }
+func (*KeyModifications) ProtoMessage()               {}
+func (*KeyModifications) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+func (m *KeyModifications) GetKeyModifications() []*KeyModification {
+	if m != nil {
+		return m.KeyModifications
+	}
+	return nil
+}
+
+// HistoryQueryOptions -- restricts and orders the results of a history query.
+// The block bounds are inclusive and are applied on the history index, so that
+// only the requested portion of the history is read. The time bounds are inclusive
+// and are applied on the timestamp of the transactions. end_block is only applied
+// if has_end_block is set, so that a query can be restricted to the genesis block,
+// and unset times mean no bound.
+type HistoryQueryOptions struct {
+	StartBlock  uint64                     `protobuf:"varint,1,opt,name=start_block,json=startBlock" json:"start_block,omitempty"`
+	EndBlock    uint64                     `protobuf:"varint,2,opt,name=end_block,json=endBlock" json:"end_block,omitempty"`
+	StartTime   *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime" json:"start_time,omitempty"`
+	EndTime     *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime" json:"end_time,omitempty"`
+	NewestFirst bool                       `protobuf:"varint,5,opt,name=newest_first,json=newestFirst" json:"newest_first,omitempty"`
+	HasEndBlock bool                       `protobuf:"varint,6,opt,name=has_end_block,json=hasEndBlock" json:"has_end_block,omitempty"`
+}
+
+func (m *HistoryQueryOptions) Reset()                    { *m = HistoryQueryOptions{} }
+func (m *HistoryQueryOptions) String() string            { return proto.CompactTextString(m) }
+func (*HistoryQueryOptions) ProtoMessage()               {}
+func (*HistoryQueryOptions) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *HistoryQueryOptions) GetStartBlock() uint64 {
+	if m != nil {
+		return m.StartBlock
+	}
+	return 0
+}
+
+func (m *HistoryQueryOptions) GetEndBlock() uint64 {
+	if m != nil {
+		return m.EndBlock
+	}
+	return 0
+}
+
+func (m *HistoryQueryOptions) GetStartTime() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.StartTime
+	}
+	return nil
+}
+
+func (m *HistoryQueryOptions) GetEndTime() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.EndTime
+	}
+	return nil
+}
+
+func (m *HistoryQueryOptions) GetNewestFirst() bool {
+	if m != nil {
+		return m.NewestFirst
+	}
+	return false
+}
+
+func (m *HistoryQueryOptions) GetHasEndBlock() bool {
+	if m != nil {
+		return m.HasEndBlock
+	}
+	return false
+}
+
 func init() {
 	proto.RegisterType((*KV)(nil), "queryresult.KV")
 	proto.RegisterType((*KeyModification)(nil), "queryresult.KeyModification")
+	proto.RegisterType((*KeyModifications)(nil), "queryresult.KeyModifications")
+	proto.RegisterType((*HistoryQueryOptions)(nil), "queryresult.HistoryQueryOptions")
 }
 
 func init() { proto.RegisterFile("ledger/queryresult/kv_query_result.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 286 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x64, 0x51, 0x4f, 0x4b, 0xc3, 0x30,
-	0x1c, 0xa5, 0xdd, 0x26, 0x6b, 0x26, 0x28, 0xd1, 0x43, 0x99, 0x82, 0x65, 0xa7, 0x9e, 0x12, 0xd1,
-	0x83, 0x9e, 0xc5, 0x8b, 0x0e, 0x2f, 0x45, 0x3c, 0x78, 0x29, 0x69, 0xfb, 0x6b, 0x17, 0xda, 0x2e,
-	0x35, 0x7f, 0xc6, 0xfa, 0x39, 0xfc, 0xc2, 0x62, 0xb2, 0xd9, 0x82, 0xb7, 0xbc, 0xf7, 0x7b, 0xef,
-	0xf1, 0x78, 0x41, 0x71, 0x03, 0x45, 0x05, 0x92, 0x7e, 0x19, 0x90, 0xbd, 0x04, 0x65, 0x1a, 0x4d,
-	0xeb, 0x5d, 0x6a, 0x61, 0xea, 0x30, 0xe9, 0xa4, 0xd0, 0x02, 0x2f, 0x46, 0x92, 0xe5, 0x4d, 0x25,
-	0x44, 0xd5, 0x00, 0xb5, 0xa7, 0xcc, 0x94, 0x54, 0xf3, 0x16, 0x94, 0x66, 0x6d, 0xe7, 0xd4, 0xab,
-	0x57, 0xe4, 0xaf, 0x3f, 0xf0, 0x35, 0x0a, 0xb6, 0xac, 0x05, 0xd5, 0xb1, 0x1c, 0x42, 0x2f, 0xf2,
-	0xe2, 0x20, 0x19, 0x08, 0x7c, 0x8e, 0x26, 0x35, 0xf4, 0xa1, 0x6f, 0xf9, 0xdf, 0x27, 0xbe, 0x44,
-	0xb3, 0x1d, 0x6b, 0x0c, 0x84, 0x93, 0xc8, 0x8b, 0x4f, 0x13, 0x07, 0x56, 0xdf, 0x1e, 0x3a, 0x5b,
-	0x43, 0xff, 0x26, 0x0a, 0x5e, 0xf2, 0x9c, 0x69, 0x2e, 0xb6, 0xf8, 0x02, 0xcd, 0xf4, 0x3e, 0xe5,
-	0xc5, 0x21, 0x75, 0xaa, 0xf7, 0x2f, 0xc5, 0x60, 0xf7, 0x47, 0x76, 0xfc, 0x88, 0x82, 0xbf, 0x76,
-	0x36, 0x78, 0x71, 0xb7, 0x24, 0xae, 0x3f, 0x39, 0xf6, 0x27, 0xef, 0x47, 0x45, 0x32, 0x88, 0xf1,
-	0x15, 0x0a, 0xb8, 0x4a, 0x0b, 0x68, 0x40, 0x43, 0x38, 0x8d, 0xbc, 0x78, 0x9e, 0xcc, 0xb9, 0x7a,
-	0xb6, 0xf8, 0xa9, 0x46, 0xb7, 0x42, 0x56, 0x64, 0xd3, 0x77, 0x20, 0xdd, 0x88, 0xa4, 0x64, 0x99,
-	0xe4, 0xb9, 0x0b, 0x55, 0xe4, 0x40, 0x8e, 0x66, 0xfb, 0x7c, 0xa8, 0xb8, 0xde, 0x98, 0x8c, 0xe4,
-	0xa2, 0xa5, 0x23, 0x23, 0x75, 0x46, 0xb7, 0xa6, 0xa2, 0xff, 0xbf, 0x24, 0x3b, 0xb1, 0xa7, 0xfb,
-	0x9f, 0x00, 0x00, 0x00, 0xff, 0xff, 0xa2, 0xb7, 0x3e, 0x86, 0xaf, 0x01, 0x00, 0x00,
+	// 469 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x53, 0x5b, 0x6f, 0xd3, 0x30,
+	0x14, 0x56, 0x7a, 0x5b, 0x7b, 0x3a, 0x44, 0xf1, 0x40, 0x8a, 0xb6, 0x49, 0x2b, 0x7d, 0xca, 0x93,
+	0x83, 0x86, 0x10, 0xf0, 0x3a, 0x01, 0x62, 0x4c, 0x0c, 0x11, 0x21, 0x1e, 0x90, 0x90, 0x95, 0xcb,
+	0x69, 0x6a, 0xe5, 0xe2, 0x60, 0x3b, 0xa3, 0xf9, 0x0f, 0xfc, 0x41, 0xfe, 0x0d, 0x8a, 0xdd, 0x92,
+	0xa8, 0x3c, 0xb0, 0xb7, 0x9c, 0xef, 0x72, 0xfc, 0x9d, 0x13, 0x1b, 0xbc, 0x1c, 0x93, 0x14, 0xa5,
+	0xff, 0xa3, 0x46, 0xd9, 0x48, 0x54, 0x75, 0xae, 0xfd, 0xec, 0x8e, 0x99, 0x92, 0xd9, 0x9a, 0x56,
+	0x52, 0x68, 0x41, 0xe6, 0x3d, 0xc9, 0xe9, 0x45, 0x2a, 0x44, 0x9a, 0xa3, 0x6f, 0xa8, 0xa8, 0x5e,
+	0xfb, 0x9a, 0x17, 0xa8, 0x74, 0x58, 0x54, 0x56, 0xbd, 0xfa, 0x00, 0x83, 0x9b, 0xaf, 0xe4, 0x1c,
+	0x66, 0x65, 0x58, 0xa0, 0xaa, 0xc2, 0x18, 0x5d, 0x67, 0xe9, 0x78, 0xb3, 0xa0, 0x03, 0xc8, 0x02,
+	0x86, 0x19, 0x36, 0xee, 0xc0, 0xe0, 0xed, 0x27, 0x79, 0x0c, 0xe3, 0xbb, 0x30, 0xaf, 0xd1, 0x1d,
+	0x2e, 0x1d, 0xef, 0x38, 0xb0, 0xc5, 0xea, 0xb7, 0x03, 0x0f, 0x6f, 0xb0, 0xf9, 0x28, 0x12, 0xbe,
+	0xe6, 0x71, 0xa8, 0xb9, 0x28, 0xc9, 0x09, 0x8c, 0xf5, 0x96, 0xf1, 0x64, 0xd7, 0x75, 0xa4, 0xb7,
+	0xd7, 0x49, 0x67, 0x1f, 0xf4, 0xec, 0xe4, 0x15, 0xcc, 0xfe, 0xa6, 0x33, 0x8d, 0xe7, 0x97, 0xa7,
+	0xd4, 0xe6, 0xa7, 0xfb, 0xfc, 0xf4, 0xcb, 0x5e, 0x11, 0x74, 0x62, 0x72, 0x06, 0x33, 0xae, 0x58,
+	0x82, 0x39, 0x6a, 0x74, 0x47, 0x4b, 0xc7, 0x9b, 0x06, 0x53, 0xae, 0xde, 0x98, 0x7a, 0x9f, 0x7e,
+	0xdc, 0xa5, 0x3f, 0x83, 0x59, 0x94, 0x8b, 0x38, 0x63, 0x65, 0x5d, 0xb8, 0x93, 0xa5, 0xe3, 0x8d,
+	0x82, 0xa9, 0x01, 0x6e, 0xeb, 0x82, 0x3c, 0x81, 0x89, 0xde, 0x1a, 0xe6, 0xc8, 0x30, 0x63, 0xbd,
+	0xbd, 0xad, 0x8b, 0xd5, 0x77, 0x58, 0x1c, 0x8c, 0xa6, 0xc8, 0x35, 0x3c, 0xca, 0xb0, 0x61, 0x45,
+	0x1f, 0x74, 0x9d, 0xe5, 0xd0, 0x9b, 0x5f, 0x9e, 0xd3, 0xde, 0x5f, 0xa0, 0x07, 0xce, 0x60, 0x91,
+	0x1d, 0xb4, 0x5a, 0xfd, 0x1a, 0xc0, 0xc9, 0x7b, 0xae, 0xb4, 0x90, 0xcd, 0xe7, 0xd6, 0xf8, 0xa9,
+	0xb2, 0x47, 0x5c, 0xc0, 0x5c, 0xe9, 0x50, 0x6a, 0x66, 0xf2, 0x99, 0x25, 0x8e, 0x02, 0x30, 0xd0,
+	0x55, 0x8b, 0xb4, 0xb3, 0x60, 0x99, 0xec, 0xe8, 0x81, 0x9d, 0x05, 0xcb, 0xc4, 0x92, 0xaf, 0xc1,
+	0x4a, 0x59, 0xbb, 0xaa, 0xfb, 0xac, 0xd4, 0xa8, 0xdb, 0x9a, 0xbc, 0x80, 0xb6, 0x8d, 0x35, 0x8e,
+	0xfe, 0x6b, 0x3c, 0xc2, 0x32, 0x31, 0xb6, 0xa7, 0x70, 0x5c, 0xe2, 0x4f, 0x54, 0x9a, 0xad, 0xb9,
+	0x54, 0xda, 0x6c, 0x7d, 0x1a, 0xcc, 0x2d, 0xf6, 0xae, 0x85, 0xc8, 0x0a, 0x1e, 0x6c, 0x42, 0xc5,
+	0xba, 0xd4, 0x13, 0xab, 0xd9, 0x84, 0xea, 0xed, 0x2e, 0xf8, 0x55, 0x06, 0xcf, 0x84, 0x4c, 0xe9,
+	0xa6, 0xa9, 0x50, 0xda, 0x8b, 0x4f, 0xd7, 0x61, 0x24, 0x79, 0x6c, 0x0f, 0x57, 0x74, 0x07, 0xf6,
+	0x96, 0xfc, 0xed, 0x65, 0xca, 0xf5, 0xa6, 0x8e, 0x68, 0x2c, 0x0a, 0xbf, 0x67, 0xf4, 0xad, 0xd1,
+	0xbe, 0x00, 0xe5, 0xff, 0xfb, 0x8c, 0xa2, 0x89, 0xa1, 0x9e, 0xff, 0x19, 0x00, 0x97, 0xbb, 0x02,
+	0xc0, 0x63, 0x03, 0x00, 0x00,
 }
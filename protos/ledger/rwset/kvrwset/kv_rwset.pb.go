@@ -15,6 +15,8 @@ It has these top-level messages:
 	RangeQueryInfo
 	QueryReads
 	QueryReadsMerkleSummary
+	CrossChannelRead
+	NsReads
 */
 package kvrwset
 
@@ -35,9 +37,10 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 // KVRWSet encapsulates the read-write set for a chaincode that operates upon a KV or Document data model
 type KVRWSet struct {
-	Reads            []*KVRead         `protobuf:"bytes,1,rep,name=reads" json:"reads,omitempty"`
-	RangeQueriesInfo []*RangeQueryInfo `protobuf:"bytes,2,rep,name=range_queries_info,json=rangeQueriesInfo" json:"range_queries_info,omitempty"`
-	Writes           []*KVWrite        `protobuf:"bytes,3,rep,name=writes" json:"writes,omitempty"`
+	Reads             []*KVRead           `protobuf:"bytes,1,rep,name=reads" json:"reads,omitempty"`
+	RangeQueriesInfo  []*RangeQueryInfo   `protobuf:"bytes,2,rep,name=range_queries_info,json=rangeQueriesInfo" json:"range_queries_info,omitempty"`
+	Writes            []*KVWrite          `protobuf:"bytes,3,rep,name=writes" json:"writes,omitempty"`
+	CrossChannelReads []*CrossChannelRead `protobuf:"bytes,4,rep,name=cross_channel_reads,json=crossChannelReads" json:"cross_channel_reads,omitempty"`
 }
 
 func (m *KVRWSet) Reset()                    { *m = KVRWSet{} }
@@ -66,6 +69,13 @@ func (m *KVRWSet) GetWrites() []*KVWrite {
 	return nil
 }
 
+func (m *KVRWSet) GetCrossChannelReads() []*CrossChannelRead {
+	if m != nil {
+		return m.CrossChannelReads
+	}
+	return nil
+}
+
 // KVRead captures a read operation performed during transaction simulation
 // A 'nil' version indicates a non-existing key read by the transaction
 type KVRead struct {
@@ -358,6 +368,83 @@ func (m *QueryReadsMerkleSummary) GetMaxLevelHashes() [][]byte {
 	return nil
 }
 
+// CrossChannelRead captures a (read-only) chaincode invocation on another channel performed during transaction simulation.
+// block_height is the height of the ledger of the called channel at the time of the invocation and response_hash is the
+// hash of the response returned by the called chaincode. ns_reads contains the reads performed by the called chaincode
+// on the called channel so that these can be verified against the state of that channel as of block_height during validation
+type CrossChannelRead struct {
+	ChannelId     string     `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	ChaincodeName string     `protobuf:"bytes,2,opt,name=chaincode_name,json=chaincodeName" json:"chaincode_name,omitempty"`
+	BlockHeight   uint64     `protobuf:"varint,3,opt,name=block_height,json=blockHeight" json:"block_height,omitempty"`
+	ResponseHash  []byte     `protobuf:"bytes,4,opt,name=response_hash,json=responseHash,proto3" json:"response_hash,omitempty"`
+	NsReads       []*NsReads `protobuf:"bytes,5,rep,name=ns_reads,json=nsReads" json:"ns_reads,omitempty"`
+}
+
+func (m *CrossChannelRead) Reset()                    { *m = CrossChannelRead{} }
+func (m *CrossChannelRead) String() string            { return proto.CompactTextString(m) }
+func (*CrossChannelRead) ProtoMessage()               {}
+func (*CrossChannelRead) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *CrossChannelRead) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *CrossChannelRead) GetChaincodeName() string {
+	if m != nil {
+		return m.ChaincodeName
+	}
+	return ""
+}
+
+func (m *CrossChannelRead) GetBlockHeight() uint64 {
+	if m != nil {
+		return m.BlockHeight
+	}
+	return 0
+}
+
+func (m *CrossChannelRead) GetResponseHash() []byte {
+	if m != nil {
+		return m.ResponseHash
+	}
+	return nil
+}
+
+func (m *CrossChannelRead) GetNsReads() []*NsReads {
+	if m != nil {
+		return m.NsReads
+	}
+	return nil
+}
+
+// NsReads encapsulates the KVReads performed on a namespace
+type NsReads struct {
+	Namespace string    `protobuf:"bytes,1,opt,name=namespace" json:"namespace,omitempty"`
+	Reads     []*KVRead `protobuf:"bytes,2,rep,name=reads" json:"reads,omitempty"`
+}
+
+func (m *NsReads) Reset()                    { *m = NsReads{} }
+func (m *NsReads) String() string            { return proto.CompactTextString(m) }
+func (*NsReads) ProtoMessage()               {}
+func (*NsReads) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *NsReads) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *NsReads) GetReads() []*KVRead {
+	if m != nil {
+		return m.Reads
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*KVRWSet)(nil), "kvrwset.KVRWSet")
 	proto.RegisterType((*KVRead)(nil), "kvrwset.KVRead")
@@ -366,45 +453,56 @@ func init() {
 	proto.RegisterType((*RangeQueryInfo)(nil), "kvrwset.RangeQueryInfo")
 	proto.RegisterType((*QueryReads)(nil), "kvrwset.QueryReads")
 	proto.RegisterType((*QueryReadsMerkleSummary)(nil), "kvrwset.QueryReadsMerkleSummary")
+	proto.RegisterType((*CrossChannelRead)(nil), "kvrwset.CrossChannelRead")
+	proto.RegisterType((*NsReads)(nil), "kvrwset.NsReads")
 }
 
 func init() { proto.RegisterFile("ledger/rwset/kvrwset/kv_rwset.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 552 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x7c, 0x53, 0x4d, 0x6b, 0xdb, 0x40,
-	0x10, 0x8d, 0xec, 0xd8, 0x92, 0xa7, 0x4e, 0xea, 0x6e, 0x5a, 0x22, 0x28, 0x05, 0xa3, 0x50, 0x30,
-	0x39, 0x48, 0x90, 0x5e, 0xda, 0x43, 0x2f, 0x25, 0x29, 0x29, 0x69, 0x03, 0xdd, 0x80, 0x03, 0xbd,
-	0x88, 0xb5, 0x35, 0xb6, 0x85, 0xf5, 0x91, 0xee, 0xae, 0x6c, 0xf9, 0x54, 0xfa, 0x53, 0xfa, 0x4f,
-	0xcb, 0x8e, 0x24, 0x3b, 0x01, 0xb7, 0x27, 0xed, 0xbc, 0x37, 0x6f, 0x76, 0xe6, 0x69, 0x16, 0xce,
-	0x12, 0x8c, 0xe6, 0x28, 0x03, 0xb9, 0x56, 0xa8, 0x83, 0xe5, 0xaa, 0xf9, 0x86, 0x74, 0xf0, 0x1f,
-	0x64, 0xae, 0x73, 0x66, 0xd7, 0xb8, 0xf7, 0xc7, 0x02, 0xfb, 0x66, 0xcc, 0xef, 0xef, 0x50, 0xb3,
-	0xb7, 0xd0, 0x91, 0x28, 0x22, 0xe5, 0x5a, 0xc3, 0xf6, 0xe8, 0xd9, 0xc5, 0x73, 0xbf, 0x4e, 0xf2,
-	0x6f, 0xc6, 0x1c, 0x45, 0xc4, 0x2b, 0x96, 0x5d, 0x01, 0x93, 0x22, 0x9b, 0x63, 0xf8, 0xb3, 0x40,
-	0x19, 0xa3, 0x0a, 0xe3, 0x6c, 0x96, 0xbb, 0x2d, 0xd2, 0x9c, 0x6e, 0x35, 0xdc, 0xa4, 0x7c, 0x2f,
-	0x50, 0x6e, 0xbe, 0x64, 0xb3, 0x9c, 0x0f, 0x64, 0x13, 0xc7, 0xa8, 0x0c, 0xc2, 0x46, 0xd0, 0x5d,
-	0xcb, 0x58, 0xa3, 0x72, 0xdb, 0x24, 0x1d, 0x3c, 0xba, 0xee, 0xde, 0x10, 0xbc, 0xe6, 0xbd, 0xcf,
-	0xd0, 0xad, 0x3a, 0x60, 0x03, 0x68, 0x2f, 0x71, 0xe3, 0x5a, 0x43, 0x6b, 0xd4, 0xe3, 0xe6, 0xc8,
-	0xce, 0xc1, 0x5e, 0xa1, 0x54, 0x71, 0x9e, 0xb9, 0xad, 0xa1, 0xf5, 0xa4, 0xcc, 0xb8, 0xc2, 0x79,
-	0x93, 0xe0, 0xdd, 0x9a, 0x51, 0xa9, 0xf4, 0x9e, 0x42, 0xaf, 0xa1, 0x17, 0xab, 0x30, 0xc2, 0x04,
-	0x35, 0x52, 0x29, 0x87, 0x3b, 0xb1, 0xba, 0xa4, 0x98, 0xbd, 0x84, 0xce, 0x4a, 0x24, 0x05, 0xba,
-	0xed, 0xa1, 0x35, 0xea, 0xf3, 0x2a, 0xf0, 0x3e, 0x82, 0x5d, 0xdf, 0x61, 0xd4, 0x93, 0x24, 0x9f,
-	0x2e, 0xc3, 0xac, 0x48, 0xa9, 0xea, 0x21, 0x77, 0x08, 0xb8, 0x2d, 0x52, 0xf6, 0x0a, 0xba, 0xba,
-	0x24, 0xa6, 0x45, 0x4c, 0x47, 0x97, 0xb7, 0x45, 0xea, 0xfd, 0x6e, 0xc1, 0xf1, 0x53, 0x97, 0x4c,
-	0x19, 0xa5, 0x85, 0xd4, 0xe1, 0xae, 0x39, 0x87, 0x80, 0x1b, 0xdc, 0xb0, 0x53, 0xb0, 0x31, 0x8b,
-	0x88, 0x6a, 0x11, 0xd5, 0xc5, 0x2c, 0x32, 0xc4, 0x19, 0x1c, 0xc5, 0x5a, 0x86, 0x58, 0x2e, 0x44,
-	0xa1, 0x34, 0x46, 0xd4, 0xa5, 0xc3, 0xfb, 0xb1, 0x96, 0x57, 0x0d, 0xc6, 0x2e, 0xa0, 0x27, 0xc5,
-	0x3a, 0xac, 0x7e, 0xf0, 0x21, 0x59, 0x75, 0xb2, 0xb5, 0x8a, 0x3a, 0x30, 0x0e, 0xab, 0xeb, 0x03,
-	0xee, 0x48, 0xb1, 0xa6, 0x33, 0xe3, 0x70, 0x42, 0xf9, 0x61, 0x8a, 0x72, 0x99, 0x60, 0xb8, 0x10,
-	0x6a, 0x81, 0xca, 0xed, 0x90, 0x7a, 0xb8, 0x47, 0xfd, 0x8d, 0xf2, 0xee, 0x8a, 0x34, 0x15, 0x72,
-	0x73, 0x7d, 0xc0, 0x5f, 0xc8, 0x1d, 0x7a, 0x4d, 0xe2, 0x4f, 0x7d, 0x80, 0xaa, 0xa6, 0xd9, 0x1a,
-	0xef, 0x3d, 0xc0, 0x4e, 0xcd, 0xce, 0xc1, 0x31, 0x7b, 0xfa, 0xbf, 0x1d, 0xb4, 0x97, 0x2b, 0xca,
-	0xf5, 0x7e, 0xc1, 0xe9, 0x3f, 0xee, 0x65, 0x6f, 0x00, 0x52, 0x51, 0x86, 0x11, 0xce, 0x25, 0x22,
-	0xd9, 0x78, 0xc4, 0x7b, 0xa9, 0x28, 0x2f, 0x09, 0x30, 0x26, 0x1b, 0x3a, 0xc1, 0x15, 0x26, 0xe4,
-	0xe4, 0x11, 0x77, 0x52, 0x51, 0x7e, 0x35, 0x31, 0x1b, 0xc1, 0x60, 0x4b, 0x36, 0xf3, 0x9a, 0xfd,
-	0xec, 0xf3, 0xe3, 0x26, 0xa7, 0x1e, 0x24, 0x87, 0x8b, 0x5c, 0xce, 0xfd, 0xc5, 0xe6, 0x01, 0x65,
-	0xf5, 0xe4, 0xfc, 0x99, 0x98, 0xc8, 0x78, 0x5a, 0x3d, 0x31, 0xe5, 0xd7, 0x60, 0xd5, 0x7e, 0x3d,
-	0xc6, 0x8f, 0x0f, 0xf3, 0x58, 0x2f, 0x8a, 0x89, 0x3f, 0xcd, 0xd3, 0xe0, 0x91, 0x34, 0xa8, 0xa4,
-	0x41, 0x25, 0x0d, 0xf6, 0x3d, 0xe1, 0x49, 0x97, 0xc8, 0x77, 0x7f, 0x03, 0x00, 0x00, 0xff, 0xff,
-	0x7e, 0x2f, 0xc5, 0xa9, 0xe1, 0x03, 0x00, 0x00,
+	// 699 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0xdd, 0x6a, 0xdb, 0x4c,
+	0x10, 0x8d, 0xed, 0xd8, 0x92, 0x27, 0x76, 0x3e, 0x67, 0xf3, 0x95, 0xb8, 0xb4, 0x05, 0xd7, 0x21,
+	0x60, 0x52, 0xb0, 0x21, 0xbd, 0x69, 0x2f, 0x7a, 0x93, 0x9f, 0xe2, 0x90, 0xd6, 0xd0, 0x0d, 0x24,
+	0xd0, 0x1b, 0xb1, 0x96, 0x26, 0x96, 0xb0, 0x7e, 0xdc, 0x5d, 0xc9, 0x3f, 0x57, 0xa5, 0x0f, 0xd8,
+	0x37, 0xe9, 0x43, 0x94, 0x9d, 0x95, 0xec, 0x24, 0xa4, 0xb9, 0xd2, 0xee, 0x39, 0x73, 0x66, 0x67,
+	0xcf, 0x8c, 0x16, 0x0e, 0x43, 0xf4, 0x26, 0x28, 0x07, 0x72, 0xa1, 0x30, 0x1d, 0x4c, 0xe7, 0xc5,
+	0xd7, 0xa1, 0x45, 0x7f, 0x26, 0x93, 0x34, 0x61, 0x56, 0x8e, 0x77, 0xff, 0x94, 0xc0, 0xba, 0xba,
+	0xe1, 0xb7, 0xd7, 0x98, 0xb2, 0x23, 0xa8, 0x4a, 0x14, 0x9e, 0x6a, 0x97, 0x3a, 0x95, 0xde, 0xce,
+	0xc9, 0x7f, 0xfd, 0x3c, 0xa8, 0x7f, 0x75, 0xc3, 0x51, 0x78, 0xdc, 0xb0, 0xec, 0x02, 0x98, 0x14,
+	0xf1, 0x04, 0x9d, 0x1f, 0x19, 0xca, 0x00, 0x95, 0x13, 0xc4, 0x77, 0x49, 0xbb, 0x4c, 0x9a, 0x83,
+	0xb5, 0x86, 0xeb, 0x90, 0x6f, 0x19, 0xca, 0xd5, 0x65, 0x7c, 0x97, 0xf0, 0x96, 0x2c, 0xf6, 0x01,
+	0x2a, 0x8d, 0xb0, 0x1e, 0xd4, 0x16, 0x32, 0x48, 0x51, 0xb5, 0x2b, 0x24, 0x6d, 0xdd, 0x3b, 0xee,
+	0x56, 0x13, 0x3c, 0xe7, 0xd9, 0x25, 0xec, 0xbb, 0x32, 0x51, 0xca, 0x71, 0x7d, 0x11, 0xc7, 0x18,
+	0x3a, 0xa6, 0xca, 0x6d, 0x92, 0xbd, 0x5c, 0xcb, 0xce, 0x74, 0xcc, 0x99, 0x09, 0xa1, 0x7a, 0xf7,
+	0xdc, 0x47, 0x88, 0xea, 0x7e, 0x86, 0x9a, 0xb9, 0x0c, 0x6b, 0x41, 0x65, 0x8a, 0xab, 0x76, 0xa9,
+	0x53, 0xea, 0xd5, 0xb9, 0x5e, 0xb2, 0x63, 0xb0, 0xe6, 0x28, 0x55, 0x90, 0xc4, 0xed, 0x72, 0xa7,
+	0xf4, 0xa0, 0xa2, 0x1b, 0x83, 0xf3, 0x22, 0xa0, 0x3b, 0x02, 0x2b, 0xaf, 0xf2, 0x89, 0x44, 0xaf,
+	0xa0, 0x1e, 0x28, 0xc7, 0xc3, 0x10, 0x53, 0xa4, 0x54, 0x36, 0xb7, 0x03, 0x75, 0x4e, 0x7b, 0xf6,
+	0x3f, 0x54, 0xe7, 0x22, 0xcc, 0xb0, 0x5d, 0xe9, 0x94, 0x7a, 0x0d, 0x6e, 0x36, 0xdd, 0x4f, 0x60,
+	0xe5, 0x67, 0x68, 0xf5, 0x38, 0x4c, 0xdc, 0xa9, 0x13, 0x67, 0x11, 0x65, 0xdd, 0xe6, 0x36, 0x01,
+	0xa3, 0x2c, 0x62, 0x2f, 0xa0, 0x96, 0x2e, 0x89, 0x29, 0x13, 0x53, 0x4d, 0x97, 0xa3, 0x2c, 0xea,
+	0xfe, 0x2a, 0xc3, 0xee, 0x43, 0xc3, 0x75, 0x1a, 0x95, 0x0a, 0x99, 0x3a, 0x9b, 0xe2, 0x6c, 0x02,
+	0xae, 0x70, 0xc5, 0x0e, 0xc0, 0xc2, 0xd8, 0x23, 0xaa, 0x4c, 0x54, 0x0d, 0x63, 0x4f, 0x13, 0x87,
+	0xd0, 0x0c, 0x52, 0xe9, 0xe0, 0xd2, 0x17, 0x99, 0x4a, 0xd1, 0xa3, 0x2a, 0x6d, 0xde, 0x08, 0x52,
+	0x79, 0x51, 0x60, 0xec, 0x04, 0xea, 0x52, 0x2c, 0xd6, 0x5d, 0xd0, 0x56, 0xed, 0xaf, 0xad, 0xa2,
+	0x0a, 0xc8, 0xec, 0xe1, 0x16, 0xb7, 0xa5, 0x58, 0xd0, 0x9a, 0x71, 0xd8, 0xa7, 0x78, 0x27, 0x42,
+	0x39, 0x0d, 0xd1, 0xf1, 0x85, 0xf2, 0x51, 0xb5, 0xab, 0xa4, 0xee, 0x3c, 0xa1, 0xfe, 0x4a, 0x71,
+	0xd7, 0x59, 0x14, 0x09, 0xb9, 0x1a, 0x6e, 0xf1, 0x3d, 0xb9, 0x41, 0x87, 0x24, 0x3e, 0x6d, 0x00,
+	0x98, 0x9c, 0x7a, 0x00, 0xbb, 0x1f, 0x00, 0x36, 0x6a, 0x76, 0x0c, 0xb6, 0x1e, 0xf9, 0xe7, 0xc6,
+	0xd9, 0x9a, 0xce, 0xcd, 0x50, 0xfc, 0x84, 0x83, 0x7f, 0x9c, 0xcb, 0xde, 0x00, 0x44, 0x62, 0xe9,
+	0x78, 0x38, 0x91, 0x88, 0x64, 0x63, 0x93, 0xd7, 0x23, 0xb1, 0x3c, 0x27, 0x40, 0x9b, 0xac, 0xe9,
+	0x10, 0xe7, 0x18, 0x92, 0x93, 0x4d, 0x6e, 0x47, 0x62, 0xf9, 0x45, 0xef, 0x59, 0x0f, 0x5a, 0x6b,
+	0xb2, 0xb8, 0xaf, 0x1e, 0xf5, 0x06, 0xdf, 0x2d, 0x62, 0xcc, 0x45, 0xba, 0xbf, 0x4b, 0xd0, 0x7a,
+	0x3c, 0xbd, 0xfa, 0xe8, 0x62, 0xde, 0x03, 0x2f, 0xef, 0x60, 0x3d, 0x47, 0x2e, 0x3d, 0x76, 0x04,
+	0xbb, 0xae, 0x2f, 0x82, 0xd8, 0x4d, 0x3c, 0x74, 0x62, 0x11, 0x61, 0xde, 0xc9, 0xe6, 0x1a, 0x1d,
+	0x89, 0x08, 0xd9, 0x5b, 0x68, 0x98, 0x69, 0xf2, 0x31, 0x98, 0xf8, 0x29, 0xf5, 0x73, 0x9b, 0xef,
+	0x10, 0x36, 0x24, 0x48, 0xf7, 0x5c, 0xa2, 0x9a, 0x25, 0xb1, 0x32, 0x6d, 0xa1, 0x96, 0x36, 0x78,
+	0xa3, 0x00, 0x75, 0x91, 0xec, 0x1d, 0xd8, 0xb1, 0xca, 0xfd, 0xac, 0x3e, 0xfa, 0x5f, 0x47, 0x8a,
+	0x9c, 0xe3, 0x56, 0x6c, 0x16, 0xfa, 0xef, 0xc8, 0x31, 0xf6, 0x1a, 0xea, 0xba, 0x38, 0x35, 0x13,
+	0x2e, 0x16, 0x97, 0x58, 0x03, 0x9b, 0x17, 0xa7, 0xfc, 0xdc, 0x8b, 0x73, 0x9a, 0xc0, 0x49, 0x22,
+	0x27, 0x7d, 0x7f, 0x35, 0x43, 0x69, 0x5e, 0xb7, 0xfe, 0x9d, 0x18, 0xcb, 0xc0, 0x35, 0xaf, 0x99,
+	0xea, 0xe7, 0xa0, 0xd1, 0xe6, 0x39, 0xbe, 0x7f, 0x9c, 0x04, 0xa9, 0x9f, 0x8d, 0xfb, 0x6e, 0x12,
+	0x0d, 0xee, 0x49, 0x07, 0x46, 0x3a, 0x30, 0xd2, 0xc1, 0x53, 0xaf, 0xe5, 0xb8, 0x46, 0xe4, 0xfb,
+	0xbf, 0x03, 0x00, 0x25, 0x28, 0xa2, 0xe6, 0x4c, 0x05, 0x00, 0x00,
 }
@@ -0,0 +1,799 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: orderer/bft.proto
+
+package orderer
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import common "github.com/hyperledger/fabric/protos/common"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// BFTEnvelope carries a message of the BFT consensus protocol, signed
+// by the consenter that sent it.
+type BFTEnvelope struct {
+	Channel   string `protobuf:"bytes,1,opt,name=channel" json:"channel,omitempty"`
+	Payload   []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	Signature []byte `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+}
+
+func (m *BFTEnvelope) Reset()                    { *m = BFTEnvelope{} }
+func (m *BFTEnvelope) String() string            { return proto.CompactTextString(m) }
+func (*BFTEnvelope) ProtoMessage()               {}
+func (*BFTEnvelope) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{0} }
+
+func (m *BFTEnvelope) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+func (m *BFTEnvelope) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+func (m *BFTEnvelope) GetSignature() []byte {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// BFTMessage is a wrapper type for the messages
+// that the BFT orderers exchange.
+type BFTMessage struct {
+	Replica uint64 `protobuf:"varint,1,opt,name=replica" json:"replica,omitempty"`
+	// Types that are valid to be assigned to Type:
+	//	*BFTMessage_Request
+	//	*BFTMessage_PrePrepare
+	//	*BFTMessage_Prepare
+	//	*BFTMessage_Commit
+	//	*BFTMessage_ViewChange
+	//	*BFTMessage_NewView
+	//	*BFTMessage_SyncRequest
+	//	*BFTMessage_SyncResponse
+	Type isBFTMessage_Type `protobuf_oneof:"Type"`
+}
+
+func (m *BFTMessage) Reset()                    { *m = BFTMessage{} }
+func (m *BFTMessage) String() string            { return proto.CompactTextString(m) }
+func (*BFTMessage) ProtoMessage()               {}
+func (*BFTMessage) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{1} }
+
+type isBFTMessage_Type interface {
+	isBFTMessage_Type()
+}
+
+type BFTMessage_Request struct {
+	Request *BFTRequest `protobuf:"bytes,2,opt,name=request,oneof"`
+}
+type BFTMessage_PrePrepare struct {
+	PrePrepare *BFTPrePrepare `protobuf:"bytes,3,opt,name=pre_prepare,json=prePrepare,oneof"`
+}
+type BFTMessage_Prepare struct {
+	Prepare *BFTPrepare `protobuf:"bytes,4,opt,name=prepare,oneof"`
+}
+type BFTMessage_Commit struct {
+	Commit *BFTCommit `protobuf:"bytes,5,opt,name=commit,oneof"`
+}
+type BFTMessage_ViewChange struct {
+	ViewChange *BFTViewChange `protobuf:"bytes,6,opt,name=view_change,json=viewChange,oneof"`
+}
+type BFTMessage_NewView struct {
+	NewView *BFTNewView `protobuf:"bytes,7,opt,name=new_view,json=newView,oneof"`
+}
+type BFTMessage_SyncRequest struct {
+	SyncRequest *BFTSyncRequest `protobuf:"bytes,8,opt,name=sync_request,json=syncRequest,oneof"`
+}
+type BFTMessage_SyncResponse struct {
+	SyncResponse *BFTSyncResponse `protobuf:"bytes,9,opt,name=sync_response,json=syncResponse,oneof"`
+}
+
+func (*BFTMessage_Request) isBFTMessage_Type()      {}
+func (*BFTMessage_PrePrepare) isBFTMessage_Type()   {}
+func (*BFTMessage_Prepare) isBFTMessage_Type()      {}
+func (*BFTMessage_Commit) isBFTMessage_Type()       {}
+func (*BFTMessage_ViewChange) isBFTMessage_Type()   {}
+func (*BFTMessage_NewView) isBFTMessage_Type()      {}
+func (*BFTMessage_SyncRequest) isBFTMessage_Type()  {}
+func (*BFTMessage_SyncResponse) isBFTMessage_Type() {}
+
+func (m *BFTMessage) GetType() isBFTMessage_Type {
+	if m != nil {
+		return m.Type
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetReplica() uint64 {
+	if m != nil {
+		return m.Replica
+	}
+	return 0
+}
+
+func (m *BFTMessage) GetRequest() *BFTRequest {
+	if x, ok := m.GetType().(*BFTMessage_Request); ok {
+		return x.Request
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetPrePrepare() *BFTPrePrepare {
+	if x, ok := m.GetType().(*BFTMessage_PrePrepare); ok {
+		return x.PrePrepare
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetPrepare() *BFTPrepare {
+	if x, ok := m.GetType().(*BFTMessage_Prepare); ok {
+		return x.Prepare
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetCommit() *BFTCommit {
+	if x, ok := m.GetType().(*BFTMessage_Commit); ok {
+		return x.Commit
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetViewChange() *BFTViewChange {
+	if x, ok := m.GetType().(*BFTMessage_ViewChange); ok {
+		return x.ViewChange
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetNewView() *BFTNewView {
+	if x, ok := m.GetType().(*BFTMessage_NewView); ok {
+		return x.NewView
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetSyncRequest() *BFTSyncRequest {
+	if x, ok := m.GetType().(*BFTMessage_SyncRequest); ok {
+		return x.SyncRequest
+	}
+	return nil
+}
+
+func (m *BFTMessage) GetSyncResponse() *BFTSyncResponse {
+	if x, ok := m.GetType().(*BFTMessage_SyncResponse); ok {
+		return x.SyncResponse
+	}
+	return nil
+}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*BFTMessage) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _BFTMessage_OneofMarshaler, _BFTMessage_OneofUnmarshaler, _BFTMessage_OneofSizer, []interface{}{
+		(*BFTMessage_Request)(nil),
+		(*BFTMessage_PrePrepare)(nil),
+		(*BFTMessage_Prepare)(nil),
+		(*BFTMessage_Commit)(nil),
+		(*BFTMessage_ViewChange)(nil),
+		(*BFTMessage_NewView)(nil),
+		(*BFTMessage_SyncRequest)(nil),
+		(*BFTMessage_SyncResponse)(nil),
+	}
+}
+
+func _BFTMessage_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*BFTMessage)
+	// Type
+	switch x := m.Type.(type) {
+	case *BFTMessage_Request:
+		b.EncodeVarint(2<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Request); err != nil {
+			return err
+		}
+	case *BFTMessage_PrePrepare:
+		b.EncodeVarint(3<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.PrePrepare); err != nil {
+			return err
+		}
+	case *BFTMessage_Prepare:
+		b.EncodeVarint(4<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Prepare); err != nil {
+			return err
+		}
+	case *BFTMessage_Commit:
+		b.EncodeVarint(5<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Commit); err != nil {
+			return err
+		}
+	case *BFTMessage_ViewChange:
+		b.EncodeVarint(6<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.ViewChange); err != nil {
+			return err
+		}
+	case *BFTMessage_NewView:
+		b.EncodeVarint(7<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.NewView); err != nil {
+			return err
+		}
+	case *BFTMessage_SyncRequest:
+		b.EncodeVarint(8<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SyncRequest); err != nil {
+			return err
+		}
+	case *BFTMessage_SyncResponse:
+		b.EncodeVarint(9<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.SyncResponse); err != nil {
+			return err
+		}
+	case nil:
+	default:
+		return fmt.Errorf("BFTMessage.Type has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _BFTMessage_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*BFTMessage)
+	switch tag {
+	case 2: // Type.request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTRequest)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_Request{msg}
+		return true, err
+	case 3: // Type.pre_prepare
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTPrePrepare)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_PrePrepare{msg}
+		return true, err
+	case 4: // Type.prepare
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTPrepare)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_Prepare{msg}
+		return true, err
+	case 5: // Type.commit
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTCommit)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_Commit{msg}
+		return true, err
+	case 6: // Type.view_change
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTViewChange)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_ViewChange{msg}
+		return true, err
+	case 7: // Type.new_view
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTNewView)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_NewView{msg}
+		return true, err
+	case 8: // Type.sync_request
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTSyncRequest)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_SyncRequest{msg}
+		return true, err
+	case 9: // Type.sync_response
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		msg := new(BFTSyncResponse)
+		err := b.DecodeMessage(msg)
+		m.Type = &BFTMessage_SyncResponse{msg}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _BFTMessage_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*BFTMessage)
+	// Type
+	switch x := m.Type.(type) {
+	case *BFTMessage_Request:
+		s := proto.Size(x.Request)
+		n += proto.SizeVarint(2<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_PrePrepare:
+		s := proto.Size(x.PrePrepare)
+		n += proto.SizeVarint(3<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_Prepare:
+		s := proto.Size(x.Prepare)
+		n += proto.SizeVarint(4<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_Commit:
+		s := proto.Size(x.Commit)
+		n += proto.SizeVarint(5<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_ViewChange:
+		s := proto.Size(x.ViewChange)
+		n += proto.SizeVarint(6<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_NewView:
+		s := proto.Size(x.NewView)
+		n += proto.SizeVarint(7<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_SyncRequest:
+		s := proto.Size(x.SyncRequest)
+		n += proto.SizeVarint(8<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *BFTMessage_SyncResponse:
+		s := proto.Size(x.SyncResponse)
+		n += proto.SizeVarint(9<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// BFTRequest forwards a marshaled envelope that was broadcast
+// to a consenter to the primary of the current view.
+type BFTRequest struct {
+	Payload []byte `protobuf:"bytes,1,opt,name=payload,proto3" json:"payload,omitempty"`
+}
+
+func (m *BFTRequest) Reset()                    { *m = BFTRequest{} }
+func (m *BFTRequest) String() string            { return proto.CompactTextString(m) }
+func (*BFTRequest) ProtoMessage()               {}
+func (*BFTRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{2} }
+
+func (m *BFTRequest) GetPayload() []byte {
+	if m != nil {
+		return m.Payload
+	}
+	return nil
+}
+
+// BFTPrePrepare is sent by the primary of a view to propose the
+// envelopes of the block with the given sequence number.
+type BFTPrePrepare struct {
+	View      uint64   `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
+	Seq       uint64   `protobuf:"varint,2,opt,name=seq" json:"seq,omitempty"`
+	Envelopes [][]byte `protobuf:"bytes,3,rep,name=envelopes,proto3" json:"envelopes,omitempty"`
+	Digest    []byte   `protobuf:"bytes,4,opt,name=digest,proto3" json:"digest,omitempty"`
+}
+
+func (m *BFTPrePrepare) Reset()                    { *m = BFTPrePrepare{} }
+func (m *BFTPrePrepare) String() string            { return proto.CompactTextString(m) }
+func (*BFTPrePrepare) ProtoMessage()               {}
+func (*BFTPrePrepare) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{3} }
+
+func (m *BFTPrePrepare) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *BFTPrePrepare) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *BFTPrePrepare) GetEnvelopes() [][]byte {
+	if m != nil {
+		return m.Envelopes
+	}
+	return nil
+}
+
+func (m *BFTPrePrepare) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+// BFTPrepare is sent by a consenter that accepted a proposal.
+type BFTPrepare struct {
+	View   uint64 `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
+	Seq    uint64 `protobuf:"varint,2,opt,name=seq" json:"seq,omitempty"`
+	Digest []byte `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+}
+
+func (m *BFTPrepare) Reset()                    { *m = BFTPrepare{} }
+func (m *BFTPrepare) String() string            { return proto.CompactTextString(m) }
+func (*BFTPrepare) ProtoMessage()               {}
+func (*BFTPrepare) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{4} }
+
+func (m *BFTPrepare) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *BFTPrepare) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *BFTPrepare) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+// BFTCommit is sent by a consenter once a quorum accepted a proposal.
+// It carries the signature of the consenter over the header of the block.
+type BFTCommit struct {
+	View      uint64                    `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
+	Seq       uint64                    `protobuf:"varint,2,opt,name=seq" json:"seq,omitempty"`
+	Digest    []byte                    `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	Signature *common.MetadataSignature `protobuf:"bytes,4,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *BFTCommit) Reset()                    { *m = BFTCommit{} }
+func (m *BFTCommit) String() string            { return proto.CompactTextString(m) }
+func (*BFTCommit) ProtoMessage()               {}
+func (*BFTCommit) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+
+func (m *BFTCommit) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *BFTCommit) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *BFTCommit) GetDigest() []byte {
+	if m != nil {
+		return m.Digest
+	}
+	return nil
+}
+
+func (m *BFTCommit) GetSignature() *common.MetadataSignature {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+// BFTPrepared proves that a quorum accepted a proposal.
+type BFTPrepared struct {
+	PrePrepare *BFTEnvelope   `protobuf:"bytes,1,opt,name=pre_prepare,json=prePrepare" json:"pre_prepare,omitempty"`
+	Prepares   []*BFTEnvelope `protobuf:"bytes,2,rep,name=prepares" json:"prepares,omitempty"`
+}
+
+func (m *BFTPrepared) Reset()                    { *m = BFTPrepared{} }
+func (m *BFTPrepared) String() string            { return proto.CompactTextString(m) }
+func (*BFTPrepared) ProtoMessage()               {}
+func (*BFTPrepared) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
+
+func (m *BFTPrepared) GetPrePrepare() *BFTEnvelope {
+	if m != nil {
+		return m.PrePrepare
+	}
+	return nil
+}
+
+func (m *BFTPrepared) GetPrepares() []*BFTEnvelope {
+	if m != nil {
+		return m.Prepares
+	}
+	return nil
+}
+
+// BFTViewChange is sent by a consenter that suspects the primary of the current view.
+// It carries the height of the ledger of the consenter, along with the header and
+// the signatures of the last block, and the proposal for the next block, if a quorum
+// accepted one.
+type BFTViewChange struct {
+	View           uint64              `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
+	Height         uint64              `protobuf:"varint,2,opt,name=height" json:"height,omitempty"`
+	LastHeader     *common.BlockHeader `protobuf:"bytes,3,opt,name=last_header,json=lastHeader" json:"last_header,omitempty"`
+	LastSignatures *common.Metadata    `protobuf:"bytes,4,opt,name=last_signatures,json=lastSignatures" json:"last_signatures,omitempty"`
+	Prepared       *BFTPrepared        `protobuf:"bytes,5,opt,name=prepared" json:"prepared,omitempty"`
+}
+
+func (m *BFTViewChange) Reset()                    { *m = BFTViewChange{} }
+func (m *BFTViewChange) String() string            { return proto.CompactTextString(m) }
+func (*BFTViewChange) ProtoMessage()               {}
+func (*BFTViewChange) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
+
+func (m *BFTViewChange) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *BFTViewChange) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *BFTViewChange) GetLastHeader() *common.BlockHeader {
+	if m != nil {
+		return m.LastHeader
+	}
+	return nil
+}
+
+func (m *BFTViewChange) GetLastSignatures() *common.Metadata {
+	if m != nil {
+		return m.LastSignatures
+	}
+	return nil
+}
+
+func (m *BFTViewChange) GetPrepared() *BFTPrepared {
+	if m != nil {
+		return m.Prepared
+	}
+	return nil
+}
+
+// BFTNewView is sent by the primary of a view once a quorum of consenters
+// asked to change to the view. The pre-prepare re-proposes the block that
+// a quorum might have accepted in a previous view, if any.
+type BFTNewView struct {
+	View        uint64         `protobuf:"varint,1,opt,name=view" json:"view,omitempty"`
+	ViewChanges []*BFTEnvelope `protobuf:"bytes,2,rep,name=view_changes,json=viewChanges" json:"view_changes,omitempty"`
+	PrePrepare  *BFTEnvelope   `protobuf:"bytes,3,opt,name=pre_prepare,json=prePrepare" json:"pre_prepare,omitempty"`
+}
+
+func (m *BFTNewView) Reset()                    { *m = BFTNewView{} }
+func (m *BFTNewView) String() string            { return proto.CompactTextString(m) }
+func (*BFTNewView) ProtoMessage()               {}
+func (*BFTNewView) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{8} }
+
+func (m *BFTNewView) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *BFTNewView) GetViewChanges() []*BFTEnvelope {
+	if m != nil {
+		return m.ViewChanges
+	}
+	return nil
+}
+
+func (m *BFTNewView) GetPrePrepare() *BFTEnvelope {
+	if m != nil {
+		return m.PrePrepare
+	}
+	return nil
+}
+
+// BFTSyncRequest asks a consenter for the blocks from the given height.
+type BFTSyncRequest struct {
+	Height uint64 `protobuf:"varint,1,opt,name=height" json:"height,omitempty"`
+}
+
+func (m *BFTSyncRequest) Reset()                    { *m = BFTSyncRequest{} }
+func (m *BFTSyncRequest) String() string            { return proto.CompactTextString(m) }
+func (*BFTSyncRequest) ProtoMessage()               {}
+func (*BFTSyncRequest) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{9} }
+
+func (m *BFTSyncRequest) GetHeight() uint64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+// BFTSyncResponse carries committed blocks, along with their signatures.
+type BFTSyncResponse struct {
+	Blocks []*common.Block `protobuf:"bytes,1,rep,name=blocks" json:"blocks,omitempty"`
+}
+
+func (m *BFTSyncResponse) Reset()                    { *m = BFTSyncResponse{} }
+func (m *BFTSyncResponse) String() string            { return proto.CompactTextString(m) }
+func (*BFTSyncResponse) ProtoMessage()               {}
+func (*BFTSyncResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{10} }
+
+func (m *BFTSyncResponse) GetBlocks() []*common.Block {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+type BFTStepResponse struct {
+}
+
+func (m *BFTStepResponse) Reset()                    { *m = BFTStepResponse{} }
+func (m *BFTStepResponse) String() string            { return proto.CompactTextString(m) }
+func (*BFTStepResponse) ProtoMessage()               {}
+func (*BFTStepResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{11} }
+
+func init() {
+	proto.RegisterType((*BFTEnvelope)(nil), "orderer.BFTEnvelope")
+	proto.RegisterType((*BFTMessage)(nil), "orderer.BFTMessage")
+	proto.RegisterType((*BFTRequest)(nil), "orderer.BFTRequest")
+	proto.RegisterType((*BFTPrePrepare)(nil), "orderer.BFTPrePrepare")
+	proto.RegisterType((*BFTPrepare)(nil), "orderer.BFTPrepare")
+	proto.RegisterType((*BFTCommit)(nil), "orderer.BFTCommit")
+	proto.RegisterType((*BFTPrepared)(nil), "orderer.BFTPrepared")
+	proto.RegisterType((*BFTViewChange)(nil), "orderer.BFTViewChange")
+	proto.RegisterType((*BFTNewView)(nil), "orderer.BFTNewView")
+	proto.RegisterType((*BFTSyncRequest)(nil), "orderer.BFTSyncRequest")
+	proto.RegisterType((*BFTSyncResponse)(nil), "orderer.BFTSyncResponse")
+	proto.RegisterType((*BFTStepResponse)(nil), "orderer.BFTStepResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for BFT service
+
+type BFTClient interface {
+	// Step passes a message of the BFT consensus protocol to the consenter
+	Step(ctx context.Context, in *BFTEnvelope, opts ...grpc.CallOption) (*BFTStepResponse, error)
+}
+
+type bFTClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewBFTClient(cc *grpc.ClientConn) BFTClient {
+	return &bFTClient{cc}
+}
+
+func (c *bFTClient) Step(ctx context.Context, in *BFTEnvelope, opts ...grpc.CallOption) (*BFTStepResponse, error) {
+	out := new(BFTStepResponse)
+	err := grpc.Invoke(ctx, "/orderer.BFT/Step", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for BFT service
+
+type BFTServer interface {
+	// Step passes a message of the BFT consensus protocol to the consenter
+	Step(context.Context, *BFTEnvelope) (*BFTStepResponse, error)
+}
+
+func RegisterBFTServer(s *grpc.Server, srv BFTServer) {
+	s.RegisterService(&_BFT_serviceDesc, srv)
+}
+
+func _BFT_Step_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BFTEnvelope)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BFTServer).Step(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orderer.BFT/Step",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BFTServer).Step(ctx, req.(*BFTEnvelope))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _BFT_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orderer.BFT",
+	HandlerType: (*BFTServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Step",
+			Handler:    _BFT_Step_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "orderer/bft.proto",
+}
+
+func init() { proto.RegisterFile("orderer/bft.proto", fileDescriptor3) }
+
+var fileDescriptor3 = []byte{
+	// 712 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0x4f, 0x6b, 0xdb, 0x4a,
+	0x10, 0xb7, 0x22, 0x3f, 0x27, 0x1e, 0x39, 0xff, 0x36, 0x21, 0x4f, 0x2f, 0xbc, 0x83, 0x11, 0xe4,
+	0x91, 0x43, 0xb0, 0x43, 0x5e, 0x4b, 0x12, 0x28, 0x04, 0x14, 0x6a, 0x4c, 0x21, 0x25, 0x28, 0x6e,
+	0x0f, 0xbd, 0x18, 0x59, 0x9a, 0xc8, 0x22, 0x8a, 0xa4, 0xec, 0x2a, 0x36, 0xbe, 0xf6, 0x13, 0xf4,
+	0x13, 0xf6, 0xd6, 0xef, 0x51, 0x76, 0xb5, 0x92, 0x56, 0xb1, 0x5b, 0x5a, 0x7a, 0xf2, 0xce, 0xce,
+	0xfc, 0x76, 0x66, 0x7e, 0x33, 0x3f, 0x0b, 0x76, 0x13, 0xea, 0x23, 0x45, 0xda, 0x9f, 0xdc, 0x67,
+	0xbd, 0x94, 0x26, 0x59, 0x42, 0xd6, 0xe5, 0xd5, 0xe1, 0x9e, 0x97, 0x3c, 0x3e, 0x26, 0x71, 0x3f,
+	0xff, 0xc9, 0xbd, 0xd6, 0x18, 0x0c, 0x7b, 0x30, 0x7a, 0x1b, 0xcf, 0x30, 0x4a, 0x52, 0x24, 0x26,
+	0xac, 0x7b, 0x53, 0x37, 0x8e, 0x31, 0x32, 0xb5, 0xae, 0x76, 0xdc, 0x76, 0x0a, 0x93, 0x7b, 0x52,
+	0x77, 0x11, 0x25, 0xae, 0x6f, 0xae, 0x75, 0xb5, 0xe3, 0x8e, 0x53, 0x98, 0xe4, 0x5f, 0x68, 0xb3,
+	0x30, 0x88, 0xdd, 0xec, 0x99, 0xa2, 0xa9, 0x0b, 0x5f, 0x75, 0x61, 0x7d, 0xd3, 0x01, 0xec, 0xc1,
+	0xe8, 0x06, 0x19, 0x73, 0x03, 0x91, 0x80, 0x62, 0x1a, 0x85, 0x9e, 0x2b, 0x12, 0x34, 0x9d, 0xc2,
+	0x24, 0x7d, 0xee, 0x79, 0x7a, 0x46, 0x96, 0x89, 0x04, 0xc6, 0xd9, 0x5e, 0x4f, 0x56, 0xde, 0xb3,
+	0x07, 0x23, 0x27, 0x77, 0x0d, 0x1b, 0x4e, 0x11, 0x45, 0x2e, 0xc1, 0x48, 0x29, 0x8e, 0x53, 0x8a,
+	0xa9, 0x2b, 0x33, 0x1b, 0x67, 0x07, 0x2a, 0xe8, 0x96, 0xe2, 0x6d, 0xee, 0x1d, 0x36, 0x1c, 0x48,
+	0x4b, 0x8b, 0xe7, 0x2a, 0x60, 0xcd, 0xe5, 0x5c, 0x15, 0xa6, 0x88, 0x22, 0x27, 0xd0, 0xe2, 0xb4,
+	0x85, 0x99, 0xf9, 0x97, 0x88, 0x27, 0x6a, 0xfc, 0xb5, 0xf0, 0x0c, 0x1b, 0x8e, 0x8c, 0xe1, 0x95,
+	0xcd, 0x42, 0x9c, 0x8f, 0x39, 0x77, 0x01, 0x9a, 0xad, 0xe5, 0xca, 0x3e, 0x86, 0x38, 0xbf, 0x16,
+	0x5e, 0x5e, 0xd9, 0xac, 0xb4, 0xc8, 0x29, 0x6c, 0xc4, 0x38, 0x1f, 0xf3, 0x1b, 0x73, 0x7d, 0xb9,
+	0xb4, 0xf7, 0x38, 0xe7, 0x50, 0x5e, 0x5a, 0x9c, 0x1f, 0xc9, 0x1b, 0xe8, 0xb0, 0x45, 0xec, 0x8d,
+	0x0b, 0xf2, 0x36, 0x04, 0xea, 0x6f, 0x15, 0x75, 0xb7, 0x88, 0xbd, 0x8a, 0x40, 0x83, 0x55, 0x26,
+	0xb9, 0x82, 0x4d, 0x89, 0x66, 0x69, 0x12, 0x33, 0x34, 0xdb, 0x02, 0x6e, 0x2e, 0xc3, 0x73, 0xff,
+	0xb0, 0xe1, 0x74, 0x98, 0x62, 0xdb, 0x2d, 0x68, 0x8e, 0x16, 0x29, 0x5a, 0xff, 0x89, 0x31, 0x17,
+	0xcf, 0x2a, 0xdb, 0xa2, 0xd5, 0xb6, 0xc5, 0x7a, 0x80, 0xcd, 0xda, 0x64, 0x08, 0x81, 0xa6, 0xe8,
+	0x36, 0x5f, 0x07, 0x71, 0x26, 0x3b, 0xa0, 0x33, 0x7c, 0x12, 0x7b, 0xd0, 0x74, 0xf8, 0x91, 0x2f,
+	0x19, 0xca, 0x25, 0x65, 0xa6, 0xde, 0xd5, 0xf9, 0x92, 0x95, 0x17, 0xe4, 0x00, 0x5a, 0x7e, 0x18,
+	0xf0, 0xee, 0x9b, 0x22, 0x9b, 0xb4, 0xac, 0x77, 0xa2, 0xa8, 0xdf, 0xcb, 0x54, 0xbd, 0xa5, 0xd7,
+	0xde, 0xfa, 0xac, 0x41, 0xbb, 0x1c, 0xf6, 0x9f, 0xbd, 0x45, 0xce, 0x55, 0xc9, 0xe4, 0x1b, 0xf8,
+	0x4f, 0x4f, 0xea, 0xf2, 0x06, 0x33, 0xd7, 0x77, 0x33, 0xf7, 0xae, 0x08, 0x50, 0xd5, 0x34, 0x03,
+	0xa3, 0x6a, 0xc8, 0x27, 0xaf, 0xeb, 0x12, 0xd0, 0xc4, 0x4b, 0xfb, 0xea, 0xec, 0x0a, 0x65, 0xd7,
+	0xd6, 0xff, 0x14, 0x36, 0x24, 0x84, 0x99, 0x6b, 0x5d, 0xfd, 0x87, 0x98, 0x32, 0xca, 0xfa, 0xaa,
+	0x89, 0xb1, 0x55, 0x6b, 0xbb, 0x92, 0x80, 0x03, 0x68, 0x4d, 0x31, 0x0c, 0xa6, 0x99, 0xe4, 0x40,
+	0x5a, 0xe4, 0x15, 0x18, 0x91, 0xcb, 0xb2, 0xf1, 0x14, 0x5d, 0x1f, 0xa9, 0x54, 0xea, 0x5e, 0xd1,
+	0xb0, 0x1d, 0x25, 0xde, 0xc3, 0x50, 0xb8, 0x1c, 0xe0, 0x71, 0xf9, 0x99, 0x5c, 0xc2, 0xb6, 0x40,
+	0x95, 0xdd, 0x33, 0x49, 0xd5, 0xce, 0x4b, 0xaa, 0x9c, 0x2d, 0x1e, 0x58, 0x12, 0xc6, 0x94, 0x06,
+	0x7d, 0x29, 0xd8, 0xfd, 0x15, 0x02, 0xf7, 0xcb, 0x06, 0x7d, 0xeb, 0x8b, 0x06, 0x50, 0xe9, 0x6b,
+	0x65, 0x77, 0xe7, 0xd0, 0x51, 0x54, 0xfd, 0x73, 0xe6, 0x8c, 0x4a, 0xd2, 0xec, 0xe5, 0x94, 0xf4,
+	0x5f, 0x9b, 0x92, 0x75, 0x0c, 0x5b, 0x75, 0xed, 0x2a, 0xfc, 0x6a, 0x2a, 0xbf, 0xd6, 0x05, 0x6c,
+	0xbf, 0x90, 0x29, 0x39, 0x82, 0xd6, 0x84, 0xf3, 0xca, 0x4c, 0x4d, 0x94, 0xb9, 0x59, 0x63, 0xdb,
+	0x91, 0x4e, 0x6b, 0x37, 0x47, 0x66, 0x98, 0x16, 0xc8, 0xb3, 0x2b, 0xd0, 0xed, 0xc1, 0x88, 0x5c,
+	0x40, 0x93, 0x5f, 0x93, 0x95, 0x75, 0x1e, 0xd6, 0xff, 0x1f, 0x14, 0xb8, 0xd5, 0xb0, 0x3f, 0xc0,
+	0x51, 0x42, 0x83, 0xde, 0x74, 0x91, 0x22, 0x8d, 0xd0, 0x0f, 0x90, 0xf6, 0xee, 0xdd, 0x09, 0x0d,
+	0xbd, 0xfc, 0x93, 0xc3, 0x0a, 0xe8, 0xa7, 0x93, 0x20, 0xcc, 0xa6, 0xcf, 0x13, 0x5e, 0x59, 0x5f,
+	0x89, 0xee, 0xe7, 0xd1, 0xfd, 0x3c, 0xba, 0x2f, 0xa3, 0x27, 0x2d, 0x61, 0xff, 0xff, 0x7d, 0x00,
+	0xf5, 0xfd, 0x1f, 0x07, 0xe3, 0x06, 0x00, 0x00,
+}
@@ -121,36 +121,94 @@ func (m *ChannelRestrictions) GetMaxCount() uint64 {
 	return 0
 }
 
+// BFTConsenter identifies an orderer that takes part in the BFT consensus of a channel
+type BFTConsenter struct {
+	Host     string `protobuf:"bytes,1,opt,name=host" json:"host,omitempty"`
+	Port     uint32 `protobuf:"varint,2,opt,name=port" json:"port,omitempty"`
+	Identity []byte `protobuf:"bytes,3,opt,name=identity,proto3" json:"identity,omitempty"`
+}
+
+func (m *BFTConsenter) Reset()                    { *m = BFTConsenter{} }
+func (m *BFTConsenter) String() string            { return proto.CompactTextString(m) }
+func (*BFTConsenter) ProtoMessage()               {}
+func (*BFTConsenter) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{5} }
+
+func (m *BFTConsenter) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *BFTConsenter) GetPort() uint32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *BFTConsenter) GetIdentity() []byte {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+// BFTConsenters is the set of orderers that order the transactions of a channel
+// when the consensus type is BFT. The index of a consenter in the set identifies it
+// in the protocol. The set tolerates f faulty consenters out of 3f+1.
+type BFTConsenters struct {
+	Consenters []*BFTConsenter `protobuf:"bytes,1,rep,name=consenters" json:"consenters,omitempty"`
+}
+
+func (m *BFTConsenters) Reset()                    { *m = BFTConsenters{} }
+func (m *BFTConsenters) String() string            { return proto.CompactTextString(m) }
+func (*BFTConsenters) ProtoMessage()               {}
+func (*BFTConsenters) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{6} }
+
+func (m *BFTConsenters) GetConsenters() []*BFTConsenter {
+	if m != nil {
+		return m.Consenters
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ConsensusType)(nil), "orderer.ConsensusType")
 	proto.RegisterType((*BatchSize)(nil), "orderer.BatchSize")
 	proto.RegisterType((*BatchTimeout)(nil), "orderer.BatchTimeout")
 	proto.RegisterType((*KafkaBrokers)(nil), "orderer.KafkaBrokers")
 	proto.RegisterType((*ChannelRestrictions)(nil), "orderer.ChannelRestrictions")
+	proto.RegisterType((*BFTConsenter)(nil), "orderer.BFTConsenter")
+	proto.RegisterType((*BFTConsenters)(nil), "orderer.BFTConsenters")
 }
 
 func init() { proto.RegisterFile("orderer/configuration.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 313 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0xd0, 0xcd, 0x4a, 0xc3, 0x40,
-	0x10, 0x07, 0x70, 0x62, 0x8b, 0xb5, 0x8b, 0x45, 0xbb, 0xbd, 0x04, 0x7a, 0x29, 0x11, 0xa1, 0x48,
-	0x49, 0x40, 0xdf, 0x20, 0x3d, 0x4a, 0x2f, 0xb1, 0x5e, 0xbc, 0x94, 0x4d, 0x3a, 0x49, 0x96, 0x36,
-	0x3b, 0x61, 0x76, 0x03, 0x89, 0xef, 0xe1, 0xfb, 0xca, 0x6e, 0x52, 0xed, 0x6d, 0x3e, 0x7e, 0x0b,
-	0xb3, 0x7f, 0xb6, 0x44, 0x3a, 0x02, 0x01, 0x45, 0x19, 0xaa, 0x5c, 0x16, 0x0d, 0x09, 0x23, 0x51,
-	0x85, 0x35, 0xa1, 0x41, 0x3e, 0x19, 0x96, 0xc1, 0x13, 0x9b, 0x6d, 0x51, 0x69, 0x50, 0xba, 0xd1,
-	0xfb, 0xae, 0x06, 0xce, 0xd9, 0xd8, 0x74, 0x35, 0xf8, 0xde, 0xca, 0x5b, 0x4f, 0x13, 0x57, 0x07,
-	0x3f, 0x1e, 0x9b, 0xc6, 0xc2, 0x64, 0xe5, 0x87, 0xfc, 0x06, 0xfe, 0xc2, 0xe6, 0x95, 0x68, 0x0f,
-	0x15, 0x68, 0x2d, 0x0a, 0x38, 0x64, 0xd8, 0x28, 0xe3, 0xf8, 0x2c, 0x79, 0xa8, 0x44, 0xbb, 0xeb,
-	0xe7, 0x5b, 0x3b, 0xe6, 0x1b, 0xc6, 0x45, 0xaa, 0xf1, 0xdc, 0x18, 0x38, 0xd8, 0x47, 0x69, 0x67,
-	0x40, 0xfb, 0x37, 0x0e, 0x3f, 0x5e, 0x36, 0x3b, 0xd1, 0xc6, 0x76, 0xce, 0x43, 0xb6, 0xa8, 0x09,
-	0x72, 0x20, 0x82, 0xe3, 0x15, 0x1f, 0x39, 0x3e, 0xff, 0x5b, 0x5d, 0x7c, 0xb0, 0x66, 0xf7, 0xee,
-	0xac, 0xbd, 0xac, 0x00, 0x1b, 0xc3, 0x7d, 0x36, 0x31, 0x7d, 0x39, 0x9c, 0x7f, 0x69, 0xad, 0x7c,
-	0x17, 0xf9, 0x49, 0xc4, 0x84, 0x27, 0x20, 0x6d, 0x65, 0xda, 0x97, 0xbe, 0xb7, 0x1a, 0x59, 0x39,
-	0xb4, 0xc1, 0x2b, 0x5b, 0x6c, 0x4b, 0xa1, 0x14, 0x9c, 0x13, 0xd0, 0x86, 0x64, 0x66, 0x53, 0xd3,
-	0x7c, 0xc9, 0xa6, 0xf6, 0xa0, 0xff, 0xcf, 0x8e, 0x93, 0xbb, 0x4a, 0xb4, 0xee, 0x97, 0xf1, 0x27,
-	0x7b, 0x46, 0x2a, 0xc2, 0xb2, 0xab, 0x81, 0xce, 0x70, 0x2c, 0x80, 0xc2, 0x5c, 0xa4, 0x24, 0xb3,
-	0x3e, 0x6d, 0x1d, 0x0e, 0x69, 0x7f, 0x6d, 0x0a, 0x69, 0xca, 0x26, 0x0d, 0x33, 0xac, 0xa2, 0x2b,
-	0x1d, 0xf5, 0x3a, 0xea, 0x75, 0x34, 0xe8, 0xf4, 0xd6, 0xf5, 0x6f, 0xbf, 0x01, 0x00, 0x00, 0xff,
-	0xff, 0x0c, 0x47, 0xa2, 0x55, 0xca, 0x01, 0x00, 0x00,
+	// 376 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x92, 0xd1, 0xaa, 0x9b, 0x40,
+	0x10, 0x86, 0xb1, 0xe7, 0xd0, 0x73, 0x32, 0x4d, 0x68, 0xcf, 0x1e, 0x0a, 0xd2, 0xdc, 0x04, 0x4b,
+	0x21, 0x94, 0xa0, 0x90, 0xd2, 0x17, 0x30, 0x90, 0x9b, 0x92, 0x1b, 0x9b, 0xde, 0xf4, 0x26, 0xac,
+	0x3a, 0xea, 0x92, 0xb8, 0x2b, 0xb3, 0x2b, 0x68, 0xdf, 0xa3, 0xef, 0x5b, 0x76, 0xd5, 0xd4, 0xbb,
+	0xff, 0x9f, 0xf9, 0x1c, 0xfe, 0x19, 0x17, 0xd6, 0x8a, 0x72, 0x24, 0xa4, 0x28, 0x53, 0xb2, 0x10,
+	0x65, 0x4b, 0xdc, 0x08, 0x25, 0xc3, 0x86, 0x94, 0x51, 0xec, 0x69, 0x6c, 0x06, 0x9f, 0x61, 0x75,
+	0x50, 0x52, 0xa3, 0xd4, 0xad, 0x3e, 0xf7, 0x0d, 0x32, 0x06, 0x8f, 0xa6, 0x6f, 0xd0, 0xf7, 0x36,
+	0xde, 0x76, 0x91, 0x38, 0x1d, 0xfc, 0xf5, 0x60, 0x11, 0x73, 0x93, 0x55, 0x3f, 0xc5, 0x1f, 0x64,
+	0x5f, 0xe1, 0xa5, 0xe6, 0xdd, 0xa5, 0x46, 0xad, 0x79, 0x89, 0x97, 0x4c, 0xb5, 0xd2, 0x38, 0x7c,
+	0x95, 0xbc, 0xaf, 0x79, 0x77, 0x1a, 0xea, 0x07, 0x5b, 0x66, 0x3b, 0x60, 0x3c, 0xd5, 0xea, 0xd6,
+	0x1a, 0xbc, 0xd8, 0x8f, 0xd2, 0xde, 0xa0, 0xf6, 0xdf, 0x38, 0xf8, 0xc3, 0xd4, 0x39, 0xf1, 0x2e,
+	0xb6, 0x75, 0x16, 0xc2, 0x6b, 0x43, 0x58, 0x20, 0x11, 0xe6, 0x33, 0xfc, 0xc1, 0xe1, 0x2f, 0xf7,
+	0xd6, 0xc4, 0x07, 0x5b, 0x58, 0xba, 0x58, 0x67, 0x51, 0xa3, 0x6a, 0x0d, 0xf3, 0xe1, 0xc9, 0x0c,
+	0x72, 0x8c, 0x3f, 0x59, 0x4b, 0xfe, 0xe0, 0xc5, 0x95, 0xc7, 0xa4, 0xae, 0x48, 0xda, 0x92, 0xe9,
+	0x20, 0x7d, 0x6f, 0xf3, 0x60, 0xc9, 0xd1, 0x06, 0x7b, 0x78, 0x3d, 0x54, 0x5c, 0x4a, 0xbc, 0x25,
+	0xa8, 0x0d, 0x89, 0xcc, 0x5e, 0x4d, 0xb3, 0x35, 0x2c, 0x6c, 0xa0, 0xff, 0xcb, 0x3e, 0x26, 0xcf,
+	0x35, 0xef, 0xdc, 0x96, 0x41, 0x02, 0xcb, 0xf8, 0x78, 0x1e, 0xee, 0x68, 0x90, 0xec, 0x0d, 0x2b,
+	0xa5, 0xa7, 0x10, 0x4e, 0xdb, 0x5a, 0xa3, 0xc8, 0x8c, 0xbb, 0x3b, 0xcd, 0x3e, 0xc1, 0xb3, 0xc8,
+	0x51, 0x1a, 0x61, 0x7a, 0xb7, 0xe4, 0x32, 0xb9, 0xfb, 0xe0, 0x08, 0xab, 0xf9, 0x4c, 0xcd, 0xbe,
+	0x03, 0x64, 0x77, 0xe7, 0x52, 0xbf, 0xdb, 0x7f, 0x0c, 0xc7, 0xff, 0x18, 0xce, 0xd9, 0x64, 0x06,
+	0xc6, 0xbf, 0xe0, 0x8b, 0xa2, 0x32, 0xac, 0xfa, 0x06, 0xe9, 0x86, 0x79, 0x89, 0x14, 0x16, 0x3c,
+	0x25, 0x91, 0x0d, 0x2f, 0x41, 0x4f, 0x13, 0x7e, 0xef, 0x4a, 0x61, 0xaa, 0x36, 0x0d, 0x33, 0x55,
+	0x47, 0x33, 0x3a, 0x1a, 0xe8, 0x68, 0xa0, 0xa3, 0x91, 0x4e, 0xdf, 0x3a, 0xff, 0xed, 0xdf, 0x00,
+	0xa1, 0x92, 0x87, 0x7d, 0x66, 0x02, 0x00, 0x00,
 }
@@ -22,6 +22,14 @@ It has these top-level messages:
 	ServerStatus
 	LogLevelRequest
 	LogLevelResponse
+	ChaincodeRuntime
+	ChaincodeRuntimes
+	GossipMember
+	GossipChannelStatus
+	GossipIdentity
+	GossipStatus
+	GossipConfigUpdate
+	GossipConfig
 	ChaincodeID
 	ChaincodeInput
 	ChaincodeSpec
@@ -33,6 +41,7 @@ It has these top-level messages:
 	GetStateByRange
 	GetQueryResult
 	GetHistoryForKey
+	GetHistoryForKeyRange
 	QueryStateNext
 	QueryStateClose
 	QueryResultBytes
@@ -75,6 +84,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf "github.com/golang/protobuf/ptypes/empty"
+import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
 
 import (
 	context "golang.org/x/net/context"
@@ -125,6 +135,36 @@ func (x ServerStatus_StatusCode) String() string {
 }
 func (ServerStatus_StatusCode) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
 
+type ChaincodeRuntime_State int32
+
+const (
+	ChaincodeRuntime_UNKNOWN   ChaincodeRuntime_State = 0
+	ChaincodeRuntime_LAUNCHING ChaincodeRuntime_State = 1
+	ChaincodeRuntime_RUNNING   ChaincodeRuntime_State = 2
+	ChaincodeRuntime_CRASHED   ChaincodeRuntime_State = 3
+	ChaincodeRuntime_STOPPED   ChaincodeRuntime_State = 4
+)
+
+var ChaincodeRuntime_State_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "LAUNCHING",
+	2: "RUNNING",
+	3: "CRASHED",
+	4: "STOPPED",
+}
+var ChaincodeRuntime_State_value = map[string]int32{
+	"UNKNOWN":   0,
+	"LAUNCHING": 1,
+	"RUNNING":   2,
+	"CRASHED":   3,
+	"STOPPED":   4,
+}
+
+func (x ChaincodeRuntime_State) String() string {
+	return proto.EnumName(ChaincodeRuntime_State_name, int32(x))
+}
+func (ChaincodeRuntime_State) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{3, 0} }
+
 type ServerStatus struct {
 	Status ServerStatus_StatusCode `protobuf:"varint,1,opt,name=status,enum=protos.ServerStatus_StatusCode" json:"status,omitempty"`
 }
@@ -189,11 +229,377 @@ func (m *LogLevelResponse) GetLogLevel() string {
 	return ""
 }
 
+// ChaincodeRuntime is the state of a chaincode runtime as seen by the peer
+type ChaincodeRuntime struct {
+	Name    string                 `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Version string                 `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	State   ChaincodeRuntime_State `protobuf:"varint,3,opt,name=state,enum=protos.ChaincodeRuntime_State" json:"state,omitempty"`
+	// number of times the peer relaunched the chaincode after it crashed
+	RestartCount uint32 `protobuf:"varint,4,opt,name=restart_count,json=restartCount" json:"restart_count,omitempty"`
+	// why the chaincode last crashed or failed to launch
+	LastError string `protobuf:"bytes,5,opt,name=last_error,json=lastError" json:"last_error,omitempty"`
+}
+
+func (m *ChaincodeRuntime) Reset()                    { *m = ChaincodeRuntime{} }
+func (m *ChaincodeRuntime) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeRuntime) ProtoMessage()               {}
+func (*ChaincodeRuntime) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ChaincodeRuntime) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ChaincodeRuntime) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *ChaincodeRuntime) GetState() ChaincodeRuntime_State {
+	if m != nil {
+		return m.State
+	}
+	return ChaincodeRuntime_UNKNOWN
+}
+
+func (m *ChaincodeRuntime) GetRestartCount() uint32 {
+	if m != nil {
+		return m.RestartCount
+	}
+	return 0
+}
+
+func (m *ChaincodeRuntime) GetLastError() string {
+	if m != nil {
+		return m.LastError
+	}
+	return ""
+}
+
+type ChaincodeRuntimes struct {
+	Runtimes []*ChaincodeRuntime `protobuf:"bytes,1,rep,name=runtimes" json:"runtimes,omitempty"`
+}
+
+func (m *ChaincodeRuntimes) Reset()                    { *m = ChaincodeRuntimes{} }
+func (m *ChaincodeRuntimes) String() string            { return proto.CompactTextString(m) }
+func (*ChaincodeRuntimes) ProtoMessage()               {}
+func (*ChaincodeRuntimes) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *ChaincodeRuntimes) GetRuntimes() []*ChaincodeRuntime {
+	if m != nil {
+		return m.Runtimes
+	}
+	return nil
+}
+
+// GossipMember is a peer as seen by the gossip component of the peer
+type GossipMember struct {
+	Endpoint         string `protobuf:"bytes,1,opt,name=endpoint" json:"endpoint,omitempty"`
+	InternalEndpoint string `protobuf:"bytes,2,opt,name=internal_endpoint,json=internalEndpoint" json:"internal_endpoint,omitempty"`
+	PkiId            []byte `protobuf:"bytes,3,opt,name=pki_id,json=pkiId,proto3" json:"pki_id,omitempty"`
+	// MSP ID of the organization of the peer
+	Org string `protobuf:"bytes,4,opt,name=org" json:"org,omitempty"`
+	// height of the ledger of the channel, as published by the peer in the
+	// channel. It is only set for the peers of a channel
+	LedgerHeight uint64 `protobuf:"varint,5,opt,name=ledger_height,json=ledgerHeight" json:"ledger_height,omitempty"`
+	// when an alive message of the peer was last received
+	LastAlive *google_protobuf1.Timestamp `protobuf:"bytes,6,opt,name=last_alive,json=lastAlive" json:"last_alive,omitempty"`
+}
+
+func (m *GossipMember) Reset()                    { *m = GossipMember{} }
+func (m *GossipMember) String() string            { return proto.CompactTextString(m) }
+func (*GossipMember) ProtoMessage()               {}
+func (*GossipMember) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *GossipMember) GetEndpoint() string {
+	if m != nil {
+		return m.Endpoint
+	}
+	return ""
+}
+
+func (m *GossipMember) GetInternalEndpoint() string {
+	if m != nil {
+		return m.InternalEndpoint
+	}
+	return ""
+}
+
+func (m *GossipMember) GetPkiId() []byte {
+	if m != nil {
+		return m.PkiId
+	}
+	return nil
+}
+
+func (m *GossipMember) GetOrg() string {
+	if m != nil {
+		return m.Org
+	}
+	return ""
+}
+
+func (m *GossipMember) GetLedgerHeight() uint64 {
+	if m != nil {
+		return m.LedgerHeight
+	}
+	return 0
+}
+
+func (m *GossipMember) GetLastAlive() *google_protobuf1.Timestamp {
+	if m != nil {
+		return m.LastAlive
+	}
+	return nil
+}
+
+// GossipChannelStatus is the state of a channel as seen by the gossip component of the peer
+type GossipChannelStatus struct {
+	ChannelId string          `protobuf:"bytes,1,opt,name=channel_id,json=channelId" json:"channel_id,omitempty"`
+	Peers     []*GossipMember `protobuf:"bytes,2,rep,name=peers" json:"peers,omitempty"`
+	// PKI-ID of the leader of the organization of the peer in the channel
+	Leader []byte `protobuf:"bytes,3,opt,name=leader,proto3" json:"leader,omitempty"`
+	// number of messages held by the stores of the channel
+	BlockMsgStoreSize     int32 `protobuf:"varint,4,opt,name=block_msg_store_size,json=blockMsgStoreSize" json:"block_msg_store_size,omitempty"`
+	BlockPullSize         int32 `protobuf:"varint,5,opt,name=block_pull_size,json=blockPullSize" json:"block_pull_size,omitempty"`
+	StateInfoMsgStoreSize int32 `protobuf:"varint,6,opt,name=state_info_msg_store_size,json=stateInfoMsgStoreSize" json:"state_info_msg_store_size,omitempty"`
+	LeaderMsgStoreSize    int32 `protobuf:"varint,7,opt,name=leader_msg_store_size,json=leaderMsgStoreSize" json:"leader_msg_store_size,omitempty"`
+}
+
+func (m *GossipChannelStatus) Reset()                    { *m = GossipChannelStatus{} }
+func (m *GossipChannelStatus) String() string            { return proto.CompactTextString(m) }
+func (*GossipChannelStatus) ProtoMessage()               {}
+func (*GossipChannelStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *GossipChannelStatus) GetChannelId() string {
+	if m != nil {
+		return m.ChannelId
+	}
+	return ""
+}
+
+func (m *GossipChannelStatus) GetPeers() []*GossipMember {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+func (m *GossipChannelStatus) GetLeader() []byte {
+	if m != nil {
+		return m.Leader
+	}
+	return nil
+}
+
+func (m *GossipChannelStatus) GetBlockMsgStoreSize() int32 {
+	if m != nil {
+		return m.BlockMsgStoreSize
+	}
+	return 0
+}
+
+func (m *GossipChannelStatus) GetBlockPullSize() int32 {
+	if m != nil {
+		return m.BlockPullSize
+	}
+	return 0
+}
+
+func (m *GossipChannelStatus) GetStateInfoMsgStoreSize() int32 {
+	if m != nil {
+		return m.StateInfoMsgStoreSize
+	}
+	return 0
+}
+
+func (m *GossipChannelStatus) GetLeaderMsgStoreSize() int32 {
+	if m != nil {
+		return m.LeaderMsgStoreSize
+	}
+	return 0
+}
+
+// GossipIdentity is an identity of a peer held by the cert store of the gossip component
+type GossipIdentity struct {
+	PkiId    []byte `protobuf:"bytes,1,opt,name=pki_id,json=pkiId,proto3" json:"pki_id,omitempty"`
+	Org      string `protobuf:"bytes,2,opt,name=org" json:"org,omitempty"`
+	Identity []byte `protobuf:"bytes,3,opt,name=identity,proto3" json:"identity,omitempty"`
+}
+
+func (m *GossipIdentity) Reset()                    { *m = GossipIdentity{} }
+func (m *GossipIdentity) String() string            { return proto.CompactTextString(m) }
+func (*GossipIdentity) ProtoMessage()               {}
+func (*GossipIdentity) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *GossipIdentity) GetPkiId() []byte {
+	if m != nil {
+		return m.PkiId
+	}
+	return nil
+}
+
+func (m *GossipIdentity) GetOrg() string {
+	if m != nil {
+		return m.Org
+	}
+	return ""
+}
+
+func (m *GossipIdentity) GetIdentity() []byte {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+// GossipStatus is the membership and channel state of the gossip component of the peer
+type GossipStatus struct {
+	Self *GossipMember `protobuf:"bytes,1,opt,name=self" json:"self,omitempty"`
+	// the peers considered alive
+	Peers      []*GossipMember        `protobuf:"bytes,2,rep,name=peers" json:"peers,omitempty"`
+	Channels   []*GossipChannelStatus `protobuf:"bytes,3,rep,name=channels" json:"channels,omitempty"`
+	Identities []*GossipIdentity      `protobuf:"bytes,4,rep,name=identities" json:"identities,omitempty"`
+	// number of messages held by the stores that are not tied to a channel
+	CertStoreSize         int32 `protobuf:"varint,5,opt,name=cert_store_size,json=certStoreSize" json:"cert_store_size,omitempty"`
+	StateInfoMsgStoreSize int32 `protobuf:"varint,6,opt,name=state_info_msg_store_size,json=stateInfoMsgStoreSize" json:"state_info_msg_store_size,omitempty"`
+}
+
+func (m *GossipStatus) Reset()                    { *m = GossipStatus{} }
+func (m *GossipStatus) String() string            { return proto.CompactTextString(m) }
+func (*GossipStatus) ProtoMessage()               {}
+func (*GossipStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *GossipStatus) GetSelf() *GossipMember {
+	if m != nil {
+		return m.Self
+	}
+	return nil
+}
+
+func (m *GossipStatus) GetPeers() []*GossipMember {
+	if m != nil {
+		return m.Peers
+	}
+	return nil
+}
+
+func (m *GossipStatus) GetChannels() []*GossipChannelStatus {
+	if m != nil {
+		return m.Channels
+	}
+	return nil
+}
+
+func (m *GossipStatus) GetIdentities() []*GossipIdentity {
+	if m != nil {
+		return m.Identities
+	}
+	return nil
+}
+
+func (m *GossipStatus) GetCertStoreSize() int32 {
+	if m != nil {
+		return m.CertStoreSize
+	}
+	return 0
+}
+
+func (m *GossipStatus) GetStateInfoMsgStoreSize() int32 {
+	if m != nil {
+		return m.StateInfoMsgStoreSize
+	}
+	return 0
+}
+
+// GossipConfigUpdate is a change of the configuration of the gossip component of the peer
+type GossipConfigUpdate struct {
+	AddBootstrapPeers    []string `protobuf:"bytes,1,rep,name=add_bootstrap_peers,json=addBootstrapPeers" json:"add_bootstrap_peers,omitempty"`
+	RemoveBootstrapPeers []string `protobuf:"bytes,2,rep,name=remove_bootstrap_peers,json=removeBootstrapPeers" json:"remove_bootstrap_peers,omitempty"`
+	// whether external_endpoint replaces the endpoint the peer publishes to peers
+	// of other organizations. An empty endpoint makes the peer inaccessible
+	// outside of its organization
+	UpdateExternalEndpoint bool   `protobuf:"varint,3,opt,name=update_external_endpoint,json=updateExternalEndpoint" json:"update_external_endpoint,omitempty"`
+	ExternalEndpoint       string `protobuf:"bytes,4,opt,name=external_endpoint,json=externalEndpoint" json:"external_endpoint,omitempty"`
+}
+
+func (m *GossipConfigUpdate) Reset()                    { *m = GossipConfigUpdate{} }
+func (m *GossipConfigUpdate) String() string            { return proto.CompactTextString(m) }
+func (*GossipConfigUpdate) ProtoMessage()               {}
+func (*GossipConfigUpdate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *GossipConfigUpdate) GetAddBootstrapPeers() []string {
+	if m != nil {
+		return m.AddBootstrapPeers
+	}
+	return nil
+}
+
+func (m *GossipConfigUpdate) GetRemoveBootstrapPeers() []string {
+	if m != nil {
+		return m.RemoveBootstrapPeers
+	}
+	return nil
+}
+
+func (m *GossipConfigUpdate) GetUpdateExternalEndpoint() bool {
+	if m != nil {
+		return m.UpdateExternalEndpoint
+	}
+	return false
+}
+
+func (m *GossipConfigUpdate) GetExternalEndpoint() string {
+	if m != nil {
+		return m.ExternalEndpoint
+	}
+	return ""
+}
+
+// GossipConfig is the configuration of the gossip component of the peer
+// that can be changed at runtime
+type GossipConfig struct {
+	BootstrapPeers   []string `protobuf:"bytes,1,rep,name=bootstrap_peers,json=bootstrapPeers" json:"bootstrap_peers,omitempty"`
+	ExternalEndpoint string   `protobuf:"bytes,2,opt,name=external_endpoint,json=externalEndpoint" json:"external_endpoint,omitempty"`
+}
+
+func (m *GossipConfig) Reset()                    { *m = GossipConfig{} }
+func (m *GossipConfig) String() string            { return proto.CompactTextString(m) }
+func (*GossipConfig) ProtoMessage()               {}
+func (*GossipConfig) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *GossipConfig) GetBootstrapPeers() []string {
+	if m != nil {
+		return m.BootstrapPeers
+	}
+	return nil
+}
+
+func (m *GossipConfig) GetExternalEndpoint() string {
+	if m != nil {
+		return m.ExternalEndpoint
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*ServerStatus)(nil), "protos.ServerStatus")
 	proto.RegisterType((*LogLevelRequest)(nil), "protos.LogLevelRequest")
 	proto.RegisterType((*LogLevelResponse)(nil), "protos.LogLevelResponse")
+	proto.RegisterType((*ChaincodeRuntime)(nil), "protos.ChaincodeRuntime")
+	proto.RegisterType((*ChaincodeRuntimes)(nil), "protos.ChaincodeRuntimes")
+	proto.RegisterType((*GossipMember)(nil), "protos.GossipMember")
+	proto.RegisterType((*GossipChannelStatus)(nil), "protos.GossipChannelStatus")
+	proto.RegisterType((*GossipIdentity)(nil), "protos.GossipIdentity")
+	proto.RegisterType((*GossipStatus)(nil), "protos.GossipStatus")
+	proto.RegisterType((*GossipConfigUpdate)(nil), "protos.GossipConfigUpdate")
+	proto.RegisterType((*GossipConfig)(nil), "protos.GossipConfig")
 	proto.RegisterEnum("protos.ServerStatus_StatusCode", ServerStatus_StatusCode_name, ServerStatus_StatusCode_value)
+	proto.RegisterEnum("protos.ChaincodeRuntime_State", ChaincodeRuntime_State_name, ChaincodeRuntime_State_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -213,6 +619,16 @@ type AdminClient interface {
 	GetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 	SetModuleLogLevel(ctx context.Context, in *LogLevelRequest, opts ...grpc.CallOption) (*LogLevelResponse, error)
 	RevertLogLevels(ctx context.Context, in *google_protobuf.Empty, opts ...grpc.CallOption) (*google_protobuf.Empty, error)
+	// Return the state of the chaincode runtimes launched by the peer.
+	// The proposal must be signed by an admin of the local MSP of the peer.
+	GetChaincodeRuntimes(ctx context.Context, in *SignedProposal, opts ...grpc.CallOption) (*ChaincodeRuntimes, error)
+	// Return the membership and channel state of the gossip component.
+	// The proposal must be signed by an admin of the local MSP of the peer.
+	GetGossipStatus(ctx context.Context, in *SignedProposal, opts ...grpc.CallOption) (*GossipStatus, error)
+	// Reconfigure the bootstrap peers and the external endpoint of the gossip component,
+	// and return the resulting configuration. The payload of the proposal is a GossipConfigUpdate,
+	// and the proposal must be signed by an admin of the local MSP of the peer.
+	UpdateGossipConfig(ctx context.Context, in *SignedProposal, opts ...grpc.CallOption) (*GossipConfig, error)
 }
 
 type adminClient struct {
@@ -268,6 +684,33 @@ func (c *adminClient) RevertLogLevels(ctx context.Context, in *google_protobuf.E
 	return out, nil
 }
 
+func (c *adminClient) GetChaincodeRuntimes(ctx context.Context, in *SignedProposal, opts ...grpc.CallOption) (*ChaincodeRuntimes, error) {
+	out := new(ChaincodeRuntimes)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetChaincodeRuntimes", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetGossipStatus(ctx context.Context, in *SignedProposal, opts ...grpc.CallOption) (*GossipStatus, error) {
+	out := new(GossipStatus)
+	err := grpc.Invoke(ctx, "/protos.Admin/GetGossipStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) UpdateGossipConfig(ctx context.Context, in *SignedProposal, opts ...grpc.CallOption) (*GossipConfig, error) {
+	out := new(GossipConfig)
+	err := grpc.Invoke(ctx, "/protos.Admin/UpdateGossipConfig", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Admin service
 
 type AdminServer interface {
@@ -277,6 +720,16 @@ type AdminServer interface {
 	GetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	SetModuleLogLevel(context.Context, *LogLevelRequest) (*LogLevelResponse, error)
 	RevertLogLevels(context.Context, *google_protobuf.Empty) (*google_protobuf.Empty, error)
+	// Return the state of the chaincode runtimes launched by the peer.
+	// The proposal must be signed by an admin of the local MSP of the peer.
+	GetChaincodeRuntimes(context.Context, *SignedProposal) (*ChaincodeRuntimes, error)
+	// Return the membership and channel state of the gossip component.
+	// The proposal must be signed by an admin of the local MSP of the peer.
+	GetGossipStatus(context.Context, *SignedProposal) (*GossipStatus, error)
+	// Reconfigure the bootstrap peers and the external endpoint of the gossip component,
+	// and return the resulting configuration. The payload of the proposal is a GossipConfigUpdate,
+	// and the proposal must be signed by an admin of the local MSP of the peer.
+	UpdateGossipConfig(context.Context, *SignedProposal) (*GossipConfig, error)
 }
 
 func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
@@ -373,6 +826,60 @@ func _Admin_RevertLogLevels_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Admin_GetChaincodeRuntimes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignedProposal)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetChaincodeRuntimes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetChaincodeRuntimes",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetChaincodeRuntimes(ctx, req.(*SignedProposal))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetGossipStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignedProposal)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetGossipStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/GetGossipStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetGossipStatus(ctx, req.(*SignedProposal))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_UpdateGossipConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SignedProposal)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).UpdateGossipConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/protos.Admin/UpdateGossipConfig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).UpdateGossipConfig(ctx, req.(*SignedProposal))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Admin_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "protos.Admin",
 	HandlerType: (*AdminServer)(nil),
@@ -397,6 +904,18 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RevertLogLevels",
 			Handler:    _Admin_RevertLogLevels_Handler,
 		},
+		{
+			MethodName: "GetChaincodeRuntimes",
+			Handler:    _Admin_GetChaincodeRuntimes_Handler,
+		},
+		{
+			MethodName: "GetGossipStatus",
+			Handler:    _Admin_GetGossipStatus_Handler,
+		},
+		{
+			MethodName: "UpdateGossipConfig",
+			Handler:    _Admin_UpdateGossipConfig_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "peer/admin.proto",
@@ -405,32 +924,76 @@ var _Admin_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/admin.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 418 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xac, 0x93, 0x41, 0x6f, 0xd3, 0x30,
-	0x14, 0xc7, 0x9b, 0x42, 0x0b, 0x79, 0x1b, 0xcc, 0x58, 0x08, 0xaa, 0x4e, 0x08, 0x94, 0x13, 0x5c,
-	0x1c, 0x69, 0x1c, 0x38, 0x20, 0x0e, 0xdd, 0x12, 0x06, 0x62, 0x4b, 0x23, 0x67, 0x15, 0x02, 0x09,
-	0x4d, 0x49, 0xf3, 0xe6, 0x55, 0x38, 0x73, 0xb0, 0x9d, 0x4a, 0xfb, 0x3a, 0x7c, 0x2e, 0x3e, 0x0c,
-	0x4a, 0xdc, 0x68, 0x13, 0xb0, 0x03, 0x82, 0x93, 0xf3, 0xde, 0xfb, 0xff, 0xff, 0x71, 0x7e, 0xb1,
-	0x81, 0xd4, 0x88, 0x3a, 0xcc, 0xcb, 0x6a, 0x75, 0xc1, 0x6a, 0xad, 0xac, 0xa2, 0xe3, 0x6e, 0x31,
-	0xd3, 0x5d, 0xa1, 0x94, 0x90, 0x18, 0x76, 0x65, 0xd1, 0x9c, 0x85, 0x58, 0xd5, 0xf6, 0xd2, 0x89,
-	0x82, 0xef, 0x1e, 0x6c, 0x67, 0xa8, 0xd7, 0xa8, 0x33, 0x9b, 0xdb, 0xc6, 0xd0, 0x57, 0x30, 0x36,
-	0xdd, 0xd3, 0xc4, 0x7b, 0xe6, 0x3d, 0xbf, 0xbf, 0xf7, 0xd4, 0x09, 0x0d, 0xbb, 0xae, 0x62, 0x6e,
-	0x39, 0x50, 0x25, 0xf2, 0x8d, 0x3c, 0xf8, 0x04, 0x70, 0xd5, 0xa5, 0xf7, 0xc0, 0x5f, 0x24, 0x51,
-	0xfc, 0xf6, 0x7d, 0x12, 0x47, 0x64, 0x40, 0xb7, 0xe0, 0x4e, 0x76, 0x32, 0xe3, 0x27, 0x71, 0x44,
-	0x3c, 0x57, 0xcc, 0xd3, 0x34, 0x8e, 0xc8, 0x90, 0x02, 0x8c, 0xd3, 0xd9, 0x22, 0x8b, 0x23, 0x72,
-	0x8b, 0xfa, 0x30, 0x8a, 0x39, 0x9f, 0x73, 0x72, 0xbb, 0xd5, 0x2c, 0x92, 0x0f, 0xc9, 0xfc, 0x63,
-	0x42, 0x46, 0xc1, 0x31, 0xec, 0x1c, 0x29, 0x71, 0x84, 0x6b, 0x94, 0x1c, 0xbf, 0x35, 0x68, 0x2c,
-	0x7d, 0x02, 0x20, 0x95, 0x38, 0xad, 0x54, 0xd9, 0x48, 0xec, 0xb6, 0xea, 0x73, 0x5f, 0x2a, 0x71,
-	0xdc, 0x35, 0xe8, 0x2e, 0xb4, 0xc5, 0xa9, 0x6c, 0x2d, 0x93, 0x61, 0x37, 0xbd, 0x2b, 0x37, 0x11,
-	0x41, 0x02, 0xe4, 0x2a, 0xce, 0xd4, 0xea, 0xc2, 0xe0, 0xbf, 0xe4, 0xed, 0xfd, 0x18, 0xc2, 0x68,
-	0xd6, 0x82, 0xa7, 0xaf, 0xc1, 0x3f, 0x44, 0xbb, 0x21, 0xf9, 0x88, 0x39, 0xf0, 0xac, 0x07, 0xcf,
-	0xe2, 0x16, 0xfc, 0xf4, 0xe1, 0x9f, 0x88, 0x06, 0x03, 0xfa, 0x06, 0xb6, 0x32, 0x9b, 0x6b, 0xeb,
-	0xda, 0x7f, 0x6d, 0x7f, 0x07, 0x0f, 0x0e, 0xd1, 0xba, 0xfd, 0xf6, 0x9f, 0x47, 0x1f, 0xf7, 0xe2,
-	0x5f, 0xf8, 0x4d, 0x27, 0xbf, 0x0f, 0x1c, 0x09, 0x97, 0x94, 0xfd, 0x9f, 0xa4, 0x03, 0xd8, 0xe1,
-	0xb8, 0x46, 0x6d, 0xfb, 0xd9, 0xcd, 0x54, 0x6e, 0xe8, 0x07, 0x83, 0xfd, 0x2f, 0x10, 0x28, 0x2d,
-	0xd8, 0xf9, 0x65, 0x8d, 0x5a, 0x62, 0x29, 0x50, 0xb3, 0xb3, 0xbc, 0xd0, 0xab, 0x65, 0xff, 0xe2,
-	0xf6, 0xe4, 0xef, 0x6f, 0x77, 0x7f, 0x20, 0xcd, 0x97, 0x5f, 0x73, 0x81, 0x9f, 0x5f, 0x88, 0x95,
-	0x3d, 0x6f, 0x0a, 0xb6, 0x54, 0x55, 0x78, 0xcd, 0x18, 0x3a, 0xa3, 0xbb, 0x0a, 0x26, 0x6c, 0x8d,
-	0x85, 0xbb, 0x26, 0x2f, 0x7f, 0x06, 0x00, 0x00, 0xff, 0xff, 0x6e, 0xe6, 0xef, 0xb7, 0x41, 0x03,
-	0x00, 0x00,
+	// 1129 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xe1, 0x6e, 0x1a, 0x47,
+	0x10, 0x36, 0x18, 0x08, 0x8c, 0x71, 0x7c, 0x6c, 0x1c, 0xf7, 0x42, 0x94, 0xc6, 0xba, 0x4a, 0xad,
+	0xdb, 0x48, 0xa0, 0xba, 0x51, 0x93, 0xaa, 0xea, 0x0f, 0x02, 0x14, 0xd3, 0xc4, 0x18, 0x1d, 0x46,
+	0x55, 0x2b, 0x55, 0xa7, 0x83, 0x1b, 0x8e, 0x93, 0x8f, 0xdb, 0xeb, 0xee, 0x82, 0xe2, 0x3c, 0x40,
+	0x1f, 0xa4, 0x8f, 0xd0, 0x3f, 0xfd, 0xd7, 0x67, 0x69, 0xdf, 0xa4, 0xda, 0xdd, 0x3b, 0x0c, 0xd8,
+	0x48, 0x4d, 0xdb, 0x5f, 0x77, 0x33, 0xf3, 0x7d, 0x73, 0x3b, 0xdf, 0xec, 0xee, 0x1c, 0x18, 0x31,
+	0x22, 0xab, 0xbb, 0xde, 0x2c, 0x88, 0x6a, 0x31, 0xa3, 0x82, 0x92, 0x82, 0x7a, 0xf0, 0xea, 0x63,
+	0x9f, 0x52, 0x3f, 0xc4, 0xba, 0x32, 0x47, 0xf3, 0x49, 0x1d, 0x67, 0xb1, 0xb8, 0xd6, 0xa0, 0xea,
+	0xd3, 0xcd, 0xa0, 0x08, 0x66, 0xc8, 0x85, 0x3b, 0x8b, 0x13, 0xc0, 0x03, 0x95, 0x37, 0x66, 0x34,
+	0xa6, 0xdc, 0x0d, 0xb5, 0xd3, 0xfa, 0x35, 0x03, 0xe5, 0x01, 0xb2, 0x05, 0xb2, 0x81, 0x70, 0xc5,
+	0x9c, 0x93, 0x17, 0x50, 0xe0, 0xea, 0xcd, 0xcc, 0x1c, 0x67, 0x4e, 0xee, 0x9f, 0x3e, 0xd5, 0x40,
+	0x5e, 0x5b, 0x45, 0xd5, 0xf4, 0xa3, 0x49, 0x3d, 0xb4, 0x13, 0xb8, 0xf5, 0x03, 0xc0, 0x8d, 0x97,
+	0xec, 0x43, 0x69, 0xd8, 0x6b, 0xb5, 0xbf, 0xed, 0xf6, 0xda, 0x2d, 0x63, 0x87, 0xec, 0xc1, 0xbd,
+	0xc1, 0x65, 0xc3, 0xbe, 0x6c, 0xb7, 0x8c, 0x8c, 0x36, 0x2e, 0xfa, 0xfd, 0x76, 0xcb, 0xc8, 0x12,
+	0x80, 0x42, 0xbf, 0x31, 0x1c, 0xb4, 0x5b, 0xc6, 0x2e, 0x29, 0x41, 0xbe, 0x6d, 0xdb, 0x17, 0xb6,
+	0x91, 0x93, 0x98, 0x61, 0xef, 0x75, 0xef, 0xe2, 0xfb, 0x9e, 0x91, 0xb7, 0xce, 0xe1, 0xe0, 0x0d,
+	0xf5, 0xdf, 0xe0, 0x02, 0x43, 0x1b, 0x7f, 0x9e, 0x23, 0x17, 0xe4, 0x09, 0x40, 0x48, 0x7d, 0x67,
+	0x46, 0xbd, 0x79, 0x88, 0x6a, 0xa9, 0x25, 0xbb, 0x14, 0x52, 0xff, 0x5c, 0x39, 0xc8, 0x63, 0x90,
+	0x86, 0x13, 0x4a, 0x8a, 0x99, 0x55, 0xd1, 0x62, 0x98, 0xa4, 0xb0, 0x7a, 0x60, 0xdc, 0xa4, 0xe3,
+	0x31, 0x8d, 0x38, 0xfe, 0xa7, 0x7c, 0xbf, 0x64, 0xc1, 0x68, 0x4e, 0xdd, 0x20, 0x1a, 0x4b, 0x3d,
+	0xe6, 0x91, 0x14, 0x9e, 0x10, 0xc8, 0x45, 0xee, 0x2c, 0x4d, 0xa5, 0xde, 0x89, 0x09, 0xf7, 0x16,
+	0xc8, 0x78, 0x40, 0xa3, 0x24, 0x47, 0x6a, 0x92, 0xe7, 0x90, 0x97, 0x32, 0xa2, 0xb9, 0xab, 0x44,
+	0xff, 0x30, 0x15, 0x7d, 0x33, 0xad, 0x12, 0x1e, 0x6d, 0x0d, 0x26, 0x1f, 0xc1, 0x3e, 0x93, 0x2d,
+	0x66, 0xc2, 0x19, 0xd3, 0x79, 0x24, 0xcc, 0xdc, 0x71, 0xe6, 0x64, 0xdf, 0x2e, 0x27, 0xce, 0xa6,
+	0xf4, 0xa9, 0xca, 0x5c, 0x2e, 0x1c, 0x64, 0x8c, 0x32, 0x33, 0x9f, 0x54, 0xe6, 0x72, 0xd1, 0x96,
+	0x0e, 0xeb, 0x3b, 0xc8, 0xab, 0x9c, 0xab, 0x8a, 0xef, 0xc8, 0xf6, 0xbd, 0x69, 0x0c, 0x7b, 0xcd,
+	0xb3, 0x6e, 0xaf, 0xa3, 0x3b, 0x66, 0x0f, 0x7b, 0x3d, 0x69, 0x64, 0xa5, 0xd1, 0xb4, 0x1b, 0x83,
+	0x33, 0xd5, 0xb2, 0x95, 0x5e, 0xe6, 0xac, 0x2e, 0x54, 0x36, 0x17, 0xcc, 0xc9, 0x73, 0x28, 0xb2,
+	0xe4, 0xdd, 0xcc, 0x1c, 0xef, 0x9e, 0xec, 0x9d, 0x9a, 0xdb, 0xaa, 0xb3, 0x97, 0x48, 0xeb, 0xcf,
+	0x0c, 0x94, 0x3b, 0x94, 0xf3, 0x20, 0x3e, 0xc7, 0xd9, 0x08, 0x19, 0xa9, 0x42, 0x11, 0x23, 0x2f,
+	0xa6, 0x41, 0x24, 0x12, 0x4d, 0x97, 0x36, 0x79, 0x06, 0x95, 0x20, 0x12, 0xc8, 0x22, 0x37, 0x74,
+	0x96, 0x20, 0xad, 0xb0, 0x91, 0x06, 0xda, 0x29, 0xf8, 0x21, 0x14, 0xe2, 0xab, 0xc0, 0x09, 0x3c,
+	0xa5, 0x75, 0xd9, 0xce, 0xc7, 0x57, 0x41, 0xd7, 0x23, 0x06, 0xec, 0x52, 0xe6, 0x2b, 0x05, 0x4b,
+	0xb6, 0x7c, 0x95, 0xea, 0x86, 0xe8, 0xf9, 0xc8, 0x9c, 0x29, 0x06, 0xfe, 0x54, 0x28, 0xed, 0x72,
+	0x76, 0x59, 0x3b, 0xcf, 0x94, 0x8f, 0x7c, 0x95, 0xa8, 0xeb, 0x86, 0xc1, 0x02, 0xcd, 0xc2, 0x71,
+	0xe6, 0x64, 0xef, 0xb4, 0x5a, 0xd3, 0x47, 0xb1, 0x96, 0x1e, 0xc5, 0xda, 0x65, 0x7a, 0x14, 0xb5,
+	0xf2, 0x0d, 0x09, 0xb6, 0xfe, 0xc8, 0xc2, 0x03, 0x5d, 0x62, 0x73, 0xea, 0x46, 0x11, 0x86, 0xc9,
+	0x09, 0x7c, 0x02, 0x30, 0xd6, 0x0e, 0xb9, 0xc8, 0x64, 0x2b, 0x26, 0x9e, 0xae, 0x47, 0x3e, 0x83,
+	0xbc, 0x3c, 0xc8, 0xdc, 0xcc, 0x2a, 0x31, 0x0f, 0x53, 0x31, 0x57, 0xd5, 0xb2, 0x35, 0x84, 0x1c,
+	0x41, 0x21, 0x44, 0xd7, 0x43, 0x96, 0xd4, 0x9a, 0x58, 0xa4, 0x0e, 0x87, 0xa3, 0x90, 0x8e, 0xaf,
+	0x9c, 0x19, 0xf7, 0x1d, 0x2e, 0x28, 0x43, 0x87, 0x07, 0xef, 0x50, 0x55, 0x9f, 0xb7, 0x2b, 0x2a,
+	0x76, 0xce, 0xfd, 0x81, 0x8c, 0x0c, 0x82, 0x77, 0x48, 0x3e, 0x86, 0x03, 0x4d, 0x88, 0xe7, 0x61,
+	0xa8, 0xb1, 0x79, 0x85, 0xdd, 0x57, 0xee, 0xfe, 0x3c, 0x0c, 0x15, 0xee, 0x25, 0x3c, 0x52, 0x5b,
+	0xd3, 0x09, 0xa2, 0x09, 0xdd, 0xcc, 0x5e, 0x50, 0x8c, 0x87, 0x0a, 0xd0, 0x8d, 0x26, 0x74, 0xed,
+	0x0b, 0x9f, 0xc3, 0x43, 0xbd, 0xb8, 0x4d, 0xd6, 0x3d, 0xc5, 0x22, 0x3a, 0xb8, 0x4a, 0xb1, 0x86,
+	0x70, 0x5f, 0x17, 0xdd, 0xf5, 0x30, 0x12, 0x81, 0xb8, 0x5e, 0xe9, 0x6d, 0xe6, 0x8e, 0xde, 0x66,
+	0x6f, 0x7a, 0x5b, 0x85, 0x62, 0x90, 0x90, 0x12, 0x69, 0x96, 0xb6, 0xf5, 0x7b, 0x36, 0xdd, 0x7a,
+	0x49, 0x43, 0x4e, 0x20, 0xc7, 0x31, 0x9c, 0xa8, 0x9c, 0xdb, 0x04, 0x57, 0x88, 0xf7, 0xea, 0xcd,
+	0x0b, 0x28, 0x26, 0x4d, 0xe5, 0xe6, 0xae, 0x82, 0x3f, 0x5e, 0x87, 0xaf, 0xed, 0x0a, 0x7b, 0x09,
+	0x26, 0x5f, 0x02, 0x24, 0x6b, 0x0d, 0x90, 0x9b, 0x39, 0x45, 0x3d, 0x5a, 0xa7, 0xa6, 0x82, 0xd8,
+	0x2b, 0x48, 0xd9, 0xc3, 0x31, 0x32, 0xb1, 0xaa, 0x6d, 0xd2, 0x43, 0xe9, 0xbe, 0xe9, 0xc4, 0xbf,
+	0xee, 0xa1, 0xf5, 0x57, 0x06, 0x48, 0xb2, 0x76, 0x1a, 0x4d, 0x02, 0x7f, 0x18, 0x7b, 0xf2, 0x66,
+	0xa9, 0xc1, 0x03, 0xd7, 0xf3, 0x9c, 0x11, 0xa5, 0x82, 0x0b, 0xe6, 0xc6, 0x8e, 0xd6, 0x48, 0x5e,
+	0x06, 0x25, 0xbb, 0xe2, 0x7a, 0xde, 0xab, 0x34, 0xd2, 0x57, 0xca, 0x3c, 0x87, 0x23, 0x86, 0x33,
+	0xba, 0xc0, 0x5b, 0x94, 0xac, 0xa2, 0x1c, 0xea, 0xe8, 0x06, 0xeb, 0x25, 0x98, 0x73, 0xf5, 0x3d,
+	0x07, 0xdf, 0x6e, 0xde, 0x05, 0xb2, 0xc5, 0x45, 0xfb, 0x48, 0xc7, 0xdb, 0x6f, 0x37, 0x6e, 0x84,
+	0x67, 0x50, 0xb9, 0x4d, 0xd1, 0x17, 0x81, 0x81, 0x1b, 0x60, 0xcb, 0x83, 0xf2, 0x6a, 0x89, 0xe4,
+	0x13, 0x38, 0xb8, 0xbb, 0xb0, 0xfb, 0xa3, 0xf5, 0xf5, 0xdd, 0xf9, 0x95, 0xec, 0xdd, 0x5f, 0x39,
+	0xfd, 0x2d, 0x07, 0xf9, 0x86, 0xfc, 0x03, 0x20, 0x5f, 0x43, 0xa9, 0x83, 0x22, 0xd9, 0x89, 0x47,
+	0xb7, 0x6e, 0x96, 0xb6, 0xfc, 0x03, 0xa8, 0x1e, 0xde, 0x35, 0xa4, 0xad, 0x1d, 0xf2, 0x0d, 0xec,
+	0x0d, 0xe4, 0x24, 0xd0, 0xee, 0xf7, 0xa6, 0x9f, 0x41, 0xa5, 0x83, 0x42, 0x8f, 0xc0, 0x74, 0x62,
+	0x92, 0x0f, 0x52, 0xf0, 0xc6, 0x48, 0xae, 0x9a, 0xb7, 0x03, 0x7a, 0xb8, 0xea, 0x4c, 0x83, 0xff,
+	0x27, 0x53, 0x13, 0x0e, 0x6c, 0x5c, 0x20, 0x13, 0x69, 0x6c, 0xbb, 0x2a, 0x5b, 0xfc, 0xd6, 0x0e,
+	0x79, 0x0d, 0x87, 0x1d, 0x14, 0xb7, 0x67, 0xd5, 0xf2, 0x18, 0x0d, 0x02, 0x3f, 0x42, 0xaf, 0x9f,
+	0xfc, 0x2b, 0x55, 0x1f, 0x6d, 0x9b, 0x58, 0x52, 0xa5, 0x06, 0x1c, 0x74, 0x50, 0xac, 0xdd, 0x18,
+	0xdb, 0xf2, 0x6c, 0x5c, 0x08, 0x4b, 0xa1, 0x5b, 0x40, 0xf4, 0x59, 0x59, 0xdb, 0x5a, 0xff, 0x30,
+	0x8b, 0x46, 0x5b, 0x3b, 0xaf, 0x7e, 0x02, 0x8b, 0x32, 0xbf, 0x36, 0xbd, 0x8e, 0x91, 0xe9, 0x21,
+	0x55, 0x9b, 0xb8, 0x23, 0x16, 0x8c, 0x53, 0xbc, 0xdc, 0x9e, 0xaf, 0xca, 0x6a, 0x5f, 0xf5, 0xdd,
+	0xf1, 0x95, 0xeb, 0xe3, 0x8f, 0x9f, 0xfa, 0x81, 0x98, 0xce, 0x47, 0xb5, 0x31, 0x9d, 0xd5, 0x57,
+	0x88, 0x75, 0x4d, 0xd4, 0x3f, 0x93, 0xbc, 0x2e, 0x89, 0x23, 0xfd, 0x17, 0xfa, 0xc5, 0xdf, 0x03,
+	0x00, 0xf2, 0xf3, 0x83, 0xa2, 0xa0, 0x0a, 0x00, 0x00,
 }
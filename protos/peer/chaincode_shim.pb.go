@@ -7,6 +7,7 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 import google_protobuf1 "github.com/golang/protobuf/ptypes/timestamp"
+import queryresult "github.com/hyperledger/fabric/protos/ledger/queryresult"
 
 import (
 	context "golang.org/x/net/context"
@@ -21,25 +22,26 @@ var _ = math.Inf
 type ChaincodeMessage_Type int32
 
 const (
-	ChaincodeMessage_UNDEFINED           ChaincodeMessage_Type = 0
-	ChaincodeMessage_REGISTER            ChaincodeMessage_Type = 1
-	ChaincodeMessage_REGISTERED          ChaincodeMessage_Type = 2
-	ChaincodeMessage_INIT                ChaincodeMessage_Type = 3
-	ChaincodeMessage_READY               ChaincodeMessage_Type = 4
-	ChaincodeMessage_TRANSACTION         ChaincodeMessage_Type = 5
-	ChaincodeMessage_COMPLETED           ChaincodeMessage_Type = 6
-	ChaincodeMessage_ERROR               ChaincodeMessage_Type = 7
-	ChaincodeMessage_GET_STATE           ChaincodeMessage_Type = 8
-	ChaincodeMessage_PUT_STATE           ChaincodeMessage_Type = 9
-	ChaincodeMessage_DEL_STATE           ChaincodeMessage_Type = 10
-	ChaincodeMessage_INVOKE_CHAINCODE    ChaincodeMessage_Type = 11
-	ChaincodeMessage_RESPONSE            ChaincodeMessage_Type = 13
-	ChaincodeMessage_GET_STATE_BY_RANGE  ChaincodeMessage_Type = 14
-	ChaincodeMessage_GET_QUERY_RESULT    ChaincodeMessage_Type = 15
-	ChaincodeMessage_QUERY_STATE_NEXT    ChaincodeMessage_Type = 16
-	ChaincodeMessage_QUERY_STATE_CLOSE   ChaincodeMessage_Type = 17
-	ChaincodeMessage_KEEPALIVE           ChaincodeMessage_Type = 18
-	ChaincodeMessage_GET_HISTORY_FOR_KEY ChaincodeMessage_Type = 19
+	ChaincodeMessage_UNDEFINED                 ChaincodeMessage_Type = 0
+	ChaincodeMessage_REGISTER                  ChaincodeMessage_Type = 1
+	ChaincodeMessage_REGISTERED                ChaincodeMessage_Type = 2
+	ChaincodeMessage_INIT                      ChaincodeMessage_Type = 3
+	ChaincodeMessage_READY                     ChaincodeMessage_Type = 4
+	ChaincodeMessage_TRANSACTION               ChaincodeMessage_Type = 5
+	ChaincodeMessage_COMPLETED                 ChaincodeMessage_Type = 6
+	ChaincodeMessage_ERROR                     ChaincodeMessage_Type = 7
+	ChaincodeMessage_GET_STATE                 ChaincodeMessage_Type = 8
+	ChaincodeMessage_PUT_STATE                 ChaincodeMessage_Type = 9
+	ChaincodeMessage_DEL_STATE                 ChaincodeMessage_Type = 10
+	ChaincodeMessage_INVOKE_CHAINCODE          ChaincodeMessage_Type = 11
+	ChaincodeMessage_RESPONSE                  ChaincodeMessage_Type = 13
+	ChaincodeMessage_GET_STATE_BY_RANGE        ChaincodeMessage_Type = 14
+	ChaincodeMessage_GET_QUERY_RESULT          ChaincodeMessage_Type = 15
+	ChaincodeMessage_QUERY_STATE_NEXT          ChaincodeMessage_Type = 16
+	ChaincodeMessage_QUERY_STATE_CLOSE         ChaincodeMessage_Type = 17
+	ChaincodeMessage_KEEPALIVE                 ChaincodeMessage_Type = 18
+	ChaincodeMessage_GET_HISTORY_FOR_KEY       ChaincodeMessage_Type = 19
+	ChaincodeMessage_GET_HISTORY_FOR_KEY_RANGE ChaincodeMessage_Type = 20
 )
 
 var ChaincodeMessage_Type_name = map[int32]string{
@@ -62,27 +64,29 @@ var ChaincodeMessage_Type_name = map[int32]string{
 	17: "QUERY_STATE_CLOSE",
 	18: "KEEPALIVE",
 	19: "GET_HISTORY_FOR_KEY",
+	20: "GET_HISTORY_FOR_KEY_RANGE",
 }
 var ChaincodeMessage_Type_value = map[string]int32{
-	"UNDEFINED":           0,
-	"REGISTER":            1,
-	"REGISTERED":          2,
-	"INIT":                3,
-	"READY":               4,
-	"TRANSACTION":         5,
-	"COMPLETED":           6,
-	"ERROR":               7,
-	"GET_STATE":           8,
-	"PUT_STATE":           9,
-	"DEL_STATE":           10,
-	"INVOKE_CHAINCODE":    11,
-	"RESPONSE":            13,
-	"GET_STATE_BY_RANGE":  14,
-	"GET_QUERY_RESULT":    15,
-	"QUERY_STATE_NEXT":    16,
-	"QUERY_STATE_CLOSE":   17,
-	"KEEPALIVE":           18,
-	"GET_HISTORY_FOR_KEY": 19,
+	"UNDEFINED":                 0,
+	"REGISTER":                  1,
+	"REGISTERED":                2,
+	"INIT":                      3,
+	"READY":                     4,
+	"TRANSACTION":               5,
+	"COMPLETED":                 6,
+	"ERROR":                     7,
+	"GET_STATE":                 8,
+	"PUT_STATE":                 9,
+	"DEL_STATE":                 10,
+	"INVOKE_CHAINCODE":          11,
+	"RESPONSE":                  13,
+	"GET_STATE_BY_RANGE":        14,
+	"GET_QUERY_RESULT":          15,
+	"QUERY_STATE_NEXT":          16,
+	"QUERY_STATE_CLOSE":         17,
+	"KEEPALIVE":                 18,
+	"GET_HISTORY_FOR_KEY":       19,
+	"GET_HISTORY_FOR_KEY_RANGE": 20,
 }
 
 func (x ChaincodeMessage_Type) String() string {
@@ -214,7 +218,8 @@ func (m *GetQueryResult) GetQuery() string {
 }
 
 type GetHistoryForKey struct {
-	Key string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Key     string                           `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Options *queryresult.HistoryQueryOptions `protobuf:"bytes,2,opt,name=options" json:"options,omitempty"`
 }
 
 func (m *GetHistoryForKey) Reset()                    { *m = GetHistoryForKey{} }
@@ -229,6 +234,45 @@ func (m *GetHistoryForKey) GetKey() string {
 	return ""
 }
 
+func (m *GetHistoryForKey) GetOptions() *queryresult.HistoryQueryOptions {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
+type GetHistoryForKeyRange struct {
+	StartKey string                           `protobuf:"bytes,1,opt,name=startKey" json:"startKey,omitempty"`
+	EndKey   string                           `protobuf:"bytes,2,opt,name=endKey" json:"endKey,omitempty"`
+	Options  *queryresult.HistoryQueryOptions `protobuf:"bytes,3,opt,name=options" json:"options,omitempty"`
+}
+
+func (m *GetHistoryForKeyRange) Reset()                    { *m = GetHistoryForKeyRange{} }
+func (m *GetHistoryForKeyRange) String() string            { return proto.CompactTextString(m) }
+func (*GetHistoryForKeyRange) ProtoMessage()               {}
+func (*GetHistoryForKeyRange) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+
+func (m *GetHistoryForKeyRange) GetStartKey() string {
+	if m != nil {
+		return m.StartKey
+	}
+	return ""
+}
+
+func (m *GetHistoryForKeyRange) GetEndKey() string {
+	if m != nil {
+		return m.EndKey
+	}
+	return ""
+}
+
+func (m *GetHistoryForKeyRange) GetOptions() *queryresult.HistoryQueryOptions {
+	if m != nil {
+		return m.Options
+	}
+	return nil
+}
+
 type QueryStateNext struct {
 	Id string `protobuf:"bytes,1,opt,name=id" json:"id,omitempty"`
 }
@@ -236,7 +280,7 @@ type QueryStateNext struct {
 func (m *QueryStateNext) Reset()                    { *m = QueryStateNext{} }
 func (m *QueryStateNext) String() string            { return proto.CompactTextString(m) }
 func (*QueryStateNext) ProtoMessage()               {}
-func (*QueryStateNext) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{5} }
+func (*QueryStateNext) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
 
 func (m *QueryStateNext) GetId() string {
 	if m != nil {
@@ -252,7 +296,7 @@ type QueryStateClose struct {
 func (m *QueryStateClose) Reset()                    { *m = QueryStateClose{} }
 func (m *QueryStateClose) String() string            { return proto.CompactTextString(m) }
 func (*QueryStateClose) ProtoMessage()               {}
-func (*QueryStateClose) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{6} }
+func (*QueryStateClose) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
 
 func (m *QueryStateClose) GetId() string {
 	if m != nil {
@@ -268,7 +312,7 @@ type QueryResultBytes struct {
 func (m *QueryResultBytes) Reset()                    { *m = QueryResultBytes{} }
 func (m *QueryResultBytes) String() string            { return proto.CompactTextString(m) }
 func (*QueryResultBytes) ProtoMessage()               {}
-func (*QueryResultBytes) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{7} }
+func (*QueryResultBytes) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{8} }
 
 func (m *QueryResultBytes) GetResultBytes() []byte {
 	if m != nil {
@@ -286,7 +330,7 @@ type QueryResponse struct {
 func (m *QueryResponse) Reset()                    { *m = QueryResponse{} }
 func (m *QueryResponse) String() string            { return proto.CompactTextString(m) }
 func (*QueryResponse) ProtoMessage()               {}
-func (*QueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{8} }
+func (*QueryResponse) Descriptor() ([]byte, []int) { return fileDescriptor3, []int{9} }
 
 func (m *QueryResponse) GetResults() []*QueryResultBytes {
 	if m != nil {
@@ -315,6 +359,7 @@ func init() {
 	proto.RegisterType((*GetStateByRange)(nil), "protos.GetStateByRange")
 	proto.RegisterType((*GetQueryResult)(nil), "protos.GetQueryResult")
 	proto.RegisterType((*GetHistoryForKey)(nil), "protos.GetHistoryForKey")
+	proto.RegisterType((*GetHistoryForKeyRange)(nil), "protos.GetHistoryForKeyRange")
 	proto.RegisterType((*QueryStateNext)(nil), "protos.QueryStateNext")
 	proto.RegisterType((*QueryStateClose)(nil), "protos.QueryStateClose")
 	proto.RegisterType((*QueryResultBytes)(nil), "protos.QueryResultBytes")
@@ -429,54 +474,58 @@ var _ChaincodeSupport_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("peer/chaincode_shim.proto", fileDescriptor3) }
 
 var fileDescriptor3 = []byte{
-	// 771 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x94, 0x6f, 0x6f, 0xe2, 0x46,
-	0x10, 0xc6, 0x8f, 0x3f, 0x49, 0x60, 0x20, 0xb0, 0xb7, 0xb9, 0xa6, 0x3e, 0xa4, 0xaa, 0xd4, 0xaa,
-	0x2a, 0xfa, 0xc6, 0xb4, 0xb4, 0xaa, 0xfa, 0xae, 0x22, 0xb0, 0x21, 0x56, 0x88, 0xcd, 0xad, 0x9d,
-	0xd3, 0xd1, 0x37, 0x96, 0x03, 0x1b, 0x63, 0x15, 0x58, 0x77, 0x77, 0x39, 0x9d, 0x3f, 0x61, 0xbf,
-	0x4c, 0x3f, 0xc4, 0x69, 0x6d, 0x4c, 0xb8, 0x9c, 0xf2, 0xca, 0x7e, 0x66, 0x7e, 0xf3, 0xcc, 0xec,
-	0x6a, 0x35, 0xf0, 0x36, 0x61, 0x4c, 0xf4, 0x17, 0xab, 0x30, 0xde, 0x2e, 0xf8, 0x92, 0x05, 0x72,
-	0x15, 0x6f, 0xac, 0x44, 0x70, 0xc5, 0xf1, 0x69, 0xf6, 0x91, 0x9d, 0xce, 0x33, 0x84, 0x7d, 0x64,
-	0x5b, 0x95, 0x33, 0x9d, 0x8b, 0x2c, 0x97, 0x08, 0x9e, 0x70, 0x19, 0xae, 0xf7, 0xc1, 0xef, 0x23,
-	0xce, 0xa3, 0x35, 0xeb, 0x67, 0xea, 0x61, 0xf7, 0xd8, 0x57, 0xf1, 0x86, 0x49, 0x15, 0x6e, 0x92,
-	0x1c, 0x30, 0xff, 0xaf, 0x02, 0x1a, 0x15, 0x7e, 0x77, 0x4c, 0xca, 0x30, 0x62, 0xf8, 0x57, 0xa8,
-	0xaa, 0x34, 0x61, 0x46, 0xa9, 0x5b, 0xea, 0xb5, 0x06, 0xdf, 0xe5, 0xa8, 0xb4, 0x9e, 0x73, 0x96,
-	0x9f, 0x26, 0x8c, 0x66, 0x28, 0xfe, 0x13, 0xea, 0x07, 0x6b, 0xa3, 0xdc, 0x2d, 0xf5, 0x1a, 0x83,
-	0x8e, 0x95, 0x37, 0xb7, 0x8a, 0xe6, 0x96, 0x5f, 0x10, 0xf4, 0x09, 0xc6, 0x06, 0x9c, 0x25, 0x61,
-	0xba, 0xe6, 0xe1, 0xd2, 0xa8, 0x74, 0x4b, 0xbd, 0x26, 0x2d, 0x24, 0xc6, 0x50, 0x55, 0x9f, 0xe2,
-	0xa5, 0x51, 0xed, 0x96, 0x7a, 0x75, 0x9a, 0xfd, 0xe3, 0x01, 0xd4, 0x8a, 0x23, 0x1a, 0x27, 0x59,
-	0x9b, 0xcb, 0x62, 0x3c, 0x2f, 0x8e, 0xb6, 0x6c, 0x39, 0xdb, 0x67, 0xe9, 0x81, 0xc3, 0x7f, 0x41,
-	0xfb, 0xd9, 0x95, 0x19, 0xa7, 0x5f, 0x96, 0x1e, 0x4e, 0x46, 0x74, 0x96, 0xb6, 0x16, 0x5f, 0x68,
-	0xf3, 0xbf, 0x32, 0x54, 0xf5, 0x59, 0xf1, 0x39, 0xd4, 0xef, 0x9d, 0x31, 0xb9, 0xb6, 0x1d, 0x32,
-	0x46, 0xaf, 0x70, 0x13, 0x6a, 0x94, 0x4c, 0x6c, 0xcf, 0x27, 0x14, 0x95, 0x70, 0x0b, 0xa0, 0x50,
-	0x64, 0x8c, 0xca, 0xb8, 0x06, 0x55, 0xdb, 0xb1, 0x7d, 0x54, 0xc1, 0x75, 0x38, 0xa1, 0x64, 0x38,
-	0x9e, 0xa3, 0x2a, 0x6e, 0x43, 0xc3, 0xa7, 0x43, 0xc7, 0x1b, 0x8e, 0x7c, 0xdb, 0x75, 0xd0, 0x89,
-	0xb6, 0x1c, 0xb9, 0x77, 0xb3, 0x29, 0xf1, 0xc9, 0x18, 0x9d, 0x6a, 0x94, 0x50, 0xea, 0x52, 0x74,
-	0xa6, 0x33, 0x13, 0xe2, 0x07, 0x9e, 0x3f, 0xf4, 0x09, 0xaa, 0x69, 0x39, 0xbb, 0x2f, 0x64, 0x5d,
-	0xcb, 0x31, 0x99, 0xee, 0x25, 0xe0, 0x37, 0x80, 0x6c, 0xe7, 0xbd, 0x7b, 0x4b, 0x82, 0xd1, 0xcd,
-	0xd0, 0x76, 0x46, 0xee, 0x98, 0xa0, 0x46, 0x3e, 0xa0, 0x37, 0x73, 0x1d, 0x8f, 0xa0, 0x73, 0x7c,
-	0x09, 0xf8, 0x60, 0x18, 0x5c, 0xcd, 0x03, 0x3a, 0x74, 0x26, 0x04, 0xb5, 0x74, 0xad, 0x8e, 0xbf,
-	0xbb, 0x27, 0x74, 0x1e, 0x50, 0xe2, 0xdd, 0x4f, 0x7d, 0xd4, 0xd6, 0xd1, 0x3c, 0x92, 0xf3, 0x0e,
-	0xf9, 0xe0, 0x23, 0x84, 0xbf, 0x81, 0xd7, 0xc7, 0xd1, 0xd1, 0xd4, 0xf5, 0x08, 0x7a, 0xad, 0xa7,
-	0xb9, 0x25, 0x64, 0x36, 0x9c, 0xda, 0xef, 0x09, 0xc2, 0xf8, 0x5b, 0xb8, 0xd0, 0x8e, 0x37, 0xb6,
-	0xe7, 0xbb, 0x74, 0x1e, 0x5c, 0xbb, 0x34, 0xb8, 0x25, 0x73, 0x74, 0x61, 0xfe, 0x01, 0xcd, 0xd9,
-	0x4e, 0x79, 0x2a, 0x54, 0xcc, 0xde, 0x3e, 0x72, 0x8c, 0xa0, 0xf2, 0x0f, 0x4b, 0xb3, 0x87, 0x56,
-	0xa7, 0xfa, 0x17, 0xbf, 0x81, 0x93, 0x8f, 0xe1, 0x7a, 0xc7, 0xb2, 0x47, 0xd4, 0xa4, 0xb9, 0x30,
-	0x09, 0xb4, 0x27, 0x2c, 0xaf, 0xbb, 0x4a, 0x69, 0xb8, 0x8d, 0x18, 0xee, 0x40, 0x4d, 0xaa, 0x50,
-	0xa8, 0xdb, 0x43, 0xfd, 0x41, 0xe3, 0x4b, 0x38, 0x65, 0xdb, 0xa5, 0xce, 0x94, 0xb3, 0xcc, 0x5e,
-	0x99, 0x3f, 0x41, 0x6b, 0xc2, 0xd4, 0xbb, 0x1d, 0x13, 0x29, 0x65, 0x72, 0xb7, 0x56, 0xba, 0xdd,
-	0xbf, 0x5a, 0xee, 0x2d, 0x72, 0x61, 0xfe, 0x08, 0x68, 0xc2, 0xd4, 0x4d, 0x2c, 0x15, 0x17, 0xe9,
-	0x35, 0x17, 0xda, 0xf3, 0xab, 0x51, 0xcd, 0x2e, 0xb4, 0x32, 0xab, 0x6c, 0x2c, 0x87, 0x7d, 0x52,
-	0xb8, 0x05, 0xe5, 0x78, 0xb9, 0x47, 0xca, 0xf1, 0xd2, 0xfc, 0x01, 0xda, 0x4f, 0xc4, 0x68, 0xcd,
-	0x25, 0xfb, 0x0a, 0xf9, 0x1d, 0xd0, 0xd1, 0x3c, 0x57, 0xa9, 0x62, 0x12, 0x77, 0xa1, 0x21, 0x9e,
-	0x64, 0x06, 0x37, 0xe9, 0x71, 0xc8, 0xdc, 0xc2, 0x79, 0x51, 0x95, 0xf0, 0xad, 0x64, 0x78, 0x00,
-	0x67, 0x79, 0x5e, 0xe3, 0x95, 0x5e, 0x63, 0x60, 0x14, 0x6f, 0xfb, 0xb9, 0x3b, 0x2d, 0x40, 0xfc,
-	0x16, 0x6a, 0xab, 0x50, 0x06, 0x1b, 0x2e, 0xf2, 0xdb, 0xae, 0xd1, 0xb3, 0x55, 0x28, 0xef, 0xb8,
-	0x28, 0xa6, 0xac, 0x14, 0x53, 0x0e, 0x3e, 0x1c, 0x6d, 0x09, 0x6f, 0x97, 0x24, 0x5c, 0x28, 0x3c,
-	0x86, 0x1a, 0x65, 0x51, 0x2c, 0x15, 0x13, 0xd8, 0x78, 0x69, 0x47, 0x74, 0x5e, 0xcc, 0x98, 0xaf,
-	0x7a, 0xa5, 0x5f, 0x4a, 0x57, 0x2e, 0x98, 0x5c, 0x44, 0xd6, 0x2a, 0x4d, 0x98, 0x58, 0xb3, 0x65,
-	0xc4, 0x84, 0xf5, 0x18, 0x3e, 0x88, 0x78, 0x51, 0xd4, 0xe9, 0xb5, 0xf6, 0xf7, 0xcf, 0x51, 0xac,
-	0x56, 0xbb, 0x07, 0x6b, 0xc1, 0x37, 0xfd, 0x23, 0xb4, 0x9f, 0xa3, 0xf9, 0x7a, 0x93, 0x7d, 0x8d,
-	0x3e, 0xe4, 0xbb, 0xf2, 0xb7, 0xcf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xad, 0x9f, 0x80, 0x68, 0x4f,
-	0x05, 0x00, 0x00,
+	// 840 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x95, 0xdf, 0x6e, 0xe2, 0x46,
+	0x14, 0xc6, 0x97, 0x7f, 0x01, 0x0e, 0x04, 0x66, 0x27, 0xd9, 0x94, 0x20, 0xad, 0x4a, 0x7d, 0x51,
+	0xd1, 0x1b, 0xd3, 0xd2, 0xaa, 0xaa, 0x7a, 0x53, 0x11, 0x98, 0x10, 0x2b, 0xc4, 0x66, 0xc7, 0xce,
+	0x6a, 0xe9, 0x8d, 0xeb, 0xc0, 0x04, 0xac, 0x05, 0xc6, 0xb5, 0x87, 0x68, 0xfd, 0x04, 0xbd, 0xe8,
+	0x7b, 0xf6, 0x39, 0xaa, 0xf1, 0xd8, 0x84, 0x65, 0xbb, 0x17, 0xdd, 0x2b, 0xfc, 0x9d, 0xf3, 0x3b,
+	0xe7, 0x7c, 0x1e, 0xf9, 0x0c, 0x70, 0x19, 0x30, 0x16, 0xf6, 0xe6, 0x2b, 0xcf, 0xdf, 0xce, 0xf9,
+	0x82, 0xb9, 0xd1, 0xca, 0xdf, 0xe8, 0x41, 0xc8, 0x05, 0xc7, 0x27, 0xc9, 0x4f, 0xd4, 0x6e, 0x1f,
+	0x21, 0xec, 0x89, 0x6d, 0x85, 0x62, 0xda, 0x67, 0x49, 0x2e, 0x08, 0x79, 0xc0, 0x23, 0x6f, 0x9d,
+	0x06, 0xbf, 0x5e, 0x72, 0xbe, 0x5c, 0xb3, 0x5e, 0xa2, 0x1e, 0x76, 0x8f, 0x3d, 0xe1, 0x6f, 0x58,
+	0x24, 0xbc, 0x4d, 0x90, 0x02, 0xdd, 0x35, 0x5b, 0x2c, 0x59, 0xd8, 0xfb, 0x73, 0xc7, 0xc2, 0x38,
+	0x64, 0xd1, 0x6e, 0x2d, 0x7a, 0xef, 0x9f, 0xdc, 0x44, 0xba, 0x4a, 0x2b, 0x52, 0xfb, 0xbb, 0x04,
+	0x68, 0x98, 0x4d, 0xbe, 0x63, 0x51, 0xe4, 0x2d, 0x19, 0xfe, 0x01, 0x8a, 0x22, 0x0e, 0x58, 0x2b,
+	0xd7, 0xc9, 0x75, 0x1b, 0xfd, 0xd7, 0x0a, 0x8d, 0xf4, 0x63, 0x4e, 0x77, 0xe2, 0x80, 0xd1, 0x04,
+	0xc5, 0xbf, 0x40, 0x75, 0x6f, 0xa2, 0x95, 0xef, 0xe4, 0xba, 0xb5, 0x7e, 0x5b, 0x57, 0x36, 0xf5,
+	0xcc, 0xa6, 0xee, 0x64, 0x04, 0x7d, 0x86, 0x71, 0x0b, 0xca, 0x81, 0x17, 0xaf, 0xb9, 0xb7, 0x68,
+	0x15, 0x3a, 0xb9, 0x6e, 0x9d, 0x66, 0x12, 0x63, 0x28, 0x8a, 0x0f, 0xfe, 0xa2, 0x55, 0xec, 0xe4,
+	0xba, 0x55, 0x9a, 0x3c, 0xe3, 0x3e, 0x54, 0xb2, 0xc3, 0x68, 0x95, 0x92, 0x31, 0x17, 0x99, 0x3d,
+	0xdb, 0x5f, 0x6e, 0xd9, 0x62, 0x9a, 0x66, 0xe9, 0x9e, 0xc3, 0xbf, 0x41, 0xf3, 0xe8, 0x70, 0x5b,
+	0x27, 0x1f, 0x97, 0xee, 0xdf, 0x8c, 0xc8, 0x2c, 0x6d, 0xcc, 0x3f, 0xd2, 0xda, 0x3f, 0x79, 0x28,
+	0xca, 0x77, 0xc5, 0xa7, 0x50, 0xbd, 0x37, 0x47, 0xe4, 0xda, 0x30, 0xc9, 0x08, 0xbd, 0xc0, 0x75,
+	0xa8, 0x50, 0x32, 0x36, 0x6c, 0x87, 0x50, 0x94, 0xc3, 0x0d, 0x80, 0x4c, 0x91, 0x11, 0xca, 0xe3,
+	0x0a, 0x14, 0x0d, 0xd3, 0x70, 0x50, 0x01, 0x57, 0xa1, 0x44, 0xc9, 0x60, 0x34, 0x43, 0x45, 0xdc,
+	0x84, 0x9a, 0x43, 0x07, 0xa6, 0x3d, 0x18, 0x3a, 0x86, 0x65, 0xa2, 0x92, 0x6c, 0x39, 0xb4, 0xee,
+	0xa6, 0x13, 0xe2, 0x90, 0x11, 0x3a, 0x91, 0x28, 0xa1, 0xd4, 0xa2, 0xa8, 0x2c, 0x33, 0x63, 0xe2,
+	0xb8, 0xb6, 0x33, 0x70, 0x08, 0xaa, 0x48, 0x39, 0xbd, 0xcf, 0x64, 0x55, 0xca, 0x11, 0x99, 0xa4,
+	0x12, 0xf0, 0x39, 0x20, 0xc3, 0x7c, 0x6b, 0xdd, 0x12, 0x77, 0x78, 0x33, 0x30, 0xcc, 0xa1, 0x35,
+	0x22, 0xa8, 0xa6, 0x0c, 0xda, 0x53, 0xcb, 0xb4, 0x09, 0x3a, 0xc5, 0x17, 0x80, 0xf7, 0x0d, 0xdd,
+	0xab, 0x99, 0x4b, 0x07, 0xe6, 0x98, 0xa0, 0x86, 0xac, 0x95, 0xf1, 0x37, 0xf7, 0x84, 0xce, 0x5c,
+	0x4a, 0xec, 0xfb, 0x89, 0x83, 0x9a, 0x32, 0xaa, 0x22, 0x8a, 0x37, 0xc9, 0x3b, 0x07, 0x21, 0xfc,
+	0x0a, 0x5e, 0x1e, 0x46, 0x87, 0x13, 0xcb, 0x26, 0xe8, 0xa5, 0x74, 0x73, 0x4b, 0xc8, 0x74, 0x30,
+	0x31, 0xde, 0x12, 0x84, 0xf1, 0x57, 0x70, 0x26, 0x3b, 0xde, 0x18, 0xb6, 0x63, 0xd1, 0x99, 0x7b,
+	0x6d, 0x51, 0xf7, 0x96, 0xcc, 0xd0, 0x19, 0x7e, 0x0d, 0x97, 0xff, 0x91, 0x48, 0x9d, 0x9c, 0x6b,
+	0x3f, 0x43, 0x7d, 0xba, 0x13, 0xb6, 0xf0, 0x04, 0x33, 0xb6, 0x8f, 0x1c, 0x23, 0x28, 0xbc, 0x67,
+	0x71, 0xf2, 0x1d, 0x56, 0xa9, 0x7c, 0xc4, 0xe7, 0x50, 0x7a, 0xf2, 0xd6, 0x3b, 0x96, 0x7c, 0x63,
+	0x75, 0xaa, 0x84, 0x46, 0xa0, 0x39, 0x66, 0xaa, 0xee, 0x2a, 0xa6, 0xde, 0x76, 0xc9, 0x70, 0x1b,
+	0x2a, 0x91, 0xf0, 0x42, 0x71, 0xbb, 0xaf, 0xdf, 0x6b, 0x7c, 0x01, 0x27, 0x6c, 0xbb, 0x90, 0x99,
+	0x7c, 0x92, 0x49, 0x95, 0xf6, 0x2d, 0x34, 0xc6, 0x4c, 0xbc, 0x91, 0x5b, 0x42, 0x93, 0x25, 0x91,
+	0xe3, 0x92, 0xa5, 0x49, 0x5b, 0x28, 0xa1, 0xfd, 0x01, 0x68, 0xcc, 0xc4, 0x8d, 0x1f, 0x09, 0x1e,
+	0xc6, 0xd7, 0x3c, 0x94, 0x3d, 0x3f, 0xb5, 0xfa, 0x2b, 0x94, 0x79, 0x20, 0x7c, 0xbe, 0x8d, 0xd2,
+	0x85, 0xe8, 0xe8, 0x07, 0xfb, 0xa8, 0xa7, 0xe5, 0xc9, 0x34, 0x4b, 0x71, 0x34, 0x2b, 0xd0, 0xfe,
+	0xca, 0xc1, 0xab, 0xe3, 0x11, 0x5f, 0xfc, 0x5e, 0x87, 0x4e, 0x0a, 0xff, 0xd7, 0x49, 0x07, 0x1a,
+	0x49, 0x22, 0x39, 0x5c, 0x93, 0x7d, 0x10, 0xb8, 0x01, 0x79, 0x7f, 0x91, 0xce, 0xce, 0xfb, 0x0b,
+	0xed, 0x1b, 0x68, 0x3e, 0x13, 0xc3, 0x35, 0x8f, 0xd8, 0x27, 0xc8, 0x4f, 0x80, 0x0e, 0x4e, 0xf5,
+	0x2a, 0x16, 0x2c, 0xc2, 0x1d, 0xa8, 0x85, 0xcf, 0x32, 0x81, 0xeb, 0xf4, 0x30, 0xa4, 0x6d, 0xe1,
+	0x34, 0xab, 0x0a, 0xf8, 0x36, 0x62, 0xb8, 0x0f, 0x65, 0x95, 0x97, 0x78, 0xa1, 0x5b, 0xeb, 0xb7,
+	0xb2, 0x05, 0x3e, 0xee, 0x4e, 0x33, 0x10, 0x5f, 0x42, 0x65, 0xe5, 0x45, 0xee, 0x86, 0x87, 0xea,
+	0x9b, 0xa9, 0xd0, 0xf2, 0xca, 0x8b, 0xee, 0x78, 0x98, 0xb9, 0x2c, 0x64, 0x2e, 0xfb, 0xef, 0x0e,
+	0xae, 0x42, 0x7b, 0x17, 0x04, 0x3c, 0x14, 0x78, 0x04, 0x15, 0xca, 0x96, 0x7e, 0x24, 0x58, 0x88,
+	0x5b, 0x9f, 0xbb, 0x08, 0xdb, 0x9f, 0xcd, 0x68, 0x2f, 0xba, 0xb9, 0xef, 0x73, 0x57, 0x16, 0x68,
+	0x3c, 0x5c, 0xea, 0xab, 0x38, 0x60, 0xa1, 0xba, 0x9a, 0xf5, 0x47, 0xef, 0x21, 0xf4, 0xe7, 0x59,
+	0x9d, 0xbc, 0xe5, 0x7f, 0xff, 0x6e, 0xe9, 0x8b, 0xd5, 0xee, 0x41, 0x9f, 0xf3, 0x4d, 0xef, 0x00,
+	0xed, 0x29, 0x54, 0xdd, 0xf6, 0x51, 0x4f, 0xa2, 0x0f, 0xea, 0xaf, 0xe3, 0xc7, 0x7f, 0x07, 0x00,
+	0x53, 0x67, 0x4a, 0x22, 0x5e, 0x06, 0x00, 0x00,
 }
@@ -41,6 +41,7 @@ const (
 	TxValidationCode_BAD_RESPONSE_PAYLOAD         TxValidationCode = 21
 	TxValidationCode_BAD_RWSET                    TxValidationCode = 22
 	TxValidationCode_ILLEGAL_WRITESET             TxValidationCode = 23
+	TxValidationCode_CROSS_CHANNEL_READ_CONFLICT  TxValidationCode = 24
 	TxValidationCode_INVALID_OTHER_REASON         TxValidationCode = 255
 )
 
@@ -69,6 +70,7 @@ var TxValidationCode_name = map[int32]string{
 	21:  "BAD_RESPONSE_PAYLOAD",
 	22:  "BAD_RWSET",
 	23:  "ILLEGAL_WRITESET",
+	24:  "CROSS_CHANNEL_READ_CONFLICT",
 	255: "INVALID_OTHER_REASON",
 }
 var TxValidationCode_value = map[string]int32{
@@ -96,6 +98,7 @@ var TxValidationCode_value = map[string]int32{
 	"BAD_RESPONSE_PAYLOAD":         21,
 	"BAD_RWSET":                    22,
 	"ILLEGAL_WRITESET":             23,
+	"CROSS_CHANNEL_READ_CONFLICT":  24,
 	"INVALID_OTHER_REASON":         255,
 }
 
@@ -308,58 +311,58 @@ func init() {
 func init() { proto.RegisterFile("peer/transaction.proto", fileDescriptor11) }
 
 var fileDescriptor11 = []byte{
-	// 833 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x74, 0x54, 0xd1, 0x6e, 0xe2, 0x46,
-	0x14, 0x2d, 0xd9, 0x4d, 0xd2, 0x0c, 0xd9, 0x64, 0x32, 0x10, 0x42, 0x50, 0xd4, 0x5d, 0xf1, 0x50,
-	0x6d, 0x5b, 0x09, 0xa4, 0xec, 0x43, 0xa5, 0xaa, 0x2f, 0x83, 0x3d, 0x09, 0x56, 0xcd, 0x8c, 0x35,
-	0x1e, 0x08, 0xe9, 0x43, 0x47, 0x06, 0xcf, 0x12, 0x54, 0xb0, 0x2d, 0xdb, 0x59, 0x35, 0xaf, 0xfd,
-	0x80, 0xf6, 0x3f, 0xfb, 0x13, 0xad, 0xc6, 0x63, 0x03, 0xc9, 0x76, 0x5f, 0x30, 0x73, 0xcf, 0xb9,
-	0xf7, 0x9c, 0x7b, 0xaf, 0x3d, 0xa0, 0x95, 0x28, 0x95, 0xf6, 0xf3, 0x34, 0x88, 0xb2, 0x60, 0x9e,
-	0x2f, 0xe3, 0xa8, 0x97, 0xa4, 0x71, 0x1e, 0xa3, 0x83, 0xe2, 0x91, 0x75, 0xde, 0x2e, 0xe2, 0x78,
-	0xb1, 0x52, 0xfd, 0xe2, 0x38, 0x7b, 0xfc, 0xd8, 0xcf, 0x97, 0x6b, 0x95, 0xe5, 0xc1, 0x3a, 0x31,
-	0xc4, 0xce, 0x55, 0x51, 0x20, 0x49, 0xe3, 0x24, 0xce, 0x82, 0x95, 0x4c, 0x55, 0x96, 0xc4, 0x51,
-	0xa6, 0x4a, 0xb4, 0x31, 0x8f, 0xd7, 0xeb, 0x38, 0xea, 0x9b, 0x87, 0x09, 0x76, 0x7f, 0x03, 0x67,
-	0xfe, 0x72, 0x11, 0xa9, 0x50, 0x6c, 0x65, 0xd1, 0x0f, 0xe0, 0x6c, 0xc7, 0x85, 0x9c, 0x3d, 0xe5,
-	0x2a, 0x6b, 0xd7, 0xde, 0xd5, 0xde, 0x1f, 0x73, 0xb8, 0x03, 0x0c, 0x74, 0x1c, 0x5d, 0x81, 0xa3,
-	0x6c, 0xb9, 0x88, 0x82, 0xfc, 0x31, 0x55, 0xed, 0xbd, 0x82, 0xb4, 0x0d, 0x74, 0xff, 0xac, 0x81,
-	0xa6, 0x97, 0xc6, 0x73, 0x95, 0x65, 0xcf, 0x35, 0x06, 0xa0, 0xb1, 0x53, 0x8a, 0x44, 0x9f, 0xd4,
-	0x2a, 0x4e, 0x54, 0xa1, 0x52, 0xbf, 0x86, 0xbd, 0xd2, 0x64, 0x15, 0xe7, 0xff, 0x47, 0x46, 0xdf,
-	0x82, 0x93, 0x4f, 0xc1, 0x6a, 0x19, 0x06, 0x3a, 0x6a, 0xc5, 0xa1, 0xd1, 0xdf, 0xe7, 0x2f, 0xa2,
-	0xdd, 0x01, 0xa8, 0xef, 0x4a, 0x7f, 0x00, 0x87, 0xe6, 0x9f, 0x6e, 0xea, 0xd5, 0xfb, 0xfa, 0xf5,
-	0xa5, 0x19, 0x46, 0xd6, 0xdb, 0x61, 0xe1, 0xe2, 0x97, 0x57, 0xcc, 0x2e, 0x01, 0x67, 0x9f, 0xa1,
-	0xa8, 0x05, 0x0e, 0x1e, 0x54, 0x10, 0xaa, 0xb4, 0x9c, 0x4e, 0x79, 0x42, 0x6d, 0x70, 0x98, 0x04,
-	0x4f, 0xab, 0x38, 0x08, 0xcb, 0x89, 0x54, 0xc7, 0xee, 0xdf, 0x35, 0xd0, 0xb2, 0x1e, 0x82, 0x65,
-	0x34, 0x8f, 0x43, 0x65, 0xaa, 0x78, 0x06, 0x42, 0x3f, 0x83, 0xce, 0xbc, 0x42, 0xe4, 0x66, 0x89,
-	0x55, 0x1d, 0x23, 0xd0, 0xde, 0x30, 0xbc, 0x92, 0x50, 0x65, 0xff, 0x08, 0x0e, 0x8c, 0xb5, 0x42,
-	0xb1, 0x7e, 0xfd, 0xb6, 0xea, 0x69, 0xa3, 0x46, 0xa2, 0x30, 0x4e, 0x33, 0x15, 0x96, 0x9d, 0x95,
-	0xf4, 0xee, 0x5f, 0x35, 0x70, 0xf1, 0x05, 0x0e, 0xfa, 0x09, 0x5c, 0x7e, 0xf6, 0x36, 0xbd, 0x70,
-	0x74, 0x51, 0x11, 0x78, 0x89, 0x6f, 0x0d, 0x1d, 0x2b, 0x53, 0x6d, 0xad, 0xa2, 0x3c, 0x6b, 0xef,
-	0x15, 0xa3, 0x6e, 0x54, 0xb6, 0xc8, 0x16, 0xe3, 0xcf, 0x88, 0xdf, 0xff, 0xf3, 0x1a, 0x40, 0xf1,
-	0xc7, 0xe4, 0xd9, 0x0a, 0xd1, 0x11, 0xd8, 0x9f, 0x60, 0xd7, 0xb1, 0xe1, 0x57, 0x08, 0x82, 0x63,
-	0xea, 0xb8, 0x92, 0xd0, 0x09, 0x71, 0x99, 0x47, 0x60, 0x0d, 0x9d, 0x82, 0xfa, 0x00, 0xdb, 0xd2,
-	0xc3, 0xf7, 0x2e, 0xc3, 0x36, 0xdc, 0x43, 0xe7, 0xe0, 0x4c, 0x07, 0x2c, 0x36, 0x1a, 0x31, 0x2a,
-	0x87, 0x04, 0xdb, 0x84, 0xc3, 0x57, 0xe8, 0x12, 0x9c, 0x17, 0x61, 0x4e, 0xb0, 0x60, 0x5c, 0xfa,
-	0xce, 0x2d, 0xc5, 0x62, 0xcc, 0x09, 0x7c, 0x8d, 0xde, 0x81, 0x2b, 0x87, 0x16, 0x0a, 0x92, 0x50,
-	0x9b, 0x71, 0x9f, 0x70, 0x29, 0x38, 0xa6, 0x3e, 0xb6, 0x84, 0xc3, 0x28, 0xdc, 0x47, 0xdf, 0x80,
-	0x4e, 0xc5, 0xb0, 0x18, 0xbd, 0x71, 0x6e, 0x9f, 0xe1, 0x07, 0xa8, 0x03, 0x5a, 0x63, 0xea, 0x8f,
-	0x3d, 0x8f, 0x71, 0x41, 0x6c, 0x29, 0xa6, 0x1b, 0x3f, 0x87, 0x95, 0x1f, 0x8f, 0x33, 0x8f, 0xf9,
-	0xd8, 0x95, 0x62, 0xea, 0xd8, 0xf0, 0x6b, 0x84, 0xc0, 0x89, 0x3d, 0xf6, 0x5c, 0xc7, 0xc2, 0x82,
-	0x98, 0xd8, 0x91, 0x96, 0x29, 0x0d, 0x8c, 0x08, 0x15, 0xd2, 0x63, 0xae, 0x63, 0xdd, 0xcb, 0x1b,
-	0xec, 0xb8, 0xda, 0x28, 0x40, 0x2d, 0x80, 0x46, 0x13, 0xcb, 0x92, 0x9c, 0x60, 0x63, 0xc4, 0x75,
-	0x2c, 0x01, 0xeb, 0xba, 0x37, 0x6f, 0x88, 0xa9, 0x60, 0xa3, 0x17, 0xd0, 0x31, 0x6a, 0x80, 0xd3,
-	0x31, 0xfd, 0x85, 0xb2, 0x3b, 0xaa, 0x5d, 0x89, 0x7b, 0x8f, 0xc0, 0x37, 0xda, 0xae, 0xc0, 0xfc,
-	0x96, 0x08, 0x69, 0x0d, 0xb1, 0x43, 0x25, 0x65, 0x42, 0xde, 0xb0, 0x31, 0xb5, 0xe1, 0x09, 0x6a,
-	0x02, 0x38, 0xc2, 0xdc, 0x1f, 0x16, 0x4e, 0x25, 0xe1, 0x9c, 0x71, 0x78, 0x5a, 0xcd, 0x5d, 0x4c,
-	0xcb, 0x96, 0xa1, 0x6e, 0x8b, 0x4c, 0x3d, 0x87, 0x13, 0xdb, 0x14, 0xb1, 0x98, 0x4d, 0xe0, 0x99,
-	0x6e, 0x61, 0x73, 0x94, 0x13, 0xc2, 0x7d, 0x87, 0xd1, 0xad, 0x1f, 0x84, 0xda, 0xa0, 0xa9, 0xa7,
-	0x61, 0xd6, 0x22, 0xc9, 0x54, 0x10, 0xaa, 0x29, 0xb0, 0xa1, 0x9b, 0x2b, 0x16, 0x34, 0xc4, 0x94,
-	0x12, 0xb7, 0x5a, 0x5c, 0xb3, 0xca, 0xe0, 0xc4, 0xf7, 0x18, 0xf5, 0xc9, 0x66, 0xb2, 0xe7, 0xe8,
-	0x0d, 0x38, 0x2a, 0x90, 0x3b, 0x9f, 0x08, 0xd8, 0xd2, 0xce, 0x1d, 0xd7, 0x25, 0xb7, 0xd8, 0x95,
-	0x77, 0xdc, 0x11, 0x44, 0x47, 0x2f, 0xd0, 0x25, 0x68, 0x56, 0xab, 0x63, 0x62, 0x48, 0xb8, 0x9e,
-	0x90, 0xcf, 0x28, 0xfc, 0xb7, 0x36, 0x98, 0x83, 0x6e, 0x9c, 0x2e, 0x7a, 0x0f, 0x4f, 0x89, 0x4a,
-	0x57, 0x2a, 0x5c, 0xa8, 0xb4, 0xf7, 0x31, 0x98, 0xa5, 0xcb, 0x79, 0xf5, 0x9e, 0xea, 0x2b, 0x75,
-	0x80, 0x76, 0x3e, 0x7d, 0x2f, 0x98, 0xff, 0x1e, 0x2c, 0xd4, 0xaf, 0xdf, 0x2d, 0x96, 0xf9, 0xc3,
-	0xe3, 0x4c, 0xdf, 0x54, 0xfd, 0x9d, 0xf4, 0xbe, 0x49, 0x37, 0x97, 0x74, 0xd6, 0xd7, 0xe9, 0x33,
-	0x73, 0x81, 0x7f, 0xf8, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x19, 0x1c, 0xb2, 0xe4, 0xe1, 0x05, 0x00,
-	0x00,
+	// 841 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x54, 0xdd, 0x6f, 0x22, 0xb7,
+	0x17, 0xfd, 0x91, 0xfd, 0x25, 0x69, 0x2e, 0xd9, 0xc4, 0x18, 0x42, 0x08, 0x8d, 0x9a, 0x15, 0x0f,
+	0xd5, 0xb6, 0x95, 0x40, 0xca, 0x3e, 0x54, 0xaa, 0xfa, 0x62, 0x66, 0x9c, 0x30, 0xea, 0x60, 0x8f,
+	0x3c, 0x86, 0x90, 0x3e, 0xd4, 0x1a, 0xc0, 0x4b, 0x50, 0x61, 0x06, 0xcd, 0x90, 0x55, 0xf3, 0xda,
+	0xd7, 0x4a, 0xed, 0x9f, 0xdc, 0xca, 0xf3, 0xc1, 0x47, 0xb6, 0x7d, 0x61, 0xf0, 0x3d, 0xc7, 0xf7,
+	0x9c, 0x7b, 0xaf, 0x75, 0xa1, 0xbe, 0xd2, 0x3a, 0xee, 0xac, 0xe3, 0x20, 0x4c, 0x82, 0xc9, 0x7a,
+	0x1e, 0x85, 0xed, 0x55, 0x1c, 0xad, 0x23, 0x7c, 0x94, 0x7e, 0x92, 0xe6, 0xcd, 0x2c, 0x8a, 0x66,
+	0x0b, 0xdd, 0x49, 0x8f, 0xe3, 0xe7, 0x8f, 0x9d, 0xf5, 0x7c, 0xa9, 0x93, 0x75, 0xb0, 0x5c, 0x65,
+	0xc4, 0xe6, 0x75, 0x9a, 0x60, 0x15, 0x47, 0xab, 0x28, 0x09, 0x16, 0x2a, 0xd6, 0xc9, 0x2a, 0x0a,
+	0x13, 0x9d, 0xa3, 0xd5, 0x49, 0xb4, 0x5c, 0x46, 0x61, 0x27, 0xfb, 0x64, 0xc1, 0xd6, 0x2f, 0x50,
+	0xf1, 0xe7, 0xb3, 0x50, 0x4f, 0xe5, 0x56, 0x16, 0x7f, 0x07, 0x95, 0x1d, 0x17, 0x6a, 0xfc, 0xb2,
+	0xd6, 0x49, 0xa3, 0xf4, 0xae, 0xf4, 0xfe, 0x54, 0xa0, 0x1d, 0xa0, 0x6b, 0xe2, 0xf8, 0x1a, 0x4e,
+	0x92, 0xf9, 0x2c, 0x0c, 0xd6, 0xcf, 0xb1, 0x6e, 0x1c, 0xa4, 0xa4, 0x6d, 0xa0, 0xf5, 0x7b, 0x09,
+	0x6a, 0x5e, 0x1c, 0x4d, 0x74, 0x92, 0xec, 0x6b, 0x74, 0xa1, 0xba, 0x93, 0x8a, 0x86, 0x9f, 0xf4,
+	0x22, 0x5a, 0xe9, 0x54, 0xa5, 0x7c, 0x8b, 0xda, 0xb9, 0xc9, 0x22, 0x2e, 0xfe, 0x8d, 0x8c, 0xbf,
+	0x86, 0xb3, 0x4f, 0xc1, 0x62, 0x3e, 0x0d, 0x4c, 0xd4, 0x8a, 0xa6, 0x99, 0xfe, 0xa1, 0x78, 0x15,
+	0x6d, 0x75, 0xa1, 0xbc, 0x2b, 0xfd, 0x01, 0x8e, 0xb3, 0x7f, 0xa6, 0xa8, 0x37, 0xef, 0xcb, 0xb7,
+	0x57, 0x59, 0x33, 0x92, 0xf6, 0x0e, 0x8b, 0xa4, 0xbf, 0xa2, 0x60, 0xb6, 0x28, 0x54, 0x3e, 0x43,
+	0x71, 0x1d, 0x8e, 0x9e, 0x74, 0x30, 0xd5, 0x71, 0xde, 0x9d, 0xfc, 0x84, 0x1b, 0x70, 0xbc, 0x0a,
+	0x5e, 0x16, 0x51, 0x30, 0xcd, 0x3b, 0x52, 0x1c, 0x5b, 0x7f, 0x95, 0xa0, 0x6e, 0x3d, 0x05, 0xf3,
+	0x70, 0x12, 0x4d, 0x75, 0x96, 0xc5, 0xcb, 0x20, 0xfc, 0x23, 0x34, 0x27, 0x05, 0xa2, 0x36, 0x43,
+	0x2c, 0xf2, 0x64, 0x02, 0x8d, 0x0d, 0xc3, 0xcb, 0x09, 0xc5, 0xed, 0xef, 0xe1, 0x28, 0xb3, 0x96,
+	0x2a, 0x96, 0x6f, 0x6f, 0x8a, 0x9a, 0x36, 0x6a, 0x34, 0x9c, 0x46, 0x71, 0xa2, 0xa7, 0x79, 0x65,
+	0x39, 0xbd, 0xf5, 0x67, 0x09, 0x2e, 0xff, 0x83, 0x83, 0x7f, 0x80, 0xab, 0xcf, 0x5e, 0xd3, 0x2b,
+	0x47, 0x97, 0x05, 0x41, 0xe4, 0xf8, 0xd6, 0xd0, 0xa9, 0xce, 0xb2, 0x2d, 0x75, 0xb8, 0x4e, 0x1a,
+	0x07, 0x69, 0xab, 0xab, 0x85, 0x2d, 0xba, 0xc5, 0xc4, 0x1e, 0xf1, 0xdb, 0x3f, 0x0e, 0x01, 0xc9,
+	0xdf, 0x86, 0x7b, 0x23, 0xc4, 0x27, 0x70, 0x38, 0x24, 0xae, 0x63, 0xa3, 0xff, 0x61, 0x04, 0xa7,
+	0xcc, 0x71, 0x15, 0x65, 0x43, 0xea, 0x72, 0x8f, 0xa2, 0x12, 0x3e, 0x87, 0x72, 0x97, 0xd8, 0xca,
+	0x23, 0x8f, 0x2e, 0x27, 0x36, 0x3a, 0xc0, 0x17, 0x50, 0x31, 0x01, 0x8b, 0xf7, 0xfb, 0x9c, 0xa9,
+	0x1e, 0x25, 0x36, 0x15, 0xe8, 0x0d, 0xbe, 0x82, 0x8b, 0x34, 0x2c, 0x28, 0x91, 0x5c, 0x28, 0xdf,
+	0xb9, 0x67, 0x44, 0x0e, 0x04, 0x45, 0xff, 0xc7, 0xef, 0xe0, 0xda, 0x61, 0xa9, 0x82, 0xa2, 0xcc,
+	0xe6, 0xc2, 0xa7, 0x42, 0x49, 0x41, 0x98, 0x4f, 0x2c, 0xe9, 0x70, 0x86, 0x0e, 0xf1, 0x57, 0xd0,
+	0x2c, 0x18, 0x16, 0x67, 0x77, 0xce, 0xfd, 0x1e, 0x7e, 0x84, 0x9b, 0x50, 0x1f, 0x30, 0x7f, 0xe0,
+	0x79, 0x5c, 0x48, 0x6a, 0x2b, 0x39, 0xda, 0xf8, 0x39, 0x2e, 0xfc, 0x78, 0x82, 0x7b, 0xdc, 0x27,
+	0xae, 0x92, 0x23, 0xc7, 0x46, 0x5f, 0x60, 0x0c, 0x67, 0xf6, 0xc0, 0x73, 0x1d, 0x8b, 0x48, 0x9a,
+	0xc5, 0x4e, 0x8c, 0x4c, 0x6e, 0xa0, 0x4f, 0x99, 0x54, 0x1e, 0x77, 0x1d, 0xeb, 0x51, 0xdd, 0x11,
+	0xc7, 0x35, 0x46, 0x01, 0xd7, 0x01, 0xf7, 0x87, 0x96, 0xa5, 0x04, 0x25, 0x99, 0x11, 0xd7, 0xb1,
+	0x24, 0x2a, 0x9b, 0xda, 0xbc, 0x1e, 0x61, 0x92, 0xf7, 0x5f, 0x41, 0xa7, 0xb8, 0x0a, 0xe7, 0x03,
+	0xf6, 0x13, 0xe3, 0x0f, 0xcc, 0xb8, 0x92, 0x8f, 0x1e, 0x45, 0x6f, 0x8d, 0x5d, 0x49, 0xc4, 0x3d,
+	0x95, 0xca, 0xea, 0x11, 0x87, 0x29, 0xc6, 0xa5, 0xba, 0xe3, 0x03, 0x66, 0xa3, 0x33, 0x5c, 0x03,
+	0xd4, 0x27, 0xc2, 0xef, 0xa5, 0x4e, 0x15, 0x15, 0x82, 0x0b, 0x74, 0x5e, 0xf4, 0x5d, 0x8e, 0xf2,
+	0x92, 0x91, 0x29, 0x8b, 0x8e, 0x3c, 0x47, 0x50, 0x3b, 0x4b, 0x62, 0x71, 0x9b, 0xa2, 0x8a, 0x29,
+	0x61, 0x73, 0x54, 0x43, 0x2a, 0x7c, 0x87, 0xb3, 0xad, 0x1f, 0x8c, 0x1b, 0x50, 0x33, 0xdd, 0xc8,
+	0xc6, 0xa2, 0xe8, 0x48, 0x52, 0x66, 0x28, 0xa8, 0x6a, 0x8a, 0x4b, 0x07, 0xd4, 0x23, 0x8c, 0x51,
+	0xb7, 0x18, 0x5c, 0xad, 0xb8, 0x21, 0xa8, 0xef, 0x71, 0xe6, 0xd3, 0x4d, 0x67, 0x2f, 0xf0, 0x5b,
+	0x38, 0x49, 0x91, 0x07, 0x9f, 0x4a, 0x54, 0x37, 0xce, 0x1d, 0xd7, 0xa5, 0xf7, 0xc4, 0x55, 0x0f,
+	0xc2, 0x91, 0xd4, 0x44, 0x2f, 0xf1, 0x0d, 0x7c, 0x69, 0x09, 0xee, 0xfb, 0x9b, 0xc4, 0xfb, 0x1d,
+	0x6a, 0xe0, 0x2b, 0xa8, 0x15, 0xb3, 0xe5, 0xb2, 0x47, 0x85, 0x21, 0xf8, 0x9c, 0xa1, 0xbf, 0x4b,
+	0xdd, 0x09, 0xb4, 0xa2, 0x78, 0xd6, 0x7e, 0x7a, 0x59, 0xe9, 0x78, 0xa1, 0xa7, 0x33, 0x1d, 0xb7,
+	0x3f, 0x06, 0xe3, 0x78, 0x3e, 0x29, 0x1e, 0xb2, 0xd9, 0xb9, 0x5d, 0xbc, 0xb3, 0x1b, 0xbc, 0x60,
+	0xf2, 0x6b, 0x30, 0xd3, 0x3f, 0x7f, 0x33, 0x9b, 0xaf, 0x9f, 0x9e, 0xc7, 0x66, 0x95, 0x75, 0x76,
+	0xae, 0x77, 0xb2, 0xeb, 0xd9, 0x16, 0x4f, 0x3a, 0xe6, 0xfa, 0x38, 0xdb, 0xf0, 0x1f, 0xfe, 0x19,
+	0x00, 0x9b, 0x21, 0xe9, 0x62, 0x02, 0x06, 0x00, 0x00,
 }
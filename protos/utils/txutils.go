@@ -307,6 +307,38 @@ func GetSignedProposal(prop *peer.Proposal, signer msp.SigningIdentity) (*peer.S
 	return &peer.SignedProposal{ProposalBytes: propBytes, Signature: signature}, nil
 }
 
+// CreateSignedAdminProposal returns a proposal without payload signed by the given signing identity,
+// which authenticates it to the Admin service of a peer
+func CreateSignedAdminProposal(signer msp.SigningIdentity) (*peer.SignedProposal, error) {
+	return CreateSignedAdminProposalWithPayload(signer, nil)
+}
+
+// CreateSignedAdminProposalWithPayload returns a proposal carrying the given payload signed by
+// the given signing identity, which authenticates the request it carries to the Admin service of a peer
+func CreateSignedAdminProposalWithPayload(signer msp.SigningIdentity, payload []byte) (*peer.SignedProposal, error) {
+	if signer == nil {
+		return nil, fmt.Errorf("Nil arguments")
+	}
+
+	creator, err := signer.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := CreateNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := MakePayloadHeader(MakeChannelHeader(common.HeaderType_MESSAGE, 0, "", 0), MakeSignatureHeader(creator, nonce))
+	hdrBytes, err := proto.Marshal(hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	return GetSignedProposal(&peer.Proposal{Header: hdrBytes, Payload: payload}, signer)
+}
+
 // GetSignedEvent returns a signed event given an Event message and a signing identity
 func GetSignedEvent(evt *peer.Event, signer msp.SigningIdentity) (*peer.SignedEvent, error) {
 	// check for nil argument
@@ -298,6 +298,41 @@ func TestGetSignedProposal(t *testing.T) {
 
 }
 
+func TestCreateSignedAdminProposal(t *testing.T) {
+	signID, err := mockmsp.NewNoopMsp().GetDefaultSigningIdentity()
+	assert.NoError(t, err, "Unexpected error getting signing identity")
+
+	signedProp, err := utils.CreateSignedAdminProposal(signID)
+	assert.NoError(t, err, "Unexpected error creating signed admin proposal")
+	assert.Equal(t, []byte("signature"), signedProp.Signature,
+		"Signature did not match expected value")
+	prop, err := utils.GetProposal(signedProp.ProposalBytes)
+	assert.NoError(t, err, "Unexpected error getting proposal")
+	hdr, err := utils.GetHeader(prop.Header)
+	assert.NoError(t, err, "Unexpected error getting header")
+	shdr, err := utils.GetSignatureHeader(hdr.SignatureHeader)
+	assert.NoError(t, err, "Unexpected error getting signature header")
+	creator, _ := signID.Serialize()
+	assert.Equal(t, creator, shdr.Creator, "Creator did not match expected value")
+
+	_, err = utils.CreateSignedAdminProposal(nil)
+	assert.Error(t, err, "Expected error with nil signing identity")
+}
+
+func TestCreateSignedAdminProposalWithPayload(t *testing.T) {
+	signID, err := mockmsp.NewNoopMsp().GetDefaultSigningIdentity()
+	assert.NoError(t, err, "Unexpected error getting signing identity")
+
+	signedProp, err := utils.CreateSignedAdminProposalWithPayload(signID, []byte("payload"))
+	assert.NoError(t, err, "Unexpected error creating signed admin proposal")
+	prop, err := utils.GetProposal(signedProp.ProposalBytes)
+	assert.NoError(t, err, "Unexpected error getting proposal")
+	assert.Equal(t, []byte("payload"), prop.Payload, "Payload did not match expected value")
+
+	_, err = utils.CreateSignedAdminProposalWithPayload(nil, []byte("payload"))
+	assert.Error(t, err, "Expected error with nil signing identity")
+}
+
 func TestGetSignedEvent(t *testing.T) {
 	var signedEvt *pb.SignedEvent
 	var err error